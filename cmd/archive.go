@@ -0,0 +1,177 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/hyperledger/firefly/internal/apiserver"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/spf13/cobra"
+)
+
+var archiveAdminURL string
+var archiveTimeout string
+var archiveToken string
+var exportNamespace string
+var exportOutput string
+var importInput string
+
+var exportCommand = &cobra.Command{
+	Use:   "export",
+	Short: "Export the definitions, messages, data and blobs of a namespace to an archive file, using the admin API of a running node",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runExport(context.Background())
+	},
+}
+
+var importCommand = &cobra.Command{
+	Use:   "import",
+	Short: "Import an archive file created by export into a namespace, using the admin API of a running node",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runImport(context.Background())
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{exportCommand, importCommand} {
+		c.Flags().StringVarP(&archiveAdminURL, "url", "u", "", "admin API URL of the node (default is derived from the admin section of the config file)")
+		c.Flags().StringVarP(&archiveTimeout, "timeout", "t", "10m", "server-side timeout for the request (limited by api.requestMaxTimeout on the node)")
+		c.Flags().StringVarP(&archiveToken, "token", "k", "", "API key or JWT with the admin role, when authentication is enabled on the node")
+	}
+	exportCommand.Flags().StringVarP(&exportNamespace, "namespace", "n", "default", "namespace to export")
+	exportCommand.Flags().StringVarP(&exportOutput, "output", "o", "", "archive file to write (default is <namespace>.tar)")
+	importCommand.Flags().StringVarP(&importInput, "input", "i", "", "archive file to read")
+	_ = importCommand.MarkFlagRequired("input")
+}
+
+func getAdminURL(ctx context.Context) (string, error) {
+	if archiveAdminURL != "" {
+		return archiveAdminURL, nil
+	}
+	config.Reset()
+	apiserver.InitConfig()
+	if err := config.ReadConfig(cfgFile); err != nil {
+		return "", i18n.WrapError(ctx, err, i18n.MsgConfigFailed)
+	}
+	return apiserver.AdminURL(), nil
+}
+
+func doArchiveRequest(ctx context.Context, req *http.Request) (*http.Response, error) {
+	req.Header.Set("Request-Timeout", archiveTimeout)
+	if archiveToken != "" {
+		req.Header.Set("Authorization", "Bearer "+archiveToken)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(io.LimitReader(res.Body, 4096))
+		return nil, i18n.NewError(ctx, i18n.MsgArchiveRequestFailed, req.URL, res.StatusCode, body)
+	}
+	return res, nil
+}
+
+func runExport(ctx context.Context) error {
+	adminURL, err := getAdminURL(ctx)
+	if err != nil {
+		return err
+	}
+	if exportOutput == "" {
+		exportOutput = fmt.Sprintf("%s.tar", exportNamespace)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/namespaces/%s/export", adminURL, url.PathEscape(exportNamespace)), nil)
+	if err != nil {
+		return err
+	}
+	res, err := doArchiveRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	out, err := os.Create(exportOutput)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	written, err := io.Copy(out, res.Body)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Exported namespace '%s' to %s (%d bytes)\n", exportNamespace, exportOutput, written)
+	return nil
+}
+
+func runImport(ctx context.Context) error {
+	adminURL, err := getAdminURL(ctx)
+	if err != nil {
+		return err
+	}
+	in, err := os.Open(importInput)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	// Stream the archive as a multi-part upload, rather than holding it in memory
+	reader, writer := io.Pipe()
+	mw := multipart.NewWriter(writer)
+	go func() {
+		part, err := mw.CreateFormFile("file", filepath.Base(importInput))
+		if err == nil {
+			_, err = io.Copy(part, in)
+		}
+		if err == nil {
+			err = mw.Close()
+		}
+		_ = writer.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/namespaces/import", adminURL), reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	res, err := doArchiveRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	var result fftypes.ArchiveImportResult
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return err
+	}
+	b, _ := json.MarshalIndent(&result, "", "  ")
+	fmt.Printf("%s\n", b)
+	return nil
+}
@@ -0,0 +1,208 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func resetArchiveFlags() {
+	archiveAdminURL = ""
+	archiveTimeout = "10m"
+	archiveToken = ""
+	exportNamespace = "default"
+	exportOutput = ""
+	importInput = ""
+	rootCmd.SetArgs([]string{})
+}
+
+func TestGetAdminURLFromConfig(t *testing.T) {
+	defer resetArchiveFlags()
+	cfg, _ := filepath.Abs(filepath.Join(configDir, "firefly.core.yaml"))
+	cfgFile = cfg
+	defer func() { cfgFile = "" }()
+
+	adminURL, err := getAdminURL(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "http://127.0.0.1:5001/admin/api/v1", adminURL)
+}
+
+func TestGetAdminURLBadConfig(t *testing.T) {
+	defer resetArchiveFlags()
+	cfgFile = filepath.Join(t.TempDir(), "missing.yaml")
+	defer func() { cfgFile = "" }()
+
+	_, err := getAdminURL(context.Background())
+	assert.Regexp(t, "FF10101", err)
+}
+
+func TestExportOk(t *testing.T) {
+	defer resetArchiveFlags()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/admin/api/v1/namespaces/ns1/export", r.URL.Path)
+		assert.Equal(t, "1h", r.Header.Get("Request-Timeout"))
+		assert.Equal(t, "Bearer key1", r.Header.Get("Authorization"))
+		w.Write([]byte("archive"))
+	}))
+	defer server.Close()
+
+	output := filepath.Join(t.TempDir(), "ns1.tar")
+	rootCmd.SetArgs([]string{"export", "-u", server.URL + "/admin/api/v1", "-n", "ns1", "-o", output, "-t", "1h", "-k", "key1"})
+	err := rootCmd.Execute()
+	assert.NoError(t, err)
+
+	b, err := ioutil.ReadFile(output)
+	assert.NoError(t, err)
+	assert.Equal(t, "archive", string(b))
+}
+
+func TestExportBadConfig(t *testing.T) {
+	defer resetArchiveFlags()
+	cfgFile = filepath.Join(t.TempDir(), "missing.yaml")
+	defer func() { cfgFile = "" }()
+
+	err := runExport(context.Background())
+	assert.Regexp(t, "FF10101", err)
+}
+
+func TestExportBadURL(t *testing.T) {
+	defer resetArchiveFlags()
+	archiveAdminURL = "::"
+
+	err := runExport(context.Background())
+	assert.Error(t, err)
+}
+
+func TestExportRequestFail(t *testing.T) {
+	defer resetArchiveFlags()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+	archiveAdminURL = server.URL
+
+	err := runExport(context.Background())
+	assert.Error(t, err)
+}
+
+func TestExportNotFound(t *testing.T) {
+	defer resetArchiveFlags()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+		w.Write([]byte(`{"error":"FF10109: Not found"}`))
+	}))
+	defer server.Close()
+	archiveAdminURL = server.URL
+
+	err := runExport(context.Background())
+	assert.Regexp(t, "FF10433.*404.*FF10109", err)
+}
+
+func TestExportBadOutput(t *testing.T) {
+	defer resetArchiveFlags()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("archive"))
+	}))
+	defer server.Close()
+	archiveAdminURL = server.URL
+	exportOutput = t.TempDir()
+
+	err := runExport(context.Background())
+	assert.Error(t, err)
+}
+
+func TestImportOk(t *testing.T) {
+	defer resetArchiveFlags()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/admin/api/v1/namespaces/import", r.URL.Path)
+		f, fh, err := r.FormFile("file")
+		assert.NoError(t, err)
+		assert.Equal(t, "ns1.tar", fh.Filename)
+		b, _ := ioutil.ReadAll(f)
+		assert.Equal(t, "archive", string(b))
+		w.Write([]byte(`{"namespace":"ns1","imported":{"messages":1},"skipped":{}}`))
+	}))
+	defer server.Close()
+
+	input := filepath.Join(t.TempDir(), "ns1.tar")
+	ioutil.WriteFile(input, []byte("archive"), 0644)
+	rootCmd.SetArgs([]string{"import", "-u", server.URL + "/admin/api/v1", "-i", input})
+	err := rootCmd.Execute()
+	assert.NoError(t, err)
+}
+
+func TestImportBadConfig(t *testing.T) {
+	defer resetArchiveFlags()
+	cfgFile = filepath.Join(t.TempDir(), "missing.yaml")
+	defer func() { cfgFile = "" }()
+
+	err := runImport(context.Background())
+	assert.Regexp(t, "FF10101", err)
+}
+
+func TestImportMissingFile(t *testing.T) {
+	defer resetArchiveFlags()
+	archiveAdminURL = "http://localhost:5001/admin/api/v1"
+	importInput = filepath.Join(t.TempDir(), "missing.tar")
+
+	err := runImport(context.Background())
+	assert.Error(t, err)
+}
+
+func TestImportBadURL(t *testing.T) {
+	defer resetArchiveFlags()
+	archiveAdminURL = "::"
+	importInput = filepath.Join(t.TempDir(), "ns1.tar")
+	ioutil.WriteFile(importInput, []byte("archive"), 0644)
+
+	err := runImport(context.Background())
+	assert.Error(t, err)
+}
+
+func TestImportFail(t *testing.T) {
+	defer resetArchiveFlags()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(400)
+		w.Write([]byte(`{"error":"FF10428: bad manifest"}`))
+	}))
+	defer server.Close()
+	archiveAdminURL = server.URL
+	importInput = filepath.Join(t.TempDir(), "ns1.tar")
+	ioutil.WriteFile(importInput, []byte("archive"), 0644)
+
+	err := runImport(context.Background())
+	assert.Regexp(t, "FF10433.*400.*FF10428", err)
+}
+
+func TestImportBadResponse(t *testing.T) {
+	defer resetArchiveFlags()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`!json`))
+	}))
+	defer server.Close()
+	archiveAdminURL = server.URL
+	importInput = filepath.Join(t.TempDir(), "ns1.tar")
+	ioutil.WriteFile(importInput, []byte("archive"), 0644)
+
+	err := runImport(context.Background())
+	assert.Error(t, err)
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/orchestrator"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -72,6 +73,8 @@ var _utOrchestrator orchestrator.Orchestrator
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "f", "", "config file")
 	rootCmd.AddCommand(showConfigCommand)
+	rootCmd.AddCommand(exportCommand)
+	rootCmd.AddCommand(importCommand)
 }
 
 func getOrchestrator() orchestrator.Orchestrator {
@@ -108,6 +111,13 @@ func run() error {
 		return i18n.WrapError(ctx, err, i18n.MsgConfigFailed)
 	}
 
+	// Start exporting spans, if enabled. The exporter is flushed on exit after the context is cancelled
+	if err = tracing.Init(ctx); err != nil {
+		cancelCtx()
+		return err
+	}
+	defer tracing.Shutdown(context.Background())
+
 	// Setup signal handling to cancel the context, which shuts down the API Server
 	errChan := make(chan error)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
@@ -0,0 +1,15 @@
+BEGIN;
+CREATE TABLE inflightrequests (
+  seq               SERIAL          PRIMARY KEY,
+  id                UUID            NOT NULL,
+  namespace         VARCHAR(64)     NOT NULL,
+  rtype             VARCHAR(64)     NOT NULL,
+  created           BIGINT          NOT NULL,
+  resolved          BIGINT,
+  reply             UUID,
+  failed            BOOLEAN         NOT NULL
+);
+
+CREATE UNIQUE INDEX inflightrequests_id ON inflightrequests(id);
+
+COMMIT;
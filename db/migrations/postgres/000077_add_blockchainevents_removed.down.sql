@@ -0,0 +1,4 @@
+BEGIN;
+DROP INDEX blockchainevents_protocol_id;
+ALTER TABLE blockchainevents DROP COLUMN removed;
+COMMIT;
@@ -0,0 +1,4 @@
+BEGIN;
+DROP INDEX operations_retry_after;
+ALTER TABLE operations DROP COLUMN retry_after;
+COMMIT;
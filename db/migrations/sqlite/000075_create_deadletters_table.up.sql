@@ -0,0 +1,18 @@
+CREATE TABLE deadletters (
+  seq               INTEGER         PRIMARY KEY AUTOINCREMENT,
+  id                UUID            NOT NULL,
+  namespace         VARCHAR(64)     NOT NULL,
+  subscription_id   UUID            NOT NULL,
+  subscription_name VARCHAR(64)     NOT NULL,
+  event_id          UUID            NOT NULL,
+  etype             VARCHAR(64)     NOT NULL,
+  ref               UUID,
+  event_seq         BIGINT          NOT NULL,
+  attempts          INTEGER         NOT NULL,
+  info              TEXT,
+  created           BIGINT          NOT NULL,
+  updated           BIGINT
+);
+
+CREATE UNIQUE INDEX deadletters_id ON deadletters(id);
+CREATE UNIQUE INDEX deadletters_subscription_event ON deadletters(subscription_id, event_id);
@@ -0,0 +1,12 @@
+CREATE TABLE inflightrequests (
+  seq               INTEGER         PRIMARY KEY AUTOINCREMENT,
+  id                UUID            NOT NULL,
+  namespace         VARCHAR(64)     NOT NULL,
+  rtype             VARCHAR(64)     NOT NULL,
+  created           BIGINT          NOT NULL,
+  resolved          BIGINT,
+  reply             UUID,
+  failed            BOOLEAN         NOT NULL
+);
+
+CREATE UNIQUE INDEX inflightrequests_id ON inflightrequests(id);
@@ -0,0 +1,2 @@
+DROP INDEX blockchainevents_protocol_id;
+ALTER TABLE blockchainevents DROP COLUMN removed;
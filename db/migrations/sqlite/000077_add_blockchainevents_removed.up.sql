@@ -0,0 +1,2 @@
+ALTER TABLE blockchainevents ADD COLUMN removed BIGINT;
+CREATE INDEX blockchainevents_protocol_id ON blockchainevents(source, protocol_id);
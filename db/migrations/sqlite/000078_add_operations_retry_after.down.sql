@@ -0,0 +1,2 @@
+DROP INDEX operations_retry_after;
+ALTER TABLE operations DROP COLUMN retry_after;
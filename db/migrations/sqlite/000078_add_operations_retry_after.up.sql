@@ -0,0 +1,2 @@
+ALTER TABLE operations ADD COLUMN retry_after BIGINT;
+CREATE INDEX operations_retry_after ON operations(retry_after);
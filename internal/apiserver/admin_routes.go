@@ -22,6 +22,10 @@ var adminRoutes = []*oapispec.Route{
 	getConfig,
 	getConfigRecord,
 	getConfigRecords,
+	getLiveness,
+	getNamespaceExport,
+	getReadiness,
+	postNamespaceImport,
 	postResetConfig,
 	putConfigRecord,
 	deleteConfigRecord,
@@ -56,6 +56,8 @@ const (
 	HTTPConfTLSKeyFile = "tls.keyFile"
 )
 
+type connContextKey struct{}
+
 type IServer interface {
 	Close() error
 	Serve(l net.Listener) error
@@ -163,12 +165,24 @@ func (hs *httpServer) createServer(ctx context.Context, r *mux.Router) (srv ISer
 			l := log.L(ctx).WithField("req", fftypes.ShortID())
 			newCtx = log.WithLogger(newCtx, l)
 			l.Debugf("New HTTP connection: remote=%s local=%s", c.RemoteAddr().String(), c.LocalAddr().String())
-			return newCtx
+			return context.WithValue(newCtx, connContextKey{}, c)
 		},
 	}
 	return srv, nil
 }
 
+// withoutTimeouts clears the read and write deadlines of the connection before calling the handler, for
+// long-lived requests such as event streams and archive transfers that would otherwise be cut off by the
+// timeouts of the server. The server sets the deadlines again for the next request on the connection.
+func withoutTimeouts(handler http.Handler) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		if c, ok := req.Context().Value(connContextKey{}).(net.Conn); ok {
+			_ = c.SetDeadline(time.Time{})
+		}
+		handler.ServeHTTP(res, req)
+	}
+}
+
 func (hs *httpServer) serveHTTP(ctx context.Context) {
 	serverEnded := make(chan struct{})
 	go func() {
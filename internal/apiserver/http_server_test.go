@@ -197,3 +197,40 @@ func TestTLSServerSelfSignedWithClientAuth(t *testing.T) {
 	err = <-errChan
 	assert.NoError(t, err)
 }
+
+func TestStreamWithoutTimeouts(t *testing.T) {
+	config.Reset()
+	cp := config.NewPluginConfig("ut")
+	initHTTPConfPrefx(cp, 0)
+	cp.Set(HTTPConfWriteTimeout, "50ms")
+	cp.Set(HTTPConfReadTimeout, "50ms")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := func(res http.ResponseWriter, req *http.Request) {
+		for i := 0; i < 4; i++ {
+			fmt.Fprintf(res, "data: %d\n\n", i)
+			res.(http.Flusher).Flush()
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+	r := mux.NewRouter()
+	r.HandleFunc("/stream", withoutTimeouts(http.HandlerFunc(stream)))
+	r.HandleFunc("/timeout", stream)
+	hs, err := newHTTPServer(ctx, "ut", r, make(chan error), cp)
+	assert.NoError(t, err)
+	go hs.serveHTTP(ctx)
+
+	res, err := http.Get(fmt.Sprintf("http://%s/stream", hs.l.Addr()))
+	assert.NoError(t, err)
+	b, err := ioutil.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "data: 0\n\ndata: 1\n\ndata: 2\n\ndata: 3\n\n", string(b))
+
+	// Without clearing the deadlines, the stream is cut off by the write timeout
+	res, err = http.Get(fmt.Sprintf("http://%s/timeout", hs.l.Addr()))
+	if err == nil {
+		b, _ = ioutil.ReadAll(res.Body)
+		assert.NotEqual(t, "data: 0\n\ndata: 1\n\ndata: 2\n\ndata: 3\n\n", string(b))
+	}
+}
@@ -32,9 +32,11 @@ import (
 )
 
 type filterResultsWithCount struct {
-	Count int64       `json:"count"`
-	Total int64       `json:"total"`
-	Items interface{} `json:"items"`
+	Count    int64       `json:"count"`
+	Total    *int64      `json:"total,omitempty"`
+	Next     string      `json:"next,omitempty"`
+	Previous string      `json:"previous,omitempty"`
+	Items    interface{} `json:"items"`
 }
 
 type filterModifiers struct {
@@ -52,14 +54,19 @@ func syncRetcode(isSync bool) int {
 
 func filterResult(items interface{}, res *database.FilterResult, err error) (interface{}, error) {
 	itemsVal := reflect.ValueOf(items)
-	if err != nil || res == nil || res.TotalCount == nil || itemsVal.Kind() != reflect.Slice {
+	if err != nil || res == nil || (res.TotalCount == nil && res.Cursors == nil) || itemsVal.Kind() != reflect.Slice {
 		return items, err
 	}
-	return &filterResultsWithCount{
-		Total: *res.TotalCount,
+	fr := &filterResultsWithCount{
+		Total: res.TotalCount,
 		Count: int64(itemsVal.Len()),
 		Items: items,
-	}, nil
+	}
+	if res.Cursors != nil {
+		fr.Next = res.Cursors.Next
+		fr.Previous = res.Cursors.Previous
+	}
+	return fr, nil
 }
 
 func (as *apiServer) getValues(values url.Values, key string) (results []string) {
@@ -117,6 +124,16 @@ func (as *apiServer) buildFilter(req *http.Request, ff database.QueryFactory) (d
 		}
 		filter.Limit(l)
 	}
+	afterVals := as.getValues(req.Form, "after")
+	beforeVals := as.getValues(req.Form, "before")
+	if len(afterVals) > 0 && len(beforeVals) > 0 {
+		return nil, i18n.NewError(req.Context(), i18n.MsgCursorAfterAndBefore)
+	}
+	if len(afterVals) > 0 {
+		filter.After(afterVals[0])
+	} else if len(beforeVals) > 0 {
+		filter.Before(beforeVals[0])
+	}
 	sortVals := as.getValues(req.Form, "sort")
 	for _, sv := range sortVals {
 		subSortVals := strings.Split(sv, ",")
@@ -147,3 +147,16 @@ func TestBuildFilterLimitLimit(t *testing.T) {
 	_, err := as.buildFilter(req, database.MessageQueryFactory)
 	assert.Regexp(t, "FF10184.*500", err)
 }
+
+func TestBuildFilterCursorAfter(t *testing.T) {
+	testIndividualFilter(t, "after="+database.EncodeCursor(12345)+"&limit=10", " sort=-sequence limit=10 after=12345")
+	testIndividualFilter(t, "after&ascending", " sort=sequence")
+}
+
+func TestBuildFilterCursorBefore(t *testing.T) {
+	testIndividualFilter(t, "before="+database.EncodeCursor(12345), " sort=-sequence before=12345")
+}
+
+func TestBuildFilterCursorAfterAndBefore(t *testing.T) {
+	testFailFilter(t, "after=abc&before=def", "FF10391")
+}
@@ -0,0 +1,54 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/oapispec"
+)
+
+var getNamespaceExport = &oapispec.Route{
+	Name:   "getNamespaceExport",
+	Path:   "namespaces/{ns}/export",
+	Method: http.MethodGet,
+	PathParams: []*oapispec.PathParam{
+		{Name: "ns", Description: i18n.MsgTBD},
+	},
+	QueryParams:     nil,
+	FilterFactory:   nil,
+	Description:     i18n.MsgTBD,
+	JSONInputValue:  nil,
+	JSONOutputValue: func() interface{} { return []byte{} },
+	JSONOutputCodes: []int{http.StatusOK},
+	NoDeadlines:     true,
+	JSONHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
+		ns := r.PP["ns"]
+		// Check the namespace up front, so we can return an error before we start streaming the archive
+		namespace, err := getOr(r.Ctx).GetNamespace(r.Ctx, ns)
+		if err != nil || namespace == nil {
+			return nil, err
+		}
+		reader, writer := io.Pipe()
+		go func() {
+			_ = writer.CloseWithError(getOr(r.Ctx).Archive().Export(r.Ctx, ns, writer))
+		}()
+		return reader, nil
+	},
+}
@@ -0,0 +1,111 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+
+	"github.com/hyperledger/firefly/mocks/archivemocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetNamespaceExport(t *testing.T) {
+	o, r := newTestAdminServer()
+	mar := &archivemocks.Manager{}
+	o.On("Archive").Return(mar)
+	req := httptest.NewRequest("GET", "/admin/api/v1/namespaces/ns1/export", nil)
+	res := httptest.NewRecorder()
+
+	o.On("GetNamespace", mock.Anything, "ns1").Return(&fftypes.Namespace{Name: "ns1"}, nil)
+	mar.On("Export", mock.Anything, "ns1", mock.Anything).Run(func(args mock.Arguments) {
+		_, _ = args[2].(io.Writer).Write([]byte("archive"))
+	}).Return(nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.Equal(t, "application/octet-stream", res.Result().Header.Get("Content-Type"))
+	b, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, "archive", string(b))
+}
+
+func TestGetNamespaceExportNoDeadlines(t *testing.T) {
+	o, r := newTestAdminServer()
+	cp := config.NewPluginConfig("ut")
+	initHTTPConfPrefx(cp, 0)
+	cp.Set(HTTPConfWriteTimeout, "50ms")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hs, err := newHTTPServer(ctx, "ut", r, make(chan error), cp)
+	assert.NoError(t, err)
+	go hs.serveHTTP(ctx)
+
+	mar := &archivemocks.Manager{}
+	o.On("Archive").Return(mar)
+	o.On("GetNamespace", mock.Anything, "ns1").Return(&fftypes.Namespace{Name: "ns1"}, nil)
+	mar.On("Export", mock.Anything, "ns1", mock.Anything).Run(func(args mock.Arguments) {
+		for i := 0; i < 4; i++ {
+			_, _ = args[2].(io.Writer).Write([]byte(fmt.Sprintf("chunk%d", i)))
+			time.Sleep(50 * time.Millisecond)
+		}
+	}).Return(nil)
+
+	// The export takes longer than the write timeout of the server
+	res, err := http.Get(fmt.Sprintf("http://%s/admin/api/v1/namespaces/ns1/export", hs.l.Addr()))
+	assert.NoError(t, err)
+	b, err := ioutil.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "chunk0chunk1chunk2chunk3", string(b))
+}
+
+func TestGetNamespaceExportNotFound(t *testing.T) {
+	o, r := newTestAdminServer()
+	req := httptest.NewRequest("GET", "/admin/api/v1/namespaces/ns1/export", nil)
+	res := httptest.NewRecorder()
+
+	o.On("GetNamespace", mock.Anything, "ns1").Return(nil, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 404, res.Result().StatusCode)
+}
+
+func TestGetNamespaceExportFail(t *testing.T) {
+	o, r := newTestAdminServer()
+	mar := &archivemocks.Manager{}
+	o.On("Archive").Return(mar)
+	req := httptest.NewRequest("GET", "/admin/api/v1/namespaces/ns1/export", nil)
+	res := httptest.NewRecorder()
+
+	o.On("GetNamespace", mock.Anything, "ns1").Return(&fftypes.Namespace{Name: "ns1"}, nil)
+	mar.On("Export", mock.Anything, "ns1", mock.Anything).Return(fmt.Errorf("pop"))
+	r.ServeHTTP(res, req)
+
+	// The status has already been sent by the time the archive fails, so the error is in the body
+	assert.Equal(t, 200, res.Result().StatusCode)
+	b, _ := ioutil.ReadAll(res.Body)
+	assert.Regexp(t, "pop", string(b))
+}
@@ -59,7 +59,29 @@ func TestGetMessagesWithCount(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, resWithCount.Items)
 	assert.Equal(t, int64(0), resWithCount.Count)
-	assert.Equal(t, int64(10), resWithCount.Total)
+	assert.Equal(t, int64(10), *resWithCount.Total)
+}
+
+func TestGetMessagesWithCursors(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/messages?after", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	o.On("GetMessages", mock.Anything, "mynamespace", mock.Anything).
+		Return([]*fftypes.Message{{}}, &database.FilterResult{
+			Cursors: &database.FilterCursors{Next: "next1", Previous: "prev1"},
+		}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	var resWithCursors filterResultsWithCount
+	err := json.NewDecoder(res.Body).Decode(&resWithCursors)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), resWithCursors.Count)
+	assert.Nil(t, resWithCursors.Total)
+	assert.Equal(t, "next1", resWithCursors.Next)
+	assert.Equal(t, "prev1", resWithCursors.Previous)
 }
 
 func TestGetMessagesWithCountAndData(t *testing.T) {
@@ -81,5 +103,5 @@ func TestGetMessagesWithCountAndData(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, resWithCount.Items)
 	assert.Equal(t, int64(0), resWithCount.Count)
-	assert.Equal(t, int64(10), resWithCount.Total)
+	assert.Equal(t, int64(10), *resWithCount.Total)
 }
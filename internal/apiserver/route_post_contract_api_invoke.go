@@ -40,7 +40,7 @@ var postContractAPIInvoke = &oapispec.Route{
 	FilterFactory:   nil,
 	Description:     i18n.MsgTBD,
 	JSONInputValue:  func() interface{} { return &fftypes.ContractCallRequest{} },
-	JSONInputMask:   []string{"Type", "Interface", "Method"},
+	JSONInputMask:   []string{"Type", "Interface", "Method", "Block", "BlockchainEvent"},
 	JSONOutputValue: func() interface{} { return &fftypes.ContractCallResponse{} },
 	JSONOutputCodes: []int{http.StatusOK},
 	JSONHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
@@ -41,6 +41,7 @@ var postContractAPIQuery = &oapispec.Route{
 	JSONInputMask:   []string{"Type", "Interface", "Method"},
 	JSONOutputValue: func() interface{} { return make(map[string]interface{}) },
 	JSONOutputCodes: []int{http.StatusOK},
+	ReadOnly:        true,
 	JSONHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
 		req := r.Input.(*fftypes.ContractCallRequest)
 		req.Type = fftypes.CallTypeQuery
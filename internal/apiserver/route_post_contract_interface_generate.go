@@ -39,6 +39,7 @@ var postContractInterfaceGenerate = &oapispec.Route{
 	JSONInputMask:   nil,
 	JSONOutputValue: func() interface{} { return &fftypes.FFI{} },
 	JSONOutputCodes: []int{http.StatusOK},
+	ReadOnly:        true,
 	JSONHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
 		generationRequest := r.Input.(*fftypes.FFIGenerationRequest)
 		return getOr(r.Ctx).Contracts().GenerateFFI(r.Ctx, r.PP["ns"], generationRequest)
@@ -40,7 +40,7 @@ var postContractInterfaceInvoke = &oapispec.Route{
 	FilterFactory:   nil,
 	Description:     i18n.MsgTBD,
 	JSONInputValue:  func() interface{} { return &fftypes.ContractCallRequest{} },
-	JSONInputMask:   []string{"Type", "Interface"},
+	JSONInputMask:   []string{"Type", "Interface", "Block", "BlockchainEvent"},
 	JSONOutputValue: func() interface{} { return &fftypes.ContractCallResponse{} },
 	JSONOutputCodes: []int{http.StatusOK},
 	JSONHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
@@ -41,6 +41,7 @@ var postContractInterfaceQuery = &oapispec.Route{
 	JSONInputMask:   []string{"Type", "Interface"},
 	JSONOutputValue: func() interface{} { return make(map[string]interface{}) },
 	JSONOutputCodes: []int{http.StatusOK},
+	ReadOnly:        true,
 	JSONHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
 		req := r.Input.(*fftypes.ContractCallRequest)
 		req.Type = fftypes.CallTypeQuery
@@ -39,6 +39,7 @@ var postContractQuery = &oapispec.Route{
 	JSONInputMask:   []string{"Type"},
 	JSONOutputValue: func() interface{} { return make(map[string]interface{}) },
 	JSONOutputCodes: []int{http.StatusOK},
+	ReadOnly:        true,
 	JSONHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
 		req := r.Input.(*fftypes.ContractCallRequest)
 		req.Type = fftypes.CallTypeQuery
@@ -23,6 +23,7 @@ const emptyObjectSchema = `{"type": "object"}`
 var routes = []*oapispec.Route{
 	deleteContractListener,
 	deleteSubscription,
+	deleteSubscriptionDeadLetters,
 	getBatchByID,
 	getBatches,
 	getBlockchainEventByID,
@@ -66,6 +67,9 @@ var routes = []*oapispec.Route{
 	getStatusBatchManager,
 	getStatusPins,
 	getSubscriptionByID,
+	getSubscriptionDeadLetterByID,
+	getSubscriptionDeadLetters,
+	getSubscriptionStatus,
 	getSubscriptions,
 	getTokenAccountPools,
 	getTokenAccounts,
@@ -86,6 +90,7 @@ var routes = []*oapispec.Route{
 	patchUpdateIdentity,
 	postContractAPIInvoke,
 	postContractAPIQuery,
+	postContractDeploy,
 	postContractInterfaceGenerate,
 	postContractInterfaceInvoke,
 	postContractInterfaceQuery,
@@ -106,6 +111,8 @@ var routes = []*oapispec.Route{
 	postNewSubscription,
 	postNodesSelf,
 	postOpRetry,
+	postSubscriptionDeadLetterReplay,
+	postSubscriptionReset,
 	postTokenApproval,
 	postTokenBurn,
 	postTokenMint,
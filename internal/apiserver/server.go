@@ -37,18 +37,22 @@ import (
 	"github.com/ghodss/yaml"
 	"github.com/gorilla/mux"
 
+	"github.com/hyperledger/firefly/internal/auth"
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/events/eifactory"
+	"github.com/hyperledger/firefly/internal/events/sse"
 	"github.com/hyperledger/firefly/internal/events/websockets"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/oapispec"
 	"github.com/hyperledger/firefly/internal/orchestrator"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
 
 type orchestratorContextKey struct{}
+type adminContextKey struct{}
 
 var ffcodeExtractor = regexp.MustCompile(`^(FF\d+):`)
 
@@ -72,15 +76,22 @@ type apiServer struct {
 	apiMaxTimeout      time.Duration
 	metricsEnabled     bool
 	ffiSwaggerGen      oapiffi.FFISwaggerGen
+	auth               auth.Manager
 }
 
 func InitConfig() {
 	initHTTPConfPrefx(apiConfigPrefix, 5000)
+	auth.InitPrefix(apiConfigPrefix.SubPrefix("auth"))
 	initHTTPConfPrefx(adminConfigPrefix, 5001)
 	initHTTPConfPrefx(metricsConfigPrefix, 6000)
 	initMetricsConfPrefix(metricsConfigPrefix)
 }
 
+// AdminURL is the base URL of the admin API of this node, for use by command line tools
+func AdminURL() string {
+	return fmt.Sprintf("%s/admin/api/v1", (&apiServer{}).getPublicURL(adminConfigPrefix, ""))
+}
+
 func NewAPIServer() Server {
 	return &apiServer{
 		defaultFilterLimit: uint64(config.GetUint(config.APIDefaultFilterLimit)),
@@ -103,6 +114,12 @@ func (as *apiServer) Serve(ctx context.Context, o orchestrator.Orchestrator) (er
 	adminErrChan := make(chan error)
 	metricsErrChan := make(chan error)
 
+	// The admin server uses the same credentials as the API server, including in pre-init mode
+	as.auth, err = auth.NewManager(ctx, apiConfigPrefix.SubPrefix("auth"))
+	if err != nil {
+		return err
+	}
+
 	if !o.IsPreInit() {
 		apiHTTPServer, err := newHTTPServer(ctx, "api", as.createMuxRouter(ctx, o), httpErrChan, apiConfigPrefix)
 		if err != nil {
@@ -209,7 +226,7 @@ func (as *apiServer) getParams(req *http.Request, route *oapispec.Route) (queryP
 
 func (as *apiServer) routeHandler(o orchestrator.Orchestrator, apiBaseURL string, route *oapispec.Route) http.HandlerFunc {
 	// Check the mandatory parts are ok at startup time
-	return as.apiWrapper(func(res http.ResponseWriter, req *http.Request) (int, error) {
+	return as.apiWrapper(route, func(res http.ResponseWriter, req *http.Request) (int, error) {
 
 		var jsonInput interface{}
 		if route.JSONInputValue != nil {
@@ -346,13 +363,41 @@ func (as *apiServer) getTimeout(req *http.Request) time.Duration {
 	return reqTimeout
 }
 
-func (as *apiServer) apiWrapper(handler func(res http.ResponseWriter, req *http.Request) (status int, err error)) http.HandlerFunc {
+// authorize resolves the caller when authentication is enabled, and checks it has the role the route
+// requires on the namespace in the path. Routes without a namespace are checked against the "*" grant.
+// Every request to the admin server requires the admin role, other than health probes.
+func (as *apiServer) authorize(req *http.Request, route *oapispec.Route) (*http.Request, error) {
+	if as.auth == nil || (route != nil && route.Unauthenticated) {
+		return req, nil
+	}
+	principal, err := as.auth.Authenticate(req)
+	if err != nil {
+		return req, err
+	}
+	role := auth.RoleWrite
+	if isAdmin, _ := req.Context().Value(adminContextKey{}).(bool); isAdmin {
+		role = auth.RoleAdmin
+	} else if req.Method == http.MethodGet || (route != nil && route.ReadOnly) {
+		role = auth.RoleRead
+	}
+	if err := principal.Authorize(req.Context(), mux.Vars(req)["ns"], role); err != nil {
+		return req, err
+	}
+	return req.WithContext(auth.WithPrincipal(req.Context(), principal)), nil
+}
+
+func (as *apiServer) apiWrapper(route *oapispec.Route, handler func(res http.ResponseWriter, req *http.Request) (status int, err error)) http.HandlerFunc {
 	return func(res http.ResponseWriter, req *http.Request) {
 
 		reqTimeout := as.getTimeout(req)
 		ctx, cancel := context.WithTimeout(req.Context(), reqTimeout)
 		httpReqID := fftypes.ShortID()
 		ctx = log.WithLogField(ctx, "httpreq", httpReqID)
+		spanRoute := req.URL.Path
+		if route != nil {
+			spanRoute = route.Path
+		}
+		ctx, span := tracing.StartHTTPServerSpan(ctx, req, spanRoute)
 		req = req.WithContext(ctx)
 		defer cancel()
 
@@ -360,7 +405,11 @@ func (as *apiServer) apiWrapper(handler func(res http.ResponseWriter, req *http.
 		l := log.L(ctx)
 		l.Infof("--> %s %s", req.Method, req.URL.Path)
 		startTime := time.Now()
-		status, err := handler(res, req)
+		var status int
+		req, err := as.authorize(req, route)
+		if err == nil {
+			status, err = handler(res, req)
+		}
 		durationMS := float64(time.Since(startTime)) / float64(time.Millisecond)
 		if err != nil {
 
@@ -397,6 +446,33 @@ func (as *apiServer) apiWrapper(handler func(res http.ResponseWriter, req *http.
 		} else {
 			l.Infof("<-- %s %s [%d] (%.2fms)", req.Method, req.URL.Path, status, durationMS)
 		}
+		tracing.EndHTTPServerSpan(span, status, err)
+	}
+}
+
+// websocketHandler authenticates the caller before the upgrade. The namespaces are authorized by
+// the websocket transport, as each subscription is started on the connection.
+func (as *apiServer) websocketHandler(ws *websockets.WebSockets) http.HandlerFunc {
+	return as.authenticatedHandler(ws)
+}
+
+// authenticatedHandler authenticates the caller of a handler that is outside of the REST routes,
+// leaving the handler to authorize the namespaces it uses.
+func (as *apiServer) authenticatedHandler(handler http.Handler) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		if as.auth != nil {
+			principal, err := as.auth.Authenticate(req)
+			if err != nil {
+				res.Header().Add("Content-Type", "application/json")
+				res.WriteHeader(http.StatusUnauthorized)
+				_ = json.NewEncoder(res).Encode(&fftypes.RESTError{
+					Error: err.Error(),
+				})
+				return
+			}
+			req = req.WithContext(auth.WithPrincipal(req.Context(), principal))
+		}
+		handler.ServeHTTP(res, req)
 	}
 }
 
@@ -499,45 +575,63 @@ func (as *apiServer) createMuxRouter(ctx context.Context, o orchestrator.Orchest
 		}
 	}
 
-	r.HandleFunc(`/api/v1/namespaces/{ns}/apis/{apiName}/api/swagger{ext:\.yaml|\.json|}`, as.apiWrapper(as.swaggerHandler(as.contractSwaggerGenerator(o, apiBaseURL))))
+	r.HandleFunc(`/api/v1/namespaces/{ns}/apis/{apiName}/api/swagger{ext:\.yaml|\.json|}`, as.apiWrapper(nil, as.swaggerHandler(as.contractSwaggerGenerator(o, apiBaseURL))))
 	r.HandleFunc(`/api/v1/namespaces/{ns}/apis/{apiName}/api`, func(rw http.ResponseWriter, req *http.Request) {
 		url := req.URL.String() + "/swagger.yaml"
-		handler := as.apiWrapper(as.swaggerUIHandler(url))
+		handler := as.apiWrapper(nil, as.swaggerUIHandler(url))
 		handler(rw, req)
 	})
 
-	r.HandleFunc(`/api/swagger{ext:\.yaml|\.json|}`, as.apiWrapper(as.swaggerHandler(as.swaggerGenerator(routes, apiBaseURL))))
-	r.HandleFunc(`/api`, as.apiWrapper(as.swaggerUIHandler(publicURL+"/api/swagger.yaml")))
+	r.HandleFunc(`/api/swagger{ext:\.yaml|\.json|}`, as.apiWrapper(nil, as.swaggerHandler(as.swaggerGenerator(routes, apiBaseURL))))
+	r.HandleFunc(`/api`, as.apiWrapper(nil, as.swaggerUIHandler(publicURL+"/api/swagger.yaml")))
 	r.HandleFunc(`/favicon{any:.*}.png`, favIcons)
 
 	ws, _ := eifactory.GetPlugin(ctx, "websockets")
-	r.HandleFunc(`/ws`, ws.(*websockets.WebSockets).ServeHTTP)
+	r.HandleFunc(`/ws`, as.websocketHandler(ws.(*websockets.WebSockets)))
+	for _, t := range config.GetStringSlice(config.EventTransportsEnabled) {
+		if t == "sse" {
+			sseTransport, _ := eifactory.GetPlugin(ctx, "sse")
+			r.HandleFunc(`/sse`, withoutTimeouts(as.authenticatedHandler(sseTransport.(*sse.SSE)))).Methods(http.MethodGet, http.MethodPost)
+		}
+	}
 
 	uiPath := config.GetString(config.UIPath)
 	if uiPath != "" && config.GetBool(config.UIEnabled) {
 		r.PathPrefix(`/ui`).Handler(newStaticHandler(uiPath, "index.html", `/ui`))
 	}
 
-	r.NotFoundHandler = as.apiWrapper(as.notFoundHandler)
+	r.NotFoundHandler = as.apiWrapper(nil, as.notFoundHandler)
 	return r
 }
 
+// adminMiddleware marks requests as being to the admin server, so they are authorized against the admin role
+func adminMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		next.ServeHTTP(res, req.WithContext(context.WithValue(req.Context(), adminContextKey{}, true)))
+	})
+}
+
 func (as *apiServer) createAdminMuxRouter(o orchestrator.Orchestrator) *mux.Router {
 	r := mux.NewRouter()
 	if as.metricsEnabled {
 		r.Use(metrics.GetAdminServerInstrumentation().Middleware)
 	}
+	r.Use(adminMiddleware)
 
 	publicURL := as.getPublicURL(adminConfigPrefix, "admin")
 	apiBaseURL := fmt.Sprintf("%s/admin/api/v1", publicURL)
 	for _, route := range adminRoutes {
 		if route.JSONHandler != nil {
-			r.HandleFunc(fmt.Sprintf("/admin/api/v1/%s", route.Path), as.routeHandler(o, apiBaseURL, route)).
+			handler := as.routeHandler(o, apiBaseURL, route)
+			if route.NoDeadlines {
+				handler = withoutTimeouts(handler)
+			}
+			r.HandleFunc(fmt.Sprintf("/admin/api/v1/%s", route.Path), handler).
 				Methods(route.Method)
 		}
 	}
-	r.HandleFunc(`/admin/api/swagger{ext:\.yaml|\.json|}`, as.apiWrapper(as.swaggerHandler(as.swaggerGenerator(adminRoutes, apiBaseURL))))
-	r.HandleFunc(`/admin/api`, as.apiWrapper(as.swaggerUIHandler(publicURL+"/api/swagger.yaml")))
+	r.HandleFunc(`/admin/api/swagger{ext:\.yaml|\.json|}`, as.apiWrapper(nil, as.swaggerHandler(as.swaggerGenerator(adminRoutes, apiBaseURL))))
+	r.HandleFunc(`/admin/api`, as.apiWrapper(nil, as.swaggerUIHandler(publicURL+"/api/swagger.yaml")))
 	r.HandleFunc(`/favicon{any:.*}.png`, favIcons)
 
 	return r
@@ -29,11 +29,15 @@ import (
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/gorilla/mux"
+	"github.com/hyperledger/firefly/internal/auth"
 	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/events/websockets"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/oapispec"
+	"github.com/hyperledger/firefly/mocks/authmocks"
 	"github.com/hyperledger/firefly/mocks/contractmocks"
+	"github.com/hyperledger/firefly/mocks/eventsmocks"
 	"github.com/hyperledger/firefly/mocks/oapiffimocks"
 	"github.com/hyperledger/firefly/mocks/orchestratormocks"
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -65,6 +69,14 @@ func newTestAdminServer() (*orchestratormocks.Orchestrator, *mux.Router) {
 	return mor, r
 }
 
+func TestAdminURL(t *testing.T) {
+	config.Reset()
+	InitConfig()
+	adminConfigPrefix.Set(HTTPConfAddress, "127.0.0.1")
+	adminConfigPrefix.Set(HTTPConfPort, 5101)
+	assert.Equal(t, "http://127.0.0.1:5101/admin/api/v1", AdminURL())
+}
+
 func TestStartStopServer(t *testing.T) {
 	config.Reset()
 	metrics.Clear()
@@ -278,7 +290,7 @@ func TestStatusInvalidContentType(t *testing.T) {
 
 func TestNotFound(t *testing.T) {
 	_, as := newTestServer()
-	handler := as.apiWrapper(as.notFoundHandler)
+	handler := as.apiWrapper(nil, as.notFoundHandler)
 	s := httptest.NewServer(http.HandlerFunc(handler))
 	defer s.Close()
 
@@ -342,7 +354,7 @@ func TestBadTimeout(t *testing.T) {
 
 func TestSwaggerUI(t *testing.T) {
 	_, as := newTestServer()
-	handler := as.apiWrapper(as.swaggerUIHandler("http://localhost:5000/api/v1"))
+	handler := as.apiWrapper(nil, as.swaggerUIHandler("http://localhost:5000/api/v1"))
 	s := httptest.NewServer(http.HandlerFunc(handler))
 	defer s.Close()
 
@@ -355,7 +367,7 @@ func TestSwaggerUI(t *testing.T) {
 
 func TestSwaggerYAML(t *testing.T) {
 	_, as := newTestServer()
-	handler := as.apiWrapper(as.swaggerHandler(as.swaggerGenerator(routes, "http://localhost:12345/api/v1")))
+	handler := as.apiWrapper(nil, as.swaggerHandler(as.swaggerGenerator(routes, "http://localhost:12345/api/v1")))
 	s := httptest.NewServer(http.HandlerFunc(handler))
 	defer s.Close()
 
@@ -513,3 +525,213 @@ func TestContractAPISwaggerUI(t *testing.T) {
 	b, _ := ioutil.ReadAll(res.Body)
 	assert.Regexp(t, "html", string(b))
 }
+
+func newTestAuthAPIServer(t *testing.T, grants ...string) (*orchestratormocks.Orchestrator, *authmocks.Manager, *mux.Router) {
+	mor, as := newTestServer()
+	mam := &authmocks.Manager{}
+	as.auth = mam
+	if grants != nil {
+		principal, err := auth.NewPrincipal(context.Background(), "app1", grants)
+		assert.NoError(t, err)
+		mam.On("Authenticate", mock.Anything).Return(principal, nil)
+	}
+	return mor, mam, as.createMuxRouter(context.Background(), mor)
+}
+
+func newTestAuthAdminServer(t *testing.T, grants ...string) (*orchestratormocks.Orchestrator, *mux.Router) {
+	mor, as := newTestServer()
+	mam := &authmocks.Manager{}
+	as.auth = mam
+	principal, err := auth.NewPrincipal(context.Background(), "app1", grants)
+	assert.NoError(t, err)
+	mam.On("Authenticate", mock.Anything).Return(principal, nil)
+	return mor, as.createAdminMuxRouter(mor)
+}
+
+func TestStartAuthFail(t *testing.T) {
+	config.Reset()
+	metrics.Clear()
+	InitConfig()
+	apiConfigPrefix.SubPrefix("auth").Set(auth.AuthConfEnabled, true)
+	as := NewAPIServer()
+	mor := &orchestratormocks.Orchestrator{}
+	// The admin server is authenticated in pre-init mode, so the config is always checked
+	mor.On("IsPreInit").Return(true)
+	err := as.Serve(context.Background(), mor)
+	assert.Regexp(t, "FF10398", err)
+}
+
+func TestAPIAuthUnauthenticated(t *testing.T) {
+	_, mam, r := newTestAuthAPIServer(t)
+	mam.On("Authenticate", mock.Anything).Return(nil, i18n.NewError(context.Background(), i18n.MsgAuthUnauthorized))
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/messages", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 401, res.Result().StatusCode)
+	var resErr fftypes.RESTError
+	json.NewDecoder(res.Body).Decode(&resErr)
+	assert.Regexp(t, "FF10396", resErr.Error)
+}
+
+func TestAPIAuthReadAllowed(t *testing.T) {
+	mor, _, r := newTestAuthAPIServer(t, "ns1:read")
+	mor.On("GetMessages", mock.MatchedBy(func(ctx context.Context) bool {
+		return auth.GetPrincipal(ctx).Name == "app1"
+	}), "ns1", mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/messages", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
+
+func TestAPIAuthWriteForbidden(t *testing.T) {
+	_, _, r := newTestAuthAPIServer(t, "ns1:read")
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/messages/broadcast", bytes.NewReader([]byte(`{}`)))
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 403, res.Result().StatusCode)
+	var resErr fftypes.RESTError
+	json.NewDecoder(res.Body).Decode(&resErr)
+	assert.Regexp(t, "FF10397.*app1.*write.*ns1", resErr.Error)
+}
+
+func TestAPIAuthOtherNamespaceForbidden(t *testing.T) {
+	_, _, r := newTestAuthAPIServer(t, "ns1:write")
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns2/messages", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 403, res.Result().StatusCode)
+}
+
+func TestAPIAuthGlobalRoute(t *testing.T) {
+	mor, _, r := newTestAuthAPIServer(t, "ns1:write")
+	req := httptest.NewRequest("GET", "/api/v1/status", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 403, res.Result().StatusCode)
+
+	mor, _, r = newTestAuthAPIServer(t, "*:read")
+	mor.On("GetStatus", mock.Anything).Return(&fftypes.NodeStatus{}, nil)
+	res = httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
+
+func TestAPIAuthReadOnlyPost(t *testing.T) {
+	mor, _, r := newTestAuthAPIServer(t, "ns1:read")
+	mcm := &contractmocks.Manager{}
+	mor.On("Contracts").Return(mcm)
+	mcm.On("InvokeContract", mock.Anything, "ns1", mock.Anything).Return(map[string]interface{}{}, nil)
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/contracts/query", bytes.NewReader([]byte(`{}`)))
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
+
+func TestAdminAuthWriteForbidden(t *testing.T) {
+	_, r := newTestAuthAdminServer(t, "*:write")
+	req := httptest.NewRequest("GET", "/admin/api/v1/namespaces/ns1/export", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 403, res.Result().StatusCode)
+	var resErr fftypes.RESTError
+	json.NewDecoder(res.Body).Decode(&resErr)
+	assert.Regexp(t, "FF10397.*app1.*admin.*ns1", resErr.Error)
+
+	req = httptest.NewRequest("GET", "/admin/api/swagger.json", nil)
+	res = httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 403, res.Result().StatusCode)
+}
+
+func TestAdminAuthAllowed(t *testing.T) {
+	mor, r := newTestAuthAdminServer(t, "ns1:admin")
+	mor.On("GetNamespace", mock.Anything, "ns1").Return(nil, nil)
+	req := httptest.NewRequest("GET", "/admin/api/v1/namespaces/ns1/export", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 404, res.Result().StatusCode)
+
+	req = httptest.NewRequest("GET", "/admin/api/v1/namespaces/ns2/export", nil)
+	res = httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 403, res.Result().StatusCode)
+}
+
+func TestAdminAuthHealthProbes(t *testing.T) {
+	mor, as := newTestServer()
+	mam := &authmocks.Manager{}
+	as.auth = mam
+	mam.On("Authenticate", mock.Anything).Return(nil, i18n.NewError(context.Background(), i18n.MsgAuthUnauthorized))
+	r := as.createAdminMuxRouter(mor)
+	mor.On("GetReadiness", mock.Anything).Return(&fftypes.NodeHealth{Healthy: true}, nil)
+
+	for _, probe := range []string{"liveness", "readiness"} {
+		req := httptest.NewRequest("GET", "/admin/api/v1/health/"+probe, nil)
+		res := httptest.NewRecorder()
+		r.ServeHTTP(res, req)
+		assert.Equal(t, 200, res.Result().StatusCode)
+	}
+	mam.AssertNotCalled(t, "Authenticate", mock.Anything)
+
+	req := httptest.NewRequest("GET", "/admin/api/v1/namespaces/ns1/export", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 401, res.Result().StatusCode)
+}
+
+func TestWebsocketAuthUnauthenticated(t *testing.T) {
+	_, mam, r := newTestAuthAPIServer(t)
+	mam.On("Authenticate", mock.Anything).Return(nil, i18n.NewError(context.Background(), i18n.MsgAuthUnauthorized))
+	req := httptest.NewRequest("GET", "/ws", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 401, res.Result().StatusCode)
+	var resErr fftypes.RESTError
+	json.NewDecoder(res.Body).Decode(&resErr)
+	assert.Regexp(t, "FF10396", resErr.Error)
+}
+
+func TestWebsocketAuthenticated(t *testing.T) {
+	_, as := newTestServer()
+	mam := &authmocks.Manager{}
+	as.auth = mam
+	principal, err := auth.NewPrincipal(context.Background(), "app1", []string{"ns1:read"})
+	assert.NoError(t, err)
+	mam.On("Authenticate", mock.Anything).Return(principal, nil)
+	ws := &websockets.WebSockets{}
+	wsPrefix := config.NewPluginConfig("ut.websockets")
+	ws.InitPrefix(wsPrefix)
+	ws.Init(context.Background(), wsPrefix, &eventsmocks.Callbacks{})
+	req := httptest.NewRequest("GET", "/ws", nil)
+	res := httptest.NewRecorder()
+	as.websocketHandler(ws)(res, req)
+	// Not an upgrade request, so rejected by the websocket transport after authentication
+	assert.Equal(t, 400, res.Result().StatusCode)
+	mam.AssertExpectations(t)
+}
+
+func TestSSEAuthUnauthenticated(t *testing.T) {
+	_, mam, r := newTestAuthAPIServer(t)
+	mam.On("Authenticate", mock.Anything).Return(nil, i18n.NewError(context.Background(), i18n.MsgAuthUnauthorized))
+	req := httptest.NewRequest("GET", "/sse?namespace=ns1&ephemeral", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 401, res.Result().StatusCode)
+	var resErr fftypes.RESTError
+	json.NewDecoder(res.Body).Decode(&resErr)
+	assert.Regexp(t, "FF10396", resErr.Error)
+}
+
+func TestSSENotEnabled(t *testing.T) {
+	config.Reset()
+	defer config.Reset()
+	config.Set(config.EventTransportsEnabled, []string{"websockets"})
+	_, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/sse?namespace=ns1&ephemeral", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 404, res.Result().StatusCode)
+}
@@ -0,0 +1,268 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+type pageFetcher func(ctx context.Context, filter database.AndFilter) (records []interface{}, res *database.FilterResult, err error)
+
+type archiveWriter struct {
+	tw    *tar.Writer
+	pages map[string]int
+	blobs map[fftypes.Bytes32]bool
+}
+
+func (aw *archiveWriter) writeHeader(name string, size int64) error {
+	return aw.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     size,
+		ModTime:  time.Now(),
+	})
+}
+
+func (aw *archiveWriter) writeEntry(name string, b []byte) error {
+	err := aw.writeHeader(name, int64(len(b)))
+	if err == nil {
+		_, err = aw.tw.Write(b)
+	}
+	return err
+}
+
+func (aw *archiveWriter) writePage(collection string, records []interface{}) error {
+	var buff bytes.Buffer
+	enc := json.NewEncoder(&buff)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	name := fmt.Sprintf("%s/%06d%s", collection, aw.pages[collection], ndjsonSuffix)
+	aw.pages[collection]++
+	return aw.writeEntry(name, buff.Bytes())
+}
+
+func (am *archiveManager) Export(ctx context.Context, ns string, w io.Writer) error {
+	if err := am.checkNamespace(ctx, ns); err != nil {
+		return err
+	}
+
+	aw := &archiveWriter{
+		tw:    tar.NewWriter(w),
+		pages: make(map[string]int),
+		blobs: make(map[fftypes.Bytes32]bool),
+	}
+	manifest, _ := json.Marshal(&fftypes.ArchiveManifest{
+		Version:   archiveVersion,
+		Namespace: ns,
+		Created:   fftypes.Now(),
+	})
+	if err := aw.writeEntry(manifestEntry, manifest); err != nil {
+		return err
+	}
+
+	// Collections are written in dependency order, so that everything a record refers to
+	// has already been imported by the time the record itself is read back
+	exports := []struct {
+		collection string
+		qf         database.QueryFactory
+		fetch      pageFetcher
+	}{
+		{collectionDatatypes, database.DatatypeQueryFactory, am.fetchDatatypes},
+		{collectionFFIs, database.FFIQueryFactory, func(ctx context.Context, filter database.AndFilter) ([]interface{}, *database.FilterResult, error) {
+			return am.fetchFFIs(ctx, ns, filter)
+		}},
+		{collectionContractAPIs, database.ContractAPIQueryFactory, func(ctx context.Context, filter database.AndFilter) ([]interface{}, *database.FilterResult, error) {
+			return am.fetchContractAPIs(ctx, ns, filter)
+		}},
+		{collectionContractListeners, database.ContractListenerQueryFactory, am.fetchContractListeners},
+		{collectionTokenPools, database.TokenPoolQueryFactory, am.fetchTokenPools},
+		{collectionSubscriptions, database.SubscriptionQueryFactory, am.fetchSubscriptions},
+		{collectionData, database.DataQueryFactory, am.fetchData},
+		{collectionMessages, database.MessageQueryFactory, am.fetchMessages},
+	}
+	for _, e := range exports {
+		if err := am.exportCollection(ctx, aw, ns, e.collection, e.qf, e.fetch); err != nil {
+			return err
+		}
+	}
+	return aw.tw.Close()
+}
+
+func (am *archiveManager) exportCollection(ctx context.Context, aw *archiveWriter, ns, collection string, qf database.QueryFactory, fetch pageFetcher) error {
+	cursor := ""
+	for {
+		fb := qf.NewFilter(ctx)
+		filter := fb.And(fb.Eq("namespace", ns))
+		filter.Ascending().After(cursor).Limit(exportPageSize)
+		records, res, err := fetch(ctx, filter)
+		if err != nil {
+			return err
+		}
+		log.L(ctx).Debugf("Exporting %d %s from namespace '%s'", len(records), collection, ns)
+		if len(records) == 0 {
+			return nil
+		}
+		if err := aw.writePage(collection, records); err != nil {
+			return err
+		}
+		if collection == collectionData {
+			if err := am.exportBlobs(ctx, aw, records); err != nil {
+				return err
+			}
+		}
+		if len(records) < exportPageSize || res == nil || res.Cursors == nil {
+			return nil
+		}
+		cursor = res.Cursors.Next
+	}
+}
+
+func (am *archiveManager) exportBlobs(ctx context.Context, aw *archiveWriter, records []interface{}) error {
+	for _, r := range records {
+		d := r.(*fftypes.Data)
+		if d.Blob == nil || d.Blob.Hash == nil || aw.blobs[*d.Blob.Hash] {
+			continue
+		}
+		blob, err := am.database.GetBlobMatchingHash(ctx, d.Blob.Hash)
+		if err != nil {
+			return err
+		}
+		if blob == nil {
+			// The blob might not have been received yet for a private message
+			log.L(ctx).Warnf("Blob %s for data %s has not been received - it will not be exported", d.Blob.Hash, d.ID)
+			continue
+		}
+		if err := am.exportBlob(ctx, aw, d, blob); err != nil {
+			return err
+		}
+		aw.blobs[*d.Blob.Hash] = true
+	}
+	return nil
+}
+
+func (am *archiveManager) exportBlob(ctx context.Context, aw *archiveWriter, d *fftypes.Data, blob *fftypes.Blob) error {
+	reader, err := am.exchange.DownloadBLOB(ctx, blob.PayloadRef)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	if err := aw.writeHeader(fmt.Sprintf("%s/%s", collectionBlobs, d.ID), blob.Size); err != nil {
+		return err
+	}
+	written, err := io.CopyN(aw.tw, reader, blob.Size)
+	if err == io.EOF {
+		return i18n.NewError(ctx, i18n.MsgArchiveBlobSizeMismatch, d.ID, written, blob.Size)
+	}
+	return err
+}
+
+func (am *archiveManager) fetchDatatypes(ctx context.Context, filter database.AndFilter) ([]interface{}, *database.FilterResult, error) {
+	datatypes, res, err := am.database.GetDatatypes(ctx, filter)
+	records := make([]interface{}, len(datatypes))
+	for i, dt := range datatypes {
+		records[i] = dt
+	}
+	return records, res, err
+}
+
+func (am *archiveManager) fetchFFIs(ctx context.Context, ns string, filter database.AndFilter) ([]interface{}, *database.FilterResult, error) {
+	ffis, res, err := am.database.GetFFIs(ctx, ns, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	records := make([]interface{}, len(ffis))
+	for i, ffi := range ffis {
+		// Methods and events are stored separately, but are always imported along with their FFI
+		if ffi.Methods, _, err = am.database.GetFFIMethods(ctx, database.FFIMethodQueryFactory.NewFilter(ctx).Eq("interface", ffi.ID)); err != nil {
+			return nil, nil, err
+		}
+		if ffi.Events, _, err = am.database.GetFFIEvents(ctx, database.FFIEventQueryFactory.NewFilter(ctx).Eq("interface", ffi.ID)); err != nil {
+			return nil, nil, err
+		}
+		records[i] = ffi
+	}
+	return records, res, nil
+}
+
+func (am *archiveManager) fetchContractAPIs(ctx context.Context, ns string, filter database.AndFilter) ([]interface{}, *database.FilterResult, error) {
+	apis, res, err := am.database.GetContractAPIs(ctx, ns, filter)
+	records := make([]interface{}, len(apis))
+	for i, api := range apis {
+		records[i] = api
+	}
+	return records, res, err
+}
+
+func (am *archiveManager) fetchContractListeners(ctx context.Context, filter database.AndFilter) ([]interface{}, *database.FilterResult, error) {
+	listeners, res, err := am.database.GetContractListeners(ctx, filter)
+	records := make([]interface{}, len(listeners))
+	for i, l := range listeners {
+		records[i] = l
+	}
+	return records, res, err
+}
+
+func (am *archiveManager) fetchTokenPools(ctx context.Context, filter database.AndFilter) ([]interface{}, *database.FilterResult, error) {
+	pools, res, err := am.database.GetTokenPools(ctx, filter)
+	records := make([]interface{}, len(pools))
+	for i, pool := range pools {
+		records[i] = pool
+	}
+	return records, res, err
+}
+
+func (am *archiveManager) fetchSubscriptions(ctx context.Context, filter database.AndFilter) ([]interface{}, *database.FilterResult, error) {
+	subs, res, err := am.database.GetSubscriptions(ctx, filter)
+	records := make([]interface{}, len(subs))
+	for i, sub := range subs {
+		records[i] = sub
+	}
+	return records, res, err
+}
+
+func (am *archiveManager) fetchData(ctx context.Context, filter database.AndFilter) ([]interface{}, *database.FilterResult, error) {
+	data, res, err := am.database.GetData(ctx, filter)
+	records := make([]interface{}, len(data))
+	for i, d := range data {
+		records[i] = d
+	}
+	return records, res, err
+}
+
+func (am *archiveManager) fetchMessages(ctx context.Context, filter database.AndFilter) ([]interface{}, *database.FilterResult, error) {
+	msgs, res, err := am.database.GetMessages(ctx, filter)
+	records := make([]interface{}, len(msgs))
+	for i, msg := range msgs {
+		records[i] = msg
+	}
+	return records, res, err
+}
@@ -0,0 +1,361 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type testRecords struct {
+	datatype *fftypes.Datatype
+	ffi      *fftypes.FFI
+	api      *fftypes.ContractAPI
+	listener *fftypes.ContractListener
+	pool     *fftypes.TokenPool
+	sub      *fftypes.Subscription
+	data     *fftypes.Data
+	blob     *fftypes.Blob
+	blobData []byte
+	msg      *fftypes.Message
+}
+
+func newTestRecords(t *testing.T) *testRecords {
+	ctx := context.Background()
+	r := &testRecords{
+		blobData: []byte("hello world"),
+	}
+	r.datatype = &fftypes.Datatype{
+		ID:        fftypes.NewUUID(),
+		Validator: fftypes.ValidatorTypeJSON,
+		Namespace: "ns1",
+		Name:      "dt1",
+		Version:   "1.0",
+		Value:     fftypes.JSONAnyPtr(`{"type":"object"}`),
+	}
+	r.datatype.Hash = r.datatype.Value.Hash()
+	ffiID := fftypes.NewUUID()
+	r.ffi = &fftypes.FFI{
+		ID:        ffiID,
+		Namespace: "ns1",
+		Name:      "ffi1",
+		Version:   "1.0",
+		Methods:   []*fftypes.FFIMethod{{ID: fftypes.NewUUID(), Contract: ffiID, Namespace: "ns1", Name: "set"}},
+		Events:    []*fftypes.FFIEvent{{ID: fftypes.NewUUID(), Contract: ffiID, Namespace: "ns1"}},
+	}
+	r.api = &fftypes.ContractAPI{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Name:      "api1",
+		Interface: &fftypes.FFIReference{ID: ffiID},
+	}
+	r.listener = &fftypes.ContractListener{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Interface: &fftypes.FFIReference{ID: ffiID},
+	}
+	r.pool = &fftypes.TokenPool{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Name:      "pool1",
+	}
+	r.sub = &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Name:      "sub1",
+		},
+		Transport: "websockets",
+	}
+	blobHash := sha256.Sum256(r.blobData)
+	r.blob = &fftypes.Blob{
+		Hash:       (*fftypes.Bytes32)(&blobHash),
+		Size:       int64(len(r.blobData)),
+		PayloadRef: "ns1/blob1",
+	}
+	r.data = &fftypes.Data{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Validator: fftypes.ValidatorTypeJSON,
+		Value:     fftypes.JSONAnyPtr(`{"event":{"info":{"block":"1"}}}`),
+		Blob:      &fftypes.BlobRef{Hash: r.blob.Hash},
+	}
+	var err error
+	r.data.Hash, err = r.data.CalcHash(ctx)
+	assert.NoError(t, err)
+	r.msg = &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			Namespace: "ns1",
+			Type:      fftypes.MessageTypeBroadcast,
+		},
+		Data: fftypes.DataRefs{{ID: r.data.ID, Hash: r.data.Hash}},
+	}
+	err = r.msg.Seal(ctx)
+	assert.NoError(t, err)
+	r.pool.Message = r.msg.Header.ID
+	return r
+}
+
+func mockExport(mdi *databasemocks.Plugin, mdx *dataexchangemocks.Plugin, r *testRecords) {
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(&fftypes.Namespace{Name: "ns1"}, nil)
+	mdi.On("GetDatatypes", mock.Anything, mock.Anything).Return([]*fftypes.Datatype{r.datatype}, &database.FilterResult{}, nil)
+	mdi.On("GetFFIs", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.FFI{{ID: r.ffi.ID, Namespace: "ns1", Name: r.ffi.Name, Version: r.ffi.Version}}, &database.FilterResult{}, nil)
+	mdi.On("GetFFIMethods", mock.Anything, mock.Anything).Return(r.ffi.Methods, &database.FilterResult{}, nil)
+	mdi.On("GetFFIEvents", mock.Anything, mock.Anything).Return(r.ffi.Events, &database.FilterResult{}, nil)
+	mdi.On("GetContractAPIs", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.ContractAPI{r.api}, &database.FilterResult{}, nil)
+	mdi.On("GetContractListeners", mock.Anything, mock.Anything).Return([]*fftypes.ContractListener{r.listener}, &database.FilterResult{}, nil)
+	mdi.On("GetTokenPools", mock.Anything, mock.Anything).Return([]*fftypes.TokenPool{r.pool}, &database.FilterResult{}, nil)
+	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{r.sub}, &database.FilterResult{}, nil)
+	mdi.On("GetData", mock.Anything, mock.Anything).Return(fftypes.DataArray{r.data}, &database.FilterResult{}, nil)
+	mdi.On("GetBlobMatchingHash", mock.Anything, r.blob.Hash).Return(r.blob, nil).Once()
+	mdx.On("DownloadBLOB", mock.Anything, "ns1/blob1").Return(ioutil.NopCloser(bytes.NewReader(r.blobData)), nil)
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{r.msg}, &database.FilterResult{}, nil)
+}
+
+func readTestArchive(t *testing.T, b []byte) map[string]string {
+	entries := make(map[string]string)
+	tr := tar.NewReader(bytes.NewReader(b))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return entries
+		}
+		assert.NoError(t, err)
+		content, err := ioutil.ReadAll(tr)
+		assert.NoError(t, err)
+		entries[hdr.Name] = string(content)
+	}
+}
+
+func TestExportOk(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	r := newTestRecords(t)
+	mockExport(am.database.(*databasemocks.Plugin), am.exchange.(*dataexchangemocks.Plugin), r)
+
+	var buff bytes.Buffer
+	err := am.Export(context.Background(), "ns1", &buff)
+	assert.NoError(t, err)
+
+	entries := readTestArchive(t, buff.Bytes())
+	assert.Len(t, entries, 10)
+	assert.Regexp(t, `"version":1,"namespace":"ns1"`, entries["manifest.json"])
+	assert.Contains(t, entries["datatypes/000000.ndjson"], r.datatype.ID.String())
+	assert.Contains(t, entries["ffis/000000.ndjson"], r.ffi.Methods[0].ID.String())
+	assert.Contains(t, entries["ffis/000000.ndjson"], r.ffi.Events[0].ID.String())
+	assert.Contains(t, entries["contractapis/000000.ndjson"], r.api.ID.String())
+	assert.Contains(t, entries["contractlisteners/000000.ndjson"], r.listener.ID.String())
+	assert.Contains(t, entries["tokenpools/000000.ndjson"], r.pool.ID.String())
+	assert.Contains(t, entries["subscriptions/000000.ndjson"], r.sub.ID.String())
+	assert.Contains(t, entries["data/000000.ndjson"], r.data.Hash.String())
+	assert.Equal(t, "hello world", entries[fmt.Sprintf("blobs/%s", r.data.ID)])
+	assert.Contains(t, entries["messages/000000.ndjson"], r.msg.Hash.String())
+}
+
+func TestExportNamespaceNotFound(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(nil, nil)
+
+	err := am.Export(context.Background(), "ns1", ioutil.Discard)
+	assert.Regexp(t, "FF10187", err)
+}
+
+func TestExportNamespaceFail(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(nil, fmt.Errorf("pop"))
+
+	err := am.Export(context.Background(), "ns1", ioutil.Discard)
+	assert.Regexp(t, "pop", err)
+}
+
+func TestExportPaging(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	aw := &archiveWriter{tw: tar.NewWriter(ioutil.Discard), pages: make(map[string]int)}
+
+	page := make([]*fftypes.Datatype, exportPageSize)
+	for i := range page {
+		page[i] = &fftypes.Datatype{ID: fftypes.NewUUID()}
+	}
+	mdi.On("GetDatatypes", mock.Anything, mock.MatchedBy(func(f database.AndFilter) bool {
+		fi, _ := f.Finalize()
+		return fi.Cursor.After == nil
+	})).Return(page, &database.FilterResult{Cursors: &database.FilterCursors{Next: database.EncodeCursor(100)}}, nil)
+	mdi.On("GetDatatypes", mock.Anything, mock.MatchedBy(func(f database.AndFilter) bool {
+		fi, _ := f.Finalize()
+		return fi.Cursor.After != nil && *fi.Cursor.After == 100
+	})).Return([]*fftypes.Datatype{}, &database.FilterResult{}, nil)
+
+	err := am.exportCollection(context.Background(), aw, "ns1", collectionDatatypes, database.DatatypeQueryFactory, am.fetchDatatypes)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, aw.pages[collectionDatatypes])
+}
+
+func TestExportDatatypesFail(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(&fftypes.Namespace{Name: "ns1"}, nil)
+	mdi.On("GetDatatypes", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := am.Export(context.Background(), "ns1", ioutil.Discard)
+	assert.Regexp(t, "pop", err)
+}
+
+func TestExportFFIMethodsFail(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetFFIs", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.FFI{{ID: fftypes.NewUUID()}}, &database.FilterResult{}, nil)
+	mdi.On("GetFFIMethods", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, _, err := am.fetchFFIs(context.Background(), "ns1", database.FFIQueryFactory.NewFilter(context.Background()).And())
+	assert.Regexp(t, "pop", err)
+}
+
+func TestExportFFIEventsFail(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetFFIs", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.FFI{{ID: fftypes.NewUUID()}}, &database.FilterResult{}, nil)
+	mdi.On("GetFFIMethods", mock.Anything, mock.Anything).Return([]*fftypes.FFIMethod{}, &database.FilterResult{}, nil)
+	mdi.On("GetFFIEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, _, err := am.fetchFFIs(context.Background(), "ns1", database.FFIQueryFactory.NewFilter(context.Background()).And())
+	assert.Regexp(t, "pop", err)
+}
+
+func TestExportFFIsFail(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetFFIs", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, _, err := am.fetchFFIs(context.Background(), "ns1", database.FFIQueryFactory.NewFilter(context.Background()).And())
+	assert.Regexp(t, "pop", err)
+}
+
+func TestExportBlobsSkipMissingAndDuplicates(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	aw := &archiveWriter{tw: tar.NewWriter(ioutil.Discard), blobs: make(map[fftypes.Bytes32]bool)}
+
+	exported := fftypes.NewRandB32()
+	aw.blobs[*exported] = true
+	missing := fftypes.NewRandB32()
+	mdi.On("GetBlobMatchingHash", mock.Anything, missing).Return(nil, nil)
+
+	err := am.exportBlobs(context.Background(), aw, []interface{}{
+		&fftypes.Data{ID: fftypes.NewUUID()},
+		&fftypes.Data{ID: fftypes.NewUUID(), Blob: &fftypes.BlobRef{Hash: exported}},
+		&fftypes.Data{ID: fftypes.NewUUID(), Blob: &fftypes.BlobRef{Hash: missing}},
+	})
+	assert.NoError(t, err)
+}
+
+func TestExportBlobLookupFail(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	aw := &archiveWriter{tw: tar.NewWriter(ioutil.Discard), blobs: make(map[fftypes.Bytes32]bool)}
+	mdi.On("GetBlobMatchingHash", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	err := am.exportBlobs(context.Background(), aw, []interface{}{
+		&fftypes.Data{ID: fftypes.NewUUID(), Blob: &fftypes.BlobRef{Hash: fftypes.NewRandB32()}},
+	})
+	assert.Regexp(t, "pop", err)
+}
+
+func TestExportBlobDownloadFail(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	mdx := am.exchange.(*dataexchangemocks.Plugin)
+	aw := &archiveWriter{tw: tar.NewWriter(ioutil.Discard), blobs: make(map[fftypes.Bytes32]bool)}
+	mdi.On("GetBlobMatchingHash", mock.Anything, mock.Anything).Return(&fftypes.Blob{PayloadRef: "ref1"}, nil)
+	mdx.On("DownloadBLOB", mock.Anything, "ref1").Return(nil, fmt.Errorf("pop"))
+
+	err := am.exportBlobs(context.Background(), aw, []interface{}{
+		&fftypes.Data{ID: fftypes.NewUUID(), Blob: &fftypes.BlobRef{Hash: fftypes.NewRandB32()}},
+	})
+	assert.Regexp(t, "pop", err)
+}
+
+func TestExportBlobShort(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	mdx := am.exchange.(*dataexchangemocks.Plugin)
+	aw := &archiveWriter{tw: tar.NewWriter(ioutil.Discard), blobs: make(map[fftypes.Bytes32]bool)}
+	mdi.On("GetBlobMatchingHash", mock.Anything, mock.Anything).Return(&fftypes.Blob{PayloadRef: "ref1", Size: 100}, nil)
+	mdx.On("DownloadBLOB", mock.Anything, "ref1").Return(ioutil.NopCloser(bytes.NewReader([]byte("short"))), nil)
+
+	err := am.exportBlobs(context.Background(), aw, []interface{}{
+		&fftypes.Data{ID: fftypes.NewUUID(), Blob: &fftypes.BlobRef{Hash: fftypes.NewRandB32()}},
+	})
+	assert.Regexp(t, "FF10431.*5.*100", err)
+}
+
+func TestExportWriteFail(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(&fftypes.Namespace{Name: "ns1"}, nil)
+
+	r, w := io.Pipe()
+	r.Close()
+	err := am.Export(context.Background(), "ns1", w)
+	assert.Regexp(t, "closed pipe", err)
+}
+
+func TestExportPageWriteFail(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	tw := tar.NewWriter(ioutil.Discard)
+	tw.Close()
+	aw := &archiveWriter{tw: tw, pages: make(map[string]int)}
+	mdi.On("GetDatatypes", mock.Anything, mock.Anything).Return([]*fftypes.Datatype{{ID: fftypes.NewUUID()}}, &database.FilterResult{}, nil)
+
+	err := am.exportCollection(context.Background(), aw, "ns1", collectionDatatypes, database.DatatypeQueryFactory, am.fetchDatatypes)
+	assert.Error(t, err)
+}
+
+func TestExportPageBadRecord(t *testing.T) {
+	aw := &archiveWriter{tw: tar.NewWriter(ioutil.Discard), pages: make(map[string]int)}
+	err := aw.writePage(collectionDatatypes, []interface{}{map[bool]bool{false: true}})
+	assert.Error(t, err)
+}
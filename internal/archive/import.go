@@ -0,0 +1,327 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+type recordImporter func(ctx context.Context, dec *json.Decoder) (imported bool, err error)
+
+func (am *archiveManager) Import(ctx context.Context, r io.Reader) (*fftypes.ArchiveImportResult, error) {
+	tr := tar.NewReader(r)
+
+	var manifest fftypes.ArchiveManifest
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgArchiveReadFailed, manifestEntry)
+	}
+	if hdr.Name != manifestEntry || json.NewDecoder(tr).Decode(&manifest) != nil || manifest.Version != archiveVersion {
+		return nil, i18n.NewError(ctx, i18n.MsgArchiveBadManifest, archiveVersion)
+	}
+	// The namespace is part of the hash of every message, so records can only be imported into the namespace they came from
+	ns := manifest.Namespace
+	if err := am.checkNamespace(ctx, ns); err != nil {
+		return nil, err
+	}
+
+	result := &fftypes.ArchiveImportResult{
+		Namespace: ns,
+		Imported:  make(map[string]int),
+		Skipped:   make(map[string]int),
+	}
+	// Token pools are activated once everything else is imported, as the announcement of each pool
+	// holds the details the connector needs, and is imported later with the data of the namespace
+	var importedPools []*fftypes.TokenPool
+	importers := map[string]recordImporter{
+		collectionDatatypes:         am.importDatatype,
+		collectionFFIs:              am.importFFI,
+		collectionContractAPIs:      am.importContractAPI,
+		collectionContractListeners: am.importContractListener,
+		collectionTokenPools: func(ctx context.Context, dec *json.Decoder) (bool, error) {
+			pool, err := am.importTokenPool(ctx, dec)
+			if pool != nil {
+				importedPools = append(importedPools, pool)
+			}
+			return pool != nil, err
+		},
+		collectionSubscriptions: am.importSubscription,
+		collectionData:          am.importData,
+		collectionMessages:      am.importMessage,
+	}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, i18n.WrapError(ctx, err, i18n.MsgArchiveReadFailed, "")
+		}
+		collection := strings.SplitN(hdr.Name, "/", 2)[0]
+		switch {
+		case collection == collectionBlobs:
+			err = am.importBlob(ctx, ns, hdr, tr, result)
+		case importers[collection] != nil && strings.HasSuffix(hdr.Name, ndjsonSuffix):
+			err = am.importPage(ctx, hdr.Name, collection, importers[collection], tr, result)
+		default:
+			err = i18n.NewError(ctx, i18n.MsgArchiveUnknownEntry, hdr.Name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, pool := range importedPools {
+		if err := am.activateTokenPool(ctx, pool); err != nil {
+			return nil, err
+		}
+	}
+	log.L(ctx).Infof("Imported archive into namespace '%s' imported=%v skipped=%v", ns, result.Imported, result.Skipped)
+	return result, nil
+}
+
+func (am *archiveManager) importPage(ctx context.Context, name, collection string, importer recordImporter, r io.Reader, result *fftypes.ArchiveImportResult) error {
+	dec := json.NewDecoder(r)
+	for dec.More() {
+		imported, err := importer(ctx, dec)
+		if err != nil {
+			return err
+		}
+		if imported {
+			result.Imported[collection]++
+		} else {
+			result.Skipped[collection]++
+		}
+	}
+	return nil
+}
+
+func (am *archiveManager) importBlob(ctx context.Context, ns string, hdr *tar.Header, r io.Reader, result *fftypes.ArchiveImportResult) error {
+	id, err := fftypes.ParseUUID(ctx, strings.TrimPrefix(hdr.Name, collectionBlobs+"/"))
+	if err != nil {
+		return i18n.WrapError(ctx, err, i18n.MsgArchiveReadFailed, hdr.Name)
+	}
+	data, err := am.database.GetDataByID(ctx, id, false)
+	if err != nil {
+		return err
+	}
+	if data == nil || data.Blob == nil || data.Blob.Hash == nil {
+		return i18n.NewError(ctx, i18n.MsgArchiveBlobUnknownData, id)
+	}
+	existing, err := am.database.GetBlobMatchingHash(ctx, data.Blob.Hash)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		result.Skipped[collectionBlobs]++
+		return nil
+	}
+
+	hashCalc := sha256.New()
+	payloadRef, uploadHash, _, err := am.exchange.UploadBLOB(ctx, ns, *id, io.TeeReader(r, hashCalc))
+	if err != nil {
+		return err
+	}
+	if hash := fftypes.HashResult(hashCalc); !hash.Equals(data.Blob.Hash) || !uploadHash.Equals(data.Blob.Hash) {
+		return i18n.NewError(ctx, i18n.MsgArchiveHashMismatch, collectionBlobs, id)
+	}
+	err = am.database.InsertBlob(ctx, &fftypes.Blob{
+		Hash:       data.Blob.Hash,
+		Size:       hdr.Size,
+		PayloadRef: payloadRef,
+		Created:    fftypes.Now(),
+	})
+	if err == nil {
+		result.Imported[collectionBlobs]++
+	}
+	return err
+}
+
+func (am *archiveManager) importDatatype(ctx context.Context, dec *json.Decoder) (bool, error) {
+	var dt fftypes.Datatype
+	if err := dec.Decode(&dt); err != nil {
+		return false, i18n.WrapError(ctx, err, i18n.MsgArchiveReadFailed, collectionDatatypes)
+	}
+	existing, err := am.database.GetDatatypeByID(ctx, dt.ID)
+	if err != nil || existing != nil {
+		return false, err
+	}
+	if err := dt.Validate(ctx, true); err != nil {
+		return false, err
+	}
+	return true, am.database.UpsertDatatype(ctx, &dt, false)
+}
+
+func (am *archiveManager) importFFI(ctx context.Context, dec *json.Decoder) (bool, error) {
+	var ffi fftypes.FFI
+	if err := dec.Decode(&ffi); err != nil {
+		return false, i18n.WrapError(ctx, err, i18n.MsgArchiveReadFailed, collectionFFIs)
+	}
+	existing, err := am.database.GetFFIByID(ctx, ffi.ID)
+	if err != nil || existing != nil {
+		return false, err
+	}
+	err = am.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		if err := am.database.UpsertFFI(ctx, &ffi); err != nil {
+			return err
+		}
+		for _, method := range ffi.Methods {
+			if err := am.database.UpsertFFIMethod(ctx, method); err != nil {
+				return err
+			}
+		}
+		for _, event := range ffi.Events {
+			if err := am.database.UpsertFFIEvent(ctx, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return true, err
+}
+
+func (am *archiveManager) importContractAPI(ctx context.Context, dec *json.Decoder) (bool, error) {
+	var api fftypes.ContractAPI
+	if err := dec.Decode(&api); err != nil {
+		return false, i18n.WrapError(ctx, err, i18n.MsgArchiveReadFailed, collectionContractAPIs)
+	}
+	existing, err := am.database.GetContractAPIByID(ctx, api.ID)
+	if err != nil || existing != nil {
+		return false, err
+	}
+	return true, am.database.UpsertContractAPI(ctx, &api)
+}
+
+func (am *archiveManager) importContractListener(ctx context.Context, dec *json.Decoder) (bool, error) {
+	var listener fftypes.ContractListener
+	if err := dec.Decode(&listener); err != nil {
+		return false, i18n.WrapError(ctx, err, i18n.MsgArchiveReadFailed, collectionContractListeners)
+	}
+	existing, err := am.database.GetContractListenerByID(ctx, listener.ID)
+	if err != nil || existing != nil {
+		return false, err
+	}
+	// The listener is registered with the connector of this node, which assigns a new protocol ID
+	input := &fftypes.ContractListenerInput{ContractListener: listener}
+	if err := am.blockchains.ForNamespace(listener.Namespace).AddContractListener(ctx, input); err != nil {
+		return false, err
+	}
+	return true, am.database.UpsertContractListener(ctx, &input.ContractListener)
+}
+
+func (am *archiveManager) importTokenPool(ctx context.Context, dec *json.Decoder) (*fftypes.TokenPool, error) {
+	var pool fftypes.TokenPool
+	if err := dec.Decode(&pool); err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgArchiveReadFailed, collectionTokenPools)
+	}
+	existing, err := am.database.GetTokenPoolByID(ctx, pool.ID)
+	if err != nil || existing != nil {
+		return nil, err
+	}
+	// The pool is confirmed again when the connector reports it is active
+	pool.State = fftypes.TokenPoolStatePending
+	if err := am.database.UpsertTokenPool(ctx, &pool); err != nil {
+		return nil, err
+	}
+	return &pool, nil
+}
+
+// activateTokenPool asks the token connector to start indexing an imported pool, using the details
+// of the blockchain event that created the pool, from the announcement of the pool if it was imported
+func (am *archiveManager) activateTokenPool(ctx context.Context, pool *fftypes.TokenPool) error {
+	var blockchainInfo fftypes.JSONObject
+	announcement, err := am.getPoolAnnouncement(ctx, pool)
+	if err != nil {
+		return err
+	}
+	if announcement != nil && announcement.Event != nil {
+		blockchainInfo = announcement.Event.Info
+	}
+	return am.assets.ActivateTokenPool(ctx, pool, blockchainInfo)
+}
+
+func (am *archiveManager) getPoolAnnouncement(ctx context.Context, pool *fftypes.TokenPool) (*fftypes.TokenPoolAnnouncement, error) {
+	if pool.Message == nil {
+		return nil, nil
+	}
+	msg, err := am.database.GetMessageByID(ctx, pool.Message)
+	if err != nil || msg == nil || len(msg.Data) == 0 {
+		return nil, err
+	}
+	data, err := am.database.GetDataByID(ctx, msg.Data[0].ID, true)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	var announcement fftypes.TokenPoolAnnouncement
+	if err := data.Value.Unmarshal(ctx, &announcement); err != nil {
+		log.L(ctx).Warnf("Invalid announcement for imported token pool %s: %s", pool.ID, err)
+		return nil, nil
+	}
+	return &announcement, nil
+}
+
+func (am *archiveManager) importSubscription(ctx context.Context, dec *json.Decoder) (bool, error) {
+	var sub fftypes.Subscription
+	if err := dec.Decode(&sub); err != nil {
+		return false, i18n.WrapError(ctx, err, i18n.MsgArchiveReadFailed, collectionSubscriptions)
+	}
+	existing, err := am.database.GetSubscriptionByID(ctx, sub.ID)
+	if err != nil || existing != nil {
+		return false, err
+	}
+	return true, am.database.UpsertSubscription(ctx, &sub, false)
+}
+
+func (am *archiveManager) importData(ctx context.Context, dec *json.Decoder) (bool, error) {
+	var data fftypes.Data
+	if err := dec.Decode(&data); err != nil {
+		return false, i18n.WrapError(ctx, err, i18n.MsgArchiveReadFailed, collectionData)
+	}
+	existing, err := am.database.GetDataByID(ctx, data.ID, false)
+	if err != nil || existing != nil {
+		return false, err
+	}
+	hash, err := data.CalcHash(ctx)
+	if err != nil || !hash.Equals(data.Hash) {
+		return false, i18n.NewError(ctx, i18n.MsgArchiveHashMismatch, collectionData, data.ID)
+	}
+	return true, am.database.UpsertData(ctx, &data, database.UpsertOptimizationNew)
+}
+
+func (am *archiveManager) importMessage(ctx context.Context, dec *json.Decoder) (bool, error) {
+	var msg fftypes.Message
+	if err := dec.Decode(&msg); err != nil {
+		return false, i18n.WrapError(ctx, err, i18n.MsgArchiveReadFailed, collectionMessages)
+	}
+	existing, err := am.database.GetMessageByID(ctx, msg.Header.ID)
+	if err != nil || existing != nil {
+		return false, err
+	}
+	if err := msg.Verify(ctx); err != nil {
+		return false, err
+	}
+	return true, am.database.UpsertMessage(ctx, &msg, database.UpsertOptimizationNew)
+}
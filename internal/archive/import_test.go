@@ -0,0 +1,520 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/assetmocks"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type testEntry struct {
+	name    string
+	content string
+}
+
+func buildTestArchive(t *testing.T, entries ...testEntry) *bytes.Buffer {
+	var buff bytes.Buffer
+	tw := tar.NewWriter(&buff)
+	for _, e := range entries {
+		err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: e.name, Mode: 0644, Size: int64(len(e.content))})
+		assert.NoError(t, err)
+		_, err = tw.Write([]byte(e.content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	return &buff
+}
+
+func testManifest() testEntry {
+	return testEntry{manifestEntry, `{"version":1,"namespace":"ns1"}`}
+}
+
+func testPage(collection string, records ...interface{}) testEntry {
+	var buff bytes.Buffer
+	enc := json.NewEncoder(&buff)
+	for _, r := range records {
+		_ = enc.Encode(r)
+	}
+	return testEntry{collection + "/000000.ndjson", buff.String()}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	mdx := am.exchange.(*dataexchangemocks.Plugin)
+	r := newTestRecords(t)
+	mockExport(mdi, mdx, r)
+
+	var buff bytes.Buffer
+	err := am.Export(context.Background(), "ns1", &buff)
+	assert.NoError(t, err)
+
+	mdi.On("GetDatatypeByID", mock.Anything, r.datatype.ID).Return(nil, nil)
+	mdi.On("UpsertDatatype", mock.Anything, mock.MatchedBy(func(dt *fftypes.Datatype) bool {
+		return dt.Hash.Equals(r.datatype.Hash)
+	}), false).Return(nil)
+	mdi.On("GetFFIByID", mock.Anything, r.ffi.ID).Return(nil, nil)
+	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{
+			a[1].(func(context.Context) error)(a[0].(context.Context)),
+		}
+	}
+	mdi.On("UpsertFFI", mock.Anything, mock.MatchedBy(func(ffi *fftypes.FFI) bool {
+		return ffi.ID.Equals(r.ffi.ID)
+	})).Return(nil)
+	mdi.On("UpsertFFIMethod", mock.Anything, mock.MatchedBy(func(m *fftypes.FFIMethod) bool {
+		return m.ID.Equals(r.ffi.Methods[0].ID)
+	})).Return(nil)
+	mdi.On("UpsertFFIEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.FFIEvent) bool {
+		return e.ID.Equals(r.ffi.Events[0].ID)
+	})).Return(nil)
+	mdi.On("GetContractAPIByID", mock.Anything, r.api.ID).Return(nil, nil)
+	mdi.On("UpsertContractAPI", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("GetContractListenerByID", mock.Anything, r.listener.ID).Return(nil, nil)
+	mbi := am.blockchains.ForNamespace("ns1").(*blockchainmocks.Plugin)
+	mbi.On("AddContractListener", mock.Anything, mock.MatchedBy(func(l *fftypes.ContractListenerInput) bool {
+		return l.ID.Equals(r.listener.ID)
+	})).Return(nil).Run(func(args mock.Arguments) {
+		args[1].(*fftypes.ContractListenerInput).ProtocolID = "sub2"
+	})
+	mdi.On("UpsertContractListener", mock.Anything, mock.MatchedBy(func(l *fftypes.ContractListener) bool {
+		return l.ProtocolID == "sub2"
+	})).Return(nil)
+	mdi.On("GetTokenPoolByID", mock.Anything, r.pool.ID).Return(nil, nil)
+	mdi.On("UpsertTokenPool", mock.Anything, mock.MatchedBy(func(p *fftypes.TokenPool) bool {
+		return p.State == fftypes.TokenPoolStatePending
+	})).Return(nil)
+	mdi.On("GetSubscriptionByID", mock.Anything, r.sub.ID).Return(nil, nil)
+	mdi.On("UpsertSubscription", mock.Anything, mock.Anything, false).Return(nil)
+	mdi.On("GetDataByID", mock.Anything, r.data.ID, false).Return(nil, nil).Once()
+	mdi.On("UpsertData", mock.Anything, mock.MatchedBy(func(d *fftypes.Data) bool {
+		return d.Hash.Equals(r.data.Hash)
+	}), database.UpsertOptimizationNew).Return(nil)
+	mdi.On("GetDataByID", mock.Anything, r.data.ID, false).Return(r.data, nil).Once()
+	mdi.On("GetBlobMatchingHash", mock.Anything, r.blob.Hash).Return(nil, nil).Once()
+	mdx.On("UploadBLOB", mock.Anything, "ns1", *r.data.ID, mock.Anything).Return("ns1/blob2", r.blob.Hash, r.blob.Size, nil).Run(func(args mock.Arguments) {
+		b, err := ioutil.ReadAll(args[3].(io.Reader))
+		assert.NoError(t, err)
+		assert.Equal(t, r.blobData, b)
+	})
+	mdi.On("InsertBlob", mock.Anything, mock.MatchedBy(func(b *fftypes.Blob) bool {
+		return b.Hash.Equals(r.blob.Hash) && b.Size == r.blob.Size && b.PayloadRef == "ns1/blob2"
+	})).Return(nil)
+	mdi.On("GetMessageByID", mock.Anything, r.msg.Header.ID).Return(nil, nil).Once()
+	mdi.On("UpsertMessage", mock.Anything, mock.MatchedBy(func(msg *fftypes.Message) bool {
+		return msg.Hash.Equals(r.msg.Hash)
+	}), database.UpsertOptimizationNew).Return(nil)
+	mdi.On("GetMessageByID", mock.Anything, r.msg.Header.ID).Return(r.msg, nil).Once()
+	mdi.On("GetDataByID", mock.Anything, r.data.ID, true).Return(r.data, nil)
+	mam := am.assets.(*assetmocks.Manager)
+	mam.On("ActivateTokenPool", mock.Anything, mock.MatchedBy(func(p *fftypes.TokenPool) bool {
+		return p.ID.Equals(r.pool.ID)
+	}), fftypes.JSONObject{"block": "1"}).Return(nil)
+
+	result, err := am.Import(context.Background(), &buff)
+	assert.NoError(t, err)
+	assert.Equal(t, "ns1", result.Namespace)
+	for _, c := range []string{
+		collectionDatatypes, collectionFFIs, collectionContractAPIs, collectionContractListeners,
+		collectionTokenPools, collectionSubscriptions, collectionData, collectionBlobs, collectionMessages,
+	} {
+		assert.Equal(t, 1, result.Imported[c], c)
+	}
+	assert.Empty(t, result.Skipped)
+}
+
+func TestImportSkipExisting(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	r := newTestRecords(t)
+
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(&fftypes.Namespace{Name: "ns1"}, nil)
+	mdi.On("GetDatatypeByID", mock.Anything, r.datatype.ID).Return(r.datatype, nil)
+	mdi.On("GetFFIByID", mock.Anything, r.ffi.ID).Return(r.ffi, nil)
+	mdi.On("GetContractAPIByID", mock.Anything, r.api.ID).Return(r.api, nil)
+	mdi.On("GetContractListenerByID", mock.Anything, r.listener.ID).Return(r.listener, nil)
+	mdi.On("GetTokenPoolByID", mock.Anything, r.pool.ID).Return(r.pool, nil)
+	mdi.On("GetSubscriptionByID", mock.Anything, r.sub.ID).Return(r.sub, nil)
+	mdi.On("GetDataByID", mock.Anything, r.data.ID, false).Return(r.data, nil)
+	mdi.On("GetBlobMatchingHash", mock.Anything, r.blob.Hash).Return(r.blob, nil)
+	mdi.On("GetMessageByID", mock.Anything, r.msg.Header.ID).Return(r.msg, nil)
+
+	result, err := am.Import(context.Background(), buildTestArchive(t,
+		testManifest(),
+		testPage(collectionDatatypes, r.datatype),
+		testPage(collectionFFIs, r.ffi),
+		testPage(collectionContractAPIs, r.api),
+		testPage(collectionContractListeners, r.listener),
+		testPage(collectionTokenPools, r.pool),
+		testPage(collectionSubscriptions, r.sub),
+		testPage(collectionData, r.data),
+		testEntry{fmt.Sprintf("blobs/%s", r.data.ID), string(r.blobData)},
+		testPage(collectionMessages, r.msg),
+	))
+	assert.NoError(t, err)
+	assert.Empty(t, result.Imported)
+	assert.Len(t, result.Skipped, 9)
+}
+
+func TestImportNotTar(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+
+	_, err := am.Import(context.Background(), bytes.NewReader([]byte("not a tar")))
+	assert.Regexp(t, "FF10427", err)
+}
+
+func TestImportBadManifest(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+
+	_, err := am.Import(context.Background(), buildTestArchive(t, testEntry{manifestEntry, `{"version":2}`}))
+	assert.Regexp(t, "FF10428", err)
+}
+
+func TestImportNamespaceNotFound(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(nil, nil)
+
+	_, err := am.Import(context.Background(), buildTestArchive(t, testManifest()))
+	assert.Regexp(t, "FF10187", err)
+}
+
+func TestImportUnknownEntry(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(&fftypes.Namespace{Name: "ns1"}, nil)
+
+	_, err := am.Import(context.Background(), buildTestArchive(t, testManifest(), testEntry{"events/000000.ndjson", "{}"}))
+	assert.Regexp(t, "FF10429.*events", err)
+}
+
+func TestImportTruncated(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(&fftypes.Namespace{Name: "ns1"}, nil)
+
+	b := buildTestArchive(t, testManifest(), testEntry{"datatypes/000000.ndjson", "{}"}).Bytes()
+	_, err := am.Import(context.Background(), bytes.NewReader(b[0:1200]))
+	assert.Regexp(t, "FF10427", err)
+}
+
+func TestImportBadRecords(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(&fftypes.Namespace{Name: "ns1"}, nil)
+
+	for _, c := range []string{
+		collectionDatatypes, collectionFFIs, collectionContractAPIs, collectionContractListeners,
+		collectionTokenPools, collectionSubscriptions, collectionData, collectionMessages,
+	} {
+		_, err := am.Import(context.Background(), buildTestArchive(t, testManifest(), testEntry{c + "/000000.ndjson", "[]"}))
+		assert.Regexp(t, "FF10427.*"+c, err)
+	}
+}
+
+func TestImportLookupFail(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(&fftypes.Namespace{Name: "ns1"}, nil)
+	mdi.On("GetDatatypeByID", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	_, err := am.Import(context.Background(), buildTestArchive(t, testManifest(), testPage(collectionDatatypes, &fftypes.Datatype{ID: fftypes.NewUUID()})))
+	assert.Regexp(t, "pop", err)
+}
+
+func TestImportDatatypeBadHash(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	r := newTestRecords(t)
+	r.datatype.Hash = fftypes.NewRandB32()
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(&fftypes.Namespace{Name: "ns1"}, nil)
+	mdi.On("GetDatatypeByID", mock.Anything, r.datatype.ID).Return(nil, nil)
+
+	_, err := am.Import(context.Background(), buildTestArchive(t, testManifest(), testPage(collectionDatatypes, r.datatype)))
+	assert.Regexp(t, "FF10201", err)
+}
+
+func TestImportDataBadHash(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	r := newTestRecords(t)
+	r.data.Hash = fftypes.NewRandB32()
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(&fftypes.Namespace{Name: "ns1"}, nil)
+	mdi.On("GetDataByID", mock.Anything, r.data.ID, false).Return(nil, nil)
+
+	_, err := am.Import(context.Background(), buildTestArchive(t, testManifest(), testPage(collectionData, r.data)))
+	assert.Regexp(t, "FF10430.*data", err)
+}
+
+func TestImportMessageBadHash(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	r := newTestRecords(t)
+	r.msg.Hash = fftypes.NewRandB32()
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(&fftypes.Namespace{Name: "ns1"}, nil)
+	mdi.On("GetMessageByID", mock.Anything, r.msg.Header.ID).Return(nil, nil)
+
+	_, err := am.Import(context.Background(), buildTestArchive(t, testManifest(), testPage(collectionMessages, r.msg)))
+	assert.Regexp(t, "FF10146", err)
+}
+
+func TestImportFFIEventFail(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	r := newTestRecords(t)
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(&fftypes.Namespace{Name: "ns1"}, nil)
+	mdi.On("GetFFIByID", mock.Anything, r.ffi.ID).Return(nil, nil)
+	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{
+			a[1].(func(context.Context) error)(a[0].(context.Context)),
+		}
+	}
+	mdi.On("UpsertFFI", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("UpsertFFIMethod", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("UpsertFFIEvent", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := am.Import(context.Background(), buildTestArchive(t, testManifest(), testPage(collectionFFIs, r.ffi)))
+	assert.Regexp(t, "pop", err)
+}
+
+func TestImportFFIMethodFail(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	r := newTestRecords(t)
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(&fftypes.Namespace{Name: "ns1"}, nil)
+	mdi.On("GetFFIByID", mock.Anything, r.ffi.ID).Return(nil, nil)
+	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{
+			a[1].(func(context.Context) error)(a[0].(context.Context)),
+		}
+	}
+	mdi.On("UpsertFFI", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("UpsertFFIMethod", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := am.Import(context.Background(), buildTestArchive(t, testManifest(), testPage(collectionFFIs, r.ffi)))
+	assert.Regexp(t, "pop", err)
+}
+
+func TestImportFFIFail(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	r := newTestRecords(t)
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(&fftypes.Namespace{Name: "ns1"}, nil)
+	mdi.On("GetFFIByID", mock.Anything, r.ffi.ID).Return(nil, nil)
+	mdi.On("RunAsGroup", mock.Anything, mock.Anything).Return(nil).Run(func(a mock.Arguments) {
+		err := a[1].(func(context.Context) error)(a[0].(context.Context))
+		assert.Regexp(t, "pop", err)
+	})
+	mdi.On("UpsertFFI", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := am.Import(context.Background(), buildTestArchive(t, testManifest(), testPage(collectionFFIs, r.ffi)))
+	assert.NoError(t, err)
+}
+
+func TestImportBlobBadName(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(&fftypes.Namespace{Name: "ns1"}, nil)
+
+	_, err := am.Import(context.Background(), buildTestArchive(t, testManifest(), testEntry{"blobs/bad", "hello"}))
+	assert.Regexp(t, "FF10427.*blobs/bad", err)
+}
+
+func TestImportBlobUnknownData(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	dataID := fftypes.NewUUID()
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(&fftypes.Namespace{Name: "ns1"}, nil)
+	mdi.On("GetDataByID", mock.Anything, dataID, false).Return(&fftypes.Data{ID: dataID}, nil)
+
+	_, err := am.Import(context.Background(), buildTestArchive(t, testManifest(), testEntry{fmt.Sprintf("blobs/%s", dataID), "hello"}))
+	assert.Regexp(t, "FF10432", err)
+}
+
+func TestImportBlobDataLookupFail(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	dataID := fftypes.NewUUID()
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(&fftypes.Namespace{Name: "ns1"}, nil)
+	mdi.On("GetDataByID", mock.Anything, dataID, false).Return(nil, fmt.Errorf("pop"))
+
+	_, err := am.Import(context.Background(), buildTestArchive(t, testManifest(), testEntry{fmt.Sprintf("blobs/%s", dataID), "hello"}))
+	assert.Regexp(t, "pop", err)
+}
+
+func TestImportBlobLookupFail(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	r := newTestRecords(t)
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(&fftypes.Namespace{Name: "ns1"}, nil)
+	mdi.On("GetDataByID", mock.Anything, r.data.ID, false).Return(r.data, nil)
+	mdi.On("GetBlobMatchingHash", mock.Anything, r.blob.Hash).Return(nil, fmt.Errorf("pop"))
+
+	_, err := am.Import(context.Background(), buildTestArchive(t, testManifest(), testEntry{fmt.Sprintf("blobs/%s", r.data.ID), "hello world"}))
+	assert.Regexp(t, "pop", err)
+}
+
+func TestImportBlobUploadFail(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	mdx := am.exchange.(*dataexchangemocks.Plugin)
+	r := newTestRecords(t)
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(&fftypes.Namespace{Name: "ns1"}, nil)
+	mdi.On("GetDataByID", mock.Anything, r.data.ID, false).Return(r.data, nil)
+	mdi.On("GetBlobMatchingHash", mock.Anything, r.blob.Hash).Return(nil, nil)
+	mdx.On("UploadBLOB", mock.Anything, "ns1", *r.data.ID, mock.Anything).Return("", nil, int64(0), fmt.Errorf("pop"))
+
+	_, err := am.Import(context.Background(), buildTestArchive(t, testManifest(), testEntry{fmt.Sprintf("blobs/%s", r.data.ID), "hello world"}))
+	assert.Regexp(t, "pop", err)
+}
+
+func TestImportBlobBadHash(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	mdx := am.exchange.(*dataexchangemocks.Plugin)
+	r := newTestRecords(t)
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(&fftypes.Namespace{Name: "ns1"}, nil)
+	mdi.On("GetDataByID", mock.Anything, r.data.ID, false).Return(r.data, nil)
+	mdi.On("GetBlobMatchingHash", mock.Anything, r.blob.Hash).Return(nil, nil)
+	mdx.On("UploadBLOB", mock.Anything, "ns1", *r.data.ID, mock.Anything).Return("ref1", r.blob.Hash, r.blob.Size, nil).Run(func(args mock.Arguments) {
+		_, _ = ioutil.ReadAll(args[3].(io.Reader))
+	})
+
+	_, err := am.Import(context.Background(), buildTestArchive(t, testManifest(), testEntry{fmt.Sprintf("blobs/%s", r.data.ID), "tampered"}))
+	assert.Regexp(t, "FF10430.*blobs", err)
+}
+
+func TestImportContractListenerFail(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	mbi := am.blockchains.ForNamespace("ns1").(*blockchainmocks.Plugin)
+	r := newTestRecords(t)
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(&fftypes.Namespace{Name: "ns1"}, nil)
+	mdi.On("GetContractListenerByID", mock.Anything, r.listener.ID).Return(nil, nil)
+	mbi.On("AddContractListener", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := am.Import(context.Background(), buildTestArchive(t, testManifest(), testPage(collectionContractListeners, r.listener)))
+	assert.Regexp(t, "pop", err)
+}
+
+func TestImportTokenPoolFail(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	r := newTestRecords(t)
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(&fftypes.Namespace{Name: "ns1"}, nil)
+	mdi.On("GetTokenPoolByID", mock.Anything, r.pool.ID).Return(nil, nil)
+	mdi.On("UpsertTokenPool", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := am.Import(context.Background(), buildTestArchive(t, testManifest(), testPage(collectionTokenPools, r.pool)))
+	assert.Regexp(t, "pop", err)
+}
+
+func TestImportTokenPoolActivateFail(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	mam := am.assets.(*assetmocks.Manager)
+	r := newTestRecords(t)
+	r.pool.Message = nil
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(&fftypes.Namespace{Name: "ns1"}, nil)
+	mdi.On("GetTokenPoolByID", mock.Anything, r.pool.ID).Return(nil, nil)
+	mdi.On("UpsertTokenPool", mock.Anything, mock.Anything).Return(nil)
+	mam.On("ActivateTokenPool", mock.Anything, mock.Anything, fftypes.JSONObject(nil)).Return(fmt.Errorf("pop"))
+
+	_, err := am.Import(context.Background(), buildTestArchive(t, testManifest(), testPage(collectionTokenPools, r.pool)))
+	assert.Regexp(t, "pop", err)
+}
+
+func TestImportTokenPoolAnnouncementLookupFail(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	r := newTestRecords(t)
+	mdi.On("GetNamespace", mock.Anything, "ns1").Return(&fftypes.Namespace{Name: "ns1"}, nil)
+	mdi.On("GetTokenPoolByID", mock.Anything, r.pool.ID).Return(nil, nil)
+	mdi.On("UpsertTokenPool", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("GetMessageByID", mock.Anything, r.msg.Header.ID).Return(nil, fmt.Errorf("pop"))
+
+	_, err := am.Import(context.Background(), buildTestArchive(t, testManifest(), testPage(collectionTokenPools, r.pool)))
+	assert.Regexp(t, "pop", err)
+}
+
+func TestActivateTokenPoolAnnouncementMissing(t *testing.T) {
+	am, done := newTestArchiveManager(t)
+	defer done()
+	mdi := am.database.(*databasemocks.Plugin)
+	mam := am.assets.(*assetmocks.Manager)
+	r := newTestRecords(t)
+	badData := &fftypes.Data{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"not an announcement"`)}
+	noDataMsg := &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}}
+	badDataMsg := &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}, Data: fftypes.DataRefs{{ID: badData.ID}}}
+	missingDataMsg := &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}, Data: fftypes.DataRefs{{ID: fftypes.NewUUID()}}}
+	mdi.On("GetMessageByID", mock.Anything, noDataMsg.Header.ID).Return(noDataMsg, nil)
+	mdi.On("GetMessageByID", mock.Anything, badDataMsg.Header.ID).Return(badDataMsg, nil)
+	mdi.On("GetMessageByID", mock.Anything, missingDataMsg.Header.ID).Return(missingDataMsg, nil)
+	mdi.On("GetDataByID", mock.Anything, badData.ID, true).Return(badData, nil)
+	mdi.On("GetDataByID", mock.Anything, missingDataMsg.Data[0].ID, true).Return(nil, nil)
+	mam.On("ActivateTokenPool", mock.Anything, r.pool, fftypes.JSONObject(nil)).Return(nil)
+
+	// The pool is still activated, without the details of the creation event
+	for _, msg := range []*fftypes.Message{noDataMsg, badDataMsg, missingDataMsg} {
+		r.pool.Message = msg.Header.ID
+		err := am.activateTokenPool(context.Background(), r.pool)
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"context"
+	"io"
+
+	"github.com/hyperledger/firefly/internal/assets"
+	"github.com/hyperledger/firefly/internal/blockchain/birouter"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/dataexchange"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// Manager exports the definitions, messages, data and blobs of a namespace to a streaming archive,
+// and imports them back into this or another node.
+//
+// The archive is a tar stream. The first entry is a JSON manifest, followed by pages of newline
+// delimited JSON records for each collection in dependency order, and the raw content of each blob
+// immediately after the page of data that references it. Records keep their original IDs and hashes,
+// so an import is idempotent - anything that already exists is skipped.
+//
+// Imported contract listeners are registered with the blockchain connector of this node, and imported
+// token pools are activated with the token connector, so that events continue to be indexed for them.
+type Manager interface {
+	Export(ctx context.Context, ns string, w io.Writer) error
+	Import(ctx context.Context, r io.Reader) (*fftypes.ArchiveImportResult, error)
+}
+
+const (
+	archiveVersion = 1
+	exportPageSize = 100
+	manifestEntry  = "manifest.json"
+	ndjsonSuffix   = ".ndjson"
+)
+
+const (
+	collectionDatatypes         = "datatypes"
+	collectionFFIs              = "ffis"
+	collectionContractAPIs      = "contractapis"
+	collectionContractListeners = "contractlisteners"
+	collectionTokenPools        = "tokenpools"
+	collectionSubscriptions     = "subscriptions"
+	collectionData              = "data"
+	collectionBlobs             = "blobs"
+	collectionMessages          = "messages"
+)
+
+type archiveManager struct {
+	database    database.Plugin
+	exchange    dataexchange.Plugin
+	blockchains birouter.Router
+	assets      assets.Manager
+}
+
+func NewArchiveManager(ctx context.Context, di database.Plugin, dx dataexchange.Plugin, br birouter.Router, am assets.Manager) (Manager, error) {
+	if di == nil || dx == nil || br == nil || am == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
+	}
+	return &archiveManager{
+		database:    di,
+		exchange:    dx,
+		blockchains: br,
+		assets:      am,
+	}, nil
+}
+
+func (am *archiveManager) checkNamespace(ctx context.Context, ns string) error {
+	namespace, err := am.database.GetNamespace(ctx, ns)
+	if err != nil {
+		return err
+	}
+	if namespace == nil {
+		return i18n.NewError(ctx, i18n.MsgNamespaceNotExist)
+	}
+	return nil
+}
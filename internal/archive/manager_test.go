@@ -0,0 +1,52 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/assetmocks"
+	"github.com/hyperledger/firefly/mocks/biroutermocks"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestArchiveManager(t *testing.T) (*archiveManager, func()) {
+	mdi := &databasemocks.Plugin{}
+	mdx := &dataexchangemocks.Plugin{}
+	mbi := &blockchainmocks.Plugin{}
+	mbr := &biroutermocks.Router{}
+	mbr.On("ForNamespace", mock.Anything).Return(mbi).Maybe()
+	mam := &assetmocks.Manager{}
+	am, err := NewArchiveManager(context.Background(), mdi, mdx, mbr, mam)
+	assert.NoError(t, err)
+	return am.(*archiveManager), func() {
+		mdi.AssertExpectations(t)
+		mdx.AssertExpectations(t)
+		mbi.AssertExpectations(t)
+		mam.AssertExpectations(t)
+	}
+}
+
+func TestNewArchiveManagerMissingDeps(t *testing.T) {
+	_, err := NewArchiveManager(context.Background(), nil, nil, nil, nil)
+	assert.Regexp(t, "FF10128", err)
+}
@@ -0,0 +1,113 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/websocket"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+)
+
+// Manager authenticates the callers of the API server
+type Manager interface {
+	// Authenticate resolves the principal from the API key or JWT bearer token on the request
+	Authenticate(req *http.Request) (*Principal, error)
+}
+
+type authManager struct {
+	apiKeys map[[32]byte]*Principal
+	jwt     *jwtVerifier
+}
+
+// NewManager returns nil if authentication is not enabled in the supplied config
+func NewManager(ctx context.Context, prefix config.Prefix) (Manager, error) {
+	if !prefix.GetBool(AuthConfEnabled) {
+		return nil, nil
+	}
+	am := &authManager{
+		apiKeys: make(map[[32]byte]*Principal),
+	}
+	for i, apiKey := range prefix.GetObjectArray(AuthConfAPIKeys) {
+		name := apiKey.GetString("name")
+		key := apiKey.GetString("key")
+		if name == "" || key == "" {
+			return nil, i18n.NewError(ctx, i18n.MsgAuthInvalidConfig, fmt.Sprintf("apiKeys[%d] requires a name and a key", i))
+		}
+		// Keys are held as hashes, so lookup time does not depend on how much of a key matches
+		hash := sha256.Sum256([]byte(key))
+		if _, exists := am.apiKeys[hash]; exists {
+			return nil, i18n.NewError(ctx, i18n.MsgAuthInvalidConfig, fmt.Sprintf("apiKeys[%d] duplicates an earlier key", i))
+		}
+		p, err := NewPrincipal(ctx, name, apiKey.GetStringArray("grants"))
+		if err != nil {
+			return nil, err
+		}
+		am.apiKeys[hash] = p
+	}
+	if prefix.GetString(AuthConfJWTJWKSFile) != "" {
+		jv, err := newJWTVerifier(ctx, prefix)
+		if err != nil {
+			return nil, err
+		}
+		am.jwt = jv
+	}
+	if len(am.apiKeys) == 0 && am.jwt == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgAuthInvalidConfig, "no API keys or JWKS file configured")
+	}
+	return am, nil
+}
+
+// getToken extracts the credential from the Authorization header. Browsers cannot set headers
+// on a WebSocket upgrade, so an "access_token" query parameter is also accepted for those.
+func getToken(req *http.Request) string {
+	authHeader := req.Header.Get("Authorization")
+	if len(authHeader) > 7 && strings.EqualFold(authHeader[0:7], "bearer ") {
+		return strings.TrimSpace(authHeader[7:])
+	}
+	if websocket.IsWebSocketUpgrade(req) {
+		return req.URL.Query().Get("access_token")
+	}
+	return ""
+}
+
+func (am *authManager) Authenticate(req *http.Request) (*Principal, error) {
+	ctx := req.Context()
+	token := getToken(req)
+	if token == "" {
+		return nil, i18n.NewError(ctx, i18n.MsgAuthUnauthorized)
+	}
+	if p, ok := am.apiKeys[sha256.Sum256([]byte(token))]; ok {
+		return p, nil
+	}
+	if am.jwt != nil && strings.Count(token, ".") == 2 {
+		p, err := am.jwt.verify(ctx, token)
+		if err != nil {
+			log.L(ctx).Warnf("JWT authentication failed: %s", err)
+			return nil, err
+		}
+		return p, nil
+	}
+	log.L(ctx).Warnf("Authentication failed: unknown API key")
+	return nil, i18n.NewError(ctx, i18n.MsgAuthUnauthorized)
+}
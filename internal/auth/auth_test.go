@@ -0,0 +1,139 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAuthPrefix() config.Prefix {
+	config.Reset()
+	prefix := config.NewPluginConfig("auth")
+	InitPrefix(prefix)
+	prefix.Set(AuthConfEnabled, true)
+	return prefix
+}
+
+func newTestManager(t *testing.T) (Manager, *testKeys) {
+	prefix := newTestAuthPrefix()
+	tk := newTestKeys(t)
+	prefix.Set(AuthConfAPIKeys, fftypes.JSONObjectArray{
+		{"name": "app1", "key": "key1", "grants": []interface{}{"ns1:write"}},
+		{"name": "app2", "key": "key2", "grants": []interface{}{"*:read"}},
+	})
+	prefix.Set(AuthConfJWTJWKSFile, writeJWKS(t, tk.jwks()))
+	am, err := NewManager(context.Background(), prefix)
+	assert.NoError(t, err)
+	return am, tk
+}
+
+func TestNewManagerDisabled(t *testing.T) {
+	config.Reset()
+	prefix := config.NewPluginConfig("auth")
+	InitPrefix(prefix)
+	am, err := NewManager(context.Background(), prefix)
+	assert.NoError(t, err)
+	assert.Nil(t, am)
+}
+
+func TestNewManagerNothingConfigured(t *testing.T) {
+	_, err := NewManager(context.Background(), newTestAuthPrefix())
+	assert.Regexp(t, "FF10398.*no API keys", err)
+}
+
+func TestNewManagerBadAPIKeys(t *testing.T) {
+	prefix := newTestAuthPrefix()
+	prefix.Set(AuthConfAPIKeys, fftypes.JSONObjectArray{{"name": "app1"}})
+	_, err := NewManager(context.Background(), prefix)
+	assert.Regexp(t, `FF10398.*apiKeys\[0\]`, err)
+
+	prefix.Set(AuthConfAPIKeys, fftypes.JSONObjectArray{
+		{"name": "app1", "key": "key1"},
+		{"name": "app2", "key": "key1"},
+	})
+	_, err = NewManager(context.Background(), prefix)
+	assert.Regexp(t, `FF10398.*apiKeys\[1\] duplicates`, err)
+
+	prefix.Set(AuthConfAPIKeys, fftypes.JSONObjectArray{
+		{"name": "app1", "key": "key1", "grants": []interface{}{"ns1:owner"}},
+	})
+	_, err = NewManager(context.Background(), prefix)
+	assert.Regexp(t, "FF10400", err)
+}
+
+func TestNewManagerBadJWKS(t *testing.T) {
+	prefix := newTestAuthPrefix()
+	prefix.Set(AuthConfJWTJWKSFile, "/does/not/exist.json")
+	_, err := NewManager(context.Background(), prefix)
+	assert.Regexp(t, "FF10398", err)
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	am, _ := newTestManager(t)
+	req := httptest.NewRequest("GET", "/api/v1/status", nil)
+	req.Header.Set("Authorization", "Bearer key1")
+	p, err := am.Authenticate(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "app1", p.Name)
+	assert.NoError(t, p.Authorize(req.Context(), "ns1", RoleWrite))
+
+	req.Header.Set("Authorization", "bearer key3")
+	_, err = am.Authenticate(req)
+	assert.Regexp(t, "FF10396", err)
+}
+
+func TestAuthenticateJWT(t *testing.T) {
+	am, tk := newTestManager(t)
+	req := httptest.NewRequest("GET", "/api/v1/status", nil)
+	req.Header.Set("Authorization", "Bearer "+tk.sign(t, "ES256", "ec1", validClaims()))
+	p, err := am.Authenticate(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "user1", p.Name)
+
+	claims := validClaims()
+	delete(claims, "exp")
+	req.Header.Set("Authorization", "Bearer "+tk.sign(t, "ES256", "ec1", claims))
+	_, err = am.Authenticate(req)
+	assert.Regexp(t, "FF10399", err)
+}
+
+func TestAuthenticateMissingToken(t *testing.T) {
+	am, _ := newTestManager(t)
+	req := httptest.NewRequest("GET", "/api/v1/status?access_token=key1", nil)
+	_, err := am.Authenticate(req)
+	assert.Regexp(t, "FF10396", err)
+
+	req.Header.Set("Authorization", "Basic a2V5MTo=")
+	_, err = am.Authenticate(req)
+	assert.Regexp(t, "FF10396", err)
+}
+
+func TestAuthenticateWebSocketQueryParam(t *testing.T) {
+	am, _ := newTestManager(t)
+	req := httptest.NewRequest("GET", "/ws?access_token=key2", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	p, err := am.Authenticate(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "app2", p.Name)
+}
@@ -0,0 +1,51 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"github.com/hyperledger/firefly/internal/config"
+)
+
+const (
+	// AuthConfEnabled whether API key / JWT authentication is required on the server
+	AuthConfEnabled = "enabled"
+	// AuthConfAPIKeys a list of static API keys, each with a "name", a "key" and a list of "grants"
+	AuthConfAPIKeys = "apiKeys"
+	// AuthConfJWTJWKSFile a JSON Web Key Set file containing the public keys used to verify JWT bearer tokens
+	AuthConfJWTJWKSFile = "jwt.jwksFile"
+	// AuthConfJWTIssuer if set, the "iss" claim of a JWT must match this value
+	AuthConfJWTIssuer = "jwt.issuer"
+	// AuthConfJWTAudience if set, the "aud" claim of a JWT must contain this value
+	AuthConfJWTAudience = "jwt.audience"
+	// AuthConfJWTNameClaim the JWT claim that identifies the caller
+	AuthConfJWTNameClaim = "jwt.nameClaim"
+	// AuthConfJWTGrantsClaim the JWT claim containing the namespace grants, as an array or a space separated string
+	AuthConfJWTGrantsClaim = "jwt.grantsClaim"
+	// AuthConfJWTClockSkew the tolerance allowed when checking the "exp" and "nbf" claims of a JWT
+	AuthConfJWTClockSkew = "jwt.clockSkew"
+)
+
+func InitPrefix(prefix config.Prefix) {
+	prefix.AddKnownKey(AuthConfEnabled, false)
+	prefix.AddKnownKey(AuthConfAPIKeys)
+	prefix.AddKnownKey(AuthConfJWTJWKSFile)
+	prefix.AddKnownKey(AuthConfJWTIssuer)
+	prefix.AddKnownKey(AuthConfJWTAudience)
+	prefix.AddKnownKey(AuthConfJWTNameClaim, "sub")
+	prefix.AddKnownKey(AuthConfJWTGrantsClaim, "firefly_grants")
+	prefix.AddKnownKey(AuthConfJWTClockSkew, "1m")
+}
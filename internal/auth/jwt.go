@@ -0,0 +1,310 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256" // registers the hashes used by the supported algorithms
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+)
+
+type jwtAlgorithm struct {
+	hash  crypto.Hash
+	kty   string
+	pss   bool
+	curve elliptic.Curve
+}
+
+var jwtAlgorithms = map[string]*jwtAlgorithm{
+	"RS256": {hash: crypto.SHA256, kty: "RSA"},
+	"RS384": {hash: crypto.SHA384, kty: "RSA"},
+	"RS512": {hash: crypto.SHA512, kty: "RSA"},
+	"PS256": {hash: crypto.SHA256, kty: "RSA", pss: true},
+	"PS384": {hash: crypto.SHA384, kty: "RSA", pss: true},
+	"PS512": {hash: crypto.SHA512, kty: "RSA", pss: true},
+	"ES256": {hash: crypto.SHA256, kty: "EC", curve: elliptic.P256()},
+	"ES384": {hash: crypto.SHA384, kty: "EC", curve: elliptic.P384()},
+	"ES512": {hash: crypto.SHA512, kty: "EC", curve: elliptic.P521()},
+}
+
+var jwkCurves = map[string]elliptic.Curve{
+	"P-256": elliptic.P256(),
+	"P-384": elliptic.P384(),
+	"P-521": elliptic.P521(),
+}
+
+type jwks struct {
+	Keys []*jwk `json:"keys"`
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+}
+
+type verificationKey struct {
+	kid string
+	kty string
+	alg string
+	key crypto.PublicKey
+}
+
+type jwtVerifier struct {
+	keys        []*verificationKey
+	issuer      string
+	audience    string
+	nameClaim   string
+	grantsClaim string
+	clockSkew   time.Duration
+}
+
+func newJWTVerifier(ctx context.Context, prefix config.Prefix) (*jwtVerifier, error) {
+	jv := &jwtVerifier{
+		issuer:      prefix.GetString(AuthConfJWTIssuer),
+		audience:    prefix.GetString(AuthConfJWTAudience),
+		nameClaim:   prefix.GetString(AuthConfJWTNameClaim),
+		grantsClaim: prefix.GetString(AuthConfJWTGrantsClaim),
+		clockSkew:   prefix.GetDuration(AuthConfJWTClockSkew),
+	}
+	jwksFile := prefix.GetString(AuthConfJWTJWKSFile)
+	b, err := ioutil.ReadFile(jwksFile)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgAuthInvalidConfig, jwksFile)
+	}
+	var keySet jwks
+	if err := json.Unmarshal(b, &keySet); err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgAuthInvalidConfig, jwksFile)
+	}
+	for i, k := range keySet.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			log.L(ctx).Debugf("Skipping JWK %d (kid=%s) with use '%s'", i, k.Kid, k.Use)
+			continue
+		}
+		key, err := parseJWK(ctx, k)
+		if err != nil {
+			return nil, err
+		}
+		if key != nil {
+			jv.keys = append(jv.keys, &verificationKey{kid: k.Kid, kty: k.Kty, alg: k.Alg, key: key})
+		}
+	}
+	if len(jv.keys) == 0 {
+		return nil, i18n.NewError(ctx, i18n.MsgAuthInvalidConfig, "no signing keys found in "+jwksFile)
+	}
+	return jv, nil
+}
+
+func decodeBigInt(s string) (*big.Int, bool) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, false
+	}
+	return new(big.Int).SetBytes(b), true
+}
+
+func parseJWK(ctx context.Context, k *jwk) (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, nOk := decodeBigInt(k.N)
+		e, eOk := decodeBigInt(k.E)
+		if !nOk || !eOk || !e.IsInt64() {
+			return nil, i18n.NewError(ctx, i18n.MsgAuthInvalidConfig, "invalid RSA key "+k.Kid)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		curve, ok := jwkCurves[k.Crv]
+		x, xOk := decodeBigInt(k.X)
+		y, yOk := decodeBigInt(k.Y)
+		if !ok || !xOk || !yOk || !curve.IsOnCurve(x, y) {
+			return nil, i18n.NewError(ctx, i18n.MsgAuthInvalidConfig, "invalid EC key "+k.Kid)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		log.L(ctx).Debugf("Skipping JWK (kid=%s) with unsupported key type '%s'", k.Kid, k.Kty)
+		return nil, nil
+	}
+}
+
+func (jv *jwtVerifier) findKey(header *jwtHeader, alg *jwtAlgorithm) *verificationKey {
+	var candidate *verificationKey
+	for _, k := range jv.keys {
+		if k.kty != alg.kty || (k.alg != "" && k.alg != header.Alg) {
+			continue
+		}
+		if header.Kid != "" && k.kid == header.Kid {
+			return k
+		}
+		if header.Kid == "" {
+			if candidate != nil {
+				// Ambiguous without a key ID
+				return nil
+			}
+			candidate = k
+		}
+	}
+	return candidate
+}
+
+func decodeSegment(ctx context.Context, segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err == nil {
+		err = json.Unmarshal(b, v)
+	}
+	if err != nil {
+		return i18n.WrapError(ctx, err, i18n.MsgAuthInvalidJWT, "malformed token")
+	}
+	return nil
+}
+
+func (jv *jwtVerifier) verifySignature(ctx context.Context, parts []string) error {
+	var header jwtHeader
+	if err := decodeSegment(ctx, parts[0], &header); err != nil {
+		return err
+	}
+	alg, ok := jwtAlgorithms[header.Alg]
+	if !ok {
+		return i18n.NewError(ctx, i18n.MsgAuthInvalidJWT, "unsupported algorithm '"+header.Alg+"'")
+	}
+	key := jv.findKey(&header, alg)
+	if key == nil {
+		return i18n.NewError(ctx, i18n.MsgAuthInvalidJWT, "no matching key for kid '"+header.Kid+"'")
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return i18n.WrapError(ctx, err, i18n.MsgAuthInvalidJWT, "malformed signature")
+	}
+	h := alg.hash.New()
+	h.Write([]byte(parts[0] + "." + parts[1]))
+	digest := h.Sum(nil)
+
+	valid := false
+	switch pub := key.key.(type) {
+	case *rsa.PublicKey:
+		if alg.pss {
+			valid = rsa.VerifyPSS(pub, alg.hash, digest, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil
+		} else {
+			valid = rsa.VerifyPKCS1v15(pub, alg.hash, digest, sig) == nil
+		}
+	case *ecdsa.PublicKey:
+		// JWS encodes an ECDSA signature as the fixed length concatenation of R and S
+		keyBytes := (pub.Curve.Params().BitSize + 7) / 8
+		if pub.Curve == alg.curve && len(sig) == 2*keyBytes {
+			r := new(big.Int).SetBytes(sig[0:keyBytes])
+			s := new(big.Int).SetBytes(sig[keyBytes:])
+			valid = ecdsa.Verify(pub, digest, r, s)
+		}
+	}
+	if !valid {
+		return i18n.NewError(ctx, i18n.MsgAuthInvalidJWT, "signature verification failed")
+	}
+	return nil
+}
+
+func (jv *jwtVerifier) checkClaims(ctx context.Context, claims map[string]interface{}) error {
+	now := time.Now()
+	if exp, ok := claims["exp"].(float64); !ok || now.Add(-jv.clockSkew).After(time.Unix(int64(exp), 0)) {
+		return i18n.NewError(ctx, i18n.MsgAuthInvalidJWT, "token is expired or has no expiry")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(jv.clockSkew).Before(time.Unix(int64(nbf), 0)) {
+		return i18n.NewError(ctx, i18n.MsgAuthInvalidJWT, "token is not yet valid")
+	}
+	if jv.issuer != "" && claims["iss"] != jv.issuer {
+		return i18n.NewError(ctx, i18n.MsgAuthInvalidJWT, "issuer mismatch")
+	}
+	if jv.audience != "" {
+		found := false
+		switch aud := claims["aud"].(type) {
+		case string:
+			found = aud == jv.audience
+		case []interface{}:
+			for _, a := range aud {
+				found = found || a == jv.audience
+			}
+		}
+		if !found {
+			return i18n.NewError(ctx, i18n.MsgAuthInvalidJWT, "audience mismatch")
+		}
+	}
+	return nil
+}
+
+func (jv *jwtVerifier) getGrants(claims map[string]interface{}) []string {
+	switch v := claims[jv.grantsClaim].(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		grants := make([]string, 0, len(v))
+		for _, g := range v {
+			if s, ok := g.(string); ok {
+				grants = append(grants, s)
+			}
+		}
+		return grants
+	default:
+		return nil
+	}
+}
+
+// verify checks the signature and claims of a compact serialized JWT, and returns
+// the principal described by its claims
+func (jv *jwtVerifier) verify(ctx context.Context, token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, i18n.NewError(ctx, i18n.MsgAuthInvalidJWT, "malformed token")
+	}
+	if err := jv.verifySignature(ctx, parts); err != nil {
+		return nil, err
+	}
+	var claims map[string]interface{}
+	if err := decodeSegment(ctx, parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if err := jv.checkClaims(ctx, claims); err != nil {
+		return nil, err
+	}
+	name, _ := claims[jv.nameClaim].(string)
+	if name == "" {
+		return nil, i18n.NewError(ctx, i18n.MsgAuthInvalidJWT, "missing claim '"+jv.nameClaim+"'")
+	}
+	return NewPrincipal(ctx, name, jv.getGrants(claims))
+}
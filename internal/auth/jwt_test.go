@@ -0,0 +1,307 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+type testKeys struct {
+	rsaKey *rsa.PrivateKey
+	ecKey  *ecdsa.PrivateKey
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func newTestKeys(t *testing.T) *testKeys {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	return &testKeys{rsaKey: rsaKey, ecKey: ecKey}
+}
+
+func (tk *testKeys) jwks() *jwks {
+	return &jwks{
+		Keys: []*jwk{
+			{
+				Kty: "RSA",
+				Kid: "rsa1",
+				Use: "sig",
+				N:   b64(tk.rsaKey.N.Bytes()),
+				E:   b64(big.NewInt(int64(tk.rsaKey.E)).Bytes()),
+			},
+			{
+				Kty: "EC",
+				Kid: "ec1",
+				Alg: "ES256",
+				Crv: "P-256",
+				X:   b64(tk.ecKey.X.FillBytes(make([]byte, 32))),
+				Y:   b64(tk.ecKey.Y.FillBytes(make([]byte, 32))),
+			},
+			{Kty: "oct", Kid: "hmac1"},
+			{Kty: "RSA", Kid: "enc1", Use: "enc"},
+		},
+	}
+}
+
+func writeJWKS(t *testing.T, keySet interface{}) string {
+	b, err := json.Marshal(keySet)
+	assert.NoError(t, err)
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	err = ioutil.WriteFile(file, b, 0600)
+	assert.NoError(t, err)
+	return file
+}
+
+func (tk *testKeys) sign(t *testing.T, alg, kid string, claims map[string]interface{}) string {
+	header := map[string]string{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+	hb, _ := json.Marshal(header)
+	cb, _ := json.Marshal(claims)
+	signed := b64(hb) + "." + b64(cb)
+	h := jwtAlgorithms[alg].hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+	var sig []byte
+	var err error
+	switch alg[0:2] {
+	case "RS":
+		sig, err = rsa.SignPKCS1v15(rand.Reader, tk.rsaKey, jwtAlgorithms[alg].hash, digest)
+	case "PS":
+		sig, err = rsa.SignPSS(rand.Reader, tk.rsaKey, jwtAlgorithms[alg].hash, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	case "ES":
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, tk.ecKey, digest)
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	assert.NoError(t, err)
+	return signed + "." + b64(sig)
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":            "user1",
+		"iss":            "https://issuer.example.com",
+		"aud":            []string{"firefly"},
+		"exp":            time.Now().Add(1 * time.Hour).Unix(),
+		"nbf":            time.Now().Add(-1 * time.Minute).Unix(),
+		"firefly_grants": []string{"ns1:write", "*:read"},
+	}
+}
+
+func newTestJWTVerifier(t *testing.T) (*jwtVerifier, *testKeys) {
+	config.Reset()
+	prefix := config.NewPluginConfig("auth")
+	InitPrefix(prefix)
+	tk := newTestKeys(t)
+	prefix.Set(AuthConfJWTJWKSFile, writeJWKS(t, tk.jwks()))
+	prefix.Set(AuthConfJWTIssuer, "https://issuer.example.com")
+	prefix.Set(AuthConfJWTAudience, "firefly")
+	jv, err := newJWTVerifier(context.Background(), prefix)
+	assert.NoError(t, err)
+	return jv, tk
+}
+
+func TestJWTVerifyAlgorithms(t *testing.T) {
+	jv, tk := newTestJWTVerifier(t)
+	for _, tc := range []struct{ alg, kid string }{
+		{"RS256", "rsa1"},
+		{"RS384", ""},
+		{"RS512", "rsa1"},
+		{"PS256", "rsa1"},
+		{"PS384", ""},
+		{"PS512", "rsa1"},
+		{"ES256", "ec1"},
+		{"ES256", ""},
+	} {
+		p, err := jv.verify(context.Background(), tk.sign(t, tc.alg, tc.kid, validClaims()))
+		assert.NoError(t, err, tc.alg)
+		assert.Equal(t, "user1", p.Name)
+		assert.Equal(t, RoleWrite, p.grants["ns1"])
+		assert.Equal(t, RoleRead, p.grants["*"])
+	}
+}
+
+func TestJWTVerifyGrantsString(t *testing.T) {
+	jv, tk := newTestJWTVerifier(t)
+	claims := validClaims()
+	claims["aud"] = "firefly"
+	claims["firefly_grants"] = "ns1:read ns2:write"
+	p, err := jv.verify(context.Background(), tk.sign(t, "RS256", "rsa1", claims))
+	assert.NoError(t, err)
+	assert.Equal(t, RoleRead, p.grants["ns1"])
+	assert.Equal(t, RoleWrite, p.grants["ns2"])
+}
+
+func TestJWTVerifyNoGrants(t *testing.T) {
+	jv, tk := newTestJWTVerifier(t)
+	claims := validClaims()
+	delete(claims, "firefly_grants")
+	p, err := jv.verify(context.Background(), tk.sign(t, "RS256", "rsa1", claims))
+	assert.NoError(t, err)
+	assert.Empty(t, p.grants)
+}
+
+func TestJWTVerifyBadGrant(t *testing.T) {
+	jv, tk := newTestJWTVerifier(t)
+	claims := validClaims()
+	claims["firefly_grants"] = []interface{}{"ns1:owner", 12345}
+	_, err := jv.verify(context.Background(), tk.sign(t, "RS256", "rsa1", claims))
+	assert.Regexp(t, "FF10400", err)
+}
+
+func TestJWTVerifyClaimFailures(t *testing.T) {
+	jv, tk := newTestJWTVerifier(t)
+	for name, tc := range map[string]struct {
+		key   string
+		value interface{}
+		regex string
+	}{
+		"expired":     {"exp", time.Now().Add(-2 * time.Minute).Unix(), "expired"},
+		"noexpiry":    {"exp", nil, "expired"},
+		"notyetvalid": {"nbf", time.Now().Add(2 * time.Minute).Unix(), "not yet valid"},
+		"issuer":      {"iss", "https://other.example.com", "issuer mismatch"},
+		"audience":    {"aud", []string{"other"}, "audience mismatch"},
+		"noaudience":  {"aud", nil, "audience mismatch"},
+		"nosubject":   {"sub", nil, "missing claim 'sub'"},
+	} {
+		claims := validClaims()
+		if tc.value == nil {
+			delete(claims, tc.key)
+		} else {
+			claims[tc.key] = tc.value
+		}
+		_, err := jv.verify(context.Background(), tk.sign(t, "RS256", "rsa1", claims))
+		assert.Regexp(t, "FF10399.*"+tc.regex, err, name)
+	}
+}
+
+func TestJWTVerifyClockSkew(t *testing.T) {
+	jv, tk := newTestJWTVerifier(t)
+	claims := validClaims()
+	claims["exp"] = time.Now().Add(-30 * time.Second).Unix()
+	_, err := jv.verify(context.Background(), tk.sign(t, "RS256", "rsa1", claims))
+	assert.NoError(t, err)
+}
+
+func TestJWTVerifyMalformed(t *testing.T) {
+	jv, tk := newTestJWTVerifier(t)
+	token := tk.sign(t, "RS256", "rsa1", validClaims())
+	for name, tc := range map[string]string{
+		"parts":     "a.b",
+		"header":    "!!!.b.c",
+		"claims":    token[0:len(token)-10] + "." + "!!!." + "c",
+		"signature": token + "!!!",
+	} {
+		_, err := jv.verify(context.Background(), tc)
+		assert.Regexp(t, "FF10399", err, name)
+	}
+}
+
+func TestJWTVerifyBadClaimsSegment(t *testing.T) {
+	jv, tk := newTestJWTVerifier(t)
+	hb, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "rsa1"})
+	signed := b64(hb) + "." + b64([]byte("not json"))
+	digest := crypto.SHA256.New()
+	digest.Write([]byte(signed))
+	sig, _ := rsa.SignPKCS1v15(rand.Reader, tk.rsaKey, crypto.SHA256, digest.Sum(nil))
+	_, err := jv.verify(context.Background(), signed+"."+b64(sig))
+	assert.Regexp(t, "FF10399.*malformed token", err)
+}
+
+func TestJWTVerifyUnsupportedAlgorithm(t *testing.T) {
+	jv, _ := newTestJWTVerifier(t)
+	hb, _ := json.Marshal(map[string]string{"alg": "none"})
+	_, err := jv.verify(context.Background(), b64(hb)+"."+b64([]byte("{}"))+".")
+	assert.Regexp(t, "FF10399.*unsupported algorithm", err)
+}
+
+func TestJWTVerifyUnknownKey(t *testing.T) {
+	jv, tk := newTestJWTVerifier(t)
+	_, err := jv.verify(context.Background(), tk.sign(t, "RS256", "unknown", validClaims()))
+	assert.Regexp(t, "FF10399.*no matching key", err)
+}
+
+func TestJWTVerifyAmbiguousKey(t *testing.T) {
+	jv, tk := newTestJWTVerifier(t)
+	jv.keys = append(jv.keys, jv.keys[0])
+	_, err := jv.verify(context.Background(), tk.sign(t, "RS256", "", validClaims()))
+	assert.Regexp(t, "FF10399.*no matching key", err)
+}
+
+func TestJWTVerifyBadSignature(t *testing.T) {
+	jv, tk := newTestJWTVerifier(t)
+	other := newTestKeys(t)
+	_, err := jv.verify(context.Background(), other.sign(t, "RS256", "rsa1", validClaims()))
+	assert.Regexp(t, "FF10399.*signature verification failed", err)
+	_, err = jv.verify(context.Background(), other.sign(t, "ES256", "ec1", validClaims()))
+	assert.Regexp(t, "FF10399.*signature verification failed", err)
+	token := tk.sign(t, "ES256", "ec1", validClaims())
+	_, err = jv.verify(context.Background(), token[0:len(token)-4])
+	assert.Regexp(t, "FF10399.*signature verification failed", err)
+}
+
+func TestNewJWTVerifierBadFiles(t *testing.T) {
+	config.Reset()
+	prefix := config.NewPluginConfig("auth")
+	InitPrefix(prefix)
+	tk := newTestKeys(t)
+
+	prefix.Set(AuthConfJWTJWKSFile, filepath.Join(t.TempDir(), "missing.json"))
+	_, err := newJWTVerifier(context.Background(), prefix)
+	assert.Regexp(t, "FF10398", err)
+
+	prefix.Set(AuthConfJWTJWKSFile, writeJWKS(t, "not a key set"))
+	_, err = newJWTVerifier(context.Background(), prefix)
+	assert.Regexp(t, "FF10398", err)
+
+	prefix.Set(AuthConfJWTJWKSFile, writeJWKS(t, &jwks{Keys: []*jwk{{Kty: "oct"}}}))
+	_, err = newJWTVerifier(context.Background(), prefix)
+	assert.Regexp(t, "FF10398.*no signing keys", err)
+
+	badRSA := tk.jwks().Keys[0]
+	badRSA.E = "!!!"
+	prefix.Set(AuthConfJWTJWKSFile, writeJWKS(t, &jwks{Keys: []*jwk{badRSA}}))
+	_, err = newJWTVerifier(context.Background(), prefix)
+	assert.Regexp(t, "FF10398.*invalid RSA key", err)
+
+	badEC := tk.jwks().Keys[1]
+	badEC.X = b64([]byte{1, 2, 3})
+	prefix.Set(AuthConfJWTJWKSFile, writeJWKS(t, &jwks{Keys: []*jwk{badEC}}))
+	_, err = newJWTVerifier(context.Background(), prefix)
+	assert.Regexp(t, "FF10398.*invalid EC key", err)
+}
@@ -0,0 +1,110 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+)
+
+// Role is the level of access granted on a namespace
+type Role string
+
+const (
+	// RoleRead allows querying, and listening for events on, a namespace
+	RoleRead Role = "read"
+	// RoleWrite allows everything RoleRead does, plus submitting changes to a namespace
+	RoleWrite Role = "write"
+	// RoleAdmin allows everything RoleWrite does, plus the routes of the admin server - such as export and import
+	RoleAdmin Role = "admin"
+)
+
+// roleLevels orders the roles, as each role includes all the access of the roles below it
+var roleLevels = map[Role]int{
+	RoleRead:  1,
+	RoleWrite: 2,
+	RoleAdmin: 3,
+}
+
+// AllNamespaces is the namespace in a grant that matches every namespace, and is
+// also the namespace checked for routes that are not scoped to a namespace
+const AllNamespaces = "*"
+
+type principalContextKey struct{}
+
+// Principal is an authenticated caller, and the roles it has been granted on each namespace
+type Principal struct {
+	Name   string
+	grants map[string]Role
+}
+
+// NewPrincipal parses a list of grants in the format "namespace:role" - such as "default:write" or "*:read"
+func NewPrincipal(ctx context.Context, name string, grants []string) (*Principal, error) {
+	p := &Principal{
+		Name:   name,
+		grants: make(map[string]Role),
+	}
+	for _, grant := range grants {
+		ns, role := grant, ""
+		if sep := strings.LastIndex(grant, ":"); sep >= 0 {
+			ns, role = grant[0:sep], grant[sep+1:]
+		}
+		level, ok := roleLevels[Role(role)]
+		if !ok {
+			return nil, i18n.NewError(ctx, i18n.MsgAuthInvalidRole, role, ns)
+		}
+		if level > roleLevels[p.grants[ns]] {
+			p.grants[ns] = Role(role)
+		}
+	}
+	return p, nil
+}
+
+func (p *Principal) hasRole(namespace string, role Role) bool {
+	for _, ns := range []string{namespace, AllNamespaces} {
+		if granted, ok := p.grants[ns]; ok && roleLevels[granted] >= roleLevels[role] {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize checks the principal has been granted the role on the namespace.
+// An empty namespace is checked against the AllNamespaces grant.
+func (p *Principal) Authorize(ctx context.Context, namespace string, role Role) error {
+	if namespace == "" {
+		namespace = AllNamespaces
+	}
+	if !p.hasRole(namespace, role) {
+		return i18n.NewError(ctx, i18n.MsgAuthForbidden, p.Name, role, namespace)
+	}
+	return nil
+}
+
+// WithPrincipal stores the authenticated principal in the context
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, p)
+}
+
+// GetPrincipal returns the authenticated principal from the context, or nil
+// if authentication is not enabled
+func GetPrincipal(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalContextKey{}).(*Principal)
+	return p
+}
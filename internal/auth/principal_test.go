@@ -0,0 +1,75 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrincipalAuthorize(t *testing.T) {
+	ctx := context.Background()
+	p, err := NewPrincipal(ctx, "app1", []string{"ns1:read", "ns1:write", "ns2:read", "ns2:read"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, p.Authorize(ctx, "ns1", RoleRead))
+	assert.NoError(t, p.Authorize(ctx, "ns1", RoleWrite))
+	assert.NoError(t, p.Authorize(ctx, "ns2", RoleRead))
+	assert.Regexp(t, "FF10397.*app1.*write.*ns2", p.Authorize(ctx, "ns2", RoleWrite))
+	assert.Regexp(t, "FF10397.*read.*ns3", p.Authorize(ctx, "ns3", RoleRead))
+	assert.Regexp(t, `FF10397.*read.*\*`, p.Authorize(ctx, "", RoleRead))
+}
+
+func TestPrincipalAuthorizeAllNamespaces(t *testing.T) {
+	ctx := context.Background()
+	p, err := NewPrincipal(ctx, "app1", []string{"*:read", "ns1:write"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, p.Authorize(ctx, "", RoleRead))
+	assert.NoError(t, p.Authorize(ctx, "ns2", RoleRead))
+	assert.NoError(t, p.Authorize(ctx, "ns1", RoleWrite))
+	assert.Regexp(t, "FF10397", p.Authorize(ctx, "ns2", RoleWrite))
+	assert.Regexp(t, "FF10397", p.Authorize(ctx, "", RoleWrite))
+}
+
+func TestPrincipalAuthorizeAdmin(t *testing.T) {
+	ctx := context.Background()
+	p, err := NewPrincipal(ctx, "app1", []string{"ns1:admin", "ns1:read", "*:write"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, p.Authorize(ctx, "ns1", RoleAdmin))
+	assert.NoError(t, p.Authorize(ctx, "ns1", RoleWrite))
+	assert.NoError(t, p.Authorize(ctx, "ns2", RoleWrite))
+	assert.Regexp(t, "FF10397.*admin.*ns2", p.Authorize(ctx, "ns2", RoleAdmin))
+	assert.Regexp(t, `FF10397.*admin.*\*`, p.Authorize(ctx, "", RoleAdmin))
+}
+
+func TestNewPrincipalBadRole(t *testing.T) {
+	_, err := NewPrincipal(context.Background(), "app1", []string{"ns1:owner"})
+	assert.Regexp(t, "FF10400.*owner.*ns1", err)
+	_, err = NewPrincipal(context.Background(), "app1", []string{"ns1"})
+	assert.Regexp(t, "FF10400", err)
+}
+
+func TestPrincipalContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Nil(t, GetPrincipal(ctx))
+	p := &Principal{Name: "app1"}
+	assert.Equal(t, p, GetPrincipal(WithPrincipal(ctx, p)))
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/hyperledger/firefly/internal/operations"
 	"github.com/hyperledger/firefly/internal/retry"
 	"github.com/hyperledger/firefly/internal/sysmessaging"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -358,14 +359,23 @@ func (bp *batchProcessor) assemblyLoop() {
 	}
 }
 
-func (bp *batchProcessor) flush(overflow bool) error {
+func (bp *batchProcessor) flush(overflow bool) (err error) {
 	id, flushWork, byteSize := bp.startFlush(overflow)
 
 	log.L(bp.ctx).Debugf("Flushing batch %s", id)
 	state := bp.initFlushState(id, flushWork)
 
+	// The batch span links to the span of each message, and is recorded so the confirmation of the batch can link to it
+	msgIDs := make([]*fftypes.UUID, len(state.Payload.Messages))
+	for i, msg := range state.Payload.Messages {
+		msgIDs[i] = msg.Header.ID
+	}
+	ctx, span := tracing.StartSpan(bp.ctx, "batch_flush", msgIDs, tracing.Namespace(bp.conf.namespace), tracing.BatchID(id))
+	defer func() { tracing.EndSpan(span, err) }()
+	tracing.Record(ctx, id)
+
 	// Sealing phase: assigns persisted pins to messages, and finalizes the manifest
-	err := bp.sealBatch(state)
+	err = bp.sealBatch(state)
 	if err != nil {
 		return err
 	}
@@ -374,7 +384,7 @@ func (bp *batchProcessor) flush(overflow bool) error {
 	// Dispatch phase: the heavy lifting work - calling plugins to do the hard work of the batch.
 	//   The dispatcher can update the state, such as appending to the BlobsPublished array,
 	//   to affect DB updates as part of the finalization phase.
-	err = bp.dispatchBatch(state)
+	err = bp.dispatchBatch(ctx, state)
 	if err != nil {
 		return err
 	}
@@ -535,9 +545,9 @@ func (bp *batchProcessor) sealBatch(state *DispatchState) (err error) {
 	return err
 }
 
-func (bp *batchProcessor) dispatchBatch(state *DispatchState) error {
+func (bp *batchProcessor) dispatchBatch(ctx context.Context, state *DispatchState) error {
 	// Call the dispatcher to do the heavy lifting - will only exit if we're closed
-	return operations.RunWithOperationCache(bp.ctx, func(ctx context.Context) error {
+	return operations.RunWithOperationCache(ctx, func(ctx context.Context) error {
 		return bp.retry.Do(ctx, "batch dispatch", func(attempt int) (retry bool, err error) {
 			return true, bp.conf.dispatch(ctx, state)
 		})
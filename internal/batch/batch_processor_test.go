@@ -163,7 +163,7 @@ func TestCloseToUnblockDispatch(t *testing.T) {
 		return fmt.Errorf("pop")
 	})
 	bp.cancelCtx()
-	bp.dispatchBatch(&DispatchState{})
+	bp.dispatchBatch(bp.ctx, &DispatchState{})
 	<-bp.done
 }
 
@@ -19,11 +19,12 @@ package batchpin
 import (
 	"context"
 
+	"github.com/hyperledger/firefly/internal/blockchain/birouter"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/identity"
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/operations"
-	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
@@ -39,23 +40,23 @@ type Submitter interface {
 }
 
 type batchPinSubmitter struct {
-	database   database.Plugin
-	identity   identity.Manager
-	blockchain blockchain.Plugin
-	metrics    metrics.Manager
-	operations operations.Manager
+	database    database.Plugin
+	identity    identity.Manager
+	blockchains birouter.Router
+	metrics     metrics.Manager
+	operations  operations.Manager
 }
 
-func NewBatchPinSubmitter(ctx context.Context, di database.Plugin, im identity.Manager, bi blockchain.Plugin, mm metrics.Manager, om operations.Manager) (Submitter, error) {
-	if di == nil || im == nil || bi == nil || mm == nil || om == nil {
+func NewBatchPinSubmitter(ctx context.Context, di database.Plugin, im identity.Manager, br birouter.Router, mm metrics.Manager, om operations.Manager) (Submitter, error) {
+	if di == nil || im == nil || br == nil || mm == nil || om == nil {
 		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
 	}
 	bp := &batchPinSubmitter{
-		database:   di,
-		identity:   im,
-		blockchain: bi,
-		metrics:    mm,
-		operations: om,
+		database:    di,
+		identity:    im,
+		blockchains: br,
+		metrics:     mm,
+		operations:  om,
 	}
 	om.RegisterHandler(ctx, bp, []fftypes.OpType{
 		fftypes.OpTypeBlockchainBatchPin,
@@ -67,17 +68,23 @@ func (bp *batchPinSubmitter) Name() string {
 	return "BatchPinSubmitter"
 }
 
-func (bp *batchPinSubmitter) SubmitPinnedBatch(ctx context.Context, batch *fftypes.BatchPersisted, contexts []*fftypes.Bytes32) error {
+func (bp *batchPinSubmitter) SubmitPinnedBatch(ctx context.Context, batch *fftypes.BatchPersisted, contexts []*fftypes.Bytes32) (err error) {
 	// The pending blockchain transaction
 	op := fftypes.NewOperation(
-		bp.blockchain,
+		bp.blockchains.NamedForNamespace(batch.Namespace),
 		batch.Namespace,
 		batch.TX.ID,
 		fftypes.OpTypeBlockchainBatchPin)
 	addBatchPinInputs(op, batch.ID, contexts)
+
+	// The span is recorded against the operation, so the receipt from the blockchain can link to it
+	ctx, span := tracing.StartSpan(ctx, "batch_pin", []*fftypes.UUID{batch.ID}, tracing.Namespace(batch.Namespace), tracing.BatchID(batch.ID))
+	defer func() { tracing.EndSpan(span, err) }()
 	if err := bp.operations.AddOrReuseOperation(ctx, op); err != nil {
 		return err
 	}
+	span.SetAttributes(tracing.OperationID(op.ID))
+	tracing.Record(ctx, op.ID)
 
 	if bp.metrics.IsMetricsEnabled() {
 		bp.metrics.CountBatchPin()
@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/biroutermocks"
 	"github.com/hyperledger/firefly/mocks/blockchainmocks"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
@@ -38,6 +39,7 @@ func newTestBatchPinSubmitter(t *testing.T, enableMetrics bool) *batchPinSubmitt
 	mdi := &databasemocks.Plugin{}
 	mim := &identitymanagermocks.Manager{}
 	mbi := &blockchainmocks.Plugin{}
+	mbr := &biroutermocks.Router{}
 	mmi := &metricsmocks.Manager{}
 	mom := &operationmocks.Manager{}
 	mmi.On("IsMetricsEnabled").Return(enableMetrics)
@@ -46,7 +48,9 @@ func newTestBatchPinSubmitter(t *testing.T, enableMetrics bool) *batchPinSubmitt
 		mmi.On("CountBatchPin").Return()
 	}
 	mbi.On("Name").Return("ut").Maybe()
-	bps, err := NewBatchPinSubmitter(context.Background(), mdi, mim, mbi, mmi, mom)
+	mbr.On("ForNamespace", mock.Anything).Return(mbi).Maybe()
+	mbr.On("NamedForNamespace", mock.Anything).Return(mbi).Maybe()
+	bps, err := NewBatchPinSubmitter(context.Background(), mdi, mim, mbr, mmi, mom)
 	assert.NoError(t, err)
 	return bps.(*batchPinSubmitter)
 }
@@ -47,7 +47,7 @@ func TestPrepareAndRunBatchPin(t *testing.T) {
 	}
 	addBatchPinInputs(op, batch.ID, contexts)
 
-	mbi := bp.blockchain.(*blockchainmocks.Plugin)
+	mbi := bp.blockchains.ForNamespace(batch.Namespace).(*blockchainmocks.Plugin)
 	mdi := bp.database.(*databasemocks.Plugin)
 	mdi.On("GetBatchByID", context.Background(), batch.ID).Return(batch, nil)
 	mbi.On("SubmitBatchPin", context.Background(), op.ID, mock.Anything, "0x123", mock.Anything).Return(nil)
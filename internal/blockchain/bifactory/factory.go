@@ -37,6 +37,13 @@ func InitPrefix(prefix config.Prefix) {
 	}
 }
 
+// InitPrefixArray initializes the keys for each entry in an array of named blockchain plugins.
+// The plugin specific keys are added as each entry is loaded, once its plugin type is known.
+func InitPrefixArray(prefix config.PrefixArray) {
+	prefix.AddKnownKey(blockchain.BlockchainConfigName)
+	prefix.AddKnownKey(blockchain.BlockchainConfigPlugin)
+}
+
 func GetPlugin(ctx context.Context, pluginType string) (blockchain.Plugin, error) {
 	plugin, ok := pluginsByName[pluginType]
 	if !ok {
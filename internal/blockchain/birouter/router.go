@@ -0,0 +1,100 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package birouter
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// Router resolves the blockchain plugin that each namespace is anchored on.
+// Namespaces that are not bound to a named plugin, including the system namespace, use the default plugin.
+type Router interface {
+	// Default returns the plugin for namespaces that are not bound to a named plugin
+	Default() blockchain.Plugin
+
+	// ForNamespace returns the plugin that the namespace is bound to
+	ForNamespace(ns string) blockchain.Plugin
+
+	// NamedForNamespace returns the configured name of the plugin that the namespace is bound to, which is recorded
+	// on operations in place of the plugin type so that they are attributed to the right chain
+	NamedForNamespace(ns string) fftypes.Named
+
+	// Plugins returns all configured plugins, by name
+	Plugins() map[string]blockchain.Plugin
+}
+
+type pluginName string
+
+func (n pluginName) Name() string {
+	return string(n)
+}
+
+type router struct {
+	plugins        map[string]blockchain.Plugin
+	defaultName    string
+	defaultPlugin  blockchain.Plugin
+	namespaces     map[string]blockchain.Plugin
+	namespaceNames map[string]string
+}
+
+// NewRouter creates a router over the named plugins, with a map of namespace names to plugin names
+func NewRouter(ctx context.Context, plugins map[string]blockchain.Plugin, defaultName string, namespaces map[string]string) (Router, error) {
+	r := &router{
+		plugins:        plugins,
+		defaultName:    defaultName,
+		defaultPlugin:  plugins[defaultName],
+		namespaces:     make(map[string]blockchain.Plugin, len(namespaces)),
+		namespaceNames: namespaces,
+	}
+	if r.defaultPlugin == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgUnknownBlockchainPlugin, defaultName)
+	}
+	for ns, name := range namespaces {
+		plugin, ok := plugins[name]
+		if !ok {
+			return nil, i18n.NewError(ctx, i18n.MsgNamespaceBlockchainNotFound, ns, name)
+		}
+		r.namespaces[ns] = plugin
+	}
+	return r, nil
+}
+
+func (r *router) Default() blockchain.Plugin {
+	return r.defaultPlugin
+}
+
+func (r *router) ForNamespace(ns string) blockchain.Plugin {
+	if plugin, ok := r.namespaces[ns]; ok {
+		return plugin
+	}
+	return r.defaultPlugin
+}
+
+func (r *router) NamedForNamespace(ns string) fftypes.Named {
+	if name, ok := r.namespaceNames[ns]; ok {
+		return pluginName(name)
+	}
+	return pluginName(r.defaultName)
+}
+
+func (r *router) Plugins() map[string]blockchain.Plugin {
+	return r.plugins
+}
@@ -0,0 +1,58 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package birouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouter(t *testing.T) {
+	eth := &blockchainmocks.Plugin{}
+	fab := &blockchainmocks.Plugin{}
+	plugins := map[string]blockchain.Plugin{"eth1": eth, "fab1": fab}
+
+	r, err := NewRouter(context.Background(), plugins, "eth1", map[string]string{
+		"ns1": "eth1",
+		"ns2": "fab1",
+	})
+	assert.NoError(t, err)
+	assert.Same(t, eth, r.Default())
+	assert.Same(t, eth, r.ForNamespace("ns1"))
+	assert.Same(t, fab, r.ForNamespace("ns2"))
+	assert.Same(t, eth, r.ForNamespace("ff_system"))
+	assert.Equal(t, "eth1", r.NamedForNamespace("ns1").Name())
+	assert.Equal(t, "fab1", r.NamedForNamespace("ns2").Name())
+	assert.Equal(t, "eth1", r.NamedForNamespace("ff_system").Name())
+	assert.Equal(t, plugins, r.Plugins())
+}
+
+func TestRouterUnknownDefault(t *testing.T) {
+	_, err := NewRouter(context.Background(), map[string]blockchain.Plugin{}, "eth1", nil)
+	assert.Regexp(t, "FF10110.*eth1", err)
+}
+
+func TestRouterUnknownNamespacePlugin(t *testing.T) {
+	_, err := NewRouter(context.Background(), map[string]blockchain.Plugin{
+		"eth1": &blockchainmocks.Plugin{},
+	}, "eth1", map[string]string{"ns1": "fab1"})
+	assert.Regexp(t, "FF10405.*ns1.*fab1", err)
+}
@@ -32,6 +32,9 @@ const (
 	defaultAddressResolverResponseField = "address"
 	defaultAddressResolverCacheSize     = 1000
 	defaultAddressResolverCacheTTL      = "24h"
+
+	defaultConfirmationsRequired        = 0
+	defaultConfirmationsPollingInterval = "1s"
 )
 
 const (
@@ -67,6 +70,15 @@ const (
 	AddressResolverCacheSize = "cache.size"
 	// AddressResolverCacheTTL the TTL on cache entries
 	AddressResolverCacheTTL = "cache.ttl"
+
+	// ConfirmationsConfigKey is a sub-key in the config to contain the block confirmation config
+	ConfirmationsConfigKey = "confirmations"
+	// ConfirmationsRequired the number of blocks that must be mined on top of the block containing an event, before the event is processed (default 0)
+	ConfirmationsRequired = "required"
+	// ConfirmationsPollingInterval how often to query the node for the latest block, while waiting for confirmations
+	ConfirmationsPollingInterval = "pollingInterval"
+	// ConfirmationsRPCConfigKey is a sub-key containing the HTTP config for the JSON/RPC endpoint of an Ethereum node, used to query blocks
+	ConfirmationsRPCConfigKey = "rpc"
 )
 
 func (e *Ethereum) InitPrefix(prefix config.Prefix) {
@@ -88,4 +100,9 @@ func (e *Ethereum) InitPrefix(prefix config.Prefix) {
 	addressResolverConf.AddKnownKey(AddressResolverResponseField, defaultAddressResolverResponseField)
 	addressResolverConf.AddKnownKey(AddressResolverCacheSize, defaultAddressResolverCacheSize)
 	addressResolverConf.AddKnownKey(AddressResolverCacheTTL, defaultAddressResolverCacheTTL)
+
+	confirmationsConf := prefix.SubPrefix(ConfirmationsConfigKey)
+	confirmationsConf.AddKnownKey(ConfirmationsRequired, defaultConfirmationsRequired)
+	confirmationsConf.AddKnownKey(ConfirmationsPollingInterval, defaultConfirmationsPollingInterval)
+	restclient.InitPrefix(confirmationsConf.SubPrefix(ConfirmationsRPCConfigKey))
 }
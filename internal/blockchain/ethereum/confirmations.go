@@ -0,0 +1,185 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// confirmationManager holds back events until the block containing them has the configured number of
+// blocks mined on top of it, by polling the JSON/RPC endpoint of an Ethereum node for the head of the chain.
+// Once the depth is reached, the hash of the block is checked to detect the event being re-organized away.
+type confirmationManager struct {
+	client          *resty.Client
+	required        int64
+	pollingInterval time.Duration
+	mux             sync.Mutex
+	headBlock       int64
+}
+
+type jsonRPCRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int64         `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type jsonRPCResponse struct {
+	Result interface{}        `json:"result"`
+	Error  fftypes.JSONObject `json:"error,omitempty"`
+}
+
+func newConfirmationManager(ctx context.Context, prefix config.Prefix) (*confirmationManager, error) {
+	rpcConf := prefix.SubPrefix(ConfirmationsRPCConfigKey)
+	if rpcConf.GetString(restclient.HTTPConfigURL) == "" {
+		return nil, i18n.NewError(ctx, i18n.MsgMissingPluginConfig, "url", "blockchain.ethereum.confirmations.rpc")
+	}
+	return &confirmationManager{
+		client:          restclient.New(ctx, rpcConf),
+		required:        prefix.GetInt64(ConfirmationsRequired),
+		pollingInterval: prefix.GetDuration(ConfirmationsPollingInterval),
+	}, nil
+}
+
+func (cm *confirmationManager) waitForConfirmations(ctx context.Context, event *blockchain.Event) (bool, error) {
+	blockNumber, err := strconv.ParseInt(event.Info.GetString("blockNumber"), 10, 64)
+	if err != nil {
+		log.L(ctx).Warnf("Unable to wait for confirmations of event %s - invalid block number: %+v", event.ProtocolID, event.Info)
+		return true, nil
+	}
+
+	target := blockNumber + cm.required
+	for {
+		head, err := cm.getHeadBlock(ctx, target)
+		if err == nil && head >= target {
+			break
+		}
+		if err != nil {
+			log.L(ctx).Warnf("Failed to query head block while waiting for confirmations of event %s: %s", event.ProtocolID, err)
+		} else {
+			log.L(ctx).Debugf("Waiting for confirmations of event %s: block=%d head=%d required=%d", event.ProtocolID, blockNumber, head, cm.required)
+		}
+		if err := cm.wait(ctx); err != nil {
+			return false, err
+		}
+	}
+
+	blockHash := event.Info.GetString("blockHash")
+	if blockHash == "" {
+		return true, nil
+	}
+	for {
+		block, err := cm.getBlockByNumber(ctx, blockNumber)
+		if err == nil {
+			if !strings.EqualFold(block.GetString("hash"), blockHash) {
+				log.L(ctx).Warnf("Event %s is no longer on the chain: block %d hash=%s expected=%s", event.ProtocolID, blockNumber, block.GetString("hash"), blockHash)
+				return false, nil
+			}
+			return true, nil
+		}
+		log.L(ctx).Warnf("Failed to query block %d while confirming event %s: %s", blockNumber, event.ProtocolID, err)
+		if err := cm.wait(ctx); err != nil {
+			return false, err
+		}
+	}
+}
+
+func (cm *confirmationManager) wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return i18n.NewError(ctx, i18n.MsgContextCanceled)
+	case <-time.After(cm.pollingInterval):
+		return nil
+	}
+}
+
+// getHeadBlock returns the cached head of the chain if it satisfies the target, and otherwise queries the node
+func (cm *confirmationManager) getHeadBlock(ctx context.Context, target int64) (int64, error) {
+	cm.mux.Lock()
+	head := cm.headBlock
+	cm.mux.Unlock()
+	if head >= target {
+		return head, nil
+	}
+
+	var hexHead string
+	if err := cm.invokeJSONRPC(ctx, "eth_blockNumber", &hexHead); err != nil {
+		return -1, err
+	}
+	head, err := strconv.ParseInt(strings.TrimPrefix(hexHead, "0x"), 16, 64)
+	if err != nil {
+		return -1, i18n.NewError(ctx, i18n.MsgJSONRPCError, "eth_blockNumber", err)
+	}
+
+	cm.mux.Lock()
+	if head > cm.headBlock {
+		cm.headBlock = head
+	}
+	cm.mux.Unlock()
+	return head, nil
+}
+
+func (cm *confirmationManager) getBlockByNumber(ctx context.Context, blockNumber int64) (fftypes.JSONObject, error) {
+	var block fftypes.JSONObject
+	if err := cm.invokeJSONRPC(ctx, "eth_getBlockByNumber", &block, fmt.Sprintf("0x%x", blockNumber), false); err != nil {
+		return nil, err
+	}
+	if block == nil {
+		block = fftypes.JSONObject{}
+	}
+	return block, nil
+}
+
+func (cm *confirmationManager) invokeJSONRPC(ctx context.Context, method string, result interface{}, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	rpcRes := &jsonRPCResponse{Result: result}
+	res, err := cm.client.R().
+		SetContext(ctx).
+		SetBody(&jsonRPCRequest{
+			JSONRPC: "2.0",
+			ID:      time.Now().UnixNano(),
+			Method:  method,
+			Params:  params,
+		}).
+		SetResult(rpcRes).
+		Post("/")
+	if err != nil {
+		return i18n.NewError(ctx, i18n.MsgJSONRPCError, method, err)
+	}
+	if res.IsError() {
+		return i18n.NewError(ctx, i18n.MsgJSONRPCError, method, res.String())
+	}
+	if rpcRes.Error != nil {
+		return i18n.NewError(ctx, i18n.MsgJSONRPCError, method, rpcRes.Error.GetString("message"))
+	}
+	return nil
+}
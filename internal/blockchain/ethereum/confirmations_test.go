@@ -0,0 +1,201 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func utConfirmationsConfigPrefix(url string) config.Prefix {
+	config.Reset()
+	prefix := config.NewPluginConfig("utconfirmations")
+	(&Ethereum{}).InitPrefix(prefix)
+	confirmationsConf := prefix.SubPrefix(ConfirmationsConfigKey)
+	confirmationsConf.Set(ConfirmationsRequired, 5)
+	confirmationsConf.Set(ConfirmationsPollingInterval, "1ms")
+	confirmationsConf.SubPrefix(ConfirmationsRPCConfigKey).Set(restclient.HTTPConfigURL, url)
+	return confirmationsConf
+}
+
+func newTestConfirmationServer(t *testing.T, handler func(method string, params []interface{}) (int, string)) (*confirmationManager, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var req jsonRPCRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		assert.NoError(t, err)
+		assert.Equal(t, "2.0", req.JSONRPC)
+		status, body := handler(req.Method, req.Params)
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(status)
+		rw.Write([]byte(body))
+	}))
+	cm, err := newConfirmationManager(context.Background(), utConfirmationsConfigPrefix(server.URL))
+	assert.NoError(t, err)
+	return cm, server.Close
+}
+
+func testConfirmationEvent(blockHash string) *blockchain.Event {
+	info := fftypes.JSONObject{
+		"blockNumber": "100",
+	}
+	if blockHash != "" {
+		info["blockHash"] = blockHash
+	}
+	return &blockchain.Event{
+		ProtocolID: "000000000100/000000/000000",
+		Info:       info,
+	}
+}
+
+func TestNewConfirmationManagerMissingURL(t *testing.T) {
+	_, err := newConfirmationManager(context.Background(), utConfirmationsConfigPrefix(""))
+	assert.Regexp(t, "FF10138.*url", err)
+}
+
+func TestWaitForConfirmationsOK(t *testing.T) {
+	heads := []string{"0x64", "0x68", "0x69"}
+	blockNumberCalls := 0
+	cm, done := newTestConfirmationServer(t, func(method string, params []interface{}) (int, string) {
+		switch method {
+		case "eth_blockNumber":
+			head := heads[blockNumberCalls]
+			blockNumberCalls++
+			return 200, `{"jsonrpc":"2.0","id":1,"result":"` + head + `"}`
+		case "eth_getBlockByNumber":
+			assert.Equal(t, []interface{}{"0x64", false}, params)
+			return 200, `{"jsonrpc":"2.0","id":1,"result":{"number":"0x64","hash":"0xABCD"}}`
+		}
+		return 404, `{}`
+	})
+	defer done()
+
+	confirmed, err := cm.waitForConfirmations(context.Background(), testConfirmationEvent("0xabcd"))
+	assert.NoError(t, err)
+	assert.True(t, confirmed)
+	assert.Equal(t, 3, blockNumberCalls)
+	assert.Equal(t, int64(105), cm.headBlock)
+
+	// A second event in an earlier block is satisfied by the cached head
+	event := testConfirmationEvent("")
+	event.Info["blockNumber"] = "99"
+	confirmed, err = cm.waitForConfirmations(context.Background(), event)
+	assert.NoError(t, err)
+	assert.True(t, confirmed)
+	assert.Equal(t, 3, blockNumberCalls)
+}
+
+func TestWaitForConfirmationsHashMismatch(t *testing.T) {
+	cm, done := newTestConfirmationServer(t, func(method string, params []interface{}) (int, string) {
+		switch method {
+		case "eth_blockNumber":
+			return 200, `{"jsonrpc":"2.0","id":1,"result":"0x100"}`
+		default:
+			return 200, `{"jsonrpc":"2.0","id":1,"result":{"hash":"0x1234"}}`
+		}
+	})
+	defer done()
+
+	confirmed, err := cm.waitForConfirmations(context.Background(), testConfirmationEvent("0xabcd"))
+	assert.NoError(t, err)
+	assert.False(t, confirmed)
+}
+
+func TestWaitForConfirmationsBlockMissing(t *testing.T) {
+	cm, done := newTestConfirmationServer(t, func(method string, params []interface{}) (int, string) {
+		switch method {
+		case "eth_blockNumber":
+			return 200, `{"jsonrpc":"2.0","id":1,"result":"0x100"}`
+		default:
+			return 200, `{"jsonrpc":"2.0","id":1,"result":null}`
+		}
+	})
+	defer done()
+
+	confirmed, err := cm.waitForConfirmations(context.Background(), testConfirmationEvent("0xabcd"))
+	assert.NoError(t, err)
+	assert.False(t, confirmed)
+}
+
+func TestWaitForConfirmationsBadBlockNumber(t *testing.T) {
+	cm := &confirmationManager{required: 5}
+	event := testConfirmationEvent("")
+	event.Info["blockNumber"] = "not a number"
+	confirmed, err := cm.waitForConfirmations(context.Background(), event)
+	assert.NoError(t, err)
+	assert.True(t, confirmed)
+}
+
+func TestWaitForConfirmationsHeadErrorsCancelled(t *testing.T) {
+	calls := 0
+	ctx, cancel := context.WithCancel(context.Background())
+	cm, done := newTestConfirmationServer(t, func(method string, params []interface{}) (int, string) {
+		calls++
+		switch calls {
+		case 1:
+			return 500, `{"error":"pop"}`
+		case 2:
+			return 200, `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"pop"}}`
+		case 3:
+			return 200, `{"jsonrpc":"2.0","id":1,"result":"not hex"}`
+		default:
+			cancel()
+			return 200, `{"jsonrpc":"2.0","id":1,"result":"0x64"}`
+		}
+	})
+	defer done()
+
+	_, err := cm.waitForConfirmations(ctx, testConfirmationEvent("0xabcd"))
+	assert.Regexp(t, "FF10158", err)
+	assert.Equal(t, 4, calls)
+}
+
+func TestWaitForConfirmationsBlockErrorsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cm, done := newTestConfirmationServer(t, func(method string, params []interface{}) (int, string) {
+		switch method {
+		case "eth_blockNumber":
+			return 200, `{"jsonrpc":"2.0","id":1,"result":"0x100"}`
+		default:
+			cancel()
+			return 500, `{"error":"pop"}`
+		}
+	})
+	defer done()
+
+	_, err := cm.waitForConfirmations(ctx, testConfirmationEvent("0xabcd"))
+	assert.Regexp(t, "FF10158", err)
+}
+
+func TestInvokeJSONRPCFail(t *testing.T) {
+	cm, done := newTestConfirmationServer(t, func(method string, params []interface{}) (int, string) {
+		return 200, `{}`
+	})
+	done()
+
+	var result string
+	err := cm.invokeJSONRPC(context.Background(), "eth_blockNumber", &result)
+	assert.Regexp(t, "FF10438.*eth_blockNumber", err)
+}
@@ -17,10 +17,12 @@
 package ethereum
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"regexp"
 	"strings"
 
@@ -62,6 +64,7 @@ type Ethereum struct {
 	wsconn          wsclient.WSClient
 	closed          chan struct{}
 	addressResolver *addressResolver
+	confirmations   *confirmationManager
 }
 
 type eventStreamWebsocket struct {
@@ -122,11 +125,20 @@ type ABIElementMarshaling struct {
 }
 
 type EthconnectMessageRequest struct {
-	Headers EthconnectMessageHeaders `json:"headers,omitempty"`
-	To      string                   `json:"to"`
-	From    string                   `json:"from,omitempty"`
-	Method  ABIElementMarshaling     `json:"method"`
-	Params  []interface{}            `json:"params"`
+	Headers     EthconnectMessageHeaders `json:"headers,omitempty"`
+	To          string                   `json:"to"`
+	From        string                   `json:"from,omitempty"`
+	Method      ABIElementMarshaling     `json:"method"`
+	Params      []interface{}            `json:"params"`
+	BlockNumber string                   `json:"blockNumber,omitempty"`
+}
+
+type EthconnectDeployRequest struct {
+	Headers  EthconnectMessageHeaders `json:"headers,omitempty"`
+	From     string                   `json:"from,omitempty"`
+	ABI      []ABIElementMarshaling   `json:"abi"`
+	Compiled []byte                   `json:"compiled"`
+	Params   []interface{}            `json:"params"`
 }
 
 type EthconnectMessageHeaders struct {
@@ -153,6 +165,7 @@ func (e *Ethereum) Init(ctx context.Context, prefix config.Prefix, callbacks blo
 
 	ethconnectConf := prefix.SubPrefix(EthconnectConfigKey)
 	addressResolverConf := prefix.SubPrefix(AddressResolverConfigKey)
+	confirmationsConf := prefix.SubPrefix(ConfirmationsConfigKey)
 
 	e.ctx = log.WithLogField(ctx, "proto", "ethereum")
 	e.callbacks = callbacks
@@ -163,6 +176,12 @@ func (e *Ethereum) Init(ctx context.Context, prefix config.Prefix, callbacks blo
 		}
 	}
 
+	if confirmationsConf.GetInt64(ConfirmationsRequired) > 0 {
+		if e.confirmations, err = newConfirmationManager(ctx, confirmationsConf); err != nil {
+			return err
+		}
+	}
+
 	if ethconnectConf.GetString(restclient.HTTPConfigURL) == "" {
 		return i18n.NewError(ctx, i18n.MsgMissingPluginConfig, "url", "blockchain.ethconnect")
 	}
@@ -170,6 +189,7 @@ func (e *Ethereum) Init(ctx context.Context, prefix config.Prefix, callbacks blo
 	e.client = restclient.New(e.ctx, ethconnectConf)
 	e.capabilities = &blockchain.Capabilities{
 		GlobalSequencer: true,
+		HistoricalQuery: true,
 	}
 
 	e.instancePath = ethconnectConf.GetString(EthconnectConfigInstancePath)
@@ -239,6 +259,13 @@ func (e *Ethereum) Capabilities() *blockchain.Capabilities {
 	return e.capabilities
 }
 
+func (e *Ethereum) HealthCheck(ctx context.Context) error {
+	if !e.wsconn.Connected() {
+		return i18n.NewError(ctx, i18n.MsgWSNotConnected, e.Name())
+	}
+	return nil
+}
+
 func (e *Ethereum) afterConnect(ctx context.Context, w wsclient.WSClient) error {
 	// Send a subscribe to our topic after each connect/reconnect
 	b, _ := json.Marshal(&ethWSCommandPayload{
@@ -384,6 +411,27 @@ func (e *Ethereum) handleContractEvent(ctx context.Context, msgJSON fftypes.JSON
 	return e.callbacks.BlockchainEvent(event)
 }
 
+// handleRemovedEvent notifies of an event that ethconnect has reported as removed, due to a chain reorganization.
+// The event is identified in the same way as when it was first delivered.
+func (e *Ethereum) handleRemovedEvent(ctx context.Context, msgJSON fftypes.JSONObject) (err error) {
+	blockNumber := msgJSON.GetInt64("blockNumber")
+	txIndex := msgJSON.GetInt64("transactionIndex")
+	logIndex := msgJSON.GetInt64("logIndex")
+	signature := msgJSON.GetString("signature")
+	delete(msgJSON, "data")
+
+	event := &blockchain.Event{
+		BlockchainTXID: msgJSON.GetString("transactionHash"),
+		Source:         e.Name(),
+		Name:           strings.SplitN(signature, "(", 2)[0],
+		ProtocolID:     fmt.Sprintf("%.12d/%.6d/%.6d", blockNumber, txIndex, logIndex),
+		Info:           msgJSON,
+	}
+	log.L(ctx).Warnf("Event %s removed from the chain: %+v", event.ProtocolID, msgJSON)
+
+	return e.callbacks.BlockchainEventRemoved(event)
+}
+
 func (e *Ethereum) handleReceipt(ctx context.Context, reply fftypes.JSONObject) error {
 	l := log.L(ctx)
 
@@ -406,7 +454,18 @@ func (e *Ethereum) handleReceipt(ctx context.Context, reply fftypes.JSONObject)
 		updateType = fftypes.OpStatusFailed
 	}
 	l.Infof("Ethconnect '%s' reply: request=%s tx=%s message=%s", replyType, requestID, txHash, message)
-	return e.callbacks.BlockchainOpUpdate(operationID, updateType, txHash, message, reply)
+	return e.callbacks.BlockchainOpUpdate(operationID, updateType, txHash, message, addDeployedLocation(reply))
+}
+
+// addDeployedLocation adds the location of a newly deployed contract to a receipt, in the same
+// format that is used to refer to contracts elsewhere in FireFly
+func addDeployedLocation(reply fftypes.JSONObject) fftypes.JSONObject {
+	if address := reply.GetString("contractAddress"); address != "" {
+		reply["location"] = fftypes.JSONObject{
+			"address": address,
+		}
+	}
+	return reply
 }
 
 func (e *Ethereum) handleMessageBatch(ctx context.Context, messages []interface{}) error {
@@ -427,7 +486,11 @@ func (e *Ethereum) handleMessageBatch(ctx context.Context, messages []interface{
 		l1.Infof("Received '%s' message", signature)
 		l1.Tracef("Message: %+v", msgJSON)
 
-		if sub == e.initInfo.sub.ID {
+		if msgJSON.GetBool("removed") {
+			if err := e.handleRemovedEvent(ctx1, msgJSON); err != nil {
+				return err
+			}
+		} else if sub == e.initInfo.sub.ID {
 			switch signature {
 			case broadcastBatchEventSignature:
 				if err := e.handleBatchPinEvent(ctx1, msgJSON); err != nil {
@@ -450,11 +513,20 @@ func (e *Ethereum) eventLoop() {
 	l := log.L(e.ctx).WithField("role", "event-loop")
 	ctx := log.WithLogger(e.ctx, l)
 	ack, _ := json.Marshal(map[string]string{"type": "ack", "topic": e.topic})
+	// The ack for a batch is held back until its events have been processed, which might be after waiting for
+	// confirmations - but receipts continue to be handled in the meantime
+	var dispatched <-chan struct{}
 	for {
 		select {
 		case <-ctx.Done():
 			l.Debugf("Event loop exiting (context cancelled)")
 			return
+		case <-dispatched:
+			dispatched = nil
+			if err := e.wsconn.Send(ctx, ack); err != nil {
+				l.Errorf("Event loop exiting: %s", err)
+				return
+			}
 		case msgBytes, ok := <-e.wsconn.Receive():
 			if !ok {
 				l.Debugf("Event loop exiting (receive channel closed)")
@@ -471,7 +543,7 @@ func (e *Ethereum) eventLoop() {
 			case []interface{}:
 				err = e.handleMessageBatch(ctx, msgTyped)
 				if err == nil {
-					err = e.wsconn.Send(ctx, ack)
+					dispatched = e.callbacks.EventsDispatched()
 				}
 			case map[string]interface{}:
 				err = e.handleReceipt(ctx, fftypes.JSONObject(msgTyped))
@@ -480,7 +552,6 @@ func (e *Ethereum) eventLoop() {
 				continue
 			}
 
-			// Send the ack - only fails if shutting down
 			if err != nil {
 				l.Errorf("Event loop exiting: %s", err)
 				return
@@ -527,14 +598,15 @@ func (e *Ethereum) invokeContractMethod(ctx context.Context, address, signingKey
 		Post("/")
 }
 
-func (e *Ethereum) queryContractMethod(ctx context.Context, address string, abi ABIElementMarshaling, input []interface{}) (*resty.Response, error) {
+func (e *Ethereum) queryContractMethod(ctx context.Context, address string, abi ABIElementMarshaling, input []interface{}, block string) (*resty.Response, error) {
 	body := EthconnectMessageRequest{
 		Headers: EthconnectMessageHeaders{
 			Type: "Query",
 		},
-		To:     address,
-		Method: abi,
-		Params: input,
+		To:          address,
+		Method:      abi,
+		Params:      input,
+		BlockNumber: block,
 	}
 	return e.client.R().
 		SetContext(ctx).
@@ -580,7 +652,55 @@ func (e *Ethereum) InvokeContract(ctx context.Context, operationID *fftypes.UUID
 	return nil
 }
 
-func (e *Ethereum) QueryContract(ctx context.Context, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}) (interface{}, error) {
+func (e *Ethereum) parseDeployContract(ctx context.Context, definition, contract *fftypes.JSONAny) (abi []ABIElementMarshaling, compiled []byte, err error) {
+	if err := json.Unmarshal(definition.Bytes(), &abi); err != nil {
+		return nil, nil, i18n.WrapError(ctx, err, i18n.MsgJSONObjectParseFailed, "definition")
+	}
+	var bytecode string
+	if err := json.Unmarshal(contract.Bytes(), &bytecode); err != nil {
+		return nil, nil, i18n.NewError(ctx, i18n.MsgContractBytecodeInvalid, err)
+	}
+	compiled, err = hex.DecodeString(strings.TrimPrefix(bytecode, "0x"))
+	if err != nil || len(compiled) == 0 {
+		return nil, nil, i18n.NewError(ctx, i18n.MsgContractBytecodeInvalid, "must be a non-empty hex string")
+	}
+	return abi, compiled, nil
+}
+
+func (e *Ethereum) ValidateContractDeploy(ctx context.Context, definition, contract *fftypes.JSONAny) error {
+	_, _, err := e.parseDeployContract(ctx, definition, contract)
+	return err
+}
+
+func (e *Ethereum) DeployContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, definition, contract *fftypes.JSONAny, input []interface{}) error {
+	abi, compiled, err := e.parseDeployContract(ctx, definition, contract)
+	if err != nil {
+		return err
+	}
+	if input == nil {
+		input = []interface{}{}
+	}
+	body := EthconnectDeployRequest{
+		Headers: EthconnectMessageHeaders{
+			Type: "DeployContract",
+			ID:   operationID.String(),
+		},
+		From:     signingKey,
+		ABI:      abi,
+		Compiled: compiled,
+		Params:   input,
+	}
+	res, err := e.client.R().
+		SetContext(ctx).
+		SetBody(body).
+		Post("/")
+	if err != nil || !res.IsSuccess() {
+		return restclient.WrapRestErr(ctx, res, err, i18n.MsgEthconnectRESTErr)
+	}
+	return nil
+}
+
+func (e *Ethereum) QueryContract(ctx context.Context, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}, block string) (interface{}, error) {
 	ethereumLocation, err := parseContractLocation(ctx, location)
 	if err != nil {
 		return nil, err
@@ -589,7 +709,7 @@ func (e *Ethereum) QueryContract(ctx context.Context, location *fftypes.JSONAny,
 	if err != nil {
 		return nil, err
 	}
-	res, err := e.queryContractMethod(ctx, ethereumLocation.Address, abi, orderedInput)
+	res, err := e.queryContractMethod(ctx, ethereumLocation.Address, abi, orderedInput, block)
 	if err != nil || !res.IsSuccess() {
 		return nil, restclient.WrapRestErr(ctx, res, err, i18n.MsgEthconnectRESTErr)
 	}
@@ -600,6 +720,44 @@ func (e *Ethereum) QueryContract(ctx context.Context, location *fftypes.JSONAny,
 	return output, nil
 }
 
+// GetOperationStatus queries the Ethconnect receipt store for the outcome of a submitted transaction.
+// A receipt is only stored once the transaction has completed, so no receipt means no record of the outcome yet -
+// the transaction might still be waiting to be mined.
+func (e *Ethereum) GetOperationStatus(ctx context.Context, operationID *fftypes.UUID) (*fftypes.OperationUpdate, error) {
+	var reply fftypes.JSONObject
+	res, err := e.client.R().
+		SetContext(ctx).
+		SetResult(&reply).
+		Get("/reply/" + operationID.String())
+	if err == nil && res.StatusCode() == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil || !res.IsSuccess() {
+		return nil, restclient.WrapRestErr(ctx, res, err, i18n.MsgEthconnectRESTErr)
+	}
+	update := &fftypes.OperationUpdate{
+		Status:         fftypes.OpStatusSucceeded,
+		BlockchainTXID: reply.GetString("transactionHash"),
+		ErrorMessage:   reply.GetString("errorMessage"),
+		Output:         addDeployedLocation(reply),
+	}
+	if reply.GetObject("headers").GetString("type") != "TransactionSuccess" {
+		update.Status = fftypes.OpStatusFailed
+	}
+	return update, nil
+}
+
+func (e *Ethereum) RequiresConfirmations() bool {
+	return e.confirmations != nil
+}
+
+func (e *Ethereum) WaitForConfirmations(ctx context.Context, event *blockchain.Event) (bool, error) {
+	if e.confirmations == nil {
+		return true, nil
+	}
+	return e.confirmations.waitForConfirmations(ctx, event)
+}
+
 func (e *Ethereum) ValidateContractLocation(ctx context.Context, location *fftypes.JSONAny) (err error) {
 	_, err = parseContractLocation(ctx, location)
 	return
@@ -626,8 +784,13 @@ func (e *Ethereum) AddContractListener(ctx context.Context, listener *fftypes.Co
 		return i18n.WrapError(ctx, err, i18n.MsgContractParamInvalid)
 	}
 
+	filter, err := buildSubscriptionFilter(ctx, abi, listener.Options.Filter)
+	if err != nil {
+		return err
+	}
+
 	subName := fmt.Sprintf("ff-sub-%s", listener.ID)
-	result, err := e.streams.createSubscription(ctx, location, e.initInfo.stream.ID, subName, listener.Options.FirstEvent, abi)
+	result, err := e.streams.createSubscription(ctx, location, e.initInfo.stream.ID, subName, listener.Options.FirstEvent, abi, filter)
 	if err != nil {
 		return err
 	}
@@ -765,7 +928,14 @@ func (e *Ethereum) getContractAddress(ctx context.Context, instancePath string)
 
 func (e *Ethereum) GenerateFFI(ctx context.Context, generationRequest *fftypes.FFIGenerationRequest) (*fftypes.FFI, error) {
 	var input FFIGenerationInput
-	err := json.Unmarshal(generationRequest.Input.Bytes(), &input)
+	var err error
+	b := bytes.TrimSpace(generationRequest.Input.Bytes())
+	if len(b) > 0 && b[0] == '[' {
+		// A bare ABI is also accepted, as used for the definition of a contract deployment
+		err = json.Unmarshal(b, &input.ABI)
+	} else {
+		err = json.Unmarshal(b, &input)
+	}
 	if err != nil {
 		return nil, i18n.NewError(ctx, i18n.MsgFFIGenerationFailed, "unable to deserialize JSON as ABI")
 	}
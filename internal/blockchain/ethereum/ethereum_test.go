@@ -18,6 +18,7 @@ package ethereum
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -93,6 +94,20 @@ func newTestEthereum() (*Ethereum, func()) {
 	}
 }
 
+func TestHealthCheck(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	wsm := e.wsconn.(*wsmocks.WSClient)
+	wsm.On("Connected").Return(true).Once()
+	err := e.HealthCheck(context.Background())
+	assert.NoError(t, err)
+
+	wsm.On("Connected").Return(false).Once()
+	err = e.HealthCheck(context.Background())
+	assert.Regexp(t, "FF10420.*ethereum", err)
+	wsm.AssertExpectations(t)
+}
+
 func TestInitMissingURL(t *testing.T) {
 	e, cancel := newTestEthereum()
 	defer cancel()
@@ -110,6 +125,15 @@ func TestInitBadAddressResolver(t *testing.T) {
 	assert.Regexp(t, "FF10337.*urlTemplate", err)
 }
 
+func TestInitConfirmationsMissingURL(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	resetConf()
+	utConfPrefix.SubPrefix(ConfirmationsConfigKey).Set(ConfirmationsRequired, 5)
+	err := e.Init(e.ctx, utConfPrefix, &blockchainmocks.Callbacks{})
+	assert.Regexp(t, "FF10138.*confirmations.rpc", err)
+}
+
 func TestInitMissingInstance(t *testing.T) {
 	e, cancel := newTestEthereum()
 	defer cancel()
@@ -169,7 +193,11 @@ func TestInitAllNewStreamsAndWSEvent(t *testing.T) {
 	utEthconnectConf.Set(EthconnectConfigInstancePath, "/instances/0x12345")
 	utEthconnectConf.Set(EthconnectConfigTopic, "topic1")
 
-	err := e.Init(e.ctx, utConfPrefix, &blockchainmocks.Callbacks{})
+	em := &blockchainmocks.Callbacks{}
+	dispatched := make(chan struct{})
+	close(dispatched)
+	em.On("EventsDispatched").Return((<-chan struct{})(dispatched))
+	err := e.Init(e.ctx, utConfPrefix, em)
 	assert.NoError(t, err)
 
 	assert.Equal(t, "ethereum", e.Name())
@@ -178,6 +206,7 @@ func TestInitAllNewStreamsAndWSEvent(t *testing.T) {
 	assert.Equal(t, "es12345", e.initInfo.stream.ID)
 	assert.Equal(t, "sub12345", e.initInfo.sub.ID)
 	assert.True(t, e.Capabilities().GlobalSequencer)
+	assert.True(t, e.Capabilities().HistoricalQuery)
 
 	err = e.Start()
 	assert.NoError(t, err)
@@ -1088,6 +1117,56 @@ func TestEventLoopSendClosed(t *testing.T) {
 	e.eventLoop() // we're simply looking for it exiting
 }
 
+func TestEventLoopAckAfterDispatch(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	r := make(chan []byte)
+	wsm := e.wsconn.(*wsmocks.WSClient)
+	wsm.On("Receive").Return((<-chan []byte)(r))
+	wsm.On("Close").Return()
+	acked := make(chan struct{})
+	wsm.On("Send", mock.Anything, []byte(`{"topic":"topic1","type":"ack"}`)).Return(nil).Run(func(args mock.Arguments) {
+		close(acked)
+	})
+	em := e.callbacks.(*blockchainmocks.Callbacks)
+	dispatched := make(chan struct{})
+	em.On("EventsDispatched").Return((<-chan struct{})(dispatched))
+	receipt := make(chan struct{})
+	em.On("BlockchainOpUpdate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		close(receipt)
+	})
+	e.closed = make(chan struct{})
+	go e.eventLoop()
+
+	r <- []byte(`[]`)
+	// Receipts are still handled while the ack is held back
+	r <- []byte(`{"headers":{"requestId":"` + fftypes.NewUUID().String() + `","type":"TransactionSuccess"}}`)
+	<-receipt
+	wsm.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+
+	close(dispatched)
+	<-acked
+}
+
+func TestEventLoopAckFail(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	r := make(chan []byte)
+	wsm := e.wsconn.(*wsmocks.WSClient)
+	wsm.On("Receive").Return((<-chan []byte)(r))
+	wsm.On("Close").Return()
+	wsm.On("Send", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	em := e.callbacks.(*blockchainmocks.Callbacks)
+	dispatched := make(chan struct{})
+	close(dispatched)
+	em.On("EventsDispatched").Return((<-chan struct{})(dispatched))
+	e.closed = make(chan struct{})
+	go e.eventLoop()
+
+	r <- []byte(`[]`)
+	<-e.closed
+}
+
 func TestHandleReceiptTXSuccess(t *testing.T) {
 	em := &blockchainmocks.Callbacks{}
 	wsm := &wsmocks.WSClient{}
@@ -1137,6 +1216,39 @@ func TestHandleReceiptTXSuccess(t *testing.T) {
 
 }
 
+func TestHandleReceiptDeploySuccess(t *testing.T) {
+	em := &blockchainmocks.Callbacks{}
+	e := &Ethereum{
+		ctx:       context.Background(),
+		topic:     "topic1",
+		callbacks: em,
+	}
+
+	operationID := fftypes.NewUUID()
+	reply := fftypes.JSONObject{
+		"headers": fftypes.JSONObject{
+			"requestId": operationID.String(),
+			"type":      "TransactionSuccess",
+		},
+		"contractAddress": "0x3c1bef20a7858f5c2f78bda60796758d7cafff27",
+		"transactionHash": "0x71a38acb7a5d4a970854f6d638ceb1fa10a4b59cbf4ed7674273a1a8dc8b36b8",
+	}
+
+	em.On("BlockchainOpUpdate",
+		operationID,
+		fftypes.OpStatusSucceeded,
+		"0x71a38acb7a5d4a970854f6d638ceb1fa10a4b59cbf4ed7674273a1a8dc8b36b8",
+		"",
+		mock.MatchedBy(func(output fftypes.JSONObject) bool {
+			return output.GetObject("location").GetString("address") == "0x3c1bef20a7858f5c2f78bda60796758d7cafff27"
+		})).Return(nil)
+
+	err := e.handleReceipt(context.Background(), reply)
+	assert.NoError(t, err)
+
+	em.AssertExpectations(t)
+}
+
 func TestHandleBadPayloadsAndThenReceiptFailure(t *testing.T) {
 	e, cancel := newTestEthereum()
 	defer cancel()
@@ -1268,6 +1380,97 @@ func TestAddSubscription(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestAddSubscriptionWithFilter(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.initInfo.stream = &eventStream{
+		ID: "es-1",
+	}
+	e.streams = &streamManager{
+		client: e.client,
+	}
+
+	sub := &fftypes.ContractListenerInput{
+		ContractListener: fftypes.ContractListener{
+			Location: fftypes.JSONAnyPtr(fftypes.JSONObject{
+				"address": "0x123",
+			}.String()),
+			Event: &fftypes.FFISerializedEvent{
+				FFIEventDefinition: fftypes.FFIEventDefinition{
+					Name: "Transfer",
+					Params: fftypes.FFIParams{
+						{
+							Name:   "from",
+							Schema: fftypes.JSONAnyPtr(`{"type": "string", "details": {"type": "address", "indexed": true}}`),
+						},
+						{
+							Name:   "to",
+							Schema: fftypes.JSONAnyPtr(`{"type": "string", "details": {"type": "address", "indexed": true}}`),
+						},
+						{
+							Name:   "value",
+							Schema: fftypes.JSONAnyPtr(`{"type": "integer", "details": {"type": "uint256"}}`),
+						},
+					},
+				},
+			},
+			Options: &fftypes.ContractListenerOptions{
+				FirstEvent: string(fftypes.SubOptsFirstEventNewest),
+				Filter: fftypes.JSONObject{
+					"from":  "0x91D2B4381A4CD5C7C0F27565A7D4B829844C8635",
+					"value": "100",
+				},
+			},
+		},
+	}
+
+	httpmock.RegisterResponder("POST", `http://localhost:12345/subscriptions`,
+		func(req *http.Request) (*http.Response, error) {
+			var body subscription
+			json.NewDecoder(req.Body).Decode(&body)
+			assert.Equal(t, [][]string{nil, {"0x00000000000000000000000091d2b4381a4cd5c7c0f27565a7d4b829844c8635"}}, body.Filter.Topics)
+			return httpmock.NewJsonResponderOrPanic(200, subscription{ID: "sub1"})(req)
+		})
+
+	err := e.AddContractListener(context.Background(), sub)
+	assert.NoError(t, err)
+	assert.Equal(t, "sub1", sub.ProtocolID)
+}
+
+func TestAddSubscriptionBadFilter(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+
+	sub := &fftypes.ContractListenerInput{
+		ContractListener: fftypes.ContractListener{
+			Location: fftypes.JSONAnyPtr(fftypes.JSONObject{
+				"address": "0x123",
+			}.String()),
+			Event: &fftypes.FFISerializedEvent{
+				FFIEventDefinition: fftypes.FFIEventDefinition{
+					Name: "Changed",
+					Params: fftypes.FFIParams{
+						{
+							Name:   "value",
+							Schema: fftypes.JSONAnyPtr(`{"type": "string", "details": {"type": "string", "indexed": true}}`),
+						},
+					},
+				},
+			},
+			Options: &fftypes.ContractListenerOptions{
+				Filter: fftypes.JSONObject{
+					"value": "hello",
+				},
+			},
+		},
+	}
+
+	err := e.AddContractListener(context.Background(), sub)
+	assert.Regexp(t, "FF10441.*value.*string", err)
+}
+
 func TestAddSubscriptionBadParamDetails(t *testing.T) {
 	e, cancel := newTestEthereum()
 	defer cancel()
@@ -1480,6 +1683,71 @@ func TestHandleMessageContractEvent(t *testing.T) {
 	em.AssertExpectations(t)
 }
 
+func TestHandleMessageRemovedEvent(t *testing.T) {
+	data := fftypes.JSONAnyPtr(`
+[
+  {
+		"address": "0x1C197604587F046FD40684A8f21f4609FB811A7b",
+		"blockNumber": "38011",
+		"transactionIndex": "0x0",
+		"transactionHash": "0xc26df2bf1a733e9249372d61eb11bd8662d26c8129df76890b1beb2f6fa72628",
+		"data": {
+			"from": "0x91D2B4381A4CD5C7C0F27565A7D4B829844C8635",
+			"value": "1"
+    },
+		"subId": "sub2",
+		"signature": "Changed(address,uint256)",
+		"logIndex": "50",
+		"timestamp": "1640811383",
+		"removed": true
+  }
+]`)
+
+	em := &blockchainmocks.Callbacks{}
+	e := &Ethereum{
+		callbacks: em,
+	}
+	e.initInfo.sub = &subscription{
+		ID: "sb-b5b97a4e-a317-4053-6400-1474650efcb5",
+	}
+
+	em.On("BlockchainEventRemoved", mock.MatchedBy(func(ev *blockchain.Event) bool {
+		return ev.ProtocolID == "000000038011/000000/000050" &&
+			ev.Name == "Changed" &&
+			ev.Source == "ethereum" &&
+			ev.BlockchainTXID == "0xc26df2bf1a733e9249372d61eb11bd8662d26c8129df76890b1beb2f6fa72628"
+	})).Return(fmt.Errorf("pop"))
+
+	var events []interface{}
+	err := json.Unmarshal(data.Bytes(), &events)
+	assert.NoError(t, err)
+	err = e.handleMessageBatch(context.Background(), events)
+	assert.EqualError(t, err, "pop")
+
+	em.AssertExpectations(t)
+}
+
+func TestWaitForConfirmationsNotConfigured(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	assert.False(t, e.RequiresConfirmations())
+	confirmed, err := e.WaitForConfirmations(context.Background(), &blockchain.Event{})
+	assert.NoError(t, err)
+	assert.True(t, confirmed)
+}
+
+func TestWaitForConfirmationsConfigured(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	e.confirmations = &confirmationManager{required: 5, headBlock: 105}
+	assert.True(t, e.RequiresConfirmations())
+	confirmed, err := e.WaitForConfirmations(context.Background(), &blockchain.Event{
+		Info: fftypes.JSONObject{"blockNumber": "100"},
+	})
+	assert.NoError(t, err)
+	assert.True(t, confirmed)
+}
+
 func TestHandleMessageContractEventNoTimestamp(t *testing.T) {
 	data := fftypes.JSONAnyPtr(`
 [
@@ -1584,6 +1852,88 @@ func TestInvokeContractOK(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestDeployContractOK(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	signingKey := ethHexFormatB32(fftypes.NewRandB32())
+	opID := fftypes.NewUUID()
+	definition := fftypes.JSONAnyPtr(`[{"type":"constructor","inputs":[{"name":"x","type":"uint256"}]}]`)
+	httpmock.RegisterResponder("POST", `http://localhost:12345/`,
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			headers := body["headers"].(map[string]interface{})
+			assert.Equal(t, "DeployContract", headers["type"])
+			assert.Equal(t, opID.String(), headers["id"])
+			assert.Equal(t, signingKey, body["from"])
+			assert.Equal(t, base64.StdEncoding.EncodeToString([]byte{0x60, 0x80}), body["compiled"])
+			assert.Equal(t, "constructor", body["abi"].([]interface{})[0].(map[string]interface{})["type"])
+			assert.Equal(t, []interface{}{float64(1)}, body["params"])
+			return httpmock.NewJsonResponderOrPanic(200, "")(req)
+		})
+	err := e.DeployContract(context.Background(), opID, signingKey, definition, fftypes.JSONAnyPtr(`"0x6080"`), []interface{}{float64(1)})
+	assert.NoError(t, err)
+}
+
+func TestDeployContractNoInput(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", `http://localhost:12345/`,
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			assert.Equal(t, []interface{}{}, body["params"])
+			return httpmock.NewJsonResponderOrPanic(200, "")(req)
+		})
+	err := e.DeployContract(context.Background(), fftypes.NewUUID(), "0x123", fftypes.JSONAnyPtr(`[]`), fftypes.JSONAnyPtr(`"6080"`), nil)
+	assert.NoError(t, err)
+}
+
+func TestDeployContractBadDefinition(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	err := e.DeployContract(context.Background(), fftypes.NewUUID(), "0x123", fftypes.JSONAnyPtr(`{}`), fftypes.JSONAnyPtr(`"0x6080"`), nil)
+	assert.Regexp(t, "FF10151.*definition", err)
+}
+
+func TestDeployContractBytecodeNotString(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	err := e.DeployContract(context.Background(), fftypes.NewUUID(), "0x123", fftypes.JSONAnyPtr(`[]`), fftypes.JSONAnyPtr(`{}`), nil)
+	assert.Regexp(t, "FF10437", err)
+}
+
+func TestDeployContractBytecodeNotHex(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	err := e.DeployContract(context.Background(), fftypes.NewUUID(), "0x123", fftypes.JSONAnyPtr(`[]`), fftypes.JSONAnyPtr(`"0xzz"`), nil)
+	assert.Regexp(t, "FF10437", err)
+}
+
+func TestValidateContractDeploy(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	err := e.ValidateContractDeploy(context.Background(), fftypes.JSONAnyPtr(`[]`), fftypes.JSONAnyPtr(`"0x6080"`))
+	assert.NoError(t, err)
+	err = e.ValidateContractDeploy(context.Background(), fftypes.JSONAnyPtr(`[]`), fftypes.JSONAnyPtr(`"0x"`))
+	assert.Regexp(t, "FF10437", err)
+}
+
+func TestDeployContractFail(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", `http://localhost:12345/`,
+		httpmock.NewStringResponder(500, `pop`))
+	err := e.DeployContract(context.Background(), fftypes.NewUUID(), "0x123", fftypes.JSONAnyPtr(`[]`), fftypes.JSONAnyPtr(`"0x6080"`), nil)
+	assert.Regexp(t, "FF10111", err)
+}
+
 func TestInvokeContractAddressNotSet(t *testing.T) {
 	e, cancel := newTestEthereum()
 	defer cancel()
@@ -1671,13 +2021,37 @@ func TestQueryContractOK(t *testing.T) {
 			assert.Equal(t, "Query", headers["type"])
 			return httpmock.NewJsonResponderOrPanic(200, queryOutput{Output: "3"})(req)
 		})
-	result, err := e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params)
+	result, err := e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, "")
 	assert.NoError(t, err)
 	j, err := json.Marshal(result)
 	assert.NoError(t, err)
 	assert.Equal(t, `{"output":"3"}`, string(j))
 }
 
+func TestQueryContractAtBlockOK(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	location := &Location{
+		Address: "0x12345",
+	}
+	method := testFFIMethod()
+	params := map[string]interface{}{}
+	locationBytes, err := json.Marshal(location)
+	assert.NoError(t, err)
+	httpmock.RegisterResponder("POST", `http://localhost:12345/`,
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			assert.Equal(t, "38011", body["blockNumber"])
+			return httpmock.NewJsonResponderOrPanic(200, queryOutput{Output: "3"})(req)
+		})
+	result, err := e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, "38011")
+	assert.NoError(t, err)
+	assert.Equal(t, "3", result.(*queryOutput).Output)
+}
+
 func TestQueryContractErrorPrepare(t *testing.T) {
 	e, cancel := newTestEthereum()
 	defer cancel()
@@ -1697,7 +2071,7 @@ func TestQueryContractErrorPrepare(t *testing.T) {
 	params := map[string]interface{}{}
 	locationBytes, err := json.Marshal(location)
 	assert.NoError(t, err)
-	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params)
+	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, "")
 	assert.Regexp(t, "invalid json", err)
 }
 
@@ -1712,7 +2086,7 @@ func TestQueryContractAddressNotSet(t *testing.T) {
 	}
 	locationBytes, err := json.Marshal(location)
 	assert.NoError(t, err)
-	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params)
+	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, "")
 	assert.Regexp(t, "'address' not set", err)
 }
 
@@ -1735,7 +2109,7 @@ func TestQueryContractEthconnectError(t *testing.T) {
 		func(req *http.Request) (*http.Response, error) {
 			return httpmock.NewJsonResponderOrPanic(400, queryOutput{})(req)
 		})
-	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params)
+	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, "")
 	assert.Regexp(t, "FF10111", err)
 }
 
@@ -1762,7 +2136,7 @@ func TestQueryContractUnmarshalResponseError(t *testing.T) {
 			assert.Equal(t, "Query", headers["type"])
 			return httpmock.NewStringResponder(200, "[definitely not JSON}")(req)
 		})
-	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params)
+	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, "")
 	assert.Regexp(t, "invalid character", err)
 }
 
@@ -2343,6 +2717,28 @@ func TestGenerateFFIInlineNamespace(t *testing.T) {
 	assert.Equal(t, ffi.Namespace, "ns1")
 }
 
+func TestGenerateFFIBareABI(t *testing.T) {
+	e, _ := newTestEthereum()
+	ffi, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
+		Name:    "Simple",
+		Version: "v0.0.1",
+		Input:   fftypes.JSONAnyPtr(` [{"type":"function","name":"set","inputs":[{"name":"x","type":"uint256"}]}]`),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, ffi.Methods, 1)
+	assert.Equal(t, "set", ffi.Methods[0].Name)
+}
+
+func TestGenerateFFIBadBareABI(t *testing.T) {
+	e, _ := newTestEthereum()
+	_, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
+		Name:    "Simple",
+		Version: "v0.0.1",
+		Input:   fftypes.JSONAnyPtr(`[1]`),
+	})
+	assert.Regexp(t, "FF10346", err)
+}
+
 func TestGenerateFFIEmptyABI(t *testing.T) {
 	e, _ := newTestEthereum()
 	_, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
@@ -2376,3 +2772,81 @@ func TestGetFFIType(t *testing.T) {
 	assert.Equal(t, e.getFFIType("tuple"), "object")
 	assert.Equal(t, e.getFFIType("foobar"), "")
 }
+
+func TestGetOperationStatusSucceeded(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	opID := fftypes.NewUUID()
+	httpmock.RegisterResponder("GET", "http://localhost:12345/reply/"+opID.String(),
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{
+			"headers":         fftypes.JSONObject{"requestId": opID.String(), "type": "TransactionSuccess"},
+			"transactionHash": "0x12345",
+		}))
+	update, err := e.GetOperationStatus(context.Background(), opID)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.OpStatusSucceeded, update.Status)
+	assert.Equal(t, "0x12345", update.BlockchainTXID)
+	assert.Equal(t, opID.String(), update.Output.GetObject("headers").GetString("requestId"))
+}
+
+func TestGetOperationStatusDeployed(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	opID := fftypes.NewUUID()
+	httpmock.RegisterResponder("GET", "http://localhost:12345/reply/"+opID.String(),
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{
+			"headers":         fftypes.JSONObject{"requestId": opID.String(), "type": "TransactionSuccess"},
+			"transactionHash": "0x12345",
+			"contractAddress": "0x3c1bef20a7858f5c2f78bda60796758d7cafff27",
+		}))
+	update, err := e.GetOperationStatus(context.Background(), opID)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.OpStatusSucceeded, update.Status)
+	assert.Equal(t, "0x3c1bef20a7858f5c2f78bda60796758d7cafff27", update.Output.GetObject("location").GetString("address"))
+}
+
+func TestGetOperationStatusFailed(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	opID := fftypes.NewUUID()
+	httpmock.RegisterResponder("GET", "http://localhost:12345/reply/"+opID.String(),
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{
+			"headers":      fftypes.JSONObject{"requestId": opID.String(), "type": "TransactionFailure"},
+			"errorMessage": "reverted",
+		}))
+	update, err := e.GetOperationStatus(context.Background(), opID)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.OpStatusFailed, update.Status)
+	assert.Equal(t, "reverted", update.ErrorMessage)
+}
+
+func TestGetOperationStatusNotFound(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	opID := fftypes.NewUUID()
+	httpmock.RegisterResponder("GET", "http://localhost:12345/reply/"+opID.String(),
+		httpmock.NewJsonResponderOrPanic(404, fftypes.JSONObject{}))
+	update, err := e.GetOperationStatus(context.Background(), opID)
+	assert.NoError(t, err)
+	assert.Nil(t, update)
+}
+
+func TestGetOperationStatusError(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	opID := fftypes.NewUUID()
+	httpmock.RegisterResponder("GET", "http://localhost:12345/reply/"+opID.String(),
+		httpmock.NewJsonResponderOrPanic(500, fftypes.JSONObject{}))
+	_, err := e.GetOperationStatus(context.Background(), opID)
+	assert.Regexp(t, "FF10111", err)
+}
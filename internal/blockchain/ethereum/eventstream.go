@@ -51,6 +51,13 @@ type subscription struct {
 	FromBlock string               `json:"fromBlock"`
 	Address   string               `json:"address"`
 	Event     ABIElementMarshaling `json:"event"`
+	Filter    *subscriptionFilter  `json:"filter,omitempty"`
+}
+
+// subscriptionFilter restricts the logs delivered on a subscription, using the same topic matching rules as eth_getLogs.
+// The first position is the event signature, which is set by ethconnect from the event ABI.
+type subscriptionFilter struct {
+	Topics [][]string `json:"topics,omitempty"`
 }
 
 func (s *streamManager) getEventStreams(ctx context.Context) (streams []*eventStream, err error) {
@@ -134,7 +141,7 @@ func (s *streamManager) getSubscriptions(ctx context.Context) (subs []*subscript
 	return subs, nil
 }
 
-func (s *streamManager) createSubscription(ctx context.Context, location *Location, stream, subName, fromBlock string, abi ABIElementMarshaling) (*subscription, error) {
+func (s *streamManager) createSubscription(ctx context.Context, location *Location, stream, subName, fromBlock string, abi ABIElementMarshaling, filter *subscriptionFilter) (*subscription, error) {
 	// Map FireFly "firstEvent" values to Ethereum "fromBlock" values
 	switch fromBlock {
 	case string(fftypes.SubOptsFirstEventOldest):
@@ -148,6 +155,7 @@ func (s *streamManager) createSubscription(ctx context.Context, location *Locati
 		FromBlock: fromBlock,
 		Address:   location.Address,
 		Event:     abi,
+		Filter:    filter,
 	}
 	res, err := s.client.R().
 		SetContext(ctx).
@@ -198,7 +206,7 @@ func (s *streamManager) ensureSubscription(ctx context.Context, instancePath, st
 	}
 
 	if sub == nil {
-		if sub, err = s.createSubscription(ctx, location, stream, subName, string(fftypes.SubOptsFirstEventOldest), abi); err != nil {
+		if sub, err = s.createSubscription(ctx, location, stream, subName, string(fftypes.SubOptsFirstEventOldest), abi, nil); err != nil {
 			return nil, err
 		}
 	}
@@ -0,0 +1,143 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+const topicLength = 32
+
+// buildSubscriptionFilter converts the values in a listener filter for indexed parameters into log topics, so that
+// ethconnect only delivers matching events. Filters on parameters that are not indexed cannot be applied by
+// ethconnect, and are left to FireFly to apply when the event is received.
+func buildSubscriptionFilter(ctx context.Context, abi ABIElementMarshaling, filter fftypes.JSONObject) (*subscriptionFilter, error) {
+	topics := [][]string{nil /* event signature */}
+	hasTopicFilter := false
+	for _, input := range abi.Inputs {
+		if !input.Indexed {
+			continue
+		}
+		var topic []string
+		if condition, ok := filter[input.Name]; ok {
+			values, ok := fftypes.ListenerFilterValues(condition)
+			if !ok {
+				return nil, i18n.NewError(ctx, i18n.MsgListenerFilterAmbiguous, input.Name)
+			}
+			for _, v := range values {
+				encoded, err := encodeTopic(ctx, input, v)
+				if err != nil {
+					return nil, err
+				}
+				topic = append(topic, encoded)
+			}
+			hasTopicFilter = true
+		}
+		topics = append(topics, topic)
+	}
+	if !hasTopicFilter {
+		return nil, nil
+	}
+	// Trailing wildcards are implied
+	for topics[len(topics)-1] == nil {
+		topics = topics[:len(topics)-1]
+	}
+	return &subscriptionFilter{Topics: topics}, nil
+}
+
+// encodeTopic encodes a value as a 32 byte log topic, for the value types that are stored directly in topics.
+// Dynamic types are stored as a hash in the topic, so cannot be matched.
+func encodeTopic(ctx context.Context, input ABIArgumentMarshaling, value interface{}) (string, error) {
+	topic := make([]byte, topicLength)
+	switch {
+	case input.Type == "address":
+		b, err := decodeHexValue(value)
+		if err != nil || len(b) != 20 {
+			return "", i18n.NewError(ctx, i18n.MsgListenerFilterValueInvalid, input.Name, value)
+		}
+		copy(topic[topicLength-20:], b)
+	case input.Type == "bool":
+		var b bool
+		switch vt := value.(type) {
+		case bool:
+			b = vt
+		case string:
+			var err error
+			if b, err = strconv.ParseBool(vt); err != nil {
+				return "", i18n.NewError(ctx, i18n.MsgListenerFilterValueInvalid, input.Name, value)
+			}
+		default:
+			return "", i18n.NewError(ctx, i18n.MsgListenerFilterValueInvalid, input.Name, value)
+		}
+		if b {
+			topic[topicLength-1] = 1
+		}
+	case strings.HasPrefix(input.Type, "uint") || strings.HasPrefix(input.Type, "int"):
+		i, ok := parseIntegerValue(value)
+		if !ok || (i.Sign() < 0 && strings.HasPrefix(input.Type, "uint")) {
+			return "", i18n.NewError(ctx, i18n.MsgListenerFilterValueInvalid, input.Name, value)
+		}
+		if i.Sign() < 0 {
+			// Two's complement representation
+			i.Add(i, new(big.Int).Lsh(big.NewInt(1), topicLength*8))
+		}
+		if i.BitLen() > topicLength*8 {
+			return "", i18n.NewError(ctx, i18n.MsgListenerFilterValueInvalid, input.Name, value)
+		}
+		i.FillBytes(topic)
+	case strings.HasPrefix(input.Type, "bytes") && input.Type != "bytes" && !strings.HasSuffix(input.Type, "]"):
+		b, err := decodeHexValue(value)
+		if err != nil || len(b) > topicLength {
+			return "", i18n.NewError(ctx, i18n.MsgListenerFilterValueInvalid, input.Name, value)
+		}
+		copy(topic, b)
+	default:
+		return "", i18n.NewError(ctx, i18n.MsgListenerFilterUnsupported, input.Name, input.Type)
+	}
+	return "0x" + hex.EncodeToString(topic), nil
+}
+
+func decodeHexValue(value interface{}) ([]byte, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("not a string")
+	}
+	return hex.DecodeString(strings.TrimPrefix(s, "0x"))
+}
+
+func parseIntegerValue(value interface{}) (*big.Int, bool) {
+	switch vt := value.(type) {
+	case float64:
+		i, accuracy := new(big.Float).SetFloat64(vt).Int(nil)
+		return i, accuracy == big.Exact
+	case string:
+		if strings.HasPrefix(vt, "0x") {
+			return new(big.Int).SetString(vt[2:], 16)
+		}
+		return new(big.Int).SetString(vt, 10)
+	default:
+		return nil, false
+	}
+}
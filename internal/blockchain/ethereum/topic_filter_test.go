@@ -0,0 +1,118 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+var testFilterABI = ABIElementMarshaling{
+	Name: "Test",
+	Type: "event",
+	Inputs: []ABIArgumentMarshaling{
+		{Name: "addr", Type: "address", Indexed: true},
+		{Name: "flag", Type: "bool", Indexed: true},
+		{Name: "amount", Type: "int256", Indexed: true},
+		{Name: "data", Type: "string"},
+	},
+}
+
+func TestBuildSubscriptionFilterNoFilter(t *testing.T) {
+	filter, err := buildSubscriptionFilter(context.Background(), testFilterABI, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, filter)
+
+	filter, err = buildSubscriptionFilter(context.Background(), testFilterABI, fftypes.JSONObject{"data": "not indexed"})
+	assert.NoError(t, err)
+	assert.Nil(t, filter)
+}
+
+func TestBuildSubscriptionFilterTopics(t *testing.T) {
+	filter, err := buildSubscriptionFilter(context.Background(), testFilterABI, fftypes.JSONObject{
+		"flag":   map[string]interface{}{"anyOf": []interface{}{true, "false"}},
+		"amount": float64(-1),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		nil,
+		nil,
+		{
+			"0x0000000000000000000000000000000000000000000000000000000000000001",
+			"0x0000000000000000000000000000000000000000000000000000000000000000",
+		},
+		{"0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"},
+	}, filter.Topics)
+}
+
+func TestBuildSubscriptionFilterTrailingWildcards(t *testing.T) {
+	filter, err := buildSubscriptionFilter(context.Background(), testFilterABI, fftypes.JSONObject{
+		"addr": "0x91d2b4381a4cd5c7c0f27565a7d4b829844c8635",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		nil,
+		{"0x00000000000000000000000091d2b4381a4cd5c7c0f27565a7d4b829844c8635"},
+	}, filter.Topics)
+}
+
+func TestBuildSubscriptionFilterBadValue(t *testing.T) {
+	_, err := buildSubscriptionFilter(context.Background(), testFilterABI, fftypes.JSONObject{
+		"addr": map[string]interface{}{"anyOf": []interface{}{"0x12345"}},
+	})
+	assert.Regexp(t, "FF10440.*addr", err)
+}
+
+func TestBuildSubscriptionFilterAmbiguous(t *testing.T) {
+	_, err := buildSubscriptionFilter(context.Background(), testFilterABI, fftypes.JSONObject{
+		"addr": []interface{}{"0x91d2b4381a4cd5c7c0f27565a7d4b829844c8635"},
+	})
+	assert.Regexp(t, "FF10448.*addr", err)
+}
+
+func TestEncodeTopic(t *testing.T) {
+	ctx := context.Background()
+	encode := func(t, v interface{}) string {
+		s, err := encodeTopic(ctx, ABIArgumentMarshaling{Name: "p", Type: t.(string)}, v)
+		if err != nil {
+			return err.Error()
+		}
+		return s
+	}
+
+	assert.Equal(t, "0x00000000000000000000000000000000000000000000000000000000000003e8", encode("uint256", "1000"))
+	assert.Equal(t, "0x00000000000000000000000000000000000000000000000000000000000003e8", encode("uint32", "0x3e8"))
+	assert.Equal(t, "0x00000000000000000000000000000000000000000000000000000000000003e8", encode("uint16", float64(1000)))
+	assert.Equal(t, "0xfffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffc18", encode("int64", "-1000"))
+	assert.Equal(t, "0x1234000000000000000000000000000000000000000000000000000000000000", encode("bytes2", "0x1234"))
+	assert.Equal(t, "0x0000000000000000000000000000000000000000000000000000000000000001", encode("bool", true))
+
+	assert.Regexp(t, "FF10440", encode("uint256", "-1"))
+	assert.Regexp(t, "FF10440", encode("uint256", float64(1.5)))
+	assert.Regexp(t, "FF10440", encode("uint256", true))
+	assert.Regexp(t, "FF10440", encode("uint256", "0x1"+"0000000000000000000000000000000000000000000000000000000000000000"))
+	assert.Regexp(t, "FF10440", encode("bool", "maybe"))
+	assert.Regexp(t, "FF10440", encode("bool", float64(1)))
+	assert.Regexp(t, "FF10440", encode("address", float64(1)))
+	assert.Regexp(t, "FF10440", encode("bytes32", "0x"+"00000000000000000000000000000000000000000000000000000000000000000000"))
+	assert.Regexp(t, "FF10441.*p.*bytes", encode("bytes", "0x1234"))
+	assert.Regexp(t, "FF10441.*p.*bytes32\\[\\]", encode("bytes32[]", "0x1234"))
+	assert.Regexp(t, "FF10441.*p.*tuple", encode("tuple", "0x1234"))
+}
@@ -22,6 +22,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
@@ -227,6 +228,13 @@ func (f *Fabric) Capabilities() *blockchain.Capabilities {
 	return f.capabilities
 }
 
+func (f *Fabric) HealthCheck(ctx context.Context) error {
+	if !f.wsconn.Connected() {
+		return i18n.NewError(ctx, i18n.MsgWSNotConnected, f.Name())
+	}
+	return nil
+}
+
 func (f *Fabric) afterConnect(ctx context.Context, w wsclient.WSClient) error {
 	// Send a subscribe to our topic after each connect/reconnect
 	b, _ := json.Marshal(&fabWSCommandPayload{
@@ -603,7 +611,21 @@ func (f *Fabric) InvokeContract(ctx context.Context, operationID *fftypes.UUID,
 	return nil
 }
 
-func (f *Fabric) QueryContract(ctx context.Context, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}) (interface{}, error) {
+func (f *Fabric) DeployContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, definition, contract *fftypes.JSONAny, input []interface{}) error {
+	// Chaincode is installed and committed through the Fabric lifecycle, which is outside the scope of FireFly
+	return i18n.NewError(ctx, i18n.MsgContractDeployUnsupported)
+}
+
+func (f *Fabric) ValidateContractDeploy(ctx context.Context, definition, contract *fftypes.JSONAny) error {
+	return i18n.NewError(ctx, i18n.MsgContractDeployUnsupported)
+}
+
+func (f *Fabric) QueryContract(ctx context.Context, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}, block string) (interface{}, error) {
+	if block != "" {
+		// Chaincode queries are evaluated against the current world state
+		return nil, i18n.NewError(ctx, i18n.MsgHistoricalQueryUnsupported, f.Name())
+	}
+
 	// All arguments must be JSON serialized
 	args, err := jsonEncodeInput(input)
 	if err != nil {
@@ -671,6 +693,32 @@ func jsonEncodeInput(params map[string]interface{}) (output map[string]string, e
 	return
 }
 
+// GetOperationStatus queries the Fabconnect receipt store for the outcome of a submitted transaction.
+// A receipt is only stored once the transaction has completed, so no receipt means no record of the outcome.
+func (f *Fabric) GetOperationStatus(ctx context.Context, operationID *fftypes.UUID) (*fftypes.OperationUpdate, error) {
+	var reply fftypes.JSONObject
+	res, err := f.client.R().
+		SetContext(ctx).
+		SetResult(&reply).
+		Get("/receipts/" + operationID.String())
+	if err == nil && res.StatusCode() == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil || !res.IsSuccess() {
+		return nil, restclient.WrapRestErr(ctx, res, err, i18n.MsgFabconnectRESTErr)
+	}
+	update := &fftypes.OperationUpdate{
+		Status:         fftypes.OpStatusSucceeded,
+		BlockchainTXID: reply.GetString("transactionId"),
+		ErrorMessage:   reply.GetString("errorMessage"),
+		Output:         reply,
+	}
+	if reply.GetObject("headers").GetString("type") != "TransactionSuccess" {
+		update.Status = fftypes.OpStatusFailed
+	}
+	return update, nil
+}
+
 func (f *Fabric) ValidateContractLocation(ctx context.Context, location *fftypes.JSONAny) (err error) {
 	_, err = parseContractLocation(ctx, location)
 	return
@@ -711,7 +759,3 @@ func (f *Fabric) GetFFIParamValidator(ctx context.Context) (fftypes.FFIParamVali
 	// Fabconnect does not require any additional validation beyond "JSON Schema correctness" at this time
 	return nil, nil
 }
-
-func (f *Fabric) GenerateFFI(ctx context.Context, generationRequest *fftypes.FFIGenerationRequest) (*fftypes.FFI, error) {
-	return nil, i18n.NewError(ctx, i18n.MsgFFIGenerationUnsupported)
-}
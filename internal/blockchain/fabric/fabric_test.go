@@ -99,6 +99,20 @@ func testFFIMethod() *fftypes.FFIMethod {
 	}
 }
 
+func TestHealthCheck(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	wsm := e.wsconn.(*wsmocks.WSClient)
+	wsm.On("Connected").Return(true).Once()
+	err := e.HealthCheck(context.Background())
+	assert.NoError(t, err)
+
+	wsm.On("Connected").Return(false).Once()
+	err = e.HealthCheck(context.Background())
+	assert.Regexp(t, "FF10420.*fabric", err)
+	wsm.AssertExpectations(t)
+}
+
 func TestInitMissingURL(t *testing.T) {
 	e, cancel := newTestFabric()
 	defer cancel()
@@ -178,6 +192,7 @@ func TestInitAllNewStreamsAndWSEvent(t *testing.T) {
 	assert.Equal(t, "es12345", e.initInfo.stream.ID)
 	assert.Equal(t, "sub12345", e.initInfo.sub.ID)
 	assert.True(t, e.Capabilities().GlobalSequencer)
+	assert.False(t, e.Capabilities().HistoricalQuery)
 
 	err = e.Start()
 	assert.NoError(t, err)
@@ -1481,7 +1496,7 @@ func TestQueryContractOK(t *testing.T) {
 			assert.Equal(t, "2", body["args"].(map[string]interface{})["y"])
 			return httpmock.NewJsonResponderOrPanic(200, &fabQueryNamedOutput{})(req)
 		})
-	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params)
+	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, "")
 	assert.NoError(t, err)
 }
 
@@ -1502,7 +1517,7 @@ func TestQueryContractInputNotJSON(t *testing.T) {
 	}
 	locationBytes, err := json.Marshal(location)
 	assert.NoError(t, err)
-	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params)
+	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, "")
 	assert.Regexp(t, "FF10151", err)
 }
 
@@ -1518,10 +1533,19 @@ func TestQueryContractBadLocation(t *testing.T) {
 		"x": float64(1),
 		"y": float64(2),
 	}
-	_, err := e.QueryContract(context.Background(), fftypes.JSONAnyPtr(`{"validLocation": false}`), method, params)
+	_, err := e.QueryContract(context.Background(), fftypes.JSONAnyPtr(`{"validLocation": false}`), method, params, "")
 	assert.Regexp(t, "FF10310", err)
 }
 
+func TestQueryContractAtBlockUnsupported(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	method := testFFIMethod()
+	params := map[string]interface{}{}
+	_, err := e.QueryContract(context.Background(), fftypes.JSONAnyPtr(`{}`), method, params, "12345")
+	assert.Regexp(t, "FF10442.*fabric", err)
+}
+
 func TestQueryContractFabconnectError(t *testing.T) {
 	e, cancel := newTestFabric()
 	defer cancel()
@@ -1542,7 +1566,7 @@ func TestQueryContractFabconnectError(t *testing.T) {
 		func(req *http.Request) (*http.Response, error) {
 			return httpmock.NewJsonResponderOrPanic(400, &fabQueryNamedOutput{})(req)
 		})
-	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params)
+	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, "")
 	assert.Regexp(t, "FF10284", err)
 }
 
@@ -1572,7 +1596,7 @@ func TestQueryContractUnmarshalResponseError(t *testing.T) {
 			assert.Equal(t, "2", body["args"].(map[string]interface{})["y"])
 			return httpmock.NewStringResponder(200, "[definitely not JSON}")(req)
 		})
-	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params)
+	_, err = e.QueryContract(context.Background(), fftypes.JSONAnyPtrBytes(locationBytes), method, params, "")
 	assert.Regexp(t, "invalid character", err)
 }
 
@@ -1633,13 +1657,70 @@ func TestGetFFIParamValidator(t *testing.T) {
 	assert.NoError(t, err)
 }
 
-func TestGenerateFFI(t *testing.T) {
+func TestDeployContractUnsupported(t *testing.T) {
 	e, _ := newTestFabric()
-	_, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
-		Name:        "Simple",
-		Version:     "v0.0.1",
-		Description: "desc",
-		Input:       fftypes.JSONAnyPtr(`[]`),
-	})
-	assert.Regexp(t, "FF10347", err)
+	err := e.DeployContract(context.Background(), fftypes.NewUUID(), "signer", fftypes.JSONAnyPtr(`{}`), fftypes.JSONAnyPtr(`"code"`), nil)
+	assert.Regexp(t, "FF10434", err)
+	err = e.ValidateContractDeploy(context.Background(), fftypes.JSONAnyPtr(`{}`), fftypes.JSONAnyPtr(`"code"`))
+	assert.Regexp(t, "FF10434", err)
+}
+
+func TestGetOperationStatusSucceeded(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	opID := fftypes.NewUUID()
+	httpmock.RegisterResponder("GET", "http://localhost:12345/receipts/"+opID.String(),
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{
+			"headers":       fftypes.JSONObject{"requestId": opID.String(), "type": "TransactionSuccess"},
+			"transactionId": "0x12345",
+		}))
+	update, err := e.GetOperationStatus(context.Background(), opID)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.OpStatusSucceeded, update.Status)
+	assert.Equal(t, "0x12345", update.BlockchainTXID)
+	assert.Equal(t, opID.String(), update.Output.GetObject("headers").GetString("requestId"))
+}
+
+func TestGetOperationStatusFailed(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	opID := fftypes.NewUUID()
+	httpmock.RegisterResponder("GET", "http://localhost:12345/receipts/"+opID.String(),
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{
+			"headers":      fftypes.JSONObject{"requestId": opID.String(), "type": "TransactionFailure"},
+			"errorMessage": "reverted",
+		}))
+	update, err := e.GetOperationStatus(context.Background(), opID)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.OpStatusFailed, update.Status)
+	assert.Equal(t, "reverted", update.ErrorMessage)
+}
+
+func TestGetOperationStatusNotFound(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	opID := fftypes.NewUUID()
+	httpmock.RegisterResponder("GET", "http://localhost:12345/receipts/"+opID.String(),
+		httpmock.NewJsonResponderOrPanic(404, fftypes.JSONObject{}))
+	update, err := e.GetOperationStatus(context.Background(), opID)
+	assert.NoError(t, err)
+	assert.Nil(t, update)
+}
+
+func TestGetOperationStatusError(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	opID := fftypes.NewUUID()
+	httpmock.RegisterResponder("GET", "http://localhost:12345/receipts/"+opID.String(),
+		httpmock.NewJsonResponderOrPanic(500, fftypes.JSONObject{}))
+	_, err := e.GetOperationStatus(context.Background(), opID)
+	assert.Regexp(t, "FF10284", err)
 }
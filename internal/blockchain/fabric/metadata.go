@@ -0,0 +1,246 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fabric
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+const (
+	// metadataSystemContract is the contract the Fabric contract API adds to every chaincode, to serve the metadata itself
+	metadataSystemContract  = "org.hyperledger.fabric"
+	metadataSchemaRefPrefix = "#/components/schemas/"
+)
+
+// ContractMetadata is the subset of the Fabric contract API metadata, as returned by
+// org.hyperledger.fabric:GetMetadata, that is used to generate an FFI
+type ContractMetadata struct {
+	Contracts  map[string]*MetadataContract `json:"contracts"`
+	Components struct {
+		Schemas map[string]fftypes.JSONObject `json:"schemas,omitempty"`
+	} `json:"components"`
+}
+
+type MetadataContract struct {
+	Name         string                 `json:"name"`
+	Transactions []*MetadataTransaction `json:"transactions,omitempty"`
+	Events       []*MetadataEvent       `json:"events,omitempty"`
+}
+
+type MetadataTransaction struct {
+	Name       string               `json:"name"`
+	Tags       []string             `json:"tags,omitempty"`
+	Parameters []*MetadataParameter `json:"parameters,omitempty"`
+	Returns    fftypes.JSONObject   `json:"returns,omitempty"`
+}
+
+type MetadataParameter struct {
+	Name   string             `json:"name"`
+	Schema fftypes.JSONObject `json:"schema,omitempty"`
+}
+
+type MetadataEvent struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Schema      fftypes.JSONObject `json:"schema,omitempty"`
+}
+
+func (f *Fabric) GenerateFFI(ctx context.Context, generationRequest *fftypes.FFIGenerationRequest) (*fftypes.FFI, error) {
+	var metadata ContractMetadata
+	err := json.Unmarshal(generationRequest.Input.Bytes(), &metadata)
+	if err != nil {
+		return nil, i18n.NewError(ctx, i18n.MsgFFIGenerationFailed, "unable to deserialize JSON as Fabric contract metadata")
+	}
+
+	contractNames := make([]string, 0, len(metadata.Contracts))
+	for name := range metadata.Contracts {
+		if name != metadataSystemContract {
+			contractNames = append(contractNames, name)
+		}
+	}
+	if len(contractNames) == 0 {
+		return nil, i18n.NewError(ctx, i18n.MsgFFIGenerationFailed, "no contracts found in metadata")
+	}
+	sort.Strings(contractNames)
+
+	ffi := &fftypes.FFI{
+		Namespace:   generationRequest.Namespace,
+		Name:        generationRequest.Name,
+		Version:     generationRequest.Version,
+		Description: generationRequest.Description,
+		Methods:     []*fftypes.FFIMethod{},
+		Events:      []*fftypes.FFIEvent{},
+	}
+	sr := &schemaResolver{schemas: metadata.Components.Schemas}
+	for _, contractName := range contractNames {
+		// When a chaincode contains multiple contracts, the transactions must be invoked by their fully qualified name
+		prefix := ""
+		if len(contractNames) > 1 {
+			prefix = contractName + ":"
+		}
+		contract := metadata.Contracts[contractName]
+		for _, tx := range contract.Transactions {
+			method, err := sr.convertTransactionToFFI(ctx, prefix, tx)
+			if err != nil {
+				return nil, err
+			}
+			ffi.Methods = append(ffi.Methods, method)
+		}
+		for _, ev := range contract.Events {
+			event, err := sr.convertEventToFFI(ctx, ev)
+			if err != nil {
+				return nil, err
+			}
+			ffi.Events = append(ffi.Events, event)
+		}
+	}
+	return ffi, nil
+}
+
+// schemaResolver inlines references to the shared component schemas in the metadata, so that each
+// FFI parameter carries a complete JSON Schema of its own
+type schemaResolver struct {
+	schemas   map[string]fftypes.JSONObject
+	resolving map[string]bool
+}
+
+func (sr *schemaResolver) convertTransactionToFFI(ctx context.Context, prefix string, tx *MetadataTransaction) (*fftypes.FFIMethod, error) {
+	method := &fftypes.FFIMethod{
+		Name:    prefix + tx.Name,
+		Params:  fftypes.FFIParams{},
+		Returns: fftypes.FFIParams{},
+	}
+	for _, p := range tx.Parameters {
+		param, err := sr.convertSchemaToParam(ctx, p.Name, p.Schema)
+		if err != nil {
+			return nil, err
+		}
+		method.Params = append(method.Params, param)
+	}
+	if len(tx.Returns) > 0 {
+		param, err := sr.convertSchemaToParam(ctx, "", tx.Returns)
+		if err != nil {
+			return nil, err
+		}
+		method.Returns = append(method.Returns, param)
+	}
+	return method, nil
+}
+
+func (sr *schemaResolver) convertEventToFFI(ctx context.Context, ev *MetadataEvent) (*fftypes.FFIEvent, error) {
+	event := &fftypes.FFIEvent{
+		FFIEventDefinition: fftypes.FFIEventDefinition{
+			Name:        ev.Name,
+			Description: ev.Description,
+			Params:      fftypes.FFIParams{},
+		},
+	}
+	if len(ev.Schema) == 0 {
+		return event, nil
+	}
+	resolved, err := sr.resolve(ctx, ev.Schema)
+	if err != nil {
+		return nil, err
+	}
+	// Event payloads are delivered as the output of the blockchain event, so each property of the
+	// payload object becomes a parameter of the event
+	payload, _ := resolved.(map[string]interface{})
+	properties := fftypes.JSONObject(payload).GetObject("properties")
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b, _ := json.Marshal(properties[name])
+		event.Params = append(event.Params, &fftypes.FFIParam{
+			Name:   name,
+			Schema: fftypes.JSONAnyPtrBytes(b),
+		})
+	}
+	return event, nil
+}
+
+func (sr *schemaResolver) convertSchemaToParam(ctx context.Context, name string, schema fftypes.JSONObject) (*fftypes.FFIParam, error) {
+	resolved, err := sr.resolve(ctx, schema)
+	if err != nil {
+		return nil, err
+	}
+	b, _ := json.Marshal(resolved)
+	return &fftypes.FFIParam{
+		Name:   name,
+		Schema: fftypes.JSONAnyPtrBytes(b),
+	}, nil
+}
+
+func (sr *schemaResolver) resolve(ctx context.Context, schema interface{}) (interface{}, error) {
+	switch s := schema.(type) {
+	case fftypes.JSONObject:
+		return sr.resolve(ctx, map[string]interface{}(s))
+	case map[string]interface{}:
+		if ref, ok := s["$ref"].(string); ok {
+			return sr.resolveRef(ctx, ref)
+		}
+		resolved := make(map[string]interface{}, len(s))
+		for k, v := range s {
+			if k == "$id" {
+				continue
+			}
+			rv, err := sr.resolve(ctx, v)
+			if err != nil {
+				return nil, err
+			}
+			resolved[k] = rv
+		}
+		return resolved, nil
+	case []interface{}:
+		resolved := make([]interface{}, len(s))
+		for i, v := range s {
+			rv, err := sr.resolve(ctx, v)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = rv
+		}
+		return resolved, nil
+	default:
+		return schema, nil
+	}
+}
+
+func (sr *schemaResolver) resolveRef(ctx context.Context, ref string) (interface{}, error) {
+	name := strings.TrimPrefix(ref, metadataSchemaRefPrefix)
+	component, ok := sr.schemas[name]
+	if !ok || name == ref {
+		return nil, i18n.NewError(ctx, i18n.MsgFFIGenerationFailed, "unknown schema reference '"+ref+"'")
+	}
+	if sr.resolving == nil {
+		sr.resolving = make(map[string]bool)
+	}
+	if sr.resolving[name] {
+		return nil, i18n.NewError(ctx, i18n.MsgFFIGenerationFailed, "recursive schema reference '"+ref+"'")
+	}
+	sr.resolving[name] = true
+	defer delete(sr.resolving, name)
+	return sr.resolve(ctx, component)
+}
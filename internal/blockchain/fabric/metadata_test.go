@@ -0,0 +1,245 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fabric
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+const testContractMetadata = `{
+	"$schema": "https://hyperledger.github.io/fabric-chaincode-node/main/api/contract-schema.json",
+	"info": {"title": "asset-transfer", "version": "1.0.0"},
+	"contracts": {
+		"AssetTransfer": {
+			"name": "AssetTransfer",
+			"transactions": [
+				{
+					"name": "CreateAsset",
+					"tags": ["submitTx"],
+					"parameters": [
+						{"name": "id", "schema": {"type": "string"}},
+						{"name": "value", "schema": {"type": "integer", "format": "int32"}}
+					]
+				},
+				{
+					"name": "ReadAsset",
+					"tags": ["evaluateTx"],
+					"parameters": [
+						{"name": "id", "schema": {"type": "string"}}
+					],
+					"returns": {"$ref": "#/components/schemas/Asset"}
+				},
+				{
+					"name": "GetAllAssets",
+					"returns": {"type": "array", "items": {"$ref": "#/components/schemas/Asset"}}
+				}
+			],
+			"events": [
+				{
+					"name": "AssetCreated",
+					"description": "emitted on create",
+					"schema": {"$ref": "#/components/schemas/Asset"}
+				},
+				{
+					"name": "Ping"
+				}
+			]
+		},
+		"org.hyperledger.fabric": {
+			"name": "org.hyperledger.fabric",
+			"transactions": [{"name": "GetMetadata"}]
+		}
+	},
+	"components": {
+		"schemas": {
+			"Asset": {
+				"$id": "Asset",
+				"type": "object",
+				"properties": {
+					"id": {"type": "string"},
+					"owner": {"$ref": "#/components/schemas/Owner"}
+				},
+				"required": ["id"]
+			},
+			"Owner": {
+				"$id": "Owner",
+				"type": "object",
+				"properties": {
+					"name": {"type": "string"}
+				}
+			}
+		}
+	}
+}`
+
+func TestGenerateFFI(t *testing.T) {
+	e, _ := newTestFabric()
+	ffi, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
+		Namespace:   "ns1",
+		Name:        "assets",
+		Version:     "v1.0.0",
+		Description: "desc",
+		Input:       fftypes.JSONAnyPtr(testContractMetadata),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ns1", ffi.Namespace)
+	assert.Equal(t, "assets", ffi.Name)
+	assert.Equal(t, "v1.0.0", ffi.Version)
+	assert.Equal(t, "desc", ffi.Description)
+
+	assert.Len(t, ffi.Methods, 3)
+	assert.Equal(t, "CreateAsset", ffi.Methods[0].Name)
+	assert.Len(t, ffi.Methods[0].Params, 2)
+	assert.Equal(t, "id", ffi.Methods[0].Params[0].Name)
+	assert.JSONEq(t, `{"type":"string"}`, ffi.Methods[0].Params[0].Schema.String())
+	assert.Equal(t, "value", ffi.Methods[0].Params[1].Name)
+	assert.JSONEq(t, `{"type":"integer","format":"int32"}`, ffi.Methods[0].Params[1].Schema.String())
+	assert.Empty(t, ffi.Methods[0].Returns)
+	assert.NotNil(t, ffi.Methods[0].Returns)
+
+	assetSchema := `{
+		"type": "object",
+		"properties": {
+			"id": {"type": "string"},
+			"owner": {"type": "object", "properties": {"name": {"type": "string"}}}
+		},
+		"required": ["id"]
+	}`
+	assert.Equal(t, "ReadAsset", ffi.Methods[1].Name)
+	assert.Len(t, ffi.Methods[1].Returns, 1)
+	assert.JSONEq(t, assetSchema, ffi.Methods[1].Returns[0].Schema.String())
+	assert.Equal(t, "GetAllAssets", ffi.Methods[2].Name)
+	assert.Empty(t, ffi.Methods[2].Params)
+	assert.JSONEq(t, `{"type":"array","items":`+assetSchema+`}`, ffi.Methods[2].Returns[0].Schema.String())
+
+	assert.Len(t, ffi.Events, 2)
+	assert.Equal(t, "AssetCreated", ffi.Events[0].Name)
+	assert.Equal(t, "emitted on create", ffi.Events[0].Description)
+	assert.Len(t, ffi.Events[0].Params, 2)
+	assert.Equal(t, "id", ffi.Events[0].Params[0].Name)
+	assert.JSONEq(t, `{"type":"string"}`, ffi.Events[0].Params[0].Schema.String())
+	assert.Equal(t, "owner", ffi.Events[0].Params[1].Name)
+	assert.JSONEq(t, `{"type":"object","properties":{"name":{"type":"string"}}}`, ffi.Events[0].Params[1].Schema.String())
+	assert.Equal(t, "Ping", ffi.Events[1].Name)
+	assert.Empty(t, ffi.Events[1].Params)
+}
+
+func TestGenerateFFIMultipleContracts(t *testing.T) {
+	e, _ := newTestFabric()
+	ffi, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
+		Name:    "multi",
+		Version: "v1",
+		Input: fftypes.JSONAnyPtr(`{
+			"contracts": {
+				"B": {"name": "B", "transactions": [{"name": "Two"}]},
+				"A": {"name": "A", "transactions": [{"name": "One"}]}
+			}
+		}`),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, ffi.Methods, 2)
+	assert.Equal(t, "A:One", ffi.Methods[0].Name)
+	assert.Equal(t, "B:Two", ffi.Methods[1].Name)
+}
+
+func TestGenerateFFIBadInput(t *testing.T) {
+	e, _ := newTestFabric()
+	_, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
+		Name:    "Simple",
+		Version: "v0.0.1",
+		Input:   fftypes.JSONAnyPtr(`[]`),
+	})
+	assert.Regexp(t, "FF10346.*metadata", err)
+}
+
+func TestGenerateFFINoContracts(t *testing.T) {
+	e, _ := newTestFabric()
+	_, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
+		Name:    "Simple",
+		Version: "v0.0.1",
+		Input:   fftypes.JSONAnyPtr(`{"contracts": {"org.hyperledger.fabric": {}}}`),
+	})
+	assert.Regexp(t, "FF10346.*no contracts", err)
+}
+
+func TestGenerateFFIUnknownParamRef(t *testing.T) {
+	e, _ := newTestFabric()
+	_, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
+		Name:    "Simple",
+		Version: "v0.0.1",
+		Input: fftypes.JSONAnyPtr(`{"contracts": {"C": {"transactions": [
+			{"name": "Tx", "parameters": [{"name": "p", "schema": {"$ref": "#/components/schemas/Missing"}}]}
+		]}}}`),
+	})
+	assert.Regexp(t, "FF10346.*unknown schema reference '#/components/schemas/Missing'", err)
+}
+
+func TestGenerateFFIBadRefPrefix(t *testing.T) {
+	e, _ := newTestFabric()
+	_, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
+		Name:    "Simple",
+		Version: "v0.0.1",
+		Input: fftypes.JSONAnyPtr(`{
+			"contracts": {"C": {"transactions": [{"name": "Tx", "returns": {"$ref": "Asset"}}]}},
+			"components": {"schemas": {"Asset": {"type": "object"}}}
+		}`),
+	})
+	assert.Regexp(t, "FF10346.*unknown schema reference 'Asset'", err)
+}
+
+func TestGenerateFFIRecursiveRef(t *testing.T) {
+	e, _ := newTestFabric()
+	_, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
+		Name:    "Simple",
+		Version: "v0.0.1",
+		Input: fftypes.JSONAnyPtr(`{
+			"contracts": {"C": {"transactions": [{"name": "Tx", "returns": {"$ref": "#/components/schemas/Node"}}]}},
+			"components": {"schemas": {"Node": {"type": "object", "properties": {
+				"children": {"type": "array", "items": {"$ref": "#/components/schemas/Node"}}
+			}}}}
+		}`),
+	})
+	assert.Regexp(t, "FF10346.*recursive schema reference", err)
+}
+
+func TestGenerateFFIBadEventRef(t *testing.T) {
+	e, _ := newTestFabric()
+	_, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
+		Name:    "Simple",
+		Version: "v0.0.1",
+		Input: fftypes.JSONAnyPtr(`{"contracts": {"C": {"events": [
+			{"name": "Ev", "schema": {"$ref": "#/components/schemas/Missing"}}
+		]}}}`),
+	})
+	assert.Regexp(t, "FF10346.*unknown schema reference", err)
+}
+
+func TestGenerateFFIBadRefInArray(t *testing.T) {
+	e, _ := newTestFabric()
+	_, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
+		Name:    "Simple",
+		Version: "v0.0.1",
+		Input: fftypes.JSONAnyPtr(`{"contracts": {"C": {"transactions": [
+			{"name": "Tx", "returns": {"anyOf": [{"type": "string"}, {"$ref": "#/components/schemas/Missing"}]}}
+		]}}}`),
+	})
+	assert.Regexp(t, "FF10346.*unknown schema reference", err)
+}
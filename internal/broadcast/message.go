@@ -23,6 +23,7 @@ import (
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/sysmessaging"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
 
@@ -143,8 +144,12 @@ func (s *broadcastSender) sendInternal(ctx context.Context, method sendMethod) (
 		return nil
 	}
 
-	// Write the message
-	if err := s.mgr.data.WriteNewMessage(ctx, s.msg); err != nil {
+	// Write the message, recording the span so the asynchronous batching and confirmation can link to it
+	ctx, span := tracing.StartSpan(ctx, "broadcast_message", nil, tracing.Namespace(msg.Header.Namespace), tracing.MessageID(msg.Header.ID))
+	tracing.Record(ctx, msg.Header.ID)
+	err = s.mgr.data.WriteNewMessage(ctx, s.msg)
+	tracing.EndSpan(span, err)
+	if err != nil {
 		return err
 	}
 	log.L(ctx).Infof("Sent broadcast message %s:%s sequence=%d datacount=%d", msg.Header.Namespace, msg.Header.ID, msg.Sequence, len(s.msg.AllData))
@@ -212,6 +212,32 @@ var (
 	NodeName = rootKey("node.name")
 	// NodeDescription is a description for the node
 	NodeDescription = rootKey("node.description")
+	// OperationsReconcilerEnabled enables the background reconciler, that queries the owning plugin for operations that have been pending for too long
+	OperationsReconcilerEnabled = rootKey("operations.reconciler.enabled")
+	// OperationsReconcilerInterval how often the reconciler checks for stale pending operations
+	OperationsReconcilerInterval = rootKey("operations.reconciler.interval")
+	// OperationsReconcilerPendingTimeout how long an operation can be pending before the reconciler queries its status
+	OperationsReconcilerPendingTimeout = rootKey("operations.reconciler.pendingTimeout")
+	// OperationsReconcilerNoRecordTimeout how long after creation an operation the owning plugin has no record of is marked failed - until then it is left pending, as it might still be in flight
+	OperationsReconcilerNoRecordTimeout = rootKey("operations.reconciler.noRecordTimeout")
+	// OperationsReconcilerBatchSize the maximum number of stale operations to query in each pass of the reconciler
+	OperationsReconcilerBatchSize = rootKey("operations.reconciler.batchSize")
+	// OperationsRetryPolicies is a list of automatic retry policies for failed operations, each for an operation "type"
+	OperationsRetryPolicies = rootKey("operations.retry.policies")
+	// OperationsRetryMaxAttempts the default maximum number of attempts for an operation, including the first, for policies that do not set "maxAttempts"
+	OperationsRetryMaxAttempts = rootKey("operations.retry.maxAttempts")
+	// OperationsRetryInitialDelay the default delay before the first automatic retry, for policies that do not set "initialDelay"
+	OperationsRetryInitialDelay = rootKey("operations.retry.initialDelay")
+	// OperationsRetryMaxDelay the default maximum delay between automatic retries, for policies that do not set "maxDelay"
+	OperationsRetryMaxDelay = rootKey("operations.retry.maxDelay")
+	// OperationsRetryFactor the default backoff factor between automatic retries, for policies that do not set "factor"
+	OperationsRetryFactor = rootKey("operations.retry.factor")
+	// OperationsRetryPollInterval how often to check the database for failed operations that are due an automatic retry
+	OperationsRetryPollInterval = rootKey("operations.retry.pollInterval")
+	// OperationsRetryBatchSize the maximum number of operations to retry in each check for due retries
+	OperationsRetryBatchSize = rootKey("operations.retry.batchSize")
+	// OperationsRetryErrors the default list of regular expressions that classify an error as retryable, for policies that do not set "errors"
+	OperationsRetryErrors = rootKey("operations.retry.errors")
 	// OrgName is the short name o the org
 	OrgName = rootKey("org.name")
 	// OrgIdentityDeprecated deprecated synonym to org.key
@@ -222,12 +248,22 @@ var (
 	OrgDescription = rootKey("org.description")
 	// OrchestratorStartupAttempts is how many time to attempt to connect to core infrastructure on startup
 	OrchestratorStartupAttempts = rootKey("orchestrator.startupAttempts")
+	// RetentionEnabled enables the background pruner, that removes confirmed messages (and their data, blobs, events, batches, pins, operations and blockchain events) beyond the configured age
+	RetentionEnabled = rootKey("retention.enabled")
+	// RetentionInterval how often the pruner checks for messages to prune
+	RetentionInterval = rootKey("retention.interval")
+	// RetentionBatchSize the maximum number of messages to query in each pass of the pruner
+	RetentionBatchSize = rootKey("retention.batchSize")
+	// RetentionPolicies is a list of per-namespace retention policies, each with a "namespace" and a "messageAge"
+	RetentionPolicies = rootKey("retention.policies")
 	// SharedStorageType specifies which shared storage interface plugin to use
 	SharedStorageType = rootKey("sharedstorage.type")
 	// PublicStorageType specifies which shared storage interface plugin to use - deprecated in favor of SharedStorageType
 	PublicStorageType = rootKey("publicstorage.type")
 	// SubscriptionDefaultsReadAhead default read ahead to enable for subscriptions that do not explicitly configure readahead
 	SubscriptionDefaultsReadAhead = rootKey("subscription.defaults.batchSize")
+	// SubscriptionMetricsInterval how often the status metrics of durable subscriptions are refreshed, when metrics are enabled
+	SubscriptionMetricsInterval = rootKey("subscription.metricsInterval")
 	// SubscriptionMax maximum number of pre-defined subscriptions that can exist (note for high fan-out consider connecting a dedicated pub/sub broker to the dispatcher)
 	SubscriptionMax = rootKey("subscription.max")
 	// SubscriptionsRetryInitialDelay is the initial retry delay
@@ -236,6 +272,30 @@ var (
 	SubscriptionsRetryMaxDelay = rootKey("subscription.retry.maxDelay")
 	// SubscriptionsRetryFactor the backoff factor to use for retry of database operations
 	SubscriptionsRetryFactor = rootKey("subscription.retry.factor")
+	// SyncAsyncDistributedEnabled coordinates synchronous API requests through the database, so that they can be resolved by events processed on any replica
+	SyncAsyncDistributedEnabled = rootKey("syncasync.distributed.enabled")
+	// SyncAsyncDistributedPollInterval how often a waiting request checks the database for a resolution by another replica
+	SyncAsyncDistributedPollInterval = rootKey("syncasync.distributed.pollInterval")
+	// SyncAsyncDistributedRequestExpiry how long after creation the record of a request is deleted, if the replica that made it did not remove it. Must be longer than api.requestMaxTimeout
+	SyncAsyncDistributedRequestExpiry = rootKey("syncasync.distributed.requestExpiry")
+	// SyncAsyncDistributedCleanupInterval how often to check the database for expired request records
+	SyncAsyncDistributedCleanupInterval = rootKey("syncasync.distributed.cleanupInterval")
+	// TracingEnabled determines whether OpenTelemetry spans are exported
+	TracingEnabled = rootKey("tracing.enabled")
+	// TracingServiceName is the service name reported on every exported span
+	TracingServiceName = rootKey("tracing.serviceName")
+	// TracingExporter selects where spans are exported. Valid options: "otlp" (default) - OTLP over HTTP, "file" - JSON lines to a local file
+	TracingExporter = rootKey("tracing.exporter")
+	// TracingOTLPEndpoint the host:port of the OTLP/HTTP collector
+	TracingOTLPEndpoint = rootKey("tracing.otlp.endpoint")
+	// TracingOTLPInsecure disables TLS when connecting to the OTLP/HTTP collector
+	TracingOTLPInsecure = rootKey("tracing.otlp.insecure")
+	// TracingFilePath the file spans are appended to, when using the file exporter
+	TracingFilePath = rootKey("tracing.file.path")
+	// TracingLinkCacheLimit the number of message, batch and operation spans remembered so later asynchronous processing can link to them
+	TracingLinkCacheLimit = rootKey("tracing.linkCache.limit")
+	// TracingLinkCacheTTL how long message, batch and operation spans are remembered for linking
+	TracingLinkCacheTTL = rootKey("tracing.linkCache.ttl")
 	// TransactionCacheSize
 	TransactionCacheSize = rootKey("transaction.cache.size")
 	// TransactionCacheTTL
@@ -342,7 +402,7 @@ func Reset() {
 	viper.SetDefault(string(EventDispatcherBufferLength), 5)
 	viper.SetDefault(string(EventDispatcherBatchTimeout), "250ms")
 	viper.SetDefault(string(EventDispatcherPollTimeout), "30s")
-	viper.SetDefault(string(EventTransportsEnabled), []string{"websockets", "webhooks"})
+	viper.SetDefault(string(EventTransportsEnabled), []string{"websockets", "webhooks", "sse"})
 	viper.SetDefault(string(EventTransportsDefault), "websockets")
 	viper.SetDefault(string(EventListenerTopicCacheSize), "100Kb")
 	viper.SetDefault(string(EventListenerTopicCacheTTL), "5m")
@@ -364,6 +424,30 @@ func Reset() {
 	viper.SetDefault(string(MessageWriterCount), 5)
 	viper.SetDefault(string(NamespacesDefault), "default")
 	viper.SetDefault(string(NamespacesPredefined), fftypes.JSONObjectArray{{"name": "default", "description": "Default predefined namespace"}})
+	viper.SetDefault(string(OperationsReconcilerEnabled), false)
+	viper.SetDefault(string(OperationsReconcilerInterval), "1m")
+	viper.SetDefault(string(OperationsReconcilerPendingTimeout), "5m")
+	viper.SetDefault(string(OperationsReconcilerNoRecordTimeout), "24h")
+	viper.SetDefault(string(OperationsReconcilerBatchSize), 50)
+	viper.SetDefault(string(OperationsRetryPolicies), fftypes.JSONObjectArray{})
+	viper.SetDefault(string(OperationsRetryMaxAttempts), 5)
+	viper.SetDefault(string(OperationsRetryInitialDelay), "5s")
+	viper.SetDefault(string(OperationsRetryMaxDelay), "5m")
+	viper.SetDefault(string(OperationsRetryFactor), 2.0)
+	viper.SetDefault(string(OperationsRetryPollInterval), "1s")
+	viper.SetDefault(string(OperationsRetryBatchSize), 50)
+	viper.SetDefault(string(OperationsRetryErrors), []string{
+		"connection refused",
+		"connection reset",
+		"no such host",
+		"timeout",
+		"timed out",
+		"deadline exceeded",
+		"EOF",
+		"bad gateway",
+		"service unavailable",
+		"gateway timeout",
+	})
 	viper.SetDefault(string(OrchestratorStartupAttempts), 5)
 	viper.SetDefault(string(PrivateMessagingRetryFactor), 2.0)
 	viper.SetDefault(string(PrivateMessagingRetryInitDelay), "100ms")
@@ -373,11 +457,27 @@ func Reset() {
 	viper.SetDefault(string(PrivateMessagingBatchSize), 200)
 	viper.SetDefault(string(PrivateMessagingBatchTimeout), "1s")
 	viper.SetDefault(string(PrivateMessagingBatchPayloadLimit), "800Kb")
+	viper.SetDefault(string(RetentionEnabled), false)
+	viper.SetDefault(string(RetentionInterval), "1h")
+	viper.SetDefault(string(RetentionBatchSize), 100)
+	viper.SetDefault(string(RetentionPolicies), fftypes.JSONObjectArray{})
 	viper.SetDefault(string(SubscriptionDefaultsReadAhead), 0)
 	viper.SetDefault(string(SubscriptionMax), 500)
+	viper.SetDefault(string(SubscriptionMetricsInterval), "15s")
 	viper.SetDefault(string(SubscriptionsRetryInitialDelay), "250ms")
 	viper.SetDefault(string(SubscriptionsRetryMaxDelay), "30s")
 	viper.SetDefault(string(SubscriptionsRetryFactor), 2.0)
+	viper.SetDefault(string(SyncAsyncDistributedEnabled), false)
+	viper.SetDefault(string(SyncAsyncDistributedPollInterval), "500ms")
+	viper.SetDefault(string(SyncAsyncDistributedRequestExpiry), "1h")
+	viper.SetDefault(string(SyncAsyncDistributedCleanupInterval), "5m")
+	viper.SetDefault(string(TracingEnabled), false)
+	viper.SetDefault(string(TracingServiceName), "firefly")
+	viper.SetDefault(string(TracingExporter), "otlp")
+	viper.SetDefault(string(TracingOTLPEndpoint), "localhost:4318")
+	viper.SetDefault(string(TracingOTLPInsecure), false)
+	viper.SetDefault(string(TracingLinkCacheLimit), 1000 /* items */)
+	viper.SetDefault(string(TracingLinkCacheTTL), "5m")
 	viper.SetDefault(string(TransactionCacheSize), "1Mb")
 	viper.SetDefault(string(TransactionCacheTTL), "5m")
 	viper.SetDefault(string(UIEnabled), true)
@@ -479,6 +579,9 @@ func GetKnownKeys() []string {
 // configPrefix is the main config structure passed to plugins, and used for root to wrap viper
 type configPrefix struct {
 	prefix string
+	// inArray is set for prefixes within an entry of an array, where Viper cannot apply
+	// defaults - so they are set explicitly when keys are added (see ArrayEntry)
+	inArray bool
 }
 
 // configPrefixArray is a point in the config that supports an array
@@ -508,7 +611,8 @@ func (c *configPrefix) prefixKey(k string) string {
 
 func (c *configPrefix) SubPrefix(suffix string) Prefix {
 	return &configPrefix{
-		prefix: c.prefix + suffix + ".",
+		prefix:  c.prefix + suffix + ".",
+		inArray: c.inArray,
 	}
 }
 
@@ -531,19 +635,11 @@ func (c *configPrefixArray) ArraySize() int {
 // ArrayEntry must only be called after the config has been loaded
 func (c *configPrefixArray) ArrayEntry(i int) Prefix {
 	cp := &configPrefix{
-		prefix: c.base + fmt.Sprintf(".%d.", i),
+		prefix:  c.base + fmt.Sprintf(".%d.", i),
+		inArray: true,
 	}
 	for knownKey, defValue := range c.defaults {
 		cp.AddKnownKey(knownKey, defValue...)
-		// Sadly Viper can't handle defaults inside the array, when
-		// a value is set. So here we check/set the defaults.
-		if defValue != nil && cp.Get(knownKey) == nil {
-			if len(defValue) == 1 {
-				cp.Set(knownKey, defValue[0])
-			} else if len(defValue) > 0 {
-				cp.Set(knownKey, defValue)
-			}
-		}
 	}
 	return cp
 }
@@ -571,6 +667,14 @@ func (c *configPrefix) AddKnownKey(k string, defValue ...interface{}) {
 
 func (c *configPrefix) SetDefault(k string, defValue interface{}) {
 	key := c.prefix + k
+	if c.inArray {
+		// Sadly Viper can't handle defaults inside the array, when
+		// a value is set. So here we check/set the defaults.
+		if viper.Get(key) == nil {
+			viper.Set(key, defValue)
+		}
+		return
+	}
 	viper.SetDefault(key, defValue)
 }
 
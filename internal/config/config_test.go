@@ -146,6 +146,26 @@ tokens:
 	assert.Equal(t, []string{"arr1", "arr2"}, sally.GetStringSlice("key2"))
 }
 
+func TestArrayEntrySubPrefixDefaults(t *testing.T) {
+	defer Reset()
+
+	biPlugins := NewPluginConfig("blockchain").Array()
+	biPlugins.AddKnownKey("name")
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+blockchain:
+- name: chain1
+  ethereum:
+    key1: explicit value
+`))
+	assert.NoError(t, err)
+	chain1 := biPlugins.ArrayEntry(0).SubPrefix("ethereum")
+	chain1.AddKnownKey("key1", "default value")
+	chain1.AddKnownKey("key2", "default value")
+	assert.Equal(t, "explicit value", chain1.GetString("key1"))
+	assert.Equal(t, "default value", chain1.GetString("key2"))
+}
+
 func TestMapOfAdminOverridePlugins(t *testing.T) {
 	defer Reset()
 
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/hyperledger/firefly/internal/blockchain/birouter"
 	"github.com/hyperledger/firefly/internal/broadcast"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/identity"
@@ -42,6 +43,7 @@ type Manager interface {
 	GetFFIs(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.FFI, *database.FilterResult, error)
 
 	InvokeContract(ctx context.Context, ns string, req *fftypes.ContractCallRequest) (interface{}, error)
+	DeployContract(ctx context.Context, ns string, req *fftypes.ContractDeployRequest) (*fftypes.ContractDeployResponse, error)
 	InvokeContractAPI(ctx context.Context, ns, apiName, methodPath string, req *fftypes.ContractCallRequest) (interface{}, error)
 	GetContractAPI(ctx context.Context, httpServerURL, ns, apiName string) (*fftypes.ContractAPI, error)
 	GetContractAPIs(ctx context.Context, httpServerURL, ns string, filter database.AndFilter) ([]*fftypes.ContractAPI, *database.FilterResult, error)
@@ -61,36 +63,41 @@ type Manager interface {
 }
 
 type contractManager struct {
-	database          database.Plugin
-	txHelper          txcommon.Helper
-	broadcast         broadcast.Manager
-	identity          identity.Manager
-	blockchain        blockchain.Plugin
-	ffiParamValidator fftypes.FFIParamValidator
-	operations        operations.Manager
+	database           database.Plugin
+	txHelper           txcommon.Helper
+	broadcast          broadcast.Manager
+	identity           identity.Manager
+	blockchains        birouter.Router
+	ffiParamValidators map[blockchain.Plugin]fftypes.FFIParamValidator
+	operations         operations.Manager
 }
 
-func NewContractManager(ctx context.Context, di database.Plugin, bm broadcast.Manager, im identity.Manager, bi blockchain.Plugin, om operations.Manager, txHelper txcommon.Helper) (Manager, error) {
-	if di == nil || bm == nil || im == nil || bi == nil || om == nil {
+func NewContractManager(ctx context.Context, di database.Plugin, bm broadcast.Manager, im identity.Manager, br birouter.Router, om operations.Manager, txHelper txcommon.Helper) (Manager, error) {
+	if di == nil || bm == nil || im == nil || br == nil || om == nil {
 		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
 	}
-	v, err := bi.GetFFIParamValidator(ctx)
-	if err != nil {
-		return nil, i18n.WrapError(ctx, err, i18n.MsgPluginInitializationFailed)
+	validators := make(map[blockchain.Plugin]fftypes.FFIParamValidator)
+	for _, bi := range br.Plugins() {
+		v, err := bi.GetFFIParamValidator(ctx)
+		if err != nil {
+			return nil, i18n.WrapError(ctx, err, i18n.MsgPluginInitializationFailed)
+		}
+		validators[bi] = v
 	}
 
 	cm := &contractManager{
-		database:          di,
-		txHelper:          txHelper,
-		broadcast:         bm,
-		identity:          im,
-		blockchain:        bi,
-		ffiParamValidator: v,
-		operations:        om,
+		database:           di,
+		txHelper:           txHelper,
+		broadcast:          bm,
+		identity:           im,
+		blockchains:        br,
+		ffiParamValidators: validators,
+		operations:         om,
 	}
 
 	om.RegisterHandler(ctx, cm, []fftypes.OpType{
 		fftypes.OpTypeBlockchainInvoke,
+		fftypes.OpTypeBlockchainContractDeploy,
 	})
 
 	return cm, nil
@@ -100,10 +107,11 @@ func (cm *contractManager) Name() string {
 	return "ContractManager"
 }
 
-func (cm *contractManager) newFFISchemaCompiler() *jsonschema.Compiler {
+// newFFISchemaCompiler returns a compiler that validates FFI params against the blockchain plugin of the namespace
+func (cm *contractManager) newFFISchemaCompiler(ns string) *jsonschema.Compiler {
 	c := fftypes.NewFFISchemaCompiler()
-	if cm.ffiParamValidator != nil {
-		c.RegisterExtension(cm.ffiParamValidator.GetExtensionName(), cm.ffiParamValidator.GetMetaSchema(), cm.ffiParamValidator)
+	if v := cm.ffiParamValidators[cm.blockchains.ForNamespace(ns)]; v != nil {
+		c.RegisterExtension(v.GetExtensionName(), v.GetMetaSchema(), v)
 	}
 	return c
 }
@@ -183,7 +191,7 @@ func (cm *contractManager) writeInvokeTransaction(ctx context.Context, ns string
 	}
 
 	op := fftypes.NewOperation(
-		cm.blockchain,
+		cm.blockchains.NamedForNamespace(ns),
 		ns,
 		txid,
 		fftypes.OpTypeBlockchainInvoke)
@@ -193,8 +201,21 @@ func (cm *contractManager) writeInvokeTransaction(ctx context.Context, ns string
 	return op, err
 }
 
+// normalizeSigningKey resolves the signing key with the blockchain plugin of the namespace. Identities are
+// anchored on the default plugin, so for a namespace bound to another plugin the key must be supplied.
+func (cm *contractManager) normalizeSigningKey(ctx context.Context, ns, key string) (string, error) {
+	bi := cm.blockchains.ForNamespace(ns)
+	if bi == cm.blockchains.Default() {
+		return cm.identity.NormalizeSigningKey(ctx, key, identity.KeyNormalizationBlockchainPlugin)
+	}
+	if key == "" {
+		return "", i18n.NewError(ctx, i18n.MsgBlockchainKeyNotSet)
+	}
+	return bi.NormalizeSigningKey(ctx, key)
+}
+
 func (cm *contractManager) InvokeContract(ctx context.Context, ns string, req *fftypes.ContractCallRequest) (res interface{}, err error) {
-	req.Key, err = cm.identity.NormalizeSigningKey(ctx, req.Key, identity.KeyNormalizationBlockchainPlugin)
+	req.Key, err = cm.normalizeSigningKey(ctx, ns, req.Key)
 	if err != nil {
 		return nil, err
 	}
@@ -204,7 +225,10 @@ func (cm *contractManager) InvokeContract(ctx context.Context, ns string, req *f
 		if req.Method, err = cm.resolveInvokeContractRequest(ctx, ns, req); err != nil {
 			return err
 		}
-		if err := cm.validateInvokeContractRequest(ctx, req); err != nil {
+		if err := cm.validateInvokeContractRequest(ctx, ns, req); err != nil {
+			return err
+		}
+		if err := cm.resolveQueryBlock(ctx, ns, req); err != nil {
 			return err
 		}
 		if req.Type == fftypes.CallTypeInvoke {
@@ -224,12 +248,119 @@ func (cm *contractManager) InvokeContract(ctx context.Context, ns string, req *f
 		res = &fftypes.ContractCallResponse{ID: op.ID}
 		return res, cm.operations.RunOperation(ctx, opBlockchainInvoke(op, req))
 	case fftypes.CallTypeQuery:
-		return cm.blockchain.QueryContract(ctx, req.Location, req.Method, req.Input)
+		return cm.blockchains.ForNamespace(ns).QueryContract(ctx, req.Location, req.Method, req.Input, req.Block)
 	default:
 		panic(fmt.Sprintf("unknown call type: %s", req.Type))
 	}
 }
 
+func (cm *contractManager) writeDeployTransaction(ctx context.Context, ns string, req *fftypes.ContractDeployRequest) (*fftypes.Operation, error) {
+	txid, err := cm.txHelper.SubmitNewTransaction(ctx, ns, fftypes.TransactionTypeContractDeploy)
+	if err != nil {
+		return nil, err
+	}
+
+	op := fftypes.NewOperation(
+		cm.blockchains.NamedForNamespace(ns),
+		ns,
+		txid,
+		fftypes.OpTypeBlockchainContractDeploy)
+	if err = txcommon.AddContractDeployInputs(op, req); err == nil {
+		err = cm.database.InsertOperation(ctx, op)
+	}
+	return op, err
+}
+
+// resolveDeployInterface finds the FFI referenced by a deploy request. When a name and version are supplied
+// that do not match an existing FFI, one is generated from the definition of the contract and broadcast.
+func (cm *contractManager) resolveDeployInterface(ctx context.Context, ns string, req *fftypes.ContractDeployRequest) error {
+	ref := req.Interface
+	if ref.ID == nil && ref.Name != "" && ref.Version != "" {
+		existing, err := cm.database.GetFFI(ctx, ns, ref.Name, ref.Version)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			ref.ID = existing.ID
+			return nil
+		}
+		ffi, err := cm.blockchains.ForNamespace(ns).GenerateFFI(ctx, &fftypes.FFIGenerationRequest{
+			Namespace: ns,
+			Name:      ref.Name,
+			Version:   ref.Version,
+			Input:     req.Definition,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err = cm.BroadcastFFI(ctx, ns, ffi, false); err != nil {
+			return err
+		}
+		ref.ID = ffi.ID
+		return nil
+	}
+	return cm.resolveFFIReference(ctx, ns, ref)
+}
+
+// validateDeployContractRequest performs all checks that can fail a deploy request, before the FFI is broadcast
+// or the transaction is written
+func (cm *contractManager) validateDeployContractRequest(ctx context.Context, ns string, req *fftypes.ContractDeployRequest) error {
+	if req.Definition == nil {
+		return i18n.NewError(ctx, i18n.MsgMissingRequiredField, "definition")
+	}
+	if req.Contract == nil {
+		return i18n.NewError(ctx, i18n.MsgMissingRequiredField, "contract")
+	}
+	if err := cm.blockchains.ForNamespace(ns).ValidateContractDeploy(ctx, req.Definition, req.Contract); err != nil {
+		return err
+	}
+	if req.API != "" {
+		if req.Interface == nil {
+			return i18n.NewError(ctx, i18n.MsgContractDeployNoInterface)
+		}
+		if err := fftypes.ValidateFFNameField(ctx, req.API, "api"); err != nil {
+			return err
+		}
+		existing, err := cm.database.GetContractAPIByName(ctx, ns, req.API)
+		if err != nil {
+			return err
+		} else if existing != nil {
+			return i18n.NewError(ctx, i18n.MsgContractAPIExists, ns, req.API)
+		}
+	}
+	return nil
+}
+
+func (cm *contractManager) DeployContract(ctx context.Context, ns string, req *fftypes.ContractDeployRequest) (res *fftypes.ContractDeployResponse, err error) {
+	req.Key, err = cm.normalizeSigningKey(ctx, ns, req.Key)
+	if err != nil {
+		return nil, err
+	}
+	if err = cm.validateDeployContractRequest(ctx, ns, req); err != nil {
+		return nil, err
+	}
+
+	// The interface is established before the deployment, so that it is available to register
+	// the contract API against once the location of the contract is known
+	if req.Interface != nil {
+		if err = cm.resolveDeployInterface(ctx, ns, req); err != nil {
+			return nil, err
+		}
+	}
+
+	var op *fftypes.Operation
+	err = cm.database.RunAsGroup(ctx, func(ctx context.Context) (err error) {
+		op, err = cm.writeDeployTransaction(ctx, ns, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res = &fftypes.ContractDeployResponse{ID: op.ID, Interface: req.Interface}
+	return res, cm.operations.RunOperation(ctx, opBlockchainContractDeploy(op, req))
+}
+
 func (cm *contractManager) InvokeContractAPI(ctx context.Context, ns, apiName, methodPath string, req *fftypes.ContractCallRequest) (interface{}, error) {
 	api, err := cm.database.GetContractAPIByName(ctx, ns, apiName)
 	if err != nil {
@@ -375,7 +506,7 @@ func (cm *contractManager) ValidateFFIAndSetPathnames(ctx context.Context, ffi *
 		method.Contract = ffi.ID
 		method.Namespace = ffi.Namespace
 		method.Pathname = cm.uniquePathName(method.Name, methodPathNames)
-		if err := cm.validateFFIMethod(ctx, method); err != nil {
+		if err := cm.validateFFIMethod(ctx, ffi.Namespace, method); err != nil {
 			return err
 		}
 	}
@@ -385,32 +516,32 @@ func (cm *contractManager) ValidateFFIAndSetPathnames(ctx context.Context, ffi *
 		event.Contract = ffi.ID
 		event.Namespace = ffi.Namespace
 		event.Pathname = cm.uniquePathName(event.Name, eventPathNames)
-		if err := cm.validateFFIEvent(ctx, &event.FFIEventDefinition); err != nil {
+		if err := cm.validateFFIEvent(ctx, ffi.Namespace, &event.FFIEventDefinition); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (cm *contractManager) validateFFIMethod(ctx context.Context, method *fftypes.FFIMethod) error {
+func (cm *contractManager) validateFFIMethod(ctx context.Context, ns string, method *fftypes.FFIMethod) error {
 	if method.Name == "" {
 		return i18n.NewError(ctx, i18n.MsgMethodNameMustBeSet)
 	}
 	for _, param := range method.Params {
-		if err := cm.validateFFIParam(ctx, param); err != nil {
+		if err := cm.validateFFIParam(ctx, ns, param); err != nil {
 			return err
 		}
 	}
 	for _, param := range method.Returns {
-		if err := cm.validateFFIParam(ctx, param); err != nil {
+		if err := cm.validateFFIParam(ctx, ns, param); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (cm *contractManager) validateFFIParam(ctx context.Context, param *fftypes.FFIParam) error {
-	c := cm.newFFISchemaCompiler()
+func (cm *contractManager) validateFFIParam(ctx context.Context, ns string, param *fftypes.FFIParam) error {
+	c := cm.newFFISchemaCompiler(ns)
 	if err := c.AddResource(param.Name, strings.NewReader(param.Schema.String())); err != nil {
 		return i18n.WrapError(ctx, err, i18n.MsgFFISchemaParseFail, param.Name)
 	}
@@ -420,20 +551,20 @@ func (cm *contractManager) validateFFIParam(ctx context.Context, param *fftypes.
 	return nil
 }
 
-func (cm *contractManager) validateFFIEvent(ctx context.Context, event *fftypes.FFIEventDefinition) error {
+func (cm *contractManager) validateFFIEvent(ctx context.Context, ns string, event *fftypes.FFIEventDefinition) error {
 	if event.Name == "" {
 		return i18n.NewError(ctx, i18n.MsgEventNameMustBeSet)
 	}
 	for _, param := range event.Params {
-		if err := cm.validateFFIParam(ctx, param); err != nil {
+		if err := cm.validateFFIParam(ctx, ns, param); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (cm *contractManager) validateInvokeContractRequest(ctx context.Context, req *fftypes.ContractCallRequest) error {
-	if err := cm.validateFFIMethod(ctx, req.Method); err != nil {
+func (cm *contractManager) validateInvokeContractRequest(ctx context.Context, ns string, req *fftypes.ContractCallRequest) error {
+	if err := cm.validateFFIMethod(ctx, ns, req.Method); err != nil {
 		return err
 	}
 
@@ -450,6 +581,37 @@ func (cm *contractManager) validateInvokeContractRequest(ctx context.Context, re
 	return nil
 }
 
+// resolveQueryBlock checks the block a query should be made at is supported by the blockchain plugin, resolving
+// it from the blockchain event the query refers to if required
+func (cm *contractManager) resolveQueryBlock(ctx context.Context, ns string, req *fftypes.ContractCallRequest) error {
+	if req.Block == "" && req.BlockchainEvent == nil {
+		return nil
+	}
+	if req.Type != fftypes.CallTypeQuery {
+		return i18n.NewError(ctx, i18n.MsgQueryBlockNotForInvoke)
+	}
+	bi := cm.blockchains.ForNamespace(ns)
+	if !bi.Capabilities().HistoricalQuery {
+		return i18n.NewError(ctx, i18n.MsgHistoricalQueryUnsupported, bi.Name())
+	}
+	if req.BlockchainEvent != nil {
+		if req.Block != "" {
+			return i18n.NewError(ctx, i18n.MsgQueryBlockConflict)
+		}
+		event, err := cm.database.GetBlockchainEventByID(ctx, req.BlockchainEvent)
+		if err != nil {
+			return err
+		}
+		if event == nil || event.Namespace != ns {
+			return i18n.NewError(ctx, i18n.MsgQueryBlockchainEventNotFound, req.BlockchainEvent)
+		}
+		if req.Block = event.Info.GetString("blockNumber"); req.Block == "" {
+			return i18n.NewError(ctx, i18n.MsgQueryBlockchainEventNoBlock, req.BlockchainEvent)
+		}
+	}
+	return nil
+}
+
 func (cm *contractManager) AddContractListener(ctx context.Context, ns string, listener *fftypes.ContractListenerInput) (output *fftypes.ContractListener, err error) {
 	listener.ID = fftypes.NewUUID()
 	listener.Namespace = ns
@@ -516,10 +678,13 @@ func (cm *contractManager) AddContractListener(ctx context.Context, ns string, l
 		return nil, err
 	}
 
-	if err := cm.validateFFIEvent(ctx, &listener.Event.FFIEventDefinition); err != nil {
+	if err := cm.validateFFIEvent(ctx, ns, &listener.Event.FFIEventDefinition); err != nil {
 		return nil, err
 	}
-	if err = cm.blockchain.AddContractListener(ctx, listener); err != nil {
+	if err := cm.validateContractListenerFilter(ctx, &listener.Event.FFIEventDefinition, listener.Options.Filter); err != nil {
+		return nil, err
+	}
+	if err = cm.blockchains.ForNamespace(ns).AddContractListener(ctx, listener); err != nil {
 		return nil, err
 	}
 	if listener.Name == "" {
@@ -532,6 +697,36 @@ func (cm *contractManager) AddContractListener(ctx context.Context, ns string, l
 	return &listener.ContractListener, err
 }
 
+// validateContractListenerFilter checks every entry of a listener filter refers to a parameter of the event, and
+// that each of the alternative values for the parameter is valid against its schema
+func (cm *contractManager) validateContractListenerFilter(ctx context.Context, event *fftypes.FFIEventDefinition, filter fftypes.JSONObject) error {
+	for name, value := range filter {
+		var param *fftypes.FFIParam
+		for _, p := range event.Params {
+			if p.Name == name {
+				param = p
+				break
+			}
+		}
+		if param == nil {
+			return i18n.NewError(ctx, i18n.MsgListenerFilterParamUnknown, name, event.Name)
+		}
+		values, ok := fftypes.ListenerFilterValues(value)
+		if !ok {
+			return i18n.NewError(ctx, i18n.MsgListenerFilterAmbiguous, name)
+		}
+		if len(values) == 0 {
+			return i18n.NewError(ctx, i18n.MsgListenerFilterValueInvalid, name, "no values")
+		}
+		for _, v := range values {
+			if err := cm.checkParamSchema(ctx, v, param); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (cm *contractManager) GetContractListenerByNameOrID(ctx context.Context, ns, nameOrID string) (listener *fftypes.ContractListener, err error) {
 	id, err := fftypes.ParseUUID(ctx, nameOrID)
 	if err != nil {
@@ -560,7 +755,7 @@ func (cm *contractManager) DeleteContractListenerByNameOrID(ctx context.Context,
 		if err != nil {
 			return err
 		}
-		if err = cm.blockchain.DeleteContractListener(ctx, listener); err != nil {
+		if err = cm.blockchains.ForNamespace(listener.Namespace).DeleteContractListener(ctx, listener); err != nil {
 			return err
 		}
 		return cm.database.DeleteContractListenerByID(ctx, listener.ID)
@@ -586,7 +781,7 @@ func (cm *contractManager) checkParamSchema(ctx context.Context, input interface
 
 func (cm *contractManager) GenerateFFI(ctx context.Context, ns string, generationRequest *fftypes.FFIGenerationRequest) (*fftypes.FFI, error) {
 	generationRequest.Namespace = ns
-	return cm.blockchain.GenerateFFI(ctx, generationRequest)
+	return cm.blockchains.ForNamespace(ns).GenerateFFI(ctx, generationRequest)
 }
 
 func (cm *contractManager) getDefaultContractListenerOptions() *fftypes.ContractListenerOptions {
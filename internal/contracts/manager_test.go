@@ -24,6 +24,7 @@ import (
 	"github.com/hyperledger/firefly/internal/blockchain/ethereum"
 	"github.com/hyperledger/firefly/internal/identity"
 	"github.com/hyperledger/firefly/internal/txcommon"
+	"github.com/hyperledger/firefly/mocks/biroutermocks"
 	"github.com/hyperledger/firefly/mocks/blockchainmocks"
 	"github.com/hyperledger/firefly/mocks/broadcastmocks"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
@@ -31,6 +32,7 @@ import (
 	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
 	"github.com/hyperledger/firefly/mocks/operationmocks"
 	"github.com/hyperledger/firefly/mocks/txcommonmocks"
+	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/santhosh-tekuri/jsonschema/v5"
@@ -38,6 +40,15 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
+func newTestRouter(mbi *blockchainmocks.Plugin) *biroutermocks.Router {
+	mbr := &biroutermocks.Router{}
+	mbr.On("Plugins").Return(map[string]blockchain.Plugin{"ut": mbi}).Maybe()
+	mbr.On("Default").Return(mbi).Maybe()
+	mbr.On("ForNamespace", mock.Anything).Return(mbi).Maybe()
+	mbr.On("NamedForNamespace", mock.Anything).Return(mbi).Maybe()
+	return mbr
+}
+
 func newTestContractManager() *contractManager {
 	mdi := &databasemocks.Plugin{}
 	mdm := &datamocks.Manager{}
@@ -50,6 +61,7 @@ func newTestContractManager() *contractManager {
 	mom.On("RegisterHandler", mock.Anything, mock.Anything, mock.Anything)
 
 	mbi.On("Name").Return("mockblockchain").Maybe()
+	mbr := newTestRouter(mbi)
 
 	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything).Maybe()
 	rag.RunFn = func(a mock.Arguments) {
@@ -57,7 +69,7 @@ func newTestContractManager() *contractManager {
 			a[1].(func(context.Context) error)(a[0].(context.Context)),
 		}
 	}
-	cm, _ := NewContractManager(context.Background(), mdi, mbm, mim, mbi, mom, txHelper)
+	cm, _ := NewContractManager(context.Background(), mdi, mbm, mim, mbr, mom, txHelper)
 	cm.(*contractManager).txHelper = &txcommonmocks.Helper{}
 	return cm.(*contractManager)
 }
@@ -81,7 +93,7 @@ func TestNewContractManagerFFISchemaLoaderFail(t *testing.T) {
 	mom := &operationmocks.Manager{}
 	txHelper := txcommon.NewTransactionHelper(mdi, mdm)
 	mbi.On("GetFFIParamValidator", mock.Anything).Return(nil, fmt.Errorf("pop"))
-	_, err := NewContractManager(context.Background(), mdi, mbm, mim, mbi, mom, txHelper)
+	_, err := NewContractManager(context.Background(), mdi, mbm, mim, newTestRouter(mbi), mom, txHelper)
 	assert.Regexp(t, "pop", err)
 }
 
@@ -95,7 +107,7 @@ func TestNewContractManagerFFISchemaLoader(t *testing.T) {
 	txHelper := txcommon.NewTransactionHelper(mdi, mdm)
 	mbi.On("GetFFIParamValidator", mock.Anything).Return(&ethereum.FFIParamValidator{}, nil)
 	mom.On("RegisterHandler", mock.Anything, mock.Anything, mock.Anything)
-	_, err := NewContractManager(context.Background(), mdi, mbm, mim, mbi, mom, txHelper)
+	_, err := NewContractManager(context.Background(), mdi, mbm, mim, newTestRouter(mbi), mom, txHelper)
 	assert.NoError(t, err)
 }
 
@@ -242,7 +254,7 @@ func TestValidateInvokeContractRequest(t *testing.T) {
 			"y": float64(2),
 		},
 	}
-	err := cm.validateInvokeContractRequest(context.Background(), req)
+	err := cm.validateInvokeContractRequest(context.Background(), "ns1", req)
 	assert.NoError(t, err)
 }
 
@@ -273,7 +285,7 @@ func TestValidateInvokeContractRequestMissingInput(t *testing.T) {
 			"x": float64(1),
 		},
 	}
-	err := cm.validateInvokeContractRequest(context.Background(), req)
+	err := cm.validateInvokeContractRequest(context.Background(), "ns1", req)
 	assert.Regexp(t, "Missing required input argument 'y'", err)
 }
 
@@ -305,7 +317,7 @@ func TestValidateInvokeContractRequestInputWrongType(t *testing.T) {
 			"y": "two",
 		},
 	}
-	err := cm.validateInvokeContractRequest(context.Background(), req)
+	err := cm.validateInvokeContractRequest(context.Background(), "ns1", req)
 	assert.Regexp(t, "expected integer, but got string", err)
 }
 
@@ -338,7 +350,7 @@ func TestValidateInvokeContractRequestInvalidParam(t *testing.T) {
 		},
 	}
 
-	err := cm.validateInvokeContractRequest(context.Background(), req)
+	err := cm.validateInvokeContractRequest(context.Background(), "ns1", req)
 	assert.Regexp(t, "does not validate", err)
 }
 
@@ -557,7 +569,7 @@ func TestValidateFFIBadEventParam(t *testing.T) {
 
 func TestAddContractListenerInline(t *testing.T) {
 	cm := newTestContractManager()
-	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
 	mdi := cm.database.(*databasemocks.Plugin)
 
 	sub := &fftypes.ContractListenerInput{
@@ -592,9 +604,94 @@ func TestAddContractListenerInline(t *testing.T) {
 	mdi.AssertExpectations(t)
 }
 
+func TestAddContractListenerFilter(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	sub := &fftypes.ContractListenerInput{
+		ContractListener: fftypes.ContractListener{
+			Location: fftypes.JSONAnyPtr(fftypes.JSONObject{
+				"address": "0x123",
+			}.String()),
+			Event: &fftypes.FFISerializedEvent{
+				FFIEventDefinition: fftypes.FFIEventDefinition{
+					Name: "Transfer",
+					Params: fftypes.FFIParams{
+						{
+							Name:   "from",
+							Schema: fftypes.JSONAnyPtr(`{"type": "string"}`),
+						},
+						{
+							Name:   "value",
+							Schema: fftypes.JSONAnyPtr(`{"type": "integer"}`),
+						},
+					},
+				},
+			},
+			Options: &fftypes.ContractListenerOptions{
+				Filter: fftypes.JSONObject{
+					"from":  map[string]interface{}{"anyOf": []interface{}{"0x111", "0x222"}},
+					"value": float64(100),
+				},
+			},
+		},
+	}
+
+	mbi.On("AddContractListener", context.Background(), sub).Return(nil)
+	mdi.On("UpsertContractListener", context.Background(), &sub.ContractListener).Return(nil)
+
+	result, err := cm.AddContractListener(context.Background(), "ns", sub)
+	assert.NoError(t, err)
+	assert.Equal(t, string(fftypes.SubOptsFirstEventNewest), result.Options.FirstEvent)
+
+	mbi.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestAddContractListenerFilterBad(t *testing.T) {
+	cm := newTestContractManager()
+
+	newSub := func(filter fftypes.JSONObject) *fftypes.ContractListenerInput {
+		return &fftypes.ContractListenerInput{
+			ContractListener: fftypes.ContractListener{
+				Location: fftypes.JSONAnyPtr(fftypes.JSONObject{
+					"address": "0x123",
+				}.String()),
+				Event: &fftypes.FFISerializedEvent{
+					FFIEventDefinition: fftypes.FFIEventDefinition{
+						Name: "Transfer",
+						Params: fftypes.FFIParams{
+							{
+								Name:   "value",
+								Schema: fftypes.JSONAnyPtr(`{"type": "integer"}`),
+							},
+						},
+					},
+				},
+				Options: &fftypes.ContractListenerOptions{
+					Filter: filter,
+				},
+			},
+		}
+	}
+
+	_, err := cm.AddContractListener(context.Background(), "ns", newSub(fftypes.JSONObject{"to": "0x111"}))
+	assert.Regexp(t, "FF10439.*to.*Transfer", err)
+
+	_, err = cm.AddContractListener(context.Background(), "ns", newSub(fftypes.JSONObject{"value": []interface{}{float64(1)}}))
+	assert.Regexp(t, "FF10448.*value", err)
+
+	_, err = cm.AddContractListener(context.Background(), "ns", newSub(fftypes.JSONObject{"value": map[string]interface{}{"anyOf": []interface{}{}}}))
+	assert.Regexp(t, "FF10440.*value", err)
+
+	_, err = cm.AddContractListener(context.Background(), "ns", newSub(fftypes.JSONObject{"value": map[string]interface{}{"anyOf": []interface{}{float64(1), "one"}}}))
+	assert.Regexp(t, "FF10331.*value", err)
+}
+
 func TestAddContractListenerByRef(t *testing.T) {
 	cm := newTestContractManager()
-	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
 	mdi := cm.database.(*databasemocks.Plugin)
 
 	interfaceID := fftypes.NewUUID()
@@ -645,7 +742,7 @@ func TestAddContractListenerByRef(t *testing.T) {
 
 func TestAddContractListenerByEventID(t *testing.T) {
 	cm := newTestContractManager()
-	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
 	mdi := cm.database.(*databasemocks.Plugin)
 
 	eventID := fftypes.NewUUID()
@@ -846,7 +943,7 @@ func TestAddContractListenerByRefLookupWrongNS(t *testing.T) {
 
 func TestAddContractListenerBadNamespace(t *testing.T) {
 	cm := newTestContractManager()
-	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
 	mdi := cm.database.(*databasemocks.Plugin)
 
 	sub := &fftypes.ContractListenerInput{}
@@ -860,7 +957,7 @@ func TestAddContractListenerBadNamespace(t *testing.T) {
 
 func TestAddContractListenerBadName(t *testing.T) {
 	cm := newTestContractManager()
-	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
 	mdi := cm.database.(*databasemocks.Plugin)
 
 	sub := &fftypes.ContractListenerInput{
@@ -878,7 +975,7 @@ func TestAddContractListenerBadName(t *testing.T) {
 
 func TestAddContractListenerNameConflict(t *testing.T) {
 	cm := newTestContractManager()
-	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
 	mdi := cm.database.(*databasemocks.Plugin)
 
 	sub := &fftypes.ContractListenerInput{
@@ -902,7 +999,7 @@ func TestAddContractListenerNameConflict(t *testing.T) {
 
 func TestAddContractListenerNameError(t *testing.T) {
 	cm := newTestContractManager()
-	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
 	mdi := cm.database.(*databasemocks.Plugin)
 
 	sub := &fftypes.ContractListenerInput{
@@ -926,7 +1023,7 @@ func TestAddContractListenerNameError(t *testing.T) {
 
 func TestAddContractListenerValidateFail(t *testing.T) {
 	cm := newTestContractManager()
-	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
 	mdi := cm.database.(*databasemocks.Plugin)
 
 	sub := &fftypes.ContractListenerInput{
@@ -957,7 +1054,7 @@ func TestAddContractListenerValidateFail(t *testing.T) {
 
 func TestAddContractListenerBlockchainFail(t *testing.T) {
 	cm := newTestContractManager()
-	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
 	mdi := cm.database.(*databasemocks.Plugin)
 
 	sub := &fftypes.ContractListenerInput{
@@ -990,7 +1087,7 @@ func TestAddContractListenerBlockchainFail(t *testing.T) {
 
 func TestAddContractListenerUpsertSubFail(t *testing.T) {
 	cm := newTestContractManager()
-	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
 	mdi := cm.database.(*databasemocks.Plugin)
 
 	sub := &fftypes.ContractListenerInput{
@@ -1174,6 +1271,64 @@ func TestInvokeContract(t *testing.T) {
 	mom.AssertExpectations(t)
 }
 
+func TestInvokeContractOtherBlockchain(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+	mth := cm.txHelper.(*txcommonmocks.Helper)
+	mom := cm.operations.(*operationmocks.Manager)
+	mfab := &blockchainmocks.Plugin{}
+	mfabName := &blockchainmocks.Plugin{}
+	mfabName.On("Name").Return("fabric1")
+	mbr := &biroutermocks.Router{}
+	mbr.On("Default").Return(cm.blockchains.Default())
+	mbr.On("ForNamespace", "ns2").Return(mfab)
+	mbr.On("NamedForNamespace", "ns2").Return(mfabName)
+	cm.blockchains = mbr
+
+	req := &fftypes.ContractCallRequest{
+		Type:      fftypes.CallTypeInvoke,
+		Interface: fftypes.NewUUID(),
+		Key:       "user1",
+		Ledger:    fftypes.JSONAnyPtr(""),
+		Location:  fftypes.JSONAnyPtr(""),
+		Method: &fftypes.FFIMethod{
+			Name:    "doStuff",
+			ID:      fftypes.NewUUID(),
+			Params:  fftypes.FFIParams{},
+			Returns: fftypes.FFIParams{},
+		},
+	}
+
+	mth.On("SubmitNewTransaction", mock.Anything, "ns2", fftypes.TransactionTypeContractInvoke).Return(fftypes.NewUUID(), nil)
+	mfab.On("NormalizeSigningKey", mock.Anything, "user1").Return("Org1MSP::user1", nil)
+	mdi.On("InsertOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.Namespace == "ns2" && op.Plugin == "fabric1"
+	})).Return(nil)
+	mom.On("RunOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.PreparedOperation) bool {
+		data := op.Data.(blockchainInvokeData)
+		return data.Namespace == "ns2" && data.Request.Key == "Org1MSP::user1"
+	})).Return(nil)
+
+	_, err := cm.InvokeContract(context.Background(), "ns2", req)
+	assert.NoError(t, err)
+
+	mth.AssertExpectations(t)
+	mfab.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestInvokeContractOtherBlockchainNoKey(t *testing.T) {
+	cm := newTestContractManager()
+	mbr := &biroutermocks.Router{}
+	mbr.On("Default").Return(cm.blockchains.Default())
+	mbr.On("ForNamespace", "ns2").Return(&blockchainmocks.Plugin{})
+	cm.blockchains = mbr
+
+	_, err := cm.InvokeContract(context.Background(), "ns2", &fftypes.ContractCallRequest{})
+	assert.Regexp(t, "FF10352", err)
+}
+
 func TestInvokeContractFail(t *testing.T) {
 	cm := newTestContractManager()
 	mim := cm.identity.(*identitymanagermocks.Manager)
@@ -1234,7 +1389,7 @@ func TestInvokeContractFailNormalizeSigningKey(t *testing.T) {
 
 func TestInvokeContractFailResolve(t *testing.T) {
 	cm := newTestContractManager()
-	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
 	mim := cm.identity.(*identitymanagermocks.Manager)
 
 	req := &fftypes.ContractCallRequest{
@@ -1359,7 +1514,7 @@ func TestInvokeContractMethodBadInput(t *testing.T) {
 
 func TestQueryContract(t *testing.T) {
 	cm := newTestContractManager()
-	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
 	mim := cm.identity.(*identitymanagermocks.Manager)
 	mdi := cm.database.(*databasemocks.Plugin)
 	mth := cm.txHelper.(*txcommonmocks.Helper)
@@ -1382,13 +1537,114 @@ func TestQueryContract(t *testing.T) {
 	mdi.On("InsertOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
 		return op.Namespace == "ns1" && op.Type == fftypes.OpTypeBlockchainInvoke && op.Plugin == "mockblockchain"
 	})).Return(nil)
-	mbi.On("QueryContract", mock.Anything, req.Location, req.Method, req.Input).Return(struct{}{}, nil)
+	mbi.On("QueryContract", mock.Anything, req.Location, req.Method, req.Input, "").Return(struct{}{}, nil)
 
 	_, err := cm.InvokeContract(context.Background(), "ns1", req)
 
 	assert.NoError(t, err)
 }
 
+func newTestQueryAtBlockRequest() *fftypes.ContractCallRequest {
+	return &fftypes.ContractCallRequest{
+		Type:     fftypes.CallTypeQuery,
+		Location: fftypes.JSONAnyPtr(""),
+		Method: &fftypes.FFIMethod{
+			Name:    "balanceOf",
+			ID:      fftypes.NewUUID(),
+			Params:  fftypes.FFIParams{},
+			Returns: fftypes.FFIParams{},
+		},
+	}
+}
+
+func TestQueryContractAtBlock(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
+	mim := cm.identity.(*identitymanagermocks.Manager)
+
+	req := newTestQueryAtBlockRequest()
+	req.Block = "12345"
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mbi.On("Capabilities").Return(&blockchain.Capabilities{HistoricalQuery: true})
+	mbi.On("QueryContract", mock.Anything, req.Location, req.Method, req.Input, "12345").Return(struct{}{}, nil)
+
+	_, err := cm.InvokeContract(context.Background(), "ns1", req)
+	assert.NoError(t, err)
+
+	mbi.AssertExpectations(t)
+}
+
+func TestQueryContractAtBlockchainEvent(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	req := newTestQueryAtBlockRequest()
+	req.BlockchainEvent = fftypes.NewUUID()
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mbi.On("Capabilities").Return(&blockchain.Capabilities{HistoricalQuery: true})
+	mdi.On("GetBlockchainEventByID", mock.Anything, req.BlockchainEvent).Return(&fftypes.BlockchainEvent{
+		Namespace: "ns1",
+		Info:      fftypes.JSONObject{"blockNumber": "38011"},
+	}, nil)
+	mbi.On("QueryContract", mock.Anything, req.Location, req.Method, req.Input, "38011").Return(struct{}{}, nil)
+
+	_, err := cm.InvokeContract(context.Background(), "ns1", req)
+	assert.NoError(t, err)
+
+	mbi.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestQueryContractAtBlockErrors(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mbi.On("Capabilities").Return(&blockchain.Capabilities{HistoricalQuery: false}).Once()
+	mbi.On("Capabilities").Return(&blockchain.Capabilities{HistoricalQuery: true})
+
+	req := newTestQueryAtBlockRequest()
+	req.Block = "12345"
+	_, err := cm.InvokeContract(context.Background(), "ns1", req)
+	assert.Regexp(t, "FF10442.*mockblockchain", err)
+
+	req = newTestQueryAtBlockRequest()
+	req.Type = fftypes.CallTypeInvoke
+	req.Block = "12345"
+	_, err = cm.InvokeContract(context.Background(), "ns1", req)
+	assert.Regexp(t, "FF10443", err)
+
+	req = newTestQueryAtBlockRequest()
+	req.Block = "12345"
+	req.BlockchainEvent = fftypes.NewUUID()
+	_, err = cm.InvokeContract(context.Background(), "ns1", req)
+	assert.Regexp(t, "FF10444", err)
+
+	req = newTestQueryAtBlockRequest()
+	req.BlockchainEvent = fftypes.NewUUID()
+	mdi.On("GetBlockchainEventByID", mock.Anything, req.BlockchainEvent).Return(nil, fmt.Errorf("pop"))
+	_, err = cm.InvokeContract(context.Background(), "ns1", req)
+	assert.EqualError(t, err, "pop")
+
+	req = newTestQueryAtBlockRequest()
+	req.BlockchainEvent = fftypes.NewUUID()
+	mdi.On("GetBlockchainEventByID", mock.Anything, req.BlockchainEvent).Return(&fftypes.BlockchainEvent{Namespace: "ns2"}, nil)
+	_, err = cm.InvokeContract(context.Background(), "ns1", req)
+	assert.Regexp(t, "FF10445", err)
+
+	req = newTestQueryAtBlockRequest()
+	req.BlockchainEvent = fftypes.NewUUID()
+	mdi.On("GetBlockchainEventByID", mock.Anything, req.BlockchainEvent).Return(&fftypes.BlockchainEvent{Namespace: "ns1"}, nil)
+	_, err = cm.InvokeContract(context.Background(), "ns1", req)
+	assert.Regexp(t, "FF10446", err)
+}
+
 func TestCallContractInvalidType(t *testing.T) {
 	cm := newTestContractManager()
 	mim := cm.identity.(*identitymanagermocks.Manager)
@@ -1490,7 +1746,7 @@ func TestGetContractListeners(t *testing.T) {
 
 func TestDeleteContractListener(t *testing.T) {
 	cm := newTestContractManager()
-	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
 	mdi := cm.database.(*databasemocks.Plugin)
 
 	sub := &fftypes.ContractListener{
@@ -1507,7 +1763,7 @@ func TestDeleteContractListener(t *testing.T) {
 
 func TestDeleteContractListenerBlockchainFail(t *testing.T) {
 	cm := newTestContractManager()
-	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
 	mdi := cm.database.(*databasemocks.Plugin)
 
 	sub := &fftypes.ContractListener{
@@ -1532,6 +1788,371 @@ func TestDeleteContractListenerNotFound(t *testing.T) {
 	assert.Regexp(t, "FF10109", err)
 }
 
+func newTestDeployRequest() *fftypes.ContractDeployRequest {
+	return &fftypes.ContractDeployRequest{
+		Definition: fftypes.JSONAnyPtr(`[]`),
+		Contract:   fftypes.JSONAnyPtr(`"0x6080"`),
+		Input:      []interface{}{"1"},
+	}
+}
+
+func TestDeployContract(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mdi := cm.database.(*databasemocks.Plugin)
+	mth := cm.txHelper.(*txcommonmocks.Helper)
+	mom := cm.operations.(*operationmocks.Manager)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
+
+	req := newTestDeployRequest()
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mbi.On("ValidateContractDeploy", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeContractDeploy).Return(fftypes.NewUUID(), nil)
+	mdi.On("InsertOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.Namespace == "ns1" && op.Type == fftypes.OpTypeBlockchainContractDeploy && op.Plugin == "mockblockchain" &&
+			op.Input.GetString("key") == "key-resolved"
+	})).Return(nil)
+	mom.On("RunOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.PreparedOperation) bool {
+		data := op.Data.(blockchainContractDeployData)
+		return op.Type == fftypes.OpTypeBlockchainContractDeploy && data.Request == req && data.Namespace == "ns1"
+	})).Return(nil)
+
+	res, err := cm.DeployContract(context.Background(), "ns1", req)
+	assert.NoError(t, err)
+	assert.NotNil(t, res.ID)
+	assert.Nil(t, res.Interface)
+
+	mth.AssertExpectations(t)
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestDeployContractGenerateInterfaceAndAPI(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mdi := cm.database.(*databasemocks.Plugin)
+	mth := cm.txHelper.(*txcommonmocks.Helper)
+	mom := cm.operations.(*operationmocks.Manager)
+	mbm := cm.broadcast.(*broadcastmocks.Manager)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
+
+	req := newTestDeployRequest()
+	req.Key = "key1"
+	req.Interface = &fftypes.FFIReference{Name: "simple", Version: "v1"}
+	req.API = "simple"
+
+	mim.On("NormalizeSigningKey", mock.Anything, "key1", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mbi.On("ValidateContractDeploy", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("GetContractAPIByName", mock.Anything, "ns1", "simple").Return(nil, nil)
+	mdi.On("GetFFI", mock.Anything, "ns1", "simple", "v1").Return(nil, nil)
+	mbi.On("GenerateFFI", mock.Anything, mock.MatchedBy(func(gr *fftypes.FFIGenerationRequest) bool {
+		return gr.Namespace == "ns1" && gr.Name == "simple" && gr.Version == "v1" && gr.Input == req.Definition
+	})).Return(&fftypes.FFI{
+		Name:    "simple",
+		Version: "v1",
+		Methods: []*fftypes.FFIMethod{{Name: "set"}},
+	}, nil)
+	mbm.On("BroadcastDefinitionAsNode", mock.Anything, "ns1", mock.AnythingOfType("*fftypes.FFI"), fftypes.SystemTagDefineFFI, false).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{ID: fftypes.NewUUID()},
+	}, nil)
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeContractDeploy).Return(fftypes.NewUUID(), nil)
+	mdi.On("InsertOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.Input.GetString("api") == "simple" && op.Input.GetObject("interface").GetString("id") != ""
+	})).Return(nil)
+	mom.On("RunOperation", mock.Anything, mock.Anything).Return(nil)
+
+	res, err := cm.DeployContract(context.Background(), "ns1", req)
+	assert.NoError(t, err)
+	assert.NotNil(t, res.Interface.ID)
+	assert.Equal(t, "simple", res.Interface.Name)
+
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+	mbm.AssertExpectations(t)
+	mbi.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestDeployContractExistingInterfaceByName(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mdi := cm.database.(*databasemocks.Plugin)
+	mth := cm.txHelper.(*txcommonmocks.Helper)
+	mom := cm.operations.(*operationmocks.Manager)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
+
+	req := newTestDeployRequest()
+	req.Interface = &fftypes.FFIReference{Name: "simple", Version: "v1"}
+	ffiID := fftypes.NewUUID()
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mbi.On("ValidateContractDeploy", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("GetFFI", mock.Anything, "ns1", "simple", "v1").Return(&fftypes.FFI{ID: ffiID}, nil)
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeContractDeploy).Return(fftypes.NewUUID(), nil)
+	mdi.On("InsertOperation", mock.Anything, mock.Anything).Return(nil)
+	mom.On("RunOperation", mock.Anything, mock.Anything).Return(nil)
+
+	res, err := cm.DeployContract(context.Background(), "ns1", req)
+	assert.NoError(t, err)
+	assert.Equal(t, ffiID, res.Interface.ID)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestDeployContractInterfaceByID(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mdi := cm.database.(*databasemocks.Plugin)
+	mth := cm.txHelper.(*txcommonmocks.Helper)
+	mom := cm.operations.(*operationmocks.Manager)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
+
+	req := newTestDeployRequest()
+	ffiID := fftypes.NewUUID()
+	req.Interface = &fftypes.FFIReference{ID: ffiID}
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mbi.On("ValidateContractDeploy", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("GetFFIByID", mock.Anything, ffiID).Return(&fftypes.FFI{ID: ffiID}, nil)
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeContractDeploy).Return(fftypes.NewUUID(), nil)
+	mdi.On("InsertOperation", mock.Anything, mock.Anything).Return(nil)
+	mom.On("RunOperation", mock.Anything, mock.Anything).Return(nil)
+
+	res, err := cm.DeployContract(context.Background(), "ns1", req)
+	assert.NoError(t, err)
+	assert.Equal(t, ffiID, res.Interface.ID)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestDeployContractInterfaceNotFound(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mdi := cm.database.(*databasemocks.Plugin)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
+
+	req := newTestDeployRequest()
+	ffiID := fftypes.NewUUID()
+	req.Interface = &fftypes.FFIReference{ID: ffiID}
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mbi.On("ValidateContractDeploy", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("GetFFIByID", mock.Anything, ffiID).Return(nil, nil)
+
+	_, err := cm.DeployContract(context.Background(), "ns1", req)
+	assert.Regexp(t, "FF10303", err)
+}
+
+func TestDeployContractGetInterfaceFail(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mdi := cm.database.(*databasemocks.Plugin)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
+
+	req := newTestDeployRequest()
+	req.Interface = &fftypes.FFIReference{Name: "simple", Version: "v1"}
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mbi.On("ValidateContractDeploy", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("GetFFI", mock.Anything, "ns1", "simple", "v1").Return(nil, fmt.Errorf("pop"))
+
+	_, err := cm.DeployContract(context.Background(), "ns1", req)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDeployContractGenerateInterfaceFail(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mdi := cm.database.(*databasemocks.Plugin)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
+
+	req := newTestDeployRequest()
+	req.Interface = &fftypes.FFIReference{Name: "simple", Version: "v1"}
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mbi.On("ValidateContractDeploy", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("GetFFI", mock.Anything, "ns1", "simple", "v1").Return(nil, nil)
+	mbi.On("GenerateFFI", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	_, err := cm.DeployContract(context.Background(), "ns1", req)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDeployContractBroadcastInterfaceFail(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mdi := cm.database.(*databasemocks.Plugin)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
+	mbm := cm.broadcast.(*broadcastmocks.Manager)
+
+	req := newTestDeployRequest()
+	req.Interface = &fftypes.FFIReference{Name: "simple", Version: "v1"}
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mbi.On("ValidateContractDeploy", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("GetFFI", mock.Anything, "ns1", "simple", "v1").Return(nil, nil)
+	mbi.On("GenerateFFI", mock.Anything, mock.Anything).Return(&fftypes.FFI{Name: "simple", Version: "v1"}, nil)
+	mbm.On("BroadcastDefinitionAsNode", mock.Anything, "ns1", mock.AnythingOfType("*fftypes.FFI"), fftypes.SystemTagDefineFFI, false).Return(nil, fmt.Errorf("pop"))
+
+	_, err := cm.DeployContract(context.Background(), "ns1", req)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDeployContractBadKey(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("", fmt.Errorf("pop"))
+
+	_, err := cm.DeployContract(context.Background(), "ns1", newTestDeployRequest())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDeployContractMissingDefinition(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+
+	req := newTestDeployRequest()
+	req.Definition = nil
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+
+	_, err := cm.DeployContract(context.Background(), "ns1", req)
+	assert.Regexp(t, "FF10140.*definition", err)
+}
+
+func TestDeployContractMissingContract(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+
+	req := newTestDeployRequest()
+	req.Contract = nil
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+
+	_, err := cm.DeployContract(context.Background(), "ns1", req)
+	assert.Regexp(t, "FF10140.*contract", err)
+}
+
+func TestDeployContractUnsupported(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
+
+	req := newTestDeployRequest()
+	req.Interface = &fftypes.FFIReference{Name: "simple", Version: "v1"}
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mbi.On("ValidateContractDeploy", mock.Anything, req.Definition, req.Contract).Return(fmt.Errorf("pop"))
+
+	_, err := cm.DeployContract(context.Background(), "ns1", req)
+	assert.EqualError(t, err, "pop")
+
+	mbi.AssertNotCalled(t, "GenerateFFI", mock.Anything, mock.Anything)
+}
+
+func TestDeployContractAPINoInterface(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
+
+	req := newTestDeployRequest()
+	req.API = "simple"
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mbi.On("ValidateContractDeploy", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	_, err := cm.DeployContract(context.Background(), "ns1", req)
+	assert.Regexp(t, "FF10435", err)
+}
+
+func TestDeployContractAPIBadName(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
+
+	req := newTestDeployRequest()
+	req.Interface = &fftypes.FFIReference{Name: "simple", Version: "v1"}
+	req.API = "!bad"
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mbi.On("ValidateContractDeploy", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	_, err := cm.DeployContract(context.Background(), "ns1", req)
+	assert.Regexp(t, "FF10131.*api", err)
+}
+
+func TestDeployContractAPILookupFail(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mdi := cm.database.(*databasemocks.Plugin)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
+
+	req := newTestDeployRequest()
+	req.Interface = &fftypes.FFIReference{Name: "simple", Version: "v1"}
+	req.API = "simple"
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mbi.On("ValidateContractDeploy", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("GetContractAPIByName", mock.Anything, "ns1", "simple").Return(nil, fmt.Errorf("pop"))
+
+	_, err := cm.DeployContract(context.Background(), "ns1", req)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDeployContractAPIExists(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mdi := cm.database.(*databasemocks.Plugin)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
+
+	req := newTestDeployRequest()
+	req.Interface = &fftypes.FFIReference{Name: "simple", Version: "v1"}
+	req.API = "simple"
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mbi.On("ValidateContractDeploy", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("GetContractAPIByName", mock.Anything, "ns1", "simple").Return(&fftypes.ContractAPI{}, nil)
+
+	_, err := cm.DeployContract(context.Background(), "ns1", req)
+	assert.Regexp(t, "FF10436", err)
+}
+
+func TestDeployContractSubmitFail(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mth := cm.txHelper.(*txcommonmocks.Helper)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mbi.On("ValidateContractDeploy", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeContractDeploy).Return(nil, fmt.Errorf("pop"))
+
+	_, err := cm.DeployContract(context.Background(), "ns1", newTestDeployRequest())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDeployContractRunOperationFail(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mdi := cm.database.(*databasemocks.Plugin)
+	mth := cm.txHelper.(*txcommonmocks.Helper)
+	mom := cm.operations.(*operationmocks.Manager)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mbi.On("ValidateContractDeploy", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeContractDeploy).Return(fftypes.NewUUID(), nil)
+	mdi.On("InsertOperation", mock.Anything, mock.Anything).Return(nil)
+	mom.On("RunOperation", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	res, err := cm.DeployContract(context.Background(), "ns1", newTestDeployRequest())
+	assert.EqualError(t, err, "pop")
+	assert.NotNil(t, res.ID)
+}
+
 func TestInvokeContractAPI(t *testing.T) {
 	cm := newTestContractManager()
 	mdb := cm.database.(*databasemocks.Plugin)
@@ -1943,7 +2564,7 @@ func TestValidateFFIParamBadSchemaJSON(t *testing.T) {
 		Name:   "x",
 		Schema: fftypes.JSONAnyPtr(`{"type": "integer"`),
 	}
-	err := cm.validateFFIParam(context.Background(), param)
+	err := cm.validateFFIParam(context.Background(), "ns1", param)
 	assert.Regexp(t, "unexpected EOF", err)
 }
 
@@ -1968,20 +2589,21 @@ func TestCheckParamSchemaCompileFail(t *testing.T) {
 }
 
 func TestAddJSONSchemaExtension(t *testing.T) {
+	mbi := &blockchainmocks.Plugin{}
 	cm := &contractManager{
-		database:          &databasemocks.Plugin{},
-		broadcast:         &broadcastmocks.Manager{},
-		identity:          &identitymanagermocks.Manager{},
-		blockchain:        &blockchainmocks.Plugin{},
-		ffiParamValidator: &MockFFIParamValidator{},
+		database:           &databasemocks.Plugin{},
+		broadcast:          &broadcastmocks.Manager{},
+		identity:           &identitymanagermocks.Manager{},
+		blockchains:        newTestRouter(mbi),
+		ffiParamValidators: map[blockchain.Plugin]fftypes.FFIParamValidator{mbi: &MockFFIParamValidator{}},
 	}
-	c := cm.newFFISchemaCompiler()
+	c := cm.newFFISchemaCompiler("ns1")
 	assert.NotNil(t, c)
 }
 
 func TestGenerateFFI(t *testing.T) {
 	cm := newTestContractManager()
-	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
 	mbi.On("GenerateFFI", mock.Anything, mock.Anything).Return(&fftypes.FFI{
 		Name: "generated",
 	}, nil)
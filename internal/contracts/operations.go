@@ -21,11 +21,18 @@ import (
 	"encoding/json"
 
 	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
 
 type blockchainInvokeData struct {
-	Request *fftypes.ContractCallRequest `json:"request"`
+	Namespace string                       `json:"namespace"`
+	Request   *fftypes.ContractCallRequest `json:"request"`
+}
+
+type blockchainContractDeployData struct {
+	Namespace string                         `json:"namespace"`
+	Request   *fftypes.ContractDeployRequest `json:"request"`
 }
 
 func addBlockchainInvokeInputs(op *fftypes.Operation, req *fftypes.ContractCallRequest) (err error) {
@@ -54,6 +61,13 @@ func (cm *contractManager) PrepareOperation(ctx context.Context, op *fftypes.Ope
 		}
 		return opBlockchainInvoke(op, req), nil
 
+	case fftypes.OpTypeBlockchainContractDeploy:
+		req, err := txcommon.RetrieveContractDeployInputs(ctx, op)
+		if err != nil {
+			return nil, err
+		}
+		return opBlockchainContractDeploy(op, req), nil
+
 	default:
 		return nil, i18n.NewError(ctx, i18n.MsgOperationNotSupported)
 	}
@@ -63,7 +77,11 @@ func (cm *contractManager) RunOperation(ctx context.Context, op *fftypes.Prepare
 	switch data := op.Data.(type) {
 	case blockchainInvokeData:
 		req := data.Request
-		return false, cm.blockchain.InvokeContract(ctx, op.ID, req.Key, req.Location, req.Method, req.Input)
+		return false, cm.blockchains.ForNamespace(data.Namespace).InvokeContract(ctx, op.ID, req.Key, req.Location, req.Method, req.Input)
+
+	case blockchainContractDeployData:
+		req := data.Request
+		return false, cm.blockchains.ForNamespace(data.Namespace).DeployContract(ctx, op.ID, req.Key, req.Definition, req.Contract, req.Input)
 
 	default:
 		return false, i18n.NewError(ctx, i18n.MsgOperationNotSupported)
@@ -74,6 +92,14 @@ func opBlockchainInvoke(op *fftypes.Operation, req *fftypes.ContractCallRequest)
 	return &fftypes.PreparedOperation{
 		ID:   op.ID,
 		Type: op.Type,
-		Data: blockchainInvokeData{Request: req},
+		Data: blockchainInvokeData{Namespace: op.Namespace, Request: req},
+	}
+}
+
+func opBlockchainContractDeploy(op *fftypes.Operation, req *fftypes.ContractDeployRequest) *fftypes.PreparedOperation {
+	return &fftypes.PreparedOperation{
+		ID:   op.ID,
+		Type: op.Type,
+		Data: blockchainContractDeployData{Namespace: op.Namespace, Request: req},
 	}
 }
@@ -18,6 +18,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/mocks/blockchainmocks"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
@@ -44,7 +45,7 @@ func TestPrepareAndRunBlockchainInvoke(t *testing.T) {
 	err := addBlockchainInvokeInputs(op, req)
 	assert.NoError(t, err)
 
-	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
 	mbi.On("InvokeContract", context.Background(), op.ID, "0x123", mock.MatchedBy(func(loc *fftypes.JSONAny) bool {
 		return loc.String() == req.Location.String()
 	}), mock.MatchedBy(func(method *fftypes.FFIMethod) bool {
@@ -63,6 +64,41 @@ func TestPrepareAndRunBlockchainInvoke(t *testing.T) {
 	mbi.AssertExpectations(t)
 }
 
+func TestPrepareAndRunBlockchainContractDeploy(t *testing.T) {
+	cm := newTestContractManager()
+
+	op := &fftypes.Operation{
+		Type: fftypes.OpTypeBlockchainContractDeploy,
+		ID:   fftypes.NewUUID(),
+	}
+	req := &fftypes.ContractDeployRequest{
+		Key:        "0x123",
+		Definition: fftypes.JSONAnyPtr(`[]`),
+		Contract:   fftypes.JSONAnyPtr(`"0x6080"`),
+		Input:      []interface{}{"1"},
+	}
+	err := txcommon.AddContractDeployInputs(op, req)
+	assert.NoError(t, err)
+
+	mbi := cm.blockchains.Default().(*blockchainmocks.Plugin)
+	mbi.On("DeployContract", context.Background(), op.ID, "0x123", mock.MatchedBy(func(def *fftypes.JSONAny) bool {
+		return def.String() == "[]"
+	}), mock.MatchedBy(func(contract *fftypes.JSONAny) bool {
+		return contract.String() == `"0x6080"`
+	}), req.Input).Return(nil)
+
+	po, err := cm.PrepareOperation(context.Background(), op)
+	assert.NoError(t, err)
+	assert.Equal(t, req.Key, po.Data.(blockchainContractDeployData).Request.Key)
+
+	complete, err := cm.RunOperation(context.Background(), po)
+
+	assert.False(t, complete)
+	assert.NoError(t, err)
+
+	mbi.AssertExpectations(t)
+}
+
 func TestPrepareOperationNotSupported(t *testing.T) {
 	cm := newTestContractManager()
 
@@ -84,6 +120,18 @@ func TestPrepareOperationBlockchainInvokeBadInput(t *testing.T) {
 	assert.Regexp(t, "FF10151", err)
 }
 
+func TestPrepareOperationBlockchainContractDeployBadInput(t *testing.T) {
+	cm := newTestContractManager()
+
+	op := &fftypes.Operation{
+		Type:  fftypes.OpTypeBlockchainContractDeploy,
+		Input: fftypes.JSONObject{"input": "bad"},
+	}
+
+	_, err := cm.PrepareOperation(context.Background(), op)
+	assert.Regexp(t, "FF10151", err)
+}
+
 func TestRunOperationNotSupported(t *testing.T) {
 	cm := newTestContractManager()
 
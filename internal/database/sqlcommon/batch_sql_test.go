@@ -162,6 +162,13 @@ func TestBatch2EWithDB(t *testing.T) {
 	assert.Equal(t, 1, len(batches))
 	assert.Equal(t, int64(1), *res.TotalCount)
 
+	// Test delete
+	err = s.DeleteBatch(ctx, batchID)
+	assert.NoError(t, err)
+	batches, _, err = s.GetBatches(ctx, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(batches))
+
 	s.callbacks.AssertExpectations(t)
 }
 
@@ -299,3 +306,20 @@ func TestBatchUpdateFail(t *testing.T) {
 	err := s.UpdateBatch(context.Background(), fftypes.NewUUID(), u)
 	assert.Regexp(t, "FF10117", err)
 }
+
+func TestBatchDeleteBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteBatch(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10114", err)
+}
+
+func TestBatchDeleteFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteBatch(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10118", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -40,6 +40,7 @@ var (
 		"timestamp",
 		"tx_type",
 		"tx_id",
+		"removed",
 	}
 	blockchainEventFilterFieldMap = map[string]string{
 		"protocolid": "protocol_id",
@@ -71,6 +72,7 @@ func (s *SQLCommon) InsertBlockchainEvent(ctx context.Context, event *fftypes.Bl
 				event.Timestamp,
 				event.TX.Type,
 				event.TX.ID,
+				event.Removed,
 			),
 		func() {
 			s.callbacks.OrderedUUIDCollectionNSEvent(database.CollectionBlockchainEvents, fftypes.ChangeEventTypeCreated, event.Namespace, event.ID, event.Sequence)
@@ -96,6 +98,7 @@ func (s *SQLCommon) blockchainEventResult(ctx context.Context, row *sql.Rows) (*
 		&event.Timestamp,
 		&event.TX.Type,
 		&event.TX.ID,
+		&event.Removed,
 		// Must be added to the list of columns in all selects
 		&event.Sequence,
 	)
@@ -164,3 +167,43 @@ func (s *SQLCommon) GetBlockchainEvents(ctx context.Context, filter database.Fil
 
 	return events, s.queryRes(ctx, tx, "blockchainevents", fop, fi), err
 }
+
+func (s *SQLCommon) UpdateBlockchainEvent(ctx context.Context, id *fftypes.UUID, update database.Update) (err error) {
+
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	query, err := s.buildUpdate(sq.Update("blockchainevents"), update, blockchainEventFilterFieldMap)
+	if err != nil {
+		return err
+	}
+	query = query.Where(sq.Eq{"id": id})
+
+	_, err = s.updateTx(ctx, tx, query, nil /* no change events on filter update */)
+	if err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) DeleteBlockchainEvent(ctx context.Context, id *fftypes.UUID) (err error) {
+
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	err = s.deleteTx(ctx, tx, sq.Delete("blockchainevents").Where(sq.Eq{
+		"id": id,
+	}), nil /* no change events when pruning */)
+	if err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
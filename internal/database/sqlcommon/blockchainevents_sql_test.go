@@ -70,6 +70,31 @@ func TestBlockchainEventsE2EWithDB(t *testing.T) {
 	assert.NoError(t, err)
 	eventReadJson, _ = json.Marshal(eventRead)
 	assert.Equal(t, string(eventJson), string(eventReadJson))
+
+	// Mark the event removed
+	removed := fftypes.Now()
+	up := database.BlockchainEventQueryFactory.NewUpdate(ctx).Set("removed", removed)
+	err = s.UpdateBlockchainEvent(ctx, event.ID, up)
+	assert.NoError(t, err)
+
+	// Query back the event (by removed filter)
+	filter = fb.And(
+		fb.Eq("protocolid", "tx1"),
+		fb.Eq("removed", nil),
+	)
+	events, _, err = s.GetBlockchainEvents(ctx, filter)
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+	eventRead, err = s.GetBlockchainEventByID(ctx, event.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, removed.String(), eventRead.Removed.String())
+
+	// Delete the event
+	err = s.DeleteBlockchainEvent(ctx, event.ID)
+	assert.NoError(t, err)
+	eventRead, err = s.GetBlockchainEventByID(ctx, event.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, eventRead)
 }
 
 func TestInsertBlockchainEventFailBegin(t *testing.T) {
@@ -149,3 +174,46 @@ func TestGetBlockchainEventsScanFail(t *testing.T) {
 	assert.Regexp(t, "FF10121", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestUpdateBlockchainEventBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	u := database.BlockchainEventQueryFactory.NewUpdate(context.Background()).Set("removed", fftypes.Now())
+	err := s.UpdateBlockchainEvent(context.Background(), fftypes.NewUUID(), u)
+	assert.Regexp(t, "FF10114", err)
+}
+
+func TestUpdateBlockchainEventBuildQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	u := database.BlockchainEventQueryFactory.NewUpdate(context.Background()).Set("id", map[bool]bool{true: false})
+	err := s.UpdateBlockchainEvent(context.Background(), fftypes.NewUUID(), u)
+	assert.Regexp(t, "FF10149.*id", err)
+}
+
+func TestUpdateBlockchainEventFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	u := database.BlockchainEventQueryFactory.NewUpdate(context.Background()).Set("removed", fftypes.Now())
+	err := s.UpdateBlockchainEvent(context.Background(), fftypes.NewUUID(), u)
+	assert.Regexp(t, "FF10117", err)
+}
+
+func TestDeleteBlockchainEventBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteBlockchainEvent(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10114", err)
+}
+
+func TestDeleteBlockchainEventFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteBlockchainEvent(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10118", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -163,6 +163,13 @@ func TestDataE2EWithDB(t *testing.T) {
 	assert.Equal(t, 1, len(dataRes))
 	assert.Equal(t, int64(1), *res.TotalCount)
 
+	// Test delete
+	err = s.DeleteData(ctx, dataID)
+	assert.NoError(t, err)
+	dataRes, _, err = s.GetData(ctx, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(dataRes))
+
 	s.callbacks.AssertExpectations(t)
 }
 
@@ -380,3 +387,20 @@ func TestDataUpdateFail(t *testing.T) {
 	err := s.UpdateData(context.Background(), fftypes.NewUUID(), u)
 	assert.Regexp(t, "FF10117", err)
 }
+
+func TestDataDeleteBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteData(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10114", err)
+}
+
+func TestDataDeleteFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteData(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10118", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
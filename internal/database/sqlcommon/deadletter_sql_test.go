@@ -0,0 +1,256 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadLettersE2EWithDB(t *testing.T) {
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Create a new dead letter entry
+	deadLetter := &fftypes.DeadLetter{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Subscription: fftypes.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Name:      "sub1",
+		},
+		Event:     fftypes.NewUUID(),
+		EventType: fftypes.EventTypeMessageConfirmed,
+		Reference: fftypes.NewUUID(),
+		Sequence:  12345,
+		Attempts:  5,
+		Info:      "rejected",
+		Created:   fftypes.Now(),
+	}
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionDeadLetters, fftypes.ChangeEventTypeCreated, "ns1", deadLetter.ID).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionDeadLetters, fftypes.ChangeEventTypeDeleted, "ns1", deadLetter.ID).Return()
+	err := s.InsertDeadLetter(ctx, deadLetter)
+	assert.NoError(t, err)
+
+	// Check we get the exact same dead letter back
+	deadLetterRead, err := s.GetDeadLetterByID(ctx, deadLetter.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, deadLetterRead)
+	deadLetterJson, _ := json.Marshal(&deadLetter)
+	deadLetterReadJson, _ := json.Marshal(&deadLetterRead)
+	assert.Equal(t, string(deadLetterJson), string(deadLetterReadJson))
+
+	// Query back the dead letter
+	fb := database.DeadLetterQueryFactory.NewFilter(ctx)
+	filter := fb.And(
+		fb.Eq("id", deadLetter.ID.String()),
+		fb.Eq("subscription", deadLetter.Subscription.ID),
+		fb.Eq("event", deadLetter.Event),
+		fb.Eq("eventtype", fftypes.EventTypeMessageConfirmed),
+		fb.Gt("sequence", 0),
+	)
+	deadLetters, res, err := s.GetDeadLetters(ctx, filter.Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(deadLetters))
+	assert.Equal(t, int64(1), *res.TotalCount)
+	deadLetterReadJson, _ = json.Marshal(deadLetters[0])
+	assert.Equal(t, string(deadLetterJson), string(deadLetterReadJson))
+
+	// Update the dead letter
+	up := database.DeadLetterQueryFactory.NewUpdate(ctx).
+		Set("attempts", 6).
+		Set("info", "rejected again")
+	err = s.UpdateDeadLetter(ctx, deadLetter.ID, up)
+	assert.NoError(t, err)
+	deadLetterRead, err = s.GetDeadLetterByID(ctx, deadLetter.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, deadLetterRead.Attempts)
+	assert.Equal(t, "rejected again", deadLetterRead.Info)
+	assert.NotNil(t, deadLetterRead.Updated)
+
+	// Delete the dead letter
+	err = s.DeleteDeadLetter(ctx, deadLetter.ID)
+	assert.NoError(t, err)
+	deadLetterRead, err = s.GetDeadLetterByID(ctx, deadLetter.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, deadLetterRead)
+
+	// Insert two more, and purge them for the subscription
+	for i := 0; i < 2; i++ {
+		dl := *deadLetter
+		dl.ID = fftypes.NewUUID()
+		dl.Event = fftypes.NewUUID()
+		s.callbacks.On("UUIDCollectionNSEvent", database.CollectionDeadLetters, fftypes.ChangeEventTypeCreated, "ns1", dl.ID).Return()
+		err = s.InsertDeadLetter(ctx, &dl)
+		assert.NoError(t, err)
+	}
+	err = s.DeleteDeadLetters(ctx, deadLetter.Subscription.ID)
+	assert.NoError(t, err)
+	deadLetters, _, err = s.GetDeadLetters(ctx, filter)
+	assert.NoError(t, err)
+	assert.Empty(t, deadLetters)
+
+	// Purge again is a no-op
+	err = s.DeleteDeadLetters(ctx, deadLetter.Subscription.ID)
+	assert.NoError(t, err)
+}
+
+func TestInsertDeadLetterFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertDeadLetter(context.Background(), &fftypes.DeadLetter{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertDeadLetterFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertDeadLetter(context.Background(), &fftypes.DeadLetter{})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertDeadLetterFailCommit(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertDeadLetter(context.Background(), &fftypes.DeadLetter{})
+	assert.Regexp(t, "FF10119", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDeadLetterByIDSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetDeadLetterByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDeadLetterByIDScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, err := s.GetDeadLetterByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDeadLettersQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.DeadLetterQueryFactory.NewFilter(context.Background()).Eq("id", "")
+	_, _, err := s.GetDeadLetters(context.Background(), f)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDeadLettersBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.DeadLetterQueryFactory.NewFilter(context.Background()).Eq("id", map[bool]bool{true: false})
+	_, _, err := s.GetDeadLetters(context.Background(), f)
+	assert.Regexp(t, "FF10149.*id", err)
+}
+
+func TestGetDeadLettersReadMessageFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.DeadLetterQueryFactory.NewFilter(context.Background()).Eq("id", "")
+	_, _, err := s.GetDeadLetters(context.Background(), f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeadLetterUpdateBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	u := database.DeadLetterQueryFactory.NewUpdate(context.Background()).Set("attempts", 1)
+	err := s.UpdateDeadLetter(context.Background(), fftypes.NewUUID(), u)
+	assert.Regexp(t, "FF10114", err)
+}
+
+func TestDeadLetterUpdateBuildQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	u := database.DeadLetterQueryFactory.NewUpdate(context.Background()).Set("attempts", map[bool]bool{true: false})
+	err := s.UpdateDeadLetter(context.Background(), fftypes.NewUUID(), u)
+	assert.Regexp(t, "FF10149.*attempts", err)
+}
+
+func TestDeadLetterUpdateFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	u := database.DeadLetterQueryFactory.NewUpdate(context.Background()).Set("attempts", 1)
+	err := s.UpdateDeadLetter(context.Background(), fftypes.NewUUID(), u)
+	assert.Regexp(t, "FF10117", err)
+}
+
+func TestDeadLetterDeleteBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteDeadLetter(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10114", err)
+}
+
+func TestDeadLetterDeleteSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteDeadLetter(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10115", err)
+}
+
+func TestDeadLetterDeleteFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(deadLetterColumns).AddRow(
+		fftypes.NewUUID(), "ns1", fftypes.NewUUID(), "sub1", fftypes.NewUUID(), "message_confirmed", nil, 1, 1, "", fftypes.Now(), nil),
+	)
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteDeadLetter(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10118", err)
+}
+
+func TestDeadLettersDeleteBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteDeadLetters(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10114", err)
+}
+
+func TestDeadLettersDeleteFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteDeadLetters(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10118", err)
+}
@@ -44,6 +44,11 @@ func (s *SQLCommon) filterSelect(ctx context.Context, tableName string, sel sq.S
 		}
 	}
 	fop, err := s.filterSelectFinalized(ctx, tableName, fi, typeMap, preconditions...)
+	if err == nil && fi.Cursor != nil {
+		var page sq.Sqlizer
+		page, err = s.filterCursorPage(ctx, tableName, sel, fop, fi, typeMap)
+		sel = sel.Where(page)
+	}
 	sel = sel.Where(fop)
 	sort := make([]string, len(fi.Sort))
 	var sortString string
@@ -73,6 +78,67 @@ func (s *SQLCommon) filterSelect(ctx context.Context, tableName string, sel sq.S
 	return sel, fop, fi, err
 }
 
+// filterNoCursor rejects cursor pagination on aggregated queries, where a sequence does not identify a single row
+func (s *SQLCommon) filterNoCursor(ctx context.Context, filter database.Filter, desc string) error {
+	if fi, err := filter.Finalize(); err == nil && fi.Cursor != nil {
+		return i18n.NewError(ctx, i18n.MsgCursorNotSupported, desc)
+	}
+	return nil // any other error is returned by filterSelect
+}
+
+// filterCursorPage resolves the sequences of the items in the requested page up-front, so the
+// main query returns exactly those items regardless of any rows being inserted concurrently.
+func (s *SQLCommon) filterCursorPage(ctx context.Context, tableName string, sel sq.SelectBuilder, fop sq.Sqlizer, fi *database.FilterInfo, typeMap map[string]string) (sq.Sqlizer, error) {
+	seqField := s.mapField(tableName, "sequence", typeMap)
+	descending := fi.Sort[0].Descending
+	page := sel.Column(fmt.Sprintf("%s AS cursor_seq", seqField)).Where(fop)
+
+	// Walk away from the cursor - towards lower sequences for "after" on a descending query,
+	// or "before" on an ascending query, otherwise towards higher sequences.
+	towardsLower := descending == (fi.Cursor.Before == nil)
+	cursor := fi.Cursor.After
+	if cursor == nil {
+		cursor = fi.Cursor.Before
+	}
+	if towardsLower {
+		if cursor != nil {
+			page = page.Where(sq.Lt{seqField: *cursor})
+		}
+		page = page.OrderBy(fmt.Sprintf("%s DESC", seqField))
+	} else {
+		if cursor != nil {
+			page = page.Where(sq.Gt{seqField: *cursor})
+		}
+		page = page.OrderBy(seqField)
+	}
+	if fi.Limit > 0 {
+		page = page.Limit(fi.Limit)
+	}
+
+	rows, _, err := s.query(ctx, sq.Select("page.cursor_seq").FromSelect(page, "page"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	sequences := []int64{}
+	for rows.Next() {
+		var seq int64
+		if err := rows.Scan(&seq); err != nil {
+			return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "page")
+		}
+		sequences = append(sequences, seq)
+		if fi.Cursor.First == nil || (seq > *fi.Cursor.First) == descending {
+			first := seq
+			fi.Cursor.First = &first
+		}
+		if fi.Cursor.Last == nil || (seq < *fi.Cursor.Last) == descending {
+			last := seq
+			fi.Cursor.Last = &last
+		}
+	}
+	return sq.Eq{seqField: sequences}, nil
+}
+
 func (s *SQLCommon) filterSelectFinalized(ctx context.Context, tableName string, fi *database.FilterInfo, tm map[string]string, preconditions ...sq.Sqlizer) (sq.Sqlizer, error) {
 	fop, err := s.filterOp(ctx, tableName, fi, tm)
 	if err != nil {
@@ -19,8 +19,10 @@ package sqlcommon
 import (
 	"context"
 	"database/sql/driver"
+	"fmt"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/Masterminds/squirrel"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -225,3 +227,88 @@ func TestNotILIKE(t *testing.T) {
 	sqlString, _, _ = q.ToSql()
 	assert.Regexp(t, "lower\\(test\\)", sqlString)
 }
+
+func TestSQLQueryFactoryCursorAfter(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT page.cursor_seq FROM \\(SELECT \\*, seq AS cursor_seq FROM mytable WHERE \\(namespace = \\$1\\) AND seq < \\$2 ORDER BY seq DESC LIMIT 2\\) AS page").
+		WillReturnRows(sqlmock.NewRows([]string{"cursor_seq"}).AddRow(9).AddRow(10))
+	sel := squirrel.Select("*").From("mytable")
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	f := fb.And(fb.Eq("namespace", "ns1")).Limit(2).After(database.EncodeCursor(11))
+	sel, _, fi, err := s.filterSelect(context.Background(), "", sel, f, nil, []interface{}{"sequence"})
+	assert.NoError(t, err)
+
+	sqlFilter, args, err := sel.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM mytable WHERE seq IN (?,?) AND (namespace = ?) ORDER BY seq DESC LIMIT 2", sqlFilter)
+	assert.Equal(t, []interface{}{int64(9), int64(10), "ns1"}, args)
+	assert.Equal(t, int64(10), *fi.Cursor.First)
+	assert.Equal(t, int64(9), *fi.Cursor.Last)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLQueryFactoryCursorBeforeAscending(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT page.cursor_seq FROM \\(SELECT \\*, seq AS cursor_seq FROM mytable WHERE \\(namespace = \\$1\\) AND seq < \\$2 ORDER BY seq DESC\\) AS page").
+		WillReturnRows(sqlmock.NewRows([]string{"cursor_seq"}).AddRow(8).AddRow(7))
+	sel := squirrel.Select("*").From("mytable")
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	f := fb.And(fb.Eq("namespace", "ns1")).Before(database.EncodeCursor(9)).Ascending()
+	sel, _, fi, err := s.filterSelect(context.Background(), "", sel, f, nil, []interface{}{"sequence"})
+	assert.NoError(t, err)
+
+	sqlFilter, _, err := sel.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM mytable WHERE seq IN (?,?) AND (namespace = ?) ORDER BY seq", sqlFilter)
+	assert.Equal(t, int64(7), *fi.Cursor.First)
+	assert.Equal(t, int64(8), *fi.Cursor.Last)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLQueryFactoryCursorFirstPageEmpty(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT page.cursor_seq FROM \\(SELECT \\*, seq AS cursor_seq FROM mytable WHERE \\(namespace = \\$1\\) ORDER BY seq\\) AS page").
+		WillReturnRows(sqlmock.NewRows([]string{"cursor_seq"}))
+	sel := squirrel.Select("*").From("mytable")
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	f := fb.And(fb.Eq("namespace", "ns1")).After("").Ascending()
+	sel, _, fi, err := s.filterSelect(context.Background(), "", sel, f, nil, []interface{}{"sequence"})
+	assert.NoError(t, err)
+
+	sqlFilter, _, err := sel.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM mytable WHERE (1=0) AND (namespace = ?) ORDER BY seq", sqlFilter)
+	assert.Nil(t, fi.Cursor.First)
+	assert.Nil(t, fi.Cursor.Last)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLQueryFactoryCursorQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	sel := squirrel.Select("*").From("mytable")
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	_, _, _, err := s.filterSelect(context.Background(), "", sel, fb.And().After(""), nil, []interface{}{"sequence"})
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLQueryFactoryCursorScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"cursor_seq"}).AddRow("!number"))
+	sel := squirrel.Select("*").From("mytable")
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	_, _, _, err := s.filterSelect(context.Background(), "", sel, fb.And().After(""), nil, []interface{}{"sequence"})
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLQueryFactoryNoCursor(t *testing.T) {
+	s, _ := newMockProvider().init()
+	fb := database.TokenBalanceQueryFactory.NewFilter(context.Background())
+	err := s.filterNoCursor(context.Background(), fb.And().After(""), "things")
+	assert.Regexp(t, "FF10395.*things", err)
+	fb = database.TokenBalanceQueryFactory.NewFilter(context.Background())
+	err = s.filterNoCursor(context.Background(), fb.And(), "things")
+	assert.NoError(t, err)
+}
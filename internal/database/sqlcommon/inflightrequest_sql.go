@@ -0,0 +1,183 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	inflightRequestColumns = []string{
+		"id",
+		"namespace",
+		"rtype",
+		"created",
+		"resolved",
+		"reply",
+		"failed",
+	}
+	inflightRequestFilterFieldMap = map[string]string{
+		"type": "rtype",
+	}
+)
+
+func (s *SQLCommon) InsertInflightRequest(ctx context.Context, req *fftypes.InflightRequest) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	if _, err = s.insertTx(ctx, tx,
+		sq.Insert("inflightrequests").
+			Columns(inflightRequestColumns...).
+			Values(
+				req.ID,
+				req.Namespace,
+				req.Type,
+				req.Created,
+				req.Resolved,
+				req.Reply,
+				req.Failed,
+			),
+		func() {
+			s.callbacks.UUIDCollectionNSEvent(database.CollectionInflightRequests, fftypes.ChangeEventTypeCreated, req.Namespace, req.ID)
+		},
+	); err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) ResolveInflightRequest(ctx context.Context, ns string, id, reply *fftypes.UUID, failed bool) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	if _, err = s.updateTx(ctx, tx,
+		sq.Update("inflightrequests").
+			Set("resolved", fftypes.Now()).
+			Set("reply", reply).
+			Set("failed", failed).
+			Where(sq.Eq{
+				"id":       id,
+				"resolved": nil,
+			}),
+		func() {
+			s.callbacks.UUIDCollectionNSEvent(database.CollectionInflightRequests, fftypes.ChangeEventTypeUpdated, ns, id)
+		},
+	); err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) inflightRequestResult(ctx context.Context, row *sql.Rows) (*fftypes.InflightRequest, error) {
+	var req fftypes.InflightRequest
+	err := row.Scan(
+		&req.ID,
+		&req.Namespace,
+		&req.Type,
+		&req.Created,
+		&req.Resolved,
+		&req.Reply,
+		&req.Failed,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "inflightrequests")
+	}
+	return &req, nil
+}
+
+func (s *SQLCommon) GetInflightRequestByID(ctx context.Context, id *fftypes.UUID) (req *fftypes.InflightRequest, err error) {
+
+	rows, _, err := s.query(ctx,
+		sq.Select(inflightRequestColumns...).
+			From("inflightrequests").
+			Where(sq.Eq{"id": id}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("Inflight request '%s' not found", id)
+		return nil, nil
+	}
+
+	return s.inflightRequestResult(ctx, rows)
+}
+
+func (s *SQLCommon) GetInflightRequests(ctx context.Context, filter database.Filter) (reqs []*fftypes.InflightRequest, fr *database.FilterResult, err error) {
+
+	query, fop, fi, err := s.filterSelect(ctx, "", sq.Select(inflightRequestColumns...).From("inflightrequests"), filter, inflightRequestFilterFieldMap, []interface{}{"sequence"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.query(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	reqs = []*fftypes.InflightRequest{}
+	for rows.Next() {
+		req, err := s.inflightRequestResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		reqs = append(reqs, req)
+	}
+
+	return reqs, s.queryRes(ctx, tx, "inflightrequests", fop, fi), err
+}
+
+func (s *SQLCommon) DeleteInflightRequest(ctx context.Context, id *fftypes.UUID) (err error) {
+
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	req, err := s.GetInflightRequestByID(ctx, id)
+	if err == nil && req != nil {
+		err = s.deleteTx(ctx, tx, sq.Delete("inflightrequests").Where(sq.Eq{"id": id}),
+			func() {
+				s.callbacks.UUIDCollectionNSEvent(database.CollectionInflightRequests, fftypes.ChangeEventTypeDeleted, req.Namespace, req.ID)
+			},
+		)
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
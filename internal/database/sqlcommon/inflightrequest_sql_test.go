@@ -0,0 +1,210 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInflightRequestsE2EWithDB(t *testing.T) {
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Create a new inflight request
+	req := &fftypes.InflightRequest{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Type:      fftypes.InflightRequestTypeMessageReply,
+		Created:   fftypes.Now(),
+	}
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionInflightRequests, fftypes.ChangeEventTypeCreated, "ns1", req.ID).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionInflightRequests, fftypes.ChangeEventTypeUpdated, "ns1", req.ID).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionInflightRequests, fftypes.ChangeEventTypeDeleted, "ns1", req.ID).Return()
+	err := s.InsertInflightRequest(ctx, req)
+	assert.NoError(t, err)
+
+	// Check we get the exact same request back
+	reqRead, err := s.GetInflightRequestByID(ctx, req.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, reqRead)
+	reqJson, _ := json.Marshal(&req)
+	reqReadJson, _ := json.Marshal(&reqRead)
+	assert.Equal(t, string(reqJson), string(reqReadJson))
+
+	// Query back the request
+	fb := database.InflightRequestQueryFactory.NewFilter(ctx)
+	filter := fb.And(
+		fb.Eq("id", req.ID.String()),
+		fb.Eq("namespace", "ns1"),
+		fb.Eq("type", fftypes.InflightRequestTypeMessageReply),
+		fb.Eq("failed", false),
+	)
+	reqs, res, err := s.GetInflightRequests(ctx, filter.Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(reqs))
+	assert.Equal(t, int64(1), *res.TotalCount)
+	reqReadJson, _ = json.Marshal(reqs[0])
+	assert.Equal(t, string(reqJson), string(reqReadJson))
+
+	// Resolve the request
+	replyID := fftypes.NewUUID()
+	err = s.ResolveInflightRequest(ctx, "ns1", req.ID, replyID, true)
+	assert.NoError(t, err)
+	reqRead, err = s.GetInflightRequestByID(ctx, req.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, reqRead.Resolved)
+	assert.Equal(t, *replyID, *reqRead.Reply)
+	assert.True(t, reqRead.Failed)
+
+	// A second resolution does not overwrite the first
+	err = s.ResolveInflightRequest(ctx, "ns1", req.ID, fftypes.NewUUID(), false)
+	assert.NoError(t, err)
+	reqRead, err = s.GetInflightRequestByID(ctx, req.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, *replyID, *reqRead.Reply)
+	assert.True(t, reqRead.Failed)
+
+	// Delete the request
+	err = s.DeleteInflightRequest(ctx, req.ID)
+	assert.NoError(t, err)
+	reqRead, err = s.GetInflightRequestByID(ctx, req.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, reqRead)
+}
+
+func TestInsertInflightRequestFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertInflightRequest(context.Background(), &fftypes.InflightRequest{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertInflightRequestFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertInflightRequest(context.Background(), &fftypes.InflightRequest{})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertInflightRequestFailCommit(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertInflightRequest(context.Background(), &fftypes.InflightRequest{})
+	assert.Regexp(t, "FF10119", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResolveInflightRequestFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.ResolveInflightRequest(context.Background(), "ns1", fftypes.NewUUID(), fftypes.NewUUID(), false)
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResolveInflightRequestFailUpdate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.ResolveInflightRequest(context.Background(), "ns1", fftypes.NewUUID(), fftypes.NewUUID(), false)
+	assert.Regexp(t, "FF10117", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetInflightRequestByIDSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetInflightRequestByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetInflightRequestByIDScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, err := s.GetInflightRequestByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetInflightRequestsQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.InflightRequestQueryFactory.NewFilter(context.Background()).Eq("id", "")
+	_, _, err := s.GetInflightRequests(context.Background(), f)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetInflightRequestsBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.InflightRequestQueryFactory.NewFilter(context.Background()).Eq("id", map[bool]bool{true: false})
+	_, _, err := s.GetInflightRequests(context.Background(), f)
+	assert.Regexp(t, "FF10149.*id", err)
+}
+
+func TestGetInflightRequestsReadFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.InflightRequestQueryFactory.NewFilter(context.Background()).Eq("id", "")
+	_, _, err := s.GetInflightRequests(context.Background(), f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInflightRequestDeleteBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteInflightRequest(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10114", err)
+}
+
+func TestInflightRequestDeleteSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteInflightRequest(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10115", err)
+}
+
+func TestInflightRequestDeleteFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(inflightRequestColumns).AddRow(
+		fftypes.NewUUID(), "ns1", "message_reply", fftypes.Now(), nil, nil, false),
+	)
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteInflightRequest(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10118", err)
+}
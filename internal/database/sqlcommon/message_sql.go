@@ -518,13 +518,11 @@ func (s *SQLCommon) GetMessagesForData(ctx context.Context, dataID *fftypes.UUID
 		cols[i] = fmt.Sprintf("m.%s", col)
 	}
 	cols[len(msgColumns)] = "m.seq"
-	query, fop, fi, err := s.filterSelect(ctx, "m", sq.Select(cols...).From("messages_data AS md"), filter, msgFilterFieldMap, []interface{}{"sequence"},
-		sq.Eq{"md.data_id": dataID})
+	query, fop, fi, err := s.filterSelect(ctx, "m", sq.Select(cols...).From("messages_data AS md").LeftJoin("messages AS m ON m.id = md.message_id"),
+		filter, msgFilterFieldMap, []interface{}{"sequence"}, sq.Eq{"md.data_id": dataID})
 	if err != nil {
 		return nil, nil, err
 	}
-
-	query = query.LeftJoin("messages AS m ON m.id = md.message_id")
 	return s.getMessagesQuery(ctx, query, fop, fi, false)
 }
 
@@ -557,3 +555,28 @@ func (s *SQLCommon) UpdateMessages(ctx context.Context, filter database.Filter,
 
 	return s.commitTx(ctx, tx, autoCommit)
 }
+
+func (s *SQLCommon) DeleteMessage(ctx context.Context, msgID *fftypes.UUID) (err error) {
+
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	err = s.deleteTx(ctx, tx, sq.Delete("messages_data").Where(sq.Eq{
+		"message_id": msgID,
+	}), nil /* no change event */)
+	if err != nil && err != database.DeleteRecordNotFound {
+		return err
+	}
+
+	err = s.deleteTx(ctx, tx, sq.Delete("messages").Where(sq.Eq{
+		"id": msgID,
+	}), nil /* no change events when pruning */)
+	if err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
@@ -211,9 +211,98 @@ func TestUpsertE2EWithDB(t *testing.T) {
 	msgReadJson, _ = json.Marshal(msgRead)
 	assert.Equal(t, string(msgJson), string(msgReadJson))
 
+	// Test delete, including the data references
+	err = s.DeleteMessage(ctx, msgUpdated.Header.ID)
+	assert.NoError(t, err)
+	msgRead, err = s.GetMessageByID(ctx, msgUpdated.Header.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, msgRead)
+	msgs, _, err = s.GetMessagesForData(ctx, dataID2, filter)
+	assert.NoError(t, err)
+	assert.Empty(t, msgs)
+	err = s.DeleteMessage(ctx, msgUpdated.Header.ID)
+	assert.Equal(t, database.DeleteRecordNotFound, err)
+
 	s.callbacks.AssertExpectations(t)
 }
 
+func TestGetMessagesCursorE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionMessages, fftypes.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).Return()
+
+	dataID := fftypes.NewUUID()
+	msgs := make([]*fftypes.Message, 5)
+	for i := 0; i < len(msgs); i++ {
+		msgs[i] = &fftypes.Message{
+			Header: fftypes.MessageHeader{
+				ID:        fftypes.NewUUID(),
+				Namespace: "ns1",
+				Created:   fftypes.Now(),
+				DataHash:  fftypes.NewRandB32(),
+			},
+			Hash: fftypes.NewRandB32(),
+			Data: fftypes.DataRefs{{ID: dataID, Hash: fftypes.NewRandB32()}},
+		}
+		err := s.UpsertMessage(ctx, msgs[i], database.UpsertOptimizationNew)
+		assert.NoError(t, err)
+	}
+
+	// Walk forwards through the messages, newest first
+	fb := database.MessageQueryFactory.NewFilter(ctx)
+	page1, res, err := s.GetMessages(ctx, fb.Eq("namespace", "ns1").Limit(2).After(""))
+	assert.NoError(t, err)
+	assert.Len(t, page1, 2)
+	assert.Equal(t, msgs[4].Header.ID, page1[0].Header.ID)
+	assert.Equal(t, msgs[3].Header.ID, page1[1].Header.ID)
+
+	fb = database.MessageQueryFactory.NewFilter(ctx)
+	page2, res, err := s.GetMessages(ctx, fb.Eq("namespace", "ns1").Limit(2).After(res.Cursors.Next))
+	assert.NoError(t, err)
+	assert.Len(t, page2, 2)
+	assert.Equal(t, msgs[2].Header.ID, page2[0].Header.ID)
+	assert.Equal(t, msgs[1].Header.ID, page2[1].Header.ID)
+
+	// Inserting a new message does not shift the pages
+	newMsg := &fftypes.Message{
+		Header: fftypes.MessageHeader{ID: fftypes.NewUUID(), Namespace: "ns1", Created: fftypes.Now(), DataHash: fftypes.NewRandB32()},
+		Hash:   fftypes.NewRandB32(),
+	}
+	err = s.UpsertMessage(ctx, newMsg, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+
+	fb = database.MessageQueryFactory.NewFilter(ctx)
+	page3, res3, err := s.GetMessages(ctx, fb.Eq("namespace", "ns1").Limit(2).After(res.Cursors.Next))
+	assert.NoError(t, err)
+	assert.Len(t, page3, 1)
+	assert.Equal(t, msgs[0].Header.ID, page3[0].Header.ID)
+
+	fb = database.MessageQueryFactory.NewFilter(ctx)
+	page4, res4, err := s.GetMessages(ctx, fb.Eq("namespace", "ns1").Limit(2).After(res3.Cursors.Next))
+	assert.NoError(t, err)
+	assert.Empty(t, page4)
+	assert.Equal(t, res3.Cursors.Next, res4.Cursors.Next)
+
+	// Walk back again
+	fb = database.MessageQueryFactory.NewFilter(ctx)
+	prev, _, err := s.GetMessages(ctx, fb.Eq("namespace", "ns1").Limit(2).Before(res.Cursors.Previous))
+	assert.NoError(t, err)
+	assert.Len(t, prev, 2)
+	assert.Equal(t, msgs[4].Header.ID, prev[0].Header.ID)
+	assert.Equal(t, msgs[3].Header.ID, prev[1].Header.ID)
+
+	// Also works through the data join
+	fb = database.MessageQueryFactory.NewFilter(ctx)
+	forData, res, err := s.GetMessagesForData(ctx, dataID, fb.And().Limit(3).After("").Ascending())
+	assert.NoError(t, err)
+	assert.Len(t, forData, 3)
+	assert.Equal(t, msgs[0].Header.ID, forData[0].Header.ID)
+	assert.Equal(t, msgs[2].Header.ID, forData[2].Header.ID)
+	assert.NotEmpty(t, res.Cursors.Next)
+}
+
 func TestUpsertMessageFailBegin(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
@@ -643,3 +732,31 @@ func TestMessageUpdateFail(t *testing.T) {
 	err := s.UpdateMessage(context.Background(), fftypes.NewUUID(), u)
 	assert.Regexp(t, "FF10117", err)
 }
+
+func TestMessageDeleteBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteMessage(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10114", err)
+}
+
+func TestMessageDeleteFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteMessage(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10118", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMessageDeleteDataRefsFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnResult(driver.RowsAffected(1))
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteMessage(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10118", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
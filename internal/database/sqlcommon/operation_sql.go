@@ -41,12 +41,14 @@ var (
 		"input",
 		"output",
 		"retry_id",
+		"retry_after",
 	}
 	opFilterFieldMap = map[string]string{
-		"tx":     "tx_id",
-		"type":   "optype",
-		"status": "opstatus",
-		"retry":  "retry_id",
+		"tx":         "tx_id",
+		"type":       "optype",
+		"status":     "opstatus",
+		"retry":      "retry_id",
+		"retryafter": "retry_after",
 	}
 )
 
@@ -73,6 +75,7 @@ func (s *SQLCommon) InsertOperation(ctx context.Context, operation *fftypes.Oper
 				operation.Input,
 				operation.Output,
 				operation.Retry,
+				operation.RetryAfter,
 			),
 		func() {
 			s.callbacks.UUIDCollectionNSEvent(database.CollectionOperations, fftypes.ChangeEventTypeCreated, operation.Namespace, operation.ID)
@@ -99,6 +102,7 @@ func (s *SQLCommon) opResult(ctx context.Context, row *sql.Rows) (*fftypes.Opera
 		&op.Input,
 		&op.Output,
 		&op.Retry,
+		&op.RetryAfter,
 	)
 	if err != nil {
 		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "operations")
@@ -188,3 +192,21 @@ func (s *SQLCommon) ResolveOperation(ctx context.Context, id *fftypes.UUID, stat
 	}
 	return s.UpdateOperation(ctx, id, update)
 }
+
+func (s *SQLCommon) DeleteOperation(ctx context.Context, opID *fftypes.UUID) (err error) {
+
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	err = s.deleteTx(ctx, tx, sq.Delete("operations").Where(sq.Eq{
+		"id": opID,
+	}), nil /* no change events when pruning */)
+	if err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
@@ -48,6 +48,7 @@ func TestOperationE2EWithDB(t *testing.T) {
 		Output:      fftypes.JSONObject{"some": "output-info"},
 		Created:     fftypes.Now(),
 		Updated:     fftypes.Now(),
+		RetryAfter:  fftypes.Now(),
 	}
 	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionOperations, fftypes.ChangeEventTypeCreated, "ns1", operationID).Return()
 	err := s.InsertOperation(ctx, operation)
@@ -71,6 +72,7 @@ func TestOperationE2EWithDB(t *testing.T) {
 		fb.Eq("plugin", operation.Plugin),
 		fb.Gt("created", 0),
 		fb.Gt("updated", 0),
+		fb.Lte("retryafter", fftypes.Now()),
 	)
 	operations, res, err := s.GetOperations(ctx, filter.Count(true))
 	assert.NoError(t, err)
@@ -103,6 +105,21 @@ func TestOperationE2EWithDB(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(operations))
 
+	// Clear the retry time
+	up := database.OperationQueryFactory.NewUpdate(ctx).Set("retryafter", nil)
+	err = s.UpdateOperation(ctx, operation.ID, up)
+	assert.NoError(t, err)
+	operationRead, err = s.GetOperationByID(ctx, operationID)
+	assert.NoError(t, err)
+	assert.Nil(t, operationRead.RetryAfter)
+
+	// Test delete
+	err = s.DeleteOperation(ctx, operation.ID)
+	assert.NoError(t, err)
+	operations, _, err = s.GetOperations(ctx, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(operations))
+
 	s.callbacks.AssertExpectations(t)
 }
 
@@ -214,3 +231,20 @@ func TestOperationUpdateFail(t *testing.T) {
 	err := s.UpdateOperation(context.Background(), fftypes.NewUUID(), u)
 	assert.Regexp(t, "FF10117", err)
 }
+
+func TestOperationDeleteBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteOperation(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10114", err)
+}
+
+func TestOperationDeleteFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteOperation(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10118", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
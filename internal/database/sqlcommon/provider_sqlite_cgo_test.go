@@ -0,0 +1,40 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build cgo
+// +build cgo
+
+package sqlcommon
+
+import (
+	"database/sql"
+
+	migratedb "github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+
+	// Import SQLite driver
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// With CGO the test suite runs against the SQLite C library, as used by the sqlite3 plugin
+
+func (tp *sqliteGoTestProvider) Open(url string) (*sql.DB, error) {
+	return sql.Open("sqlite3", url)
+}
+
+func (tp *sqliteGoTestProvider) GetMigrationDriver(db *sql.DB) (migratedb.Driver, error) {
+	return sqlite3.WithInstance(db, &sqlite3.Config{})
+}
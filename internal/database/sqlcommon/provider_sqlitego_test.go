@@ -18,21 +18,15 @@ package sqlcommon
 
 import (
 	"context"
-	"database/sql"
 	"io/ioutil"
 	"os"
 	"testing"
 
 	sq "github.com/Masterminds/squirrel"
-	migratedb "github.com/golang-migrate/migrate/v4/database"
-	"github.com/golang-migrate/migrate/v4/database/sqlite3"
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/stretchr/testify/assert"
-
-	// Import SQLite driver
-	_ "github.com/mattn/go-sqlite3"
 )
 
 // sqliteGoTestProvider uses QL in-memory database
@@ -89,11 +83,3 @@ func (tp *sqliteGoTestProvider) ApplyInsertQueryCustomizations(insert sq.InsertB
 	// Nothing required - QL supports the query for returning the generated ID, and we use that for the sequence
 	return insert, false
 }
-
-func (tp *sqliteGoTestProvider) Open(url string) (*sql.DB, error) {
-	return sql.Open("sqlite3", url)
-}
-
-func (tp *sqliteGoTestProvider) GetMigrationDriver(db *sql.DB) (migratedb.Driver, error) {
-	return sqlite3.WithInstance(db, &sqlite3.Config{})
-}
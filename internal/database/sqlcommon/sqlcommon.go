@@ -111,6 +111,13 @@ func (s *SQLCommon) Init(ctx context.Context, provider Provider, prefix config.P
 
 func (s *SQLCommon) Capabilities() *database.Capabilities { return s.capabilities }
 
+func (s *SQLCommon) HealthCheck(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return i18n.WrapError(ctx, err, i18n.MsgDBHealthCheckFailed)
+	}
+	return nil
+}
+
 func (s *SQLCommon) RunAsGroup(ctx context.Context, fn func(ctx context.Context) error) error {
 	if tx := getTXFromContext(ctx); tx != nil {
 		// transaction already exists - just continue using it
@@ -262,6 +269,9 @@ func (s *SQLCommon) queryRes(ctx context.Context, tx *txWrapper, tableName strin
 		}
 		fr.TotalCount = &count // could be -1 if the count extract fails - we still return the result
 	}
+	if fi.Cursor != nil {
+		fr.Cursors = fi.Cursor.Result()
+	}
 	return fr
 }
 
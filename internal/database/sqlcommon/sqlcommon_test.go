@@ -36,6 +36,21 @@ func TestInitSQLCommon(t *testing.T) {
 	assert.NotNil(t, s.DB())
 }
 
+func TestHealthCheck(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	err := s.HealthCheck(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestHealthCheckFail(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	s.db.Close()
+	err := s.HealthCheck(context.Background())
+	assert.Regexp(t, "FF10421", err)
+}
+
 func TestInitSQLCommonMissingOptions(t *testing.T) {
 	s := &SQLCommon{}
 	err := s.Init(context.Background(), nil, nil, nil, nil)
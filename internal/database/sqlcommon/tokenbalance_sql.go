@@ -197,6 +197,9 @@ func (s *SQLCommon) GetTokenBalances(ctx context.Context, filter database.Filter
 }
 
 func (s *SQLCommon) GetTokenAccounts(ctx context.Context, filter database.Filter) ([]*fftypes.TokenAccount, *database.FilterResult, error) {
+	if err := s.filterNoCursor(ctx, filter, "token accounts"); err != nil {
+		return nil, nil, err
+	}
 	query, fop, fi, err := s.filterSelect(ctx, "",
 		sq.Select("key", "MAX(updated) AS updated", "MAX(seq) AS seq").From("tokenbalance").GroupBy("key"),
 		filter, tokenBalanceFilterFieldMap, []interface{}{"seq"})
@@ -225,6 +228,9 @@ func (s *SQLCommon) GetTokenAccounts(ctx context.Context, filter database.Filter
 }
 
 func (s *SQLCommon) GetTokenAccountPools(ctx context.Context, key string, filter database.Filter) ([]*fftypes.TokenAccountPool, *database.FilterResult, error) {
+	if err := s.filterNoCursor(ctx, filter, "token account pools"); err != nil {
+		return nil, nil, err
+	}
 	query, fop, fi, err := s.filterSelect(ctx, "",
 		sq.Select("pool_id", "MAX(updated) AS updated", "MAX(seq) AS seq").From("tokenbalance").GroupBy("pool_id"),
 		filter, tokenBalanceFilterFieldMap, []interface{}{"seq"},
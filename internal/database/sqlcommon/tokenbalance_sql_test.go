@@ -251,6 +251,13 @@ func TestGetTokenAccountsBuildQueryFail(t *testing.T) {
 	assert.Regexp(t, "FF10149.*pool", err)
 }
 
+func TestGetTokenAccountsCursorFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.TokenBalanceQueryFactory.NewFilter(context.Background()).And().After("")
+	_, _, err := s.GetTokenAccounts(context.Background(), f)
+	assert.Regexp(t, "FF10395", err)
+}
+
 func TestGetTokenAccountsScanFail(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"key", "bad"}).AddRow("too many", "columns"))
@@ -276,6 +283,13 @@ func TestGetTokenAccountPoolsBuildQueryFail(t *testing.T) {
 	assert.Regexp(t, "FF10149.*pool", err)
 }
 
+func TestGetTokenAccountPoolsCursorFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.TokenBalanceQueryFactory.NewFilter(context.Background()).And().After("")
+	_, _, err := s.GetTokenAccountPools(context.Background(), "0x1", f)
+	assert.Regexp(t, "FF10395", err)
+}
+
 func TestGetTokenAccountPoolsScanFail(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"key", "bad"}).AddRow("too many", "columns"))
@@ -0,0 +1,94 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlitego
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync"
+
+	sq "github.com/Masterminds/squirrel"
+	migratedb "github.com/golang-migrate/migrate/v4/database"
+	migratesqlite "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/database/sqlcommon"
+	"github.com/hyperledger/firefly/pkg/database"
+
+	// Pure Go translation of the SQLite C library, which does not require CGO
+	"modernc.org/sqlite"
+)
+
+var registerOnce sync.Once
+
+// SQLiteGo is the same SQLite database as the sqlite3 plugin, but using a pure Go driver so
+// it is available in builds with CGO disabled. It uses the same migrations as the sqlite3 plugin.
+type SQLiteGo struct {
+	sqlcommon.SQLCommon
+}
+
+// ffDriver wraps the driver, to configure each connection as it is opened
+type ffDriver struct {
+	sqlite.Driver
+}
+
+func (d *ffDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = conn.(driver.Execer).Exec("PRAGMA case_sensitive_like=ON;", nil); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (sqlite *SQLiteGo) Init(ctx context.Context, prefix config.Prefix, callbacks database.Callbacks) error {
+	capabilities := &database.Capabilities{}
+	registerOnce.Do(func() {
+		sql.Register("sqlitego_ff", &ffDriver{})
+	})
+	return sqlite.SQLCommon.Init(ctx, sqlite, prefix, callbacks, capabilities)
+}
+
+func (sqlite *SQLiteGo) Name() string {
+	return "sqlitego"
+}
+
+func (sqlite *SQLiteGo) MigrationsDir() string {
+	return "sqlite"
+}
+
+func (sqlite *SQLiteGo) Features() sqlcommon.SQLFeatures {
+	features := sqlcommon.DefaultSQLProviderFeatures()
+	features.PlaceholderFormat = sq.Dollar
+	features.UseILIKE = false // Not supported
+	return features
+}
+
+func (sqlite *SQLiteGo) ApplyInsertQueryCustomizations(insert sq.InsertBuilder, requestConflictEmptyResult bool) (sq.InsertBuilder, bool) {
+	return insert, false
+}
+
+func (sqlite *SQLiteGo) Open(url string) (*sql.DB, error) {
+	return sql.Open("sqlitego_ff", url)
+}
+
+func (sqlite *SQLiteGo) GetMigrationDriver(db *sql.DB) (migratedb.Driver, error) {
+	return migratesqlite.WithInstance(db, &migratesqlite.Config{})
+}
@@ -0,0 +1,94 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlitego
+
+import (
+	"context"
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/database/sqlcommon"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSQLiteGoProvider(t *testing.T) {
+	sqlite := &SQLiteGo{}
+	dcb := &databasemocks.Callbacks{}
+	prefix := config.NewPluginConfig("unittest")
+	sqlite.InitPrefix(prefix)
+	prefix.Set(sqlcommon.SQLConfDatasourceURL, "!wrong://")
+	err := sqlite.Init(context.Background(), prefix, dcb)
+	assert.NoError(t, err)
+	_, err = sqlite.GetMigrationDriver(sqlite.DB())
+	assert.Error(t, err)
+
+	db, err := sqlite.Open("file::memory:")
+	assert.NoError(t, err)
+	conn, err := db.Conn(context.Background())
+	assert.NoError(t, err)
+	var caseSensitive bool
+	err = conn.QueryRowContext(context.Background(), "SELECT 'A' LIKE 'a'").Scan(&caseSensitive)
+	assert.NoError(t, err)
+	assert.False(t, caseSensitive)
+	conn.Close()
+
+	assert.Equal(t, "sqlitego", sqlite.Name())
+	assert.Equal(t, "sqlite", sqlite.MigrationsDir())
+	assert.Equal(t, sq.Dollar, sqlite.Features().PlaceholderFormat)
+
+	insert := sq.Insert("test").Columns("col1").Values("val1")
+	insert, query := sqlite.ApplyInsertQueryCustomizations(insert, false)
+	sql, _, err := insert.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO test (col1) VALUES (?)", sql)
+	assert.False(t, query)
+}
+
+func TestSQLiteGoOpenFail(t *testing.T) {
+	sqlite := &SQLiteGo{}
+	db, err := sqlite.Open("file:/not/a/real/dir/firefly.db?mode=ro")
+	assert.NoError(t, err)
+	_, err = db.Conn(context.Background())
+	assert.Error(t, err)
+}
+
+func TestSQLiteGoMigrations(t *testing.T) {
+	sqlite := &SQLiteGo{}
+	dcb := &databasemocks.Callbacks{}
+	prefix := config.NewPluginConfig("unittest.migrations")
+	sqlite.InitPrefix(prefix)
+	prefix.Set(sqlcommon.SQLConfDatasourceURL, "file::memory:")
+	prefix.Set(sqlcommon.SQLConfMigrationsAuto, true)
+	prefix.Set(sqlcommon.SQLConfMigrationsDirectory, "../../../db/migrations/sqlite")
+	err := sqlite.Init(context.Background(), prefix, dcb)
+	assert.NoError(t, err)
+	defer sqlite.Close()
+
+	// The migrated schema works with the common SQL layer
+	dcb.On("UUIDCollectionEvent", database.CollectionNamespaces, fftypes.ChangeEventTypeCreated, mock.Anything).Return()
+	ns := &fftypes.Namespace{ID: fftypes.NewUUID(), Name: "ns1", Type: fftypes.NamespaceTypeLocal, Created: fftypes.Now()}
+	err = sqlite.UpsertNamespace(context.Background(), ns, false)
+	assert.NoError(t, err)
+	nsRead, err := sqlite.GetNamespace(context.Background(), "ns1")
+	assert.NoError(t, err)
+	assert.Equal(t, ns.ID, nsRead.ID)
+}
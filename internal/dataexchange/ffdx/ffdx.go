@@ -150,6 +150,16 @@ func (h *FFDX) Capabilities() *dataexchange.Capabilities {
 	return h.capabilities
 }
 
+func (h *FFDX) HealthCheck(ctx context.Context) error {
+	if err := h.checkInitialized(ctx); err != nil {
+		return err
+	}
+	if !h.wsconn.Connected() {
+		return i18n.NewError(ctx, i18n.MsgWSNotConnected, h.Name())
+	}
+	return nil
+}
+
 func (h *FFDX) beforeConnect(ctx context.Context) error {
 	h.initMutex.Lock()
 	defer h.initMutex.Unlock()
@@ -246,6 +256,18 @@ func (h *FFDX) DownloadBLOB(ctx context.Context, payloadRef string) (content io.
 	return res.RawBody(), nil
 }
 
+func (h *FFDX) DeleteBLOB(ctx context.Context, payloadRef string) (err error) {
+	res, err := h.client.R().SetContext(ctx).
+		Delete(fmt.Sprintf("/api/v1/blobs/%s", payloadRef))
+	if err == nil && res.StatusCode() == http.StatusNotFound {
+		return nil
+	}
+	if err != nil || !res.IsSuccess() {
+		return restclient.WrapRestErr(ctx, res, err, i18n.MsgDXRESTErr)
+	}
+	return nil
+}
+
 func (h *FFDX) SendMessage(ctx context.Context, opID *fftypes.UUID, peerID string, data []byte) (err error) {
 	if err := h.checkInitialized(ctx); err != nil {
 		return err
@@ -359,6 +359,33 @@ func TestDownloadBLOBError(t *testing.T) {
 	assert.Regexp(t, "FF10229", err)
 }
 
+func TestDeleteBLOB(t *testing.T) {
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+
+	u := fftypes.NewUUID()
+	httpmock.RegisterResponder("DELETE", fmt.Sprintf("%s/api/v1/blobs/ns1/%s", httpURL, u),
+		httpmock.NewJsonResponderOrPanic(204, nil))
+	httpmock.RegisterResponder("DELETE", fmt.Sprintf("%s/api/v1/blobs/missing", httpURL),
+		httpmock.NewJsonResponderOrPanic(404, fftypes.JSONObject{}))
+
+	err := h.DeleteBLOB(context.Background(), fmt.Sprintf("ns1/%s", u))
+	assert.NoError(t, err)
+	err = h.DeleteBLOB(context.Background(), "missing")
+	assert.NoError(t, err)
+}
+
+func TestDeleteBLOBError(t *testing.T) {
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+
+	httpmock.RegisterResponder("DELETE", fmt.Sprintf("%s/api/v1/blobs/bad", httpURL),
+		httpmock.NewJsonResponderOrPanic(500, fftypes.JSONObject{}))
+
+	err := h.DeleteBLOB(context.Background(), "bad")
+	assert.Regexp(t, "FF10229", err)
+}
+
 func TestSendMessage(t *testing.T) {
 
 	h, _, _, httpURL, done := newTestFFDX(t, false)
@@ -511,6 +538,27 @@ func TestEventsWithManifest(t *testing.T) {
 	mcb.AssertExpectations(t)
 }
 
+func TestHealthCheck(t *testing.T) {
+	wsm := &wsmocks.WSClient{}
+	h := &FFDX{
+		ctx:         context.Background(),
+		wsconn:      wsm,
+		initialized: true,
+	}
+	wsm.On("Connected").Return(true).Once()
+	err := h.HealthCheck(context.Background())
+	assert.NoError(t, err)
+
+	wsm.On("Connected").Return(false).Once()
+	err = h.HealthCheck(context.Background())
+	assert.Regexp(t, "FF10420.*ffdx", err)
+
+	h.initialized = false
+	err = h.HealthCheck(context.Background())
+	assert.Regexp(t, "FF10342", err)
+	wsm.AssertExpectations(t)
+}
+
 func TestEventLoopReceiveClosed(t *testing.T) {
 	dxc := &dataexchangemocks.Callbacks{}
 	wsm := &wsmocks.WSClient{}
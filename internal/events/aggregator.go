@@ -29,6 +29,7 @@ import (
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/retry"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -401,7 +402,10 @@ func (ag *aggregator) processMessage(ctx context.Context, manifest *fftypes.Batc
 	var newState fftypes.MessageState
 	if dataAvailable {
 		l.Debugf("Attempt dispatch msg=%s broadcastContexts=%v privatePins=%v", msg.Header.ID, unmaskedContexts, msg.Pins)
-		newState, dispatched, err = ag.attemptMessageDispatch(ctx, msg, data, manifest.TX.ID, state, pin)
+		spanCtx, span := tracing.StartSpan(ctx, "aggregate_message", []*fftypes.UUID{msg.Header.ID, manifest.ID},
+			tracing.Namespace(msg.Header.Namespace), tracing.MessageID(msg.Header.ID), tracing.BatchID(manifest.ID))
+		newState, dispatched, err = ag.attemptMessageDispatch(spanCtx, msg, data, manifest.TX.ID, state, pin)
+		tracing.EndSpan(span, err)
 		if err != nil {
 			return err
 		}
@@ -18,10 +18,15 @@ package events
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
 
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
 
@@ -86,6 +91,56 @@ func (em *eventManager) getTopicForChainListener(ctx context.Context, listenerID
 	return topic, nil
 }
 
+// listenerFilterMatches applies the filter of a contract listener to the output of an event, for the cases where
+// the connector was unable to apply the filter itself. Every parameter in the filter must match one of its values.
+func listenerFilterMatches(filter fftypes.JSONObject, output fftypes.JSONObject) bool {
+	for name, condition := range filter {
+		alternatives, _ := fftypes.ListenerFilterValues(condition)
+		matched := false
+		for _, alternative := range alternatives {
+			if filterValueMatches(alternative, output[name]) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// filterValueMatches compares values as strings, allowing for differences in the representation of numbers and
+// the case of hex strings between the filter and the output of the connector
+func filterValueMatches(expected, actual interface{}) bool {
+	e, a := filterValueString(expected), filterValueString(actual)
+	if e == a || (strings.HasPrefix(e, "0x") && strings.EqualFold(e, a)) {
+		return true
+	}
+	eInt, eOK := filterValueInteger(e)
+	aInt, aOK := filterValueInteger(a)
+	return eOK && aOK && eInt.Cmp(aInt) == 0
+}
+
+func filterValueString(v interface{}) string {
+	switch vt := v.(type) {
+	case string:
+		return vt
+	case float64:
+		return strconv.FormatFloat(vt, 'f', -1, 64)
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+func filterValueInteger(s string) (*big.Int, bool) {
+	if strings.HasPrefix(s, "0x") {
+		return new(big.Int).SetString(s[2:], 16)
+	}
+	return new(big.Int).SetString(s, 10)
+}
+
 func (em *eventManager) persistBlockchainEvent(ctx context.Context, chainEvent *fftypes.BlockchainEvent) error {
 	if err := em.database.InsertBlockchainEvent(ctx, chainEvent); err != nil {
 		return err
@@ -112,6 +167,10 @@ func (em *eventManager) BlockchainEvent(event *blockchain.EventWithSubscription)
 				log.L(ctx).Warnf("Event received from unknown subscription %s", event.Subscription)
 				return nil // no retry
 			}
+			if sub.Options != nil && !listenerFilterMatches(sub.Options.Filter, event.Output) {
+				log.L(ctx).Debugf("Event '%s' does not match the filter on listener %s", event.ProtocolID, sub.ID)
+				return nil
+			}
 
 			chainEvent := buildBlockchainEvent(sub.Namespace, sub.ID, &event.Event, nil)
 			if err := em.persistBlockchainEvent(ctx, chainEvent); err != nil {
@@ -122,3 +181,45 @@ func (em *eventManager) BlockchainEvent(event *blockchain.EventWithSubscription)
 		return err != nil, err
 	})
 }
+
+// BlockchainEventRemoved marks any stored events matching a removed blockchain event, and emits an event
+// for each so that applications can compensate for any processing they performed when it was received
+func (em *eventManager) BlockchainEventRemoved(event *blockchain.Event) error {
+	return em.retry.Do(em.ctx, "remove blockchain event", func(attempt int) (bool, error) {
+		err := em.database.RunAsGroup(em.ctx, func(ctx context.Context) error {
+			fb := database.BlockchainEventQueryFactory.NewFilter(ctx)
+			filter := fb.And(
+				fb.Eq("source", event.Source),
+				fb.Eq("protocolid", event.ProtocolID),
+				fb.Eq("removed", nil),
+			)
+			chainEvents, _, err := em.database.GetBlockchainEvents(ctx, filter)
+			if err != nil {
+				return err
+			}
+			if len(chainEvents) == 0 {
+				log.L(ctx).Infof("No stored events match removed event '%s' from '%s'", event.ProtocolID, event.Source)
+				return nil
+			}
+
+			removed := fftypes.Now()
+			for _, chainEvent := range chainEvents {
+				log.L(ctx).Warnf("Blockchain event %s '%s' removed from the chain", chainEvent.ID, chainEvent.ProtocolID)
+				update := database.BlockchainEventQueryFactory.NewUpdate(ctx).Set("removed", removed)
+				if err := em.database.UpdateBlockchainEvent(ctx, chainEvent.ID, update); err != nil {
+					return err
+				}
+				topic, err := em.getTopicForChainListener(ctx, chainEvent.Listener)
+				if err != nil {
+					return err
+				}
+				ffEvent := fftypes.NewEvent(fftypes.EventTypeBlockchainEventRemoved, chainEvent.Namespace, chainEvent.ID, chainEvent.TX.ID, topic)
+				if err := em.database.InsertEvent(ctx, ffEvent); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		return err != nil, err
+	})
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -98,6 +99,65 @@ func TestContractEventUnknownSubscription(t *testing.T) {
 	mdi.AssertExpectations(t)
 }
 
+func TestContractEventFilteredOut(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	ev := &blockchain.EventWithSubscription{
+		Subscription: "sb-1",
+		Event: blockchain.Event{
+			Name: "Transfer",
+			Output: fftypes.JSONObject{
+				"from": "0x91D2B4381A4CD5C7C0F27565A7D4B829844C8635",
+			},
+		},
+	}
+	sub := &fftypes.ContractListener{
+		Namespace: "ns",
+		ID:        fftypes.NewUUID(),
+		Options: &fftypes.ContractListenerOptions{
+			Filter: fftypes.JSONObject{
+				"from": "0x1111111111111111111111111111111111111111",
+			},
+		},
+	}
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetContractListenerByProtocolID", mock.Anything, "sb-1").Return(sub, nil)
+
+	err := em.BlockchainEvent(ev)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestListenerFilterMatches(t *testing.T) {
+	output := fftypes.JSONObject{
+		"from":   "0x91D2B4381A4CD5C7C0F27565A7D4B829844C8635",
+		"value":  "1000",
+		"flag":   true,
+		"name":   "Widget",
+		"nested": map[string]interface{}{"a": "b"},
+		"list":   []interface{}{"a", "b"},
+	}
+
+	assert.True(t, listenerFilterMatches(nil, output))
+	assert.True(t, listenerFilterMatches(fftypes.JSONObject{
+		"from":   "0x91d2b4381a4cd5c7c0f27565a7d4b829844c8635",
+		"value":  map[string]interface{}{"anyOf": []interface{}{float64(1), float64(1000)}},
+		"flag":   true,
+		"name":   "Widget",
+		"nested": map[string]interface{}{"equals": map[string]interface{}{"a": "b"}},
+		"list":   map[string]interface{}{"equals": []interface{}{"a", "b"}},
+	}, output))
+	assert.True(t, listenerFilterMatches(fftypes.JSONObject{"value": "0x3e8"}, output))
+	assert.False(t, listenerFilterMatches(fftypes.JSONObject{"name": "widget"}, output))
+	assert.False(t, listenerFilterMatches(fftypes.JSONObject{"value": map[string]interface{}{"anyOf": []interface{}{"1", "2"}}}, output))
+	assert.False(t, listenerFilterMatches(fftypes.JSONObject{"list": []interface{}{"a", "b"}}, output))
+	assert.False(t, listenerFilterMatches(fftypes.JSONObject{"list": map[string]interface{}{"anyOf": []interface{}{"a", "b"}}}, output))
+	assert.False(t, listenerFilterMatches(fftypes.JSONObject{"missing": "1"}, output))
+}
+
 func TestPersistBlockchainEventChainListenerLoopkupFail(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	defer cancel()
@@ -142,3 +202,60 @@ func TestGetTopicForChainListenerFallback(t *testing.T) {
 
 	mdi.AssertExpectations(t)
 }
+
+func TestBlockchainEventRemovedWithRetries(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	ev := &blockchain.Event{
+		Source:     "ethereum",
+		ProtocolID: "000000000010/000000/000001",
+	}
+	listenerID := fftypes.NewUUID()
+	txID := fftypes.NewUUID()
+	chainEvents := []*fftypes.BlockchainEvent{
+		{ID: fftypes.NewUUID(), Namespace: "ns1", Listener: listenerID},
+		{ID: fftypes.NewUUID(), Namespace: "ns1", TX: fftypes.TransactionRef{ID: txID}},
+	}
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetBlockchainEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Once()
+	mdi.On("GetBlockchainEvents", mock.Anything, mock.Anything).Return(chainEvents, nil, nil)
+	mdi.On("UpdateBlockchainEvent", mock.Anything, chainEvents[0].ID, mock.Anything).Return(fmt.Errorf("pop")).Once()
+	mdi.On("UpdateBlockchainEvent", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("GetContractListenerByID", mock.Anything, listenerID).Return(nil, fmt.Errorf("pop")).Once()
+	mdi.On("GetContractListenerByID", mock.Anything, listenerID).Return(&fftypes.ContractListener{Topic: "topic1"}, nil)
+	mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(fmt.Errorf("pop")).Once()
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeBlockchainEventRemoved && e.Reference == chainEvents[0].ID && e.Topic == "topic1"
+	})).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeBlockchainEventRemoved && e.Reference == chainEvents[1].ID && e.Transaction == txID && e.Topic == fftypes.SystemBatchPinTopic
+	})).Return(nil)
+
+	err := em.BlockchainEventRemoved(ev)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestBlockchainEventRemovedNoMatch(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	ev := &blockchain.Event{
+		Source:     "ethereum",
+		ProtocolID: "000000000010/000000/000001",
+	}
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetBlockchainEvents", mock.Anything, mock.MatchedBy(func(f database.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.String() == "( source == 'ethereum' ) && ( protocolid == '000000000010/000000/000001' ) && ( removed == null )"
+	})).Return([]*fftypes.BlockchainEvent{}, nil, nil)
+
+	err := em.BlockchainEventRemoved(ev)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
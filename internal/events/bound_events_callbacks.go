@@ -38,6 +38,14 @@ func (bc *boundCallbacks) DeliveryResponse(connID string, inflight *fftypes.Even
 	bc.sm.deliveryResponse(bc.ei, connID, inflight)
 }
 
+func (bc *boundCallbacks) CumulativeAck(connID string, subID *fftypes.UUID, sequence int64) {
+	bc.sm.cumulativeAck(bc.ei, connID, subID, sequence)
+}
+
+func (bc *boundCallbacks) DeliveryRetry(connID string, sub *fftypes.SubscriptionRef) {
+	bc.sm.deliveryRetry(bc.ei, connID, sub)
+}
+
 func (bc *boundCallbacks) ConnnectionClosed(connID string) {
 	bc.sm.connnectionClosed(bc.ei, connID)
 }
@@ -18,8 +18,11 @@ package events
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/data"
@@ -27,6 +30,7 @@ import (
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/retry"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/events"
@@ -41,6 +45,15 @@ type ackNack struct {
 	id     fftypes.UUID
 	isNack bool
 	offset int64
+	info   string
+	event  *fftypes.Event
+	// cumulative is the set of events released by a cumulative ack, in which case offset is the highest of them
+	cumulative []fftypes.UUID
+}
+
+type replayedEvent struct {
+	deadLetter *fftypes.DeadLetter
+	event      *fftypes.Event
 }
 
 type eventDispatcher struct {
@@ -60,10 +73,25 @@ type eventDispatcher struct {
 	mux           sync.Mutex
 	namespace     string
 	readAhead     int
+	batchOptions  *events.BatchOptions
 	subscription  *subscription
 	cel           *changeEventListener
 	changeEvents  chan *fftypes.ChangeEvent
 	txHelper      txcommon.Helper
+	// maxRedeliveries is the number of times a rejected event is redelivered, before it is dead lettered (zero for unlimited)
+	maxRedeliveries int
+	replays         map[fftypes.UUID]*replayedEvent
+	// totalNacks, lastDelivery and lastAck are statistics for the lifetime of the dispatcher, reported in the subscription status
+	totalNacks   int64
+	lastDelivery *fftypes.FFTime
+	lastAck      *fftypes.FFTime
+}
+
+type dispatcherStats struct {
+	inflight     int
+	nacks        int64
+	lastDelivery *fftypes.FFTime
+	lastAck      *fftypes.FFTime
 }
 
 func newEventDispatcher(ctx context.Context, ei events.Plugin, di database.Plugin, dm data.Manager, sh definitions.DefinitionHandlers, connID string, sub *subscription, en *eventNotifier, cel *changeEventListener, txHelper txcommon.Helper) *eventDispatcher {
@@ -72,29 +100,44 @@ func newEventDispatcher(ctx context.Context, ei events.Plugin, di database.Plugi
 	if sub.definition.Options.ReadAhead != nil {
 		readAhead = uint(*sub.definition.Options.ReadAhead)
 	}
+	var batchOptions *events.BatchOptions
+	if batcher, ok := ei.(events.BatchDeliverer); ok {
+		batchOptions = batcher.BatchOptions(sub.definition)
+	}
+	if batchOptions != nil && readAhead+1 < uint(batchOptions.Size) {
+		// A batch can only hold the events we allow in flight, so read far enough ahead to fill it
+		readAhead = uint(batchOptions.Size - 1)
+	}
 	if readAhead > maxReadAhead {
 		readAhead = maxReadAhead
 	}
+	maxRedeliveries := 0
+	if sub.definition.Options.MaxRedeliveries != nil && !sub.definition.Ephemeral {
+		maxRedeliveries = int(*sub.definition.Options.MaxRedeliveries)
+	}
 	ed := &eventDispatcher{
 		ctx: log.WithLogField(log.WithLogField(ctx,
 			"role", fmt.Sprintf("ed[%s]", connID)),
 			"sub", fmt.Sprintf("%s/%s:%s", sub.definition.ID, sub.definition.Namespace, sub.definition.Name)),
-		database:      di,
-		transport:     ei,
-		definitions:   sh,
-		data:          dm,
-		connID:        connID,
-		cancelCtx:     cancelCtx,
-		subscription:  sub,
-		namespace:     sub.definition.Namespace,
-		inflight:      make(map[fftypes.UUID]*fftypes.Event),
-		eventDelivery: make(chan *fftypes.EventDelivery, readAhead+1),
-		changeEvents:  make(chan *fftypes.ChangeEvent),
-		readAhead:     int(readAhead),
-		acksNacks:     make(chan ackNack),
-		closed:        make(chan struct{}),
-		cel:           cel,
-		txHelper:      txHelper,
+		database:        di,
+		transport:       ei,
+		definitions:     sh,
+		data:            dm,
+		connID:          connID,
+		cancelCtx:       cancelCtx,
+		subscription:    sub,
+		namespace:       sub.definition.Namespace,
+		inflight:        make(map[fftypes.UUID]*fftypes.Event),
+		eventDelivery:   make(chan *fftypes.EventDelivery, readAhead+1),
+		changeEvents:    make(chan *fftypes.ChangeEvent),
+		readAhead:       int(readAhead),
+		batchOptions:    batchOptions,
+		acksNacks:       make(chan ackNack),
+		closed:          make(chan struct{}),
+		cel:             cel,
+		txHelper:        txHelper,
+		maxRedeliveries: maxRedeliveries,
+		replays:         make(map[fftypes.UUID]*replayedEvent),
 	}
 
 	pollerConf := &eventPollerConf{
@@ -144,11 +187,14 @@ func (ed *eventDispatcher) electAndStart() {
 		return
 	}
 	// We're ready to go - not
+	ed.mux.Lock()
 	ed.elected = true
+	ed.mux.Unlock()
 	ed.eventPoller.start()
 	go ed.deliverEvents()
-	// Wait until the event poller closes
+	// Wait until the event poller closes, and any final offset commit is complete
 	<-ed.eventPoller.closed
+	<-ed.eventPoller.commitLoopDone
 }
 
 func (ed *eventDispatcher) getEvents(ctx context.Context, filter database.Filter) ([]fftypes.LocallySequenced, error) {
@@ -176,7 +222,7 @@ func (ed *eventDispatcher) enrichEvents(events []fftypes.LocallySequenced) ([]*f
 	return enriched, nil
 }
 
-func (ed *eventDispatcher) filterEvents(candidates []*fftypes.EventDelivery) []*fftypes.EventDelivery {
+func (ed *eventDispatcher) filterEvents(candidates []*fftypes.EventDelivery) ([]*fftypes.EventDelivery, error) {
 	matchingEvents := make([]*fftypes.EventDelivery, 0, len(candidates))
 	for _, event := range candidates {
 		filter := ed.subscription
@@ -249,9 +295,87 @@ func (ed *eventDispatcher) filterEvents(candidates []*fftypes.EventDelivery) []*
 			}
 		}
 
+		// Data filters are checked last, as the message data might need to be loaded
+		if len(filter.dataFilters) > 0 {
+			dataMatch, err := ed.matchDataFilters(event)
+			if err != nil {
+				return nil, err
+			}
+			if !dataMatch {
+				continue
+			}
+		}
+
 		matchingEvents = append(matchingEvents, event)
 	}
-	return matchingEvents
+	return matchingEvents, nil
+}
+
+func (ed *eventDispatcher) matchDataFilters(event *fftypes.EventDelivery) (bool, error) {
+	var values []interface{}
+	switch {
+	case event.Message != nil:
+		data, _, err := ed.data.GetMessageDataCached(ed.ctx, event.Message)
+		if err != nil {
+			return false, err
+		}
+		for _, d := range data {
+			var value interface{}
+			if d.Value != nil && json.Unmarshal(d.Value.Bytes(), &value) == nil {
+				values = append(values, value)
+			}
+		}
+	case event.BlockchainEvent != nil:
+		values = append(values, map[string]interface{}(event.BlockchainEvent.Output))
+	}
+
+	// Any one value must match all of the filters
+	for _, value := range values {
+		match := true
+		for _, df := range ed.subscription.dataFilters {
+			fieldValue, ok := resolveDataPath(value, df.path)
+			if !ok || !df.valueFilter.MatchString(fieldValue) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// resolveDataPath walks a dot separated path through a JSON value, and returns the string form of the
+// field at the end of it. Strings are returned as-is, and other types are returned as JSON.
+func resolveDataPath(value interface{}, path []string) (string, bool) {
+	for _, segment := range path {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if value, ok = v[segment]; !ok {
+				return "", false
+			}
+		case fftypes.JSONObject:
+			var ok bool
+			if value, ok = v[segment]; !ok {
+				return "", false
+			}
+		case []interface{}:
+			idx, err := strconv.Atoi(segment)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return "", false
+			}
+			value = v[idx]
+		default:
+			return "", false
+		}
+	}
+	if s, ok := value.(string); ok {
+		return s, true
+	}
+	b, _ := json.Marshal(value)
+	return string(b), true
 }
 
 func (ed *eventDispatcher) bufferedDelivery(events []fftypes.LocallySequenced) (bool, error) {
@@ -272,7 +396,13 @@ func (ed *eventDispatcher) bufferedDelivery(events []fftypes.LocallySequenced) (
 		return false, err
 	}
 
-	matching := ed.filterEvents(candidates)
+	matching, err := ed.filterEvents(candidates)
+	if err != nil {
+		return false, err
+	}
+	if ed.maxRedeliveries > 0 {
+		matching = ed.skipDeadLettered(matching)
+	}
 	matchCount := len(matching)
 	dispatched := 0
 
@@ -315,6 +445,10 @@ func (ed *eventDispatcher) bufferedDelivery(events []fftypes.LocallySequenced) (
 		case <-ed.ctx.Done():
 			return false, i18n.NewError(ed.ctx, i18n.MsgDispatcherClosing)
 		case an := <-ed.acksNacks:
+			if an.isNack && ed.deadLetterIfExhausted(an) {
+				// The event has been moved to the dead letter collection, so we move past it as if it was acknowledged
+				an.isNack = false
+			}
 			if an.isNack {
 				nacks++
 				ed.handleNackOffsetUpdate(an)
@@ -343,10 +477,64 @@ func (ed *eventDispatcher) handleNackOffsetUpdate(nack ackNack) {
 	ed.inflight = map[fftypes.UUID]*fftypes.Event{}
 }
 
+func (ed *eventDispatcher) deadLetterIfExhausted(nack ackNack) bool {
+	if ed.maxRedeliveries <= 0 {
+		return false
+	}
+	attempts := ed.subscription.redeliveries.nacked(nack.id)
+	if attempts <= ed.maxRedeliveries {
+		return false
+	}
+
+	l := log.L(ed.ctx)
+	deadLetter := &fftypes.DeadLetter{
+		ID:           fftypes.NewUUID(),
+		Namespace:    ed.namespace,
+		Subscription: ed.subscription.definition.SubscriptionRef,
+		Event:        nack.event.ID,
+		EventType:    nack.event.Type,
+		Reference:    nack.event.Reference,
+		Sequence:     nack.event.Sequence,
+		Attempts:     attempts,
+		Info:         nack.info,
+		Created:      fftypes.Now(),
+	}
+	if err := ed.database.InsertDeadLetter(ed.ctx, deadLetter); err != nil {
+		// We will redeliver, and try again next time it is rejected
+		l.Errorf("Failed to dead letter event %.10d/%s after %d attempts: %s", nack.event.Sequence, nack.event.ID, attempts, err)
+		return false
+	}
+	// The event is no longer in flight, even if we are waiting to redeliver from an earlier rejection
+	ed.subscription.redeliveries.deadLetter(nack.id, nack.offset)
+	ed.mux.Lock()
+	delete(ed.inflight, nack.id)
+	ed.mux.Unlock()
+	l.Warnf("Event %.10d/%s [%s] moved to dead letter %s after %d attempts: info='%s'", nack.event.Sequence, nack.event.ID, nack.event.Type, deadLetter.ID, attempts, nack.info)
+	return true
+}
+
+// skipDeadLettered removes events that have already been moved to the dead letter collection, but have
+// been read again because the offset was rewound to redeliver an earlier rejected event
+func (ed *eventDispatcher) skipDeadLettered(matching []*fftypes.EventDelivery) []*fftypes.EventDelivery {
+	offset := ed.eventPoller.getPollingOffset()
+	remaining := make([]*fftypes.EventDelivery, 0, len(matching))
+	for _, event := range matching {
+		if ed.subscription.redeliveries.isDeadLettered(*event.ID, offset) {
+			log.L(ed.ctx).Debugf("Skipping dead lettered event %.10d/%s", event.Sequence, event.ID)
+			continue
+		}
+		remaining = append(remaining, event)
+	}
+	return remaining
+}
+
 func (ed *eventDispatcher) handleAckOffsetUpdate(ack ackNack) {
 	oldOffset := ed.eventPoller.getPollingOffset()
 	ed.mux.Lock()
 	delete(ed.inflight, ack.id)
+	for _, id := range ack.cumulative {
+		delete(ed.inflight, id)
+	}
 	lowestInflight := int64(-1)
 	for _, inflight := range ed.inflight {
 		if lowestInflight < 0 || inflight.Sequence < lowestInflight {
@@ -354,6 +542,10 @@ func (ed *eventDispatcher) handleAckOffsetUpdate(ack ackNack) {
 		}
 	}
 	ed.mux.Unlock()
+	if ed.maxRedeliveries > 0 {
+		ed.subscription.redeliveries.acked(ack.id)
+		ed.subscription.redeliveries.acked(ack.cumulative...)
+	}
 	if (lowestInflight == -1 || lowestInflight > ack.offset) && ack.offset > oldOffset {
 		// This was the lowest in flight, and we can move the offset forwards
 		ed.eventPoller.commitOffset(ack.offset)
@@ -376,6 +568,10 @@ func (ed *eventDispatcher) deliverEvents() {
 		defer ed.cel.removeDispatcher(*ed.subscription.definition.ID)
 	}
 	withData := ed.subscription.definition.Options.WithData != nil && *ed.subscription.definition.Options.WithData
+	batchOptions := ed.batchOptions
+	batcher, _ := ed.transport.(events.BatchDeliverer)
+	var batch []*events.EventDeliveryWithData
+	var batchTimeout <-chan time.Time
 	for {
 		select {
 		case event, ok := <-ed.eventDelivery:
@@ -383,17 +579,41 @@ func (ed *eventDispatcher) deliverEvents() {
 				return
 			}
 			log.L(ed.ctx).Debugf("Dispatching %s event: %.10d/%s [%s]: ref=%s/%s", ed.transport.Name(), event.Sequence, event.ID, event.Type, event.Namespace, event.Reference)
+			ed.mux.Lock()
+			ed.lastDelivery = fftypes.Now()
+			ed.mux.Unlock()
 			var data []*fftypes.Data
 			var err error
 			if withData && event.Message != nil {
 				data, _, err = ed.data.GetMessageDataCached(ed.ctx, event.Message)
 			}
+			if err == nil && batchOptions != nil {
+				// Add to the batch, which is delivered when full or when the timeout expires
+				batch = append(batch, &events.EventDeliveryWithData{Event: event, Data: data})
+				if len(batch) == 1 {
+					batchTimeout = time.After(batchOptions.Timeout)
+				}
+				if len(batch) >= batchOptions.Size {
+					ed.deliverBatch(batcher, batch)
+					batch = nil
+					batchTimeout = nil
+				}
+				break
+			}
 			if err == nil {
-				err = ed.transport.DeliveryRequest(ed.connID, ed.subscription.definition, event, data)
+				// The delivery span links to the span of the message (or other object) the event refers to
+				_, span := tracing.StartSpan(ed.ctx, "deliver_event", []*fftypes.UUID{event.Reference},
+					tracing.Namespace(event.Namespace), tracing.EventID(event.ID), tracing.Subscription(ed.subscription.definition.Name))
+				err = ed.transport.DeliveryRequest(ed.ctx, ed.connID, ed.subscription.definition, event, data)
+				tracing.EndSpan(span, err)
 			}
 			if err != nil {
 				ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: event.ID, Rejected: true})
 			}
+		case <-batchTimeout:
+			ed.deliverBatch(batcher, batch)
+			batch = nil
+			batchTimeout = nil
 		case changeEvent := <-ed.changeEvents:
 			ws, ok := ed.transport.(events.ChangeEventListener)
 			if !ok {
@@ -406,6 +626,25 @@ func (ed *eventDispatcher) deliverEvents() {
 		}
 	}
 }
+
+func (ed *eventDispatcher) deliverBatch(batcher events.BatchDeliverer, batch []*events.EventDeliveryWithData) {
+	log.L(ed.ctx).Debugf("Dispatching batch of %d %s events", len(batch), ed.transport.Name())
+	refs := make([]*fftypes.UUID, len(batch))
+	for i, e := range batch {
+		refs[i] = e.Event.Reference
+	}
+	_, span := tracing.StartSpan(ed.ctx, "deliver_event_batch", refs,
+		tracing.Namespace(ed.subscription.definition.Namespace), tracing.Subscription(ed.subscription.definition.Name))
+	err := batcher.BatchDeliveryRequest(ed.ctx, ed.connID, ed.subscription.definition, batch)
+	tracing.EndSpan(span, err)
+	if err != nil {
+		// The whole batch is rejected
+		for _, e := range batch {
+			ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: e.Event.ID, Rejected: true, Info: err.Error()})
+		}
+	}
+}
+
 func (ed *eventDispatcher) deliveryResponse(response *fftypes.EventDeliveryResponse) {
 	l := log.L(ed.ctx)
 
@@ -416,10 +655,25 @@ func (ed *eventDispatcher) deliveryResponse(response *fftypes.EventDeliveryRespo
 		an.id = *response.ID
 		an.offset = event.Sequence
 		an.isNack = response.Rejected
+		an.info = response.Info
+		an.event = event
+		if response.Rejected {
+			ed.totalNacks++
+		} else {
+			ed.lastAck = fftypes.Now()
+		}
+	}
+	replay, replaying := ed.replays[*response.ID]
+	if !found && replaying {
+		delete(ed.replays, *response.ID)
 	}
 	ed.mux.Unlock()
 
 	// Do some extra logging and persistent actions now we're out of lock
+	if !found && replaying {
+		ed.replayResponse(replay, response)
+		return
+	}
 	if !found {
 		l.Warnf("Response for event not in flight: %s rejected=%t info='%s' (likely previous reject)", response.ID, response.Rejected, response.Info)
 		return
@@ -442,6 +696,108 @@ func (ed *eventDispatcher) deliveryResponse(response *fftypes.EventDeliveryRespo
 	}
 }
 
+func (ed *eventDispatcher) cumulativeAck(sequence int64) {
+	l := log.L(ed.ctx)
+
+	ed.mux.Lock()
+	an := ackNack{offset: -1}
+	for id, event := range ed.inflight {
+		if event.Sequence <= sequence {
+			an.cumulative = append(an.cumulative, id)
+			if event.Sequence > an.offset {
+				an.offset = event.Sequence
+			}
+		}
+	}
+	if len(an.cumulative) > 0 {
+		ed.lastAck = fftypes.Now()
+	}
+	ed.mux.Unlock()
+
+	if len(an.cumulative) == 0 {
+		l.Warnf("Cumulative ack up to %d matched no events in flight (likely previous reject)", sequence)
+		return
+	}
+
+	l.Debugf("Cumulative ack for %s events up to %d: count=%d", ed.transport.Name(), sequence, len(an.cumulative))
+	select {
+	case ed.acksNacks <- an:
+	case <-ed.ctx.Done():
+		l.Debugf("Cumulative ack will not be delivered: closing")
+	}
+}
+
+func (ed *eventDispatcher) replayDeadLetter(deadLetter *fftypes.DeadLetter) (bool, error) {
+	ed.mux.Lock()
+	elected := ed.elected
+	ed.mux.Unlock()
+	if !elected {
+		// Only the elected dispatcher for a subscription can deliver events
+		return false, nil
+	}
+
+	event, err := ed.database.GetEventByID(ed.ctx, deadLetter.Event)
+	if err != nil {
+		return true, err
+	}
+	if event == nil {
+		return true, i18n.NewError(ed.ctx, i18n.Msg404NotFound)
+	}
+	enriched, err := ed.enrichEvents([]fftypes.LocallySequenced{event})
+	if err != nil {
+		return true, err
+	}
+
+	ed.mux.Lock()
+	ed.replays[*event.ID] = &replayedEvent{
+		deadLetter: deadLetter,
+		event:      event,
+	}
+	ed.mux.Unlock()
+
+	log.L(ed.ctx).Infof("Replaying dead letter %s for event %.10d/%s [%s]", deadLetter.ID, event.Sequence, event.ID, event.Type)
+	select {
+	case ed.eventDelivery <- enriched[0]:
+	case <-ed.ctx.Done():
+		return true, i18n.NewError(ed.ctx, i18n.MsgDispatcherClosing)
+	}
+	return true, nil
+}
+
+func (ed *eventDispatcher) replayResponse(replay *replayedEvent, response *fftypes.EventDeliveryResponse) {
+	l := log.L(ed.ctx)
+	l.Debugf("Response for replayed %s event: %.10d/%s [%s]: deadletter=%s rejected=%t info='%s'", ed.transport.Name(), replay.event.Sequence, replay.event.ID, replay.event.Type, replay.deadLetter.ID, response.Rejected, response.Info)
+
+	if response.Reply != nil {
+		ed.definitions.SendReply(ed.ctx, replay.event, response.Reply)
+	}
+
+	var err error
+	if response.Rejected {
+		// The dead letter stays in place, with the latest rejection recorded against it
+		update := database.DeadLetterQueryFactory.NewUpdate(ed.ctx).
+			Set("attempts", replay.deadLetter.Attempts+1).
+			Set("info", response.Info)
+		err = ed.database.UpdateDeadLetter(ed.ctx, replay.deadLetter.ID, update)
+	} else {
+		err = ed.database.DeleteDeadLetter(ed.ctx, replay.deadLetter.ID)
+	}
+	if err != nil {
+		l.Errorf("Failed to update dead letter %s after replay: %s", replay.deadLetter.ID, err)
+	}
+}
+
+func (ed *eventDispatcher) getStats() *dispatcherStats {
+	ed.mux.Lock()
+	defer ed.mux.Unlock()
+	return &dispatcherStats{
+		inflight:     len(ed.inflight),
+		nacks:        ed.totalNacks,
+		lastDelivery: ed.lastDelivery,
+		lastAck:      ed.lastAck,
+	}
+}
+
 func (ed *eventDispatcher) close() {
 	log.L(ed.ctx).Infof("Dispatcher closing for conn=%s subscription=%s", ed.connID, ed.subscription.definition.ID)
 	ed.cancelCtx()
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/log"
@@ -163,9 +164,9 @@ func TestEventDispatcherReadAheadOutOfOrderAcks(t *testing.T) {
 	mdm := ed.data.(*datamocks.Manager)
 
 	eventDeliveries := make(chan *fftypes.EventDelivery)
-	deliveryRequestMock := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliveryRequestMock := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	deliveryRequestMock.RunFn = func(a mock.Arguments) {
-		eventDeliveries <- a.Get(2).(*fftypes.EventDelivery)
+		eventDeliveries <- a.Get(3).(*fftypes.EventDelivery)
 	}
 
 	// Setup the IDs
@@ -238,6 +239,80 @@ func TestEventDispatcherReadAheadOutOfOrderAcks(t *testing.T) {
 	mdm.AssertExpectations(t)
 }
 
+func TestEventDispatcherCumulativeAck(t *testing.T) {
+	log.SetLevel("debug")
+	var five = uint16(5)
+	sub := &subscription{
+		dispatcherElection: make(chan bool, 1),
+		definition: &fftypes.Subscription{
+			SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+			Options: fftypes.SubscriptionOptions{
+				SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+					ReadAhead: &five,
+				},
+			},
+		},
+	}
+
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+	go ed.deliverEvents()
+	ed.eventPoller.offsetCommitted = make(chan int64, 3)
+	mdi := ed.database.(*databasemocks.Plugin)
+	mei := ed.transport.(*eventsmocks.PluginAll)
+
+	eventDeliveries := make(chan *fftypes.EventDelivery)
+	deliveryRequestMock := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliveryRequestMock.RunFn = func(a mock.Arguments) {
+		eventDeliveries <- a.Get(3).(*fftypes.EventDelivery)
+	}
+
+	batch1Done := make(chan struct{})
+	go func() {
+		repoll, err := ed.bufferedDelivery([]fftypes.LocallySequenced{
+			&fftypes.Event{ID: fftypes.NewUUID(), Sequence: 10000001},
+			&fftypes.Event{ID: fftypes.NewUUID(), Sequence: 10000002},
+			&fftypes.Event{ID: fftypes.NewUUID(), Sequence: 10000003},
+		})
+		assert.NoError(t, err)
+		assert.True(t, repoll)
+		close(batch1Done)
+	}()
+
+	<-eventDeliveries
+	<-eventDeliveries
+	event3 := <-eventDeliveries
+
+	// Ack the first two in a single step, and check they are all released with a single offset commit
+	ed.cumulativeAck(10000002)
+	assert.Equal(t, int64(10000002), <-ed.eventPoller.offsetCommitted)
+	assert.Len(t, ed.inflight, 1)
+	assert.NotNil(t, ed.getStats().lastAck)
+
+	// A cumulative ack that matches nothing is ignored
+	ed.cumulativeAck(10000002)
+
+	ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: event3.ID})
+	assert.Equal(t, int64(10000003), <-ed.eventPoller.offsetCommitted)
+
+	<-batch1Done
+	assert.Empty(t, ed.eventPoller.offsetCommitted)
+
+	mdi.AssertExpectations(t)
+	mei.AssertExpectations(t)
+}
+
+func TestEventDispatcherCumulativeAckClosed(t *testing.T) {
+	sub := &subscription{
+		definition: &fftypes.Subscription{},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	cancel()
+
+	ed.inflight[*fftypes.NewUUID()] = &fftypes.Event{Sequence: 1}
+	ed.cumulativeAck(1)
+}
+
 func TestEventDispatcherNoReadAheadInOrder(t *testing.T) {
 	log.SetLevel("debug")
 	sub := &subscription{
@@ -258,9 +333,9 @@ func TestEventDispatcherNoReadAheadInOrder(t *testing.T) {
 	mei := ed.transport.(*eventsmocks.PluginAll)
 
 	eventDeliveries := make(chan *fftypes.EventDelivery)
-	deliveryRequestMock := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliveryRequestMock := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	deliveryRequestMock.RunFn = func(a mock.Arguments) {
-		eventDeliveries <- a.Get(2).(*fftypes.EventDelivery)
+		eventDeliveries <- a.Get(3).(*fftypes.EventDelivery)
 	}
 
 	// Setup the IDs
@@ -465,7 +540,7 @@ func TestFilterEventsMatch(t *testing.T) {
 	id5 := fftypes.NewUUID()
 	id6 := fftypes.NewUUID()
 	lid := fftypes.NewUUID()
-	events := ed.filterEvents([]*fftypes.EventDelivery{
+	events, _ := ed.filterEvents([]*fftypes.EventDelivery{
 		{
 			EnrichedEvent: fftypes.EnrichedEvent{
 				Event: fftypes.Event{
@@ -565,7 +640,7 @@ func TestFilterEventsMatch(t *testing.T) {
 	ed.subscription.topicFilter = regexp.MustCompile(".*")
 	ed.subscription.messageFilter.tagFilter = regexp.MustCompile(".*")
 	ed.subscription.messageFilter.groupFilter = regexp.MustCompile(".*")
-	matched := ed.filterEvents(events)
+	matched, _ := ed.filterEvents(events)
 	assert.Equal(t, 2, len(matched))
 	assert.Equal(t, *id1, *matched[0].ID)
 	assert.Equal(t, *id2, *matched[1].ID)
@@ -575,7 +650,7 @@ func TestFilterEventsMatch(t *testing.T) {
 	ed.subscription.topicFilter = nil
 	ed.subscription.messageFilter.tagFilter = nil
 	ed.subscription.messageFilter.groupFilter = nil
-	matched = ed.filterEvents(events)
+	matched, _ = ed.filterEvents(events)
 	assert.Equal(t, 6, len(matched))
 	assert.Equal(t, *id1, *matched[0].ID)
 	assert.Equal(t, *id2, *matched[1].ID)
@@ -584,46 +659,46 @@ func TestFilterEventsMatch(t *testing.T) {
 	assert.Equal(t, *id5, *matched[4].ID)
 
 	ed.subscription.topicFilter = regexp.MustCompile("topic1")
-	matched = ed.filterEvents(events)
+	matched, _ = ed.filterEvents(events)
 	assert.Equal(t, 2, len(matched))
 	assert.Equal(t, *id1, *matched[0].ID)
 	assert.Equal(t, *id2, *matched[1].ID)
 
 	ed.subscription.topicFilter = nil
 	ed.subscription.messageFilter.tagFilter = regexp.MustCompile("tag2")
-	matched = ed.filterEvents(events)
+	matched, _ = ed.filterEvents(events)
 	assert.Equal(t, 1, len(matched))
 	assert.Equal(t, *id2, *matched[0].ID)
 
 	ed.subscription.topicFilter = nil
 	ed.subscription.messageFilter.authorFilter = nil
 	ed.subscription.messageFilter.groupFilter = regexp.MustCompile(gid1.String())
-	matched = ed.filterEvents(events)
+	matched, _ = ed.filterEvents(events)
 	assert.Equal(t, 1, len(matched))
 	assert.Equal(t, *id2, *matched[0].ID)
 
 	ed.subscription.messageFilter.groupFilter = regexp.MustCompile("^$")
-	matched = ed.filterEvents(events)
+	matched, _ = ed.filterEvents(events)
 	assert.Equal(t, 0, len(matched))
 
 	ed.subscription.messageFilter.groupFilter = nil
 	ed.subscription.topicFilter = nil
 	ed.subscription.messageFilter.tagFilter = nil
 	ed.subscription.messageFilter.authorFilter = regexp.MustCompile("org2")
-	matched = ed.filterEvents(events)
+	matched, _ = ed.filterEvents(events)
 	assert.Equal(t, 1, len(matched))
 	assert.Equal(t, *id2, *matched[0].ID)
 
 	ed.subscription.messageFilter = nil
 	ed.subscription.transactionFilter.typeFilter = regexp.MustCompile(fmt.Sprintf("^%s$", fftypes.TransactionTypeBatchPin))
-	matched = ed.filterEvents(events)
+	matched, _ = ed.filterEvents(events)
 	assert.Equal(t, 1, len(matched))
 	assert.Equal(t, *id5, *matched[0].ID)
 
 	ed.subscription.messageFilter = nil
 	ed.subscription.transactionFilter = nil
 	ed.subscription.blockchainFilter.nameFilter = regexp.MustCompile("flapflip")
-	matched = ed.filterEvents(events)
+	matched, _ = ed.filterEvents(events)
 	assert.Equal(t, 1, len(matched))
 	assert.Equal(t, *id4, *matched[0].ID)
 
@@ -631,11 +706,93 @@ func TestFilterEventsMatch(t *testing.T) {
 	ed.subscription.transactionFilter = nil
 	ed.subscription.blockchainFilter.nameFilter = nil
 	ed.subscription.blockchainFilter.listenerFilter = regexp.MustCompile(lid.String())
-	matched = ed.filterEvents(events)
+	matched, _ = ed.filterEvents(events)
 	assert.Equal(t, 1, len(matched))
 	assert.Equal(t, *id6, *matched[0].ID)
 }
 
+func TestFilterEventsData(t *testing.T) {
+
+	sub := &subscription{
+		definition: &fftypes.Subscription{},
+		dataFilters: []*dataFilter{
+			{path: []string{"customer", "id"}, valueFilter: regexp.MustCompile("^abc$")},
+			{path: []string{"items", "1", "qty"}, valueFilter: regexp.MustCompile("^[0-9]+$")},
+		},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	msg1 := &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}}
+	msg2 := &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}}
+	mdm := ed.data.(*datamocks.Manager)
+	mdm.On("GetMessageDataCached", mock.Anything, msg1).Return(fftypes.DataArray{
+		{Value: fftypes.JSONAnyPtr(`"just a string"`)},
+		{Value: fftypes.JSONAnyPtr(`{"customer":{"id":"abc"},"items":[{"qty":1},{"qty":2}]}`)},
+	}, true, nil)
+	mdm.On("GetMessageDataCached", mock.Anything, msg2).Return(fftypes.DataArray{
+		{Value: fftypes.JSONAnyPtr(`{"customer":{"id":"abc"},"items":[{"qty":1}]}`)},
+		{Value: fftypes.JSONAnyPtr(`{"customer":{"id":"abc"},"items":{"1":{"qty":"many"}}}`)},
+		{Value: fftypes.JSONAnyPtr(`{"customer":{"id":"def"}}`)},
+		{Value: fftypes.JSONAnyPtr(`{"vendor":{"id":"abc"}}`)},
+		{},
+	}, true, nil)
+
+	id1 := fftypes.NewUUID()
+	id2 := fftypes.NewUUID()
+	id3 := fftypes.NewUUID()
+	id4 := fftypes.NewUUID()
+	id5 := fftypes.NewUUID()
+	matched, err := ed.filterEvents([]*fftypes.EventDelivery{
+		{EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{ID: id1}, Message: msg1}},
+		{EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{ID: id2}, Message: msg2}},
+		{EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{ID: id3}, BlockchainEvent: &fftypes.BlockchainEvent{
+			Output: fftypes.JSONObject{
+				"customer": fftypes.JSONObject{"id": "abc"},
+				"items":    []interface{}{"first", map[string]interface{}{"qty": 10}},
+			},
+		}}},
+		{EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{ID: id4}, BlockchainEvent: &fftypes.BlockchainEvent{
+			Output: fftypes.JSONObject{
+				"customer": "abc",
+			},
+		}}},
+		{EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{ID: fftypes.NewUUID()}, BlockchainEvent: &fftypes.BlockchainEvent{
+			Output: fftypes.JSONObject{
+				"customer": fftypes.JSONObject{"name": "abc"},
+			},
+		}}},
+		{EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{ID: id5}}},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, matched, 2)
+	assert.Equal(t, *id1, *matched[0].ID)
+	assert.Equal(t, *id3, *matched[1].ID)
+
+	mdm.AssertExpectations(t)
+}
+
+func TestBufferedDeliveryDataFilterFail(t *testing.T) {
+
+	sub := &subscription{
+		definition: &fftypes.Subscription{},
+		dataFilters: []*dataFilter{
+			{valueFilter: regexp.MustCompile(".*")},
+		},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	msg1 := &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}}
+	mdm := ed.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, mock.Anything).Return(msg1, nil, true, nil)
+	mdm.On("GetMessageDataCached", mock.Anything, msg1).Return(nil, false, fmt.Errorf("pop"))
+
+	repoll, err := ed.bufferedDelivery([]fftypes.LocallySequenced{&fftypes.Event{ID: fftypes.NewUUID(), Type: fftypes.EventTypeMessageConfirmed}})
+	assert.False(t, repoll)
+	assert.EqualError(t, err, "pop")
+}
+
 func TestEnrichTransactionEvents(t *testing.T) {
 	log.SetLevel("debug")
 	sub := &subscription{
@@ -655,9 +812,9 @@ func TestEnrichTransactionEvents(t *testing.T) {
 	mei := ed.transport.(*eventsmocks.PluginAll)
 
 	eventDeliveries := make(chan *fftypes.EventDelivery)
-	deliveryRequestMock := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliveryRequestMock := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	deliveryRequestMock.RunFn = func(a mock.Arguments) {
-		eventDeliveries <- a.Get(2).(*fftypes.EventDelivery)
+		eventDeliveries <- a.Get(3).(*fftypes.EventDelivery)
 	}
 
 	// Setup the IDs
@@ -744,9 +901,9 @@ func TestEnrichBlockchainEventEvents(t *testing.T) {
 	mei := ed.transport.(*eventsmocks.PluginAll)
 
 	eventDeliveries := make(chan *fftypes.EventDelivery)
-	deliveryRequestMock := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliveryRequestMock := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	deliveryRequestMock.RunFn = func(a mock.Arguments) {
-		eventDeliveries <- a.Get(2).(*fftypes.EventDelivery)
+		eventDeliveries <- a.Get(3).(*fftypes.EventDelivery)
 	}
 
 	// Setup the IDs
@@ -866,7 +1023,7 @@ func TestBufferedDeliveryClosedContext(t *testing.T) {
 	mdi := ed.database.(*databasemocks.Plugin)
 	mei := ed.transport.(*eventsmocks.PluginAll)
 	mdi.On("GetDataRefs", mock.Anything, mock.Anything).Return(nil, nil, nil)
-	mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
 	repoll, err := ed.bufferedDelivery([]fftypes.LocallySequenced{&fftypes.Event{ID: fftypes.NewUUID()}})
 	assert.False(t, repoll)
@@ -889,7 +1046,7 @@ func TestBufferedDeliveryNackRewind(t *testing.T) {
 	mdi.On("UpdateOffset", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
 	delivered := make(chan struct{})
-	deliver := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliver := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	deliver.RunFn = func(a mock.Arguments) {
 		close(delivered)
 	}
@@ -912,6 +1069,12 @@ func TestBufferedDeliveryNackRewind(t *testing.T) {
 
 	<-bdDone
 	assert.Equal(t, int64(100001), ed.eventPoller.pollingOffset)
+
+	stats := ed.getStats()
+	assert.Equal(t, int64(1), stats.nacks)
+	assert.Equal(t, 0, stats.inflight)
+	assert.NotNil(t, stats.lastDelivery)
+	assert.Nil(t, stats.lastAck)
 }
 
 func TestBufferedDeliveryFailNack(t *testing.T) {
@@ -934,7 +1097,7 @@ func TestBufferedDeliveryFailNack(t *testing.T) {
 	mdi.On("UpdateOffset", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
 
 	failNacked := make(chan bool)
-	deliver := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	deliver := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
 	deliver.RunFn = func(a mock.Arguments) {
 		failNacked <- true
 	}
@@ -961,6 +1124,26 @@ func TestBufferedDeliveryFailNack(t *testing.T) {
 
 }
 
+func TestAckUpdatesStats(t *testing.T) {
+
+	sub := &subscription{
+		definition: &fftypes.Subscription{},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	ev1 := fftypes.NewUUID()
+	ed.inflight[*ev1] = &fftypes.Event{ID: ev1, Sequence: 100001}
+	go ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: ev1})
+	an := <-ed.acksNacks
+	assert.False(t, an.isNack)
+
+	stats := ed.getStats()
+	assert.Equal(t, int64(0), stats.nacks)
+	assert.Equal(t, 1, stats.inflight)
+	assert.NotNil(t, stats.lastAck)
+}
+
 func TestAckNotInFlightNoop(t *testing.T) {
 
 	sub := &subscription{
@@ -1148,3 +1331,459 @@ func TestDispatchChangeEventBlockedClose(t *testing.T) {
 
 	ed.dispatchChangeEvent(&fftypes.ChangeEvent{})
 }
+
+func TestBufferedDeliveryNackDeadLetter(t *testing.T) {
+
+	one := uint16(1)
+	sub := &subscription{
+		definition: &fftypes.Subscription{
+			SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+			Options: fftypes.SubscriptionOptions{
+				SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+					MaxRedeliveries: &one,
+				},
+			},
+		},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+	go ed.deliverEvents()
+	assert.Equal(t, 1, ed.maxRedeliveries)
+
+	ev1 := fftypes.NewUUID()
+	sub.redeliveries.nacked(*ev1) // already redelivered once
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mei := ed.transport.(*eventsmocks.PluginAll)
+	mdi.On("InsertDeadLetter", mock.Anything, mock.MatchedBy(func(dl *fftypes.DeadLetter) bool {
+		return dl.Event.Equals(ev1) && dl.Sequence == 100001 && dl.Attempts == 2 && dl.Info == "bad event" &&
+			dl.Subscription.ID.Equals(sub.definition.ID) && dl.EventType == fftypes.EventTypeIdentityConfirmed
+	})).Return(nil)
+
+	delivered := make(chan struct{})
+	deliver := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliver.RunFn = func(a mock.Arguments) {
+		close(delivered)
+	}
+
+	bdDone := make(chan struct{})
+	ed.eventPoller.pollingOffset = 100000
+	go func() {
+		repoll, err := ed.bufferedDelivery([]fftypes.LocallySequenced{&fftypes.Event{ID: ev1, Sequence: 100001, Type: fftypes.EventTypeIdentityConfirmed}})
+		assert.NoError(t, err)
+		assert.True(t, repoll)
+		close(bdDone)
+	}()
+
+	<-delivered
+	ed.deliveryResponse(&fftypes.EventDeliveryResponse{
+		ID:       ev1,
+		Rejected: true,
+		Info:     "bad event",
+	})
+
+	<-bdDone
+	assert.Equal(t, int64(100001), ed.eventPoller.pollingOffset)
+	assert.Empty(t, sub.redeliveries.nackCounts)
+	assert.Contains(t, sub.redeliveries.deadLettered, *ev1)
+	mdi.AssertExpectations(t)
+}
+
+func TestBufferedDeliveryNackDeadLetterFail(t *testing.T) {
+
+	one := uint16(1)
+	sub := &subscription{
+		definition: &fftypes.Subscription{
+			SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+			Options: fftypes.SubscriptionOptions{
+				SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+					MaxRedeliveries: &one,
+				},
+			},
+		},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+	go ed.deliverEvents()
+
+	ev1 := fftypes.NewUUID()
+	sub.redeliveries.nacked(*ev1)
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mei := ed.transport.(*eventsmocks.PluginAll)
+	mdi.On("InsertDeadLetter", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	delivered := make(chan struct{})
+	deliver := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliver.RunFn = func(a mock.Arguments) {
+		close(delivered)
+	}
+
+	bdDone := make(chan struct{})
+	ed.eventPoller.pollingOffset = 100050
+	go func() {
+		repoll, err := ed.bufferedDelivery([]fftypes.LocallySequenced{&fftypes.Event{ID: ev1, Sequence: 100001}})
+		assert.NoError(t, err)
+		assert.True(t, repoll)
+		close(bdDone)
+	}()
+
+	<-delivered
+	ed.deliveryResponse(&fftypes.EventDeliveryResponse{
+		ID:       ev1,
+		Rejected: true,
+	})
+
+	<-bdDone
+	assert.Equal(t, int64(100001), ed.eventPoller.pollingOffset)
+	assert.Equal(t, 2, sub.redeliveries.nackCounts[*ev1])
+}
+
+func TestBufferedDeliveryDeadLetterAfterNack(t *testing.T) {
+
+	one := uint16(1)
+	sub := &subscription{
+		definition: &fftypes.Subscription{
+			SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+			Options: fftypes.SubscriptionOptions{
+				SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+					MaxRedeliveries: &one,
+				},
+			},
+		},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+	go ed.deliverEvents()
+
+	ev1 := fftypes.NewUUID()
+	ev2 := fftypes.NewUUID()
+	sub.redeliveries.nacked(*ev2) // already redelivered once
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mei := ed.transport.(*eventsmocks.PluginAll)
+	mdi.On("InsertDeadLetter", mock.Anything, mock.MatchedBy(func(dl *fftypes.DeadLetter) bool {
+		return dl.Event.Equals(ev2)
+	})).Return(nil).Once()
+
+	delivered := make(chan *fftypes.UUID, 3)
+	deliver := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliver.RunFn = func(a mock.Arguments) {
+		delivered <- a[3].(*fftypes.EventDelivery).ID
+	}
+
+	events := []fftypes.LocallySequenced{
+		&fftypes.Event{ID: ev1, Sequence: 100001},
+		&fftypes.Event{ID: ev2, Sequence: 100002},
+	}
+	ed.eventPoller.pollingOffset = 100000
+	bdDone := make(chan struct{})
+	go func() {
+		repoll, err := ed.bufferedDelivery(events)
+		assert.NoError(t, err)
+		assert.True(t, repoll)
+		close(bdDone)
+	}()
+
+	// The first event is rejected, then the second is dead lettered while we are waiting to redeliver the first
+	assert.Equal(t, *ev1, *<-delivered)
+	ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: ev1, Rejected: true})
+	assert.Equal(t, *ev2, *<-delivered)
+	ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: ev2, Rejected: true})
+	<-bdDone
+	assert.Empty(t, ed.inflight)
+	assert.Equal(t, 1, sub.redeliveries.nackCounts[*ev1])
+	assert.NotContains(t, sub.redeliveries.nackCounts, *ev2)
+
+	// Only the first event is redelivered
+	bdDone = make(chan struct{})
+	go func() {
+		repoll, err := ed.bufferedDelivery(events)
+		assert.NoError(t, err)
+		assert.True(t, repoll)
+		close(bdDone)
+	}()
+	assert.Equal(t, *ev1, *<-delivered)
+	ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: ev1})
+	<-bdDone
+	assert.Equal(t, int64(100002), ed.eventPoller.pollingOffset)
+	assert.Empty(t, sub.redeliveries.nackCounts)
+
+	// Once the offset has moved past the dead lettered event, it is forgotten
+	assert.False(t, sub.redeliveries.isDeadLettered(*ev2, ed.eventPoller.pollingOffset))
+	assert.Empty(t, sub.redeliveries.deadLettered)
+	mdi.AssertExpectations(t)
+}
+
+func TestRedeliveryStateReset(t *testing.T) {
+	rs := &redeliveryState{}
+	id := fftypes.NewUUID()
+	assert.Equal(t, 1, rs.nacked(*id))
+	rs.deadLetter(*id, 12345)
+	assert.True(t, rs.isDeadLettered(*id, 100))
+	rs.reset()
+	assert.False(t, rs.isDeadLettered(*id, 100))
+	assert.Equal(t, 1, rs.nacked(*id))
+}
+
+func TestEphemeralNoDeadLetter(t *testing.T) {
+	one := uint16(1)
+	ed, cancel := newTestEventDispatcher(&subscription{
+		definition: &fftypes.Subscription{
+			Ephemeral: true,
+			Options: fftypes.SubscriptionOptions{
+				SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+					MaxRedeliveries: &one,
+				},
+			},
+		},
+	})
+	defer cancel()
+	assert.Equal(t, 0, ed.maxRedeliveries)
+}
+
+func newTestReplayDispatcher() (*eventDispatcher, *fftypes.DeadLetter, func()) {
+	sub := &subscription{
+		definition: &fftypes.Subscription{
+			SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+		},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	ed.elected = true
+	deadLetter := &fftypes.DeadLetter{
+		ID:           fftypes.NewUUID(),
+		Namespace:    "ns1",
+		Subscription: sub.definition.SubscriptionRef,
+		Event:        fftypes.NewUUID(),
+		EventType:    fftypes.EventTypeIdentityConfirmed,
+		Attempts:     3,
+	}
+	return ed, deadLetter, cancel
+}
+
+func TestReplayDeadLetterAck(t *testing.T) {
+	ed, deadLetter, cancel := newTestReplayDispatcher()
+	defer cancel()
+	go ed.deliverEvents()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mei := ed.transport.(*eventsmocks.PluginAll)
+	msh := ed.definitions.(*definitionsmocks.DefinitionHandlers)
+	event := &fftypes.Event{ID: deadLetter.Event, Sequence: 12345, Type: fftypes.EventTypeIdentityConfirmed}
+	mdi.On("GetEventByID", mock.Anything, deadLetter.Event).Return(event, nil)
+	deleted := make(chan struct{})
+	mdi.On("DeleteDeadLetter", mock.Anything, deadLetter.ID).Return(fmt.Errorf("pop")).Run(func(a mock.Arguments) {
+		close(deleted)
+	})
+	msh.On("SendReply", mock.Anything, event, mock.Anything).Return()
+	deliver := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliver.RunFn = func(a mock.Arguments) {
+		ed.deliveryResponse(&fftypes.EventDeliveryResponse{
+			ID:    event.ID,
+			Reply: &fftypes.MessageInOut{},
+		})
+	}
+
+	ed.eventPoller.pollingOffset = 100000
+	replayed, err := ed.replayDeadLetter(deadLetter)
+	assert.NoError(t, err)
+	assert.True(t, replayed)
+
+	<-deleted
+	assert.Empty(t, ed.replays)
+	assert.Equal(t, int64(100000), ed.eventPoller.getPollingOffset())
+	mdi.AssertExpectations(t)
+	msh.AssertExpectations(t)
+}
+
+func TestReplayDeadLetterNack(t *testing.T) {
+	ed, deadLetter, cancel := newTestReplayDispatcher()
+	defer cancel()
+	go ed.deliverEvents()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mei := ed.transport.(*eventsmocks.PluginAll)
+	event := &fftypes.Event{ID: deadLetter.Event, Sequence: 12345, Type: fftypes.EventTypeIdentityConfirmed}
+	mdi.On("GetEventByID", mock.Anything, deadLetter.Event).Return(event, nil)
+	updated := make(chan struct{})
+	mdi.On("UpdateDeadLetter", mock.Anything, deadLetter.ID, mock.Anything).Return(nil).Run(func(a mock.Arguments) {
+		close(updated)
+	})
+	deliver := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	deliver.RunFn = func(a mock.Arguments) {
+		// The failure results in a rejection
+	}
+
+	replayed, err := ed.replayDeadLetter(deadLetter)
+	assert.NoError(t, err)
+	assert.True(t, replayed)
+
+	<-updated
+	assert.Empty(t, ed.replays)
+	mdi.AssertExpectations(t)
+}
+
+func TestReplayDeadLetterNotElected(t *testing.T) {
+	ed, deadLetter, cancel := newTestReplayDispatcher()
+	defer cancel()
+	ed.elected = false
+
+	replayed, err := ed.replayDeadLetter(deadLetter)
+	assert.NoError(t, err)
+	assert.False(t, replayed)
+}
+
+func TestReplayDeadLetterGetEventFail(t *testing.T) {
+	ed, deadLetter, cancel := newTestReplayDispatcher()
+	defer cancel()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetEventByID", mock.Anything, deadLetter.Event).Return(nil, fmt.Errorf("pop"))
+
+	replayed, err := ed.replayDeadLetter(deadLetter)
+	assert.Regexp(t, "pop", err)
+	assert.True(t, replayed)
+}
+
+func TestReplayDeadLetterEventNotFound(t *testing.T) {
+	ed, deadLetter, cancel := newTestReplayDispatcher()
+	defer cancel()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetEventByID", mock.Anything, deadLetter.Event).Return(nil, nil)
+
+	replayed, err := ed.replayDeadLetter(deadLetter)
+	assert.Regexp(t, "FF10109", err)
+	assert.True(t, replayed)
+}
+
+func TestReplayDeadLetterEnrichFail(t *testing.T) {
+	ed, deadLetter, cancel := newTestReplayDispatcher()
+	defer cancel()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	event := &fftypes.Event{ID: deadLetter.Event, Type: fftypes.EventTypeBlockchainEventReceived, Reference: fftypes.NewUUID()}
+	mdi.On("GetEventByID", mock.Anything, deadLetter.Event).Return(event, nil)
+	mdi.On("GetBlockchainEventByID", mock.Anything, event.Reference).Return(nil, fmt.Errorf("pop"))
+
+	replayed, err := ed.replayDeadLetter(deadLetter)
+	assert.Regexp(t, "pop", err)
+	assert.True(t, replayed)
+}
+
+func TestReplayDeadLetterClosed(t *testing.T) {
+	ed, deadLetter, cancel := newTestReplayDispatcher()
+	cancel()
+	ed.eventDelivery = make(chan *fftypes.EventDelivery)
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	event := &fftypes.Event{ID: deadLetter.Event, Type: fftypes.EventTypeIdentityConfirmed}
+	mdi.On("GetEventByID", mock.Anything, deadLetter.Event).Return(event, nil)
+
+	replayed, err := ed.replayDeadLetter(deadLetter)
+	assert.Regexp(t, "FF10182", err)
+	assert.True(t, replayed)
+}
+
+type testBatchTransport struct {
+	*eventsmocks.PluginAll
+	*eventsmocks.BatchDeliverer
+}
+
+func newTestBatchEventDispatcher(batchOptions *events.BatchOptions) (*eventDispatcher, *eventsmocks.BatchDeliverer, func()) {
+	yes := true
+	sub := &subscription{
+		definition: &fftypes.Subscription{
+			SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+			Options: fftypes.SubscriptionOptions{
+				SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+					WithData: &yes,
+				},
+			},
+		},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	mbd := &eventsmocks.BatchDeliverer{}
+	mbd.On("BatchOptions", sub.definition).Return(batchOptions)
+	ed.transport = &testBatchTransport{
+		PluginAll:      ed.transport.(*eventsmocks.PluginAll),
+		BatchDeliverer: mbd,
+	}
+	ed.batchOptions = batchOptions
+	return ed, mbd, cancel
+}
+
+func TestBatchReadAheadRaised(t *testing.T) {
+	sub := &subscription{
+		definition: &fftypes.Subscription{
+			SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+		},
+	}
+	mei := &eventsmocks.PluginAll{}
+	mbd := &eventsmocks.BatchDeliverer{}
+	mbd.On("BatchOptions", sub.definition).Return(&events.BatchOptions{Size: 10, Timeout: 1 * time.Minute})
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ed := newEventDispatcher(ctx, &testBatchTransport{PluginAll: mei, BatchDeliverer: mbd}, mdi, mdm, &definitionsmocks.DefinitionHandlers{}, "conn1", sub, newEventNotifier(ctx, "ut"), newChangeEventListener(ctx), txcommon.NewTransactionHelper(mdi, mdm))
+
+	// Enough events are allowed in flight to fill a batch
+	assert.Equal(t, 9, ed.readAhead)
+	assert.Equal(t, 10, ed.batchOptions.Size)
+	mbd.AssertExpectations(t)
+}
+
+func TestDeliverEventsBatchFull(t *testing.T) {
+	ed, mbd, cancel := newTestBatchEventDispatcher(&events.BatchOptions{Size: 2, Timeout: 1 * time.Minute})
+	defer cancel()
+
+	data := fftypes.DataArray{{ID: fftypes.NewUUID()}}
+	mdm := ed.data.(*datamocks.Manager)
+	mdm.On("GetMessageDataCached", ed.ctx, mock.Anything).Return(data, true, nil)
+
+	delivered := make(chan []*events.EventDeliveryWithData)
+	mbd.On("BatchDeliveryRequest", ed.ctx, ed.connID, ed.subscription.definition, mock.Anything).Return(nil).Run(func(a mock.Arguments) {
+		delivered <- a[3].([]*events.EventDeliveryWithData)
+	})
+
+	go ed.deliverEvents()
+	id1 := fftypes.NewUUID()
+	id2 := fftypes.NewUUID()
+	ed.eventDelivery <- &fftypes.EventDelivery{EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{ID: id1}, Message: &fftypes.Message{}}}
+	ed.eventDelivery <- &fftypes.EventDelivery{EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{ID: id2}}}
+
+	batch := <-delivered
+	assert.Len(t, batch, 2)
+	assert.Equal(t, id1, batch[0].Event.ID)
+	assert.Equal(t, data, batch[0].Data)
+	assert.Equal(t, id2, batch[1].Event.ID)
+	assert.Nil(t, batch[1].Data)
+}
+
+func TestDeliverEventsBatchTimeoutFail(t *testing.T) {
+	ed, mbd, cancel := newTestBatchEventDispatcher(&events.BatchOptions{Size: 10, Timeout: 50 * time.Millisecond})
+	defer cancel()
+
+	mbd.On("BatchDeliveryRequest", ed.ctx, ed.connID, ed.subscription.definition, mock.MatchedBy(func(batch []*events.EventDeliveryWithData) bool {
+		return len(batch) == 2
+	})).Return(fmt.Errorf("pop"))
+
+	id1 := fftypes.NewUUID()
+	id2 := fftypes.NewUUID()
+	ed.inflight[*id1] = &fftypes.Event{ID: id1, Sequence: 1}
+	ed.inflight[*id2] = &fftypes.Event{ID: id2, Sequence: 2}
+	ed.eventDelivery = make(chan *fftypes.EventDelivery, 2)
+	ed.eventDelivery <- &fftypes.EventDelivery{EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{ID: id1}}}
+	ed.eventDelivery <- &fftypes.EventDelivery{EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{ID: id2}}}
+	go ed.deliverEvents()
+
+	// The whole batch is rejected
+	an := <-ed.acksNacks
+	assert.True(t, an.isNack)
+	assert.Equal(t, *id1, an.id)
+	assert.Regexp(t, "pop", an.info)
+	an = <-ed.acksNacks
+	assert.True(t, an.isNack)
+	assert.Equal(t, *id2, an.id)
+}
@@ -56,6 +56,9 @@ type EventManager interface {
 	ChangeEvents() chan<- *fftypes.ChangeEvent
 	DeleteDurableSubscription(ctx context.Context, subDef *fftypes.Subscription) (err error)
 	CreateUpdateDurableSubscription(ctx context.Context, subDef *fftypes.Subscription, mustNew bool) (err error)
+	ReplayDeadLetter(ctx context.Context, deadLetter *fftypes.DeadLetter) (err error)
+	ResetSubscriptionOffset(ctx context.Context, subDef *fftypes.Subscription, reset *fftypes.SubscriptionOffsetReset) (*fftypes.Offset, error)
+	GetSubscriptionStatus(ctx context.Context, subDef *fftypes.Subscription) (*fftypes.SubscriptionStatus, error)
 	Start() error
 	WaitStop()
 
@@ -63,6 +66,7 @@ type EventManager interface {
 	OperationUpdate(plugin fftypes.Named, operationID *fftypes.UUID, txState blockchain.TransactionStatus, blockchainTXID, errorMessage string, opOutput fftypes.JSONObject) error
 	BatchPinComplete(bi blockchain.Plugin, batch *blockchain.BatchPin, signingKey *fftypes.VerifierRef) error
 	BlockchainEvent(event *blockchain.EventWithSubscription) error
+	BlockchainEventRemoved(event *blockchain.Event) error
 
 	// Bound dataexchange callbacks
 	TransferResult(dx dataexchange.Plugin, trackingID string, status fftypes.OpStatus, update fftypes.TransportStatusUpdate) error
@@ -139,7 +143,7 @@ func NewEventManager(ctx context.Context, ni sysmessaging.LocalNodeInfo, si shar
 	em.internalEvents = ie.(*system.Events)
 
 	var err error
-	if em.subManager, err = newSubscriptionManager(ctx, di, dm, newEventNotifier, dh, txHelper); err != nil {
+	if em.subManager, err = newSubscriptionManager(ctx, di, dm, newEventNotifier, dh, txHelper, mm); err != nil {
 		return nil, err
 	}
 
@@ -235,6 +239,32 @@ func (em *eventManager) DeleteDurableSubscription(ctx context.Context, subDef *f
 	return em.database.DeleteSubscriptionByID(ctx, subDef.ID)
 }
 
+func (em *eventManager) ResetSubscriptionOffset(ctx context.Context, subDef *fftypes.Subscription, reset *fftypes.SubscriptionOffsetReset) (*fftypes.Offset, error) {
+	var firstOffset int64
+	var err error
+	switch {
+	case reset.FirstEvent != nil && reset.Timestamp == nil:
+		firstOffset, err = calcFirstOffset(ctx, em.database, reset.FirstEvent)
+	case reset.Timestamp != nil && reset.FirstEvent == nil:
+		firstOffset, err = calcTimestampOffset(ctx, em.database, reset.Timestamp)
+	default:
+		return nil, i18n.NewError(ctx, i18n.MsgSubscriptionResetInvalid)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return em.subManager.resetOffset(ctx, subDef.ID, firstOffset)
+}
+
+func (em *eventManager) GetSubscriptionStatus(ctx context.Context, subDef *fftypes.Subscription) (*fftypes.SubscriptionStatus, error) {
+	return em.subManager.getSubscriptionStatus(ctx, subDef)
+}
+
+func (em *eventManager) ReplayDeadLetter(ctx context.Context, deadLetter *fftypes.DeadLetter) (err error) {
+	// Replay is out-of-band to the offset of the subscription, via the active dispatcher for the subscription
+	return em.subManager.replayDeadLetter(ctx, deadLetter)
+}
+
 func (em *eventManager) AddSystemEventListener(ns string, el system.EventListener) error {
 	return em.internalEvents.AddListener(ns, el)
 }
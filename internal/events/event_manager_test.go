@@ -152,8 +152,9 @@ func TestEmitSubscriptionEventsNoops(t *testing.T) {
 	}
 
 	delOffsetCalled := make(chan bool)
-	delOffsetMock := mdi.On("DeleteOffset", mock.Anything, mock.Anything, mock.Anything).Return(nil)
-	delOffsetMock.RunFn = func(a mock.Arguments) {
+	mdi.On("DeleteOffset", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	delDeadLettersMock := mdi.On("DeleteDeadLetters", mock.Anything, mock.Anything).Return(nil)
+	delDeadLettersMock.RunFn = func(a mock.Arguments) {
 		delOffsetCalled <- true
 	}
 
@@ -368,6 +369,128 @@ func TestCreateDeleteDurableSubscriptionOk(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestReplayDeadLetterNoDispatcher(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	err := em.ReplayDeadLetter(em.ctx, &fftypes.DeadLetter{
+		Subscription: fftypes.SubscriptionRef{ID: fftypes.NewUUID()},
+	})
+	assert.Regexp(t, "FF10376", err)
+}
+
+func TestResetSubscriptionOffsetFirstEvent(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+
+	subID := fftypes.NewUUID()
+	mdi.On("UpsertOffset", mock.Anything, mock.MatchedBy(func(offset *fftypes.Offset) bool {
+		return offset.Name == subID.String() && offset.Current == -1
+	}), true).Return(nil)
+	mdi.On("GetSubscriptionByID", mock.Anything, subID).Return(&fftypes.Subscription{}, nil)
+
+	oldest := fftypes.SubOptsFirstEventOldest
+	offset, err := em.ResetSubscriptionOffset(em.ctx, &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{ID: subID},
+	}, &fftypes.SubscriptionOffsetReset{FirstEvent: &oldest})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-1), offset.Current)
+	mdi.AssertExpectations(t)
+}
+
+func TestGetSubscriptionStatusEventManager(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+
+	subID := fftypes.NewUUID()
+	mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeSubscription, subID.String()).Return(&fftypes.Offset{Current: 5}, nil)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{{Sequence: 12}}, nil, nil)
+
+	status, err := em.GetSubscriptionStatus(em.ctx, &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{ID: subID, Namespace: "ns1", Name: "sub1"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), status.Lag)
+	mdi.AssertExpectations(t)
+}
+
+func TestResetSubscriptionOffsetTimestamp(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+
+	subID := fftypes.NewUUID()
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{{Sequence: 12345}}, nil, nil)
+	mdi.On("UpsertOffset", mock.Anything, mock.MatchedBy(func(offset *fftypes.Offset) bool {
+		return offset.Name == subID.String() && offset.Current == 12345
+	}), true).Return(nil)
+	mdi.On("GetSubscriptionByID", mock.Anything, subID).Return(&fftypes.Subscription{}, nil)
+
+	offset, err := em.ResetSubscriptionOffset(em.ctx, &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{ID: subID},
+	}, &fftypes.SubscriptionOffsetReset{Timestamp: fftypes.Now()})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12345), offset.Current)
+	mdi.AssertExpectations(t)
+}
+
+func TestResetSubscriptionOffsetTimestampNoEvents(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{}, nil, nil)
+	mdi.On("UpsertOffset", mock.Anything, mock.MatchedBy(func(offset *fftypes.Offset) bool {
+		return offset.Current == -1
+	}), true).Return(nil)
+	mdi.On("GetSubscriptionByID", mock.Anything, mock.Anything).Return(&fftypes.Subscription{}, nil)
+
+	_, err := em.ResetSubscriptionOffset(em.ctx, &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID()},
+	}, &fftypes.SubscriptionOffsetReset{Timestamp: fftypes.Now()})
+	assert.NoError(t, err)
+}
+
+func TestResetSubscriptionOffsetTimestampFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := em.ResetSubscriptionOffset(em.ctx, &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID()},
+	}, &fftypes.SubscriptionOffsetReset{Timestamp: fftypes.Now()})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestResetSubscriptionOffsetBadFirstEvent(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	badFirstEvent := fftypes.SubOptsFirstEvent("!bad")
+	_, err := em.ResetSubscriptionOffset(em.ctx, &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID()},
+	}, &fftypes.SubscriptionOffsetReset{FirstEvent: &badFirstEvent})
+	assert.Regexp(t, "FF10191", err)
+}
+
+func TestResetSubscriptionOffsetInvalid(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	newest := fftypes.SubOptsFirstEventNewest
+	_, err := em.ResetSubscriptionOffset(em.ctx, &fftypes.Subscription{}, &fftypes.SubscriptionOffsetReset{
+		FirstEvent: &newest,
+		Timestamp:  fftypes.Now(),
+	})
+	assert.Regexp(t, "FF10411", err)
+
+	_, err = em.ResetSubscriptionOffset(em.ctx, &fftypes.Subscription{}, &fftypes.SubscriptionOffsetReset{})
+	assert.Regexp(t, "FF10411", err)
+}
+
 func TestAddInternalListener(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	ie := &system.Events{}
@@ -34,6 +34,7 @@ type eventPoller struct {
 	shoulderTaps    chan bool
 	eventNotifier   *eventNotifier
 	closed          chan struct{}
+	commitLoopDone  chan struct{}
 	offsetCommitted chan int64
 	offsetID        int64
 	pollingOffset   int64
@@ -69,6 +70,7 @@ func newEventPoller(ctx context.Context, di database.Plugin, en *eventNotifier,
 		offsetCommitted: make(chan int64, 1),
 		eventNotifier:   en,
 		closed:          make(chan struct{}),
+		commitLoopDone:  make(chan struct{}),
 		conf:            conf,
 	}
 	if ep.conf.maybeRewind == nil {
@@ -119,6 +121,7 @@ func (ep *eventPoller) start() {
 	if err != nil {
 		log.L(ep.ctx).Errorf("Event poller context closed before we successfully restored offset: %s", err)
 		close(ep.closed)
+		close(ep.commitLoopDone)
 		return
 	}
 	go ep.newEventNotifications()
@@ -226,6 +229,7 @@ func (ep *eventPoller) eventLoop() {
 
 func (ep *eventPoller) offsetCommitLoop() {
 	l := log.L(ep.ctx)
+	defer close(ep.commitLoopDone)
 	for range ep.offsetCommitted {
 		_ = ep.conf.retry.Do(ep.ctx, "process events", func(attempt int) (retry bool, err error) {
 			ep.mux.Lock()
@@ -62,3 +62,18 @@ func calcFirstOffset(ctx context.Context, di database.Plugin, pfe *fftypes.SubOp
 	log.L(ctx).Debugf("Event poller initial offest: %d (newest=%t)", firstOffset, useNewest)
 	return firstOffset, err
 }
+
+// calcTimestampOffset returns the offset of the last event created before the timestamp, so that delivery
+// resumes from the first event created at or after it
+func calcTimestampOffset(ctx context.Context, di database.Plugin, timestamp *fftypes.FFTime) (int64, error) {
+	fb := database.EventQueryFactory.NewFilter(ctx)
+	f := fb.And(fb.Lt("created", timestamp)).Sort("sequence").Descending().Limit(1)
+	events, _, err := di.GetEvents(ctx, f)
+	if err != nil {
+		return -1, err
+	}
+	if len(events) > 0 {
+		return events[0].Sequence, nil
+	}
+	return -1, nil
+}
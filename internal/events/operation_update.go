@@ -20,19 +20,20 @@ import (
 	"context"
 
 	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
 
-func (em *eventManager) operationUpdateCtx(ctx context.Context, operationID *fftypes.UUID, txState fftypes.OpStatus, blockchainTXID, errorMessage string, opOutput fftypes.JSONObject) error {
+func (em *eventManager) operationUpdateCtx(ctx context.Context, operationID *fftypes.UUID, txState fftypes.OpStatus, blockchainTXID, errorMessage string, opOutput fftypes.JSONObject) (announceAPI *fftypes.ContractAPI, err error) {
 	op, err := em.database.GetOperationByID(ctx, operationID)
 	if err != nil || op == nil {
 		log.L(ctx).Warnf("Operation update '%s' ignored, as it was not submitted by this node", operationID)
-		return nil
+		return nil, nil
 	}
 
 	if err := em.database.ResolveOperation(ctx, op.ID, txState, errorMessage, opOutput); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Special handling for OpTypeTokenTransfer, which writes an event when it fails
@@ -52,7 +53,7 @@ func (em *eventManager) operationUpdateCtx(ctx context.Context, operationID *fft
 			}
 		}
 		if err := em.database.InsertEvent(ctx, event); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
@@ -70,15 +71,68 @@ func (em *eventManager) operationUpdateCtx(ctx context.Context, operationID *fft
 			event.Correlator = tokenApproval.LocalID
 		}
 		if err := em.database.InsertEvent(ctx, event); err != nil {
-			return err
+			return nil, err
+		}
+	}
+
+	// Special handling for OpTypeBlockchainContractDeploy, which can request a contract API to be registered
+	// at the location of the new contract once it succeeds
+	if op.Type == fftypes.OpTypeBlockchainContractDeploy && txState == fftypes.OpStatusSucceeded {
+		if announceAPI, err = em.deployedContractAPI(ctx, op, opOutput); err != nil {
+			return nil, err
 		}
 	}
 
-	return em.txHelper.AddBlockchainTX(ctx, op.Transaction, blockchainTXID)
+	return announceAPI, em.txHelper.AddBlockchainTX(ctx, op.Transaction, blockchainTXID)
+}
+
+// deployedContractAPI returns the contract API to announce for a successful deployment. The update might be
+// redelivered after the announcement has already been made, so nothing is returned if the API already exists.
+func (em *eventManager) deployedContractAPI(ctx context.Context, op *fftypes.Operation, opOutput fftypes.JSONObject) (*fftypes.ContractAPI, error) {
+	req, err := txcommon.RetrieveContractDeployInputs(ctx, op)
+	if err != nil {
+		log.L(ctx).Warnf("Could not parse contract deployment: %s", err)
+		return nil, nil
+	}
+	if req.API == "" || req.Interface == nil {
+		return nil, nil
+	}
+	location, ok := opOutput.GetObjectOk("location")
+	if !ok || len(location) == 0 {
+		log.L(ctx).Warnf("Unable to register contract API '%s' - no location for the deployed contract in operation %s", req.API, op.ID)
+		return nil, nil
+	}
+	existing, err := em.database.GetContractAPIByName(ctx, op.Namespace, req.API)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		log.L(ctx).Infof("Contract API '%s' already exists - not announcing for deployed contract in operation %s", req.API, op.ID)
+		return nil, nil
+	}
+	return &fftypes.ContractAPI{
+		ID:        fftypes.NewUUID(),
+		Namespace: op.Namespace,
+		Name:      req.API,
+		Interface: req.Interface,
+		Location:  fftypes.JSONAnyPtr(location.String()),
+	}, nil
 }
 
-func (em *eventManager) OperationUpdate(plugin fftypes.Named, operationID *fftypes.UUID, txState fftypes.OpStatus, blockchainTXID, errorMessage string, opOutput fftypes.JSONObject) error {
-	return em.database.RunAsGroup(em.ctx, func(ctx context.Context) error {
-		return em.operationUpdateCtx(ctx, operationID, txState, blockchainTXID, errorMessage, opOutput)
+func (em *eventManager) OperationUpdate(plugin fftypes.Named, operationID *fftypes.UUID, txState fftypes.OpStatus, blockchainTXID, errorMessage string, opOutput fftypes.JSONObject) (err error) {
+	// Linked to the span that submitted the operation
+	ctx, span := tracing.StartSpan(em.ctx, "operation_update", []*fftypes.UUID{operationID}, tracing.OperationID(operationID), tracing.OperationStatus(txState))
+	defer func() { tracing.EndSpan(span, err) }()
+	var announceAPI *fftypes.ContractAPI
+	err = em.database.RunAsGroup(ctx, func(ctx context.Context) (err error) {
+		announceAPI, err = em.operationUpdateCtx(ctx, operationID, txState, blockchainTXID, errorMessage, opOutput)
+		return err
 	})
+
+	// Announce the contract API for a newly deployed contract
+	if err == nil && announceAPI != nil {
+		log.L(ctx).Infof("Announcing contract API '%s' for deployed contract, id=%s", announceAPI.Name, announceAPI.ID)
+		_, err = em.broadcast.BroadcastDefinitionAsNode(ctx, announceAPI.Namespace, announceAPI, fftypes.SystemTagDefineContractAPI, false)
+	}
+	return err
 }
@@ -21,7 +21,9 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/broadcastmocks"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/txcommonmocks"
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -63,7 +65,7 @@ func TestOperationUpdateNotFound(t *testing.T) {
 	mdi.On("GetOperationByID", em.ctx, opID).Return(nil, fmt.Errorf("pop"))
 
 	info := fftypes.JSONObject{"some": "info"}
-	err := em.operationUpdateCtx(em.ctx, opID, fftypes.OpStatusFailed, "", "some error", info)
+	_, err := em.operationUpdateCtx(em.ctx, opID, fftypes.OpStatusFailed, "", "some error", info)
 	assert.NoError(t, err) // swallowed after logging
 
 	mdi.AssertExpectations(t)
@@ -82,7 +84,7 @@ func TestOperationUpdateError(t *testing.T) {
 	mdi.On("GetOperationByID", em.ctx, opID).Return(&fftypes.Operation{ID: opID, Transaction: txid}, nil)
 	mdi.On("ResolveOperation", mock.Anything, opID, fftypes.OpStatusFailed, "some error", info).Return(fmt.Errorf("pop"))
 
-	err := em.operationUpdateCtx(em.ctx, opID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	_, err := em.operationUpdateCtx(em.ctx, opID, fftypes.OpStatusFailed, "0x12345", "some error", info)
 	assert.EqualError(t, err, "pop")
 
 	mdi.AssertExpectations(t)
@@ -103,7 +105,7 @@ func TestOperationTXUpdateError(t *testing.T) {
 	mdi.On("ResolveOperation", mock.Anything, opID, fftypes.OpStatusFailed, "some error", info).Return(nil)
 	mth.On("AddBlockchainTX", mock.Anything, txid, "0x12345").Return(fmt.Errorf("pop"))
 
-	err := em.operationUpdateCtx(em.ctx, opID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	_, err := em.operationUpdateCtx(em.ctx, opID, fftypes.OpStatusFailed, "0x12345", "some error", info)
 	assert.EqualError(t, err, "pop")
 
 	mdi.AssertExpectations(t)
@@ -132,7 +134,7 @@ func TestOperationUpdateTransferFailBadData(t *testing.T) {
 	})).Return(nil)
 	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0x12345").Return(nil)
 
-	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	_, err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
 	assert.NoError(t, err)
 
 	mdi.AssertExpectations(t)
@@ -166,7 +168,7 @@ func TestOperationUpdateTransferFail(t *testing.T) {
 	})).Return(nil)
 	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0x12345").Return(nil)
 
-	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	_, err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
 	assert.NoError(t, err)
 
 	mdi.AssertExpectations(t)
@@ -193,7 +195,7 @@ func TestOperationUpdateTransferTransactionFail(t *testing.T) {
 	mdi.On("InsertEvent", em.ctx, mock.Anything).Return(nil)
 	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0x12345").Return(fmt.Errorf("pop"))
 
-	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	_, err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
 	assert.EqualError(t, err, "pop")
 
 	mdi.AssertExpectations(t)
@@ -219,7 +221,7 @@ func TestOperationUpdateTransferEventFail(t *testing.T) {
 		return e.Type == fftypes.EventTypeTransferOpFailed && e.Namespace == "ns1"
 	})).Return(fmt.Errorf("pop"))
 
-	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	_, err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
 	assert.EqualError(t, err, "pop")
 
 	mdi.AssertExpectations(t)
@@ -248,7 +250,7 @@ func TestOperationUpdateApprovalFailBadInput(t *testing.T) {
 	})).Return(nil)
 	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0x12345").Return(nil)
 
-	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	_, err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
 	assert.NoError(t, err)
 
 	mdi.AssertExpectations(t)
@@ -281,7 +283,7 @@ func TestOperationUpdateApprovalFail(t *testing.T) {
 	})).Return(nil)
 	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0x12345").Return(nil)
 
-	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	_, err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
 	assert.NoError(t, err)
 
 	mdi.AssertExpectations(t)
@@ -307,7 +309,7 @@ func TestOperationUpdateApprovalTransactionFail(t *testing.T) {
 	mdi.On("InsertEvent", em.ctx, mock.Anything).Return(nil)
 	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0x12345").Return(fmt.Errorf("pop"))
 
-	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	_, err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
 	assert.EqualError(t, err, "pop")
 
 	mdi.AssertExpectations(t)
@@ -333,9 +335,187 @@ func TestOperationUpdateApprovalEventFail(t *testing.T) {
 		return e.Type == fftypes.EventTypeApprovalOpFailed && e.Namespace == "ns1"
 	})).Return(fmt.Errorf("pop"))
 
-	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	_, err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
 	assert.EqualError(t, err, "pop")
 
 	mdi.AssertExpectations(t)
 	mbi.AssertExpectations(t)
 }
+
+func newTestDeployOperation(t *testing.T, req *fftypes.ContractDeployRequest) *fftypes.Operation {
+	op := &fftypes.Operation{
+		ID:          fftypes.NewUUID(),
+		Namespace:   "ns1",
+		Type:        fftypes.OpTypeBlockchainContractDeploy,
+		Transaction: fftypes.NewUUID(),
+	}
+	err := txcommon.AddContractDeployInputs(op, req)
+	assert.NoError(t, err)
+	return op
+}
+
+func TestOperationUpdateDeployAnnounceAPI(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+	mbm := em.broadcast.(*broadcastmocks.Manager)
+
+	ffiID := fftypes.NewUUID()
+	op := newTestDeployOperation(t, &fftypes.ContractDeployRequest{
+		Interface: &fftypes.FFIReference{ID: ffiID},
+		API:       "simple",
+	})
+	info := fftypes.JSONObject{"location": fftypes.JSONObject{"address": "0x12345"}}
+
+	mdi.On("RunAsGroup", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args[1].(func(ctx context.Context) error)(args[0].(context.Context))
+	}).Return(nil)
+	mdi.On("GetOperationByID", mock.Anything, op.ID).Return(op, nil)
+	mdi.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusSucceeded, "", info).Return(nil)
+	mdi.On("GetContractAPIByName", mock.Anything, "ns1", "simple").Return(nil, nil)
+	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0xffffeeee").Return(nil)
+	mbm.On("BroadcastDefinitionAsNode", mock.Anything, "ns1", mock.MatchedBy(func(api *fftypes.ContractAPI) bool {
+		return api.Name == "simple" && api.Interface.ID.Equals(ffiID) && api.Location.JSONObject().GetString("address") == "0x12345"
+	}), fftypes.SystemTagDefineContractAPI, false).Return(&fftypes.Message{}, nil)
+
+	err := em.OperationUpdate(mdi, op.ID, fftypes.OpStatusSucceeded, "0xffffeeee", "", info)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+	mbm.AssertExpectations(t)
+}
+
+func TestOperationUpdateDeployAnnounceAPIFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+	mbm := em.broadcast.(*broadcastmocks.Manager)
+
+	op := newTestDeployOperation(t, &fftypes.ContractDeployRequest{
+		Interface: &fftypes.FFIReference{ID: fftypes.NewUUID()},
+		API:       "simple",
+	})
+	info := fftypes.JSONObject{"location": fftypes.JSONObject{"address": "0x12345"}}
+
+	mdi.On("RunAsGroup", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args[1].(func(ctx context.Context) error)(args[0].(context.Context))
+	}).Return(nil)
+	mdi.On("GetOperationByID", mock.Anything, op.ID).Return(op, nil)
+	mdi.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusSucceeded, "", info).Return(nil)
+	mdi.On("GetContractAPIByName", mock.Anything, "ns1", "simple").Return(nil, nil)
+	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0xffffeeee").Return(nil)
+	mbm.On("BroadcastDefinitionAsNode", mock.Anything, "ns1", mock.Anything, fftypes.SystemTagDefineContractAPI, false).Return(nil, fmt.Errorf("pop"))
+
+	err := em.OperationUpdate(mdi, op.ID, fftypes.OpStatusSucceeded, "0xffffeeee", "", info)
+	assert.EqualError(t, err, "pop")
+
+	mbm.AssertExpectations(t)
+}
+
+func TestOperationUpdateDeployAPIExists(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+
+	op := newTestDeployOperation(t, &fftypes.ContractDeployRequest{
+		Interface: &fftypes.FFIReference{ID: fftypes.NewUUID()},
+		API:       "simple",
+	})
+	info := fftypes.JSONObject{"location": fftypes.JSONObject{"address": "0x12345"}}
+
+	mdi.On("GetOperationByID", mock.Anything, op.ID).Return(op, nil)
+	mdi.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusSucceeded, "", info).Return(nil)
+	mdi.On("GetContractAPIByName", mock.Anything, "ns1", "simple").Return(&fftypes.ContractAPI{}, nil)
+	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0xffffeeee").Return(nil)
+
+	api, err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusSucceeded, "0xffffeeee", "", info)
+	assert.NoError(t, err)
+	assert.Nil(t, api)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestOperationUpdateDeployAPILookupFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+
+	op := newTestDeployOperation(t, &fftypes.ContractDeployRequest{
+		Interface: &fftypes.FFIReference{ID: fftypes.NewUUID()},
+		API:       "simple",
+	})
+	info := fftypes.JSONObject{"location": fftypes.JSONObject{"address": "0x12345"}}
+
+	mdi.On("GetOperationByID", mock.Anything, op.ID).Return(op, nil)
+	mdi.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusSucceeded, "", info).Return(nil)
+	mdi.On("GetContractAPIByName", mock.Anything, "ns1", "simple").Return(nil, fmt.Errorf("pop"))
+
+	_, err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusSucceeded, "0xffffeeee", "", info)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestOperationUpdateDeployNoAPI(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+
+	op := newTestDeployOperation(t, &fftypes.ContractDeployRequest{})
+	info := fftypes.JSONObject{"location": fftypes.JSONObject{"address": "0x12345"}}
+
+	mdi.On("GetOperationByID", mock.Anything, op.ID).Return(op, nil)
+	mdi.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusSucceeded, "", info).Return(nil)
+	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0xffffeeee").Return(nil)
+
+	api, err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusSucceeded, "0xffffeeee", "", info)
+	assert.NoError(t, err)
+	assert.Nil(t, api)
+}
+
+func TestOperationUpdateDeployNoLocation(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+
+	op := newTestDeployOperation(t, &fftypes.ContractDeployRequest{
+		Interface: &fftypes.FFIReference{ID: fftypes.NewUUID()},
+		API:       "simple",
+	})
+	info := fftypes.JSONObject{}
+
+	mdi.On("GetOperationByID", mock.Anything, op.ID).Return(op, nil)
+	mdi.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusSucceeded, "", info).Return(nil)
+	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0xffffeeee").Return(nil)
+
+	api, err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusSucceeded, "0xffffeeee", "", info)
+	assert.NoError(t, err)
+	assert.Nil(t, api)
+}
+
+func TestOperationUpdateDeployBadInput(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+
+	op := &fftypes.Operation{
+		ID:          fftypes.NewUUID(),
+		Type:        fftypes.OpTypeBlockchainContractDeploy,
+		Transaction: fftypes.NewUUID(),
+		Input:       fftypes.JSONObject{"input": "bad"},
+	}
+	info := fftypes.JSONObject{}
+
+	mdi.On("GetOperationByID", mock.Anything, op.ID).Return(op, nil)
+	mdi.On("ResolveOperation", mock.Anything, op.ID, fftypes.OpStatusSucceeded, "", info).Return(nil)
+	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0xffffeeee").Return(nil)
+
+	api, err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusSucceeded, "0xffffeeee", "", info)
+	assert.NoError(t, err)
+	assert.Nil(t, api)
+}
@@ -0,0 +1,240 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/auth"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/events"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// SSE is an event transport that streams events to the client as a text/event-stream
+// over a long-lived HTTP GET. As the stream is one-way, acknowledgements are either
+// automatic, or are sent as separate HTTP POST requests that reference the connection.
+type SSE struct {
+	ctx               context.Context
+	capabilities      *events.Capabilities
+	callbacks         events.Callbacks
+	connections       map[string]*sseConnection
+	connMux           sync.Mutex
+	keepAliveInterval time.Duration
+}
+
+// sseAckPayload is the body of a POST to acknowledge an event delivered on a stream
+type sseAckPayload struct {
+	Connection   string                   `json:"connection"`
+	ID           *fftypes.UUID            `json:"id,omitempty"`
+	Subscription *fftypes.SubscriptionRef `json:"subscription,omitempty"`
+}
+
+func (s *SSE) Name() string { return "sse" }
+
+func (s *SSE) Init(ctx context.Context, prefix config.Prefix, callbacks events.Callbacks) error {
+	*s = SSE{
+		ctx:               ctx,
+		connections:       make(map[string]*sseConnection),
+		capabilities:      &events.Capabilities{},
+		callbacks:         callbacks,
+		keepAliveInterval: prefix.GetDuration(KeepAliveInterval),
+	}
+	return nil
+}
+
+func (s *SSE) Capabilities() *events.Capabilities {
+	return s.capabilities
+}
+
+func (s *SSE) GetOptionsSchema(ctx context.Context) string {
+	return `{}` // no extra options currently
+}
+
+func (s *SSE) ValidateOptions(options *fftypes.SubscriptionOptions) error {
+	// As with websockets, we only stream the references
+	if options.WithData != nil && *options.WithData {
+		return i18n.NewError(s.ctx, i18n.MsgSSENoData)
+	}
+	forceFalse := false
+	options.WithData = &forceFalse
+	return nil
+}
+
+func (s *SSE) DeliveryRequest(ctx context.Context, connID string, sub *fftypes.Subscription, event *fftypes.EventDelivery, data fftypes.DataArray) error {
+	conn := s.getConnection(connID)
+	if conn == nil {
+		return i18n.NewError(s.ctx, i18n.MsgSSEConnectionNotActive, connID)
+	}
+	return conn.dispatch(event)
+}
+
+func (s *SSE) ChangeEvent(connID string, ce *fftypes.ChangeEvent) {
+	// Change events are not supported
+}
+
+// ServeHTTP handles both the GET that opens the stream, and the POST used to acknowledge events
+func (s *SSE) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodPost {
+		s.handleAck(res, req)
+		return
+	}
+	s.handleStream(res, req)
+}
+
+func (s *SSE) handleStream(res http.ResponseWriter, req *http.Request) {
+	flusher, ok := res.(http.Flusher)
+	if !ok {
+		s.replyError(res, http.StatusInternalServerError, i18n.NewError(req.Context(), i18n.MsgSSEStreamingUnsupported))
+		return
+	}
+
+	query := req.URL.Query()
+	ephemeral, hasEphemeral := query["ephemeral"]
+	isEphemeral := hasEphemeral && (len(ephemeral) == 0 || ephemeral[0] != "false")
+	autoAck, hasAutoack := query["autoack"]
+	isAutoack := hasAutoack && (len(autoAck) == 0 || autoAck[0] != "false")
+	namespace := query.Get("namespace")
+	name := query.Get("name")
+	if namespace == "" || (!isEphemeral && name == "") {
+		s.replyError(res, http.StatusBadRequest, i18n.NewError(req.Context(), i18n.MsgSSEInvalidStart))
+		return
+	}
+
+	// When authentication is enabled on the API server, the stream can only listen on namespaces it can read
+	if principal := auth.GetPrincipal(req.Context()); principal != nil {
+		if err := principal.Authorize(req.Context(), namespace, auth.RoleRead); err != nil {
+			s.replyError(res, http.StatusForbidden, err)
+			return
+		}
+	}
+
+	// The browser sends the id of the last event it received when it reconnects. For an ephemeral
+	// subscription we use that to resume after it, while durable subscriptions have their own offset.
+	var options fftypes.SubscriptionOptions
+	if lastEventID := req.Header.Get("Last-Event-ID"); lastEventID != "" {
+		if _, err := strconv.ParseInt(lastEventID, 10, 64); err != nil {
+			s.replyError(res, http.StatusBadRequest, i18n.NewError(req.Context(), i18n.MsgSSEInvalidLastEventID, lastEventID))
+			return
+		}
+		firstEvent := fftypes.SubOptsFirstEvent(lastEventID)
+		options.FirstEvent = &firstEvent
+	}
+
+	sc := newConnection(s.ctx, req.Context(), s, namespace, name, isAutoack)
+	defer close(sc.done)
+	s.connMux.Lock()
+	s.connections[sc.connID] = sc
+	s.connMux.Unlock()
+
+	var err error
+	if isEphemeral {
+		filter := fftypes.NewSubscriptionFilterFromQuery(query)
+		err = s.callbacks.EphemeralSubscription(sc.connID, namespace, &filter, &options)
+	} else {
+		err = s.callbacks.RegisterConnection(sc.connID, sc.durableSubMatcher)
+	}
+	if err != nil {
+		sc.close()
+		s.replyError(res, http.StatusBadRequest, err)
+		return
+	}
+
+	res.Header().Set("Content-Type", "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	res.WriteHeader(http.StatusOK)
+	sc.sendLoop(res, flusher)
+}
+
+func (s *SSE) handleAck(res http.ResponseWriter, req *http.Request) {
+	var ack sseAckPayload
+	if err := json.NewDecoder(req.Body).Decode(&ack); err != nil {
+		s.replyError(res, http.StatusBadRequest, i18n.WrapError(req.Context(), err, i18n.MsgWSClientSentInvalidData))
+		return
+	}
+
+	sc := s.getConnection(ack.Connection)
+	if sc == nil {
+		s.replyError(res, http.StatusNotFound, i18n.NewError(req.Context(), i18n.MsgSSEConnectionNotActive, ack.Connection))
+		return
+	}
+
+	// The caller must be able to read the namespace of the stream it is acknowledging
+	if principal := auth.GetPrincipal(req.Context()); principal != nil {
+		if err := principal.Authorize(req.Context(), sc.namespace, auth.RoleRead); err != nil {
+			s.replyError(res, http.StatusForbidden, err)
+			return
+		}
+	}
+
+	inflight, err := sc.checkAck(&ack)
+	if err != nil {
+		s.replyError(res, http.StatusBadRequest, err)
+		return
+	}
+
+	// Deliver the ack to the core, now we're unlocked
+	s.ack(sc.connID, inflight)
+	res.WriteHeader(http.StatusNoContent)
+}
+
+func (s *SSE) replyError(res http.ResponseWriter, status int, err error) {
+	log.L(s.ctx).Errorf("Server-Sent Events request failed: %s", err)
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(status)
+	_ = json.NewEncoder(res).Encode(&fftypes.RESTError{
+		Error: err.Error(),
+	})
+}
+
+func (s *SSE) getConnection(connID string) *sseConnection {
+	s.connMux.Lock()
+	defer s.connMux.Unlock()
+	return s.connections[connID]
+}
+
+func (s *SSE) ack(connID string, inflight *fftypes.EventDeliveryResponse) {
+	s.callbacks.DeliveryResponse(connID, inflight)
+}
+
+func (s *SSE) connClosed(connID string) {
+	s.connMux.Lock()
+	delete(s.connections, connID)
+	s.connMux.Unlock()
+	// Drop lock before calling back
+	s.callbacks.ConnnectionClosed(connID)
+}
+
+func (s *SSE) WaitClosed() {
+	closedConnections := []*sseConnection{}
+	s.connMux.Lock()
+	for _, sc := range s.connections {
+		closedConnections = append(closedConnections, sc)
+	}
+	s.connMux.Unlock()
+	for _, sc := range closedConnections {
+		sc.waitClose()
+	}
+}
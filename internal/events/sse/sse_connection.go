@@ -0,0 +1,207 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// sseConnectedPayload is sent as the first event on every stream
+type sseConnectedPayload struct {
+	Connection string `json:"connection"`
+}
+
+type sseConnection struct {
+	ctx          context.Context
+	cancelCtx    func()
+	reqDone      <-chan struct{}
+	sse          *SSE
+	connID       string
+	namespace    string
+	name         string
+	autoAck      bool
+	sendMessages chan *fftypes.EventDelivery
+	done         chan struct{}
+	inflight     []*fftypes.EventDeliveryResponse
+	mux          sync.Mutex
+	closed       bool
+}
+
+func newConnection(pCtx, reqCtx context.Context, s *SSE, namespace, name string, autoAck bool) *sseConnection {
+	connID := fftypes.NewUUID().String()
+	ctx := log.WithLogField(pCtx, "sse", connID)
+	ctx, cancelCtx := context.WithCancel(ctx)
+	return &sseConnection{
+		ctx:          ctx,
+		cancelCtx:    cancelCtx,
+		reqDone:      reqCtx.Done(),
+		sse:          s,
+		connID:       connID,
+		namespace:    namespace,
+		name:         name,
+		autoAck:      autoAck,
+		sendMessages: make(chan *fftypes.EventDelivery),
+		done:         make(chan struct{}),
+	}
+}
+
+// sendLoop runs on the goroutine of the HTTP handler, until the client disconnects or we shut down
+func (sc *sseConnection) sendLoop(res http.ResponseWriter, flusher http.Flusher) {
+	l := log.L(sc.ctx)
+	defer sc.close()
+
+	var keepAlive <-chan time.Time
+	if sc.sse.keepAliveInterval > 0 {
+		ticker := time.NewTicker(sc.sse.keepAliveInterval)
+		defer ticker.Stop()
+		keepAlive = ticker.C
+	}
+
+	// The first event tells the client the connection to reference when acknowledging events
+	err := sc.write(res, flusher, "", "connected", &sseConnectedPayload{Connection: sc.connID})
+	for err == nil {
+		select {
+		case event := <-sc.sendMessages:
+			l.Tracef("Sending: %+v", event)
+			// The id is the event sequence, which the client sends back as Last-Event-ID when it reconnects
+			err = sc.write(res, flusher, strconv.FormatInt(event.Sequence, 10), "", event)
+		case <-keepAlive:
+			if _, err = res.Write([]byte(": keepalive\n\n")); err == nil {
+				flusher.Flush()
+			}
+		case <-sc.reqDone:
+			l.Debugf("Sender closing - client disconnected")
+			return
+		case <-sc.ctx.Done():
+			l.Debugf("Sender closing - context cancelled")
+			return
+		}
+	}
+	l.Errorf("Write failed on stream: %s", err)
+}
+
+func (sc *sseConnection) write(res http.ResponseWriter, flusher http.Flusher, id, eventType string, payload interface{}) error {
+	b, _ := json.Marshal(payload)
+	var buff bytes.Buffer
+	if id != "" {
+		fmt.Fprintf(&buff, "id: %s\n", id)
+	}
+	if eventType != "" {
+		fmt.Fprintf(&buff, "event: %s\n", eventType)
+	}
+	fmt.Fprintf(&buff, "data: %s\n\n", b)
+	if _, err := res.Write(buff.Bytes()); err != nil {
+		return err
+	}
+	flusher.Flush()
+	return nil
+}
+
+func (sc *sseConnection) dispatch(event *fftypes.EventDelivery) error {
+	inflight := &fftypes.EventDeliveryResponse{
+		ID:           event.ID,
+		Subscription: event.Subscription,
+	}
+
+	if !sc.autoAck {
+		sc.mux.Lock()
+		sc.inflight = append(sc.inflight, inflight)
+		sc.mux.Unlock()
+	}
+
+	select {
+	case sc.sendMessages <- event:
+	case <-sc.ctx.Done():
+		return i18n.NewError(sc.ctx, i18n.MsgSSEConnectionNotActive, sc.connID)
+	}
+
+	if sc.autoAck {
+		sc.sse.ack(sc.connID, inflight)
+	}
+	return nil
+}
+
+func (sc *sseConnection) durableSubMatcher(sr fftypes.SubscriptionRef) bool {
+	return sr.Namespace == sc.namespace && sr.Name == sc.name
+}
+
+func (sc *sseConnection) checkAck(ack *sseAckPayload) (*fftypes.EventDeliveryResponse, error) {
+	var inflight *fftypes.EventDeliveryResponse
+	sc.mux.Lock()
+	defer sc.mux.Unlock()
+
+	if sc.autoAck {
+		return nil, i18n.NewError(sc.ctx, i18n.MsgWSAutoAckEnabled)
+	}
+
+	if ack.ID != nil {
+		newInflight := make([]*fftypes.EventDeliveryResponse, 0, len(sc.inflight))
+		for _, candidate := range sc.inflight {
+			match := inflight == nil && *candidate.ID == *ack.ID
+			if match && ack.Subscription != nil {
+				// A subscription has been explicitly specified, so it must match
+				match = (ack.Subscription.ID != nil && *ack.Subscription.ID == *candidate.Subscription.ID) ||
+					(ack.Subscription.Name == candidate.Subscription.Name && ack.Subscription.Namespace == candidate.Subscription.Namespace)
+			}
+			// Remove from the inflight list
+			if match {
+				inflight = candidate
+			} else {
+				newInflight = append(newInflight, candidate)
+			}
+		}
+		sc.inflight = newInflight
+	} else if len(sc.inflight) > 0 {
+		// Just ack the front of the queue
+		inflight = sc.inflight[0]
+		sc.inflight = sc.inflight[1:]
+	}
+	if inflight == nil {
+		return nil, i18n.NewError(sc.ctx, i18n.MsgWSMsgSubNotMatched)
+	}
+	return inflight, nil
+}
+
+func (sc *sseConnection) close() {
+	var didClose bool
+	sc.mux.Lock()
+	if !sc.closed {
+		didClose = true
+		sc.closed = true
+		sc.cancelCtx()
+	}
+	sc.mux.Unlock()
+	// Drop lock before callback
+	if didClose {
+		sc.sse.connClosed(sc.connID)
+	}
+}
+
+func (sc *sseConnection) waitClose() {
+	<-sc.done
+}
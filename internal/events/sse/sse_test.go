@@ -0,0 +1,479 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sse
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/auth"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/pkg/events"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestSSE(t *testing.T, cbs *eventsmocks.Callbacks) (s *SSE, url string, cancel func()) {
+	config.Reset()
+
+	s = &SSE{}
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	svrPrefix := config.NewPluginConfig("ut.sse")
+	s.InitPrefix(svrPrefix)
+	s.Init(ctx, svrPrefix, cbs)
+	assert.Equal(t, "sse", s.Name())
+	assert.NotNil(t, s.Capabilities())
+	assert.NotNil(t, s.GetOptionsSchema(context.Background()))
+	cbs.On("ConnnectionClosed", mock.Anything).Return(nil).Maybe()
+
+	svr := httptest.NewServer(s)
+
+	var si interface{} = s
+	_, ok := si.(events.PluginAll)
+	assert.True(t, ok)
+
+	return s, fmt.Sprintf("http://%s", svr.Listener.Addr()), func() {
+		cancelCtx()
+		s.WaitClosed()
+		svr.Close()
+	}
+}
+
+func openStream(t *testing.T, url string, headers map[string]string) (*http.Response, *bufio.Reader) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	assert.NoError(t, err)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	return res, bufio.NewReader(res.Body)
+}
+
+func readEvent(t *testing.T, r *bufio.Reader) map[string]string {
+	fields := map[string]string{}
+	for {
+		line, err := r.ReadString('\n')
+		assert.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return fields
+		}
+		if strings.HasPrefix(line, ":") {
+			fields["comment"] = strings.TrimSpace(line[1:])
+			continue
+		}
+		kv := strings.SplitN(line, ": ", 2)
+		fields[kv[0]] = kv[1]
+	}
+}
+
+func readConnID(t *testing.T, r *bufio.Reader) string {
+	connected := readEvent(t, r)
+	assert.Equal(t, "connected", connected["event"])
+	var payload sseConnectedPayload
+	err := json.Unmarshal([]byte(connected["data"]), &payload)
+	assert.NoError(t, err)
+	return payload.Connection
+}
+
+func postAck(t *testing.T, url, body string) *http.Response {
+	res, err := http.Post(url, "application/json", strings.NewReader(body))
+	assert.NoError(t, err)
+	return res
+}
+
+type noFlushWriter struct {
+	http.ResponseWriter
+}
+
+type failingWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (fw *failingWriter) Write(b []byte) (int, error) {
+	return 0, fmt.Errorf("pop")
+}
+
+func TestValidateOptionsFail(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, _, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	yes := true
+	err := s.ValidateOptions(&fftypes.SubscriptionOptions{
+		SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+			WithData: &yes,
+		},
+	})
+	assert.Regexp(t, "FF10406", err)
+}
+
+func TestValidateOptionsOk(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, _, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	opts := &fftypes.SubscriptionOptions{}
+	err := s.ValidateOptions(opts)
+	assert.NoError(t, err)
+	assert.False(t, *opts.WithData)
+}
+
+func TestStreamReceiveAckEphemeral(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, url, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	cbs.On("EphemeralSubscription", mock.Anything, "ns1",
+		mock.MatchedBy(func(f *fftypes.SubscriptionFilter) bool { return f.Topic == "topic1" }),
+		mock.MatchedBy(func(o *fftypes.SubscriptionOptions) bool { return *o.FirstEvent == "10" }),
+	).Return(nil)
+	acked := make(chan *fftypes.EventDeliveryResponse, 1)
+	cbs.On("DeliveryResponse", mock.Anything, mock.Anything).Run(func(a mock.Arguments) {
+		acked <- a[1].(*fftypes.EventDeliveryResponse)
+	}).Return(nil)
+
+	res, r := openStream(t, url+"?namespace=ns1&ephemeral&filter.topic=topic1", map[string]string{
+		"Last-Event-ID": "10",
+	})
+	defer res.Body.Close()
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+	connID := readConnID(t, r)
+
+	eventID := fftypes.NewUUID()
+	subID := fftypes.NewUUID()
+	go func() {
+		err := s.DeliveryRequest(context.Background(), connID, nil, &fftypes.EventDelivery{
+			EnrichedEvent: fftypes.EnrichedEvent{
+				Event: fftypes.Event{ID: eventID, Sequence: 11},
+			},
+			Subscription: fftypes.SubscriptionRef{ID: subID},
+		}, nil)
+		assert.NoError(t, err)
+	}()
+
+	delivered := readEvent(t, r)
+	assert.Equal(t, "11", delivered["id"])
+	var event fftypes.EventDelivery
+	err := json.Unmarshal([]byte(delivered["data"]), &event)
+	assert.NoError(t, err)
+	assert.Equal(t, *eventID, *event.ID)
+
+	ackRes := postAck(t, url, fmt.Sprintf(`{"connection":"%s","id":"%s","subscription":{"id":"%s"}}`, connID, eventID, subID))
+	assert.Equal(t, 204, ackRes.StatusCode)
+	inflight := <-acked
+	assert.Equal(t, *eventID, *inflight.ID)
+
+	cbs.AssertExpectations(t)
+}
+
+func TestStreamAutoAckDurable(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, url, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	cbs.On("RegisterConnection", mock.Anything, mock.MatchedBy(func(matcher events.SubscriptionMatcher) bool {
+		return matcher(fftypes.SubscriptionRef{Namespace: "ns1", Name: "sub1"}) &&
+			!matcher(fftypes.SubscriptionRef{Namespace: "ns1", Name: "sub2"})
+	})).Return(nil)
+	acked := make(chan struct{})
+	cbs.On("DeliveryResponse", mock.Anything, mock.Anything).Run(func(a mock.Arguments) {
+		close(acked)
+	}).Return(nil)
+
+	res, r := openStream(t, url+"?namespace=ns1&name=sub1&autoack", nil)
+	defer res.Body.Close()
+	assert.Equal(t, 200, res.StatusCode)
+	connID := readConnID(t, r)
+
+	go func() {
+		err := s.DeliveryRequest(context.Background(), connID, nil, &fftypes.EventDelivery{
+			EnrichedEvent: fftypes.EnrichedEvent{
+				Event: fftypes.Event{ID: fftypes.NewUUID(), Sequence: 1},
+			},
+			Subscription: fftypes.SubscriptionRef{Namespace: "ns1", Name: "sub1"},
+		}, nil)
+		assert.NoError(t, err)
+	}()
+
+	delivered := readEvent(t, r)
+	assert.Equal(t, "1", delivered["id"])
+	<-acked
+
+	// Manual acks are rejected when autoack is enabled
+	ackRes := postAck(t, url, fmt.Sprintf(`{"connection":"%s"}`, connID))
+	assert.Equal(t, 400, ackRes.StatusCode)
+
+	cbs.AssertExpectations(t)
+}
+
+func TestStreamKeepAlive(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, url, cancel := newTestSSE(t, cbs)
+	defer cancel()
+	s.keepAliveInterval = 1 * time.Millisecond
+
+	cbs.On("EphemeralSubscription", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(nil)
+
+	res, r := openStream(t, url+"?namespace=ns1&ephemeral", nil)
+	defer res.Body.Close()
+	readConnID(t, r)
+	keepAlive := readEvent(t, r)
+	assert.Equal(t, "keepalive", keepAlive["comment"])
+}
+
+func TestStreamClientDisconnect(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, url, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	cbs.On("EphemeralSubscription", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(nil)
+
+	res, r := openStream(t, url+"?namespace=ns1&ephemeral", nil)
+	connID := readConnID(t, r)
+	res.Body.Close()
+	assert.Eventually(t, func() bool { return s.getConnection(connID) == nil }, 5*time.Second, 1*time.Millisecond)
+}
+
+func TestStreamBadStart(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	_, url, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	res, _ := openStream(t, url+"?namespace=ns1", nil)
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+	var resErr fftypes.RESTError
+	json.NewDecoder(res.Body).Decode(&resErr)
+	assert.Regexp(t, "FF10407", resErr.Error)
+}
+
+func TestStreamBadLastEventID(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	_, url, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	res, _ := openStream(t, url+"?namespace=ns1&ephemeral", map[string]string{
+		"Last-Event-ID": "abc",
+	})
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+	var resErr fftypes.RESTError
+	json.NewDecoder(res.Body).Decode(&resErr)
+	assert.Regexp(t, "FF10410", resErr.Error)
+}
+
+func TestStreamSubscribeFail(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, url, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	cbs.On("EphemeralSubscription", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	res, _ := openStream(t, url+"?namespace=ns1&ephemeral=true", nil)
+	defer res.Body.Close()
+	assert.Equal(t, 400, res.StatusCode)
+	assert.Empty(t, s.connections)
+	cbs.AssertCalled(t, "ConnnectionClosed", mock.Anything)
+}
+
+func TestStreamUnauthorizedNamespace(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, _, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	principal, err := auth.NewPrincipal(context.Background(), "app1", []string{"ns1:read"})
+	assert.NoError(t, err)
+	req := httptest.NewRequest(http.MethodGet, "/sse?namespace=ns2&ephemeral", nil)
+	req = req.WithContext(auth.WithPrincipal(req.Context(), principal))
+	res := httptest.NewRecorder()
+	s.ServeHTTP(res, req)
+	assert.Equal(t, 403, res.Result().StatusCode)
+}
+
+func TestStreamNoFlusher(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, _, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	req := httptest.NewRequest(http.MethodGet, "/sse?namespace=ns1&ephemeral", nil)
+	res := httptest.NewRecorder()
+	s.ServeHTTP(&noFlushWriter{res}, req)
+	assert.Equal(t, 500, res.Result().StatusCode)
+}
+
+func TestStreamWriteFail(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, _, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	cbs.On("EphemeralSubscription", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/sse?namespace=ns1&ephemeral", nil)
+	s.ServeHTTP(&failingWriter{httptest.NewRecorder()}, req)
+	assert.Empty(t, s.connections)
+}
+
+func TestAckBadData(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	_, url, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	res := postAck(t, url, `!json`)
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func TestAckUnknownConnection(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	_, url, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	res := postAck(t, url, `{"connection":"unknown"}`)
+	assert.Equal(t, 404, res.StatusCode)
+	var resErr fftypes.RESTError
+	json.NewDecoder(res.Body).Decode(&resErr)
+	assert.Regexp(t, "FF10408", resErr.Error)
+}
+
+func TestAckUnauthorizedNamespace(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, _, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	sc := newConnection(s.ctx, context.Background(), s, "ns2", "", false)
+	defer close(sc.done)
+	s.connections[sc.connID] = sc
+
+	principal, err := auth.NewPrincipal(context.Background(), "app1", []string{"ns1:read"})
+	assert.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/sse", strings.NewReader(fmt.Sprintf(`{"connection":"%s"}`, sc.connID)))
+	req = req.WithContext(auth.WithPrincipal(req.Context(), principal))
+	res := httptest.NewRecorder()
+	s.ServeHTTP(res, req)
+	assert.Equal(t, 403, res.Result().StatusCode)
+}
+
+func TestAckNoneInflight(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, url, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	sc := newConnection(s.ctx, context.Background(), s, "ns1", "", false)
+	defer close(sc.done)
+	s.connections[sc.connID] = sc
+
+	res := postAck(t, url, fmt.Sprintf(`{"connection":"%s"}`, sc.connID))
+	assert.Equal(t, 400, res.StatusCode)
+	var resErr fftypes.RESTError
+	json.NewDecoder(res.Body).Decode(&resErr)
+	assert.Regexp(t, "FF10175", resErr.Error)
+}
+
+func TestCheckAckMatching(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, _, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	sc := newConnection(s.ctx, context.Background(), s, "ns1", "", false)
+	id1 := fftypes.NewUUID()
+	id2 := fftypes.NewUUID()
+	id3 := fftypes.NewUUID()
+	sc.inflight = []*fftypes.EventDeliveryResponse{
+		{ID: id1, Subscription: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"}},
+		{ID: id2, Subscription: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"}},
+		{ID: id3, Subscription: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"}},
+	}
+
+	// Wrong subscription does not match
+	_, err := sc.checkAck(&sseAckPayload{ID: id2, Subscription: &fftypes.SubscriptionRef{Namespace: "ns1", Name: "sub2"}})
+	assert.Regexp(t, "FF10175", err)
+
+	// Match by name
+	inflight, err := sc.checkAck(&sseAckPayload{ID: id2, Subscription: &fftypes.SubscriptionRef{Namespace: "ns1", Name: "sub1"}})
+	assert.NoError(t, err)
+	assert.Equal(t, *id2, *inflight.ID)
+
+	// Match by ID alone
+	inflight, err = sc.checkAck(&sseAckPayload{ID: id3})
+	assert.NoError(t, err)
+	assert.Equal(t, *id3, *inflight.ID)
+
+	// Front of the queue
+	inflight, err = sc.checkAck(&sseAckPayload{})
+	assert.NoError(t, err)
+	assert.Equal(t, *id1, *inflight.ID)
+	assert.Empty(t, sc.inflight)
+}
+
+func TestDeliveryRequestNoConnection(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, _, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	err := s.DeliveryRequest(context.Background(), "unknown", nil, &fftypes.EventDelivery{}, nil)
+	assert.Regexp(t, "FF10408", err)
+}
+
+func TestDispatchAfterClose(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, _, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	sc := newConnection(s.ctx, context.Background(), s, "ns1", "", false)
+	defer close(sc.done)
+	s.connections[sc.connID] = sc
+	sc.close()
+	sc.close() // no-op
+
+	err := sc.dispatch(&fftypes.EventDelivery{})
+	assert.Regexp(t, "FF10408", err)
+	assert.Empty(t, s.connections)
+}
+
+func TestChangeEventNoop(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, _, cancel := newTestSSE(t, cbs)
+	defer cancel()
+
+	s.ChangeEvent("any", &fftypes.ChangeEvent{})
+}
+
+func TestWaitClosedOnShutdown(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, url, cancel := newTestSSE(t, cbs)
+
+	cbs.On("EphemeralSubscription", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(nil)
+
+	res, r := openStream(t, url+"?namespace=ns1&ephemeral", nil)
+	defer res.Body.Close()
+	readConnID(t, r)
+	assert.Len(t, s.connections, 1)
+
+	cancel()
+	assert.Empty(t, s.connections)
+}
@@ -19,7 +19,10 @@ package events
 import (
 	"context"
 	"regexp"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/data"
@@ -28,6 +31,7 @@ import (
 	"github.com/hyperledger/firefly/internal/events/system"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/retry"
 	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/pkg/database"
@@ -44,6 +48,70 @@ type subscription struct {
 	blockchainFilter   *blockchainFilter
 	transactionFilter  *transactionFilter
 	topicFilter        *regexp.Regexp
+	dataFilters        []*dataFilter
+	redeliveries       redeliveryState
+}
+
+// redeliveryState tracks the events rejected on a subscription. It is held on the subscription rather
+// than the dispatcher, so the attempts are still counted when the application reconnects.
+type redeliveryState struct {
+	mux          sync.Mutex
+	nackCounts   map[fftypes.UUID]int
+	deadLettered map[fftypes.UUID]int64
+}
+
+// nacked records a rejection of an event, and returns the number of times it has been rejected
+func (rs *redeliveryState) nacked(id fftypes.UUID) int {
+	rs.mux.Lock()
+	defer rs.mux.Unlock()
+	if rs.nackCounts == nil {
+		rs.nackCounts = make(map[fftypes.UUID]int)
+	}
+	rs.nackCounts[id]++
+	return rs.nackCounts[id]
+}
+
+// acked clears the rejection count of events that have been acknowledged
+func (rs *redeliveryState) acked(ids ...fftypes.UUID) {
+	rs.mux.Lock()
+	defer rs.mux.Unlock()
+	for _, id := range ids {
+		delete(rs.nackCounts, id)
+	}
+}
+
+// deadLetter records that an event has been moved to the dead letter collection, so it is skipped
+// if it is read again before the offset moves past it (such as after a rewind for another rejection)
+func (rs *redeliveryState) deadLetter(id fftypes.UUID, sequence int64) {
+	rs.mux.Lock()
+	defer rs.mux.Unlock()
+	delete(rs.nackCounts, id)
+	if rs.deadLettered == nil {
+		rs.deadLettered = make(map[fftypes.UUID]int64)
+	}
+	rs.deadLettered[id] = sequence
+}
+
+// isDeadLettered checks whether an event has already been dead lettered, and forgets any dead lettered
+// events at or below the offset, as those will not be read again
+func (rs *redeliveryState) isDeadLettered(id fftypes.UUID, offset int64) bool {
+	rs.mux.Lock()
+	defer rs.mux.Unlock()
+	for dlID, sequence := range rs.deadLettered {
+		if sequence <= offset {
+			delete(rs.deadLettered, dlID)
+		}
+	}
+	_, deadLettered := rs.deadLettered[id]
+	return deadLettered
+}
+
+// reset forgets all rejections, such as when the offset of the subscription is reset
+func (rs *redeliveryState) reset() {
+	rs.mux.Lock()
+	defer rs.mux.Unlock()
+	rs.nackCounts = nil
+	rs.deadLettered = nil
 }
 
 type messageFilter struct {
@@ -61,6 +129,11 @@ type transactionFilter struct {
 	typeFilter *regexp.Regexp
 }
 
+type dataFilter struct {
+	path        []string
+	valueFilter *regexp.Regexp
+}
+
 type connection struct {
 	id          string
 	transport   string
@@ -81,14 +154,17 @@ type subscriptionManager struct {
 	mux                       sync.Mutex
 	maxSubs                   uint64
 	durableSubs               map[fftypes.UUID]*subscription
+	offsetResets              map[fftypes.UUID]*offsetReset
 	cancelCtx                 func()
 	newOrUpdatedSubscriptions chan *fftypes.UUID
 	deletedSubscriptions      chan *fftypes.UUID
 	cel                       *changeEventListener
 	retry                     retry.Retry
+	metrics                   metrics.Manager
+	metricsInterval           time.Duration
 }
 
-func newSubscriptionManager(ctx context.Context, di database.Plugin, dm data.Manager, en *eventNotifier, sh definitions.DefinitionHandlers, txHelper txcommon.Helper) (*subscriptionManager, error) {
+func newSubscriptionManager(ctx context.Context, di database.Plugin, dm data.Manager, en *eventNotifier, sh definitions.DefinitionHandlers, txHelper txcommon.Helper, mm metrics.Manager) (*subscriptionManager, error) {
 	ctx, cancelCtx := context.WithCancel(ctx)
 	sm := &subscriptionManager{
 		ctx:                       ctx,
@@ -97,6 +173,7 @@ func newSubscriptionManager(ctx context.Context, di database.Plugin, dm data.Man
 		transports:                make(map[string]events.Plugin),
 		connections:               make(map[string]*connection),
 		durableSubs:               make(map[fftypes.UUID]*subscription),
+		offsetResets:              make(map[fftypes.UUID]*offsetReset),
 		newOrUpdatedSubscriptions: make(chan *fftypes.UUID),
 		deletedSubscriptions:      make(chan *fftypes.UUID),
 		maxSubs:                   uint64(config.GetUint(config.SubscriptionMax)),
@@ -109,6 +186,8 @@ func newSubscriptionManager(ctx context.Context, di database.Plugin, dm data.Man
 			MaximumDelay: config.GetDuration(config.SubscriptionsRetryMaxDelay),
 			Factor:       config.GetFloat64(config.SubscriptionsRetryFactor),
 		},
+		metrics:         mm,
+		metricsInterval: config.GetDuration(config.SubscriptionMetricsInterval),
 	}
 	sm.cel = newChangeEventListener(ctx)
 
@@ -174,9 +253,97 @@ func (sm *subscriptionManager) start() error {
 	log.L(sm.ctx).Infof("Subscription manager started - loaded %d durable subscriptions", len(sm.durableSubs))
 	go sm.subscriptionEventListener()
 	go sm.cel.changeEventListener()
+	if sm.metrics.IsMetricsEnabled() {
+		go sm.subscriptionMetricsLoop()
+	}
 	return nil
 }
 
+// subscriptionMetricsLoop periodically refreshes the status gauges for all durable subscriptions,
+// as the lag changes as new events arrive even when nothing is being dispatched
+func (sm *subscriptionManager) subscriptionMetricsLoop() {
+	ticker := time.NewTicker(sm.metricsInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			sm.mux.Lock()
+			subDefs := make([]*fftypes.Subscription, 0, len(sm.durableSubs))
+			for _, sub := range sm.durableSubs {
+				subDefs = append(subDefs, sub.definition)
+			}
+			sm.mux.Unlock()
+			for _, subDef := range subDefs {
+				if _, err := sm.getSubscriptionStatus(sm.ctx, subDef); err != nil {
+					log.L(sm.ctx).Warnf("Failed to update metrics for subscription %s:%s [%s]: %s", subDef.Namespace, subDef.Name, subDef.ID, err)
+				}
+			}
+		case <-sm.ctx.Done():
+			return
+		}
+	}
+}
+
+// getSubscriptionStatus combines the stored offset of a subscription, with the live state of the
+// dispatchers for that subscription on any connections to this node
+func (sm *subscriptionManager) getSubscriptionStatus(ctx context.Context, subDef *fftypes.Subscription) (*fftypes.SubscriptionStatus, error) {
+	status := &fftypes.SubscriptionStatus{
+		Subscription:    subDef.ID,
+		CurrentOffset:   -1,
+		HighestSequence: -1,
+		Connections:     []string{},
+	}
+
+	sm.mux.Lock()
+	var dispatchers []*eventDispatcher
+	for _, conn := range sm.connections {
+		if dispatcher, ok := conn.dispatchers[*subDef.ID]; ok {
+			status.Connections = append(status.Connections, conn.id)
+			dispatchers = append(dispatchers, dispatcher)
+		}
+	}
+	sm.mux.Unlock()
+	sort.Strings(status.Connections)
+
+	for _, dispatcher := range dispatchers {
+		stats := dispatcher.getStats()
+		status.Inflight += stats.inflight
+		status.Nacks += stats.nacks
+		if stats.lastDelivery != nil && (status.LastDelivery == nil || stats.lastDelivery.UnixNano() > status.LastDelivery.UnixNano()) {
+			status.LastDelivery = stats.lastDelivery
+		}
+		if stats.lastAck != nil && (status.LastAck == nil || stats.lastAck.UnixNano() > status.LastAck.UnixNano()) {
+			status.LastAck = stats.lastAck
+		}
+	}
+
+	offset, err := sm.database.GetOffset(ctx, fftypes.OffsetTypeSubscription, subDef.ID.String())
+	if err != nil {
+		return nil, err
+	}
+	if offset != nil {
+		status.CurrentOffset = offset.Current
+	}
+
+	fb := database.EventQueryFactory.NewFilter(ctx)
+	filter := fb.And(fb.Eq("namespace", subDef.Namespace)).Sort("sequence").Descending().Limit(1)
+	newestEvents, _, err := sm.database.GetEvents(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(newestEvents) > 0 {
+		status.HighestSequence = newestEvents[0].Sequence
+	}
+	if status.HighestSequence > status.CurrentOffset {
+		status.Lag = status.HighestSequence - status.CurrentOffset
+	}
+
+	if sm.metrics.IsMetricsEnabled() {
+		sm.metrics.SubscriptionStatus(subDef.Namespace, subDef.Name, status)
+	}
+	return status, nil
+}
+
 func (sm *subscriptionManager) subscriptionEventListener() {
 	for {
 		select {
@@ -255,9 +422,96 @@ func (sm *subscriptionManager) closeDurabeSubscriptionLocked(id *fftypes.UUID) (
 	return loaded, dispatchers
 }
 
+// offsetReset tracks a reset in progress, so that a delete of the subscription while the
+// reset is working outside of the lock is not missed
+type offsetReset struct {
+	deleted bool
+}
+
+// resetOffset moves the stored offset of a durable subscription. Any active dispatchers are closed
+// first, so that they cannot commit their own offset over the top of the new one. They are then
+// restarted, and restore their position from the updated offset with nothing in-flight.
+// If the subscription is deleted while the reset is in progress, it is not restarted and the
+// offset is removed again.
+//
+// Only the dispatchers on this node are closed. In a deployment with multiple replicas sharing the
+// database, dispatchers for the same subscription on other replicas keep their in-memory position,
+// and can commit an offset over the top of the reset. Resets should be made with only one replica
+// running the subscription.
+func (sm *subscriptionManager) resetOffset(ctx context.Context, id *fftypes.UUID, firstOffset int64) (*fftypes.Offset, error) {
+	reset := &offsetReset{}
+	sm.mux.Lock()
+	sub := sm.durableSubs[*id]
+	loaded, dispatchers := sm.closeDurabeSubscriptionLocked(id)
+	sm.offsetResets[*id] = reset
+	sm.mux.Unlock()
+
+	log.L(ctx).Infof("Resetting subscription %s offset to %d loaded=%t dispatchers=%d", id, firstOffset, loaded, len(dispatchers))
+
+	// Outside the lock, close out the active dispatchers
+	for _, dispatcher := range dispatchers {
+		dispatcher.close()
+	}
+
+	offset := &fftypes.Offset{
+		Type:    fftypes.OffsetTypeSubscription,
+		Name:    id.String(),
+		Current: firstOffset,
+	}
+	err := sm.database.UpsertOffset(ctx, offset, true)
+	if sub != nil {
+		// Events that were dead lettered are delivered again if the reset moves the offset back past them
+		sub.redeliveries.reset()
+	}
+	existing, lookupErr := sm.database.GetSubscriptionByID(ctx, id)
+
+	// Restart the dispatchers, unless the subscription was replaced or deleted while we were working.
+	// A delete that is processed after the lookup above is recorded on the reset, and checked under
+	// the lock, so the cleanup of a delete cannot run between the check and the restart.
+	sm.mux.Lock()
+	if sm.offsetResets[*id] == reset {
+		delete(sm.offsetResets, *id)
+	}
+	deleted := reset.deleted || (lookupErr == nil && existing == nil)
+	if loaded && !deleted {
+		if _, replaced := sm.durableSubs[*id]; !replaced {
+			sm.durableSubs[*id] = sub
+			for _, conn := range sm.connections {
+				sm.matchSubToConnLocked(conn, sub)
+			}
+		}
+	}
+	sm.mux.Unlock()
+
+	if deleted {
+		log.L(ctx).Infof("Subscription %s deleted while resetting offset", id)
+		if sub != nil {
+			sm.deleteSubscriptionMetrics(sub.definition)
+		}
+		if err == nil {
+			// The cleanup of the delete might have run before the offset was written
+			err = sm.database.DeleteOffset(ctx, fftypes.OffsetTypeSubscription, id.String())
+		}
+		if err == nil {
+			err = i18n.NewError(ctx, i18n.Msg404NoResult)
+		}
+	}
+	if err == nil {
+		err = lookupErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return offset, nil
+}
+
 func (sm *subscriptionManager) deletedDurableSubscription(id *fftypes.UUID) {
 	sm.mux.Lock()
+	sub := sm.durableSubs[*id]
 	loaded, dispatchers := sm.closeDurabeSubscriptionLocked(id)
+	if reset, resetting := sm.offsetResets[*id]; resetting {
+		reset.deleted = true
+	}
 	sm.mux.Unlock()
 
 	log.L(sm.ctx).Infof("Cleaning up subscription %s loaded=%t dispatchers=%d", id, loaded, len(dispatchers))
@@ -266,11 +520,19 @@ func (sm *subscriptionManager) deletedDurableSubscription(id *fftypes.UUID) {
 	for _, dispatcher := range dispatchers {
 		dispatcher.close()
 	}
+	if sub != nil {
+		sm.deleteSubscriptionMetrics(sub.definition)
+	}
 	// Delete the offsets, as the durable subscriptions are gone
 	err := sm.database.DeleteOffset(sm.ctx, fftypes.OffsetTypeSubscription, id.String())
 	if err != nil {
 		log.L(sm.ctx).Errorf("Failed to cleanup subscription offset: %s", err)
 	}
+	// Delete any dead letters, as there is nothing left to replay them to
+	err = sm.database.DeleteDeadLetters(sm.ctx, id)
+	if err != nil {
+		log.L(sm.ctx).Errorf("Failed to cleanup subscription dead letters: %s", err)
+	}
 }
 
 func (sm *subscriptionManager) parseSubscriptionDef(ctx context.Context, subDef *fftypes.Subscription) (sub *subscription, err error) {
@@ -411,6 +673,25 @@ func (sm *subscriptionManager) parseSubscriptionDef(ctx context.Context, subDef
 		sub.transactionFilter = tf
 	}
 
+	if len(filter.Data) > 0 {
+		paths := make([]string, 0, len(filter.Data))
+		for path := range filter.Data {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			valueFilter, err := regexp.Compile(filter.Data[path])
+			if err != nil {
+				return nil, i18n.WrapError(ctx, err, i18n.MsgRegexpCompileFailed, "filter.data."+path, filter.Data[path])
+			}
+			df := &dataFilter{valueFilter: valueFilter}
+			if path != "" {
+				df.path = strings.Split(path, ".")
+			}
+			sub.dataFilters = append(sub.dataFilters, df)
+		}
+	}
+
 	return sub, err
 }
 
@@ -420,10 +701,24 @@ func (sm *subscriptionManager) close() {
 	for _, conn := range sm.connections {
 		conns = append(conns, conn)
 	}
+	subDefs := make([]*fftypes.Subscription, 0, len(sm.durableSubs))
+	for _, sub := range sm.durableSubs {
+		subDefs = append(subDefs, sub.definition)
+	}
 	sm.mux.Unlock()
 	for _, conn := range conns {
 		sm.connnectionClosed(conn.ei, conn.id)
 	}
+	for _, subDef := range subDefs {
+		sm.deleteSubscriptionMetrics(subDef)
+	}
+}
+
+// deleteSubscriptionMetrics removes the status gauges for a subscription that is no longer active on this node
+func (sm *subscriptionManager) deleteSubscriptionMetrics(subDef *fftypes.Subscription) {
+	if sm.metrics.IsMetricsEnabled() {
+		sm.metrics.DeleteSubscriptionStatus(subDef.Namespace, subDef.Name)
+	}
 }
 
 func (sm *subscriptionManager) getCreateConnLocked(ei events.Plugin, connID string) *connection {
@@ -544,25 +839,64 @@ func (sm *subscriptionManager) connnectionClosed(ei events.Plugin, connID string
 	}
 }
 
+func (sm *subscriptionManager) replayDeadLetter(ctx context.Context, deadLetter *fftypes.DeadLetter) error {
+	sm.mux.Lock()
+	var dispatchers []*eventDispatcher
+	for _, conn := range sm.connections {
+		if dispatcher, ok := conn.dispatchers[*deadLetter.Subscription.ID]; ok {
+			dispatchers = append(dispatchers, dispatcher)
+		}
+	}
+	sm.mux.Unlock()
+
+	// Hand the event to whichever dispatcher is the elected leader for the subscription
+	for _, dispatcher := range dispatchers {
+		replayed, err := dispatcher.replayDeadLetter(deadLetter)
+		if replayed || err != nil {
+			return err
+		}
+	}
+	return i18n.NewError(ctx, i18n.MsgSubscriptionNotActive, deadLetter.Subscription.ID)
+}
+
 func (sm *subscriptionManager) deliveryResponse(ei events.Plugin, connID string, inflight *fftypes.EventDeliveryResponse) {
+	dispatcher := sm.getResponseDispatcher(ei, connID, inflight.Subscription.ID, "DeliveryResponse")
+	if dispatcher != nil {
+		dispatcher.deliveryResponse(inflight)
+	}
+}
+
+func (sm *subscriptionManager) cumulativeAck(ei events.Plugin, connID string, subID *fftypes.UUID, sequence int64) {
+	dispatcher := sm.getResponseDispatcher(ei, connID, subID, "CumulativeAck")
+	if dispatcher != nil {
+		dispatcher.cumulativeAck(sequence)
+	}
+}
+
+func (sm *subscriptionManager) deliveryRetry(ei events.Plugin, connID string, sub *fftypes.SubscriptionRef) {
+	log.L(sm.ctx).Debugf("Delivery retry on %s connection %s for subscription %s:%s", ei.Name(), connID, sub.Namespace, sub.Name)
+	if sm.metrics.IsMetricsEnabled() {
+		sm.metrics.CountDeliveryRetry(sub.Namespace, sub.Name, ei.Name())
+	}
+}
+
+func (sm *subscriptionManager) getResponseDispatcher(ei events.Plugin, connID string, subID *fftypes.UUID, callback string) *eventDispatcher {
 	sm.mux.Lock()
+	defer sm.mux.Unlock()
 	var dispatcher *eventDispatcher
 	conn, ok := sm.connections[connID]
-	if ok && inflight.Subscription.ID != nil {
-		dispatcher = conn.dispatchers[*inflight.Subscription.ID]
+	if ok && subID != nil {
+		dispatcher = conn.dispatchers[*subID]
 	}
 	if ok && conn.ei != ei {
 		err := i18n.NewError(sm.ctx, i18n.MsgMismatchedTransport, connID, ei.Name(), conn.ei.Name())
-		log.L(sm.ctx).Errorf("Invalid DeliveryResponse callback from plugin: %s", err)
-		sm.mux.Unlock()
-		return
+		log.L(sm.ctx).Errorf("Invalid %s callback from plugin: %s", callback, err)
+		return nil
 	}
 	if dispatcher == nil {
-		err := i18n.NewError(sm.ctx, i18n.MsgConnSubscriptionNotStarted, inflight.Subscription.ID)
-		log.L(sm.ctx).Errorf("Invalid DeliveryResponse callback from plugin: %s", err)
-		sm.mux.Unlock()
-		return
+		err := i18n.NewError(sm.ctx, i18n.MsgConnSubscriptionNotStarted, subID)
+		log.L(sm.ctx).Errorf("Invalid %s callback from plugin: %s", callback, err)
+		return nil
 	}
-	sm.mux.Unlock()
-	dispatcher.deliveryResponse(inflight)
+	return dispatcher
 }
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/txcommon"
@@ -27,6 +28,7 @@ import (
 	"github.com/hyperledger/firefly/mocks/datamocks"
 	"github.com/hyperledger/firefly/mocks/definitionsmocks"
 	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
 	"github.com/hyperledger/firefly/pkg/events"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
@@ -40,16 +42,18 @@ func newTestSubManager(t *testing.T, mei *eventsmocks.PluginAll) (*subscriptionM
 	mdi := &databasemocks.Plugin{}
 	mdm := &datamocks.Manager{}
 	msh := &definitionsmocks.DefinitionHandlers{}
+	mmi := &metricsmocks.Manager{}
 	txHelper := txcommon.NewTransactionHelper(mdi, mdm)
 
 	ctx, cancel := context.WithCancel(context.Background())
+	mmi.On("IsMetricsEnabled").Return(false).Maybe()
 	mei.On("Name").Return("ut")
 	mei.On("Capabilities").Return(&events.Capabilities{}).Maybe()
 	mei.On("InitPrefix", mock.Anything).Return()
 	mei.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	mdi.On("GetEvents", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.Event{}, nil, nil).Maybe()
 	mdi.On("GetOffset", mock.Anything, mock.Anything, mock.Anything).Return(&fftypes.Offset{RowID: 3333333, Current: 0}, nil).Maybe()
-	sm, err := newSubscriptionManager(ctx, mdi, mdm, newEventNotifier(ctx, "ut"), msh, txHelper)
+	sm, err := newSubscriptionManager(ctx, mdi, mdm, newEventNotifier(ctx, "ut"), msh, txHelper, mmi)
 	assert.NoError(t, err)
 	sm.transports = map[string]events.Plugin{
 		"ut": mei,
@@ -163,13 +167,28 @@ func TestRegisterEphemeralSubscriptionsFail(t *testing.T) {
 
 }
 
+func TestStartSubManagerMetricsEnabled(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	mdi := sm.database.(*databasemocks.Plugin)
+	mmi := &metricsmocks.Manager{}
+	sm.metrics = mmi
+
+	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{}, nil, nil)
+	mmi.On("IsMetricsEnabled").Return(true)
+	err := sm.start()
+	assert.NoError(t, err)
+	cancel()
+	mmi.AssertExpectations(t)
+}
+
 func TestSubManagerBadPlugin(t *testing.T) {
 	mdi := &databasemocks.Plugin{}
 	mdm := &datamocks.Manager{}
 	txHelper := txcommon.NewTransactionHelper(mdi, mdm)
 	config.Reset()
 	config.Set(config.EventTransportsEnabled, []string{"!unknown!"})
-	_, err := newSubscriptionManager(context.Background(), mdi, mdm, newEventNotifier(context.Background(), "ut"), nil, txHelper)
+	_, err := newSubscriptionManager(context.Background(), mdi, mdm, newEventNotifier(context.Background(), "ut"), nil, txHelper, nil)
 	assert.Regexp(t, "FF10172", err)
 }
 
@@ -361,6 +380,22 @@ func TestCreateSubscriptionBadBlockchainEventNameFilter(t *testing.T) {
 	assert.Regexp(t, "FF10171.*name", err)
 }
 
+func TestCreateSubscriptionBadDataFilter(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	_, err := sm.parseSubscriptionDef(sm.ctx, &fftypes.Subscription{
+		Filter: fftypes.SubscriptionFilter{
+			Data: fftypes.DataFilter{
+				"customer.id": "[[[[! badness",
+			},
+		},
+		Transport: "ut",
+	})
+	assert.Regexp(t, "FF10171.*customer.id", err)
+}
+
 func TestCreateSubscriptionBadDeprecatedGroupFilter(t *testing.T) {
 	mei := &eventsmocks.PluginAll{}
 	sm, cancel := newTestSubManager(t, mei)
@@ -481,6 +516,26 @@ func TestCreateSubscriptionSuccessTxFilter(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestCreateSubscriptionSuccessDataFilter(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	sub, err := sm.parseSubscriptionDef(sm.ctx, &fftypes.Subscription{
+		Filter: fftypes.SubscriptionFilter{
+			Data: fftypes.DataFilter{
+				"customer.id": "abc",
+				"":            "def",
+			},
+		},
+		Transport: "ut",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, sub.dataFilters, 2)
+	assert.Nil(t, sub.dataFilters[0].path)
+	assert.Equal(t, []string{"customer", "id"}, sub.dataFilters[1].path)
+}
+
 func TestCreateSubscriptionSuccessBlockchainEvent(t *testing.T) {
 	mei := &eventsmocks.PluginAll{}
 	sm, cancel := newTestSubManager(t, mei)
@@ -546,6 +601,39 @@ func TestDispatchDeliveryResponseOK(t *testing.T) {
 	mdi.AssertExpectations(t)
 }
 
+func TestDispatchCumulativeAckOK(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mdi := sm.database.(*databasemocks.Plugin)
+	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{}, nil, nil)
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	err := sm.start()
+	assert.NoError(t, err)
+	be := &boundCallbacks{sm: sm, ei: mei}
+
+	err = be.EphemeralSubscription("conn1", "ns1", &fftypes.SubscriptionFilter{}, &fftypes.SubscriptionOptions{})
+	assert.NoError(t, err)
+
+	var subID *fftypes.UUID
+	for _, d := range sm.connections["conn1"].dispatchers {
+		subID = d.subscription.definition.ID
+	}
+
+	// Nothing in-flight, but that's fine
+	be.CumulativeAck("conn1", subID, 10)
+	mdi.AssertExpectations(t)
+}
+
+func TestDispatchCumulativeAckInvalidSubscription(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+
+	be := &boundCallbacks{sm: sm, ei: mei}
+	be.CumulativeAck("conn1", fftypes.NewUUID(), 10)
+}
+
 func TestDispatchDeliveryResponseInvalidSubscription(t *testing.T) {
 	mei := &eventsmocks.PluginAll{}
 	sm, cancel := newTestSubManager(t, mei)
@@ -588,6 +676,8 @@ func TestConnIDSafetyChecking(t *testing.T) {
 
 	be2.DeliveryResponse("conn1", &fftypes.EventDeliveryResponse{})
 
+	be2.CumulativeAck("conn1", fftypes.NewUUID(), 10)
+
 	be2.ConnnectionClosed("conn1")
 
 	assert.NotNil(t, sm.connections["conn1"])
@@ -823,9 +913,439 @@ func TestDeleteDurableSubscriptionOk(t *testing.T) {
 
 	mdi.On("GetSubscriptionByID", mock.Anything, subID).Return(subDef, nil)
 	mdi.On("DeleteOffset", mock.Anything, fftypes.FFEnum("subscription"), subID.String()).Return(fmt.Errorf("this error is logged and swallowed"))
+	mdi.On("DeleteDeadLetters", mock.Anything, subID).Return(fmt.Errorf("this error is also logged and swallowed"))
+	mmi := &metricsmocks.Manager{}
+	sm.metrics = mmi
+	mmi.On("IsMetricsEnabled").Return(true)
+	mmi.On("DeleteSubscriptionStatus", "ns1", "sub1").Return()
 	sm.deletedDurableSubscription(subID)
 
 	assert.Empty(t, sm.connections["conn1"].dispatchers)
 	assert.Empty(t, sm.durableSubs)
 	<-ed.closed
+	mmi.AssertExpectations(t)
+}
+
+func TestCloseDeletesSubscriptionMetrics(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mmi := &metricsmocks.Manager{}
+	sm.metrics = mmi
+
+	subDef := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+	}
+	sm.durableSubs[*subDef.ID] = &subscription{definition: subDef}
+	mmi.On("IsMetricsEnabled").Return(true)
+	mmi.On("DeleteSubscriptionStatus", "ns1", "sub1").Return()
+
+	sm.close()
+
+	mmi.AssertExpectations(t)
+}
+
+func TestReplayDeadLetterNotActive(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+
+	subID := fftypes.NewUUID()
+	sub := &subscription{
+		definition: &fftypes.Subscription{
+			SubscriptionRef: fftypes.SubscriptionRef{ID: subID, Namespace: "ns1", Name: "sub1"},
+		},
+	}
+	ed, _ := newTestEventDispatcher(sub)
+	sm.connections["conn1"] = &connection{
+		ei: mei,
+		id: "conn1",
+		dispatchers: map[fftypes.UUID]*eventDispatcher{
+			*subID: ed,
+		},
+	}
+
+	err := sm.replayDeadLetter(sm.ctx, &fftypes.DeadLetter{
+		ID:           fftypes.NewUUID(),
+		Subscription: sub.definition.SubscriptionRef,
+	})
+	assert.Regexp(t, "FF10376", err)
+}
+
+func TestReplayDeadLetterElected(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+
+	subID := fftypes.NewUUID()
+	sub := &subscription{
+		definition: &fftypes.Subscription{
+			SubscriptionRef: fftypes.SubscriptionRef{ID: subID, Namespace: "ns1", Name: "sub1"},
+		},
+	}
+	ed, _ := newTestEventDispatcher(sub)
+	ed.elected = true
+	sm.connections["conn1"] = &connection{
+		ei: mei,
+		id: "conn1",
+		dispatchers: map[fftypes.UUID]*eventDispatcher{
+			*subID: ed,
+		},
+	}
+
+	deadLetter := &fftypes.DeadLetter{
+		ID:           fftypes.NewUUID(),
+		Subscription: sub.definition.SubscriptionRef,
+		Event:        fftypes.NewUUID(),
+	}
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetEventByID", mock.Anything, deadLetter.Event).Return(nil, fmt.Errorf("pop"))
+
+	err := sm.replayDeadLetter(sm.ctx, deadLetter)
+	assert.Regexp(t, "pop", err)
+}
+
+func TestResetOffsetRestartsDispatchers(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	mdi := sm.database.(*databasemocks.Plugin)
+
+	subID := fftypes.NewUUID()
+	s := &subscription{
+		// Unbuffered, so the restarted dispatcher waits to be elected until we close
+		dispatcherElection: make(chan bool),
+		definition: &fftypes.Subscription{
+			SubscriptionRef: fftypes.SubscriptionRef{ID: subID, Namespace: "ns1", Name: "sub1"},
+			Transport:       "ut",
+		},
+	}
+	sm.durableSubs[*subID] = s
+
+	ed, cancelEd := newTestEventDispatcher(s)
+	cancelEd()
+	close(ed.closed)
+	sm.connections["conn1"] = &connection{
+		ei:        mei,
+		id:        "conn1",
+		transport: "ut",
+		matcher: func(sr fftypes.SubscriptionRef) bool {
+			return sr.Namespace == "ns1" && sr.Name == "sub1"
+		},
+		dispatchers: map[fftypes.UUID]*eventDispatcher{
+			*subID: ed,
+		},
+	}
+
+	mdi.On("UpsertOffset", mock.Anything, mock.MatchedBy(func(offset *fftypes.Offset) bool {
+		return offset.Type == fftypes.OffsetTypeSubscription && offset.Name == subID.String() && offset.Current == 10
+	}), true).Return(nil)
+	mdi.On("GetSubscriptionByID", mock.Anything, subID).Return(s.definition, nil)
+
+	offset, err := sm.resetOffset(sm.ctx, subID, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), offset.Current)
+
+	restarted := sm.connections["conn1"].dispatchers[*subID]
+	assert.NotNil(t, restarted)
+	assert.NotEqual(t, ed, restarted)
+	assert.Equal(t, s, sm.durableSubs[*subID])
+
+	cancel()
+	<-restarted.closed
+	mdi.AssertExpectations(t)
+}
+
+func TestResetOffsetReplacedWhileResetting(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mdi := sm.database.(*databasemocks.Plugin)
+
+	subID := fftypes.NewUUID()
+	s := &subscription{
+		definition: &fftypes.Subscription{
+			SubscriptionRef: fftypes.SubscriptionRef{ID: subID, Namespace: "ns1", Name: "sub1"},
+		},
+	}
+	s2 := &subscription{
+		definition: s.definition,
+	}
+	sm.durableSubs[*subID] = s
+
+	mdi.On("UpsertOffset", mock.Anything, mock.Anything, true).Run(func(args mock.Arguments) {
+		sm.mux.Lock()
+		sm.durableSubs[*subID] = s2
+		sm.mux.Unlock()
+	}).Return(nil)
+	mdi.On("GetSubscriptionByID", mock.Anything, subID).Return(s.definition, nil)
+
+	_, err := sm.resetOffset(sm.ctx, subID, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, s2, sm.durableSubs[*subID])
+}
+
+func TestResetOffsetDeletedWhileResetting(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mdi := sm.database.(*databasemocks.Plugin)
+
+	subID := fftypes.NewUUID()
+	s := &subscription{
+		definition: &fftypes.Subscription{
+			SubscriptionRef: fftypes.SubscriptionRef{ID: subID, Namespace: "ns1", Name: "sub1"},
+		},
+	}
+	sm.durableSubs[*subID] = s
+
+	mdi.On("UpsertOffset", mock.Anything, mock.Anything, true).Return(nil)
+	mdi.On("GetSubscriptionByID", mock.Anything, subID).Return(nil, nil)
+	mdi.On("DeleteOffset", mock.Anything, fftypes.OffsetTypeSubscription, subID.String()).Return(nil)
+
+	_, err := sm.resetOffset(sm.ctx, subID, 10)
+	assert.Regexp(t, "FF10143", err)
+	assert.Empty(t, sm.durableSubs)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestResetOffsetDeletedAfterLookup(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mdi := sm.database.(*databasemocks.Plugin)
+
+	subID := fftypes.NewUUID()
+	s := &subscription{
+		definition: &fftypes.Subscription{
+			SubscriptionRef: fftypes.SubscriptionRef{ID: subID, Namespace: "ns1", Name: "sub1"},
+		},
+	}
+	sm.durableSubs[*subID] = s
+
+	mdi.On("UpsertOffset", mock.Anything, mock.Anything, true).Return(nil)
+	mdi.On("DeleteOffset", mock.Anything, fftypes.OffsetTypeSubscription, subID.String()).Return(nil)
+	mdi.On("DeleteDeadLetters", mock.Anything, subID).Return(nil)
+	// The delete is processed after the lookup has found the subscription - the lookup is made
+	// outside of the lock, so the cleanup of the delete is able to run
+	mdi.On("GetSubscriptionByID", mock.Anything, subID).Return(s.definition, nil).Run(func(args mock.Arguments) {
+		sm.deletedDurableSubscription(subID)
+	})
+
+	_, err := sm.resetOffset(sm.ctx, subID, 10)
+	assert.Regexp(t, "FF10143", err)
+	assert.Empty(t, sm.durableSubs)
+	assert.Empty(t, sm.offsetResets)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestResetOffsetDeletedDeleteOffsetFail(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mdi := sm.database.(*databasemocks.Plugin)
+
+	subID := fftypes.NewUUID()
+	mdi.On("UpsertOffset", mock.Anything, mock.Anything, true).Return(nil)
+	mdi.On("GetSubscriptionByID", mock.Anything, subID).Return(nil, nil)
+	mdi.On("DeleteOffset", mock.Anything, fftypes.OffsetTypeSubscription, subID.String()).Return(fmt.Errorf("pop"))
+
+	_, err := sm.resetOffset(sm.ctx, subID, 10)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestResetOffsetLookupFail(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mdi := sm.database.(*databasemocks.Plugin)
+
+	subID := fftypes.NewUUID()
+	mdi.On("UpsertOffset", mock.Anything, mock.Anything, true).Return(nil)
+	mdi.On("GetSubscriptionByID", mock.Anything, subID).Return(nil, fmt.Errorf("pop"))
+
+	_, err := sm.resetOffset(sm.ctx, subID, 10)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestResetOffsetNotLoadedFail(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mdi := sm.database.(*databasemocks.Plugin)
+
+	mdi.On("UpsertOffset", mock.Anything, mock.Anything, true).Return(fmt.Errorf("pop"))
+	mdi.On("GetSubscriptionByID", mock.Anything, mock.Anything).Return(&fftypes.Subscription{}, nil)
+
+	_, err := sm.resetOffset(sm.ctx, fftypes.NewUUID(), 10)
+	assert.EqualError(t, err, "pop")
+	assert.Empty(t, sm.durableSubs)
+}
+
+func TestGetSubscriptionStatus(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mdi := &databasemocks.Plugin{}
+	sm.database = mdi
+	mmi := &metricsmocks.Manager{}
+	sm.metrics = mmi
+
+	subID := fftypes.NewUUID()
+	subDef := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{ID: subID, Namespace: "ns1", Name: "sub1"},
+	}
+	s := &subscription{definition: subDef}
+
+	ed1, cancelEd1 := newTestEventDispatcher(s)
+	defer cancelEd1()
+	ed1.inflight[*fftypes.NewUUID()] = &fftypes.Event{}
+	ed1.totalNacks = 2
+	ed1.lastDelivery = fftypes.UnixTime(1000)
+	ed1.lastAck = fftypes.UnixTime(3000)
+	ed2, cancelEd2 := newTestEventDispatcher(s)
+	defer cancelEd2()
+	ed2.totalNacks = 1
+	ed2.lastDelivery = fftypes.UnixTime(2000)
+	ed2.lastAck = fftypes.UnixTime(500)
+	sm.connections["conn2"] = &connection{id: "conn2", dispatchers: map[fftypes.UUID]*eventDispatcher{*subID: ed2}}
+	sm.connections["conn1"] = &connection{id: "conn1", dispatchers: map[fftypes.UUID]*eventDispatcher{*subID: ed1}}
+	sm.connections["conn3"] = &connection{id: "conn3", dispatchers: map[fftypes.UUID]*eventDispatcher{}}
+
+	mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeSubscription, subID.String()).Return(&fftypes.Offset{Current: 10}, nil)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{{Sequence: 25}}, nil, nil)
+	mmi.On("IsMetricsEnabled").Return(true)
+	mmi.On("SubscriptionStatus", "ns1", "sub1", mock.Anything).Return()
+
+	status, err := sm.getSubscriptionStatus(sm.ctx, subDef)
+	assert.NoError(t, err)
+	assert.Equal(t, subID, status.Subscription)
+	assert.Equal(t, int64(10), status.CurrentOffset)
+	assert.Equal(t, int64(25), status.HighestSequence)
+	assert.Equal(t, int64(15), status.Lag)
+	assert.Equal(t, 1, status.Inflight)
+	assert.Equal(t, int64(3), status.Nacks)
+	assert.Equal(t, []string{"conn1", "conn2"}, status.Connections)
+	assert.Equal(t, int64(2000), status.LastDelivery.Time().Unix())
+	assert.Equal(t, int64(3000), status.LastAck.Time().Unix())
+
+	mdi.AssertExpectations(t)
+	mmi.AssertExpectations(t)
+}
+
+func TestGetSubscriptionStatusNoOffsetOrEvents(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mdi := &databasemocks.Plugin{}
+	sm.database = mdi
+
+	subDef := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+	}
+	mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeSubscription, subDef.ID.String()).Return(nil, nil)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{}, nil, nil)
+
+	status, err := sm.getSubscriptionStatus(sm.ctx, subDef)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-1), status.CurrentOffset)
+	assert.Equal(t, int64(-1), status.HighestSequence)
+	assert.Equal(t, int64(0), status.Lag)
+	assert.Empty(t, status.Connections)
+	assert.Nil(t, status.LastDelivery)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestGetSubscriptionStatusOffsetFail(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mdi := &databasemocks.Plugin{}
+	sm.database = mdi
+
+	subDef := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+	}
+	mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeSubscription, subDef.ID.String()).Return(nil, fmt.Errorf("pop"))
+
+	_, err := sm.getSubscriptionStatus(sm.ctx, subDef)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestGetSubscriptionStatusEventsFail(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mdi := &databasemocks.Plugin{}
+	sm.database = mdi
+
+	subDef := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+	}
+	mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeSubscription, subDef.ID.String()).Return(&fftypes.Offset{Current: 10}, nil)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := sm.getSubscriptionStatus(sm.ctx, subDef)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestSubscriptionMetricsLoop(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	mdi := &databasemocks.Plugin{}
+	sm.database = mdi
+	mmi := &metricsmocks.Manager{}
+	sm.metrics = mmi
+	sm.metricsInterval = 1 * time.Millisecond
+
+	sub1 := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+	}
+	sub2 := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub2"},
+	}
+	sm.durableSubs[*sub1.ID] = &subscription{definition: sub1}
+	sm.durableSubs[*sub2.ID] = &subscription{definition: sub2}
+
+	updated := make(chan bool)
+	mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeSubscription, sub1.ID.String()).Return(nil, fmt.Errorf("pop"))
+	mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeSubscription, sub2.ID.String()).Return(&fftypes.Offset{Current: 10}, nil)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{}, nil, nil)
+	mmi.On("IsMetricsEnabled").Return(true)
+	mmi.On("SubscriptionStatus", "ns1", "sub2", mock.Anything).Run(func(args mock.Arguments) {
+		select {
+		case updated <- true:
+		default:
+		}
+	}).Return()
+
+	done := make(chan struct{})
+	go func() {
+		sm.subscriptionMetricsLoop()
+		close(done)
+	}()
+	<-updated
+	cancel()
+	<-done
+}
+
+func TestDeliveryRetryMetrics(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mmi := &metricsmocks.Manager{}
+	sm.metrics = mmi
+	mmi.On("IsMetricsEnabled").Return(true)
+	mmi.On("CountDeliveryRetry", "ns1", "sub1", "ut").Return()
+
+	be := &boundCallbacks{sm: sm, ei: mei}
+	be.DeliveryRetry("conn1", &fftypes.SubscriptionRef{Namespace: "ns1", Name: "sub1"})
+
+	mmi.AssertExpectations(t)
 }
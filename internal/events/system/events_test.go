@@ -65,7 +65,7 @@ func TestDeliveryRequestOk(t *testing.T) {
 	})
 	assert.NoError(t, err)
 
-	err = se.DeliveryRequest(se.connID, &fftypes.Subscription{}, &fftypes.EventDelivery{
+	err = se.DeliveryRequest(context.Background(), se.connID, &fftypes.Subscription{}, &fftypes.EventDelivery{
 		EnrichedEvent: fftypes.EnrichedEvent{
 			Event: fftypes.Event{
 				Namespace: "ns1",
@@ -74,7 +74,7 @@ func TestDeliveryRequestOk(t *testing.T) {
 	}, nil)
 	assert.NoError(t, err)
 
-	err = se.DeliveryRequest(se.connID, &fftypes.Subscription{}, &fftypes.EventDelivery{
+	err = se.DeliveryRequest(context.Background(), se.connID, &fftypes.Subscription{}, &fftypes.EventDelivery{
 		EnrichedEvent: fftypes.EnrichedEvent{
 			Event: fftypes.Event{
 				Namespace: "ns2",
@@ -101,7 +101,7 @@ func TestDeliveryRequestFail(t *testing.T) {
 	})
 	assert.NoError(t, err)
 
-	err = se.DeliveryRequest(mock.Anything, &fftypes.Subscription{}, &fftypes.EventDelivery{
+	err = se.DeliveryRequest(context.Background(), mock.Anything, &fftypes.Subscription{}, &fftypes.EventDelivery{
 		EnrichedEvent: fftypes.EnrichedEvent{
 			Event: fftypes.Event{
 				Namespace: "ns1",
@@ -21,19 +21,31 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/internal/retry"
 	"github.com/hyperledger/firefly/pkg/events"
 	"github.com/hyperledger/firefly/pkg/fftypes"
+	whsig "github.com/hyperledger/firefly/pkg/webhooks"
+)
+
+const (
+	defaultBatchSize         = 50
+	defaultBatchTimeout      = 500 * time.Millisecond
+	defaultRetryAttempts     = 5
+	defaultRetryInitialDelay = 250 * time.Millisecond
+	defaultRetryMaxDelay     = 30 * time.Second
 )
 
 type WebHooks struct {
@@ -51,6 +63,18 @@ type whRequest struct {
 	body      fftypes.JSONObject
 	forceJSON bool
 	replyTx   string
+	secrets   []string
+}
+
+type whRetryOptions struct {
+	retry       retry.Retry
+	attempts    int
+	statusCodes map[int]bool
+}
+
+type whBatchEvent struct {
+	*fftypes.EventDelivery
+	Data fftypes.DataArray `json:"data,omitempty"`
 }
 
 type whResponse struct {
@@ -122,6 +146,60 @@ func (wh *WebHooks) GetOptionsSchema(ctx context.Context) string {
 					"type": "string"
 				}
 			},
+			"batch": {
+				"type": "object",
+				"description": "%s",
+				"properties": {
+					"size": {
+						"type": "integer",
+						"description": "%s"
+					},
+					"timeout": {
+						"type": "string",
+						"description": "%s"
+					}
+				}
+			},
+			"retry": {
+				"type": "object",
+				"description": "%s",
+				"properties": {
+					"attempts": {
+						"type": "integer",
+						"description": "%s"
+					},
+					"initialDelay": {
+						"type": "string",
+						"description": "%s"
+					},
+					"maxDelay": {
+						"type": "string",
+						"description": "%s"
+					},
+					"statusCodes": {
+						"type": "array",
+						"description": "%s",
+						"items": {
+							"type": "integer"
+						}
+					}
+				}
+			},
+			"signing": {
+				"type": "object",
+				"description": "%s",
+				"properties": {
+					"secrets": {
+						"type": "array",
+						"description": "%s",
+						"minItems": 1,
+						"maxItems": 2,
+						"items": {
+							"type": "string"
+						}
+					}
+				}
+			},
 			"input": {
 				"type": "object",
 				"description": "%s",
@@ -159,6 +237,16 @@ func (wh *WebHooks) GetOptionsSchema(ctx context.Context) string {
 		i18n.Expand(ctx, i18n.MsgWebhooksOptReplyTx),
 		i18n.Expand(ctx, i18n.MsgWebhooksOptHeaders),
 		i18n.Expand(ctx, i18n.MsgWebhooksOptQuery),
+		i18n.Expand(ctx, i18n.MsgWebhooksOptBatch),
+		i18n.Expand(ctx, i18n.MsgWebhooksOptBatchSize),
+		i18n.Expand(ctx, i18n.MsgWebhooksOptBatchTimeout),
+		i18n.Expand(ctx, i18n.MsgWebhooksOptRetry),
+		i18n.Expand(ctx, i18n.MsgWebhooksOptRetryAttempts),
+		i18n.Expand(ctx, i18n.MsgWebhooksOptRetryInitDelay),
+		i18n.Expand(ctx, i18n.MsgWebhooksOptRetryMaxDelay),
+		i18n.Expand(ctx, i18n.MsgWebhooksOptRetryStatusCodes),
+		i18n.Expand(ctx, i18n.MsgWebhooksOptSigning),
+		i18n.Expand(ctx, i18n.MsgWebhooksOptSigningSecrets),
 		i18n.Expand(ctx, i18n.MsgWebhooksOptInput),
 		i18n.Expand(ctx, i18n.MsgWebhooksOptInputQuery),
 		i18n.Expand(ctx, i18n.MsgWebhooksOptInputHeaders),
@@ -202,6 +290,19 @@ func (wh *WebHooks) buildRequest(options fftypes.JSONObject, firstData fftypes.J
 		}
 		_ = req.r.SetQueryParam(q, s)
 	}
+	// Signing secrets - two can be active at once, to allow rotation
+	if _, signing := options["signing"]; signing {
+		secrets, ok := options.GetObject("signing").GetStringArrayOk("secrets")
+		if !ok || len(secrets) == 0 || len(secrets) > 2 {
+			return nil, i18n.NewError(wh.ctx, i18n.MsgWebhookSigningSecrets)
+		}
+		for _, secret := range secrets {
+			if secret == "" {
+				return nil, i18n.NewError(wh.ctx, i18n.MsgWebhookSigningSecrets)
+			}
+		}
+		req.secrets = secrets
+	}
 	if firstData != nil {
 		// Options on how to process the input
 		input := options.GetObject("input")
@@ -252,15 +353,174 @@ func (wh *WebHooks) buildRequest(options fftypes.JSONObject, firstData fftypes.J
 	return req, err
 }
 
+func (req *whRequest) setBody(body interface{}) {
+	switch {
+	case len(req.secrets) > 0:
+		// We serialize the body ourselves, so the signature covers exactly the bytes we send
+		var b []byte
+		if body != nil {
+			b, _ = json.Marshal(body) // all of our bodies are JSON compatible
+			req.r.SetBody(b)
+		}
+		req.r.SetHeader(whsig.SignatureHeader, whsig.GenerateSignatureHeader(time.Now().Unix(), b, req.secrets...))
+	case body != nil:
+		req.r.SetBody(body)
+	}
+}
+
+func (wh *WebHooks) parseBatchOptions(options fftypes.JSONObject) (*events.BatchOptions, error) {
+	if _, batch := options["batch"]; !batch {
+		return nil, nil
+	}
+	batch, ok := options.GetObjectOk("batch")
+	if !ok {
+		return nil, i18n.NewError(wh.ctx, i18n.MsgWebhookInvalidBatchOptions)
+	}
+	if options.GetBool("reply") {
+		return nil, i18n.NewError(wh.ctx, i18n.MsgWebhookBatchReply)
+	}
+	batchOptions := &events.BatchOptions{
+		Size:    defaultBatchSize,
+		Timeout: defaultBatchTimeout,
+	}
+	if _, ok := batch["size"]; ok {
+		size := batch.GetInt64("size")
+		if size <= 0 {
+			return nil, i18n.NewError(wh.ctx, i18n.MsgWebhookInvalidBatchOptions)
+		}
+		batchOptions.Size = int(size)
+	}
+	if timeout := batch.GetString("timeout"); timeout != "" {
+		d, err := fftypes.ParseDurationString(timeout, time.Millisecond)
+		if err != nil || d <= 0 {
+			return nil, i18n.NewError(wh.ctx, i18n.MsgWebhookInvalidBatchOptions)
+		}
+		batchOptions.Timeout = time.Duration(d)
+	}
+	return batchOptions, nil
+}
+
+func (wh *WebHooks) parseRetryOptions(options fftypes.JSONObject) (*whRetryOptions, error) {
+	if _, retry := options["retry"]; !retry {
+		return nil, nil
+	}
+	retryOptions, ok := options.GetObjectOk("retry")
+	if !ok {
+		return nil, i18n.NewError(wh.ctx, i18n.MsgWebhookInvalidRetryOptions)
+	}
+	ro := &whRetryOptions{
+		attempts: defaultRetryAttempts,
+		retry: retry.Retry{
+			InitialDelay: defaultRetryInitialDelay,
+			MaximumDelay: defaultRetryMaxDelay,
+		},
+		statusCodes: make(map[int]bool),
+	}
+	if _, ok := retryOptions["attempts"]; ok {
+		attempts := retryOptions.GetInt64("attempts")
+		if attempts <= 0 {
+			return nil, i18n.NewError(wh.ctx, i18n.MsgWebhookInvalidRetryOptions)
+		}
+		ro.attempts = int(attempts)
+	}
+	for key, target := range map[string]*time.Duration{
+		"initialDelay": &ro.retry.InitialDelay,
+		"maxDelay":     &ro.retry.MaximumDelay,
+	} {
+		if delay := retryOptions.GetString(key); delay != "" {
+			d, err := fftypes.ParseDurationString(delay, time.Millisecond)
+			if err != nil || d < 0 {
+				return nil, i18n.NewError(wh.ctx, i18n.MsgWebhookInvalidRetryOptions)
+			}
+			*target = time.Duration(d)
+		}
+	}
+	if statusCodes, ok := retryOptions["statusCodes"]; ok {
+		codes, ok := statusCodes.([]interface{})
+		if !ok {
+			return nil, i18n.NewError(wh.ctx, i18n.MsgWebhookInvalidRetryOptions)
+		}
+		for _, c := range codes {
+			var code int
+			switch v := c.(type) {
+			case float64:
+				code = int(v)
+			case int:
+				code = v
+			}
+			if code < 100 || code > 599 {
+				return nil, i18n.NewError(wh.ctx, i18n.MsgWebhookInvalidRetryOptions)
+			}
+			ro.statusCodes[code] = true
+		}
+	}
+	return ro, nil
+}
+
+// retryable determines whether a failed attempt should be retried. Connection failures and timeouts are
+// always retried, as are the configured status codes (or all 5xx status codes if none are configured).
+func (ro *whRetryOptions) retryable(err error, status int) bool {
+	if ro == nil {
+		return false
+	}
+	if status == 0 {
+		var urlErr *url.Error
+		return errors.As(err, &urlErr)
+	}
+	if len(ro.statusCodes) == 0 {
+		return status >= 500
+	}
+	return ro.statusCodes[status]
+}
+
+// withRetry performs a delivery, retrying inside the transport with a backoff while the attempt reports
+// a retryable failure. Each retry is logged, and reported to the core for metrics. Retries stop when the
+// context of the dispatcher that requested the delivery is cancelled.
+func (wh *WebHooks) withRetry(ctx context.Context, connID string, sub *fftypes.Subscription, what string, attempt func(ro *whRetryOptions) (retryable bool, err error)) error {
+	ro, err := wh.parseRetryOptions(sub.Options.TransportOptions())
+	if err != nil {
+		// Options are validated when the subscription is created, so we just deliver once
+		log.L(ctx).Errorf("Invalid retry options for subscription %s: %s", sub.ID, err)
+	}
+	if ro == nil {
+		_, err := attempt(nil)
+		return err
+	}
+	return ro.retry.DoCustomLog(ctx, func(i int) (bool, error) {
+		retryable, err := attempt(ro)
+		if err == nil || !retryable || i >= ro.attempts {
+			return false, err
+		}
+		log.L(ctx).Warnf("Webhook delivery of %s for subscription %s failed on attempt %d/%d (will retry): %s", what, sub.ID, i, ro.attempts, err)
+		wh.callbacks.DeliveryRetry(connID, &sub.SubscriptionRef)
+		return true, err
+	})
+}
+
 func (wh *WebHooks) ValidateOptions(options *fftypes.SubscriptionOptions) error {
 	if options.WithData == nil {
 		defaultTrue := true
 		options.WithData = &defaultTrue
 	}
 	_, err := wh.buildRequest(options.TransportOptions(), fftypes.JSONObject{})
+	if err == nil {
+		_, err = wh.parseBatchOptions(options.TransportOptions())
+	}
+	if err == nil {
+		_, err = wh.parseRetryOptions(options.TransportOptions())
+	}
 	return err
 }
 
+func (wh *WebHooks) BatchOptions(sub *fftypes.Subscription) *events.BatchOptions {
+	batchOptions, err := wh.parseBatchOptions(sub.Options.TransportOptions())
+	if err != nil {
+		// Options are validated when the subscription is created, so we just deliver individually
+		log.L(wh.ctx).Errorf("Invalid batch options for subscription %s: %s", sub.ID, err)
+	}
+	return batchOptions
+}
+
 func (wh *WebHooks) attemptRequest(sub *fftypes.Subscription, event *fftypes.EventDelivery, data fftypes.DataArray) (req *whRequest, res *whResponse, err error) {
 	withData := sub.Options.WithData != nil && *sub.Options.WithData
 	allData := make([]*fftypes.JSONAny, 0, len(data))
@@ -291,22 +551,24 @@ func (wh *WebHooks) attemptRequest(sub *fftypes.Subscription, event *fftypes.Eve
 		return nil, nil, err
 	}
 
+	var body interface{}
 	if req.method == http.MethodPost || req.method == http.MethodPatch || req.method == http.MethodPut {
 		switch {
 		case !withData:
 			// We are just sending the event itself
-			req.r.SetBody(event)
+			body = event
 		case req.body != nil:
 			// We might have been told to extract a body from the first data record
-			req.r.SetBody(req.body)
+			body = req.body
 		case len(allData) > 1:
 			// We've got an array of data to POST
-			req.r.SetBody(allData)
+			body = allData
 		default:
 			// Otherwise just send the first object directly
-			req.r.SetBody(firstData)
+			body = firstData
 		}
 	}
+	req.setBody(body)
 
 	resp, err := req.r.Execute(req.method, req.url)
 	if err != nil {
@@ -350,8 +612,29 @@ func (wh *WebHooks) attemptRequest(sub *fftypes.Subscription, event *fftypes.Eve
 	return req, res, nil
 }
 
-func (wh *WebHooks) doDelivery(connID string, reply bool, sub *fftypes.Subscription, event *fftypes.EventDelivery, data fftypes.DataArray) error {
-	req, res, gwErr := wh.attemptRequest(sub, event, data)
+func (wh *WebHooks) doDelivery(ctx context.Context, connID string, reply bool, sub *fftypes.Subscription, event *fftypes.EventDelivery, data fftypes.DataArray) error {
+	var req *whRequest
+	var res *whResponse
+	var gwErr error
+	err := wh.withRetry(ctx, connID, sub, fmt.Sprintf("event %s", event.ID), func(ro *whRetryOptions) (bool, error) {
+		req, res, gwErr = wh.attemptRequest(sub, event, data)
+		status := 0
+		if res != nil {
+			status = res.Status
+		}
+		if !ro.retryable(gwErr, status) {
+			// Anything we do not retry is handled below, in the same way as a successful response
+			return false, nil
+		}
+		if gwErr == nil {
+			gwErr = i18n.NewError(wh.ctx, i18n.MsgWebhookDeliveryFailed, status)
+		}
+		return true, gwErr
+	})
+	if err != nil {
+		// We exhausted our retries, so the event must be rejected
+		return err
+	}
 	if gwErr != nil {
 		// Generate a bad-gateway error response - we always want to send something back,
 		// rather than just causing timeouts
@@ -400,7 +683,7 @@ func (wh *WebHooks) doDelivery(connID string, reply bool, sub *fftypes.Subscript
 	return nil
 }
 
-func (wh *WebHooks) DeliveryRequest(connID string, sub *fftypes.Subscription, event *fftypes.EventDelivery, data fftypes.DataArray) error {
+func (wh *WebHooks) DeliveryRequest(ctx context.Context, connID string, sub *fftypes.Subscription, event *fftypes.EventDelivery, data fftypes.DataArray) error {
 	if event.Message == nil && sub.Options.WithData != nil && *sub.Options.WithData {
 		log.L(wh.ctx).Debugf("Webhook withData=true subscription called with non-message event '%s'", event.ID)
 		return nil
@@ -423,11 +706,90 @@ func (wh *WebHooks) DeliveryRequest(connID string, sub *fftypes.Subscription, ev
 	// In fastack mode we drive calls in parallel to the backend, immediately acknowledging the event
 	if sub.Options.TransportOptions().GetBool("fastack") {
 		go func() {
-			err := wh.doDelivery(connID, reply, sub, event, data)
-			log.L(wh.ctx).Warnf("Webhook delivery failed in fastack mode for event '%s': %s", event.ID, err)
+			if err := wh.doDelivery(ctx, connID, reply, sub, event, data); err != nil {
+				log.L(wh.ctx).Warnf("Webhook delivery failed in fastack mode for event '%s': %s", event.ID, err)
+			}
 		}()
 		return nil
 	}
 
-	return wh.doDelivery(connID, reply, sub, event, data)
+	if err := wh.doDelivery(ctx, connID, reply, sub, event, data); err != nil {
+		log.L(wh.ctx).Errorf("Failed to deliver event '%s' to webhook after retries: %s", event.ID, err)
+		wh.callbacks.DeliveryResponse(connID, &fftypes.EventDeliveryResponse{
+			ID:           event.ID,
+			Rejected:     true,
+			Info:         err.Error(),
+			Subscription: event.Subscription,
+		})
+	}
+	return nil
+}
+
+func (wh *WebHooks) attemptBatchRequest(sub *fftypes.Subscription, batch []*events.EventDeliveryWithData) (int, error) {
+	req, err := wh.buildRequest(sub.Options.TransportOptions(), nil)
+	if err != nil {
+		return 0, err
+	}
+	withData := sub.Options.WithData != nil && *sub.Options.WithData
+	body := make([]*whBatchEvent, len(batch))
+	for i, e := range batch {
+		body[i] = &whBatchEvent{EventDelivery: e.Event}
+		if withData {
+			body[i].Data = e.Data
+		}
+	}
+	req.setBody(body)
+
+	resp, err := req.r.Execute(req.method, req.url)
+	if err != nil {
+		return 0, err
+	}
+	_ = resp.RawBody().Close()
+	if !resp.IsSuccess() {
+		return resp.StatusCode(), i18n.NewError(wh.ctx, i18n.MsgWebhookBatchFailed, resp.StatusCode())
+	}
+	return resp.StatusCode(), nil
+}
+
+func (wh *WebHooks) attemptBatchRequestWithRetry(ctx context.Context, connID string, sub *fftypes.Subscription, batch []*events.EventDeliveryWithData) error {
+	return wh.withRetry(ctx, connID, sub, fmt.Sprintf("batch of %d events", len(batch)), func(ro *whRetryOptions) (bool, error) {
+		status, err := wh.attemptBatchRequest(sub, batch)
+		return ro.retryable(err, status), err
+	})
+}
+
+func (wh *WebHooks) BatchDeliveryRequest(ctx context.Context, connID string, sub *fftypes.Subscription, batch []*events.EventDeliveryWithData) error {
+	respond := func(err error) {
+		info := ""
+		if err != nil {
+			info = err.Error()
+		}
+		for _, e := range batch {
+			wh.callbacks.DeliveryResponse(connID, &fftypes.EventDeliveryResponse{
+				ID:           e.Event.ID,
+				Rejected:     err != nil,
+				Info:         info,
+				Subscription: e.Event.Subscription,
+			})
+		}
+	}
+
+	// In fastack mode we acknowledge the whole batch immediately, and deliver in the background
+	if sub.Options.TransportOptions().GetBool("fastack") {
+		respond(nil)
+		go func() {
+			if err := wh.attemptBatchRequestWithRetry(ctx, connID, sub, batch); err != nil {
+				log.L(wh.ctx).Warnf("Webhook batch delivery failed in fastack mode for %d events: %s", len(batch), err)
+			}
+		}()
+		return nil
+	}
+
+	// A single response acknowledges, or rejects, the whole batch
+	err := wh.attemptBatchRequestWithRetry(ctx, connID, sub, batch)
+	if err != nil {
+		log.L(wh.ctx).Errorf("Failed to invoke webhook for batch of %d events: %s", len(batch), err)
+	}
+	respond(err)
+	return nil
 }
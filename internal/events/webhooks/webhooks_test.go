@@ -20,15 +20,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/mocks/eventsmocks"
 	"github.com/hyperledger/firefly/pkg/events"
 	"github.com/hyperledger/firefly/pkg/fftypes"
+	whsig "github.com/hyperledger/firefly/pkg/webhooks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -117,6 +121,29 @@ func TestValidateOptionsBadQuery(t *testing.T) {
 	assert.Regexp(t, "FF10243.*query", err)
 }
 
+func TestValidateOptionsBadSigningSecrets(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	opts := &fftypes.SubscriptionOptions{}
+	opts.TransportOptions()["url"] = "/anything"
+	opts.TransportOptions()["signing"] = fftypes.JSONObject{
+		"secrets": []interface{}{"s1", "s2", "s3"},
+	}
+	err := wh.ValidateOptions(opts)
+	assert.Regexp(t, "FF10379", err)
+
+	opts.TransportOptions()["signing"] = fftypes.JSONObject{
+		"secrets": []interface{}{""},
+	}
+	err = wh.ValidateOptions(opts)
+	assert.Regexp(t, "FF10379", err)
+
+	opts.TransportOptions()["signing"] = "not an object"
+	err = wh.ValidateOptions(opts)
+	assert.Regexp(t, "FF10379", err)
+}
+
 func TestRequestWithBodyReplyEndToEnd(t *testing.T) {
 	wh, cancel := newTestWebHooks(t)
 	defer cancel()
@@ -218,7 +245,7 @@ func TestRequestWithBodyReplyEndToEnd(t *testing.T) {
 		return true
 	})).Return(nil)
 
-	err := wh.DeliveryRequest(mock.Anything, sub, event, fftypes.DataArray{data})
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, fftypes.DataArray{data})
 	assert.NoError(t, err)
 
 	mcb.AssertExpectations(t)
@@ -325,7 +352,7 @@ func TestRequestWithEmptyStringBodyReplyEndToEnd(t *testing.T) {
 		return true
 	})).Return(nil)
 
-	err := wh.DeliveryRequest(mock.Anything, sub, event, fftypes.DataArray{data})
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, fftypes.DataArray{data})
 	assert.NoError(t, err)
 
 	mcb.AssertExpectations(t)
@@ -382,7 +409,94 @@ func TestRequestNoBodyNoReply(t *testing.T) {
 		}`),
 	}
 
-	err := wh.DeliveryRequest(mock.Anything, sub, event, fftypes.DataArray{data})
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, fftypes.DataArray{data})
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
+
+func TestRequestSignedWithRotatedSecrets(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	msgID := fftypes.NewUUID()
+
+	called := false
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		b, err := ioutil.ReadAll(req.Body)
+		assert.NoError(t, err)
+		sigHeader := req.Header.Get(whsig.SignatureHeader)
+		assert.Regexp(t, "^t=[0-9]+,v1=[0-9a-f]{64},v1=[0-9a-f]{64}$", sigHeader)
+		// Either secret must verify
+		assert.NoError(t, whsig.VerifySignature(context.Background(), sigHeader, b, 0, "oldsecret"))
+		assert.NoError(t, whsig.VerifySignature(context.Background(), sigHeader, b, 0, "newsecret"))
+		assert.Regexp(t, "FF10382", whsig.VerifySignature(context.Background(), sigHeader, b, 0, "wrongsecret"))
+		var body fftypes.JSONObject
+		err = json.Unmarshal(b, &body)
+		assert.NoError(t, err)
+		assert.Equal(t, msgID.String(), body.GetObject("message").GetObject("header").GetString("id"))
+		res.WriteHeader(200)
+		called = true
+	}).Methods(http.MethodPost)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	sub := &fftypes.Subscription{}
+	to := sub.Options.TransportOptions()
+	to["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+	to["signing"] = map[string]interface{}{
+		"secrets": []interface{}{"newsecret", "oldsecret"},
+	}
+	event := &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{
+				ID: fftypes.NewUUID(),
+			},
+			Message: &fftypes.Message{
+				Header: fftypes.MessageHeader{
+					ID: msgID,
+				},
+			},
+		},
+	}
+
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, fftypes.DataArray{})
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
+
+func TestRequestSignedNoBody(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	called := false
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		sigHeader := req.Header.Get(whsig.SignatureHeader)
+		assert.NoError(t, whsig.VerifySignature(context.Background(), sigHeader, []byte{}, 0, "secret"))
+		res.WriteHeader(200)
+		called = true
+	}).Methods(http.MethodGet)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	sub := &fftypes.Subscription{}
+	to := sub.Options.TransportOptions()
+	to["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+	to["method"] = http.MethodGet
+	to["signing"] = map[string]interface{}{
+		"secrets": []interface{}{"secret"},
+	}
+	event := &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{
+				ID: fftypes.NewUUID(),
+			},
+			Message: &fftypes.Message{},
+		},
+	}
+
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, fftypes.DataArray{})
 	assert.NoError(t, err)
 	assert.True(t, called)
 }
@@ -441,7 +555,7 @@ func TestRequestReplyEmptyData(t *testing.T) {
 		return true
 	})).Return(nil)
 
-	err := wh.DeliveryRequest(mock.Anything, sub, event, fftypes.DataArray{})
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, fftypes.DataArray{})
 	assert.NoError(t, err)
 	assert.True(t, called)
 }
@@ -489,7 +603,7 @@ func TestRequestReplyBadJSON(t *testing.T) {
 		return true
 	})).Return(nil)
 
-	err := wh.DeliveryRequest(mock.Anything, sub, event, fftypes.DataArray{})
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, fftypes.DataArray{})
 	assert.NoError(t, err)
 }
 func TestRequestReplyDataArrayBadStatusB64(t *testing.T) {
@@ -552,7 +666,7 @@ func TestRequestReplyDataArrayBadStatusB64(t *testing.T) {
 		return true
 	})).Return(nil)
 
-	err := wh.DeliveryRequest(mock.Anything, sub, event, fftypes.DataArray{
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, fftypes.DataArray{
 		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"value1"`)},
 		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"value2"`)},
 	})
@@ -602,7 +716,7 @@ func TestRequestReplyDataArrayError(t *testing.T) {
 		return true
 	})).Return(nil)
 
-	err := wh.DeliveryRequest(mock.Anything, sub, event, fftypes.DataArray{
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, fftypes.DataArray{
 		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"value1"`)},
 		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"value2"`)},
 	})
@@ -654,7 +768,7 @@ func TestRequestReplyBuildRequestFailFastAsk(t *testing.T) {
 		close(waiter)
 	}
 
-	err := wh.DeliveryRequest(mock.Anything, sub, event, fftypes.DataArray{
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, fftypes.DataArray{
 		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"value1"`)},
 		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"value2"`)},
 	})
@@ -688,7 +802,7 @@ func TestDeliveryRequestNilMessage(t *testing.T) {
 		},
 	}
 
-	err := wh.DeliveryRequest(mock.Anything, sub, event, nil)
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, nil)
 	assert.NoError(t, err)
 }
 
@@ -728,6 +842,480 @@ func TestDeliveryRequestReplyToReply(t *testing.T) {
 		return !response.Rejected // should be accepted as a no-op so we can move on to other events
 	}))
 
-	err := wh.DeliveryRequest(mock.Anything, sub, event, nil)
+	err := wh.DeliveryRequest(context.Background(), mock.Anything, sub, event, nil)
+	assert.NoError(t, err)
+}
+
+func TestBatchOptions(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	sub := &fftypes.Subscription{}
+	to := sub.Options.TransportOptions()
+	to["url"] = "/anything"
+	assert.Nil(t, wh.BatchOptions(sub))
+
+	to["batch"] = fftypes.JSONObject{}
+	assert.NoError(t, wh.ValidateOptions(&sub.Options))
+	assert.Equal(t, &events.BatchOptions{Size: 50, Timeout: 500 * time.Millisecond}, wh.BatchOptions(sub))
+
+	to["batch"] = fftypes.JSONObject{"size": float64(10), "timeout": "2s"}
+	assert.NoError(t, wh.ValidateOptions(&sub.Options))
+	assert.Equal(t, &events.BatchOptions{Size: 10, Timeout: 2 * time.Second}, wh.BatchOptions(sub))
+}
+
+func TestBatchOptionsInvalid(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	sub := &fftypes.Subscription{}
+	to := sub.Options.TransportOptions()
+	to["url"] = "/anything"
+
+	to["batch"] = "not an object"
+	assert.Regexp(t, "FF10386", wh.ValidateOptions(&sub.Options))
+	assert.Nil(t, wh.BatchOptions(sub))
+
+	to["batch"] = fftypes.JSONObject{"size": float64(0)}
+	assert.Regexp(t, "FF10386", wh.ValidateOptions(&sub.Options))
+
+	to["batch"] = fftypes.JSONObject{"timeout": "bad"}
+	assert.Regexp(t, "FF10386", wh.ValidateOptions(&sub.Options))
+
+	to["batch"] = fftypes.JSONObject{}
+	to["reply"] = true
+	assert.Regexp(t, "FF10387", wh.ValidateOptions(&sub.Options))
+}
+
+func newTestBatch(withData bool) (*fftypes.Subscription, []*events.EventDeliveryWithData) {
+	sub := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID()},
+		Options: fftypes.SubscriptionOptions{
+			SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+				WithData: &withData,
+			},
+		},
+	}
+	sub.Options.TransportOptions()["batch"] = fftypes.JSONObject{}
+	batch := make([]*events.EventDeliveryWithData, 3)
+	for i := range batch {
+		batch[i] = &events.EventDeliveryWithData{
+			Event: &fftypes.EventDelivery{
+				EnrichedEvent: fftypes.EnrichedEvent{
+					Event: fftypes.Event{
+						ID:       fftypes.NewUUID(),
+						Sequence: int64(i),
+					},
+				},
+				Subscription: sub.SubscriptionRef,
+			},
+			Data: fftypes.DataArray{
+				{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(fmt.Sprintf(`{"index":%d}`, i))},
+			},
+		}
+	}
+	return sub, batch
+}
+
+func TestBatchDeliveryRequestOk(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	sub, batch := newTestBatch(true)
+
+	called := false
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		b, err := ioutil.ReadAll(req.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, whsig.VerifySignature(context.Background(), req.Header.Get(whsig.SignatureHeader), b, 0, "secret"))
+		var body []fftypes.JSONObject
+		err = json.Unmarshal(b, &body)
+		assert.NoError(t, err)
+		assert.Len(t, body, 3)
+		for i, e := range body {
+			assert.Equal(t, batch[i].Event.ID.String(), e.GetString("id"))
+			assert.Equal(t, float64(i), e.GetObjectArray("data")[0].GetObject("value")["index"])
+		}
+		res.WriteHeader(204)
+		called = true
+	}).Methods(http.MethodPost)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	to := sub.Options.TransportOptions()
+	to["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+	to["signing"] = fftypes.JSONObject{"secrets": []interface{}{"secret"}}
+
+	mcb := wh.callbacks.(*eventsmocks.Callbacks)
+	for _, e := range batch {
+		mcb.On("DeliveryResponse", "conn1", &fftypes.EventDeliveryResponse{
+			ID:           e.Event.ID,
+			Subscription: sub.SubscriptionRef,
+		}).Return().Once()
+	}
+
+	err := wh.BatchDeliveryRequest(context.Background(), "conn1", sub, batch)
+	assert.NoError(t, err)
+	assert.True(t, called)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestBatchDeliveryRequestNoDataBadStatus(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	sub, batch := newTestBatch(false)
+
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		var body []fftypes.JSONObject
+		err := json.NewDecoder(req.Body).Decode(&body)
+		assert.NoError(t, err)
+		assert.Len(t, body, 3)
+		assert.Nil(t, body[0]["data"])
+		res.WriteHeader(500)
+	}).Methods(http.MethodPost)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	sub.Options.TransportOptions()["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+
+	mcb := wh.callbacks.(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", "conn1", mock.MatchedBy(func(response *fftypes.EventDeliveryResponse) bool {
+		return response.Rejected && strings.Contains(response.Info, "FF10388")
+	})).Return().Times(3)
+
+	err := wh.BatchDeliveryRequest(context.Background(), "conn1", sub, batch)
 	assert.NoError(t, err)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestBatchDeliveryRequestBadOptions(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	sub, batch := newTestBatch(false)
+
+	mcb := wh.callbacks.(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", "conn1", mock.MatchedBy(func(response *fftypes.EventDeliveryResponse) bool {
+		return response.Rejected && strings.Contains(response.Info, "FF10242")
+	})).Return().Times(3)
+
+	err := wh.BatchDeliveryRequest(context.Background(), "conn1", sub, batch)
+	assert.NoError(t, err)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestBatchDeliveryRequestFastAckFail(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	sub, batch := newTestBatch(false)
+
+	server := httptest.NewServer(mux.NewRouter())
+	server.Close()
+
+	to := sub.Options.TransportOptions()
+	to["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+	to["fastack"] = true
+
+	mcb := wh.callbacks.(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", "conn1", mock.MatchedBy(func(response *fftypes.EventDeliveryResponse) bool {
+		return !response.Rejected
+	})).Return().Times(3)
+
+	err := wh.BatchDeliveryRequest(context.Background(), "conn1", sub, batch)
+	assert.NoError(t, err)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestRetryOptionsInvalid(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	sub := &fftypes.Subscription{}
+	to := sub.Options.TransportOptions()
+	to["url"] = "/anything"
+
+	to["retry"] = "not an object"
+	assert.Regexp(t, "FF10418", wh.ValidateOptions(&sub.Options))
+
+	to["retry"] = fftypes.JSONObject{"attempts": float64(0)}
+	assert.Regexp(t, "FF10418", wh.ValidateOptions(&sub.Options))
+
+	to["retry"] = fftypes.JSONObject{"initialDelay": "bad"}
+	assert.Regexp(t, "FF10418", wh.ValidateOptions(&sub.Options))
+
+	to["retry"] = fftypes.JSONObject{"maxDelay": "-1s"}
+	assert.Regexp(t, "FF10418", wh.ValidateOptions(&sub.Options))
+
+	to["retry"] = fftypes.JSONObject{"statusCodes": "500"}
+	assert.Regexp(t, "FF10418", wh.ValidateOptions(&sub.Options))
+
+	to["retry"] = fftypes.JSONObject{"statusCodes": []interface{}{float64(503), "504"}}
+	assert.Regexp(t, "FF10418", wh.ValidateOptions(&sub.Options))
+}
+
+func TestRetryOptionsOk(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	ro, err := wh.parseRetryOptions(fftypes.JSONObject{
+		"retry": fftypes.JSONObject{
+			"attempts":     float64(3),
+			"initialDelay": "10ms",
+			"maxDelay":     "1s",
+			"statusCodes":  []interface{}{float64(429), 503},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, ro.attempts)
+	assert.Equal(t, 10*time.Millisecond, ro.retry.InitialDelay)
+	assert.Equal(t, 1*time.Second, ro.retry.MaximumDelay)
+	assert.True(t, ro.retryable(nil, 429))
+	assert.True(t, ro.retryable(nil, 503))
+	assert.False(t, ro.retryable(nil, 500))
+	assert.False(t, ro.retryable(fmt.Errorf("pop"), 0))
+
+	ro, err = wh.parseRetryOptions(fftypes.JSONObject{
+		"retry": fftypes.JSONObject{},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, defaultRetryAttempts, ro.attempts)
+	assert.True(t, ro.retryable(nil, 500))
+	assert.False(t, ro.retryable(nil, 404))
+}
+
+func newTestRetrySubscription(url string) *fftypes.Subscription {
+	sub := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+	}
+	to := sub.Options.TransportOptions()
+	to["url"] = url
+	to["retry"] = fftypes.JSONObject{
+		"attempts":     float64(3),
+		"initialDelay": "1ms",
+		"maxDelay":     "1ms",
+	}
+	return sub
+}
+
+func newTestRetryEvent(sub *fftypes.Subscription) *fftypes.EventDelivery {
+	return &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{
+				ID: fftypes.NewUUID(),
+			},
+			Message: &fftypes.Message{
+				Header: fftypes.MessageHeader{
+					ID: fftypes.NewUUID(),
+				},
+			},
+		},
+		Subscription: sub.SubscriptionRef,
+	}
+}
+
+func TestRequestRetryThenSuccess(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	calls := 0
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		calls++
+		if calls < 3 {
+			res.WriteHeader(503)
+			return
+		}
+		res.WriteHeader(200)
+	}).Methods(http.MethodPost)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	sub := newTestRetrySubscription(fmt.Sprintf("http://%s/myapi", server.Listener.Addr()))
+	event := newTestRetryEvent(sub)
+
+	mcb := wh.callbacks.(*eventsmocks.Callbacks)
+	mcb.On("DeliveryRetry", "conn1", &sub.SubscriptionRef).Return().Twice()
+
+	err := wh.DeliveryRequest(context.Background(), "conn1", sub, event, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestRequestRetryExhaustedNack(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	calls := 0
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		calls++
+		res.WriteHeader(500)
+	}).Methods(http.MethodPost)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	sub := newTestRetrySubscription(fmt.Sprintf("http://%s/myapi", server.Listener.Addr()))
+	sub.Options.TransportOptions()["reply"] = true
+	event := newTestRetryEvent(sub)
+
+	mcb := wh.callbacks.(*eventsmocks.Callbacks)
+	mcb.On("DeliveryRetry", "conn1", &sub.SubscriptionRef).Return().Twice()
+	mcb.On("DeliveryResponse", "conn1", mock.MatchedBy(func(response *fftypes.EventDeliveryResponse) bool {
+		return response.Rejected && response.Reply == nil && strings.Contains(response.Info, "FF10419")
+	})).Return()
+
+	err := wh.DeliveryRequest(context.Background(), "conn1", sub, event, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestRequestRetryStopsWhenDispatcherClosed(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	calls := 0
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		calls++
+		res.WriteHeader(503)
+	}).Methods(http.MethodPost)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	sub := newTestRetrySubscription(fmt.Sprintf("http://%s/myapi", server.Listener.Addr()))
+	sub.Options.TransportOptions()["retry"] = fftypes.JSONObject{
+		"attempts":     float64(3),
+		"initialDelay": "1h",
+		"maxDelay":     "1h",
+	}
+	event := newTestRetryEvent(sub)
+
+	dispatcherCtx, closeDispatcher := context.WithCancel(context.Background())
+	mcb := wh.callbacks.(*eventsmocks.Callbacks)
+	mcb.On("DeliveryRetry", "conn1", &sub.SubscriptionRef).Run(func(args mock.Arguments) {
+		closeDispatcher()
+	}).Return().Once()
+	mcb.On("DeliveryResponse", "conn1", mock.MatchedBy(func(response *fftypes.EventDeliveryResponse) bool {
+		return response.Rejected
+	})).Return()
+
+	err := wh.DeliveryRequest(dispatcherCtx, "conn1", sub, event, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestRequestRetryNotRetryableStatus(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	calls := 0
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		calls++
+		res.WriteHeader(404)
+	}).Methods(http.MethodPost)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	sub := newTestRetrySubscription(fmt.Sprintf("http://%s/myapi", server.Listener.Addr()))
+	event := newTestRetryEvent(sub)
+
+	err := wh.DeliveryRequest(context.Background(), "conn1", sub, event, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRequestRetryConnectionFailureFastAck(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	server := httptest.NewServer(mux.NewRouter())
+	server.Close()
+
+	sub := newTestRetrySubscription(fmt.Sprintf("http://%s/myapi", server.Listener.Addr()))
+	sub.Options.TransportOptions()["fastack"] = true
+	event := newTestRetryEvent(sub)
+
+	mcb := wh.callbacks.(*eventsmocks.Callbacks)
+	retried := make(chan struct{}, 2)
+	mcb.On("DeliveryRetry", "conn1", &sub.SubscriptionRef).Run(func(args mock.Arguments) {
+		retried <- struct{}{}
+	}).Return()
+
+	err := wh.DeliveryRequest(context.Background(), "conn1", sub, event, nil)
+	assert.NoError(t, err)
+	<-retried
+	<-retried
+}
+
+func TestRequestInvalidRetryOptionsDeliversOnce(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	calls := 0
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		calls++
+		res.WriteHeader(500)
+	}).Methods(http.MethodPost)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	sub := newTestRetrySubscription(fmt.Sprintf("http://%s/myapi", server.Listener.Addr()))
+	sub.Options.TransportOptions()["retry"] = "bad"
+	event := newTestRetryEvent(sub)
+
+	err := wh.DeliveryRequest(context.Background(), "conn1", sub, event, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestBatchDeliveryRequestRetryExhausted(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	sub, batch := newTestBatch(false)
+
+	calls := 0
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		calls++
+		res.WriteHeader(429)
+	}).Methods(http.MethodPost)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	to := sub.Options.TransportOptions()
+	to["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+	to["retry"] = fftypes.JSONObject{
+		"attempts":     float64(2),
+		"initialDelay": "1ms",
+		"statusCodes":  []interface{}{float64(429)},
+	}
+
+	mcb := wh.callbacks.(*eventsmocks.Callbacks)
+	mcb.On("DeliveryRetry", "conn1", &sub.SubscriptionRef).Return().Once()
+	mcb.On("DeliveryResponse", "conn1", mock.MatchedBy(func(response *fftypes.EventDeliveryResponse) bool {
+		return response.Rejected && strings.Contains(response.Info, "FF10388")
+	})).Return().Times(3)
+
+	err := wh.BatchDeliveryRequest(context.Background(), "conn1", sub, batch)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	mcb.AssertExpectations(t)
 }
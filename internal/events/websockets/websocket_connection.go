@@ -25,6 +25,7 @@ import (
 	"sync"
 
 	"github.com/gorilla/websocket"
+	"github.com/hyperledger/firefly/internal/auth"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -36,6 +37,12 @@ type websocketStartedSub struct {
 	namespace string
 }
 
+// websocketInflight tracks an event delivered to the client, that it has not yet acknowledged
+type websocketInflight struct {
+	*fftypes.EventDeliveryResponse
+	sequence int64
+}
+
 type websocketConnection struct {
 	ctx                context.Context
 	ws                 *WebSockets
@@ -47,13 +54,14 @@ type websocketConnection struct {
 	receiverDone       chan struct{}
 	autoAck            bool
 	started            []*websocketStartedSub
-	inflight           []*fftypes.EventDeliveryResponse
+	inflight           []*websocketInflight
 	mux                sync.Mutex
 	closed             bool
 	changeEventMatcher *regexp.Regexp
+	principal          *auth.Principal
 }
 
-func newConnection(pCtx context.Context, ws *WebSockets, wsConn *websocket.Conn) *websocketConnection {
+func newConnection(pCtx context.Context, ws *WebSockets, wsConn *websocket.Conn, principal *auth.Principal) *websocketConnection {
 	connID := fftypes.NewUUID().String()
 	ctx := log.WithLogField(pCtx, "websocket", connID)
 	ctx, cancelCtx := context.WithCancel(ctx)
@@ -66,6 +74,7 @@ func newConnection(pCtx context.Context, ws *WebSockets, wsConn *websocket.Conn)
 		sendMessages: make(chan interface{}),
 		senderDone:   make(chan struct{}),
 		receiverDone: make(chan struct{}),
+		principal:    principal,
 	}
 	go wc.sendLoop()
 	go wc.receiveLoop()
@@ -192,7 +201,10 @@ func (wc *websocketConnection) dispatch(event *fftypes.EventDelivery) error {
 	wc.mux.Lock()
 	autoAck = wc.autoAck
 	if !autoAck {
-		wc.inflight = append(wc.inflight, inflight)
+		wc.inflight = append(wc.inflight, &websocketInflight{
+			EventDeliveryResponse: inflight,
+			sequence:              event.Sequence,
+		})
 	}
 	wc.mux.Unlock()
 
@@ -232,6 +244,13 @@ func (wc *websocketConnection) send(msg interface{}) error {
 }
 
 func (wc *websocketConnection) handleStart(start *fftypes.WSClientActionStartPayload) (err error) {
+	// When authentication is enabled on the API server, the connection can only listen on namespaces it can read
+	if wc.principal != nil {
+		if err := wc.principal.Authorize(wc.ctx, start.Namespace, auth.RoleRead); err != nil {
+			return err
+		}
+	}
+
 	wc.mux.Lock()
 	if start.AutoAck != nil {
 		if *start.AutoAck != wc.autoAck && len(wc.started) > 0 {
@@ -275,6 +294,21 @@ func (wc *websocketConnection) durableSubMatcher(sr fftypes.SubscriptionRef) boo
 	return false
 }
 
+// ackMatchesSubscriptionLocked checks whether an ack applies to the subscription of an in-flight event
+func (wc *websocketConnection) ackMatchesSubscriptionLocked(ack *fftypes.WSClientActionAckPayload, candidate *websocketInflight) (bool, error) {
+	if ack.Subscription != nil {
+		// A subscription has been explicitly specified, so it must match
+		return (ack.Subscription.ID != nil && *ack.Subscription.ID == *candidate.Subscription.ID) ||
+			(ack.Subscription.Name == candidate.Subscription.Name && ack.Subscription.Namespace == candidate.Subscription.Namespace), nil
+	}
+	// If there's more than one started subscription, that's a problem
+	if len(wc.started) != 1 {
+		log.L(wc.ctx).Errorf("No subscription specified on ack, and there is not exactly one started subscription")
+		return false, i18n.NewError(wc.ctx, i18n.MsgWSMsgSubNotMatched)
+	}
+	return true, nil
+}
+
 func (wc *websocketConnection) checkAck(ack *fftypes.WSClientActionAckPayload) (*fftypes.EventDeliveryResponse, error) {
 	l := log.L(wc.ctx)
 	var inflight *fftypes.EventDeliveryResponse
@@ -286,28 +320,18 @@ func (wc *websocketConnection) checkAck(ack *fftypes.WSClientActionAckPayload) (
 	}
 
 	if ack.ID != nil {
-		newInflight := make([]*fftypes.EventDeliveryResponse, 0, len(wc.inflight))
+		newInflight := make([]*websocketInflight, 0, len(wc.inflight))
 		for _, candidate := range wc.inflight {
 			var match bool
 			if *candidate.ID == *ack.ID {
-				if ack.Subscription != nil {
-					// A subscription has been explicitly specified, so it must match
-					if (ack.Subscription.ID != nil && *ack.Subscription.ID == *candidate.Subscription.ID) ||
-						(ack.Subscription.Name == candidate.Subscription.Name && ack.Subscription.Namespace == candidate.Subscription.Namespace) {
-						match = true
-					}
-				} else {
-					// If there's more than one started subscription, that's a problem
-					if len(wc.started) != 1 {
-						l.Errorf("No subscription specified on ack, and there is not exactly one started subscription")
-						return nil, i18n.NewError(wc.ctx, i18n.MsgWSMsgSubNotMatched)
-					}
-					match = true
+				var err error
+				if match, err = wc.ackMatchesSubscriptionLocked(ack, candidate); err != nil {
+					return nil, err
 				}
 			}
 			// Remove from the inflight list
 			if match {
-				inflight = candidate
+				inflight = candidate.EventDeliveryResponse
 			} else {
 				newInflight = append(newInflight, candidate)
 			}
@@ -318,7 +342,7 @@ func (wc *websocketConnection) checkAck(ack *fftypes.WSClientActionAckPayload) (
 		if len(wc.inflight) == 0 {
 			l.Errorf("Ack received, but no messages in flight")
 		} else {
-			inflight = wc.inflight[0]
+			inflight = wc.inflight[0].EventDeliveryResponse
 			wc.inflight = wc.inflight[1:]
 		}
 	}
@@ -328,7 +352,52 @@ func (wc *websocketConnection) checkAck(ack *fftypes.WSClientActionAckPayload) (
 	return inflight, nil
 }
 
+// checkCumulativeAck removes all events in-flight for the subscription, up to and including the sequence
+// in the ack, and returns the ID of the subscription they were delivered for
+func (wc *websocketConnection) checkCumulativeAck(ack *fftypes.WSClientActionAckPayload) (*fftypes.UUID, error) {
+	wc.mux.Lock()
+	defer wc.mux.Unlock()
+
+	if wc.autoAck {
+		return nil, i18n.NewError(wc.ctx, i18n.MsgWSAutoAckEnabled)
+	}
+	if ack.ID != nil {
+		return nil, i18n.NewError(wc.ctx, i18n.MsgWSCumulativeAckWithID)
+	}
+
+	var subID *fftypes.UUID
+	newInflight := make([]*websocketInflight, 0, len(wc.inflight))
+	for _, candidate := range wc.inflight {
+		match := false
+		if candidate.sequence <= *ack.UpToSequence && (subID == nil || *subID == *candidate.Subscription.ID) {
+			var err error
+			if match, err = wc.ackMatchesSubscriptionLocked(ack, candidate); err != nil {
+				return nil, err
+			}
+		}
+		if match {
+			subID = candidate.Subscription.ID
+		} else {
+			newInflight = append(newInflight, candidate)
+		}
+	}
+	if subID == nil {
+		return nil, i18n.NewError(wc.ctx, i18n.MsgWSMsgSubNotMatched)
+	}
+	wc.inflight = newInflight
+	return subID, nil
+}
+
 func (wc *websocketConnection) handleAck(ack *fftypes.WSClientActionAckPayload) error {
+	if ack.UpToSequence != nil {
+		subID, err := wc.checkCumulativeAck(ack)
+		if err != nil {
+			return err
+		}
+		wc.ws.cumulativeAck(wc.connID, subID, *ack.UpToSequence)
+		return nil
+	}
+
 	// Perform a locked set of check
 	inflight, err := wc.checkAck(ack)
 	if err != nil {
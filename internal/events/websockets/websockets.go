@@ -22,6 +22,7 @@ import (
 	"sync"
 
 	"github.com/gorilla/websocket"
+	"github.com/hyperledger/firefly/internal/auth"
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
@@ -78,7 +79,7 @@ func (ws *WebSockets) ValidateOptions(options *fftypes.SubscriptionOptions) erro
 	return nil
 }
 
-func (ws *WebSockets) DeliveryRequest(connID string, sub *fftypes.Subscription, event *fftypes.EventDelivery, data fftypes.DataArray) error {
+func (ws *WebSockets) DeliveryRequest(ctx context.Context, connID string, sub *fftypes.Subscription, event *fftypes.EventDelivery, data fftypes.DataArray) error {
 	ws.connMux.Lock()
 	conn, ok := ws.connections[connID]
 	ws.connMux.Unlock()
@@ -108,7 +109,7 @@ func (ws *WebSockets) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 	}
 
 	ws.connMux.Lock()
-	wc := newConnection(ws.ctx, ws, wsConn)
+	wc := newConnection(ws.ctx, ws, wsConn, auth.GetPrincipal(req.Context()))
 	ws.connections[wc.connID] = wc
 	ws.connMux.Unlock()
 
@@ -119,6 +120,10 @@ func (ws *WebSockets) ack(connID string, inflight *fftypes.EventDeliveryResponse
 	ws.callbacks.DeliveryResponse(connID, inflight)
 }
 
+func (ws *WebSockets) cumulativeAck(connID string, subID *fftypes.UUID, sequence int64) {
+	ws.callbacks.CumulativeAck(connID, subID, sequence)
+}
+
 func (ws *WebSockets) start(wc *websocketConnection, start *fftypes.WSClientActionStartPayload) error {
 	if start.Namespace == "" || (!start.Ephemeral && start.Name == "") {
 		return i18n.NewError(ws.ctx, i18n.MsgWSInvalidStartAction)
@@ -26,6 +26,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/hyperledger/firefly/internal/auth"
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/config/wsconfig"
 	"github.com/hyperledger/firefly/internal/log"
@@ -181,7 +182,7 @@ func TestStartReceiveAckEphemeral(t *testing.T) {
 	assert.NoError(t, err)
 
 	<-waitSubscribed
-	ws.DeliveryRequest(connID, nil, &fftypes.EventDelivery{
+	ws.DeliveryRequest(context.Background(), connID, nil, &fftypes.EventDelivery{
 		EnrichedEvent: fftypes.EnrichedEvent{
 			Event: fftypes.Event{ID: fftypes.NewUUID()},
 		},
@@ -231,7 +232,7 @@ func TestStartReceiveDurable(t *testing.T) {
 	assert.NoError(t, err)
 
 	<-waitSubscribed
-	ws.DeliveryRequest(connID, nil, &fftypes.EventDelivery{
+	ws.DeliveryRequest(context.Background(), connID, nil, &fftypes.EventDelivery{
 		EnrichedEvent: fftypes.EnrichedEvent{
 			Event: fftypes.Event{ID: fftypes.NewUUID()},
 		},
@@ -242,7 +243,7 @@ func TestStartReceiveDurable(t *testing.T) {
 		},
 	}, nil)
 	// Put a second in flight
-	ws.DeliveryRequest(connID, nil, &fftypes.EventDelivery{
+	ws.DeliveryRequest(context.Background(), connID, nil, &fftypes.EventDelivery{
 		EnrichedEvent: fftypes.EnrichedEvent{
 			Event: fftypes.Event{ID: fftypes.NewUUID()},
 		},
@@ -280,6 +281,68 @@ func TestStartReceiveDurable(t *testing.T) {
 	cbs.AssertExpectations(t)
 }
 
+func TestStartReceiveDurableCumulativeAck(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	ws, wsc, cancel := newTestWebsockets(t, cbs)
+	defer cancel()
+	var connID string
+	sub := cbs.On("RegisterConnection",
+		mock.MatchedBy(func(s string) bool { connID = s; return true }),
+		mock.Anything,
+	).Return(nil)
+	subID := fftypes.NewUUID()
+	ack := cbs.On("CumulativeAck",
+		mock.MatchedBy(func(s string) bool { return s == connID }),
+		subID, int64(2)).Return(nil)
+
+	waitSubscribed := make(chan struct{})
+	sub.RunFn = func(a mock.Arguments) {
+		close(waitSubscribed)
+	}
+
+	waitAcked := make(chan struct{})
+	ack.RunFn = func(a mock.Arguments) {
+		close(waitAcked)
+	}
+
+	err := wsc.Send(context.Background(), []byte(`{"type":"start","namespace":"ns1","name":"sub1"}`))
+	assert.NoError(t, err)
+
+	<-waitSubscribed
+	for seq := int64(1); seq <= 3; seq++ {
+		go ws.DeliveryRequest(context.Background(), connID, nil, &fftypes.EventDelivery{
+			EnrichedEvent: fftypes.EnrichedEvent{
+				Event: fftypes.Event{ID: fftypes.NewUUID(), Sequence: seq},
+			},
+			Subscription: fftypes.SubscriptionRef{
+				ID:        subID,
+				Namespace: "ns1",
+				Name:      "sub1",
+			},
+		}, nil)
+		<-wsc.Receive()
+	}
+
+	err = wsc.Send(context.Background(), []byte(`{
+		"type":"ack",
+		"upToSequence": 2,
+		"subscription": {
+			"namespace": "ns1",
+			"name": "sub1"
+		}
+	}`))
+	assert.NoError(t, err)
+
+	<-waitAcked
+
+	// Check we left the right one behind
+	conn := ws.connections[connID]
+	assert.Equal(t, 1, len(conn.inflight))
+	assert.Equal(t, int64(3), conn.inflight[0].sequence)
+
+	cbs.AssertExpectations(t)
+}
+
 func TestAutoStartReceiveAckEphemeral(t *testing.T) {
 	var connID string
 	cbs := &eventsmocks.Callbacks{}
@@ -304,7 +367,7 @@ func TestAutoStartReceiveAckEphemeral(t *testing.T) {
 	defer cancel()
 
 	<-waitSubscribed
-	ws.DeliveryRequest(connID, nil, &fftypes.EventDelivery{
+	ws.DeliveryRequest(context.Background(), connID, nil, &fftypes.EventDelivery{
 		EnrichedEvent: fftypes.EnrichedEvent{
 			Event: fftypes.Event{ID: fftypes.NewUUID()},
 		},
@@ -336,14 +399,72 @@ func TestAutoStartBadOptions(t *testing.T) {
 	cbs.AssertExpectations(t)
 }
 
+func TestAutoStartUnauthorizedNamespace(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	config.Reset()
+	ws := &WebSockets{}
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	defer cancelCtx()
+	svrPrefix := config.NewPluginConfig("ut.websockets")
+	ws.InitPrefix(svrPrefix)
+	ws.Init(ctx, svrPrefix, cbs)
+	cbs.On("ConnnectionClosed", mock.Anything).Return(nil).Maybe()
+
+	principal, err := auth.NewPrincipal(ctx, "app1", []string{"ns1:read"})
+	assert.NoError(t, err)
+	svr := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		ws.ServeHTTP(res, req.WithContext(auth.WithPrincipal(req.Context(), principal)))
+	}))
+	defer svr.Close()
+
+	clientPrefix := config.NewPluginConfig("ut.wsclient")
+	wsconfig.InitPrefix(clientPrefix)
+	clientPrefix.Set(restclient.HTTPConfigURL, fmt.Sprintf("http://%s?ephemeral&namespace=ns2", svr.Listener.Addr()))
+	wsc, err := wsclient.New(ctx, wsconfig.GenerateConfigFromPrefix(clientPrefix), nil, nil)
+	assert.NoError(t, err)
+	err = wsc.Connect()
+	assert.NoError(t, err)
+	defer wsc.Close()
+
+	b := <-wsc.Receive()
+	var res fftypes.WSProtocolErrorPayload
+	err = json.Unmarshal(b, &res)
+	assert.NoError(t, err)
+	assert.Regexp(t, "FF10397.*app1.*ns2", res.Error)
+	cbs.AssertExpectations(t)
+}
+
+func TestHandleStartAuthorizedNamespace(t *testing.T) {
+	mcb := &eventsmocks.Callbacks{}
+	principal, err := auth.NewPrincipal(context.Background(), "app1", []string{"ns1:read"})
+	assert.NoError(t, err)
+	ws := &WebSockets{
+		ctx:       context.Background(),
+		callbacks: mcb,
+	}
+	wsc := &websocketConnection{
+		ctx:       context.Background(),
+		connID:    fftypes.NewUUID().String(),
+		ws:        ws,
+		principal: principal,
+	}
+	mcb.On("EphemeralSubscription", wsc.connID, "ns1", mock.Anything, mock.Anything).Return(nil)
+	err = wsc.handleStart(&fftypes.WSClientActionStartPayload{
+		Ephemeral: true,
+		Namespace: "ns1",
+	})
+	assert.NoError(t, err)
+	mcb.AssertExpectations(t)
+}
+
 func TestHandleAckWithAutoAck(t *testing.T) {
 	eventUUID := fftypes.NewUUID()
 	wsc := &websocketConnection{
 		ctx:          context.Background(),
 		started:      []*websocketStartedSub{{ephemeral: false, name: "name1", namespace: "ns1"}},
 		sendMessages: make(chan interface{}, 1),
-		inflight: []*fftypes.EventDeliveryResponse{
-			{ID: eventUUID},
+		inflight: []*websocketInflight{
+			{EventDeliveryResponse: &fftypes.EventDeliveryResponse{ID: eventUUID}},
 		},
 		autoAck: true,
 	}
@@ -359,8 +480,8 @@ func TestHandleStartFlippingAutoAck(t *testing.T) {
 		ctx:          context.Background(),
 		started:      []*websocketStartedSub{{ephemeral: false, name: "name1", namespace: "ns1"}},
 		sendMessages: make(chan interface{}, 1),
-		inflight: []*fftypes.EventDeliveryResponse{
-			{ID: eventUUID},
+		inflight: []*websocketInflight{
+			{EventDeliveryResponse: &fftypes.EventDeliveryResponse{ID: eventUUID}},
 		},
 		autoAck: true,
 	}
@@ -446,8 +567,8 @@ func TestHandleStartWithBadChangeEventsRegex(t *testing.T) {
 		connID:       "conn1",
 		started:      []*websocketStartedSub{{ephemeral: false, name: "name1", namespace: "ns1"}},
 		sendMessages: make(chan interface{}, 1),
-		inflight: []*fftypes.EventDeliveryResponse{
-			{ID: eventUUID},
+		inflight: []*websocketInflight{
+			{EventDeliveryResponse: &fftypes.EventDeliveryResponse{ID: eventUUID}},
 		},
 		autoAck: true,
 		ws: &WebSockets{
@@ -480,8 +601,8 @@ func TestHandleAckMultipleStartedMissingSub(t *testing.T) {
 			{ephemeral: false, name: "name3", namespace: "ns1"},
 		},
 		sendMessages: make(chan interface{}, 1),
-		inflight: []*fftypes.EventDeliveryResponse{
-			{ID: eventUUID},
+		inflight: []*websocketInflight{
+			{EventDeliveryResponse: &fftypes.EventDeliveryResponse{ID: eventUUID}},
 		},
 	}
 	err := wsc.handleAck(&fftypes.WSClientActionAckPayload{
@@ -503,8 +624,8 @@ func TestHandleAckMultipleStartedNoSubSingleMatch(t *testing.T) {
 		},
 		started:      []*websocketStartedSub{{ephemeral: false, name: "name1", namespace: "ns1"}},
 		sendMessages: make(chan interface{}, 1),
-		inflight: []*fftypes.EventDeliveryResponse{
-			{ID: eventUUID},
+		inflight: []*websocketInflight{
+			{EventDeliveryResponse: &fftypes.EventDeliveryResponse{ID: eventUUID}},
 		},
 	}
 	err := wsc.handleAck(&fftypes.WSClientActionAckPayload{
@@ -518,12 +639,98 @@ func TestHandleAckNoneInflight(t *testing.T) {
 	wsc := &websocketConnection{
 		ctx:          context.Background(),
 		sendMessages: make(chan interface{}, 1),
-		inflight:     []*fftypes.EventDeliveryResponse{},
+		inflight:     []*websocketInflight{},
 	}
 	err := wsc.handleAck(&fftypes.WSClientActionAckPayload{})
 	assert.Regexp(t, "FF10175", err)
 }
 
+func TestHandleCumulativeAckWithAutoAck(t *testing.T) {
+	upTo := int64(10)
+	wsc := &websocketConnection{
+		ctx:     context.Background(),
+		autoAck: true,
+	}
+	err := wsc.handleAck(&fftypes.WSClientActionAckPayload{
+		UpToSequence: &upTo,
+	})
+	assert.Regexp(t, "FF10180", err)
+}
+
+func TestHandleCumulativeAckWithID(t *testing.T) {
+	upTo := int64(10)
+	wsc := &websocketConnection{
+		ctx: context.Background(),
+	}
+	err := wsc.handleAck(&fftypes.WSClientActionAckPayload{
+		ID:           fftypes.NewUUID(),
+		UpToSequence: &upTo,
+	})
+	assert.Regexp(t, "FF10412", err)
+}
+
+func TestHandleCumulativeAckMultipleStartedMissingSub(t *testing.T) {
+	upTo := int64(10)
+	wsc := &websocketConnection{
+		ctx: context.Background(),
+		started: []*websocketStartedSub{
+			{ephemeral: false, name: "name1", namespace: "ns1"},
+			{ephemeral: false, name: "name2", namespace: "ns1"},
+		},
+		inflight: []*websocketInflight{
+			{EventDeliveryResponse: &fftypes.EventDeliveryResponse{ID: fftypes.NewUUID()}, sequence: 5},
+		},
+	}
+	err := wsc.handleAck(&fftypes.WSClientActionAckPayload{
+		UpToSequence: &upTo,
+	})
+	assert.Regexp(t, "FF10175", err)
+	assert.Len(t, wsc.inflight, 1)
+}
+
+func TestHandleCumulativeAckSingleSubscription(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	sub1 := fftypes.NewUUID()
+	sub2 := fftypes.NewUUID()
+	cbs.On("CumulativeAck", "conn1", sub1, int64(10)).Return(nil)
+	upTo := int64(10)
+	wsc := &websocketConnection{
+		ctx:    context.Background(),
+		connID: "conn1",
+		ws: &WebSockets{
+			ctx:       context.Background(),
+			callbacks: cbs,
+		},
+		started: []*websocketStartedSub{{ephemeral: true, namespace: "ns1"}},
+		inflight: []*websocketInflight{
+			{EventDeliveryResponse: &fftypes.EventDeliveryResponse{ID: fftypes.NewUUID(), Subscription: fftypes.SubscriptionRef{ID: sub1}}, sequence: 5},
+			{EventDeliveryResponse: &fftypes.EventDeliveryResponse{ID: fftypes.NewUUID(), Subscription: fftypes.SubscriptionRef{ID: sub2}}, sequence: 6},
+			{EventDeliveryResponse: &fftypes.EventDeliveryResponse{ID: fftypes.NewUUID(), Subscription: fftypes.SubscriptionRef{ID: sub1}}, sequence: 10},
+			{EventDeliveryResponse: &fftypes.EventDeliveryResponse{ID: fftypes.NewUUID(), Subscription: fftypes.SubscriptionRef{ID: sub1}}, sequence: 11},
+		},
+	}
+	err := wsc.handleAck(&fftypes.WSClientActionAckPayload{
+		UpToSequence: &upTo,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, wsc.inflight, 2)
+	assert.Equal(t, int64(6), wsc.inflight[0].sequence)
+	assert.Equal(t, int64(11), wsc.inflight[1].sequence)
+	cbs.AssertExpectations(t)
+}
+
+func TestHandleCumulativeAckNoneInflight(t *testing.T) {
+	upTo := int64(10)
+	wsc := &websocketConnection{
+		ctx:      context.Background(),
+		inflight: []*websocketInflight{},
+	}
+	err := wsc.handleAck(&fftypes.WSClientActionAckPayload{
+		UpToSequence: &upTo,
+	})
+	assert.Regexp(t, "FF10175", err)
+}
+
 func TestProtocolErrorSwallowsSendError(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
@@ -587,7 +794,7 @@ func TestWebsocketDispatchAfterClose(t *testing.T) {
 		ctx:         context.Background(),
 		connections: make(map[string]*websocketConnection),
 	}
-	err := ws.DeliveryRequest("gone", nil, &fftypes.EventDelivery{}, nil)
+	err := ws.DeliveryRequest(context.Background(), "gone", nil, &fftypes.EventDelivery{}, nil)
 	assert.Regexp(t, "FF10173", err)
 }
 
@@ -607,7 +814,7 @@ func TestDispatchAutoAck(t *testing.T) {
 		autoAck:      true,
 	}
 	wsc.ws.connections[wsc.connID] = wsc
-	err := wsc.ws.DeliveryRequest(wsc.connID, nil, &fftypes.EventDelivery{
+	err := wsc.ws.DeliveryRequest(context.Background(), wsc.connID, nil, &fftypes.EventDelivery{
 		EnrichedEvent: fftypes.EnrichedEvent{
 			Event: fftypes.Event{ID: fftypes.NewUUID()},
 		},
@@ -292,4 +292,77 @@ var (
 	MsgBlobMissingPublic            = ffm("FF10373", "Blob for data %s missing public payload reference while flushing batch", 500)
 	MsgDBMultiRowConfigError        = ffm("FF10374", "Database invalid configuration - using multi-row insert on DB plugin that does not support query syntax for input")
 	MsgDBNoSequence                 = ffm("FF10375", "Failed to retrieve sequence for insert row %d (could mean duplicate insert)", 500)
+	MsgSubscriptionNotActive        = ffm("FF10376", "Subscription '%s' is not currently being delivered on any connection", 409)
+	MsgWebhooksOptSigning           = ffm("FF10377", "Options for signing the webhook request with a timestamped HMAC-SHA256 signature in the X-FireFly-Signature header")
+	MsgWebhooksOptSigningSecrets    = ffm("FF10378", "The active signing secrets. Supply two secrets during a rotation, and both signatures are sent")
+	MsgWebhookSigningSecrets        = ffm("FF10379", "Webhook subscription option 'signing.secrets' must be an array of one or two non-empty strings", 400)
+	MsgWebhookSignatureInvalid      = ffm("FF10380", "Webhook signature header is missing or malformed", 401)
+	MsgWebhookSignatureExpired      = ffm("FF10381", "Webhook signature timestamp %d is outside of the allowed tolerance", 401)
+	MsgWebhookSignatureMismatch     = ffm("FF10382", "Webhook signature does not match any of the supplied secrets", 401)
+	MsgWebhooksOptBatch             = ffm("FF10383", "Deliver events in batches, as a JSON array in a single request. A successful response acknowledges the whole batch, and any failure rejects it")
+	MsgWebhooksOptBatchSize         = ffm("FF10384", "The maximum number of events in a batch. Default=50")
+	MsgWebhooksOptBatchTimeout      = ffm("FF10385", "The maximum time to wait for a batch to fill, after the first event arrives. Default=500ms")
+	MsgWebhookInvalidBatchOptions   = ffm("FF10386", "Webhook subscription option 'batch' must be an object with a positive 'size' and 'timeout'", 400)
+	MsgWebhookBatchReply            = ffm("FF10387", "Webhook subscription option 'reply' cannot be used with 'batch'", 400)
+	MsgWebhookBatchFailed           = ffm("FF10388", "Webhook batch delivery failed with status %d")
+	MsgInvalidRetentionPolicy       = ffm("FF10389", "Invalid retention policy at index %d: %s")
+	MsgInvalidCursor                = ffm("FF10390", "Invalid pagination cursor '%s'", 400)
+	MsgCursorAfterAndBefore         = ffm("FF10391", "Only one of 'after' or 'before' can be specified", 400)
+	MsgCursorSkipOrSort             = ffm("FF10392", "Cursor pagination cannot be combined with 'skip', or with a sort on fields other than 'sequence'", 400)
+	MsgFilterAfterDesc              = ffm("FF10393", "Cursor from the 'next' field of a previous response, to return the page that follows it. Supply an empty value to begin cursor pagination from the first page")
+	MsgFilterBeforeDesc             = ffm("FF10394", "Cursor from the 'previous' field of a previous response, to return the page that precedes it")
+	MsgCursorNotSupported           = ffm("FF10395", "Cursor pagination is not supported when querying %s", 400)
+	MsgAuthUnauthorized             = ffm("FF10396", "Authentication required. Supply a valid API key or JWT bearer token", 401)
+	MsgAuthForbidden                = ffm("FF10397", "Identity '%s' does not have the '%s' role for namespace '%s'", 403)
+	MsgAuthInvalidConfig            = ffm("FF10398", "Invalid authentication configuration: %s")
+	MsgAuthInvalidJWT               = ffm("FF10399", "Invalid JWT: %s", 401)
+	MsgAuthInvalidRole              = ffm("FF10400", "Invalid role '%s' for namespace '%s'. Must be 'read', 'write' or 'admin'")
+	MsgInvalidOperationRetryPolicy  = ffm("FF10401", "Invalid operation retry policy at index %d: %s")
+	MsgOperationStaleNoRecord       = ffm("FF10402", "Operation was submitted more than %s ago, and plugin '%s' has no record of it")
+	MsgMissingBlockchainConfig      = ffm("FF10403", "Invalid blockchain configuration at index %d - name and plugin are required", 400)
+	MsgDuplicateBlockchainPlugin    = ffm("FF10404", "Duplicate blockchain plugin name '%s'", 400)
+	MsgNamespaceBlockchainNotFound  = ffm("FF10405", "Namespace '%s' is bound to unknown blockchain plugin '%s'", 400)
+	MsgSSENoData                    = ffm("FF10406", "Server-Sent Events subscriptions do not support streaming the full data payload, just the references (withData must be false)", 400)
+	MsgSSEInvalidStart              = ffm("FF10407", "The namespace query parameter must be set, along with either name or ephemeral", 400)
+	MsgSSEConnectionNotActive       = ffm("FF10408", "Server-Sent Events connection '%s' no longer active", 404)
+	MsgSSEStreamingUnsupported      = ffm("FF10409", "The HTTP server does not support streaming responses")
+	MsgSSEInvalidLastEventID        = ffm("FF10410", "Invalid Last-Event-ID '%s' - must be an event sequence", 400)
+	MsgSubscriptionResetInvalid     = ffm("FF10411", "Exactly one of firstEvent or timestamp must be set to reset a subscription", 400)
+	MsgWSCumulativeAckWithID        = ffm("FF10412", "A cumulative ack with upToSequence cannot also specify an event id")
+	MsgWebhooksOptRetry             = ffm("FF10413", "Retry failed deliveries inside the transport with an exponential backoff, before the events are rejected")
+	MsgWebhooksOptRetryAttempts     = ffm("FF10414", "The maximum number of delivery attempts, including the first. Default=5")
+	MsgWebhooksOptRetryInitDelay    = ffm("FF10415", "The delay before the first retry. Default=250ms")
+	MsgWebhooksOptRetryMaxDelay     = ffm("FF10416", "The maximum delay between retries. Default=30s")
+	MsgWebhooksOptRetryStatusCodes  = ffm("FF10417", "The HTTP status codes to retry, in addition to connection failures and timeouts. Default=all 5xx status codes")
+	MsgWebhookInvalidRetryOptions   = ffm("FF10418", "Webhook subscription option 'retry' must be an object with a positive 'attempts', valid 'initialDelay' and 'maxDelay' durations, and 'statusCodes' between 100 and 599", 400)
+	MsgWebhookDeliveryFailed        = ffm("FF10419", "Webhook delivery failed with status %d")
+	MsgWSNotConnected               = ffm("FF10420", "Websocket connection for plugin '%s' is not established")
+	MsgDBHealthCheckFailed          = ffm("FF10421", "Database health check failed")
+	MsgNodeNotReady                 = ffm("FF10422", "Node is not ready: %s", 503)
+	MsgTracingInvalidExporter       = ffm("FF10423", "Invalid tracing exporter '%s'")
+	MsgTracingFilePathRequired      = ffm("FF10424", "A file path must be configured when using the file tracing exporter")
+	MsgTracingFileOpenFailed        = ffm("FF10425", "Failed to open trace file '%s'")
+	MsgTracingExporterFailed        = ffm("FF10426", "Failed to create '%s' tracing exporter")
+	MsgArchiveReadFailed            = ffm("FF10427", "Failed to read archive entry '%s'", 400)
+	MsgArchiveBadManifest           = ffm("FF10428", "Archive must begin with a manifest of version %d", 400)
+	MsgArchiveUnknownEntry          = ffm("FF10429", "Unknown entry '%s' in archive", 400)
+	MsgArchiveHashMismatch          = ffm("FF10430", "Hash mismatch importing %s '%s'", 400)
+	MsgArchiveBlobSizeMismatch      = ffm("FF10431", "Blob for data '%s' was %d bytes, expected %d")
+	MsgArchiveBlobUnknownData       = ffm("FF10432", "Archive contains a blob for data '%s', which is not in the archive or the database", 400)
+	MsgArchiveRequestFailed         = ffm("FF10433", "Request to admin API at '%s' failed with status %d: %s")
+	MsgContractDeployUnsupported    = ffm("FF10434", "Smart contract deployment is not supported by this blockchain plugin", 400)
+	MsgContractDeployNoInterface    = ffm("FF10435", "An 'interface' is required to register a contract API for the deployed contract", 400)
+	MsgContractAPIExists            = ffm("FF10436", "A contract API already exists in the namespace: '%s' with name: '%s'", 409)
+	MsgContractBytecodeInvalid      = ffm("FF10437", "Invalid contract bytecode: %s", 400)
+	MsgJSONRPCError                 = ffm("FF10438", "Error from JSON/RPC node calling '%s': %s")
+	MsgListenerFilterParamUnknown   = ffm("FF10439", "Listener filter refers to '%s', which is not a parameter of event '%s'", 400)
+	MsgListenerFilterValueInvalid   = ffm("FF10440", "Invalid listener filter value for parameter '%s': %s", 400)
+	MsgListenerFilterUnsupported    = ffm("FF10441", "Listener filter on indexed parameter '%s' of type '%s' is not supported", 400)
+	MsgHistoricalQueryUnsupported   = ffm("FF10442", "Blockchain plugin '%s' does not support queries at a specific block", 400)
+	MsgQueryBlockNotForInvoke       = ffm("FF10443", "A block or blockchain event can only be specified for query requests", 400)
+	MsgQueryBlockConflict           = ffm("FF10444", "Only one of 'block' and 'blockchainEvent' can be specified", 400)
+	MsgQueryBlockchainEventNotFound = ffm("FF10445", "Blockchain event '%s' not found", 404)
+	MsgQueryBlockchainEventNoBlock  = ffm("FF10446", "Blockchain event '%s' does not record the block it occurred in", 400)
+	MsgTokensBlockchainNotFound     = ffm("FF10447", "Tokens plugin '%s' is bound to unknown blockchain plugin '%s'", 400)
+	MsgListenerFilterAmbiguous      = ffm("FF10448", "Listener filter for parameter '%s' must be a single value, {\"anyOf\": [...]} to match any of a list of values, or {\"equals\": ...} to match an array or object exactly", 400)
 )
@@ -33,6 +33,10 @@ type Manager interface {
 	MessageConfirmed(msg *fftypes.Message, eventType fftypes.FFEnum)
 	TransferSubmitted(transfer *fftypes.TokenTransfer)
 	TransferConfirmed(transfer *fftypes.TokenTransfer)
+	CountPruned(ns, collection string, count int)
+	SubscriptionStatus(ns, name string, status *fftypes.SubscriptionStatus)
+	DeleteSubscriptionStatus(ns, name string)
+	CountDeliveryRetry(ns, name, transport string)
 	AddTime(id string)
 	GetTime(id string) time.Time
 	DeleteTime(id string)
@@ -129,6 +133,38 @@ func (mm *metricsManager) TransferConfirmed(transfer *fftypes.TokenTransfer) {
 	}
 }
 
+func (mm *metricsManager) CountPruned(ns, collection string, count int) {
+	RetentionPrunedCounter.WithLabelValues(ns, collection).Add(float64(count))
+}
+
+func (mm *metricsManager) SubscriptionStatus(ns, name string, status *fftypes.SubscriptionStatus) {
+	SubscriptionOffsetGauge.WithLabelValues(ns, name).Set(float64(status.CurrentOffset))
+	SubscriptionHighestSequenceGauge.WithLabelValues(ns, name).Set(float64(status.HighestSequence))
+	SubscriptionLagGauge.WithLabelValues(ns, name).Set(float64(status.Lag))
+	SubscriptionInflightGauge.WithLabelValues(ns, name).Set(float64(status.Inflight))
+	SubscriptionConnectionsGauge.WithLabelValues(ns, name).Set(float64(len(status.Connections)))
+	SubscriptionNacksGauge.WithLabelValues(ns, name).Set(float64(status.Nacks))
+	if status.LastDelivery != nil {
+		SubscriptionLastDeliveryGauge.WithLabelValues(ns, name).Set(float64(status.LastDelivery.UnixNano()) / float64(time.Second))
+	}
+}
+
+// DeleteSubscriptionStatus removes the status gauges of a subscription that is no longer active,
+// so that the last values are not reported indefinitely
+func (mm *metricsManager) DeleteSubscriptionStatus(ns, name string) {
+	SubscriptionOffsetGauge.DeleteLabelValues(ns, name)
+	SubscriptionHighestSequenceGauge.DeleteLabelValues(ns, name)
+	SubscriptionLagGauge.DeleteLabelValues(ns, name)
+	SubscriptionInflightGauge.DeleteLabelValues(ns, name)
+	SubscriptionConnectionsGauge.DeleteLabelValues(ns, name)
+	SubscriptionNacksGauge.DeleteLabelValues(ns, name)
+	SubscriptionLastDeliveryGauge.DeleteLabelValues(ns, name)
+}
+
+func (mm *metricsManager) CountDeliveryRetry(ns, name, transport string) {
+	SubscriptionDeliveryRetryCounter.WithLabelValues(ns, name, transport).Inc()
+}
+
 func (mm *metricsManager) AddTime(id string) {
 	mutex.Lock()
 	mm.timeMap[id] = time.Now()
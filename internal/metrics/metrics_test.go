@@ -68,6 +68,35 @@ func TestCountBatchPin(t *testing.T) {
 	mm.CountBatchPin()
 }
 
+func TestCountPruned(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+	mm.CountPruned("ns1", "messages", 10)
+}
+
+func TestSubscriptionStatus(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+	mm.SubscriptionStatus("ns1", "sub1", &fftypes.SubscriptionStatus{
+		CurrentOffset:   10,
+		HighestSequence: 15,
+		Lag:             5,
+		Connections:     []string{"conn1"},
+	})
+	mm.SubscriptionStatus("ns1", "sub1", &fftypes.SubscriptionStatus{
+		LastDelivery: fftypes.Now(),
+	})
+	mm.DeleteSubscriptionStatus("ns1", "sub1")
+	assert.False(t, SubscriptionLagGauge.DeleteLabelValues("ns1", "sub1"))
+	assert.False(t, SubscriptionLastDeliveryGauge.DeleteLabelValues("ns1", "sub1"))
+}
+
+func TestCountDeliveryRetry(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+	mm.CountDeliveryRetry("ns1", "sub1", "webhooks")
+}
+
 func TestMessageSubmittedBroadcast(t *testing.T) {
 	mm, cancel := newTestMetricsManager(t)
 	defer cancel()
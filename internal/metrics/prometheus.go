@@ -80,6 +80,8 @@ func initMetricsCollectors() {
 	InitTokenTransferMetrics()
 	InitTokenBurnMetrics()
 	InitBatchPinMetrics()
+	InitRetentionMetrics()
+	InitSubscriptionMetrics()
 }
 
 func registerMetricsCollectors() {
@@ -92,4 +94,6 @@ func registerMetricsCollectors() {
 	RegisterTokenMintMetrics()
 	RegisterTokenTransferMetrics()
 	RegisterTokenBurnMetrics()
+	RegisterRetentionMetrics()
+	RegisterSubscriptionMetrics()
 }
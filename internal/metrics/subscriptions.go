@@ -0,0 +1,100 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var SubscriptionOffsetGauge *prometheus.GaugeVec
+var SubscriptionHighestSequenceGauge *prometheus.GaugeVec
+var SubscriptionLagGauge *prometheus.GaugeVec
+var SubscriptionInflightGauge *prometheus.GaugeVec
+var SubscriptionConnectionsGauge *prometheus.GaugeVec
+var SubscriptionNacksGauge *prometheus.GaugeVec
+var SubscriptionLastDeliveryGauge *prometheus.GaugeVec
+var SubscriptionDeliveryRetryCounter *prometheus.CounterVec
+
+// MetricsSubscriptionOffset is the prometheus metric for the current offset of a subscription
+var MetricsSubscriptionOffset = "ff_subscription_offset"
+
+// MetricsSubscriptionHighestSequence is the prometheus metric for the highest event sequence available to a subscription
+var MetricsSubscriptionHighestSequence = "ff_subscription_highest_sequence"
+
+// MetricsSubscriptionLag is the prometheus metric for the number of sequences a subscription is behind the latest event
+var MetricsSubscriptionLag = "ff_subscription_lag"
+
+// MetricsSubscriptionInflight is the prometheus metric for the number of events in-flight to a subscription
+var MetricsSubscriptionInflight = "ff_subscription_inflight"
+
+// MetricsSubscriptionConnections is the prometheus metric for the number of connections bound to a subscription
+var MetricsSubscriptionConnections = "ff_subscription_connections"
+
+// MetricsSubscriptionNacks is the prometheus metric for the number of events rejected by a subscription
+var MetricsSubscriptionNacks = "ff_subscription_nacks"
+
+// MetricsSubscriptionLastDelivery is the prometheus metric for the time of the last delivery to a subscription, in seconds since the epoch
+var MetricsSubscriptionLastDelivery = "ff_subscription_last_delivery_seconds"
+
+// MetricsSubscriptionDeliveryRetries is the prometheus metric for the total number of deliveries retried by a transport
+var MetricsSubscriptionDeliveryRetries = "ff_subscription_delivery_retries_total"
+
+func InitSubscriptionMetrics() {
+	SubscriptionOffsetGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: MetricsSubscriptionOffset,
+		Help: "Current offset of the subscription",
+	}, []string{"ns", "subscription"})
+	SubscriptionHighestSequenceGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: MetricsSubscriptionHighestSequence,
+		Help: "Highest event sequence available to the subscription",
+	}, []string{"ns", "subscription"})
+	SubscriptionLagGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: MetricsSubscriptionLag,
+		Help: "Number of sequences between the subscription offset and the highest available event",
+	}, []string{"ns", "subscription"})
+	SubscriptionInflightGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: MetricsSubscriptionInflight,
+		Help: "Number of events delivered to the subscription and awaiting a response",
+	}, []string{"ns", "subscription"})
+	SubscriptionConnectionsGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: MetricsSubscriptionConnections,
+		Help: "Number of connections bound to the subscription",
+	}, []string{"ns", "subscription"})
+	SubscriptionNacksGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: MetricsSubscriptionNacks,
+		Help: "Number of events rejected by the subscription since its dispatchers started",
+	}, []string{"ns", "subscription"})
+	SubscriptionLastDeliveryGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: MetricsSubscriptionLastDelivery,
+		Help: "Time of the last event delivery to the subscription, in seconds since the epoch",
+	}, []string{"ns", "subscription"})
+	SubscriptionDeliveryRetryCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: MetricsSubscriptionDeliveryRetries,
+		Help: "Number of failed deliveries to the subscription retried by the transport",
+	}, []string{"ns", "subscription", "transport"})
+}
+
+func RegisterSubscriptionMetrics() {
+	registry.MustRegister(SubscriptionOffsetGauge)
+	registry.MustRegister(SubscriptionHighestSequenceGauge)
+	registry.MustRegister(SubscriptionLagGauge)
+	registry.MustRegister(SubscriptionInflightGauge)
+	registry.MustRegister(SubscriptionConnectionsGauge)
+	registry.MustRegister(SubscriptionNacksGauge)
+	registry.MustRegister(SubscriptionLastDeliveryGauge)
+	registry.MustRegister(SubscriptionDeliveryRetryCounter)
+}
@@ -249,6 +249,8 @@ func addRoute(ctx context.Context, doc *openapi3.T, route *Route) {
 		addParam(ctx, op, "query", "skip", "", "", i18n.MsgFilterSkipDesc, false, config.GetUint(config.APIMaxFilterSkip))
 		addParam(ctx, op, "query", "limit", "", config.GetString(config.APIDefaultFilterLimit), i18n.MsgFilterLimitDesc, false, config.GetUint(config.APIMaxFilterLimit))
 		addParam(ctx, op, "query", "count", "", "", i18n.MsgFilterCountDesc, false)
+		addParam(ctx, op, "query", "after", "", "", i18n.MsgFilterAfterDesc, false)
+		addParam(ctx, op, "query", "before", "", "", i18n.MsgFilterBeforeDesc, false)
 	}
 	switch route.Method {
 	case http.MethodGet:
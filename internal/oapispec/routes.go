@@ -63,6 +63,12 @@ type Route struct {
 	FormUploadHandler func(r *APIRequest) (output interface{}, err error)
 	// Deprecated whether this route is deprecated
 	Deprecated bool
+	// ReadOnly marks a route that does not change any state, so only requires the read role even if it is not a GET
+	ReadOnly bool
+	// Unauthenticated marks a route that is served without authentication, such as a health probe
+	Unauthenticated bool
+	// NoDeadlines marks a route that transfers a stream of unbounded size, so the read and write deadlines of the server are cleared
+	NoDeadlines bool
 }
 
 // PathParam is a description of a path parameter
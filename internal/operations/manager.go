@@ -19,6 +19,7 @@ package operations
 import (
 	"context"
 
+	"github.com/hyperledger/firefly/internal/blockchain/birouter"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/pkg/database"
@@ -38,24 +39,38 @@ type Manager interface {
 	RunOperation(ctx context.Context, op *fftypes.PreparedOperation) error
 	RetryOperation(ctx context.Context, ns string, opID *fftypes.UUID) (*fftypes.Operation, error)
 	AddOrReuseOperation(ctx context.Context, op *fftypes.Operation) error
+	OperationFailed(ctx context.Context, opID *fftypes.UUID, errorMessage string)
+	Start() error
+	WaitStop()
 }
 
 type operationsManager struct {
-	ctx      context.Context
-	database database.Plugin
-	tokens   map[string]tokens.Plugin
-	handlers map[fftypes.OpType]OperationHandler
+	ctx            context.Context
+	database       database.Plugin
+	blockchains    birouter.Router
+	tokens         map[string]tokens.Plugin
+	updater        OperationUpdater
+	handlers       map[fftypes.OpType]OperationHandler
+	retryPolicies  map[fftypes.OpType]*retryPolicy
+	reconcilerDone chan struct{}
+	retryLoopDone  chan struct{}
 }
 
-func NewOperationsManager(ctx context.Context, di database.Plugin, ti map[string]tokens.Plugin) (Manager, error) {
-	if di == nil || ti == nil {
+func NewOperationsManager(ctx context.Context, di database.Plugin, br birouter.Router, ti map[string]tokens.Plugin, updater OperationUpdater) (Manager, error) {
+	if di == nil || br == nil || ti == nil || updater == nil {
 		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
 	}
 	om := &operationsManager{
-		ctx:      ctx,
-		database: di,
-		tokens:   ti,
-		handlers: make(map[fftypes.OpType]OperationHandler),
+		ctx:           ctx,
+		database:      di,
+		blockchains:   br,
+		tokens:        ti,
+		updater:       updater,
+		handlers:      make(map[fftypes.OpType]OperationHandler),
+		retryPolicies: make(map[fftypes.OpType]*retryPolicy),
+	}
+	if err := om.initRetryPolicies(ctx); err != nil {
+		return nil, err
 	}
 	return om, nil
 }
@@ -84,6 +99,7 @@ func (om *operationsManager) RunOperation(ctx context.Context, op *fftypes.Prepa
 	log.L(ctx).Tracef("Operation detail: %+v", op)
 	if complete, err := handler.RunOperation(ctx, op); err != nil {
 		om.writeOperationFailure(ctx, op.ID, err)
+		om.scheduleRetry(ctx, op.ID, op.Type, err.Error())
 		return err
 	} else if complete {
 		om.writeOperationSuccess(ctx, op.ID)
@@ -103,6 +119,10 @@ func (om *operationsManager) findLatestRetry(ctx context.Context, opID *fftypes.
 }
 
 func (om *operationsManager) RetryOperation(ctx context.Context, ns string, opID *fftypes.UUID) (op *fftypes.Operation, err error) {
+	return om.retryOperation(ctx, opID)
+}
+
+func (om *operationsManager) retryOperation(ctx context.Context, opID *fftypes.UUID) (op *fftypes.Operation, err error) {
 	var po *fftypes.PreparedOperation
 	err = om.database.RunAsGroup(ctx, func(ctx context.Context) error {
 		op, err = om.findLatestRetry(ctx, opID)
@@ -115,14 +135,17 @@ func (om *operationsManager) RetryOperation(ctx context.Context, ns string, opID
 		op.Status = fftypes.OpStatusPending
 		op.Error = ""
 		op.Output = nil
+		op.RetryAfter = nil
 		op.Created = fftypes.Now()
 		op.Updated = op.Created
 		if err = om.database.InsertOperation(ctx, op); err != nil {
 			return err
 		}
 
-		// Update the old operation to point to the new one
-		update := database.OperationQueryFactory.NewUpdate(ctx).Set("retry", op.ID)
+		// Update the old operation to point to the new one, replacing any scheduled retry
+		update := database.OperationQueryFactory.NewUpdate(ctx).
+			Set("retry", op.ID).
+			Set("retryafter", nil)
 		if err = om.database.UpdateOperation(ctx, opID, update); err != nil {
 			return err
 		}
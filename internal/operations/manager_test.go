@@ -20,7 +20,10 @@ import (
 	"testing"
 
 	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/biroutermocks"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/eventmocks"
 	"github.com/hyperledger/firefly/mocks/tokenmocks"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -50,6 +53,7 @@ func (m *mockHandler) RunOperation(ctx context.Context, op *fftypes.PreparedOper
 func newTestOperations(t *testing.T) (*operationsManager, func()) {
 	config.Reset()
 	mdi := &databasemocks.Plugin{}
+	mbi := &blockchainmocks.Plugin{}
 	mti := &tokenmocks.Plugin{}
 
 	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything).Maybe()
@@ -59,15 +63,19 @@ func newTestOperations(t *testing.T) (*operationsManager, func()) {
 		}
 	}
 
+	mbi.On("Name").Return("ut_blockchain").Maybe()
+	mbr := &biroutermocks.Router{}
+	mbr.On("ForNamespace", mock.Anything).Return(mbi).Maybe()
+	mbr.On("NamedForNamespace", mock.Anything).Return(mbi).Maybe()
 	mti.On("Name").Return("ut_tokens").Maybe()
 	ctx, cancel := context.WithCancel(context.Background())
-	om, err := NewOperationsManager(ctx, mdi, map[string]tokens.Plugin{"magic-tokens": mti})
+	om, err := NewOperationsManager(ctx, mdi, mbr, map[string]tokens.Plugin{"magic-tokens": mti}, &eventmocks.EventManager{})
 	assert.NoError(t, err)
 	return om.(*operationsManager), cancel
 }
 
 func TestInitFail(t *testing.T) {
-	_, err := NewOperationsManager(context.Background(), nil, nil)
+	_, err := NewOperationsManager(context.Background(), nil, nil, nil, nil)
 	assert.Regexp(t, "FF10128", err)
 }
 
@@ -192,11 +200,15 @@ func TestRetryOperationSuccess(t *testing.T) {
 	mdi.On("UpdateOperation", ctx, op.ID, mock.MatchedBy(func(update database.Update) bool {
 		info, err := update.Finalize()
 		assert.NoError(t, err)
-		assert.Equal(t, 1, len(info.SetOperations))
+		assert.Equal(t, 2, len(info.SetOperations))
 		assert.Equal(t, "retry", info.SetOperations[0].Field)
 		val, err := info.SetOperations[0].Value.Value()
 		assert.NoError(t, err)
 		assert.Equal(t, op.ID.String(), val)
+		assert.Equal(t, "retryafter", info.SetOperations[1].Field)
+		val, err = info.SetOperations[1].Value.Value()
+		assert.NoError(t, err)
+		assert.Nil(t, val)
 		return true
 	})).Return(nil)
 
@@ -0,0 +1,142 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"context"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// OperationUpdater receives the resolved status of operations, in the same way as an
+// asynchronous receipt from a plugin
+type OperationUpdater interface {
+	OperationUpdate(plugin fftypes.Named, operationID *fftypes.UUID, txState fftypes.OpStatus, blockchainTXID, errorMessage string, opOutput fftypes.JSONObject) error
+}
+
+type statusQueryFn func(ctx context.Context, operationID *fftypes.UUID) (*fftypes.OperationUpdate, error)
+
+func (om *operationsManager) Start() error {
+	if config.GetBool(config.OperationsReconcilerEnabled) {
+		om.reconcilerDone = make(chan struct{})
+		go om.reconcilerLoop()
+	}
+	if len(om.retryPolicies) > 0 {
+		om.retryLoopDone = make(chan struct{})
+		go om.retryLoop()
+	}
+	return nil
+}
+
+func (om *operationsManager) WaitStop() {
+	if om.reconcilerDone != nil {
+		<-om.reconcilerDone
+		om.reconcilerDone = nil
+	}
+	if om.retryLoopDone != nil {
+		<-om.retryLoopDone
+		om.retryLoopDone = nil
+	}
+}
+
+func (om *operationsManager) reconcilerLoop() {
+	defer close(om.reconcilerDone)
+
+	interval := config.GetDuration(config.OperationsReconcilerInterval)
+	for {
+		if err := om.reconcileStaleOperations(om.ctx); err != nil {
+			log.L(om.ctx).Errorf("Operation reconciliation failed: %s", err)
+		}
+		select {
+		case <-time.After(interval):
+		case <-om.ctx.Done():
+			log.L(om.ctx).Debugf("Operation reconciler exiting")
+			return
+		}
+	}
+}
+
+// statusQuerier returns the plugin that owns the operation, if that plugin is able to report
+// the status of operations it has previously been asked to perform
+func (om *operationsManager) statusQuerier(op *fftypes.Operation) (fftypes.Named, statusQueryFn) {
+	if op.Plugin == om.blockchains.NamedForNamespace(op.Namespace).Name() {
+		bi := om.blockchains.ForNamespace(op.Namespace)
+		if q, ok := bi.(blockchain.OperationStatusQuerier); ok {
+			return bi, q.GetOperationStatus
+		}
+	}
+	return nil, nil
+}
+
+// reconcileStaleOperations finds operations that have been pending for longer than the configured
+// timeout, and asks the owning plugin what happened to them. This recovers operations where the
+// receipt from the connector was lost, such as during a restart.
+func (om *operationsManager) reconcileStaleOperations(ctx context.Context) error {
+	cutoff := fftypes.FFTime(time.Now().Add(-config.GetDuration(config.OperationsReconcilerPendingTimeout)))
+	fb := database.OperationQueryFactory.NewFilter(ctx)
+	filter := fb.And(
+		fb.Eq("status", fftypes.OpStatusPending),
+		fb.Lt("updated", cutoff),
+	).Sort("updated").Limit(uint64(config.GetInt(config.OperationsReconcilerBatchSize)))
+	ops, _, err := om.database.GetOperations(ctx, filter)
+	if err != nil {
+		return err
+	}
+	for _, op := range ops {
+		if err := om.reconcileOperation(ctx, op); err != nil {
+			// Continue with the rest of the batch - this operation will be picked up again next time
+			log.L(ctx).Errorf("Failed to reconcile operation %s: %s", op.ID, err)
+		}
+	}
+	return nil
+}
+
+func (om *operationsManager) reconcileOperation(ctx context.Context, op *fftypes.Operation) error {
+	plugin, queryStatus := om.statusQuerier(op)
+	if queryStatus == nil {
+		log.L(ctx).Debugf("Plugin '%s' does not support status queries for stale operation %s", op.Plugin, op.ID)
+		return nil
+	}
+	update, err := queryStatus(ctx, op.ID)
+	if err != nil {
+		return err
+	}
+	if update == nil {
+		// No record might only mean the operation has not completed yet (such as a transaction still
+		// waiting to be mined), so we only give up on it after a much longer timeout
+		noRecordTimeout := config.GetDuration(config.OperationsReconcilerNoRecordTimeout)
+		if op.Created != nil && time.Since(*op.Created.Time()) < noRecordTimeout {
+			log.L(ctx).Debugf("Plugin '%s' has no record yet of stale operation %s", op.Plugin, op.ID)
+			return nil
+		}
+		log.L(ctx).Warnf("Plugin '%s' has no record of stale operation %s", op.Plugin, op.ID)
+		errMsg := i18n.NewError(ctx, i18n.MsgOperationStaleNoRecord, noRecordTimeout, op.Plugin).Error()
+		return om.updater.OperationUpdate(plugin, op.ID, fftypes.OpStatusFailed, "", errMsg, nil)
+	}
+	if update.Status == fftypes.OpStatusPending {
+		log.L(ctx).Debugf("Stale operation %s is still pending in plugin '%s'", op.ID, op.Plugin)
+		return nil
+	}
+	log.L(ctx).Infof("Reconciled stale operation %s with status %s from plugin '%s'", op.ID, update.Status, op.Plugin)
+	return om.updater.OperationUpdate(plugin, op.ID, update.Status, update.BlockchainTXID, update.ErrorMessage, update.Output)
+}
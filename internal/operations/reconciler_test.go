@@ -0,0 +1,150 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/biroutermocks"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/eventmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type queryingBlockchain struct {
+	*blockchainmocks.Plugin
+	*blockchainmocks.OperationStatusQuerier
+}
+
+func newTestReconciler(t *testing.T) (*operationsManager, *blockchainmocks.OperationStatusQuerier, func()) {
+	om, cancel := newTestOperations(t)
+	mbi := &blockchainmocks.Plugin{}
+	mbi.On("Name").Return("ut_blockchain").Maybe()
+	mbq := &blockchainmocks.OperationStatusQuerier{}
+	mbr := &biroutermocks.Router{}
+	mbr.On("ForNamespace", mock.Anything).Return(&queryingBlockchain{Plugin: mbi, OperationStatusQuerier: mbq})
+	mbr.On("NamedForNamespace", mock.Anything).Return(mbi)
+	om.blockchains = mbr
+	return om, mbq, cancel
+}
+
+func TestReconcilerDisabled(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	err := om.Start()
+	assert.NoError(t, err)
+	assert.Nil(t, om.reconcilerDone)
+	om.WaitStop()
+}
+
+func TestReconcilerStartStop(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	config.Set(config.OperationsReconcilerEnabled, true)
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Run(func(args mock.Arguments) {
+		cancel()
+	})
+
+	err := om.Start()
+	assert.NoError(t, err)
+	om.WaitStop()
+	assert.Nil(t, om.reconcilerDone)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestReconcileStaleOperations(t *testing.T) {
+	om, mbq, cancel := newTestReconciler(t)
+	defer cancel()
+
+	longAgo := fftypes.FFTime(time.Now().Add(-48 * time.Hour))
+	opSucceeded := &fftypes.Operation{ID: fftypes.NewUUID(), Plugin: "ut_blockchain"}
+	opPending := &fftypes.Operation{ID: fftypes.NewUUID(), Plugin: "ut_blockchain"}
+	opNoRecord := &fftypes.Operation{ID: fftypes.NewUUID(), Plugin: "ut_blockchain", Created: &longAgo}
+	opNoRecordYet := &fftypes.Operation{ID: fftypes.NewUUID(), Plugin: "ut_blockchain", Created: fftypes.Now()}
+	opQueryFail := &fftypes.Operation{ID: fftypes.NewUUID(), Plugin: "ut_blockchain"}
+	opUnknown := &fftypes.Operation{ID: fftypes.NewUUID(), Plugin: "ut_dx"}
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{
+		opSucceeded, opPending, opNoRecord, opNoRecordYet, opQueryFail, opUnknown,
+	}, nil, nil)
+
+	output := fftypes.JSONObject{"some": "info"}
+	mbq.On("GetOperationStatus", mock.Anything, opSucceeded.ID).Return(&fftypes.OperationUpdate{
+		Status:         fftypes.OpStatusSucceeded,
+		BlockchainTXID: "0x12345",
+		Output:         output,
+	}, nil)
+	mbq.On("GetOperationStatus", mock.Anything, opPending.ID).Return(&fftypes.OperationUpdate{
+		Status: fftypes.OpStatusPending,
+	}, nil)
+	mbq.On("GetOperationStatus", mock.Anything, opNoRecord.ID).Return(nil, nil)
+	mbq.On("GetOperationStatus", mock.Anything, opNoRecordYet.ID).Return(nil, nil)
+	mbq.On("GetOperationStatus", mock.Anything, opQueryFail.ID).Return(nil, fmt.Errorf("pop"))
+
+	mem := om.updater.(*eventmocks.EventManager)
+	mem.On("OperationUpdate", om.blockchains.ForNamespace("ns1"), opSucceeded.ID, fftypes.OpStatusSucceeded, "0x12345", "", output).Return(nil)
+	mem.On("OperationUpdate", om.blockchains.ForNamespace("ns1"), opNoRecord.ID, fftypes.OpStatusFailed, "", mock.MatchedBy(func(errMsg string) bool {
+		return regexp.MustCompile("FF10402.*ut_blockchain").MatchString(errMsg)
+	}), fftypes.JSONObject(nil)).Return(nil)
+
+	err := om.reconcileStaleOperations(context.Background())
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mbq.AssertExpectations(t)
+	mem.AssertExpectations(t)
+}
+
+func TestReconcileStaleOperationsQueryFail(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := om.reconcileStaleOperations(context.Background())
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestReconcileOperationNoQuerier(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	// Neither of the plain plugin mocks support status queries
+	for _, op := range []*fftypes.Operation{
+		{ID: fftypes.NewUUID(), Plugin: "ut_blockchain"},
+		{ID: fftypes.NewUUID(), Plugin: "ut_tokens", Input: fftypes.JSONObject{"connector": "magic-tokens"}},
+		{ID: fftypes.NewUUID(), Plugin: "ut_tokens", Input: fftypes.JSONObject{"connector": "other"}},
+	} {
+		err := om.reconcileOperation(context.Background(), op)
+		assert.NoError(t, err)
+	}
+}
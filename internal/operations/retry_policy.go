@@ -0,0 +1,248 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"regexp"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// retryPolicyConf is a single entry in the operations.retry.policies config, where any
+// unset fields take the defaults from operations.retry.*
+type retryPolicyConf struct {
+	Type         string   `json:"type"`
+	MaxAttempts  *int     `json:"maxAttempts,omitempty"`
+	InitialDelay string   `json:"initialDelay,omitempty"`
+	MaxDelay     string   `json:"maxDelay,omitempty"`
+	Factor       *float64 `json:"factor,omitempty"`
+	Errors       []string `json:"errors,omitempty"`
+}
+
+type retryPolicy struct {
+	maxAttempts  int
+	initialDelay time.Duration
+	maxDelay     time.Duration
+	factor       float64
+	errors       []*regexp.Regexp
+}
+
+func parseRetryDelay(ctx context.Context, i int, value string, defValue time.Duration) (time.Duration, error) {
+	if value == "" {
+		return defValue, nil
+	}
+	d, err := fftypes.ParseDurationString(value, time.Millisecond)
+	if err != nil || d <= 0 {
+		return 0, i18n.NewError(ctx, i18n.MsgInvalidOperationRetryPolicy, i, value)
+	}
+	return time.Duration(d), nil
+}
+
+func parseRetryPolicy(ctx context.Context, i int, conf *retryPolicyConf) (opType fftypes.OpType, policy *retryPolicy, err error) {
+	for _, v := range fftypes.FFEnumValues("optype") {
+		if fftypes.OpType(v.(string)).Equals(fftypes.OpType(conf.Type)) {
+			opType = fftypes.OpType(v.(string))
+		}
+	}
+	if opType == "" {
+		return "", nil, i18n.NewError(ctx, i18n.MsgInvalidOperationRetryPolicy, i, "unknown type '"+conf.Type+"'")
+	}
+	policy = &retryPolicy{
+		maxAttempts: config.GetInt(config.OperationsRetryMaxAttempts),
+		factor:      config.GetFloat64(config.OperationsRetryFactor),
+	}
+	if conf.MaxAttempts != nil {
+		policy.maxAttempts = *conf.MaxAttempts
+	}
+	if conf.Factor != nil {
+		policy.factor = *conf.Factor
+	}
+	if policy.maxAttempts < 1 || policy.factor < 1 {
+		return "", nil, i18n.NewError(ctx, i18n.MsgInvalidOperationRetryPolicy, i, "maxAttempts must be at least 1, and factor must be at least 1.0")
+	}
+	if policy.initialDelay, err = parseRetryDelay(ctx, i, conf.InitialDelay, config.GetDuration(config.OperationsRetryInitialDelay)); err != nil {
+		return "", nil, err
+	}
+	if policy.maxDelay, err = parseRetryDelay(ctx, i, conf.MaxDelay, config.GetDuration(config.OperationsRetryMaxDelay)); err != nil {
+		return "", nil, err
+	}
+	errPatterns := conf.Errors
+	if errPatterns == nil {
+		errPatterns = config.GetStringSlice(config.OperationsRetryErrors)
+	}
+	for _, pattern := range errPatterns {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return "", nil, i18n.WrapError(ctx, err, i18n.MsgInvalidOperationRetryPolicy, i, pattern)
+		}
+		policy.errors = append(policy.errors, re)
+	}
+	return opType, policy, nil
+}
+
+func (om *operationsManager) initRetryPolicies(ctx context.Context) error {
+	for i, policyObject := range config.GetObjectArray(config.OperationsRetryPolicies) {
+		var conf retryPolicyConf
+		b, _ := json.Marshal(policyObject)
+		if err := json.Unmarshal(b, &conf); err != nil {
+			return i18n.WrapError(ctx, err, i18n.MsgInvalidOperationRetryPolicy, i, policyObject.String())
+		}
+		opType, policy, err := parseRetryPolicy(ctx, i, &conf)
+		if err != nil {
+			return err
+		}
+		om.retryPolicies[opType] = policy
+	}
+	return nil
+}
+
+// isRetryable classifies an error as transient, by matching it against the policy's patterns
+func (rp *retryPolicy) isRetryable(errorMessage string) bool {
+	for _, re := range rp.errors {
+		if re.MatchString(errorMessage) {
+			return true
+		}
+	}
+	return false
+}
+
+// delay returns the backoff before the attempt that follows the supplied one
+func (rp *retryPolicy) delay(attempt int) time.Duration {
+	delay := float64(rp.initialDelay) * math.Pow(rp.factor, float64(attempt-1))
+	if delay > float64(rp.maxDelay) {
+		return rp.maxDelay
+	}
+	return time.Duration(delay)
+}
+
+// countAttempts follows the chain of retries back from an operation, to find which attempt it was.
+// The count stops at the supplied maximum, as no further retries would be scheduled beyond it.
+func (om *operationsManager) countAttempts(ctx context.Context, opID *fftypes.UUID, max int) (int, error) {
+	attempt := 1
+	for attempt < max {
+		fb := database.OperationQueryFactory.NewFilter(ctx)
+		prev, _, err := om.database.GetOperations(ctx, fb.And(fb.Eq("retry", opID)).Limit(1))
+		if err != nil {
+			return -1, err
+		}
+		if len(prev) == 0 {
+			break
+		}
+		opID = prev[0].ID
+		attempt++
+	}
+	return attempt, nil
+}
+
+func (om *operationsManager) OperationFailed(ctx context.Context, opID *fftypes.UUID, errorMessage string) {
+	if len(om.retryPolicies) == 0 {
+		return
+	}
+	op, err := om.database.GetOperationByID(ctx, opID)
+	if err != nil || op == nil {
+		log.L(ctx).Errorf("Failed to load operation %s to schedule a retry: %v", opID, err)
+		return
+	}
+	om.scheduleRetry(ctx, op.ID, op.Type, errorMessage)
+}
+
+// scheduleRetry is called when an operation fails, whether running it failed directly or a plugin later reported
+// the failure. If the operation type has a retry policy, the error is retryable, and the attempts are not exhausted,
+// a retry time is set on the operation after the backoff delay. The retry loop picks it up from the database
+// once it is due, so scheduled retries survive a restart.
+func (om *operationsManager) scheduleRetry(ctx context.Context, opID *fftypes.UUID, opType fftypes.OpType, errorMessage string) {
+	policy, ok := om.retryPolicies[opType]
+	if !ok {
+		return
+	}
+	l := log.L(ctx)
+	if !policy.isRetryable(errorMessage) {
+		l.Infof("Operation %s failed with a non-retryable error", opID)
+		return
+	}
+	attempt, err := om.countAttempts(ctx, opID, policy.maxAttempts)
+	if err != nil {
+		l.Errorf("Failed to count the attempts for operation %s: %s", opID, err)
+		return
+	}
+	if attempt >= policy.maxAttempts {
+		l.Warnf("Operation %s failed after the maximum of %d attempts", opID, policy.maxAttempts)
+		return
+	}
+	delay := policy.delay(attempt)
+	retryAfter := fftypes.FFTime(time.Now().Add(delay))
+	update := database.OperationQueryFactory.NewUpdate(ctx).Set("retryafter", &retryAfter)
+	if err := om.database.UpdateOperation(ctx, opID, update); err != nil {
+		l.Errorf("Failed to schedule retry of operation %s: %s", opID, err)
+		return
+	}
+	l.Infof("Operation %s failed on attempt %d. Retrying in %s", opID, attempt, delay)
+}
+
+func (om *operationsManager) retryLoop() {
+	defer close(om.retryLoopDone)
+
+	interval := config.GetDuration(config.OperationsRetryPollInterval)
+	for {
+		if err := om.runDueRetries(om.ctx); err != nil {
+			log.L(om.ctx).Errorf("Failed to query operations due a retry: %s", err)
+		}
+		select {
+		case <-time.After(interval):
+		case <-om.ctx.Done():
+			log.L(om.ctx).Debugf("Operation retry loop exiting")
+			return
+		}
+	}
+}
+
+// runDueRetries retries each failed operation whose retry time has passed. The retry time is
+// cleared when the retry is linked to the operation, or if the retry could not be started.
+func (om *operationsManager) runDueRetries(ctx context.Context) error {
+	fb := database.OperationQueryFactory.NewFilter(ctx)
+	filter := fb.And(
+		fb.Eq("status", fftypes.OpStatusFailed),
+		fb.Lte("retryafter", fftypes.Now()),
+	).Sort("retryafter").Limit(uint64(config.GetInt(config.OperationsRetryBatchSize)))
+	ops, _, err := om.database.GetOperations(ctx, filter)
+	if err != nil {
+		return err
+	}
+	for _, op := range ops {
+		retryCtx := log.WithLogField(ctx, "opretry", op.ID.String())
+		if op.Retry == nil {
+			_, err = om.retryOperation(retryCtx, op.ID)
+		}
+		if op.Retry != nil || err != nil {
+			// Already retried (perhaps manually), or the retry could not be started
+			log.L(retryCtx).Infof("Clearing automatic retry of operation %s (retry=%s): %v", op.ID, op.Retry, err)
+			update := database.OperationQueryFactory.NewUpdate(ctx).Set("retryafter", nil)
+			if err := om.database.UpdateOperation(ctx, op.ID, update); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,304 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/biroutermocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/eventmocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/tokens"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type countingHandler struct {
+	errs     []error
+	attempts int
+}
+
+func (h *countingHandler) Name() string {
+	return "CountingHandler"
+}
+
+func (h *countingHandler) PrepareOperation(ctx context.Context, op *fftypes.Operation) (*fftypes.PreparedOperation, error) {
+	return &fftypes.PreparedOperation{ID: op.ID, Type: op.Type}, nil
+}
+
+func (h *countingHandler) RunOperation(ctx context.Context, op *fftypes.PreparedOperation) (complete bool, err error) {
+	h.attempts++
+	if h.attempts <= len(h.errs) {
+		return false, h.errs[h.attempts-1]
+	}
+	return true, nil
+}
+
+func newTestOperationsWithPolicies(t *testing.T, policies fftypes.JSONObjectArray) (*operationsManager, func()) {
+	om, cancel := newTestOperations(t)
+	config.Set(config.OperationsRetryPolicies, policies)
+	om.retryPolicies = make(map[fftypes.OpType]*retryPolicy)
+	err := om.initRetryPolicies(context.Background())
+	assert.NoError(t, err)
+	return om, cancel
+}
+
+func TestInitRetryPoliciesDefaults(t *testing.T) {
+	om, cancel := newTestOperationsWithPolicies(t, fftypes.JSONObjectArray{
+		{"type": "token_transfer"},
+	})
+	defer cancel()
+
+	policy := om.retryPolicies[fftypes.OpTypeTokenTransfer]
+	assert.Equal(t, 5, policy.maxAttempts)
+	assert.Equal(t, 5*time.Second, policy.initialDelay)
+	assert.Equal(t, 5*time.Minute, policy.maxDelay)
+	assert.Equal(t, 2.0, policy.factor)
+	assert.True(t, policy.isRetryable("dial tcp 127.0.0.1:5102: connect: Connection Refused"))
+	assert.False(t, policy.isRetryable("FF10111: invalid input"))
+	assert.Len(t, om.retryPolicies, 1)
+}
+
+func TestInitRetryPoliciesOverrides(t *testing.T) {
+	om, cancel := newTestOperationsWithPolicies(t, fftypes.JSONObjectArray{
+		{
+			"type":         "BLOCKCHAIN_INVOKE",
+			"maxAttempts":  3,
+			"initialDelay": "100ms",
+			"maxDelay":     "1s",
+			"factor":       3.0,
+			"errors":       []interface{}{"FF10284"},
+		},
+	})
+	defer cancel()
+
+	policy := om.retryPolicies[fftypes.OpTypeBlockchainInvoke]
+	assert.Equal(t, 3, policy.maxAttempts)
+	assert.Equal(t, 100*time.Millisecond, policy.delay(1))
+	assert.Equal(t, 300*time.Millisecond, policy.delay(2))
+	assert.Equal(t, 900*time.Millisecond, policy.delay(3))
+	assert.Equal(t, 1*time.Second, policy.delay(4))
+	assert.True(t, policy.isRetryable("FF10284: Error from ethereum connector"))
+	assert.False(t, policy.isRetryable("connection refused"))
+}
+
+func TestInitRetryPoliciesFail(t *testing.T) {
+	for name, policy := range map[string]fftypes.JSONObject{
+		"type":         {"type": "unknown"},
+		"maxAttempts":  {"type": "token_transfer", "maxAttempts": 0},
+		"factor":       {"type": "token_transfer", "factor": 0.5},
+		"initialDelay": {"type": "token_transfer", "initialDelay": "soon"},
+		"maxDelay":     {"type": "token_transfer", "maxDelay": "-1s"},
+		"errors":       {"type": "token_transfer", "errors": []interface{}{"[unclosed"}},
+		"json":         {"type": "token_transfer", "maxAttempts": "lots"},
+	} {
+		config.Reset()
+		config.Set(config.OperationsRetryPolicies, fftypes.JSONObjectArray{policy})
+		_, err := NewOperationsManager(context.Background(), &databasemocks.Plugin{}, &biroutermocks.Router{}, map[string]tokens.Plugin{}, &eventmocks.EventManager{})
+		assert.Regexp(t, "FF10401", err, name)
+	}
+}
+
+func TestRunOperationScheduleRetry(t *testing.T) {
+	om, cancel := newTestOperationsWithPolicies(t, fftypes.JSONObjectArray{
+		{"type": "token_transfer", "maxAttempts": 3, "initialDelay": "1m"},
+	})
+	defer cancel()
+
+	opID := fftypes.NewUUID()
+	handler := &countingHandler{errs: []error{fmt.Errorf("connection refused")}}
+	om.RegisterHandler(context.Background(), handler, []fftypes.OpType{fftypes.OpTypeTokenTransfer})
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("ResolveOperation", mock.Anything, opID, fftypes.OpStatusFailed, "connection refused", mock.Anything).Return(nil)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{}, nil, nil)
+	mdi.On("UpdateOperation", mock.Anything, opID, mock.MatchedBy(func(update database.Update) bool {
+		info, _ := update.Finalize()
+		retryAfter, _ := info.SetOperations[0].Value.Value()
+		return info.SetOperations[0].Field == "retryafter" && retryAfter.(int64) > time.Now().UnixNano()
+	})).Return(nil)
+
+	err := om.RunOperation(context.Background(), &fftypes.PreparedOperation{ID: opID, Type: fftypes.OpTypeTokenTransfer})
+	assert.Regexp(t, "connection refused", err)
+	assert.Equal(t, 1, handler.attempts)
+	mdi.AssertExpectations(t)
+}
+
+func TestScheduleRetryExhausted(t *testing.T) {
+	om, cancel := newTestOperationsWithPolicies(t, fftypes.JSONObjectArray{
+		{"type": "token_transfer", "maxAttempts": 2},
+	})
+	defer cancel()
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{{ID: fftypes.NewUUID()}}, nil, nil).Once()
+
+	om.scheduleRetry(context.Background(), fftypes.NewUUID(), fftypes.OpTypeTokenTransfer, "timeout")
+	mdi.AssertExpectations(t)
+}
+
+func TestScheduleRetryCountFail(t *testing.T) {
+	om, cancel := newTestOperationsWithPolicies(t, fftypes.JSONObjectArray{
+		{"type": "token_transfer"},
+	})
+	defer cancel()
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	om.scheduleRetry(context.Background(), fftypes.NewUUID(), fftypes.OpTypeTokenTransfer, "timeout")
+	mdi.AssertExpectations(t)
+}
+
+func TestScheduleRetryUpdateFail(t *testing.T) {
+	om, cancel := newTestOperationsWithPolicies(t, fftypes.JSONObjectArray{
+		{"type": "token_transfer"},
+	})
+	defer cancel()
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{}, nil, nil)
+	mdi.On("UpdateOperation", mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	om.scheduleRetry(context.Background(), fftypes.NewUUID(), fftypes.OpTypeTokenTransfer, "timeout")
+	mdi.AssertExpectations(t)
+}
+
+func TestRunOperationNoAutoRetry(t *testing.T) {
+	om, cancel := newTestOperationsWithPolicies(t, fftypes.JSONObjectArray{
+		{"type": "token_transfer", "initialDelay": "1ms"},
+	})
+	defer cancel()
+
+	handler := &countingHandler{errs: []error{fmt.Errorf("pop"), fmt.Errorf("timeout")}}
+	om.RegisterHandler(context.Background(), handler, []fftypes.OpType{fftypes.OpTypeTokenTransfer, fftypes.OpTypeTokenApproval})
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("ResolveOperation", mock.Anything, mock.Anything, fftypes.OpStatusFailed, mock.Anything, mock.Anything).Return(nil)
+
+	// Not a retryable error
+	err := om.RunOperation(context.Background(), &fftypes.PreparedOperation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeTokenTransfer})
+	assert.Regexp(t, "pop", err)
+	// No policy for the type
+	err = om.RunOperation(context.Background(), &fftypes.PreparedOperation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeTokenApproval})
+	assert.Regexp(t, "timeout", err)
+	mdi.AssertExpectations(t)
+}
+
+func TestOperationFailed(t *testing.T) {
+	om, cancel := newTestOperationsWithPolicies(t, fftypes.JSONObjectArray{
+		{"type": "token_transfer"},
+	})
+	defer cancel()
+
+	op := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeTokenTransfer, Status: fftypes.OpStatusFailed}
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", mock.Anything, op.ID).Return(op, nil)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{}, nil, nil)
+	mdi.On("UpdateOperation", mock.Anything, op.ID, mock.Anything).Return(nil)
+
+	om.OperationFailed(context.Background(), op.ID, "service unavailable")
+	mdi.AssertExpectations(t)
+}
+
+func TestOperationFailedNoPolicies(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	om.OperationFailed(context.Background(), fftypes.NewUUID(), "timeout")
+	om.database.(*databasemocks.Plugin).AssertExpectations(t)
+}
+
+func TestOperationFailedNotFound(t *testing.T) {
+	om, cancel := newTestOperationsWithPolicies(t, fftypes.JSONObjectArray{
+		{"type": "token_transfer"},
+	})
+	defer cancel()
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", mock.Anything, mock.Anything).Return(nil, nil)
+
+	om.OperationFailed(context.Background(), fftypes.NewUUID(), "timeout")
+	mdi.AssertExpectations(t)
+}
+
+func TestRetryLoopStartStop(t *testing.T) {
+	om, cancel := newTestOperationsWithPolicies(t, fftypes.JSONObjectArray{
+		{"type": "token_transfer"},
+	})
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Run(func(args mock.Arguments) {
+		cancel()
+	})
+
+	err := om.Start()
+	assert.NoError(t, err)
+	assert.Nil(t, om.reconcilerDone)
+	om.WaitStop()
+	assert.Nil(t, om.retryLoopDone)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestRunDueRetries(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	due := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeTokenTransfer, Status: fftypes.OpStatusFailed, RetryAfter: fftypes.Now()}
+	retried := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeTokenTransfer, Status: fftypes.OpStatusFailed, RetryAfter: fftypes.Now(), Retry: fftypes.NewUUID()}
+	handler := &countingHandler{}
+	om.RegisterHandler(context.Background(), handler, []fftypes.OpType{fftypes.OpTypeTokenTransfer})
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{due, retried}, nil, nil)
+	dueCopy := *due
+	mdi.On("GetOperationByID", mock.Anything, due.ID).Return(&dueCopy, nil)
+	mdi.On("InsertOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.Status == fftypes.OpStatusPending && op.RetryAfter == nil
+	})).Return(nil)
+	mdi.On("UpdateOperation", mock.Anything, due.ID, mock.Anything).Return(nil)
+	mdi.On("UpdateOperation", mock.Anything, retried.ID, mock.Anything).Return(nil)
+	mdi.On("ResolveOperation", mock.Anything, mock.Anything, fftypes.OpStatusSucceeded, "", mock.Anything).Return(nil)
+
+	err := om.runDueRetries(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, handler.attempts)
+	mdi.AssertExpectations(t)
+}
+
+func TestRunDueRetriesClearFail(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	due := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeTokenTransfer, Status: fftypes.OpStatusFailed, RetryAfter: fftypes.Now()}
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{due}, nil, nil)
+	dueCopy := *due
+	mdi.On("GetOperationByID", mock.Anything, due.ID).Return(&dueCopy, nil)
+	mdi.On("InsertOperation", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	mdi.On("UpdateOperation", mock.Anything, due.ID, mock.Anything).Return(fmt.Errorf("pop2"))
+
+	err := om.runDueRetries(context.Background())
+	assert.Regexp(t, "pop2", err)
+	mdi.AssertExpectations(t)
+}
@@ -17,7 +17,12 @@
 package orchestrator
 
 import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/blockchain/birouter"
 	"github.com/hyperledger/firefly/internal/events"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/operations"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/dataexchange"
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -25,25 +30,139 @@ import (
 )
 
 type boundCallbacks struct {
-	bi blockchain.Plugin
-	dx dataexchange.Plugin
-	ei events.EventManager
+	ctx           context.Context
+	br            birouter.Router
+	dx            dataexchange.Plugin
+	ei            events.EventManager
+	om            operations.Manager
+	confirmations map[blockchain.Plugin]*confirmationQueue
+	tokenChains   map[string]blockchain.Plugin
+}
+
+// blockchainCallbacks are bound to an individual blockchain plugin, so that events are attributed to it.
+// The source of each event is set to the configured name of the plugin, rather than its type, so that
+// events from multiple plugins of the same type can be told apart.
+type blockchainCallbacks struct {
+	*boundCallbacks
+	name string
+	bi   blockchain.Plugin
+}
+
+func (bc *blockchainCallbacks) BlockchainOpUpdate(operationID *fftypes.UUID, txState blockchain.TransactionStatus, blockchainTXID, errorMessage string, opOutput fftypes.JSONObject) error {
+	return bc.operationUpdate(bc.bi, operationID, txState, blockchainTXID, errorMessage, opOutput)
+}
+
+func (bc *blockchainCallbacks) BatchPinComplete(batch *blockchain.BatchPin, signingKey *fftypes.VerifierRef) error {
+	if bc.br.ForNamespace(batch.Namespace) != bc.bi {
+		// Only the plugin that a namespace is bound to can sequence batches for it
+		log.L(context.Background()).Warnf("Ignoring batch %s from blockchain plugin '%s' for namespace '%s', which is bound to another plugin", batch.BatchID, bc.name, batch.Namespace)
+		return nil
+	}
+	batch.Event.Source = bc.name
+	return bc.dispatchConfirmed(bc.bi, &batch.Event, func() error {
+		return bc.ei.BatchPinComplete(bc.bi, batch, signingKey)
+	})
+}
+
+func (bc *blockchainCallbacks) BlockchainEvent(event *blockchain.EventWithSubscription) error {
+	event.Source = bc.name
+	return bc.dispatchConfirmed(bc.bi, &event.Event, func() error {
+		return bc.ei.BlockchainEvent(event)
+	})
+}
+
+func (bc *blockchainCallbacks) BlockchainEventRemoved(event *blockchain.Event) error {
+	event.Source = bc.name
+	return bc.ei.BlockchainEventRemoved(event)
+}
+
+func (bc *blockchainCallbacks) EventsDispatched() <-chan struct{} {
+	return bc.eventsDispatched(bc.bi)
+}
+
+// initConfirmations creates a confirmation queue for each blockchain plugin configured with a confirmation depth
+func (bc *boundCallbacks) initConfirmations() {
+	bc.confirmations = make(map[blockchain.Plugin]*confirmationQueue)
+	for _, bi := range bc.br.Plugins() {
+		if confirmer, ok := bi.(blockchain.BlockConfirmer); ok && confirmer.RequiresConfirmations() {
+			bc.confirmations[bi] = newConfirmationQueue(bc.ctx, confirmer)
+		}
+	}
 }
 
-func (bc *boundCallbacks) BlockchainOpUpdate(operationID *fftypes.UUID, txState blockchain.TransactionStatus, blockchainTXID, errorMessage string, opOutput fftypes.JSONObject) error {
-	return bc.ei.OperationUpdate(bc.bi, operationID, txState, blockchainTXID, errorMessage, opOutput)
+func (bc *boundCallbacks) startConfirmations() {
+	for _, cq := range bc.confirmations {
+		cq.start()
+	}
+}
+
+func (bc *boundCallbacks) waitStopConfirmations() {
+	for _, cq := range bc.confirmations {
+		<-cq.done
+	}
+}
+
+// dispatchConfirmed passes an event to the event manager once it reaches the confirmation depth configured on the
+// blockchain plugin. Where a depth is configured, the event is queued and the callback returns immediately.
+func (bc *boundCallbacks) dispatchConfirmed(bi blockchain.Plugin, event *blockchain.Event, dispatch func() error) error {
+	if cq, ok := bc.confirmations[bi]; ok {
+		cq.queue(event, dispatch)
+		return nil
+	}
+	return dispatch()
+}
+
+// eventsDispatched returns a channel that is closed once every event passed to dispatchConfirmed for the chain
+// so far has been processed
+func (bc *boundCallbacks) eventsDispatched(bi blockchain.Plugin) <-chan struct{} {
+	if cq, ok := bc.confirmations[bi]; ok {
+		return cq.allProcessed()
+	}
+	return closedChannel
+}
+
+// tokensBlockchain returns the blockchain plugin for the chain that a token connector indexes
+func (bc *boundCallbacks) tokensBlockchain(connector string) blockchain.Plugin {
+	if bi, ok := bc.tokenChains[connector]; ok {
+		return bi
+	}
+	return bc.br.Default()
+}
+
+// operationFailed lets the operations manager schedule an automatic retry, once an asynchronous failure
+// has been processed by the event manager
+func (bc *boundCallbacks) operationFailed(operationID *fftypes.UUID, errorMessage string) {
+	if bc.om != nil {
+		bc.om.OperationFailed(bc.ctx, operationID, errorMessage)
+	}
+}
+
+func (bc *boundCallbacks) operationUpdate(plugin fftypes.Named, operationID *fftypes.UUID, txState fftypes.OpStatus, blockchainTXID, errorMessage string, opOutput fftypes.JSONObject) error {
+	if err := bc.ei.OperationUpdate(plugin, operationID, txState, blockchainTXID, errorMessage, opOutput); err != nil {
+		return err
+	}
+	if txState == fftypes.OpStatusFailed {
+		bc.operationFailed(operationID, errorMessage)
+	}
+	return nil
 }
 
 func (bc *boundCallbacks) TokenOpUpdate(plugin tokens.Plugin, operationID *fftypes.UUID, txState fftypes.OpStatus, blockchainTXID, errorMessage string, opOutput fftypes.JSONObject) error {
-	return bc.ei.OperationUpdate(plugin, operationID, txState, blockchainTXID, errorMessage, opOutput)
+	return bc.operationUpdate(plugin, operationID, txState, blockchainTXID, errorMessage, opOutput)
 }
 
-func (bc *boundCallbacks) BatchPinComplete(batch *blockchain.BatchPin, signingKey *fftypes.VerifierRef) error {
-	return bc.ei.BatchPinComplete(bc.bi, batch, signingKey)
+func (bc *boundCallbacks) OperationUpdate(plugin fftypes.Named, operationID *fftypes.UUID, txState fftypes.OpStatus, blockchainTXID, errorMessage string, opOutput fftypes.JSONObject) error {
+	return bc.operationUpdate(plugin, operationID, txState, blockchainTXID, errorMessage, opOutput)
 }
 
 func (bc *boundCallbacks) TransferResult(trackingID string, status fftypes.OpStatus, update fftypes.TransportStatusUpdate) error {
-	return bc.ei.TransferResult(bc.dx, trackingID, status, update)
+	if err := bc.ei.TransferResult(bc.dx, trackingID, status, update); err != nil {
+		return err
+	}
+	if opID, err := fftypes.ParseUUID(bc.ctx, trackingID); err == nil && status == fftypes.OpStatusFailed {
+		bc.operationFailed(opID, update.Error)
+	}
+	return nil
 }
 
 func (bc *boundCallbacks) BLOBReceived(peerID string, hash fftypes.Bytes32, size int64, payloadRef string) error {
@@ -55,17 +174,27 @@ func (bc *boundCallbacks) MessageReceived(peerID string, data []byte) (manifest
 }
 
 func (bc *boundCallbacks) TokenPoolCreated(plugin tokens.Plugin, pool *tokens.TokenPool) error {
-	return bc.ei.TokenPoolCreated(plugin, pool)
+	return bc.dispatchConfirmed(bc.tokensBlockchain(pool.Connector), &pool.Event, func() error {
+		return bc.ei.TokenPoolCreated(plugin, pool)
+	})
 }
 
 func (bc *boundCallbacks) TokensTransferred(plugin tokens.Plugin, transfer *tokens.TokenTransfer) error {
-	return bc.ei.TokensTransferred(plugin, transfer)
+	return bc.dispatchConfirmed(bc.tokensBlockchain(transfer.Connector), &transfer.Event, func() error {
+		return bc.ei.TokensTransferred(plugin, transfer)
+	})
+}
+
+func (bc *boundCallbacks) TokenEventRemoved(plugin tokens.Plugin, event *blockchain.Event) error {
+	return bc.ei.BlockchainEventRemoved(event)
 }
 
-func (bc *boundCallbacks) BlockchainEvent(event *blockchain.EventWithSubscription) error {
-	return bc.ei.BlockchainEvent(event)
+func (bc *boundCallbacks) TokenEventsDispatched(connector string) <-chan struct{} {
+	return bc.eventsDispatched(bc.tokensBlockchain(connector))
 }
 
 func (bc *boundCallbacks) TokensApproved(plugin tokens.Plugin, approval *tokens.TokenApproval) error {
-	return bc.ei.TokensApproved(plugin, approval)
+	return bc.dispatchConfirmed(bc.tokensBlockchain(approval.Connector), &approval.Event, func() error {
+		return bc.ei.TokensApproved(plugin, approval)
+	})
 }
@@ -17,12 +17,15 @@
 package orchestrator
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
+	"github.com/hyperledger/firefly/mocks/biroutermocks"
 	"github.com/hyperledger/firefly/mocks/blockchainmocks"
 	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
 	"github.com/hyperledger/firefly/mocks/eventmocks"
+	"github.com/hyperledger/firefly/mocks/operationmocks"
 	"github.com/hyperledger/firefly/mocks/tokenmocks"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -36,10 +39,12 @@ func TestBoundCallbacks(t *testing.T) {
 	mbi := &blockchainmocks.Plugin{}
 	mdx := &dataexchangemocks.Plugin{}
 	mti := &tokenmocks.Plugin{}
-	bc := boundCallbacks{bi: mbi, dx: mdx, ei: mei}
+	mbr := &biroutermocks.Router{}
+	bc := boundCallbacks{br: mbr, dx: mdx, ei: mei}
+	bbc := &blockchainCallbacks{boundCallbacks: &bc, name: "eth1", bi: mbi}
 
 	info := fftypes.JSONObject{"hello": "world"}
-	batch := &blockchain.BatchPin{TransactionID: fftypes.NewUUID()}
+	batch := &blockchain.BatchPin{TransactionID: fftypes.NewUUID(), Namespace: "ns1"}
 	pool := &tokens.TokenPool{}
 	transfer := &tokens.TokenTransfer{}
 	approval := &tokens.TokenApproval{}
@@ -47,17 +52,23 @@ func TestBoundCallbacks(t *testing.T) {
 	opID := fftypes.NewUUID()
 
 	mei.On("BatchPinComplete", mbi, batch, &fftypes.VerifierRef{Value: "0x12345", Type: fftypes.VerifierTypeEthAddress}).Return(fmt.Errorf("pop"))
-	err := bc.BatchPinComplete(batch, &fftypes.VerifierRef{Value: "0x12345", Type: fftypes.VerifierTypeEthAddress})
+	mbr.On("ForNamespace", "ns1").Return(mbi)
+	err := bbc.BatchPinComplete(batch, &fftypes.VerifierRef{Value: "0x12345", Type: fftypes.VerifierTypeEthAddress})
 	assert.EqualError(t, err, "pop")
+	assert.Equal(t, "eth1", batch.Event.Source)
 
 	mei.On("OperationUpdate", mbi, opID, fftypes.OpStatusFailed, "0xffffeeee", "error info", info).Return(fmt.Errorf("pop"))
-	err = bc.BlockchainOpUpdate(opID, fftypes.OpStatusFailed, "0xffffeeee", "error info", info)
+	err = bbc.BlockchainOpUpdate(opID, fftypes.OpStatusFailed, "0xffffeeee", "error info", info)
 	assert.EqualError(t, err, "pop")
 
 	mei.On("OperationUpdate", mti, opID, fftypes.OpStatusFailed, "0xffffeeee", "error info", info).Return(fmt.Errorf("pop"))
 	err = bc.TokenOpUpdate(mti, opID, fftypes.OpStatusFailed, "0xffffeeee", "error info", info)
 	assert.EqualError(t, err, "pop")
 
+	mei.On("OperationUpdate", mti, opID, fftypes.OpStatusSucceeded, "0xffffeeee", "", info).Return(fmt.Errorf("pop"))
+	err = bc.OperationUpdate(mti, opID, fftypes.OpStatusSucceeded, "0xffffeeee", "", info)
+	assert.EqualError(t, err, "pop")
+
 	mei.On("TransferResult", mdx, "tracking12345", fftypes.OpStatusFailed, mock.Anything).Return(fmt.Errorf("pop"))
 	err = bc.TransferResult("tracking12345", fftypes.OpStatusFailed, fftypes.TransportStatusUpdate{
 		Error: "error info", Info: info,
@@ -72,6 +83,7 @@ func TestBoundCallbacks(t *testing.T) {
 	_, err = bc.MessageReceived("peer1", []byte{})
 	assert.EqualError(t, err, "pop")
 
+	mbr.On("Default").Return(mbi)
 	mei.On("TokenPoolCreated", mti, pool).Return(fmt.Errorf("pop"))
 	err = bc.TokenPoolCreated(mti, pool)
 	assert.EqualError(t, err, "pop")
@@ -85,6 +97,147 @@ func TestBoundCallbacks(t *testing.T) {
 	assert.EqualError(t, err, "pop")
 
 	mei.On("BlockchainEvent", mock.AnythingOfType("*blockchain.EventWithSubscription")).Return(fmt.Errorf("pop"))
-	err = bc.BlockchainEvent(&blockchain.EventWithSubscription{})
+	chainEvent := &blockchain.EventWithSubscription{Event: blockchain.Event{Source: "ethereum"}}
+	err = bbc.BlockchainEvent(chainEvent)
+	assert.EqualError(t, err, "pop")
+	assert.Equal(t, "eth1", chainEvent.Source)
+
+	mei.On("BlockchainEventRemoved", mock.AnythingOfType("*blockchain.Event")).Return(fmt.Errorf("pop"))
+	removedEvent := &blockchain.Event{Source: "ethereum"}
+	err = bbc.BlockchainEventRemoved(removedEvent)
+	assert.EqualError(t, err, "pop")
+	assert.Equal(t, "eth1", removedEvent.Source)
+
+	err = bc.TokenEventRemoved(mti, &blockchain.Event{})
 	assert.EqualError(t, err, "pop")
+
+	// No events are held back without confirmations
+	<-bbc.EventsDispatched()
+	<-bc.TokenEventsDispatched("erc1155")
+}
+
+func TestBoundCallbacksOperationFailed(t *testing.T) {
+	mei := &eventmocks.EventManager{}
+	mom := &operationmocks.Manager{}
+	mbi := &blockchainmocks.Plugin{}
+	mdx := &dataexchangemocks.Plugin{}
+	mti := &tokenmocks.Plugin{}
+	ctx := context.Background()
+	bc := boundCallbacks{ctx: ctx, dx: mdx, ei: mei, om: mom}
+	bbc := &blockchainCallbacks{boundCallbacks: &bc, bi: mbi}
+	opID := fftypes.NewUUID()
+
+	mei.On("OperationUpdate", mock.Anything, opID, mock.Anything, "", mock.Anything, mock.Anything).Return(nil)
+	mei.On("TransferResult", mdx, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mom.On("OperationFailed", ctx, opID, "err1").Return().Once()
+	mom.On("OperationFailed", ctx, opID, "err2").Return().Once()
+	mom.On("OperationFailed", ctx, opID, "err3").Return().Once()
+	mom.On("OperationFailed", ctx, opID, "err4").Return().Once()
+
+	err := bbc.BlockchainOpUpdate(opID, fftypes.OpStatusFailed, "", "err1", nil)
+	assert.NoError(t, err)
+	err = bc.TokenOpUpdate(mti, opID, fftypes.OpStatusFailed, "", "err2", nil)
+	assert.NoError(t, err)
+	err = bc.OperationUpdate(mti, opID, fftypes.OpStatusFailed, "", "err3", nil)
+	assert.NoError(t, err)
+	err = bc.TransferResult(opID.String(), fftypes.OpStatusFailed, fftypes.TransportStatusUpdate{Error: "err4"})
+	assert.NoError(t, err)
+
+	// No retry is scheduled for successful updates, or unknown tracking IDs
+	err = bc.OperationUpdate(mti, opID, fftypes.OpStatusSucceeded, "", "", nil)
+	assert.NoError(t, err)
+	err = bc.TransferResult("tracking12345", fftypes.OpStatusFailed, fftypes.TransportStatusUpdate{Error: "err5"})
+	assert.NoError(t, err)
+
+	mei.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+type confirmingBlockchain struct {
+	*blockchainmocks.Plugin
+	*blockchainmocks.BlockConfirmer
+}
+
+func TestBoundCallbacksConfirmations(t *testing.T) {
+	mei := &eventmocks.EventManager{}
+	mbc := &blockchainmocks.BlockConfirmer{}
+	mbi := &confirmingBlockchain{Plugin: &blockchainmocks.Plugin{}, BlockConfirmer: mbc}
+	mbi2 := &blockchainmocks.Plugin{}
+	mti := &tokenmocks.Plugin{}
+	mbr := &biroutermocks.Router{}
+	ctx, cancel := context.WithCancel(context.Background())
+	bc := boundCallbacks{ctx: ctx, br: mbr, ei: mei, tokenChains: map[string]blockchain.Plugin{"erc1155": mbi}}
+	bbc := &blockchainCallbacks{boundCallbacks: &bc, bi: mbi}
+
+	batch := &blockchain.BatchPin{Namespace: "ns1", Event: blockchain.Event{ProtocolID: "batch"}}
+	event := &blockchain.EventWithSubscription{Event: blockchain.Event{ProtocolID: "event"}}
+	pool := &tokens.TokenPool{Connector: "erc1155", Event: blockchain.Event{ProtocolID: "pool"}}
+	transfer := &tokens.TokenTransfer{Event: blockchain.Event{ProtocolID: "transfer"}}
+	transfer.Connector = "erc1155"
+	approval := &tokens.TokenApproval{Event: blockchain.Event{ProtocolID: "approval"}}
+	approval.Connector = "erc20"
+
+	mbr.On("Plugins").Return(map[string]blockchain.Plugin{"eth1": mbi, "eth2": mbi2})
+	mbr.On("ForNamespace", "ns1").Return(mbi)
+	mbr.On("Default").Return(mbi2)
+	mbc.On("RequiresConfirmations").Return(true)
+	mbc.On("WaitForConfirmations", ctx, &batch.Event).Return(true, nil)
+	mbc.On("WaitForConfirmations", ctx, &event.Event).Return(false, nil)
+	mbc.On("WaitForConfirmations", ctx, &pool.Event).Return(true, nil)
+	mbc.On("WaitForConfirmations", ctx, &transfer.Event).Return(true, nil)
+	mei.On("BatchPinComplete", mbi, batch, mock.Anything).Return(nil)
+	mei.On("TokenPoolCreated", mti, pool).Return(nil)
+	mei.On("TokensTransferred", mti, transfer).Return(nil).Run(func(args mock.Arguments) {
+		cancel()
+	})
+	mei.On("TokensApproved", mti, approval).Return(nil)
+
+	bc.initConfirmations()
+	assert.Len(t, bc.confirmations, 1)
+
+	err := bbc.BatchPinComplete(batch, &fftypes.VerifierRef{})
+	assert.NoError(t, err)
+
+	err = bbc.BlockchainEvent(event)
+	assert.NoError(t, err)
+
+	err = bc.TokenPoolCreated(mti, pool)
+	assert.NoError(t, err)
+
+	err = bc.TokensTransferred(mti, transfer)
+	assert.NoError(t, err)
+
+	// The approval is on a chain without confirmations, so is dispatched immediately
+	err = bc.TokensApproved(mti, approval)
+	assert.NoError(t, err)
+	<-bc.TokenEventsDispatched("erc20")
+
+	dispatched := bbc.EventsDispatched()
+	tokensDispatched := bc.TokenEventsDispatched("erc1155")
+	select {
+	case <-dispatched:
+		assert.Fail(t, "events dispatched before confirmation")
+	default:
+	}
+
+	bc.startConfirmations()
+	bc.waitStopConfirmations()
+	<-dispatched
+	<-tokensDispatched
+
+	mei.AssertExpectations(t)
+	mbc.AssertExpectations(t)
+}
+
+func TestBatchPinCompleteOtherBlockchain(t *testing.T) {
+	mei := &eventmocks.EventManager{}
+	mbi := &blockchainmocks.Plugin{}
+	mbr := &biroutermocks.Router{}
+	bc := &blockchainCallbacks{boundCallbacks: &boundCallbacks{br: mbr, ei: mei}, name: "eth1", bi: mbi}
+
+	mbr.On("ForNamespace", "ns1").Return(&blockchainmocks.Plugin{})
+	err := bc.BatchPinComplete(&blockchain.BatchPin{Namespace: "ns1"}, &fftypes.VerifierRef{})
+	assert.NoError(t, err)
+
+	mei.AssertExpectations(t)
 }
@@ -0,0 +1,167 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/retry"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+)
+
+// confirmationQueue holds back the events that arrive on a chain with a configured confirmation depth, so that
+// the plugin callback is not blocked while confirmations accumulate. Events are dispatched in the order they
+// were queued once confirmed, and a failed dispatch is retried until the node shuts down.
+//
+// Queued events are only held in memory, so plugins wait for them to be processed before acknowledging them to
+// their connector. That way any events still queued on shutdown are redelivered by the connector on restart.
+type confirmationQueue struct {
+	ctx       context.Context
+	confirmer blockchain.BlockConfirmer
+	retry     retry.Retry
+	mux       sync.Mutex
+	pending   []*pendingEvent
+	queued    int64
+	processed int64
+	waiters   []*processedWaiter
+	newEvents chan bool
+	done      chan struct{}
+}
+
+type pendingEvent struct {
+	event    *blockchain.Event
+	dispatch func() error
+}
+
+type processedWaiter struct {
+	target int64
+	ch     chan struct{}
+}
+
+var closedChannel = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
+func newConfirmationQueue(ctx context.Context, confirmer blockchain.BlockConfirmer) *confirmationQueue {
+	return &confirmationQueue{
+		ctx:       ctx,
+		confirmer: confirmer,
+		retry: retry.Retry{
+			InitialDelay: config.GetDuration(config.EventAggregatorRetryInitDelay),
+			MaximumDelay: config.GetDuration(config.EventAggregatorRetryMaxDelay),
+			Factor:       config.GetFloat64(config.EventAggregatorRetryFactor),
+		},
+		newEvents: make(chan bool, 1),
+		done:      make(chan struct{}),
+	}
+}
+
+func (cq *confirmationQueue) start() {
+	go cq.confirmLoop()
+}
+
+func (cq *confirmationQueue) queue(event *blockchain.Event, dispatch func() error) {
+	cq.mux.Lock()
+	cq.pending = append(cq.pending, &pendingEvent{event: event, dispatch: dispatch})
+	cq.queued++
+	cq.mux.Unlock()
+	select {
+	case cq.newEvents <- true:
+	default:
+	}
+}
+
+func (cq *confirmationQueue) next() *pendingEvent {
+	cq.mux.Lock()
+	defer cq.mux.Unlock()
+	if len(cq.pending) == 0 {
+		return nil
+	}
+	pe := cq.pending[0]
+	cq.pending = cq.pending[1:]
+	return pe
+}
+
+// allProcessed returns a channel that is closed once every event queued so far has been dispatched or dropped
+func (cq *confirmationQueue) allProcessed() <-chan struct{} {
+	cq.mux.Lock()
+	defer cq.mux.Unlock()
+	if cq.processed >= cq.queued {
+		return closedChannel
+	}
+	w := &processedWaiter{target: cq.queued, ch: make(chan struct{})}
+	cq.waiters = append(cq.waiters, w)
+	return w.ch
+}
+
+func (cq *confirmationQueue) markProcessed() {
+	cq.mux.Lock()
+	defer cq.mux.Unlock()
+	cq.processed++
+	remaining := cq.waiters[:0]
+	for _, w := range cq.waiters {
+		if w.target <= cq.processed {
+			close(w.ch)
+		} else {
+			remaining = append(remaining, w)
+		}
+	}
+	cq.waiters = remaining
+}
+
+func (cq *confirmationQueue) confirmLoop() {
+	defer close(cq.done)
+	for {
+		pe := cq.next()
+		if pe == nil {
+			select {
+			case <-cq.newEvents:
+				continue
+			case <-cq.ctx.Done():
+				log.L(cq.ctx).Debugf("Confirmation queue exiting")
+				return
+			}
+		}
+		if err := cq.process(pe); err != nil {
+			log.L(cq.ctx).Debugf("Confirmation queue exiting: %s", err)
+			return
+		}
+		cq.markProcessed()
+	}
+}
+
+// process waits for the event to be confirmed, then dispatches it. Events that are removed from the chain while
+// waiting are dropped. An error is only returned if the context is cancelled.
+func (cq *confirmationQueue) process(pe *pendingEvent) error {
+	confirmed, err := cq.confirmer.WaitForConfirmations(cq.ctx, pe.event)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		log.L(cq.ctx).Warnf("Ignoring event '%s' from '%s', which was removed from the chain before it was confirmed", pe.event.ProtocolID, pe.event.Source)
+		return nil
+	}
+	return cq.retry.Do(cq.ctx, "dispatch confirmed event", func(attempt int) (retry bool, err error) {
+		err = pe.dispatch()
+		return err != nil, err
+	})
+}
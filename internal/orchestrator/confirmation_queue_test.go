@@ -0,0 +1,137 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestConfirmationQueue() (*confirmationQueue, *blockchainmocks.BlockConfirmer, context.CancelFunc) {
+	mbc := &blockchainmocks.BlockConfirmer{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cq := newConfirmationQueue(ctx, mbc)
+	cq.retry.InitialDelay = 0
+	return cq, mbc, cancel
+}
+
+func TestConfirmationQueueDispatchInOrder(t *testing.T) {
+	cq, mbc, cancel := newTestConfirmationQueue()
+	defer cancel()
+
+	event1 := &blockchain.Event{ProtocolID: "000000000001/000000/000000"}
+	event2 := &blockchain.Event{ProtocolID: "000000000002/000000/000000"}
+	mbc.On("WaitForConfirmations", mock.Anything, event1).Return(true, nil)
+	mbc.On("WaitForConfirmations", mock.Anything, event2).Return(true, nil)
+
+	var dispatched []string
+	attempts := 0
+	cq.queue(event1, func() error {
+		attempts++
+		if attempts == 1 {
+			return fmt.Errorf("pop")
+		}
+		dispatched = append(dispatched, event1.ProtocolID)
+		return nil
+	})
+	cq.queue(event2, func() error {
+		dispatched = append(dispatched, event2.ProtocolID)
+		cancel()
+		return nil
+	})
+
+	cq.start()
+	<-cq.done
+
+	assert.Equal(t, []string{event1.ProtocolID, event2.ProtocolID}, dispatched)
+	assert.Equal(t, 2, attempts)
+	mbc.AssertExpectations(t)
+}
+
+func TestConfirmationQueueEventRemoved(t *testing.T) {
+	cq, mbc, cancel := newTestConfirmationQueue()
+	defer cancel()
+
+	event := &blockchain.Event{ProtocolID: "000000000001/000000/000000"}
+	mbc.On("WaitForConfirmations", mock.Anything, event).Return(false, nil)
+
+	err := cq.process(&pendingEvent{event: event, dispatch: func() error {
+		panic("should not be dispatched")
+	}})
+	assert.NoError(t, err)
+
+	mbc.AssertExpectations(t)
+}
+
+func TestConfirmationQueueWaitCancelled(t *testing.T) {
+	cq, mbc, cancel := newTestConfirmationQueue()
+	defer cancel()
+
+	event := &blockchain.Event{ProtocolID: "000000000001/000000/000000"}
+	mbc.On("WaitForConfirmations", mock.Anything, event).Return(false, fmt.Errorf("pop"))
+
+	cq.queue(event, func() error { return nil })
+	cq.start()
+	<-cq.done
+
+	mbc.AssertExpectations(t)
+}
+
+func TestConfirmationQueueDispatchCancelled(t *testing.T) {
+	cq, mbc, cancel := newTestConfirmationQueue()
+
+	event := &blockchain.Event{ProtocolID: "000000000001/000000/000000"}
+	mbc.On("WaitForConfirmations", mock.Anything, event).Return(true, nil)
+
+	err := cq.process(&pendingEvent{event: event, dispatch: func() error {
+		cancel()
+		return fmt.Errorf("pop")
+	}})
+	assert.Regexp(t, "FF10158", err)
+
+	mbc.AssertExpectations(t)
+}
+
+func TestConfirmationQueueAllProcessed(t *testing.T) {
+	cq, mbc, cancel := newTestConfirmationQueue()
+	defer cancel()
+
+	<-cq.allProcessed()
+
+	event1 := &blockchain.Event{ProtocolID: "000000000001/000000/000000"}
+	event2 := &blockchain.Event{ProtocolID: "000000000002/000000/000000"}
+	mbc.On("WaitForConfirmations", mock.Anything, event1).Return(true, nil)
+	mbc.On("WaitForConfirmations", mock.Anything, event2).Return(false, nil)
+
+	cq.queue(event1, func() error { return nil })
+	processed1 := cq.allProcessed()
+	cq.queue(event2, func() error { return nil })
+	processed2 := cq.allProcessed()
+
+	cq.start()
+	<-processed1
+	<-processed2
+	<-cq.allProcessed()
+
+	mbc.AssertExpectations(t)
+}
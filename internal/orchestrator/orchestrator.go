@@ -19,11 +19,14 @@ package orchestrator
 import (
 	"context"
 	"fmt"
+	"sync"
 
+	"github.com/hyperledger/firefly/internal/archive"
 	"github.com/hyperledger/firefly/internal/assets"
 	"github.com/hyperledger/firefly/internal/batch"
 	"github.com/hyperledger/firefly/internal/batchpin"
 	"github.com/hyperledger/firefly/internal/blockchain/bifactory"
+	"github.com/hyperledger/firefly/internal/blockchain/birouter"
 	"github.com/hyperledger/firefly/internal/broadcast"
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/contracts"
@@ -55,6 +58,7 @@ import (
 
 var (
 	blockchainConfig    = config.NewPluginConfig("blockchain")
+	blockchainsConfig   = config.NewPluginConfig("blockchain").Array()
 	databaseConfig      = config.NewPluginConfig("database")
 	identityConfig      = config.NewPluginConfig("identity")
 	sharedstorageConfig = config.NewPluginConfig("sharedstorage")
@@ -79,10 +83,12 @@ type Orchestrator interface {
 	Metrics() metrics.Manager
 	BatchManager() batch.Manager
 	Operations() operations.Manager
+	Archive() archive.Manager
 	IsPreInit() bool
 
 	// Status
 	GetStatus(ctx context.Context) (*fftypes.NodeStatus, error)
+	GetReadiness(ctx context.Context) (*fftypes.NodeHealth, error)
 
 	// Subscription management
 	GetSubscriptions(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Subscription, *database.FilterResult, error)
@@ -90,6 +96,12 @@ type Orchestrator interface {
 	CreateSubscription(ctx context.Context, ns string, subDef *fftypes.Subscription) (*fftypes.Subscription, error)
 	CreateUpdateSubscription(ctx context.Context, ns string, subDef *fftypes.Subscription) (*fftypes.Subscription, error)
 	DeleteSubscription(ctx context.Context, ns, id string) error
+	GetDeadLetters(ctx context.Context, ns, subID string, filter database.AndFilter) ([]*fftypes.DeadLetter, *database.FilterResult, error)
+	GetDeadLetterByID(ctx context.Context, ns, subID, id string) (*fftypes.DeadLetter, error)
+	ReplayDeadLetter(ctx context.Context, ns, subID, id string) (*fftypes.DeadLetter, error)
+	PurgeDeadLetters(ctx context.Context, ns, subID string) error
+	ResetSubscriptionOffset(ctx context.Context, ns, subID string, reset *fftypes.SubscriptionOffsetReset) (*fftypes.Offset, error)
+	GetSubscriptionStatus(ctx context.Context, ns, subID string) (*fftypes.SubscriptionStatus, error)
 
 	// Data Query
 	GetNamespace(ctx context.Context, ns string) (*fftypes.Namespace, error)
@@ -142,9 +154,10 @@ type Orchestrator interface {
 type orchestrator struct {
 	ctx            context.Context
 	cancelCtx      context.CancelFunc
+	startedMux     sync.Mutex
 	started        bool
 	database       database.Plugin
-	blockchain     blockchain.Plugin
+	blockchains    birouter.Router
 	identity       identity.Manager
 	identityPlugin idplugin.Plugin
 	sharedstorage  sharedstorage.Plugin
@@ -167,6 +180,10 @@ type orchestrator struct {
 	metrics        metrics.Manager
 	operations     operations.Manager
 	txHelper       txcommon.Helper
+	archive        archive.Manager
+
+	retentionPolicies []*retentionPolicy
+	prunerDone        chan struct{}
 }
 
 func NewOrchestrator() Orchestrator {
@@ -174,6 +191,7 @@ func NewOrchestrator() Orchestrator {
 
 	// Initialize the config on all the factories
 	bifactory.InitPrefix(blockchainConfig)
+	bifactory.InitPrefixArray(blockchainsConfig)
 	difactory.InitPrefix(databaseConfig)
 	ssfactory.InitPrefix(sharedstorageConfig)
 	// For backward compatibility also init with the old "publicstorage" prefix
@@ -197,10 +215,17 @@ func (or *orchestrator) Init(ctx context.Context, cancelCtx context.CancelFunc)
 	if err == nil {
 		err = or.initNamespaces(ctx)
 	}
+	if err == nil {
+		err = or.initRetention(ctx)
+	}
 	// Bind together the blockchain interface callbacks, with the events manager
-	or.bc.bi = or.blockchain
+	or.bc.ctx = ctx
+	or.bc.br = or.blockchains
 	or.bc.ei = or.events
 	or.bc.dx = or.dataexchange
+	if err == nil {
+		or.bc.initConfirmations()
+	}
 	return err
 }
 
@@ -209,13 +234,22 @@ func (or *orchestrator) Start() error {
 		log.L(or.ctx).Infof("Orchestrator in pre-init mode, waiting for initialization")
 		return nil
 	}
-	err := or.blockchain.Start()
+	var err error
+	or.bc.startConfirmations()
+	for _, bi := range or.blockchains.Plugins() {
+		if err = bi.Start(); err != nil {
+			break
+		}
+	}
 	if err == nil {
 		err = or.batch.Start()
 	}
 	if err == nil {
 		err = or.events.Start()
 	}
+	if err == nil {
+		err = or.syncasync.Start()
+	}
 	if err == nil {
 		err = or.broadcast.Start()
 	}
@@ -229,15 +263,34 @@ func (or *orchestrator) Start() error {
 			}
 		}
 	}
+	if err == nil {
+		err = or.operations.Start()
+	}
 	if err == nil {
 		err = or.metrics.Start()
 	}
+	if err == nil {
+		or.startRetention()
+	}
+	or.startedMux.Lock()
 	or.started = true
+	or.startedMux.Unlock()
 	return err
 }
 
+func (or *orchestrator) isStarted() bool {
+	or.startedMux.Lock()
+	defer or.startedMux.Unlock()
+	return or.started
+}
+
 func (or *orchestrator) WaitStop() {
-	if !or.started {
+	// Readiness checks fail from the point we begin stopping
+	or.startedMux.Lock()
+	started := or.started
+	or.started = false
+	or.startedMux.Unlock()
+	if !started {
 		return
 	}
 	if or.batch != nil {
@@ -252,7 +305,19 @@ func (or *orchestrator) WaitStop() {
 		or.data.WaitStop()
 		or.data = nil
 	}
-	or.started = false
+	if or.operations != nil {
+		or.operations.WaitStop()
+		or.operations = nil
+	}
+	if or.syncasync != nil {
+		or.syncasync.WaitStop()
+		or.syncasync = nil
+	}
+	if or.prunerDone != nil {
+		<-or.prunerDone
+		or.prunerDone = nil
+	}
+	or.bc.waitStopConfirmations()
 }
 
 func (or *orchestrator) IsPreInit() bool {
@@ -291,6 +356,10 @@ func (or *orchestrator) Contracts() contracts.Manager {
 	return or.contracts
 }
 
+func (or *orchestrator) Archive() archive.Manager {
+	return or.archive
+}
+
 func (or *orchestrator) Metrics() metrics.Manager {
 	return or.metrics
 }
@@ -354,6 +423,73 @@ func (or *orchestrator) initDataExchange(ctx context.Context) (err error) {
 	return or.dataexchange.Init(ctx, dataexchangeConfig.SubPrefix(dxPlugin), nodeInfo, &or.bc)
 }
 
+type blockchainEntry struct {
+	name       string
+	pluginType string
+	prefix     config.Prefix
+}
+
+func (or *orchestrator) getBlockchainEntries(ctx context.Context) ([]*blockchainEntry, error) {
+	if biType := config.GetString(config.BlockchainType); biType != "" {
+		// A single plugin configured with "blockchain.type", which is named after its type
+		return []*blockchainEntry{{name: biType, pluginType: biType, prefix: blockchainConfig}}, nil
+	}
+	var entries []*blockchainEntry
+	names := make(map[string]bool)
+	for i := 0; i < blockchainsConfig.ArraySize(); i++ {
+		prefix := blockchainsConfig.ArrayEntry(i)
+		name := prefix.GetString(blockchain.BlockchainConfigName)
+		pluginType := prefix.GetString(blockchain.BlockchainConfigPlugin)
+		if name == "" || pluginType == "" {
+			return nil, i18n.NewError(ctx, i18n.MsgMissingBlockchainConfig, i)
+		}
+		if err := fftypes.ValidateFFNameField(ctx, name, fmt.Sprintf("blockchain[%d].name", i)); err != nil {
+			return nil, err
+		}
+		if names[name] {
+			return nil, i18n.NewError(ctx, i18n.MsgDuplicateBlockchainPlugin, name)
+		}
+		names[name] = true
+		entries = append(entries, &blockchainEntry{name: name, pluginType: pluginType, prefix: prefix})
+	}
+	return entries, nil
+}
+
+func (or *orchestrator) initBlockchains(ctx context.Context) error {
+	entries, err := or.getBlockchainEntries(ctx)
+	if err != nil {
+		return err
+	}
+	plugins := make(map[string]blockchain.Plugin)
+	defaultName := ""
+	for _, entry := range entries {
+		log.L(ctx).Infof("Loading blockchain plugin name=%s plugin=%s", entry.name, entry.pluginType)
+		plugin, err := bifactory.GetPlugin(ctx, entry.pluginType)
+		if err != nil {
+			return err
+		}
+		prefix := entry.prefix.SubPrefix(plugin.Name())
+		plugin.InitPrefix(prefix)
+		if err = plugin.Init(ctx, prefix, &blockchainCallbacks{boundCallbacks: &or.bc, name: entry.name, bi: plugin}); err != nil {
+			return err
+		}
+		plugins[entry.name] = plugin
+		if defaultName == "" {
+			// The first plugin is used for any namespace that is not bound to a plugin by name
+			defaultName = entry.name
+		}
+	}
+
+	namespaces := make(map[string]string)
+	for _, nsObject := range config.GetObjectArray(config.NamespacesPredefined) {
+		if name := nsObject.GetString("blockchain"); name != "" {
+			namespaces[nsObject.GetString("name")] = name
+		}
+	}
+	or.blockchains, err = birouter.NewRouter(ctx, plugins, defaultName, namespaces)
+	return err
+}
+
 func (or *orchestrator) initPlugins(ctx context.Context) (err error) {
 
 	if err = or.initDatabaseCheckPreinit(ctx); err != nil {
@@ -372,15 +508,11 @@ func (or *orchestrator) initPlugins(ctx context.Context) (err error) {
 		return err
 	}
 
-	if or.blockchain == nil {
-		biType := config.GetString(config.BlockchainType)
-		if or.blockchain, err = bifactory.GetPlugin(ctx, biType); err != nil {
+	if or.blockchains == nil {
+		if err = or.initBlockchains(ctx); err != nil {
 			return err
 		}
 	}
-	if err = or.blockchain.Init(ctx, blockchainConfig.SubPrefix(or.blockchain.Name()), &or.bc); err != nil {
-		return err
-	}
 
 	storageConfig := sharedstorageConfig
 	if or.sharedstorage == nil {
@@ -405,6 +537,7 @@ func (or *orchestrator) initPlugins(ctx context.Context) (err error) {
 
 	if or.tokens == nil {
 		or.tokens = make(map[string]tokens.Plugin)
+		or.bc.tokenChains = make(map[string]blockchain.Plugin)
 		tokensConfigArraySize := tokensConfig.ArraySize()
 		for i := 0; i < tokensConfigArraySize; i++ {
 			prefix := tokensConfig.ArrayEntry(i)
@@ -441,6 +574,14 @@ func (or *orchestrator) initPlugins(ctx context.Context) (err error) {
 				return err
 			}
 			or.tokens[name] = plugin
+
+			if biName := prefix.GetString(tokens.TokensConfigBlockchain); biName != "" {
+				bi, ok := or.blockchains.Plugins()[biName]
+				if !ok {
+					return i18n.NewError(ctx, i18n.MsgTokensBlockchainNotFound, name, biName)
+				}
+				or.bc.tokenChains[name] = bi
+			}
 		}
 	}
 
@@ -464,7 +605,7 @@ func (or *orchestrator) initComponents(ctx context.Context) (err error) {
 	}
 
 	if or.identity == nil {
-		or.identity, err = identity.NewIdentityManager(ctx, or.database, or.identityPlugin, or.blockchain, or.data)
+		or.identity, err = identity.NewIdentityManager(ctx, or.database, or.identityPlugin, or.blockchains.Default(), or.data)
 		if err != nil {
 			return err
 		}
@@ -478,27 +619,28 @@ func (or *orchestrator) initComponents(ctx context.Context) (err error) {
 	}
 
 	if or.operations == nil {
-		if or.operations, err = operations.NewOperationsManager(ctx, or.database, or.tokens); err != nil {
+		if or.operations, err = operations.NewOperationsManager(ctx, or.database, or.blockchains, or.tokens, &or.bc); err != nil {
 			return err
 		}
 	}
+	or.bc.om = or.operations
 
 	or.syncasync = syncasync.NewSyncAsyncBridge(ctx, or.database, or.data)
 
 	if or.batchpin == nil {
-		if or.batchpin, err = batchpin.NewBatchPinSubmitter(ctx, or.database, or.identity, or.blockchain, or.metrics, or.operations); err != nil {
+		if or.batchpin, err = batchpin.NewBatchPinSubmitter(ctx, or.database, or.identity, or.blockchains, or.metrics, or.operations); err != nil {
 			return err
 		}
 	}
 
 	if or.messaging == nil {
-		if or.messaging, err = privatemessaging.NewPrivateMessaging(ctx, or.database, or.identity, or.dataexchange, or.blockchain, or.batch, or.data, or.syncasync, or.batchpin, or.metrics, or.operations); err != nil {
+		if or.messaging, err = privatemessaging.NewPrivateMessaging(ctx, or.database, or.identity, or.dataexchange, or.blockchains.Default(), or.batch, or.data, or.syncasync, or.batchpin, or.metrics, or.operations); err != nil {
 			return err
 		}
 	}
 
 	if or.broadcast == nil {
-		if or.broadcast, err = broadcast.NewBroadcastManager(ctx, or.database, or.identity, or.data, or.blockchain, or.dataexchange, or.sharedstorage, or.batch, or.syncasync, or.batchpin, or.metrics, or.operations); err != nil {
+		if or.broadcast, err = broadcast.NewBroadcastManager(ctx, or.database, or.identity, or.data, or.blockchains.Default(), or.dataexchange, or.sharedstorage, or.batch, or.syncasync, or.batchpin, or.metrics, or.operations); err != nil {
 			return err
 		}
 	}
@@ -511,16 +653,16 @@ func (or *orchestrator) initComponents(ctx context.Context) (err error) {
 	}
 
 	if or.contracts == nil {
-		or.contracts, err = contracts.NewContractManager(ctx, or.database, or.broadcast, or.identity, or.blockchain, or.operations, or.txHelper)
+		or.contracts, err = contracts.NewContractManager(ctx, or.database, or.broadcast, or.identity, or.blockchains, or.operations, or.txHelper)
 		if err != nil {
 			return err
 		}
 	}
 
-	or.definitions = definitions.NewDefinitionHandlers(or.database, or.blockchain, or.dataexchange, or.data, or.identity, or.broadcast, or.messaging, or.assets, or.contracts)
+	or.definitions = definitions.NewDefinitionHandlers(or.database, or.blockchains.Default(), or.dataexchange, or.data, or.identity, or.broadcast, or.messaging, or.assets, or.contracts)
 
 	if or.events == nil {
-		or.events, err = events.NewEventManager(ctx, or, or.sharedstorage, or.database, or.blockchain, or.identity, or.definitions, or.data, or.broadcast, or.messaging, or.assets, or.metrics, or.txHelper)
+		or.events, err = events.NewEventManager(ctx, or, or.sharedstorage, or.database, or.blockchains.Default(), or.identity, or.definitions, or.data, or.broadcast, or.messaging, or.assets, or.metrics, or.txHelper)
 		if err != nil {
 			return err
 		}
@@ -533,6 +675,13 @@ func (or *orchestrator) initComponents(ctx context.Context) (err error) {
 		}
 	}
 
+	if or.archive == nil {
+		or.archive, err = archive.NewArchiveManager(ctx, or.database, or.dataexchange, or.blockchains, or.assets)
+		if err != nil {
+			return err
+		}
+	}
+
 	or.syncasync.Init(or.events)
 
 	return nil
@@ -19,15 +19,21 @@ package orchestrator
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/dataexchange/dxfactory"
 	"github.com/hyperledger/firefly/internal/restclient"
 	"github.com/hyperledger/firefly/internal/tokens/tifactory"
+	"github.com/hyperledger/firefly/mocks/archivemocks"
 	"github.com/hyperledger/firefly/mocks/assetmocks"
 	"github.com/hyperledger/firefly/mocks/batchmocks"
 	"github.com/hyperledger/firefly/mocks/batchpinmocks"
+	"github.com/hyperledger/firefly/mocks/biroutermocks"
 	"github.com/hyperledger/firefly/mocks/blockchainmocks"
 	"github.com/hyperledger/firefly/mocks/broadcastmocks"
 	"github.com/hyperledger/firefly/mocks/contractmocks"
@@ -42,10 +48,13 @@ import (
 	"github.com/hyperledger/firefly/mocks/operationmocks"
 	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
 	"github.com/hyperledger/firefly/mocks/sharedstoragemocks"
+	"github.com/hyperledger/firefly/mocks/syncasyncmocks"
 	"github.com/hyperledger/firefly/mocks/tokenmocks"
 	"github.com/hyperledger/firefly/mocks/txcommonmocks"
+	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/hyperledger/firefly/pkg/tokens"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -64,6 +73,7 @@ type testOrchestrator struct {
 	mps *sharedstoragemocks.Plugin
 	mpm *privatemessagingmocks.Manager
 	mbi *blockchainmocks.Plugin
+	mbr *biroutermocks.Router
 	mii *identitymocks.Plugin
 	mim *identitymanagermocks.Manager
 	mdx *dataexchangemocks.Plugin
@@ -74,6 +84,8 @@ type testOrchestrator struct {
 	mom *operationmocks.Manager
 	mbp *batchpinmocks.Submitter
 	mth *txcommonmocks.Helper
+	msa *syncasyncmocks.Bridge
+	mar *archivemocks.Manager
 }
 
 func newTestOrchestrator() *testOrchestrator {
@@ -93,6 +105,7 @@ func newTestOrchestrator() *testOrchestrator {
 		mps: &sharedstoragemocks.Plugin{},
 		mpm: &privatemessagingmocks.Manager{},
 		mbi: &blockchainmocks.Plugin{},
+		mbr: &biroutermocks.Router{},
 		mii: &identitymocks.Plugin{},
 		mim: &identitymanagermocks.Manager{},
 		mdx: &dataexchangemocks.Plugin{},
@@ -103,6 +116,8 @@ func newTestOrchestrator() *testOrchestrator {
 		mom: &operationmocks.Manager{},
 		mbp: &batchpinmocks.Submitter{},
 		mth: &txcommonmocks.Helper{},
+		msa: &syncasyncmocks.Bridge{},
+		mar: &archivemocks.Manager{},
 	}
 	tor.orchestrator.database = tor.mdi
 	tor.orchestrator.data = tor.mdm
@@ -112,7 +127,7 @@ func newTestOrchestrator() *testOrchestrator {
 	tor.orchestrator.networkmap = tor.mnm
 	tor.orchestrator.sharedstorage = tor.mps
 	tor.orchestrator.messaging = tor.mpm
-	tor.orchestrator.blockchain = tor.mbi
+	tor.orchestrator.blockchains = tor.mbr
 	tor.orchestrator.identity = tor.mim
 	tor.orchestrator.identityPlugin = tor.mii
 	tor.orchestrator.dataexchange = tor.mdx
@@ -123,10 +138,15 @@ func newTestOrchestrator() *testOrchestrator {
 	tor.orchestrator.operations = tor.mom
 	tor.orchestrator.batchpin = tor.mbp
 	tor.orchestrator.txHelper = tor.mth
+	tor.orchestrator.syncasync = tor.msa
+	tor.orchestrator.archive = tor.mar
 	tor.mdi.On("Name").Return("mock-di").Maybe()
 	tor.mem.On("Name").Return("mock-ei").Maybe()
 	tor.mps.On("Name").Return("mock-ps").Maybe()
 	tor.mbi.On("Name").Return("mock-bi").Maybe()
+	tor.mbr.On("Plugins").Return(map[string]blockchain.Plugin{"mock-bi": tor.mbi}).Maybe()
+	tor.mbr.On("Default").Return(tor.mbi).Maybe()
+	tor.mbr.On("ForNamespace", mock.Anything).Return(tor.mbi).Maybe()
 	tor.mii.On("Name").Return("mock-ii").Maybe()
 	tor.mdx.On("Name").Return("mock-dx").Maybe()
 	tor.mam.On("Name").Return("mock-am").Maybe()
@@ -195,7 +215,7 @@ func TestBadIdentityInitFail(t *testing.T) {
 func TestBadBlockchainPlugin(t *testing.T) {
 	or := newTestOrchestrator()
 	config.Set(config.BlockchainType, "wrong")
-	or.blockchain = nil
+	or.blockchains = nil
 	or.mdi.On("GetConfigRecords", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.ConfigRecord{}, nil, nil)
 	or.mdi.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	or.mii.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
@@ -206,13 +226,184 @@ func TestBadBlockchainPlugin(t *testing.T) {
 
 func TestBlockchainInitFail(t *testing.T) {
 	or := newTestOrchestrator()
+	config.Set(config.BlockchainType, "ethereum")
+	or.blockchains = nil
 	or.mdi.On("GetConfigRecords", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.ConfigRecord{}, nil, nil)
 	or.mii.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	or.mdi.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
-	or.mbi.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
 	ctx, cancelCtx := context.WithCancel(context.Background())
 	err := or.Init(ctx, cancelCtx)
-	assert.EqualError(t, err, "pop")
+	assert.Regexp(t, "FF10138.*url", err)
+}
+
+func testBlockchainsConfig(t *testing.T, yaml string) {
+	config.Reset()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(yaml))
+	assert.NoError(t, err)
+}
+
+func TestBlockchainsInitFail(t *testing.T) {
+	or := newTestOrchestrator()
+	testBlockchainsConfig(t, `
+blockchain:
+- name: chain1
+  plugin: ethereum
+`)
+	or.blockchains = nil
+	or.mdi.On("GetConfigRecords", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.ConfigRecord{}, nil, nil)
+	or.mii.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mdi.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	err := or.Init(ctx, cancelCtx)
+	assert.Regexp(t, "FF10138.*url", err)
+}
+
+func TestBlockchainsMissingPlugin(t *testing.T) {
+	or := newTestOrchestrator()
+	testBlockchainsConfig(t, `
+blockchain:
+- name: chain1
+`)
+	or.blockchains = nil
+	or.mdi.On("GetConfigRecords", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.ConfigRecord{}, nil, nil)
+	or.mii.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mdi.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	err := or.Init(ctx, cancelCtx)
+	assert.Regexp(t, "FF10403.*0", err)
+}
+
+func TestBlockchainsBadName(t *testing.T) {
+	or := newTestOrchestrator()
+	testBlockchainsConfig(t, `
+blockchain:
+- name: "!bad"
+  plugin: ethereum
+`)
+	or.blockchains = nil
+	or.mdi.On("GetConfigRecords", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.ConfigRecord{}, nil, nil)
+	or.mii.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mdi.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	err := or.Init(ctx, cancelCtx)
+	assert.Regexp(t, "FF10131.*blockchain\\[0\\].name", err)
+}
+
+func TestBlockchainsDuplicateName(t *testing.T) {
+	or := newTestOrchestrator()
+	testBlockchainsConfig(t, `
+blockchain:
+- name: chain1
+  plugin: wrong
+- name: chain1
+  plugin: wrong
+`)
+	or.blockchains = nil
+	or.mdi.On("GetConfigRecords", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.ConfigRecord{}, nil, nil)
+	or.mii.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mdi.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	err := or.Init(ctx, cancelCtx)
+	assert.Regexp(t, "FF10404.*chain1", err)
+}
+
+func TestBlockchainsBadPlugin(t *testing.T) {
+	or := newTestOrchestrator()
+	testBlockchainsConfig(t, `
+blockchain:
+- name: chain1
+  plugin: wrong
+`)
+	or.blockchains = nil
+	or.mdi.On("GetConfigRecords", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.ConfigRecord{}, nil, nil)
+	or.mii.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mdi.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	err := or.Init(ctx, cancelCtx)
+	assert.Regexp(t, "FF10110.*wrong", err)
+}
+
+func newTestEthconnect(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`[]`))
+		} else {
+			w.Write([]byte(`{"id":"` + fftypes.NewUUID().String() + `"}`))
+		}
+	}))
+}
+
+func TestBlockchainsNamespaceBinding(t *testing.T) {
+	or := newTestOrchestrator()
+	ethconnect := newTestEthconnect(t)
+	defer ethconnect.Close()
+	testBlockchainsConfig(t, fmt.Sprintf(`
+blockchain:
+- name: chain1
+  plugin: ethereum
+  ethereum:
+    ethconnect:
+      url: %[1]s
+      instance: "0x12345"
+      topic: topic1
+- name: chain2
+  plugin: ethereum
+  ethereum:
+    ethconnect:
+      url: %[1]s
+      instance: "0x67890"
+      topic: topic2
+`, ethconnect.URL))
+	config.Set(config.NamespacesPredefined, fftypes.JSONObjectArray{
+		{"name": "ns1"},
+		{"name": "ns2", "blockchain": "chain2"},
+	})
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	defer cancelCtx()
+	err := or.initBlockchains(ctx)
+	assert.NoError(t, err)
+
+	plugins := or.blockchains.Plugins()
+	assert.Len(t, plugins, 2)
+	assert.Equal(t, plugins["chain1"], or.blockchains.Default())
+	assert.Equal(t, plugins["chain1"], or.blockchains.ForNamespace("ns1"))
+	assert.Equal(t, plugins["chain2"], or.blockchains.ForNamespace("ns2"))
+}
+
+func TestBlockchainsNamespaceBindingUnknown(t *testing.T) {
+	or := newTestOrchestrator()
+	ethconnect := newTestEthconnect(t)
+	defer ethconnect.Close()
+	testBlockchainsConfig(t, fmt.Sprintf(`
+blockchain:
+- name: chain1
+  plugin: ethereum
+  ethereum:
+    ethconnect:
+      url: %s
+      instance: "0x12345"
+      topic: topic1
+`, ethconnect.URL))
+	config.Set(config.NamespacesPredefined, fftypes.JSONObjectArray{
+		{"name": "ns1", "blockchain": "chain2"},
+	})
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	defer cancelCtx()
+	err := or.initBlockchains(ctx)
+	assert.Regexp(t, "FF10405.*ns1.*chain2", err)
+}
+
+func TestBlockchainsNoneConfigured(t *testing.T) {
+	or := newTestOrchestrator()
+	or.blockchains = nil
+	or.mdi.On("GetConfigRecords", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.ConfigRecord{}, nil, nil)
+	or.mii.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mdi.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	err := or.Init(ctx, cancelCtx)
+	assert.Regexp(t, "FF10110", err)
 }
 
 func TestBlockchainInitGetConfigRecordsFail(t *testing.T) {
@@ -458,6 +649,50 @@ func TestGoodTokensPlugin(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestGoodTokensPluginBlockchain(t *testing.T) {
+	or := newTestOrchestrator()
+	tokensConfig = config.NewPluginConfig("tokens").Array()
+	tifactory.InitPrefix(tokensConfig)
+	tokensConfig.AddKnownKey(tokens.TokensConfigName, "test")
+	tokensConfig.AddKnownKey(tokens.TokensConfigPlugin, "fftokens")
+	tokensConfig.AddKnownKey(tokens.TokensConfigBlockchain, "eth2")
+	tokensConfig.AddKnownKey(restclient.HTTPConfigURL, "test")
+	config.Set("tokens", []fftypes.JSONObject{{}})
+	or.tokens = nil
+	mbi2 := &blockchainmocks.Plugin{}
+	or.mbr.ExpectedCalls = nil
+	or.mbr.On("Plugins").Return(map[string]blockchain.Plugin{"eth1": or.mbi, "eth2": mbi2})
+	or.mdi.On("GetConfigRecords", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.ConfigRecord{}, nil, nil)
+	or.mdi.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mii.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mps.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mdi.On("GetIdentities", mock.Anything, mock.Anything).Return([]*fftypes.Identity{}, nil, nil)
+	or.mdx.On("Init", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	err := or.initPlugins(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, mbi2, or.bc.tokenChains["test"])
+}
+
+func TestBadTokensPluginUnknownBlockchain(t *testing.T) {
+	or := newTestOrchestrator()
+	tokensConfig = config.NewPluginConfig("tokens").Array()
+	tifactory.InitPrefix(tokensConfig)
+	tokensConfig.AddKnownKey(tokens.TokensConfigName, "test")
+	tokensConfig.AddKnownKey(tokens.TokensConfigPlugin, "fftokens")
+	tokensConfig.AddKnownKey(tokens.TokensConfigBlockchain, "eth2")
+	tokensConfig.AddKnownKey(restclient.HTTPConfigURL, "test")
+	config.Set("tokens", []fftypes.JSONObject{{}})
+	or.tokens = nil
+	or.mdi.On("GetConfigRecords", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.ConfigRecord{}, nil, nil)
+	or.mdi.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mii.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mps.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mdi.On("GetIdentities", mock.Anything, mock.Anything).Return([]*fftypes.Identity{}, nil, nil)
+	or.mdx.On("Init", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	err := or.initPlugins(context.Background())
+	assert.Regexp(t, "FF10447", err)
+}
+
 func TestInitMessagingComponentFail(t *testing.T) {
 	or := newTestOrchestrator()
 	or.database = nil
@@ -482,6 +717,14 @@ func TestInitNetworkMapComponentFail(t *testing.T) {
 	assert.Regexp(t, "FF10128", err)
 }
 
+func TestInitArchiveComponentFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.dataexchange = nil
+	or.archive = nil
+	err := or.initComponents(context.Background())
+	assert.Regexp(t, "FF10128", err)
+}
+
 func TestInitBatchComponentFail(t *testing.T) {
 	or := newTestOrchestrator()
 	or.database = nil
@@ -562,12 +805,32 @@ func TestStartBatchFail(t *testing.T) {
 	assert.EqualError(t, err, "pop")
 }
 
+func TestStartBlockchainFail(t *testing.T) {
+	config.Reset()
+	or := newTestOrchestrator()
+	or.mbi.On("Start").Return(fmt.Errorf("pop"))
+	err := or.Start()
+	assert.EqualError(t, err, "pop")
+}
+
+func TestStartSyncAsyncFail(t *testing.T) {
+	config.Reset()
+	or := newTestOrchestrator()
+	or.mbi.On("Start").Return(nil)
+	or.mba.On("Start").Return(nil)
+	or.mem.On("Start").Return(nil)
+	or.msa.On("Start").Return(fmt.Errorf("pop"))
+	err := or.Start()
+	assert.EqualError(t, err, "pop")
+}
+
 func TestStartTokensFail(t *testing.T) {
 	config.Reset()
 	or := newTestOrchestrator()
 	or.mbi.On("Start").Return(nil)
 	or.mba.On("Start").Return(nil)
 	or.mem.On("Start").Return(nil)
+	or.msa.On("Start").Return(nil)
 	or.mbm.On("Start").Return(nil)
 	or.mpm.On("Start").Return(nil)
 	or.mam.On("Start").Return(nil)
@@ -582,10 +845,12 @@ func TestStartStopOk(t *testing.T) {
 	or.mbi.On("Start").Return(nil)
 	or.mba.On("Start").Return(nil)
 	or.mem.On("Start").Return(nil)
+	or.msa.On("Start").Return(nil)
 	or.mbm.On("Start").Return(nil)
 	or.mpm.On("Start").Return(nil)
 	or.mam.On("Start").Return(nil)
 	or.mti.On("Start").Return(nil)
+	or.mom.On("Start").Return(nil)
 	or.mmi.On("Start").Return(nil)
 	or.mbi.On("WaitStop").Return(nil)
 	or.mba.On("WaitStop").Return(nil)
@@ -594,12 +859,43 @@ func TestStartStopOk(t *testing.T) {
 	or.mam.On("WaitStop").Return(nil)
 	or.mti.On("WaitStop").Return(nil)
 	or.mdm.On("WaitStop").Return(nil)
+	or.mom.On("WaitStop").Return(nil)
+	or.msa.On("WaitStop").Return(nil)
 	err := or.Start()
 	assert.NoError(t, err)
+	assert.True(t, or.isStarted())
 	or.WaitStop()
+	assert.False(t, or.isStarted())
 	or.WaitStop() // swallows dups
 }
 
+func TestStartStopRetentionOk(t *testing.T) {
+	config.Reset()
+	or := newTestOrchestrator()
+	or.retentionPolicies = []*retentionPolicy{{namespace: "ns1", messageAge: time.Hour}}
+	or.mbi.On("Start").Return(nil)
+	or.mba.On("Start").Return(nil)
+	or.mem.On("Start").Return(nil)
+	or.msa.On("Start").Return(nil)
+	or.mbm.On("Start").Return(nil)
+	or.mpm.On("Start").Return(nil)
+	or.mti.On("Start").Return(nil)
+	or.mom.On("Start").Return(nil)
+	or.mmi.On("Start").Return(nil)
+	or.mba.On("WaitStop").Return(nil)
+	or.mbm.On("WaitStop").Return(nil)
+	or.mdm.On("WaitStop").Return(nil)
+	or.mom.On("WaitStop").Return(nil)
+	or.msa.On("WaitStop").Return(nil)
+	or.mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Run(func(args mock.Arguments) {
+		or.cancelCtx()
+	})
+	err := or.Start()
+	assert.NoError(t, err)
+	or.WaitStop()
+	assert.Nil(t, or.prunerDone)
+}
+
 func TestInitNamespacesBadName(t *testing.T) {
 	or := newTestOrchestrator()
 	config.Reset()
@@ -685,6 +981,7 @@ func TestInitOK(t *testing.T) {
 	assert.Equal(t, or.mdm, or.Data())
 	assert.Equal(t, or.mam, or.Assets())
 	assert.Equal(t, or.mcm, or.Contracts())
+	assert.Equal(t, or.mar, or.Archive())
 	assert.Equal(t, or.mmi, or.Metrics())
 	assert.Equal(t, or.mom, or.Operations())
 }
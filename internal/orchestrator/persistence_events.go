@@ -72,6 +72,8 @@ func (or *orchestrator) UUIDCollectionNSEvent(resType database.UUIDCollectionNS,
 		or.events.DeletedSubscriptions() <- id
 	case eventType == fftypes.ChangeEventTypeUpdated && resType == database.CollectionSubscriptions:
 		or.events.SubscriptionUpdates() <- id
+	case eventType == fftypes.ChangeEventTypeUpdated && resType == database.CollectionInflightRequests:
+		or.syncasync.InflightRequestUpdated(ns, id)
 	}
 	or.attemptChangeEventDispatch(&fftypes.ChangeEvent{
 		Collection: string(resType),
@@ -22,6 +22,7 @@ import (
 
 	"github.com/hyperledger/firefly/mocks/batchmocks"
 	"github.com/hyperledger/firefly/mocks/eventmocks"
+	"github.com/hyperledger/firefly/mocks/syncasyncmocks"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
@@ -115,3 +116,18 @@ func TestHashCollectionNSEventOk(t *testing.T) {
 	o.HashCollectionNSEvent(database.CollectionGroups, fftypes.ChangeEventTypeDeleted, "ns1", fftypes.NewRandB32())
 	mem.AssertExpectations(t)
 }
+
+func TestInflightRequestUpdated(t *testing.T) {
+	mem := &eventmocks.EventManager{}
+	msa := &syncasyncmocks.Bridge{}
+	o := &orchestrator{
+		events:    mem,
+		syncasync: msa,
+	}
+	id := fftypes.NewUUID()
+	msa.On("InflightRequestUpdated", "ns1", id).Return()
+	mem.On("ChangeEvents").Return((chan<- *fftypes.ChangeEvent)(make(chan *fftypes.ChangeEvent, 1)))
+	o.UUIDCollectionNSEvent(database.CollectionInflightRequests, fftypes.ChangeEventTypeUpdated, "ns1", id)
+	msa.AssertExpectations(t)
+	mem.AssertExpectations(t)
+}
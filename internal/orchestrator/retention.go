@@ -0,0 +1,356 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+type retentionPolicy struct {
+	namespace  string
+	messageAge time.Duration
+}
+
+type prunedCounts struct {
+	messages         int
+	data             int
+	blobs            int
+	events           int
+	batches          int
+	pins             int
+	operations       int
+	blockchainEvents int
+	// blobPayloads are deleted from data exchange once the database changes are committed
+	blobPayloads []string
+}
+
+func (or *orchestrator) initRetention(ctx context.Context) error {
+	or.retentionPolicies = nil
+	if !config.GetBool(config.RetentionEnabled) {
+		return nil
+	}
+	for i, policyObject := range config.GetObjectArray(config.RetentionPolicies) {
+		ns := policyObject.GetString("namespace")
+		if err := fftypes.ValidateFFNameField(ctx, ns, fmt.Sprintf("retention.policies[%d].namespace", i)); err != nil {
+			return err
+		}
+		messageAge := policyObject.GetString("messageAge")
+		age, err := fftypes.ParseDurationString(messageAge, time.Millisecond)
+		if err != nil || age <= 0 {
+			return i18n.NewError(ctx, i18n.MsgInvalidRetentionPolicy, i, messageAge)
+		}
+		or.retentionPolicies = append(or.retentionPolicies, &retentionPolicy{
+			namespace:  ns,
+			messageAge: time.Duration(age),
+		})
+	}
+	return nil
+}
+
+func (or *orchestrator) startRetention() {
+	if len(or.retentionPolicies) > 0 {
+		or.prunerDone = make(chan struct{})
+		go or.retentionLoop()
+	}
+}
+
+func (or *orchestrator) retentionLoop() {
+	defer close(or.prunerDone)
+
+	interval := config.GetDuration(config.RetentionInterval)
+	for {
+		for _, policy := range or.retentionPolicies {
+			if err := or.pruneNamespace(or.ctx, policy); err != nil {
+				log.L(or.ctx).Errorf("Retention pruning failed for namespace '%s': %s", policy.namespace, err)
+			}
+		}
+		select {
+		case <-time.After(interval):
+		case <-or.ctx.Done():
+			log.L(or.ctx).Debugf("Retention pruner exiting")
+			return
+		}
+	}
+}
+
+// consumedUpTo returns the lowest event sequence that has been consumed by every durable subscription
+// on the namespace. Events beyond this point must not be pruned. The subscribed flag is false if there
+// are no durable subscriptions, in which case there is no limit on the events that can be pruned.
+// A consumed sequence of -1 with subscriptions means nothing has been consumed yet.
+func (or *orchestrator) consumedUpTo(ctx context.Context, ns string) (consumed int64, subscribed bool, err error) {
+	fb := database.SubscriptionQueryFactory.NewFilter(ctx)
+	subs, _, err := or.database.GetSubscriptions(ctx, fb.Eq("namespace", ns))
+	if err != nil {
+		return -1, false, err
+	}
+	consumed = -1
+	for _, sub := range subs {
+		offset, err := or.database.GetOffset(ctx, fftypes.OffsetTypeSubscription, sub.ID.String())
+		if err != nil {
+			return -1, false, err
+		}
+		current := int64(-1)
+		if offset != nil {
+			current = offset.Current
+		}
+		if !subscribed || current < consumed {
+			consumed = current
+		}
+		subscribed = true
+	}
+	return consumed, subscribed, nil
+}
+
+func (or *orchestrator) pruneNamespace(ctx context.Context, policy *retentionPolicy) error {
+	consumed, subscribed, err := or.consumedUpTo(ctx, policy.namespace)
+	if err != nil {
+		return err
+	}
+	if subscribed && consumed < 0 {
+		log.L(ctx).Debugf("Retention pruning paused for namespace '%s' until subscriptions have consumed events", policy.namespace)
+		return nil
+	}
+
+	cutoff := fftypes.FFTime(time.Now().Add(-policy.messageAge))
+	fb := database.MessageQueryFactory.NewFilter(ctx)
+	filter := fb.And(
+		fb.Eq("namespace", policy.namespace),
+		fb.In("state", []driver.Value{fftypes.MessageStateConfirmed, fftypes.MessageStateRejected}),
+		fb.Lt("confirmed", cutoff),
+	).Sort("confirmed").Limit(uint64(config.GetInt(config.RetentionBatchSize)))
+	msgs, _, err := or.database.GetMessages(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range msgs {
+		counts := &prunedCounts{}
+		var pruned bool
+		err := or.database.RunAsGroup(ctx, func(ctx context.Context) (err error) {
+			pruned, err = or.pruneMessage(ctx, msg, consumed, subscribed, counts)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if !pruned {
+			// Messages are processed in confirmation order, so everything after this is also unconsumed
+			log.L(ctx).Debugf("Retention pruning paused at message %s until subscriptions have consumed its events", msg.Header.ID)
+			return nil
+		}
+		or.deleteBlobPayloads(ctx, counts.blobPayloads)
+		or.countPruned(policy.namespace, counts)
+	}
+	return nil
+}
+
+func (or *orchestrator) pruneMessage(ctx context.Context, msg *fftypes.Message, consumed int64, subscribed bool, counts *prunedCounts) (bool, error) {
+	fb := database.EventQueryFactory.NewFilter(ctx)
+	events, _, err := or.database.GetEvents(ctx, fb.Eq("reference", msg.Header.ID))
+	if err != nil {
+		return false, err
+	}
+	if subscribed {
+		for _, event := range events {
+			if event.Sequence > consumed {
+				return false, nil
+			}
+		}
+	}
+
+	if err := or.database.DeleteMessage(ctx, msg.Header.ID); err != nil {
+		return false, err
+	}
+	counts.messages++
+	for _, event := range events {
+		if err := or.database.DeleteEvent(ctx, event.ID); err != nil {
+			return false, err
+		}
+		counts.events++
+	}
+	for _, dataRef := range msg.Data {
+		if err := or.pruneData(ctx, dataRef.ID, counts); err != nil {
+			return false, err
+		}
+	}
+	if msg.BatchID != nil {
+		if err := or.pruneBatch(ctx, msg.BatchID, counts); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// pruneData removes a data record, and the blob records for its payload once no other data refers to it.
+// The blob payloads are collected, to be deleted from data exchange after the database changes commit.
+func (or *orchestrator) pruneData(ctx context.Context, dataID *fftypes.UUID, counts *prunedCounts) error {
+	// Data can be shared between messages, so only remove it once the last reference has gone
+	fb := database.MessageQueryFactory.NewFilter(ctx)
+	refs, _, err := or.database.GetMessagesForData(ctx, dataID, fb.And().Limit(1))
+	if err != nil || len(refs) > 0 {
+		return err
+	}
+	data, err := or.database.GetDataByID(ctx, dataID, false)
+	if err != nil || data == nil {
+		return err
+	}
+	if err := or.database.DeleteData(ctx, dataID); err != nil {
+		return err
+	}
+	counts.data++
+
+	if data.Blob == nil || data.Blob.Hash == nil {
+		return nil
+	}
+	dfb := database.DataQueryFactory.NewFilter(ctx)
+	others, _, err := or.database.GetDataRefs(ctx, dfb.Eq("blob.hash", data.Blob.Hash).Limit(1))
+	if err != nil || len(others) > 0 {
+		return err
+	}
+	bfb := database.BlobQueryFactory.NewFilter(ctx)
+	blobs, _, err := or.database.GetBlobs(ctx, bfb.Eq("hash", data.Blob.Hash))
+	if err != nil {
+		return err
+	}
+	for _, blob := range blobs {
+		if err := or.database.DeleteBlob(ctx, blob.Sequence); err != nil {
+			return err
+		}
+		counts.blobs++
+		counts.blobPayloads = append(counts.blobPayloads, blob.PayloadRef)
+	}
+	return nil
+}
+
+// deleteBlobPayloads removes the payloads of pruned blobs from data exchange. A failure is only logged, as
+// the records that refer to the payload have already been removed.
+func (or *orchestrator) deleteBlobPayloads(ctx context.Context, payloadRefs []string) {
+	for _, payloadRef := range payloadRefs {
+		if err := or.dataexchange.DeleteBLOB(ctx, payloadRef); err != nil {
+			log.L(ctx).Errorf("Failed to delete payload '%s' of pruned blob: %s", payloadRef, err)
+		}
+	}
+}
+
+func (or *orchestrator) pruneBatch(ctx context.Context, batchID *fftypes.UUID, counts *prunedCounts) error {
+	fb := database.MessageQueryFactory.NewFilter(ctx)
+	remaining, _, err := or.database.GetMessages(ctx, fb.Eq("batch", batchID).Limit(1))
+	if err != nil || len(remaining) > 0 {
+		return err
+	}
+	batch, err := or.database.GetBatchByID(ctx, batchID)
+	if err != nil || batch == nil {
+		return err
+	}
+	if err := or.database.DeleteBatch(ctx, batchID); err != nil {
+		return err
+	}
+	counts.batches++
+
+	pfb := database.PinQueryFactory.NewFilter(ctx)
+	pins, _, err := or.database.GetPins(ctx, pfb.Eq("batch", batchID))
+	if err != nil {
+		return err
+	}
+	for _, pin := range pins {
+		if err := or.database.DeletePin(ctx, pin.Sequence); err != nil {
+			return err
+		}
+		counts.pins++
+	}
+
+	if batch.TX.ID == nil {
+		return nil
+	}
+	return or.pruneTransaction(ctx, batch.Namespace, batch.TX.ID, counts)
+}
+
+// pruneTransaction removes the operations and blockchain events of the transaction that pinned a pruned batch.
+// The blockchain events are recorded before the messages in the batch are confirmed, so the events that refer
+// to them have been consumed before the events of the pruned messages.
+func (or *orchestrator) pruneTransaction(ctx context.Context, ns string, txID *fftypes.UUID, counts *prunedCounts) error {
+	ofb := database.OperationQueryFactory.NewFilter(ctx)
+	ops, _, err := or.database.GetOperations(ctx, ofb.Eq("tx", txID))
+	if err != nil {
+		return err
+	}
+	for _, op := range ops {
+		if err := or.database.DeleteOperation(ctx, op.ID); err != nil {
+			return err
+		}
+		counts.operations++
+	}
+
+	bfb := database.BlockchainEventQueryFactory.NewFilter(ctx)
+	chainEvents, _, err := or.database.GetBlockchainEvents(ctx, bfb.And(
+		bfb.Eq("namespace", ns),
+		bfb.Eq("tx.id", txID),
+	))
+	if err != nil {
+		return err
+	}
+	for _, chainEvent := range chainEvents {
+		efb := database.EventQueryFactory.NewFilter(ctx)
+		events, _, err := or.database.GetEvents(ctx, efb.Eq("reference", chainEvent.ID))
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if err := or.database.DeleteEvent(ctx, event.ID); err != nil {
+				return err
+			}
+			counts.events++
+		}
+		if err := or.database.DeleteBlockchainEvent(ctx, chainEvent.ID); err != nil {
+			return err
+		}
+		counts.blockchainEvents++
+	}
+	return nil
+}
+
+func (or *orchestrator) countPruned(ns string, counts *prunedCounts) {
+	if !or.metrics.IsMetricsEnabled() {
+		return
+	}
+	for _, c := range []struct {
+		collection string
+		count      int
+	}{
+		{string(database.CollectionMessages), counts.messages},
+		{string(database.CollectionData), counts.data},
+		{string(database.CollectionBlobs), counts.blobs},
+		{string(database.CollectionEvents), counts.events},
+		{string(database.CollectionBatches), counts.batches},
+		{string(database.CollectionPins), counts.pins},
+		{string(database.CollectionOperations), counts.operations},
+		{string(database.CollectionBlockchainEvents), counts.blockchainEvents},
+	} {
+		if c.count > 0 {
+			or.metrics.CountPruned(ns, c.collection, c.count)
+		}
+	}
+}
@@ -0,0 +1,541 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestRetention() (*testOrchestrator, *retentionPolicy) {
+	or := newTestOrchestrator()
+	rag := or.mdi.On("RunAsGroup", mock.Anything, mock.Anything).Maybe()
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+	return or, &retentionPolicy{namespace: "ns1", messageAge: time.Hour}
+}
+
+func newTestPrunableMessage() *fftypes.Message {
+	return &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+		},
+		BatchID: fftypes.NewUUID(),
+		Data: fftypes.DataRefs{
+			{ID: fftypes.NewUUID()},
+		},
+	}
+}
+
+func TestInitRetentionDisabled(t *testing.T) {
+	or := newTestOrchestrator()
+	config.Set(config.RetentionPolicies, fftypes.JSONObjectArray{
+		{"namespace": "ns1", "messageAge": "1h"},
+	})
+	err := or.initRetention(or.ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, or.retentionPolicies)
+}
+
+func TestInitRetentionOk(t *testing.T) {
+	or := newTestOrchestrator()
+	config.Set(config.RetentionEnabled, true)
+	config.Set(config.RetentionPolicies, fftypes.JSONObjectArray{
+		{"namespace": "ns1", "messageAge": "2160h"},
+		{"namespace": "ns2", "messageAge": "60000"},
+	})
+	err := or.initRetention(or.ctx)
+	assert.NoError(t, err)
+	assert.Len(t, or.retentionPolicies, 2)
+	assert.Equal(t, "ns1", or.retentionPolicies[0].namespace)
+	assert.Equal(t, 90*24*time.Hour, or.retentionPolicies[0].messageAge)
+	assert.Equal(t, time.Minute, or.retentionPolicies[1].messageAge)
+}
+
+func TestInitRetentionBadNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	config.Set(config.RetentionEnabled, true)
+	config.Set(config.RetentionPolicies, fftypes.JSONObjectArray{
+		{"namespace": "!bad", "messageAge": "1h"},
+	})
+	err := or.initRetention(or.ctx)
+	assert.Regexp(t, "FF10131.*retention.policies\\[0\\].namespace", err)
+}
+
+func TestInitRetentionBadAge(t *testing.T) {
+	or := newTestOrchestrator()
+	config.Set(config.RetentionEnabled, true)
+	config.Set(config.RetentionPolicies, fftypes.JSONObjectArray{
+		{"namespace": "ns1", "messageAge": "forever"},
+	})
+	err := or.initRetention(or.ctx)
+	assert.Regexp(t, "FF10389", err)
+}
+
+func TestInitRetentionZeroAge(t *testing.T) {
+	or := newTestOrchestrator()
+	config.Set(config.RetentionEnabled, true)
+	config.Set(config.RetentionPolicies, fftypes.JSONObjectArray{
+		{"namespace": "ns1"},
+	})
+	err := or.initRetention(or.ctx)
+	assert.Regexp(t, "FF10389", err)
+}
+
+func TestRetentionLoopStartStop(t *testing.T) {
+	or, policy := newTestRetention()
+	or.retentionPolicies = []*retentionPolicy{policy}
+	config.Set(config.RetentionInterval, "1s")
+	polled := make(chan struct{})
+	or.mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Run(func(args mock.Arguments) {
+		close(polled)
+	}).Once()
+	or.startRetention()
+	<-polled
+	or.cancelCtx()
+	<-or.prunerDone
+	or.mdi.AssertExpectations(t)
+}
+
+func TestStartNoRetentionPolicies(t *testing.T) {
+	or := newTestOrchestrator()
+	or.startRetention()
+	assert.Nil(t, or.prunerDone)
+}
+
+func TestPruneNamespaceOk(t *testing.T) {
+	or, policy := newTestRetention()
+	msg := newTestPrunableMessage()
+	sub := &fftypes.Subscription{SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID()}}
+	event := &fftypes.Event{ID: fftypes.NewUUID(), Sequence: 10}
+	blobHash := fftypes.NewRandB32()
+	batch := &fftypes.BatchPersisted{TX: fftypes.TransactionRef{ID: fftypes.NewUUID()}}
+	op := &fftypes.Operation{ID: fftypes.NewUUID()}
+	chainEvent := &fftypes.BlockchainEvent{ID: fftypes.NewUUID()}
+	chainEventReceived := &fftypes.Event{ID: fftypes.NewUUID(), Sequence: 5}
+
+	or.mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{sub}, nil, nil)
+	or.mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeSubscription, sub.ID.String()).Return(&fftypes.Offset{Current: 10}, nil)
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{msg}, nil, nil).Once()
+	or.mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{event}, nil, nil).Once()
+	or.mdi.On("DeleteMessage", mock.Anything, msg.Header.ID).Return(nil)
+	or.mdi.On("DeleteEvent", mock.Anything, event.ID).Return(nil)
+	or.mdi.On("GetMessagesForData", mock.Anything, msg.Data[0].ID, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	or.mdi.On("GetDataByID", mock.Anything, msg.Data[0].ID, false).Return(&fftypes.Data{
+		ID:   msg.Data[0].ID,
+		Blob: &fftypes.BlobRef{Hash: blobHash},
+	}, nil)
+	or.mdi.On("DeleteData", mock.Anything, msg.Data[0].ID).Return(nil)
+	or.mdi.On("GetDataRefs", mock.Anything, mock.Anything).Return(fftypes.DataRefs{}, nil, nil)
+	or.mdi.On("GetBlobs", mock.Anything, mock.Anything).Return([]*fftypes.Blob{{Sequence: 12345, PayloadRef: "ns1/blob1"}}, nil, nil)
+	or.mdi.On("DeleteBlob", mock.Anything, int64(12345)).Return(nil)
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, nil, nil).Once()
+	or.mdi.On("GetBatchByID", mock.Anything, msg.BatchID).Return(batch, nil)
+	or.mdi.On("DeleteBatch", mock.Anything, msg.BatchID).Return(nil)
+	or.mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*fftypes.Pin{{Sequence: 54321}}, nil, nil)
+	or.mdi.On("DeletePin", mock.Anything, int64(54321)).Return(nil)
+	or.mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{op}, nil, nil)
+	or.mdi.On("DeleteOperation", mock.Anything, op.ID).Return(nil)
+	or.mdi.On("GetBlockchainEvents", mock.Anything, mock.Anything).Return([]*fftypes.BlockchainEvent{chainEvent}, nil, nil)
+	or.mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{chainEventReceived}, nil, nil).Once()
+	or.mdi.On("DeleteEvent", mock.Anything, chainEventReceived.ID).Return(nil)
+	or.mdi.On("DeleteBlockchainEvent", mock.Anything, chainEvent.ID).Return(nil)
+	or.mdx.On("DeleteBLOB", mock.Anything, "ns1/blob1").Return(nil)
+	or.mmi.On("IsMetricsEnabled").Return(true)
+	for _, collection := range []string{"messages", "data", "blobs", "batches", "pins", "operations", "blockchainevents"} {
+		or.mmi.On("CountPruned", "ns1", collection, 1).Return()
+	}
+	or.mmi.On("CountPruned", "ns1", "events", 2).Return()
+
+	err := or.pruneNamespace(or.ctx, policy)
+	assert.NoError(t, err)
+
+	or.mdi.AssertExpectations(t)
+	or.mdx.AssertExpectations(t)
+	or.mmi.AssertExpectations(t)
+}
+
+func TestDeleteBlobPayloadsFail(t *testing.T) {
+	or, _ := newTestRetention()
+	or.mdx.On("DeleteBLOB", mock.Anything, "ns1/blob1").Return(fmt.Errorf("pop"))
+	or.mdx.On("DeleteBLOB", mock.Anything, "ns1/blob2").Return(nil)
+	or.deleteBlobPayloads(or.ctx, []string{"ns1/blob1", "ns1/blob2"})
+	or.mdx.AssertExpectations(t)
+}
+
+func TestPruneNamespaceSharedDataAndBatch(t *testing.T) {
+	or, policy := newTestRetention()
+	msg := newTestPrunableMessage()
+
+	or.mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{}, nil, nil)
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{msg}, nil, nil).Once()
+	or.mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{}, nil, nil)
+	or.mdi.On("DeleteMessage", mock.Anything, msg.Header.ID).Return(nil)
+	or.mdi.On("GetMessagesForData", mock.Anything, msg.Data[0].ID, mock.Anything).Return([]*fftypes.Message{{}}, nil, nil)
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{{}}, nil, nil).Once()
+	or.mmi.On("IsMetricsEnabled").Return(true)
+	or.mmi.On("CountPruned", "ns1", "messages", 1).Return()
+
+	err := or.pruneNamespace(or.ctx, policy)
+	assert.NoError(t, err)
+
+	or.mdi.AssertExpectations(t)
+	or.mmi.AssertExpectations(t)
+}
+
+func TestPruneNamespaceNoBlobOrTX(t *testing.T) {
+	or, policy := newTestRetention()
+	msg := newTestPrunableMessage()
+
+	or.mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{}, nil, nil)
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{msg}, nil, nil).Once()
+	or.mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{}, nil, nil)
+	or.mdi.On("DeleteMessage", mock.Anything, msg.Header.ID).Return(nil)
+	or.mdi.On("GetMessagesForData", mock.Anything, msg.Data[0].ID, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	or.mdi.On("GetDataByID", mock.Anything, msg.Data[0].ID, false).Return(&fftypes.Data{ID: msg.Data[0].ID}, nil)
+	or.mdi.On("DeleteData", mock.Anything, msg.Data[0].ID).Return(nil)
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, nil, nil).Once()
+	or.mdi.On("GetBatchByID", mock.Anything, msg.BatchID).Return(&fftypes.BatchPersisted{}, nil)
+	or.mdi.On("DeleteBatch", mock.Anything, msg.BatchID).Return(nil)
+	or.mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*fftypes.Pin{}, nil, nil)
+	or.mmi.On("IsMetricsEnabled").Return(false)
+
+	err := or.pruneNamespace(or.ctx, policy)
+	assert.NoError(t, err)
+
+	or.mdi.AssertExpectations(t)
+	or.mmi.AssertExpectations(t)
+}
+
+func TestPruneNamespaceUnconsumed(t *testing.T) {
+	or, policy := newTestRetention()
+	msg1 := newTestPrunableMessage()
+	msg2 := newTestPrunableMessage()
+	sub1 := &fftypes.Subscription{SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID()}}
+	sub2 := &fftypes.Subscription{SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID()}}
+
+	or.mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{sub1, sub2}, nil, nil)
+	or.mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeSubscription, sub1.ID.String()).Return(&fftypes.Offset{Current: 100}, nil)
+	or.mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeSubscription, sub2.ID.String()).Return(&fftypes.Offset{Current: 0}, nil)
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{msg1, msg2}, nil, nil)
+	or.mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{{ID: fftypes.NewUUID(), Sequence: 1}}, nil, nil).Once()
+
+	err := or.pruneNamespace(or.ctx, policy)
+	assert.NoError(t, err)
+
+	or.mdi.AssertExpectations(t)
+}
+
+func TestPruneNamespaceSubscriptionFromOldest(t *testing.T) {
+	or, policy := newTestRetention()
+	sub1 := &fftypes.Subscription{SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID()}}
+	sub2 := &fftypes.Subscription{SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID()}}
+
+	or.mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{sub1, sub2}, nil, nil)
+	or.mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeSubscription, sub1.ID.String()).Return(&fftypes.Offset{Current: 100}, nil)
+	or.mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeSubscription, sub2.ID.String()).Return(&fftypes.Offset{Current: -1}, nil)
+
+	err := or.pruneNamespace(or.ctx, policy)
+	assert.NoError(t, err)
+
+	or.mdi.AssertExpectations(t)
+	or.mdi.AssertNotCalled(t, "GetMessages", mock.Anything, mock.Anything)
+}
+
+func TestPruneNamespaceSubscriptionNoOffset(t *testing.T) {
+	or, policy := newTestRetention()
+	sub := &fftypes.Subscription{SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID()}}
+
+	or.mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{sub}, nil, nil)
+	or.mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeSubscription, sub.ID.String()).Return(nil, nil)
+
+	err := or.pruneNamespace(or.ctx, policy)
+	assert.NoError(t, err)
+
+	or.mdi.AssertExpectations(t)
+	or.mdi.AssertNotCalled(t, "GetMessages", mock.Anything, mock.Anything)
+}
+
+func TestPruneNamespaceGetSubscriptionsFail(t *testing.T) {
+	or, policy := newTestRetention()
+	or.mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	err := or.pruneNamespace(or.ctx, policy)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPruneNamespaceGetOffsetFail(t *testing.T) {
+	or, policy := newTestRetention()
+	sub := &fftypes.Subscription{SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID()}}
+	or.mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{sub}, nil, nil)
+	or.mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeSubscription, sub.ID.String()).Return(nil, fmt.Errorf("pop"))
+	err := or.pruneNamespace(or.ctx, policy)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPruneNamespaceGetMessagesFail(t *testing.T) {
+	or, policy := newTestRetention()
+	or.mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{}, nil, nil)
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	err := or.pruneNamespace(or.ctx, policy)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPruneMessageGetEventsFail(t *testing.T) {
+	or, _ := newTestRetention()
+	or.mdi.On("GetEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := or.pruneMessage(or.ctx, newTestPrunableMessage(), -1, false, &prunedCounts{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPruneMessageDeleteMessageFail(t *testing.T) {
+	or, policy := newTestRetention()
+	msg := newTestPrunableMessage()
+	or.mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{}, nil, nil)
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{msg}, nil, nil)
+	or.mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{}, nil, nil)
+	or.mdi.On("DeleteMessage", mock.Anything, msg.Header.ID).Return(fmt.Errorf("pop"))
+	err := or.pruneNamespace(or.ctx, policy)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPruneMessageDeleteEventFail(t *testing.T) {
+	or, _ := newTestRetention()
+	msg := newTestPrunableMessage()
+	or.mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{{ID: fftypes.NewUUID()}}, nil, nil)
+	or.mdi.On("DeleteMessage", mock.Anything, msg.Header.ID).Return(nil)
+	or.mdi.On("DeleteEvent", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	_, err := or.pruneMessage(or.ctx, msg, -1, false, &prunedCounts{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPruneMessageDataFail(t *testing.T) {
+	or, _ := newTestRetention()
+	msg := newTestPrunableMessage()
+	or.mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{}, nil, nil)
+	or.mdi.On("DeleteMessage", mock.Anything, msg.Header.ID).Return(nil)
+	or.mdi.On("GetMessagesForData", mock.Anything, msg.Data[0].ID, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := or.pruneMessage(or.ctx, msg, -1, false, &prunedCounts{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPruneMessageBatchFail(t *testing.T) {
+	or, _ := newTestRetention()
+	msg := newTestPrunableMessage()
+	msg.Data = fftypes.DataRefs{}
+	or.mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{}, nil, nil)
+	or.mdi.On("DeleteMessage", mock.Anything, msg.Header.ID).Return(nil)
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := or.pruneMessage(or.ctx, msg, -1, false, &prunedCounts{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPruneDataGetDataFail(t *testing.T) {
+	or, _ := newTestRetention()
+	dataID := fftypes.NewUUID()
+	or.mdi.On("GetMessagesForData", mock.Anything, dataID, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	or.mdi.On("GetDataByID", mock.Anything, dataID, false).Return(nil, fmt.Errorf("pop"))
+	err := or.pruneData(or.ctx, dataID, &prunedCounts{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPruneDataDeleteDataFail(t *testing.T) {
+	or, _ := newTestRetention()
+	dataID := fftypes.NewUUID()
+	or.mdi.On("GetMessagesForData", mock.Anything, dataID, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	or.mdi.On("GetDataByID", mock.Anything, dataID, false).Return(&fftypes.Data{ID: dataID}, nil)
+	or.mdi.On("DeleteData", mock.Anything, dataID).Return(fmt.Errorf("pop"))
+	err := or.pruneData(or.ctx, dataID, &prunedCounts{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPruneDataBlobStillReferenced(t *testing.T) {
+	or, _ := newTestRetention()
+	dataID := fftypes.NewUUID()
+	or.mdi.On("GetMessagesForData", mock.Anything, dataID, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	or.mdi.On("GetDataByID", mock.Anything, dataID, false).Return(&fftypes.Data{
+		ID:   dataID,
+		Blob: &fftypes.BlobRef{Hash: fftypes.NewRandB32()},
+	}, nil)
+	or.mdi.On("DeleteData", mock.Anything, dataID).Return(nil)
+	or.mdi.On("GetDataRefs", mock.Anything, mock.Anything).Return(fftypes.DataRefs{{ID: fftypes.NewUUID()}}, nil, nil)
+	counts := &prunedCounts{}
+	err := or.pruneData(or.ctx, dataID, counts)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, counts.data)
+	assert.Equal(t, 0, counts.blobs)
+	or.mdi.AssertExpectations(t)
+}
+
+func TestPruneDataGetDataRefsFail(t *testing.T) {
+	or, _ := newTestRetention()
+	dataID := fftypes.NewUUID()
+	or.mdi.On("GetMessagesForData", mock.Anything, dataID, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	or.mdi.On("GetDataByID", mock.Anything, dataID, false).Return(&fftypes.Data{
+		ID:   dataID,
+		Blob: &fftypes.BlobRef{Hash: fftypes.NewRandB32()},
+	}, nil)
+	or.mdi.On("DeleteData", mock.Anything, dataID).Return(nil)
+	or.mdi.On("GetDataRefs", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	err := or.pruneData(or.ctx, dataID, &prunedCounts{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPruneDataGetBlobFail(t *testing.T) {
+	or, _ := newTestRetention()
+	dataID := fftypes.NewUUID()
+	blobHash := fftypes.NewRandB32()
+	or.mdi.On("GetMessagesForData", mock.Anything, dataID, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	or.mdi.On("GetDataByID", mock.Anything, dataID, false).Return(&fftypes.Data{
+		ID:   dataID,
+		Blob: &fftypes.BlobRef{Hash: blobHash},
+	}, nil)
+	or.mdi.On("DeleteData", mock.Anything, dataID).Return(nil)
+	or.mdi.On("GetDataRefs", mock.Anything, mock.Anything).Return(fftypes.DataRefs{}, nil, nil)
+	or.mdi.On("GetBlobs", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	err := or.pruneData(or.ctx, dataID, &prunedCounts{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPruneDataDeleteBlobFail(t *testing.T) {
+	or, _ := newTestRetention()
+	dataID := fftypes.NewUUID()
+	blobHash := fftypes.NewRandB32()
+	or.mdi.On("GetMessagesForData", mock.Anything, dataID, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	or.mdi.On("GetDataByID", mock.Anything, dataID, false).Return(&fftypes.Data{
+		ID:   dataID,
+		Blob: &fftypes.BlobRef{Hash: blobHash},
+	}, nil)
+	or.mdi.On("DeleteData", mock.Anything, dataID).Return(nil)
+	or.mdi.On("GetDataRefs", mock.Anything, mock.Anything).Return(fftypes.DataRefs{}, nil, nil)
+	or.mdi.On("GetBlobs", mock.Anything, mock.Anything).Return([]*fftypes.Blob{{Sequence: 1}}, nil, nil)
+	or.mdi.On("DeleteBlob", mock.Anything, int64(1)).Return(fmt.Errorf("pop"))
+	err := or.pruneData(or.ctx, dataID, &prunedCounts{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPruneBatchGetBatchFail(t *testing.T) {
+	or, _ := newTestRetention()
+	batchID := fftypes.NewUUID()
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, batchID).Return(nil, fmt.Errorf("pop"))
+	err := or.pruneBatch(or.ctx, batchID, &prunedCounts{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPruneBatchDeleteBatchFail(t *testing.T) {
+	or, _ := newTestRetention()
+	batchID := fftypes.NewUUID()
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, batchID).Return(&fftypes.BatchPersisted{}, nil)
+	or.mdi.On("DeleteBatch", mock.Anything, batchID).Return(fmt.Errorf("pop"))
+	err := or.pruneBatch(or.ctx, batchID, &prunedCounts{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPruneBatchGetOperationsFail(t *testing.T) {
+	or, _ := newTestRetention()
+	batchID := fftypes.NewUUID()
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, batchID).Return(&fftypes.BatchPersisted{TX: fftypes.TransactionRef{ID: fftypes.NewUUID()}}, nil)
+	or.mdi.On("DeleteBatch", mock.Anything, batchID).Return(nil)
+	or.mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*fftypes.Pin{}, nil, nil)
+	or.mdi.On("GetOperations", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	err := or.pruneBatch(or.ctx, batchID, &prunedCounts{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPruneBatchDeleteOperationFail(t *testing.T) {
+	or, _ := newTestRetention()
+	batchID := fftypes.NewUUID()
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, batchID).Return(&fftypes.BatchPersisted{TX: fftypes.TransactionRef{ID: fftypes.NewUUID()}}, nil)
+	or.mdi.On("DeleteBatch", mock.Anything, batchID).Return(nil)
+	or.mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*fftypes.Pin{}, nil, nil)
+	or.mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{{ID: fftypes.NewUUID()}}, nil, nil)
+	or.mdi.On("DeleteOperation", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	err := or.pruneBatch(or.ctx, batchID, &prunedCounts{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPruneBatchGetPinsFail(t *testing.T) {
+	or, _ := newTestRetention()
+	batchID := fftypes.NewUUID()
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, batchID).Return(&fftypes.BatchPersisted{}, nil)
+	or.mdi.On("DeleteBatch", mock.Anything, batchID).Return(nil)
+	or.mdi.On("GetPins", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	err := or.pruneBatch(or.ctx, batchID, &prunedCounts{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPruneBatchDeletePinFail(t *testing.T) {
+	or, _ := newTestRetention()
+	batchID := fftypes.NewUUID()
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, batchID).Return(&fftypes.BatchPersisted{}, nil)
+	or.mdi.On("DeleteBatch", mock.Anything, batchID).Return(nil)
+	or.mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*fftypes.Pin{{Sequence: 1}}, nil, nil)
+	or.mdi.On("DeletePin", mock.Anything, int64(1)).Return(fmt.Errorf("pop"))
+	err := or.pruneBatch(or.ctx, batchID, &prunedCounts{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPruneTransactionGetBlockchainEventsFail(t *testing.T) {
+	or, _ := newTestRetention()
+	or.mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{}, nil, nil)
+	or.mdi.On("GetBlockchainEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	err := or.pruneTransaction(or.ctx, "ns1", fftypes.NewUUID(), &prunedCounts{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPruneTransactionGetEventsFail(t *testing.T) {
+	or, _ := newTestRetention()
+	or.mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{}, nil, nil)
+	or.mdi.On("GetBlockchainEvents", mock.Anything, mock.Anything).Return([]*fftypes.BlockchainEvent{{ID: fftypes.NewUUID()}}, nil, nil)
+	or.mdi.On("GetEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	err := or.pruneTransaction(or.ctx, "ns1", fftypes.NewUUID(), &prunedCounts{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPruneTransactionDeleteEventFail(t *testing.T) {
+	or, _ := newTestRetention()
+	or.mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{}, nil, nil)
+	or.mdi.On("GetBlockchainEvents", mock.Anything, mock.Anything).Return([]*fftypes.BlockchainEvent{{ID: fftypes.NewUUID()}}, nil, nil)
+	or.mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{{ID: fftypes.NewUUID()}}, nil, nil)
+	or.mdi.On("DeleteEvent", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	err := or.pruneTransaction(or.ctx, "ns1", fftypes.NewUUID(), &prunedCounts{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPruneTransactionDeleteBlockchainEventFail(t *testing.T) {
+	or, _ := newTestRetention()
+	or.mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{}, nil, nil)
+	or.mdi.On("GetBlockchainEvents", mock.Anything, mock.Anything).Return([]*fftypes.BlockchainEvent{{ID: fftypes.NewUUID()}}, nil, nil)
+	or.mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{}, nil, nil)
+	or.mdi.On("DeleteBlockchainEvent", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	err := or.pruneTransaction(or.ctx, "ns1", fftypes.NewUUID(), &prunedCounts{})
+	assert.EqualError(t, err, "pop")
+}
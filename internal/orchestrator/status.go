@@ -19,8 +19,11 @@ package orchestrator
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
@@ -29,7 +32,7 @@ func (or *orchestrator) GetNodeUUID(ctx context.Context) (node *fftypes.UUID) {
 	if or.node != nil {
 		return or.node
 	}
-	status, err := or.GetStatus(ctx)
+	status, err := or.getNodeStatus(ctx)
 	if err != nil {
 		log.L(or.ctx).Warnf("Failed to query local node UUID: %s", err)
 		return nil
@@ -43,6 +46,84 @@ func (or *orchestrator) GetNodeUUID(ctx context.Context) (node *fftypes.UUID) {
 }
 
 func (or *orchestrator) GetStatus(ctx context.Context) (status *fftypes.NodeStatus, err error) {
+	status, err = or.getNodeStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status.Plugins = or.getPluginStatus(ctx)
+	return status, nil
+}
+
+// GetReadiness returns an error unless the orchestrator has started, and every plugin reports it is healthy
+func (or *orchestrator) GetReadiness(ctx context.Context) (*fftypes.NodeHealth, error) {
+	if !or.isStarted() {
+		return nil, i18n.NewError(ctx, i18n.MsgNodeNotReady, "not started")
+	}
+	health := &fftypes.NodeHealth{
+		Healthy: true,
+		Plugins: or.getPluginStatus(ctx),
+	}
+	var unhealthy []string
+	for _, ps := range health.Plugins {
+		if !ps.Healthy {
+			unhealthy = append(unhealthy, fmt.Sprintf("%s '%s': %s", ps.Type, ps.Name, ps.Error))
+		}
+	}
+	if len(unhealthy) > 0 {
+		return nil, i18n.NewError(ctx, i18n.MsgNodeNotReady, strings.Join(unhealthy, "; "))
+	}
+	return health, nil
+}
+
+func (or *orchestrator) getPluginStatus(ctx context.Context) []*fftypes.NodeStatusPlugin {
+	plugins := make([]*fftypes.NodeStatusPlugin, 0)
+	check := func(pluginType, name string, healthCheck func(ctx context.Context) error) {
+		ps := &fftypes.NodeStatusPlugin{
+			Type:    pluginType,
+			Name:    name,
+			Healthy: true,
+		}
+		if err := healthCheck(ctx); err != nil {
+			log.L(ctx).Warnf("Health check failed for %s plugin '%s': %s", pluginType, name, err)
+			ps.Healthy = false
+			ps.Error = err.Error()
+		}
+		plugins = append(plugins, ps)
+	}
+
+	// Plugins are nil in pre-init mode
+	if or.database != nil {
+		check("database", or.database.Name(), or.database.HealthCheck)
+	}
+	if or.blockchains != nil {
+		bis := or.blockchains.Plugins()
+		names := make([]string, 0, len(bis))
+		for name := range bis {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			check("blockchain", name, bis[name].HealthCheck)
+		}
+	}
+	if or.dataexchange != nil {
+		check("dataexchange", or.dataexchange.Name(), or.dataexchange.HealthCheck)
+	}
+	if or.sharedstorage != nil {
+		check("sharedstorage", or.sharedstorage.Name(), or.sharedstorage.HealthCheck)
+	}
+	names := make([]string, 0, len(or.tokens))
+	for name := range or.tokens {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		check("tokens", name, or.tokens[name].HealthCheck)
+	}
+	return plugins
+}
+
+func (or *orchestrator) getNodeStatus(ctx context.Context) (status *fftypes.NodeStatus, err error) {
 
 	org, err := or.identity.GetNodeOwnerOrg(ctx)
 	if err != nil {
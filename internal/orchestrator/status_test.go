@@ -24,10 +24,20 @@ import (
 	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
+func mockPluginsHealthy(or *testOrchestrator) {
+	or.mdi.On("HealthCheck", mock.Anything).Return(nil)
+	or.mbi.On("HealthCheck", mock.Anything).Return(nil)
+	or.mdx.On("HealthCheck", mock.Anything).Return(nil)
+	or.mps.On("HealthCheck", mock.Anything).Return(nil)
+	or.mti.On("HealthCheck", mock.Anything).Return(nil)
+}
+
 func TestGetStatusRegistered(t *testing.T) {
 	or := newTestOrchestrator()
+	mockPluginsHealthy(or)
 
 	config.Reset()
 	config.Set(config.NamespacesDefault, "default")
@@ -67,6 +77,17 @@ func TestGetStatusRegistered(t *testing.T) {
 	assert.True(t, status.Node.Registered)
 	assert.Equal(t, *nodeID, *status.Node.ID)
 
+	assert.Len(t, status.Plugins, 5)
+	assert.Equal(t, "database", status.Plugins[0].Type)
+	assert.Equal(t, "mock-di", status.Plugins[0].Name)
+	assert.True(t, status.Plugins[0].Healthy)
+	assert.Equal(t, "blockchain", status.Plugins[1].Type)
+	assert.Equal(t, "mock-bi", status.Plugins[1].Name)
+	assert.Equal(t, "dataexchange", status.Plugins[2].Type)
+	assert.Equal(t, "sharedstorage", status.Plugins[3].Type)
+	assert.Equal(t, "tokens", status.Plugins[4].Type)
+	assert.Equal(t, "token", status.Plugins[4].Name)
+
 	assert.True(t, or.GetNodeUUID(or.ctx).Equals(nodeID))
 	assert.True(t, or.GetNodeUUID(or.ctx).Equals(nodeID)) // cached
 
@@ -74,6 +95,7 @@ func TestGetStatusRegistered(t *testing.T) {
 
 func TestGetStatusWrongNodeOwner(t *testing.T) {
 	or := newTestOrchestrator()
+	mockPluginsHealthy(or)
 
 	config.Reset()
 	config.Set(config.NamespacesDefault, "default")
@@ -117,6 +139,7 @@ func TestGetStatusWrongNodeOwner(t *testing.T) {
 
 func TestGetStatusUnregistered(t *testing.T) {
 	or := newTestOrchestrator()
+	mockPluginsHealthy(or)
 
 	config.Reset()
 	config.Set(config.NamespacesDefault, "default")
@@ -143,6 +166,7 @@ func TestGetStatusUnregistered(t *testing.T) {
 
 func TestGetStatusOrgOnlyRegistered(t *testing.T) {
 	or := newTestOrchestrator()
+	mockPluginsHealthy(or)
 
 	config.Reset()
 	config.Set(config.NamespacesDefault, "default")
@@ -203,3 +227,69 @@ func TestGetStatusNodeError(t *testing.T) {
 	assert.Nil(t, or.GetNodeUUID(or.ctx))
 
 }
+
+func TestGetStatusPluginUnhealthy(t *testing.T) {
+	or := newTestOrchestrator()
+
+	mim := or.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", or.ctx).Return(nil, fmt.Errorf("pop"))
+	or.mdi.On("HealthCheck", mock.Anything).Return(nil)
+	or.mbi.On("HealthCheck", mock.Anything).Return(fmt.Errorf("not connected"))
+	or.mdx.On("HealthCheck", mock.Anything).Return(nil)
+	or.mps.On("HealthCheck", mock.Anything).Return(nil)
+	or.mti.On("HealthCheck", mock.Anything).Return(nil)
+
+	status, err := or.GetStatus(or.ctx)
+	assert.NoError(t, err)
+	assert.Len(t, status.Plugins, 5)
+	assert.True(t, status.Plugins[0].Healthy)
+	assert.False(t, status.Plugins[1].Healthy)
+	assert.Equal(t, "not connected", status.Plugins[1].Error)
+}
+
+func TestGetStatusPreInit(t *testing.T) {
+	or := newTestOrchestrator()
+	or.database = nil
+	or.blockchains = nil
+	or.dataexchange = nil
+	or.sharedstorage = nil
+	or.tokens = nil
+
+	mim := or.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", or.ctx).Return(nil, fmt.Errorf("pop"))
+
+	status, err := or.GetStatus(or.ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, status.Plugins)
+}
+
+func TestGetReadiness(t *testing.T) {
+	or := newTestOrchestrator()
+	or.started = true
+	mockPluginsHealthy(or)
+
+	health, err := or.GetReadiness(or.ctx)
+	assert.NoError(t, err)
+	assert.True(t, health.Healthy)
+	assert.Len(t, health.Plugins, 5)
+}
+
+func TestGetReadinessNotStarted(t *testing.T) {
+	or := newTestOrchestrator()
+
+	_, err := or.GetReadiness(or.ctx)
+	assert.Regexp(t, "FF10422.*not started", err)
+}
+
+func TestGetReadinessPluginUnhealthy(t *testing.T) {
+	or := newTestOrchestrator()
+	or.started = true
+	or.mdi.On("HealthCheck", mock.Anything).Return(nil)
+	or.mbi.On("HealthCheck", mock.Anything).Return(nil)
+	or.mdx.On("HealthCheck", mock.Anything).Return(fmt.Errorf("pop"))
+	or.mps.On("HealthCheck", mock.Anything).Return(nil)
+	or.mti.On("HealthCheck", mock.Anything).Return(nil)
+
+	_, err := or.GetReadiness(or.ctx)
+	assert.Regexp(t, "FF10422.*dataexchange 'mock-dx': pop", err)
+}
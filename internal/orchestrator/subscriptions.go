@@ -48,7 +48,10 @@ func (or *orchestrator) createUpdateSubscription(ctx context.Context, ns string,
 		return nil, i18n.NewError(ctx, i18n.MsgSystemTransportInternal)
 	}
 
-	return subDef, or.events.CreateUpdateDurableSubscription(ctx, subDef, mustNew)
+	if err := or.events.CreateUpdateDurableSubscription(ctx, subDef, mustNew); err != nil {
+		return nil, err
+	}
+	return subDef.Redacted(), nil
 }
 
 func (or *orchestrator) DeleteSubscription(ctx context.Context, ns, id string) error {
@@ -68,7 +71,14 @@ func (or *orchestrator) DeleteSubscription(ctx context.Context, ns, id string) e
 
 func (or *orchestrator) GetSubscriptions(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Subscription, *database.FilterResult, error) {
 	filter = or.scopeNS(ns, filter)
-	return or.database.GetSubscriptions(ctx, filter)
+	subs, fr, err := or.database.GetSubscriptions(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, sub := range subs {
+		subs[i] = sub.Redacted()
+	}
+	return subs, fr, nil
 }
 
 func (or *orchestrator) GetSubscriptionByID(ctx context.Context, ns, id string) (*fftypes.Subscription, error) {
@@ -76,5 +86,85 @@ func (or *orchestrator) GetSubscriptionByID(ctx context.Context, ns, id string)
 	if err != nil {
 		return nil, err
 	}
-	return or.database.GetSubscriptionByID(ctx, u)
+	sub, err := or.database.GetSubscriptionByID(ctx, u)
+	if err != nil || sub == nil {
+		return nil, err
+	}
+	return sub.Redacted(), nil
+}
+
+func (or *orchestrator) getSubscriptionInNS(ctx context.Context, ns, subID string) (*fftypes.Subscription, error) {
+	u, err := or.verifyIDAndNamespace(ctx, ns, subID)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := or.database.GetSubscriptionByID(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil || sub.Namespace != ns {
+		return nil, i18n.NewError(ctx, i18n.Msg404NotFound)
+	}
+	return sub, nil
+}
+
+func (or *orchestrator) GetDeadLetters(ctx context.Context, ns, subID string, filter database.AndFilter) ([]*fftypes.DeadLetter, *database.FilterResult, error) {
+	sub, err := or.getSubscriptionInNS(ctx, ns, subID)
+	if err != nil {
+		return nil, nil, err
+	}
+	filter = or.scopeNS(ns, filter)
+	filter = filter.Condition(filter.Builder().Eq("subscription", sub.ID))
+	return or.database.GetDeadLetters(ctx, filter)
+}
+
+func (or *orchestrator) GetDeadLetterByID(ctx context.Context, ns, subID, id string) (*fftypes.DeadLetter, error) {
+	sub, err := or.getSubscriptionInNS(ctx, ns, subID)
+	if err != nil {
+		return nil, err
+	}
+	u, err := fftypes.ParseUUID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	deadLetter, err := or.database.GetDeadLetterByID(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	if deadLetter == nil || !deadLetter.Subscription.ID.Equals(sub.ID) {
+		return nil, i18n.NewError(ctx, i18n.Msg404NotFound)
+	}
+	return deadLetter, nil
+}
+
+func (or *orchestrator) ReplayDeadLetter(ctx context.Context, ns, subID, id string) (*fftypes.DeadLetter, error) {
+	deadLetter, err := or.GetDeadLetterByID(ctx, ns, subID, id)
+	if err != nil {
+		return nil, err
+	}
+	return deadLetter, or.events.ReplayDeadLetter(ctx, deadLetter)
+}
+
+func (or *orchestrator) PurgeDeadLetters(ctx context.Context, ns, subID string) error {
+	sub, err := or.getSubscriptionInNS(ctx, ns, subID)
+	if err != nil {
+		return err
+	}
+	return or.database.DeleteDeadLetters(ctx, sub.ID)
+}
+
+func (or *orchestrator) ResetSubscriptionOffset(ctx context.Context, ns, subID string, reset *fftypes.SubscriptionOffsetReset) (*fftypes.Offset, error) {
+	sub, err := or.getSubscriptionInNS(ctx, ns, subID)
+	if err != nil {
+		return nil, err
+	}
+	return or.events.ResetSubscriptionOffset(ctx, sub, reset)
+}
+
+func (or *orchestrator) GetSubscriptionStatus(ctx context.Context, ns, subID string) (*fftypes.SubscriptionStatus, error) {
+	sub, err := or.getSubscriptionInNS(ctx, ns, subID)
+	if err != nil {
+		return nil, err
+	}
+	return or.events.GetSubscriptionStatus(ctx, sub)
 }
@@ -91,6 +91,34 @@ func TestCreateUpdateSubscriptionOk(t *testing.T) {
 	assert.Equal(t, s1, sub)
 	assert.Equal(t, "ns1", sub.Namespace)
 }
+
+func TestCreateSubscriptionRedacted(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{
+			Name: "sub1",
+		},
+	}
+	sub.Options.TransportOptions()["signing"] = fftypes.JSONObject{"secrets": []interface{}{"secret1"}}
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mem.On("CreateUpdateDurableSubscription", mock.Anything, mock.Anything, true).Return(nil)
+	s1, err := or.CreateSubscription(or.ctx, "ns1", sub)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{fftypes.RedactedSecret}, s1.Options.TransportOptions().GetObject("signing")["secrets"])
+}
+
+func TestCreateSubscriptionFail(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{
+			Name: "sub1",
+		},
+	}
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mem.On("CreateUpdateDurableSubscription", mock.Anything, mock.Anything, true).Return(fmt.Errorf("pop"))
+	_, err := or.CreateSubscription(or.ctx, "ns1", sub)
+	assert.EqualError(t, err, "pop")
+}
 func TestDeleteSubscriptionBadUUID(t *testing.T) {
 	or := newTestOrchestrator()
 	or.mdi.On("GetSubscriptionByID", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
@@ -137,11 +165,33 @@ func TestDeleteSubscription(t *testing.T) {
 func TestGetSubscriptions(t *testing.T) {
 	or := newTestOrchestrator()
 	u := fftypes.NewUUID()
-	or.mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{}, nil, nil)
+	sub := &fftypes.Subscription{}
+	sub.Options.TransportOptions()["signing"] = fftypes.JSONObject{"secrets": []interface{}{"secret1"}}
+	or.mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{sub}, nil, nil)
 	fb := database.SubscriptionQueryFactory.NewFilter(context.Background())
 	f := fb.And(fb.Eq("id", u))
-	_, _, err := or.GetSubscriptions(context.Background(), "ns1", f)
+	subs, _, err := or.GetSubscriptions(context.Background(), "ns1", f)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{fftypes.RedactedSecret}, subs[0].Options.TransportOptions().GetObject("signing")["secrets"])
+}
+
+func TestGetSubscriptionsFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	fb := database.SubscriptionQueryFactory.NewFilter(context.Background())
+	_, _, err := or.GetSubscriptions(context.Background(), "ns1", fb.And())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetSubscriptionByIDRedacted(t *testing.T) {
+	or := newTestOrchestrator()
+	u := fftypes.NewUUID()
+	sub := &fftypes.Subscription{}
+	sub.Options.TransportOptions()["signing"] = fftypes.JSONObject{"secrets": []interface{}{"secret1"}}
+	or.mdi.On("GetSubscriptionByID", mock.Anything, u).Return(sub, nil)
+	s1, err := or.GetSubscriptionByID(context.Background(), "ns1", u.String())
 	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{fftypes.RedactedSecret}, s1.Options.TransportOptions().GetObject("signing")["secrets"])
 }
 
 func TestGetSGetSubscriptionsByID(t *testing.T) {
@@ -157,3 +207,166 @@ func TestGetSubscriptionDefsByIDBadID(t *testing.T) {
 	_, err := or.GetSubscriptionByID(context.Background(), "", "")
 	assert.Regexp(t, "FF10142", err)
 }
+
+func newTestDeadLetterSub() *fftypes.Subscription {
+	return &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Name:      "sub1",
+			Namespace: "ns1",
+		},
+	}
+}
+
+func TestGetDeadLetters(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := newTestDeadLetterSub()
+	or.mdi.On("GetSubscriptionByID", mock.Anything, sub.ID).Return(sub, nil)
+	or.mdi.On("GetDeadLetters", mock.Anything, mock.Anything).Return([]*fftypes.DeadLetter{}, nil, nil)
+	fb := database.DeadLetterQueryFactory.NewFilter(context.Background())
+	f := fb.And()
+	_, _, err := or.GetDeadLetters(context.Background(), "ns1", sub.ID.String(), f)
+	assert.NoError(t, err)
+}
+
+func TestGetDeadLettersBadSubID(t *testing.T) {
+	or := newTestOrchestrator()
+	fb := database.DeadLetterQueryFactory.NewFilter(context.Background())
+	_, _, err := or.GetDeadLetters(context.Background(), "ns1", "!bad", fb.And())
+	assert.Regexp(t, "FF10142", err)
+}
+
+func TestGetDeadLettersSubLookupFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetSubscriptionByID", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+	fb := database.DeadLetterQueryFactory.NewFilter(context.Background())
+	_, _, err := or.GetDeadLetters(context.Background(), "ns1", fftypes.NewUUID().String(), fb.And())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetDeadLettersSubNSMismatch(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := newTestDeadLetterSub()
+	or.mdi.On("GetSubscriptionByID", mock.Anything, sub.ID).Return(sub, nil)
+	fb := database.DeadLetterQueryFactory.NewFilter(context.Background())
+	_, _, err := or.GetDeadLetters(context.Background(), "ns2", sub.ID.String(), fb.And())
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestGetDeadLetterByID(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := newTestDeadLetterSub()
+	dl := &fftypes.DeadLetter{ID: fftypes.NewUUID(), Subscription: sub.SubscriptionRef}
+	or.mdi.On("GetSubscriptionByID", mock.Anything, sub.ID).Return(sub, nil)
+	or.mdi.On("GetDeadLetterByID", mock.Anything, dl.ID).Return(dl, nil)
+	res, err := or.GetDeadLetterByID(context.Background(), "ns1", sub.ID.String(), dl.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, dl, res)
+}
+
+func TestGetDeadLetterByIDBadID(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := newTestDeadLetterSub()
+	or.mdi.On("GetSubscriptionByID", mock.Anything, sub.ID).Return(sub, nil)
+	_, err := or.GetDeadLetterByID(context.Background(), "ns1", sub.ID.String(), "!bad")
+	assert.Regexp(t, "FF10142", err)
+}
+
+func TestGetDeadLetterByIDSubNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetSubscriptionByID", mock.Anything, mock.Anything).Return(nil, nil)
+	_, err := or.GetDeadLetterByID(context.Background(), "ns1", fftypes.NewUUID().String(), fftypes.NewUUID().String())
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestGetDeadLetterByIDFail(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := newTestDeadLetterSub()
+	or.mdi.On("GetSubscriptionByID", mock.Anything, sub.ID).Return(sub, nil)
+	or.mdi.On("GetDeadLetterByID", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+	_, err := or.GetDeadLetterByID(context.Background(), "ns1", sub.ID.String(), fftypes.NewUUID().String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetDeadLetterByIDOtherSubscription(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := newTestDeadLetterSub()
+	dl := &fftypes.DeadLetter{ID: fftypes.NewUUID(), Subscription: fftypes.SubscriptionRef{ID: fftypes.NewUUID()}}
+	or.mdi.On("GetSubscriptionByID", mock.Anything, sub.ID).Return(sub, nil)
+	or.mdi.On("GetDeadLetterByID", mock.Anything, dl.ID).Return(dl, nil)
+	_, err := or.GetDeadLetterByID(context.Background(), "ns1", sub.ID.String(), dl.ID.String())
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestReplayDeadLetter(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := newTestDeadLetterSub()
+	dl := &fftypes.DeadLetter{ID: fftypes.NewUUID(), Subscription: sub.SubscriptionRef}
+	or.mdi.On("GetSubscriptionByID", mock.Anything, sub.ID).Return(sub, nil)
+	or.mdi.On("GetDeadLetterByID", mock.Anything, dl.ID).Return(dl, nil)
+	or.mem.On("ReplayDeadLetter", mock.Anything, dl).Return(nil)
+	res, err := or.ReplayDeadLetter(context.Background(), "ns1", sub.ID.String(), dl.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, dl, res)
+}
+
+func TestReplayDeadLetterNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := newTestDeadLetterSub()
+	or.mdi.On("GetSubscriptionByID", mock.Anything, sub.ID).Return(sub, nil)
+	or.mdi.On("GetDeadLetterByID", mock.Anything, mock.Anything).Return(nil, nil)
+	_, err := or.ReplayDeadLetter(context.Background(), "ns1", sub.ID.String(), fftypes.NewUUID().String())
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestPurgeDeadLetters(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := newTestDeadLetterSub()
+	or.mdi.On("GetSubscriptionByID", mock.Anything, sub.ID).Return(sub, nil)
+	or.mdi.On("DeleteDeadLetters", mock.Anything, sub.ID).Return(nil)
+	err := or.PurgeDeadLetters(context.Background(), "ns1", sub.ID.String())
+	assert.NoError(t, err)
+}
+
+func TestPurgeDeadLettersSubNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetSubscriptionByID", mock.Anything, mock.Anything).Return(nil, nil)
+	err := or.PurgeDeadLetters(context.Background(), "ns1", fftypes.NewUUID().String())
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestResetSubscriptionOffset(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := newTestDeadLetterSub()
+	newest := fftypes.SubOptsFirstEventNewest
+	reset := &fftypes.SubscriptionOffsetReset{FirstEvent: &newest}
+	or.mdi.On("GetSubscriptionByID", mock.Anything, sub.ID).Return(sub, nil)
+	or.mem.On("ResetSubscriptionOffset", mock.Anything, sub, reset).Return(&fftypes.Offset{Current: 12345}, nil)
+	offset, err := or.ResetSubscriptionOffset(context.Background(), "ns1", sub.ID.String(), reset)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12345), offset.Current)
+}
+
+func TestResetSubscriptionOffsetSubNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetSubscriptionByID", mock.Anything, mock.Anything).Return(nil, nil)
+	_, err := or.ResetSubscriptionOffset(context.Background(), "ns1", fftypes.NewUUID().String(), &fftypes.SubscriptionOffsetReset{})
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestGetSubscriptionStatus(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := newTestDeadLetterSub()
+	or.mdi.On("GetSubscriptionByID", mock.Anything, sub.ID).Return(sub, nil)
+	or.mem.On("GetSubscriptionStatus", mock.Anything, sub).Return(&fftypes.SubscriptionStatus{Lag: 10}, nil)
+	status, err := or.GetSubscriptionStatus(context.Background(), "ns1", sub.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), status.Lag)
+}
+
+func TestGetSubscriptionStatusSubNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetSubscriptionByID", mock.Anything, mock.Anything).Return(nil, nil)
+	_, err := or.GetSubscriptionStatus(context.Background(), "ns1", fftypes.NewUUID().String())
+	assert.Regexp(t, "FF10109", err)
+}
@@ -188,7 +188,7 @@ func (or *orchestrator) GetTransactionStatus(ctx context.Context, ns, id string)
 			})
 		}
 
-	case fftypes.TransactionTypeContractInvoke:
+	case fftypes.TransactionTypeContractInvoke, fftypes.TransactionTypeContractDeploy:
 		// no blockchain events or other objects
 
 	default:
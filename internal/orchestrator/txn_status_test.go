@@ -760,6 +760,50 @@ func TestGetTransactionStatusContractInvokeSuccess(t *testing.T) {
 	or.mdi.AssertExpectations(t)
 }
 
+func TestGetTransactionStatusContractDeploySuccess(t *testing.T) {
+	or := newTestOrchestrator()
+
+	txID := fftypes.NewUUID()
+	tx := &fftypes.Transaction{
+		Type: fftypes.TransactionTypeContractDeploy,
+	}
+	ops := []*fftypes.Operation{
+		{
+			Status:  fftypes.OpStatusSucceeded,
+			ID:      fftypes.NewUUID(),
+			Type:    fftypes.OpTypeBlockchainContractDeploy,
+			Updated: fftypes.UnixTime(0),
+			Output:  fftypes.JSONObject{"location": fftypes.JSONObject{"address": "0x12345"}},
+		},
+	}
+	events := []*fftypes.BlockchainEvent{}
+
+	or.mdi.On("GetTransactionByID", mock.Anything, txID).Return(tx, nil)
+	or.mdi.On("GetOperations", mock.Anything, mock.Anything).Return(ops, nil, nil)
+	or.mdi.On("GetBlockchainEvents", mock.Anything, mock.Anything).Return(events, nil, nil)
+
+	status, err := or.GetTransactionStatus(context.Background(), "ns1", txID.String())
+	assert.NoError(t, err)
+
+	expectedStatus := compactJSON(`{
+		"status": "Succeeded",
+		"details": [
+			{
+				"type": "Operation",
+				"subtype": "blockchain_deploy",
+				"status": "Succeeded",
+				"timestamp": "1970-01-01T00:00:00Z",
+				"id": "` + ops[0].ID.String() + `",
+				"info": {"location": {"address": "0x12345"}}
+			}
+		]
+	}`)
+	statusJSON, _ := json.Marshal(status)
+	assert.Equal(t, expectedStatus, string(statusJSON))
+
+	or.mdi.AssertExpectations(t)
+}
+
 func TestGetTransactionStatusTXError(t *testing.T) {
 	or := newTestOrchestrator()
 
@@ -23,6 +23,7 @@ import (
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/sysmessaging"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
 
@@ -166,8 +167,12 @@ func (s *messageSender) sendInternal(ctx context.Context, method sendMethod) err
 		return nil
 	}
 
-	// Store the message - this asynchronously triggers the next step in process
-	if err := s.mgr.data.WriteNewMessage(ctx, s.msg); err != nil {
+	// Store the message - this asynchronously triggers the next step in process, so we record the span to link to
+	ctx, span := tracing.StartSpan(ctx, "private_message", nil, tracing.Namespace(msg.Header.Namespace), tracing.MessageID(msg.Header.ID))
+	tracing.Record(ctx, msg.Header.ID)
+	err := s.mgr.data.WriteNewMessage(ctx, s.msg)
+	tracing.EndSpan(span, err)
+	if err != nil {
 		return err
 	}
 	log.L(ctx).Infof("Sent private message %s:%s sequence=%d", msg.Header.Namespace, msg.Header.ID, msg.Sequence)
@@ -30,6 +30,7 @@ import (
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/sirupsen/logrus"
 )
@@ -123,6 +124,8 @@ func New(ctx context.Context, staticConfig config.Prefix) *resty.Client {
 			rctx = log.WithLogger(rctx, l)
 			req.SetContext(rctx)
 		}
+		// Propagate the W3C trace context of the calling span to the connector
+		tracing.InjectHTTP(rctx, req.Header)
 		log.L(rctx).Debugf("==> %s %s%s", req.Method, url, req.URL)
 		return nil
 	})
@@ -72,6 +72,16 @@ func (i *IPFS) Capabilities() *sharedstorage.Capabilities {
 	return i.capabilities
 }
 
+func (i *IPFS) HealthCheck(ctx context.Context) error {
+	res, err := i.apiClient.R().
+		SetContext(ctx).
+		Post("/api/v0/version")
+	if err != nil || !res.IsSuccess() {
+		return restclient.WrapRestErr(ctx, res, err, i18n.MsgIPFSRESTErr)
+	}
+	return nil
+}
+
 func (i *IPFS) PublishData(ctx context.Context, data io.Reader) (string, error) {
 	var ipfsResponse ipfsUploadResponse
 	res, err := i.apiClient.R().
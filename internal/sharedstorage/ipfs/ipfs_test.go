@@ -70,6 +70,52 @@ func TestInit(t *testing.T) {
 	assert.NotNil(t, i.Capabilities())
 }
 
+func TestHealthCheck(t *testing.T) {
+	i := &IPFS{}
+
+	mockedClient := &http.Client{}
+	httpmock.ActivateNonDefault(mockedClient)
+	defer httpmock.DeactivateAndReset()
+
+	resetConf()
+	utConfPrefix.SubPrefix(IPFSConfAPISubconf).Set(restclient.HTTPConfigURL, "http://localhost:12345")
+	utConfPrefix.SubPrefix(IPFSConfGatewaySubconf).Set(restclient.HTTPConfigURL, "http://localhost:12345")
+	utConfPrefix.SubPrefix(IPFSConfAPISubconf).Set(restclient.HTTPCustomClient, mockedClient)
+
+	err := i.Init(context.Background(), utConfPrefix, &sharedstoragemocks.Callbacks{})
+	assert.NoError(t, err)
+
+	httpmock.RegisterResponder("POST", "http://localhost:12345/api/v0/version",
+		httpmock.NewJsonResponderOrPanic(200, map[string]interface{}{
+			"Version": "0.12.0",
+		}))
+
+	err = i.HealthCheck(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestHealthCheckFail(t *testing.T) {
+	i := &IPFS{}
+
+	mockedClient := &http.Client{}
+	httpmock.ActivateNonDefault(mockedClient)
+	defer httpmock.DeactivateAndReset()
+
+	resetConf()
+	utConfPrefix.SubPrefix(IPFSConfAPISubconf).Set(restclient.HTTPConfigURL, "http://localhost:12345")
+	utConfPrefix.SubPrefix(IPFSConfGatewaySubconf).Set(restclient.HTTPConfigURL, "http://localhost:12345")
+	utConfPrefix.SubPrefix(IPFSConfAPISubconf).Set(restclient.HTTPCustomClient, mockedClient)
+
+	err := i.Init(context.Background(), utConfPrefix, &sharedstoragemocks.Callbacks{})
+	assert.NoError(t, err)
+
+	httpmock.RegisterResponder("POST", "http://localhost:12345/api/v0/version",
+		httpmock.NewJsonResponderOrPanic(500, map[string]interface{}{"error": "pop"}))
+
+	err = i.HealthCheck(context.Background())
+	assert.Regexp(t, "FF10136", err)
+}
+
 func TestIPFSUploadSuccess(t *testing.T) {
 	i := &IPFS{}
 
@@ -22,6 +22,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/data"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
@@ -36,6 +37,10 @@ import (
 type Bridge interface {
 	// Init is required as there's a bi-directional relationship between sysmessaging and syncasync bridge
 	Init(sysevents sysmessaging.SystemEvents)
+	// Start listens for events on all namespaces, when coordinating requests with other replicas through the database
+	Start() error
+	// WaitStop waits for the cleanup of expired requests in the database to stop, once the context is cancelled
+	WaitStop()
 
 	// The following "WaitFor*" methods all wait for a particular type of event callback, and block until it is received.
 	// To use them, invoke the appropriate method, and pass a "send" callback that is expected to trigger the relevant event.
@@ -52,26 +57,42 @@ type Bridge interface {
 	WaitForTokenTransfer(ctx context.Context, ns string, id *fftypes.UUID, send RequestSender) (*fftypes.TokenTransfer, error)
 	// WaitForTokenTransfer waits for a token approval with the supplied ID
 	WaitForTokenApproval(ctx context.Context, ns string, id *fftypes.UUID, send RequestSender) (*fftypes.TokenApproval, error)
+
+	// InflightRequestUpdated notifies the bridge that an inflight request was resolved in the database, possibly by another replica
+	InflightRequestUpdated(ns string, id *fftypes.UUID)
 }
 
 type RequestSender func(ctx context.Context) error
 
-type requestType int
-
-const (
-	messageConfirm requestType = iota
-	messageReply
-	identityConfirm
-	tokenPoolConfirm
-	tokenTransferConfirm
-	tokenApproveConfirm
+var (
+	messageConfirm       = fftypes.InflightRequestTypeMessageConfirm
+	messageReply         = fftypes.InflightRequestTypeMessageReply
+	identityConfirm      = fftypes.InflightRequestTypeIdentityConfirm
+	tokenPoolConfirm     = fftypes.InflightRequestTypeTokenPoolConfirm
+	tokenTransferConfirm = fftypes.InflightRequestTypeTokenTransferConfirm
+	tokenApproveConfirm  = fftypes.InflightRequestTypeTokenApprovalConfirm
 )
 
+// inflightEventTypes are the types of event that can resolve an inflight request
+var inflightEventTypes = map[fftypes.EventType]bool{
+	fftypes.EventTypeMessageConfirmed:  true,
+	fftypes.EventTypeMessageRejected:   true,
+	fftypes.EventTypeIdentityConfirmed: true,
+	fftypes.EventTypePoolConfirmed:     true,
+	fftypes.EventTypeTransferConfirmed: true,
+	fftypes.EventTypeApprovalConfirmed: true,
+	fftypes.EventTypeTransferOpFailed:  true,
+	fftypes.EventTypeApprovalOpFailed:  true,
+}
+
 type inflightRequest struct {
 	id        *fftypes.UUID
+	namespace string
 	startTime time.Time
 	response  chan inflightResponse
-	reqType   requestType
+	updated   chan bool
+	reqType   fftypes.InflightRequestType
+	remote    bool
 }
 
 type inflightResponse struct {
@@ -83,20 +104,29 @@ type inflightResponse struct {
 type inflightRequestMap map[string]map[fftypes.UUID]*inflightRequest
 
 type syncAsyncBridge struct {
-	ctx         context.Context
-	database    database.Plugin
-	data        data.Manager
-	sysevents   sysmessaging.SystemEvents
-	inflightMux sync.Mutex
-	inflight    inflightRequestMap
+	ctx             context.Context
+	database        database.Plugin
+	data            data.Manager
+	sysevents       sysmessaging.SystemEvents
+	inflightMux     sync.Mutex
+	inflight        inflightRequestMap
+	distributed     bool
+	pollInterval    time.Duration
+	requestExpiry   time.Duration
+	cleanupInterval time.Duration
+	cleanupDone     chan struct{}
 }
 
 func NewSyncAsyncBridge(ctx context.Context, di database.Plugin, dm data.Manager) Bridge {
 	sa := &syncAsyncBridge{
-		ctx:      log.WithLogField(ctx, "role", "sync-async-bridge"),
-		database: di,
-		data:     dm,
-		inflight: make(inflightRequestMap),
+		ctx:             log.WithLogField(ctx, "role", "sync-async-bridge"),
+		database:        di,
+		data:            dm,
+		inflight:        make(inflightRequestMap),
+		distributed:     config.GetBool(config.SyncAsyncDistributedEnabled),
+		pollInterval:    config.GetDuration(config.SyncAsyncDistributedPollInterval),
+		requestExpiry:   config.GetDuration(config.SyncAsyncDistributedRequestExpiry),
+		cleanupInterval: config.GetDuration(config.SyncAsyncDistributedCleanupInterval),
 	}
 	return sa
 }
@@ -105,18 +135,86 @@ func (sa *syncAsyncBridge) Init(sysevents sysmessaging.SystemEvents) {
 	sa.sysevents = sysevents
 }
 
-func (sa *syncAsyncBridge) addInFlight(ns string, id *fftypes.UUID, reqType requestType) (*inflightRequest, error) {
-	inflight := &inflightRequest{
-		id:        id,
-		startTime: time.Now(),
-		response:  make(chan inflightResponse),
-		reqType:   reqType,
+func (sa *syncAsyncBridge) Start() error {
+	if !sa.distributed {
+		return nil
+	}
+	// The event that resolves a request waiting on another replica might be processed here,
+	// so we need to listen on every namespace - not just those with requests from this replica
+	if err := sa.addNamespaceListeners(); err != nil {
+		return err
+	}
+	sa.cleanupDone = make(chan struct{})
+	go sa.cleanupLoop()
+	return nil
+}
+
+func (sa *syncAsyncBridge) WaitStop() {
+	if sa.cleanupDone != nil {
+		<-sa.cleanupDone
+		sa.cleanupDone = nil
+	}
+}
+
+// cleanupLoop deletes the records of requests that have outlived the expiry. These are left behind in the
+// database when the replica that made a request stops before the request completes.
+func (sa *syncAsyncBridge) cleanupLoop() {
+	defer close(sa.cleanupDone)
+
+	for {
+		if err := sa.deleteExpiredRequests(); err != nil {
+			log.L(sa.ctx).Errorf("Failed to delete expired inflight requests: %s", err)
+		}
+		select {
+		case <-time.After(sa.cleanupInterval):
+		case <-sa.ctx.Done():
+			log.L(sa.ctx).Debugf("Inflight request cleanup exiting")
+			return
+		}
+	}
+}
+
+func (sa *syncAsyncBridge) deleteExpiredRequests() error {
+	expired := fftypes.FFTime(time.Now().Add(-sa.requestExpiry))
+	fb := database.InflightRequestQueryFactory.NewFilter(sa.ctx)
+	reqs, _, err := sa.database.GetInflightRequests(sa.ctx, fb.Lt("created", expired))
+	if err != nil {
+		return err
+	}
+	for _, req := range reqs {
+		log.L(sa.ctx).Infof("Deleting expired inflight request '%s' created at %s", req.ID, req.Created)
+		if err := sa.database.DeleteInflightRequest(sa.ctx, req.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addNamespaceListeners adds a listener to every namespace that does not already have one
+func (sa *syncAsyncBridge) addNamespaceListeners() error {
+	namespaces, _, err := sa.database.GetNamespaces(sa.ctx, database.NamespaceQueryFactory.NewFilter(sa.ctx).And())
+	if err != nil {
+		return err
 	}
 	sa.inflightMux.Lock()
-	defer func() {
-		sa.inflightMux.Unlock()
-	}()
+	defer sa.inflightMux.Unlock()
+	for _, ns := range namespaces {
+		if _, err := sa.addListenerLocked(ns.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isDefinitionConfirmed returns true for the confirmation of a definition, which might be the definition of
+// a new namespace. Namespaces are always defined in the system namespace.
+func isDefinitionConfirmed(event *fftypes.EventDelivery) bool {
+	return event.Type == fftypes.EventTypeMessageConfirmed &&
+		event.Namespace == fftypes.SystemNamespace &&
+		event.Topic == fftypes.SystemTopicDefinitions
+}
 
+func (sa *syncAsyncBridge) addListenerLocked(ns string) (map[fftypes.UUID]*inflightRequest, error) {
 	inflightNS := sa.inflight[ns]
 	if inflightNS == nil {
 		err := sa.sysevents.AddSystemEventListener(ns, sa.eventCallback)
@@ -126,33 +224,105 @@ func (sa *syncAsyncBridge) addInFlight(ns string, id *fftypes.UUID, reqType requ
 		inflightNS = make(map[fftypes.UUID]*inflightRequest)
 		sa.inflight[ns] = inflightNS
 	}
+	return inflightNS, nil
+}
+
+func (sa *syncAsyncBridge) addInFlight(ns string, id *fftypes.UUID, reqType fftypes.InflightRequestType) (*inflightRequest, error) {
+	inflight := &inflightRequest{
+		id:        id,
+		namespace: ns,
+		startTime: time.Now(),
+		response:  make(chan inflightResponse),
+		updated:   make(chan bool, 1),
+		reqType:   reqType,
+	}
+	sa.inflightMux.Lock()
+	inflightNS, err := sa.addListenerLocked(ns)
+	sa.inflightMux.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	if sa.distributed {
+		// Record the request in the database, so the event that resolves it can be processed by any replica.
+		// This is done outside the lock, so that event delivery is not held up by the database.
+		err := sa.database.InsertInflightRequest(sa.ctx, &fftypes.InflightRequest{
+			ID:        id,
+			Namespace: ns,
+			Type:      reqType,
+			Created:   fftypes.Now(),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sa.inflightMux.Lock()
 	inflightNS[*inflight.id] = inflight
+	sa.inflightMux.Unlock()
 	return inflight, nil
 }
 
-func (sa *syncAsyncBridge) getInFlight(ns string, reqType requestType, id *fftypes.UUID) *inflightRequest {
+// getInFlight looks for a request from this replica, then (if distributed) for one from another replica in the
+// database. The lock is only held for the local lookup, so event delivery does not hold it over database queries.
+func (sa *syncAsyncBridge) getInFlight(ns string, reqType fftypes.InflightRequestType, id *fftypes.UUID) (*inflightRequest, error) {
 	if id == nil {
-		return nil
+		return nil, nil
 	}
-	inflightNS := sa.inflight[ns]
-	if inflightNS != nil && id != nil {
-		inflight := inflightNS[*id]
-		if inflight != nil && inflight.reqType == reqType {
-			return inflight
+	sa.inflightMux.Lock()
+	inflight := sa.inflight[ns][*id]
+	sa.inflightMux.Unlock()
+	if inflight != nil {
+		if inflight.reqType == reqType {
+			return inflight, nil
 		}
+		return nil, nil
 	}
-	return nil
+	if sa.distributed {
+		return sa.getRemoteInFlight(ns, reqType, id)
+	}
+	return nil, nil
+}
+
+func (sa *syncAsyncBridge) getRemoteInFlight(ns string, reqType fftypes.InflightRequestType, id *fftypes.UUID) (*inflightRequest, error) {
+	req, err := sa.database.GetInflightRequestByID(sa.ctx, id)
+	if err != nil || req == nil || req.Namespace != ns || req.Type != reqType || req.Resolved != nil {
+		return nil, err
+	}
+	return &inflightRequest{
+		id:        req.ID,
+		namespace: req.Namespace,
+		startTime: *req.Created.Time(),
+		reqType:   req.Type,
+		remote:    true,
+	}, nil
 }
 
 func (sa *syncAsyncBridge) removeInFlight(ns string, id *fftypes.UUID) {
 	sa.inflightMux.Lock()
-	defer func() {
-		sa.inflightMux.Unlock()
-	}()
 	inflightNS := sa.inflight[ns]
 	if inflightNS != nil {
 		delete(inflightNS, *id)
 	}
+	sa.inflightMux.Unlock()
+
+	if sa.distributed {
+		if err := sa.database.DeleteInflightRequest(sa.ctx, id); err != nil {
+			log.L(sa.ctx).Warnf("Failed to delete inflight request '%s': %s", id, err)
+		}
+	}
+}
+
+func (sa *syncAsyncBridge) InflightRequestUpdated(ns string, id *fftypes.UUID) {
+	sa.inflightMux.Lock()
+	defer sa.inflightMux.Unlock()
+
+	if inflight := sa.inflight[ns][*id]; inflight != nil {
+		select {
+		case inflight.updated <- true:
+		default:
+		}
+	}
 }
 
 func (inflight *inflightRequest) msInflight() float64 {
@@ -244,8 +414,14 @@ func (sa *syncAsyncBridge) getOperationFromEvent(event *fftypes.EventDelivery) (
 func (sa *syncAsyncBridge) handleMessageConfirmedEvent(event *fftypes.EventDelivery) error {
 
 	// See if the CID marks this as a reply to an inflight message
-	inflight := sa.getInFlight(event.Namespace, messageConfirm, event.Reference)
-	inflightReply := sa.getInFlight(event.Namespace, messageReply, event.Correlator)
+	inflight, err := sa.getInFlight(event.Namespace, messageConfirm, event.Reference)
+	if err != nil {
+		return err
+	}
+	inflightReply, err := sa.getInFlight(event.Namespace, messageReply, event.Correlator)
+	if err != nil {
+		return err
+	}
 
 	if inflightReply == nil && inflight == nil {
 		return nil
@@ -269,8 +445,14 @@ func (sa *syncAsyncBridge) handleMessageConfirmedEvent(event *fftypes.EventDeliv
 func (sa *syncAsyncBridge) handleMessageRejectedEvent(event *fftypes.EventDelivery) error {
 
 	// See if this is a rejection of an inflight message
-	inflight := sa.getInFlight(event.Namespace, messageConfirm, event.Reference)
-	inflightPool := sa.getInFlight(event.Namespace, tokenPoolConfirm, event.Correlator)
+	inflight, err := sa.getInFlight(event.Namespace, messageConfirm, event.Reference)
+	if err != nil {
+		return err
+	}
+	inflightPool, err := sa.getInFlight(event.Namespace, tokenPoolConfirm, event.Correlator)
+	if err != nil {
+		return err
+	}
 
 	if inflight == nil && inflightPool == nil {
 		return nil
@@ -298,7 +480,10 @@ func (sa *syncAsyncBridge) handleMessageRejectedEvent(event *fftypes.EventDelive
 
 func (sa *syncAsyncBridge) handleIdentityConfirmedEvent(event *fftypes.EventDelivery) error {
 	// See if the CID marks this as a reply to an inflight identity
-	inflightReply := sa.getInFlight(event.Namespace, identityConfirm, event.Reference)
+	inflightReply, err := sa.getInFlight(event.Namespace, identityConfirm, event.Reference)
+	if err != nil {
+		return err
+	}
 	if inflightReply == nil {
 		return nil
 	}
@@ -315,7 +500,10 @@ func (sa *syncAsyncBridge) handleIdentityConfirmedEvent(event *fftypes.EventDeli
 
 func (sa *syncAsyncBridge) handlePoolConfirmedEvent(event *fftypes.EventDelivery) error {
 	// See if this is a confirmation of an inflight token pool
-	inflight := sa.getInFlight(event.Namespace, tokenPoolConfirm, event.Reference)
+	inflight, err := sa.getInFlight(event.Namespace, tokenPoolConfirm, event.Reference)
+	if err != nil {
+		return err
+	}
 	if inflight == nil {
 		return nil
 	}
@@ -332,7 +520,10 @@ func (sa *syncAsyncBridge) handlePoolConfirmedEvent(event *fftypes.EventDelivery
 
 func (sa *syncAsyncBridge) handleTransferConfirmedEvent(event *fftypes.EventDelivery) error {
 	// See if this is a confirmation of an inflight token transfer
-	inflight := sa.getInFlight(event.Namespace, tokenTransferConfirm, event.Reference)
+	inflight, err := sa.getInFlight(event.Namespace, tokenTransferConfirm, event.Reference)
+	if err != nil {
+		return err
+	}
 	if inflight == nil {
 		return nil
 	}
@@ -350,7 +541,10 @@ func (sa *syncAsyncBridge) handleTransferConfirmedEvent(event *fftypes.EventDeli
 func (sa *syncAsyncBridge) handleApprovalConfirmedEvent(event *fftypes.EventDelivery) error {
 
 	// See if this is a confirmation of an inflight token approval
-	inflight := sa.getInFlight(event.Namespace, tokenApproveConfirm, event.Reference)
+	inflight, err := sa.getInFlight(event.Namespace, tokenApproveConfirm, event.Reference)
+	if err != nil {
+		return err
+	}
 	if inflight == nil {
 		return nil
 	}
@@ -367,7 +561,10 @@ func (sa *syncAsyncBridge) handleApprovalConfirmedEvent(event *fftypes.EventDeli
 
 func (sa *syncAsyncBridge) handleTransferOpFailedEvent(event *fftypes.EventDelivery) error {
 	// See if this is a failure of an inflight token transfer operation
-	inflight := sa.getInFlight(event.Namespace, tokenTransferConfirm, event.Correlator)
+	inflight, err := sa.getInFlight(event.Namespace, tokenTransferConfirm, event.Correlator)
+	if err != nil {
+		return err
+	}
 	if inflight == nil {
 		return nil
 	}
@@ -389,7 +586,10 @@ func (sa *syncAsyncBridge) handleTransferOpFailedEvent(event *fftypes.EventDeliv
 
 func (sa *syncAsyncBridge) handleApprovalOpFailedEvent(event *fftypes.EventDelivery) error {
 	// See if this is a failure of an inflight token approval operation
-	inflight := sa.getInFlight(event.Namespace, tokenApproveConfirm, event.Correlator)
+	inflight, err := sa.getInFlight(event.Namespace, tokenApproveConfirm, event.Correlator)
+	if err != nil {
+		return err
+	}
 	if inflight == nil {
 		return nil
 	}
@@ -410,11 +610,24 @@ func (sa *syncAsyncBridge) handleApprovalOpFailedEvent(event *fftypes.EventDeliv
 }
 
 func (sa *syncAsyncBridge) eventCallback(event *fftypes.EventDelivery) error {
-	sa.inflightMux.Lock()
-	defer sa.inflightMux.Unlock()
+	if sa.distributed && isDefinitionConfirmed(event) {
+		// Listen on any namespace that has been created since we started. This has to happen asynchronously,
+		// as listeners cannot be added while an event is being delivered to them.
+		go func() {
+			if err := sa.addNamespaceListeners(); err != nil {
+				log.L(sa.ctx).Errorf("Failed to add listeners for new namespaces: %s", err)
+			}
+		}()
+	}
+
+	if !inflightEventTypes[event.Type] {
+		return nil
+	}
 
-	inflightNS := sa.inflight[event.Namespace]
-	if len(inflightNS) == 0 {
+	sa.inflightMux.Lock()
+	noLocalRequests := len(sa.inflight[event.Namespace]) == 0
+	sa.inflightMux.Unlock()
+	if noLocalRequests && !sa.distributed {
 		// No need to do any expensive lookups/matching - this could not be a match
 		return nil
 	}
@@ -441,15 +654,29 @@ func (sa *syncAsyncBridge) eventCallback(event *fftypes.EventDelivery) error {
 	case fftypes.EventTypeTransferOpFailed:
 		return sa.handleTransferOpFailedEvent(event)
 
-	case fftypes.EventTypeApprovalOpFailed:
+	default: // fftypes.EventTypeApprovalOpFailed
 		return sa.handleApprovalOpFailedEvent(event)
 	}
+}
 
-	return nil
+func (sa *syncAsyncBridge) respond(inflight *inflightRequest, response inflightResponse) {
+	if !inflight.remote {
+		inflight.response <- response
+		return
+	}
+	// The request is waiting on another replica, which picks up the resolution from the database
+	if err := sa.database.ResolveInflightRequest(sa.ctx, inflight.namespace, inflight.id, response.id, response.err != nil); err != nil {
+		log.L(sa.ctx).Errorf("Failed to resolve inflight request '%s' in the database: %s", inflight.id, err)
+	}
 }
 
 func (sa *syncAsyncBridge) resolveReply(inflight *inflightRequest, msg *fftypes.Message) {
 	log.L(sa.ctx).Debugf("Resolving reply request '%s' with message '%s'", inflight.id, msg.Header.ID)
+	if inflight.remote {
+		// The waiting replica reads the reply data itself
+		sa.respond(inflight, inflightResponse{id: msg.Header.ID})
+		return
+	}
 
 	response := &fftypes.MessageInOut{Message: *msg}
 	data, _, err := sa.data.GetMessageDataCached(sa.ctx, msg)
@@ -458,59 +685,59 @@ func (sa *syncAsyncBridge) resolveReply(inflight *inflightRequest, msg *fftypes.
 		return
 	}
 	response.SetInlineData(data)
-	inflight.response <- inflightResponse{id: msg.Header.ID, data: response}
+	sa.respond(inflight, inflightResponse{id: msg.Header.ID, data: response})
 }
 
 func (sa *syncAsyncBridge) resolveConfirmed(inflight *inflightRequest, msg *fftypes.Message) {
 	log.L(sa.ctx).Debugf("Resolving message confirmation request '%s' with ID '%s'", inflight.id, msg.Header.ID)
-	inflight.response <- inflightResponse{id: msg.Header.ID, data: msg}
+	sa.respond(inflight, inflightResponse{id: msg.Header.ID, data: msg})
 }
 
 func (sa *syncAsyncBridge) resolveRejected(inflight *inflightRequest, msgID *fftypes.UUID) {
 	err := i18n.NewError(sa.ctx, i18n.MsgRejected, msgID)
 	log.L(sa.ctx).Errorf("Resolving message confirmation request '%s' with error: %s", inflight.id, err)
-	inflight.response <- inflightResponse{err: err}
+	sa.respond(inflight, inflightResponse{id: msgID, err: err})
 }
 
 func (sa *syncAsyncBridge) resolveIdentity(inflight *inflightRequest, identity *fftypes.Identity) {
 	log.L(sa.ctx).Debugf("Resolving identity creation '%s' with ID '%s'", inflight.id, identity.ID)
-	inflight.response <- inflightResponse{id: identity.ID, data: identity}
+	sa.respond(inflight, inflightResponse{id: identity.ID, data: identity})
 }
 
 func (sa *syncAsyncBridge) resolveConfirmedTokenPool(inflight *inflightRequest, pool *fftypes.TokenPool) {
 	log.L(sa.ctx).Debugf("Resolving token pool confirmation request '%s' with ID '%s'", inflight.id, pool.ID)
-	inflight.response <- inflightResponse{id: pool.ID, data: pool}
+	sa.respond(inflight, inflightResponse{id: pool.ID, data: pool})
 }
 
 func (sa *syncAsyncBridge) resolveRejectedTokenPool(inflight *inflightRequest, poolID *fftypes.UUID) {
 	err := i18n.NewError(sa.ctx, i18n.MsgTokenPoolRejected, poolID)
 	log.L(sa.ctx).Errorf("Resolving token pool confirmation request '%s' with error '%s'", inflight.id, err)
-	inflight.response <- inflightResponse{err: err}
+	sa.respond(inflight, inflightResponse{id: poolID, err: err})
 }
 
 func (sa *syncAsyncBridge) resolveConfirmedTokenTransfer(inflight *inflightRequest, transfer *fftypes.TokenTransfer) {
 	log.L(sa.ctx).Debugf("Resolving token transfer confirmation request '%s' with ID '%s'", inflight.id, transfer.LocalID)
-	inflight.response <- inflightResponse{id: transfer.LocalID, data: transfer}
+	sa.respond(inflight, inflightResponse{id: transfer.LocalID, data: transfer})
 }
 
 func (sa *syncAsyncBridge) resolveConfirmedTokenApproval(inflight *inflightRequest, approval *fftypes.TokenApproval) {
 	log.L(sa.ctx).Debugf("Resolving token approval confirmation request '%s' with ID '%s'", inflight.id, approval.LocalID)
-	inflight.response <- inflightResponse{id: approval.LocalID, data: approval}
+	sa.respond(inflight, inflightResponse{id: approval.LocalID, data: approval})
 }
 
 func (sa *syncAsyncBridge) resolveFailedTokenTransfer(inflight *inflightRequest, transferID *fftypes.UUID) {
 	err := i18n.NewError(sa.ctx, i18n.MsgTokenTransferFailed, transferID)
 	log.L(sa.ctx).Debugf("Resolving token transfer confirmation request '%s' with error '%s'", inflight.id, err)
-	inflight.response <- inflightResponse{err: err}
+	sa.respond(inflight, inflightResponse{id: transferID, err: err})
 }
 
 func (sa *syncAsyncBridge) resolveFailedTokenApproval(inflight *inflightRequest, transferID *fftypes.UUID) {
 	err := i18n.NewError(sa.ctx, i18n.MsgTokenApprovalFailed, transferID)
 	log.L(sa.ctx).Debugf("Resolving token approval request '%s' with error '%s'", inflight.id, err)
-	inflight.response <- inflightResponse{err: err}
+	sa.respond(inflight, inflightResponse{id: transferID, err: err})
 }
 
-func (sa *syncAsyncBridge) sendAndWait(ctx context.Context, ns string, id *fftypes.UUID, reqType requestType, send RequestSender) (interface{}, error) {
+func (sa *syncAsyncBridge) sendAndWait(ctx context.Context, ns string, id *fftypes.UUID, reqType fftypes.InflightRequestType, send RequestSender) (interface{}, error) {
 	inflight, err := sa.addInFlight(ns, id, reqType)
 	if err != nil {
 		return nil, err
@@ -531,12 +758,100 @@ func (sa *syncAsyncBridge) sendAndWait(ctx context.Context, ns string, id *fftyp
 		return nil, err
 	}
 
-	select {
-	case <-ctx.Done():
-		return nil, i18n.NewError(ctx, i18n.MsgRequestTimeout, inflight.id, inflight.msInflight())
-	case reply := <-inflight.response:
-		replyID = reply.id
-		return reply.data, reply.err
+	var poll <-chan time.Time
+	if sa.distributed {
+		ticker := time.NewTicker(sa.pollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+	for {
+		var reply *inflightResponse
+		select {
+		case <-ctx.Done():
+			return nil, i18n.NewError(ctx, i18n.MsgRequestTimeout, inflight.id, inflight.msInflight())
+		case r := <-inflight.response:
+			reply = &r
+		case <-inflight.updated:
+			reply, err = sa.checkResolved(ctx, inflight)
+		case <-poll:
+			reply, err = sa.checkResolved(ctx, inflight)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if reply != nil {
+			replyID = reply.id
+			return reply.data, reply.err
+		}
+	}
+}
+
+// checkResolved looks in the database for a resolution of the request made by another replica
+func (sa *syncAsyncBridge) checkResolved(ctx context.Context, inflight *inflightRequest) (*inflightResponse, error) {
+	req, err := sa.database.GetInflightRequestByID(ctx, inflight.id)
+	if err != nil || req == nil || req.Resolved == nil {
+		return nil, err
+	}
+	log.L(sa.ctx).Debugf("Inflight request '%s' was resolved by another replica with '%s'", inflight.id, req.Reply)
+	if req.Failed {
+		return &inflightResponse{id: req.Reply, err: sa.failedError(ctx, inflight.reqType, req.Reply)}, nil
+	}
+
+	var data interface{}
+	switch inflight.reqType {
+	case messageReply, messageConfirm:
+		msg, err := sa.database.GetMessageByID(ctx, req.Reply)
+		if err != nil || msg == nil {
+			return nil, err
+		}
+		data = msg
+		if inflight.reqType == messageReply {
+			response := &fftypes.MessageInOut{Message: *msg}
+			msgData, _, err := sa.data.GetMessageDataCached(ctx, msg)
+			if err != nil {
+				return nil, err
+			}
+			response.SetInlineData(msgData)
+			data = response
+		}
+	case identityConfirm:
+		identity, err := sa.database.GetIdentityByID(ctx, req.Reply)
+		if err != nil || identity == nil {
+			return nil, err
+		}
+		data = identity
+	case tokenPoolConfirm:
+		pool, err := sa.database.GetTokenPoolByID(ctx, req.Reply)
+		if err != nil || pool == nil {
+			return nil, err
+		}
+		data = pool
+	case tokenTransferConfirm:
+		transfer, err := sa.database.GetTokenTransfer(ctx, req.Reply)
+		if err != nil || transfer == nil {
+			return nil, err
+		}
+		data = transfer
+	default:
+		approval, err := sa.database.GetTokenApproval(ctx, req.Reply)
+		if err != nil || approval == nil {
+			return nil, err
+		}
+		data = approval
+	}
+	return &inflightResponse{id: req.Reply, data: data}, nil
+}
+
+func (sa *syncAsyncBridge) failedError(ctx context.Context, reqType fftypes.InflightRequestType, id *fftypes.UUID) error {
+	switch reqType {
+	case tokenPoolConfirm:
+		return i18n.NewError(ctx, i18n.MsgTokenPoolRejected, id)
+	case tokenTransferConfirm:
+		return i18n.NewError(ctx, i18n.MsgTokenTransferFailed, id)
+	case tokenApproveConfirm:
+		return i18n.NewError(ctx, i18n.MsgTokenApprovalFailed, id)
+	default:
+		return i18n.NewError(ctx, i18n.MsgRejected, id)
 	}
 }
 
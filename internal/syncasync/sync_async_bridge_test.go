@@ -21,10 +21,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/datamocks"
 	"github.com/hyperledger/firefly/mocks/sysmessagingmocks"
+	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -1349,3 +1351,647 @@ func TestAwaitIdentityFail(t *testing.T) {
 	})
 	assert.Regexp(t, "pop", err)
 }
+
+func newTestDistributedSyncAsyncBridge(t *testing.T) (*syncAsyncBridge, func()) {
+	sa, cancel := newTestSyncAsyncBridge(t)
+	sa.distributed = true
+	sa.pollInterval = 1 * time.Hour
+	sa.requestExpiry = 1 * time.Hour
+	return sa, cancel
+}
+
+func TestStartNotDistributed(t *testing.T) {
+
+	sa, cancel := newTestSyncAsyncBridge(t)
+	defer cancel()
+
+	err := sa.Start()
+	assert.NoError(t, err)
+}
+
+func TestStartDistributed(t *testing.T) {
+
+	sa, cancel := newTestDistributedSyncAsyncBridge(t)
+	defer cancel()
+
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("GetNamespaces", sa.ctx, mock.Anything).Return([]*fftypes.Namespace{
+		{Name: "ns1"}, {Name: "ns2"},
+	}, nil, nil)
+
+	mdi.On("GetInflightRequests", sa.ctx, mock.Anything).Return([]*fftypes.InflightRequest{}, nil, nil).Run(func(args mock.Arguments) {
+		cancel()
+	})
+
+	mse := sa.sysevents.(*sysmessagingmocks.SystemEvents)
+	mse.On("AddSystemEventListener", "ns1", mock.Anything).Return(nil)
+	mse.On("AddSystemEventListener", "ns2", mock.Anything).Return(nil)
+
+	err := sa.Start()
+	assert.NoError(t, err)
+	assert.Len(t, sa.inflight, 2)
+	sa.WaitStop()
+	assert.Nil(t, sa.cleanupDone)
+
+	mdi.AssertExpectations(t)
+	mse.AssertExpectations(t)
+}
+
+func TestCleanupLoopFail(t *testing.T) {
+
+	sa, cancel := newTestDistributedSyncAsyncBridge(t)
+	sa.cleanupDone = make(chan struct{})
+
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("GetInflightRequests", sa.ctx, mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Run(func(args mock.Arguments) {
+		cancel()
+	})
+
+	sa.cleanupLoop()
+	mdi.AssertExpectations(t)
+}
+
+func TestDeleteExpiredRequests(t *testing.T) {
+
+	sa, cancel := newTestDistributedSyncAsyncBridge(t)
+	defer cancel()
+
+	expired := &fftypes.InflightRequest{ID: fftypes.NewUUID(), Created: fftypes.Now()}
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("GetInflightRequests", sa.ctx, mock.MatchedBy(func(filter database.Filter) bool {
+		f, _ := filter.Finalize()
+		v, _ := f.Value.Value()
+		return f.Field == "created" && f.Op == database.FilterOpLt && v.(int64) < time.Now().Add(-30*time.Minute).UnixNano()
+	})).Return([]*fftypes.InflightRequest{expired}, nil, nil)
+	mdi.On("DeleteInflightRequest", sa.ctx, expired.ID).Return(nil)
+
+	err := sa.deleteExpiredRequests()
+	assert.NoError(t, err)
+	mdi.AssertExpectations(t)
+}
+
+func TestDeleteExpiredRequestsFail(t *testing.T) {
+
+	sa, cancel := newTestDistributedSyncAsyncBridge(t)
+	defer cancel()
+
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("GetInflightRequests", sa.ctx, mock.Anything).Return([]*fftypes.InflightRequest{{ID: fftypes.NewUUID()}}, nil, nil)
+	mdi.On("DeleteInflightRequest", sa.ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := sa.deleteExpiredRequests()
+	assert.EqualError(t, err, "pop")
+	mdi.AssertExpectations(t)
+}
+
+func TestStartDistributedGetNamespacesFail(t *testing.T) {
+
+	sa, cancel := newTestDistributedSyncAsyncBridge(t)
+	defer cancel()
+
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("GetNamespaces", sa.ctx, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := sa.Start()
+	assert.EqualError(t, err, "pop")
+}
+
+func TestStartDistributedAddListenerFail(t *testing.T) {
+
+	sa, cancel := newTestDistributedSyncAsyncBridge(t)
+	defer cancel()
+
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("GetNamespaces", sa.ctx, mock.Anything).Return([]*fftypes.Namespace{
+		{Name: "ns1"},
+	}, nil, nil)
+
+	mse := sa.sysevents.(*sysmessagingmocks.SystemEvents)
+	mse.On("AddSystemEventListener", "ns1", mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := sa.Start()
+	assert.EqualError(t, err, "pop")
+}
+
+func TestEventCallbackNewNamespace(t *testing.T) {
+
+	sa, cancel := newTestDistributedSyncAsyncBridge(t)
+	defer cancel()
+	sa.inflight[fftypes.SystemNamespace] = map[fftypes.UUID]*inflightRequest{}
+
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("GetNamespaces", sa.ctx, mock.Anything).Return([]*fftypes.Namespace{
+		{Name: fftypes.SystemNamespace}, {Name: "ns2"},
+	}, nil, nil)
+	mdi.On("GetInflightRequestByID", sa.ctx, mock.Anything).Return(nil, nil)
+
+	added := make(chan struct{})
+	mse := sa.sysevents.(*sysmessagingmocks.SystemEvents)
+	mse.On("AddSystemEventListener", "ns2", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		close(added)
+	})
+
+	err := sa.eventCallback(&fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{
+				ID:        fftypes.NewUUID(),
+				Type:      fftypes.EventTypeMessageConfirmed,
+				Namespace: fftypes.SystemNamespace,
+				Topic:     fftypes.SystemTopicDefinitions,
+				Reference: fftypes.NewUUID(),
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	<-added
+	mse.AssertExpectations(t)
+}
+
+func TestEventCallbackNewNamespaceFail(t *testing.T) {
+
+	sa, cancel := newTestDistributedSyncAsyncBridge(t)
+	defer cancel()
+
+	done := make(chan struct{})
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("GetNamespaces", sa.ctx, mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Run(func(args mock.Arguments) {
+		close(done)
+	})
+
+	err := sa.eventCallback(&fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{
+				ID:        fftypes.NewUUID(),
+				Type:      fftypes.EventTypeMessageConfirmed,
+				Namespace: fftypes.SystemNamespace,
+				Topic:     fftypes.SystemTopicDefinitions,
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	<-done
+}
+
+func TestDistributedInsertFail(t *testing.T) {
+
+	sa, cancel := newTestDistributedSyncAsyncBridge(t)
+	defer cancel()
+
+	mse := sa.sysevents.(*sysmessagingmocks.SystemEvents)
+	mse.On("AddSystemEventListener", "ns1", mock.Anything).Return(nil)
+
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("InsertInflightRequest", sa.ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := sa.WaitForMessage(sa.ctx, "ns1", fftypes.NewUUID(), func(ctx context.Context) error {
+		return nil
+	})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDistributedResolvedLocally(t *testing.T) {
+
+	sa, cancel := newTestDistributedSyncAsyncBridge(t)
+	defer cancel()
+
+	requestID := fftypes.NewUUID()
+
+	mse := sa.sysevents.(*sysmessagingmocks.SystemEvents)
+	mse.On("AddSystemEventListener", "ns1", mock.Anything).Return(nil)
+
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("InsertInflightRequest", sa.ctx, mock.MatchedBy(func(req *fftypes.InflightRequest) bool {
+		return req.ID.Equals(requestID) && req.Namespace == "ns1" && req.Type == fftypes.InflightRequestTypeTokenPoolConfirm
+	})).Return(nil).Run(func(args mock.Arguments) {
+		// The insert is made outside of the lock
+		sa.inflightMux.Lock()
+		sa.inflightMux.Unlock()
+	})
+	mdi.On("DeleteInflightRequest", sa.ctx, requestID).Return(fmt.Errorf("pop"))
+	mdi.On("GetTokenPoolByID", sa.ctx, requestID).Return(&fftypes.TokenPool{ID: requestID}, nil)
+
+	reply, err := sa.WaitForTokenPool(sa.ctx, "ns1", requestID, func(ctx context.Context) error {
+		go func() {
+			sa.eventCallback(&fftypes.EventDelivery{
+				EnrichedEvent: fftypes.EnrichedEvent{
+					Event: fftypes.Event{
+						ID:        fftypes.NewUUID(),
+						Type:      fftypes.EventTypePoolConfirmed,
+						Reference: requestID,
+						Namespace: "ns1",
+					},
+				},
+			})
+		}()
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, *requestID, *reply.ID)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestDistributedResolvedRemotelyNotified(t *testing.T) {
+
+	sa, cancel := newTestDistributedSyncAsyncBridge(t)
+	defer cancel()
+
+	requestID := fftypes.NewUUID()
+
+	mse := sa.sysevents.(*sysmessagingmocks.SystemEvents)
+	mse.On("AddSystemEventListener", "ns1", mock.Anything).Return(nil)
+
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("InsertInflightRequest", sa.ctx, mock.Anything).Return(nil)
+	mdi.On("DeleteInflightRequest", sa.ctx, requestID).Return(nil)
+	mdi.On("GetInflightRequestByID", sa.ctx, requestID).Return(&fftypes.InflightRequest{
+		ID:       requestID,
+		Resolved: fftypes.Now(),
+		Reply:    requestID,
+	}, nil)
+	mdi.On("GetMessageByID", sa.ctx, requestID).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{ID: requestID},
+	}, nil)
+
+	reply, err := sa.WaitForMessage(sa.ctx, "ns1", requestID, func(ctx context.Context) error {
+		sa.InflightRequestUpdated("ns1", requestID)
+		sa.InflightRequestUpdated("ns1", requestID) // second notification is dropped
+		sa.InflightRequestUpdated("ns2", requestID) // unknown namespace is ignored
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, *requestID, *reply.Header.ID)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestDistributedResolvedRemotelyPolled(t *testing.T) {
+
+	sa, cancel := newTestDistributedSyncAsyncBridge(t)
+	defer cancel()
+	sa.pollInterval = 1 * time.Millisecond
+
+	requestID := fftypes.NewUUID()
+	transferID := fftypes.NewUUID()
+
+	mse := sa.sysevents.(*sysmessagingmocks.SystemEvents)
+	mse.On("AddSystemEventListener", "ns1", mock.Anything).Return(nil)
+
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("InsertInflightRequest", sa.ctx, mock.Anything).Return(nil)
+	mdi.On("DeleteInflightRequest", sa.ctx, requestID).Return(nil)
+	mdi.On("GetInflightRequestByID", sa.ctx, requestID).Return(&fftypes.InflightRequest{
+		ID: requestID,
+	}, nil).Once()
+	mdi.On("GetInflightRequestByID", sa.ctx, requestID).Return(&fftypes.InflightRequest{
+		ID:       requestID,
+		Resolved: fftypes.Now(),
+		Reply:    transferID,
+		Failed:   true,
+	}, nil)
+
+	_, err := sa.WaitForTokenTransfer(sa.ctx, "ns1", requestID, func(ctx context.Context) error {
+		return nil
+	})
+	assert.Regexp(t, "FF10291.*"+transferID.String(), err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestDistributedPollFail(t *testing.T) {
+
+	sa, cancel := newTestDistributedSyncAsyncBridge(t)
+	defer cancel()
+	sa.pollInterval = 1 * time.Millisecond
+
+	requestID := fftypes.NewUUID()
+
+	mse := sa.sysevents.(*sysmessagingmocks.SystemEvents)
+	mse.On("AddSystemEventListener", "ns1", mock.Anything).Return(nil)
+
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("InsertInflightRequest", sa.ctx, mock.Anything).Return(nil)
+	mdi.On("DeleteInflightRequest", sa.ctx, requestID).Return(nil)
+	mdi.On("GetInflightRequestByID", sa.ctx, requestID).Return(nil, fmt.Errorf("pop"))
+
+	_, err := sa.WaitForIdentity(sa.ctx, "ns1", requestID, func(ctx context.Context) error {
+		return nil
+	})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestCheckResolvedTypes(t *testing.T) {
+
+	sa, cancel := newTestDistributedSyncAsyncBridge(t)
+	defer cancel()
+
+	requestID := fftypes.NewUUID()
+	replyID := fftypes.NewUUID()
+
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("GetInflightRequestByID", sa.ctx, requestID).Return(&fftypes.InflightRequest{
+		ID:       requestID,
+		Resolved: fftypes.Now(),
+		Reply:    replyID,
+	}, nil)
+	msg := &fftypes.Message{Header: fftypes.MessageHeader{ID: replyID}}
+	mdi.On("GetMessageByID", sa.ctx, replyID).Return(msg, nil)
+	mdi.On("GetIdentityByID", sa.ctx, replyID).Return(&fftypes.Identity{}, nil)
+	mdi.On("GetTokenPoolByID", sa.ctx, replyID).Return(&fftypes.TokenPool{}, nil)
+	mdi.On("GetTokenTransfer", sa.ctx, replyID).Return(&fftypes.TokenTransfer{}, nil)
+	mdi.On("GetTokenApproval", sa.ctx, replyID).Return(&fftypes.TokenApproval{}, nil)
+
+	mdm := sa.data.(*datamocks.Manager)
+	mdm.On("GetMessageDataCached", sa.ctx, msg).Return(fftypes.DataArray{
+		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"response data"`)},
+	}, true, nil)
+
+	for _, reqType := range []fftypes.InflightRequestType{
+		messageReply,
+		messageConfirm,
+		identityConfirm,
+		tokenPoolConfirm,
+		tokenTransferConfirm,
+		tokenApproveConfirm,
+	} {
+		reply, err := sa.checkResolved(sa.ctx, &inflightRequest{id: requestID, reqType: reqType})
+		assert.NoError(t, err)
+		assert.Equal(t, *replyID, *reply.id)
+		assert.NotNil(t, reply.data)
+	}
+
+	reply, _ := sa.checkResolved(sa.ctx, &inflightRequest{id: requestID, reqType: messageReply})
+	assert.Equal(t, `"response data"`, reply.data.(*fftypes.MessageInOut).InlineData[0].Value.String())
+}
+
+func TestCheckResolvedFailures(t *testing.T) {
+
+	sa, cancel := newTestDistributedSyncAsyncBridge(t)
+	defer cancel()
+
+	requestID := fftypes.NewUUID()
+	replyID := fftypes.NewUUID()
+
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("GetInflightRequestByID", sa.ctx, requestID).Return(&fftypes.InflightRequest{
+		ID:       requestID,
+		Resolved: fftypes.Now(),
+		Reply:    replyID,
+		Failed:   true,
+	}, nil)
+
+	for reqType, errCode := range map[fftypes.InflightRequestType]string{
+		messageConfirm:       "FF10269",
+		tokenPoolConfirm:     "FF10276",
+		tokenTransferConfirm: "FF10291",
+		tokenApproveConfirm:  "FF10369",
+	} {
+		reply, err := sa.checkResolved(sa.ctx, &inflightRequest{id: requestID, reqType: reqType})
+		assert.NoError(t, err)
+		assert.Regexp(t, errCode+".*"+replyID.String(), reply.err)
+	}
+}
+
+func TestCheckResolvedLookupFails(t *testing.T) {
+
+	sa, cancel := newTestDistributedSyncAsyncBridge(t)
+	defer cancel()
+
+	requestID := fftypes.NewUUID()
+	replyID := fftypes.NewUUID()
+
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("GetInflightRequestByID", sa.ctx, requestID).Return(&fftypes.InflightRequest{
+		ID:       requestID,
+		Resolved: fftypes.Now(),
+		Reply:    replyID,
+	}, nil)
+	mdi.On("GetMessageByID", sa.ctx, replyID).Return(nil, fmt.Errorf("pop"))
+	mdi.On("GetIdentityByID", sa.ctx, replyID).Return(nil, fmt.Errorf("pop"))
+	mdi.On("GetTokenPoolByID", sa.ctx, replyID).Return(nil, fmt.Errorf("pop"))
+	mdi.On("GetTokenTransfer", sa.ctx, replyID).Return(nil, fmt.Errorf("pop"))
+	mdi.On("GetTokenApproval", sa.ctx, replyID).Return(nil, fmt.Errorf("pop"))
+
+	for _, reqType := range []fftypes.InflightRequestType{
+		messageConfirm,
+		identityConfirm,
+		tokenPoolConfirm,
+		tokenTransferConfirm,
+		tokenApproveConfirm,
+	} {
+		reply, err := sa.checkResolved(sa.ctx, &inflightRequest{id: requestID, reqType: reqType})
+		assert.EqualError(t, err, "pop")
+		assert.Nil(t, reply)
+	}
+}
+
+func TestCheckResolvedReplyDataFail(t *testing.T) {
+
+	sa, cancel := newTestDistributedSyncAsyncBridge(t)
+	defer cancel()
+
+	requestID := fftypes.NewUUID()
+	replyID := fftypes.NewUUID()
+
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("GetInflightRequestByID", sa.ctx, requestID).Return(&fftypes.InflightRequest{
+		ID:       requestID,
+		Resolved: fftypes.Now(),
+		Reply:    replyID,
+	}, nil)
+	mdi.On("GetMessageByID", sa.ctx, replyID).Return(&fftypes.Message{}, nil)
+
+	mdm := sa.data.(*datamocks.Manager)
+	mdm.On("GetMessageDataCached", sa.ctx, mock.Anything).Return(nil, false, fmt.Errorf("pop"))
+
+	_, err := sa.checkResolved(sa.ctx, &inflightRequest{id: requestID, reqType: messageReply})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestEventCallbackResolvesRemote(t *testing.T) {
+
+	sa, cancel := newTestDistributedSyncAsyncBridge(t)
+	defer cancel()
+
+	requestID := fftypes.NewUUID()
+	replyID := fftypes.NewUUID()
+
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("GetInflightRequestByID", sa.ctx, requestID).Return(&fftypes.InflightRequest{
+		ID:        requestID,
+		Namespace: "ns1",
+		Type:      messageReply,
+		Created:   fftypes.Now(),
+	}, nil)
+	mdi.On("GetInflightRequestByID", sa.ctx, replyID).Return(nil, nil)
+	mdi.On("GetMessageByID", sa.ctx, replyID).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{ID: replyID, CID: requestID},
+	}, nil)
+	resolved := make(chan struct{})
+	mdi.On("ResolveInflightRequest", sa.ctx, "ns1", requestID, replyID, false).Return(fmt.Errorf("pop")).Run(func(args mock.Arguments) {
+		close(resolved)
+	})
+
+	err := sa.eventCallback(&fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{
+				ID:         fftypes.NewUUID(),
+				Type:       fftypes.EventTypeMessageConfirmed,
+				Reference:  replyID,
+				Correlator: requestID,
+				Namespace:  "ns1",
+			},
+		},
+	})
+	assert.NoError(t, err)
+	<-resolved
+
+	mdi.AssertExpectations(t)
+}
+
+func TestEventCallbackRemoteRejected(t *testing.T) {
+
+	sa, cancel := newTestDistributedSyncAsyncBridge(t)
+	defer cancel()
+
+	requestID := fftypes.NewUUID()
+
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("GetInflightRequestByID", sa.ctx, requestID).Return(&fftypes.InflightRequest{
+		ID:        requestID,
+		Namespace: "ns1",
+		Type:      messageConfirm,
+		Created:   fftypes.Now(),
+	}, nil)
+	mdi.On("GetMessageByID", sa.ctx, requestID).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{ID: requestID},
+	}, nil)
+	resolved := make(chan struct{})
+	mdi.On("ResolveInflightRequest", sa.ctx, "ns1", requestID, requestID, true).Return(nil).Run(func(args mock.Arguments) {
+		close(resolved)
+	})
+
+	err := sa.eventCallback(&fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{
+				ID:        fftypes.NewUUID(),
+				Type:      fftypes.EventTypeMessageRejected,
+				Reference: requestID,
+				Namespace: "ns1",
+			},
+		},
+	})
+	assert.NoError(t, err)
+	<-resolved
+
+	mdi.AssertExpectations(t)
+}
+
+func TestEventCallbackRemoteNotMatched(t *testing.T) {
+
+	sa, cancel := newTestDistributedSyncAsyncBridge(t)
+	defer cancel()
+
+	otherNS := fftypes.NewUUID()
+	otherType := fftypes.NewUUID()
+	resolved := fftypes.NewUUID()
+	wrongLocalType := fftypes.NewUUID()
+	sa.inflight = map[string]map[fftypes.UUID]*inflightRequest{
+		"ns1": {
+			*wrongLocalType: &inflightRequest{
+				reqType: identityConfirm,
+			},
+		},
+	}
+
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("GetInflightRequestByID", sa.ctx, otherNS).Return(&fftypes.InflightRequest{
+		ID: otherNS, Namespace: "ns2", Type: tokenPoolConfirm,
+	}, nil)
+	mdi.On("GetInflightRequestByID", sa.ctx, otherType).Return(&fftypes.InflightRequest{
+		ID: otherType, Namespace: "ns1", Type: tokenTransferConfirm,
+	}, nil)
+	mdi.On("GetInflightRequestByID", sa.ctx, resolved).Return(&fftypes.InflightRequest{
+		ID: resolved, Namespace: "ns1", Type: tokenPoolConfirm, Resolved: fftypes.Now(),
+	}, nil)
+
+	for _, id := range []*fftypes.UUID{otherNS, otherType, resolved, wrongLocalType} {
+		err := sa.eventCallback(&fftypes.EventDelivery{
+			EnrichedEvent: fftypes.EnrichedEvent{
+				Event: fftypes.Event{
+					ID:        fftypes.NewUUID(),
+					Type:      fftypes.EventTypePoolConfirmed,
+					Reference: id,
+					Namespace: "ns1",
+				},
+			},
+		})
+		assert.NoError(t, err)
+	}
+
+	mdi.AssertExpectations(t)
+}
+
+func TestEventCallbackRemoteLookupFail(t *testing.T) {
+
+	sa, cancel := newTestDistributedSyncAsyncBridge(t)
+	defer cancel()
+
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("GetInflightRequestByID", sa.ctx, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	for _, eventType := range []fftypes.EventType{
+		fftypes.EventTypeMessageConfirmed,
+		fftypes.EventTypeMessageRejected,
+		fftypes.EventTypePoolConfirmed,
+		fftypes.EventTypeTransferConfirmed,
+		fftypes.EventTypeApprovalConfirmed,
+		fftypes.EventTypeTransferOpFailed,
+		fftypes.EventTypeApprovalOpFailed,
+		fftypes.EventTypeIdentityConfirmed,
+	} {
+		err := sa.eventCallback(&fftypes.EventDelivery{
+			EnrichedEvent: fftypes.EnrichedEvent{
+				Event: fftypes.Event{
+					Namespace:  "ns1",
+					ID:         fftypes.NewUUID(),
+					Reference:  fftypes.NewUUID(),
+					Correlator: fftypes.NewUUID(),
+					Type:       eventType,
+				},
+			},
+		})
+		assert.EqualError(t, err, "pop")
+	}
+}
+
+func TestEventCallbackRemoteCorrelatorLookupFail(t *testing.T) {
+
+	sa, cancel := newTestDistributedSyncAsyncBridge(t)
+	defer cancel()
+
+	reference := fftypes.NewUUID()
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("GetInflightRequestByID", sa.ctx, reference).Return(nil, nil)
+	mdi.On("GetInflightRequestByID", sa.ctx, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	for _, eventType := range []fftypes.EventType{
+		fftypes.EventTypeMessageConfirmed,
+		fftypes.EventTypeMessageRejected,
+	} {
+		err := sa.eventCallback(&fftypes.EventDelivery{
+			EnrichedEvent: fftypes.EnrichedEvent{
+				Event: fftypes.Event{
+					Namespace:  "ns1",
+					ID:         fftypes.NewUUID(),
+					Reference:  reference,
+					Correlator: fftypes.NewUUID(),
+					Type:       eventType,
+				},
+			},
+		})
+		assert.EqualError(t, err, "pop")
+	}
+}
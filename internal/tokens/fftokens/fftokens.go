@@ -162,6 +162,13 @@ func (ft *FFTokens) Capabilities() *tokens.Capabilities {
 	return ft.capabilities
 }
 
+func (ft *FFTokens) HealthCheck(ctx context.Context) error {
+	if !ft.wsconn.Connected() {
+		return i18n.NewError(ctx, i18n.MsgWSNotConnected, ft.configuredName)
+	}
+	return nil
+}
+
 func (ft *FFTokens) handleReceipt(ctx context.Context, data fftypes.JSONObject) error {
 	l := log.L(ctx)
 
@@ -409,15 +416,64 @@ func (ft *FFTokens) handleTokenApproval(ctx context.Context, data fftypes.JSONOb
 	return ft.callbacks.TokensApproved(ft, approval)
 }
 
+// handleTokenEventRemoved handles an event of any type that the connector reports has been removed from the chain
+// by a reorganization, after it was previously delivered
+func (ft *FFTokens) handleTokenEventRemoved(ctx context.Context, data fftypes.JSONObject) (err error) {
+	eventProtocolID := data.GetString("id")
+	if eventProtocolID == "" {
+		log.L(ctx).Errorf("Removed event is not valid - missing data: %+v", data)
+		return nil // move on
+	}
+	tx := data.GetObject("transaction")
+	event := &blockchain.Event{
+		BlockchainTXID: tx.GetString("transactionHash"),
+		Source:         ft.Name() + ":" + ft.configuredName,
+		ProtocolID:     eventProtocolID,
+		Info:           tx,
+	}
+	log.L(ctx).Warnf("Event %s removed from the chain: %+v", event.ProtocolID, data)
+	return ft.callbacks.TokenEventRemoved(ft, event)
+}
+
+func (ft *FFTokens) sendAck(ctx context.Context, id string) error {
+	log.L(ctx).Debugf("Sending ack %s", id)
+	ack, _ := json.Marshal(fftypes.JSONObject{
+		"event": "ack",
+		"data": fftypes.JSONObject{
+			"id": id,
+		},
+	})
+	return ft.wsconn.Send(ctx, ack)
+}
+
+type pendingAck struct {
+	id         string
+	dispatched <-chan struct{}
+}
+
 func (ft *FFTokens) eventLoop() {
 	defer ft.wsconn.Close()
 	l := log.L(ft.ctx).WithField("role", "event-loop")
 	ctx := log.WithLogger(ft.ctx, l)
+	// Acks are held back until the events have been processed, which might be after waiting for confirmations,
+	// and are sent in the order the events were received
+	var pendingAcks []*pendingAck
 	for {
+		var nextDispatched <-chan struct{}
+		if len(pendingAcks) > 0 {
+			nextDispatched = pendingAcks[0].dispatched
+		}
 		select {
 		case <-ctx.Done():
 			l.Debugf("Event loop exiting (context cancelled)")
 			return
+		case <-nextDispatched:
+			id := pendingAcks[0].id
+			pendingAcks = pendingAcks[1:]
+			if err := ft.sendAck(ctx, id); err != nil {
+				l.Errorf("Event loop exiting: %s", err)
+				return
+			}
 		case msgBytes, ok := <-ft.wsconn.Receive():
 			if !ok {
 				l.Debugf("Event loop exiting (receive channel closed)")
@@ -431,32 +487,20 @@ func (ft *FFTokens) eventLoop() {
 				continue // Swallow this and move on
 			}
 			l.Debugf("Received %s event %s", msg.Event, msg.ID)
-			switch msg.Event {
-			case messageReceipt:
+			switch {
+			case msg.Event == messageReceipt:
 				err = ft.handleReceipt(ctx, msg.Data)
-			case messageTokenPool:
-				err = ft.handleTokenPoolCreate(ctx, msg.Data)
-			case messageTokenMint:
-				err = ft.handleTokenTransfer(ctx, fftypes.TokenTransferTypeMint, msg.Data)
-			case messageTokenBurn:
-				err = ft.handleTokenTransfer(ctx, fftypes.TokenTransferTypeBurn, msg.Data)
-			case messageTokenTransfer:
-				err = ft.handleTokenTransfer(ctx, fftypes.TokenTransferTypeTransfer, msg.Data)
-			case messageTokenApproval:
-				err = ft.handleTokenApproval(ctx, msg.Data)
+			case msg.Data.GetBool("removed"):
+				err = ft.handleTokenEventRemoved(ctx, msg.Data)
 			default:
-				l.Errorf("Message unexpected: %s", msg.Event)
+				err = ft.handleTokenEvent(ctx, &msg)
 			}
 
 			if err == nil && msg.Event != messageReceipt && msg.ID != "" {
-				l.Debugf("Sending ack %s", msg.ID)
-				ack, _ := json.Marshal(fftypes.JSONObject{
-					"event": "ack",
-					"data": fftypes.JSONObject{
-						"id": msg.ID,
-					},
+				pendingAcks = append(pendingAcks, &pendingAck{
+					id:         msg.ID,
+					dispatched: ft.callbacks.TokenEventsDispatched(ft.configuredName),
 				})
-				err = ft.wsconn.Send(ctx, ack)
 			}
 
 			if err != nil {
@@ -467,6 +511,24 @@ func (ft *FFTokens) eventLoop() {
 	}
 }
 
+func (ft *FFTokens) handleTokenEvent(ctx context.Context, msg *wsEvent) error {
+	switch msg.Event {
+	case messageTokenPool:
+		return ft.handleTokenPoolCreate(ctx, msg.Data)
+	case messageTokenMint:
+		return ft.handleTokenTransfer(ctx, fftypes.TokenTransferTypeMint, msg.Data)
+	case messageTokenBurn:
+		return ft.handleTokenTransfer(ctx, fftypes.TokenTransferTypeBurn, msg.Data)
+	case messageTokenTransfer:
+		return ft.handleTokenTransfer(ctx, fftypes.TokenTransferTypeTransfer, msg.Data)
+	case messageTokenApproval:
+		return ft.handleTokenApproval(ctx, msg.Data)
+	default:
+		log.L(ctx).Errorf("Message unexpected: %s", msg.Event)
+		return nil
+	}
+}
+
 func (ft *FFTokens) CreateTokenPool(ctx context.Context, opID *fftypes.UUID, pool *fftypes.TokenPool) (complete bool, err error) {
 	data, _ := json.Marshal(tokenData{
 		TX:     pool.TX.ID,
@@ -30,6 +30,7 @@ import (
 	"github.com/hyperledger/firefly/internal/restclient"
 	"github.com/hyperledger/firefly/mocks/tokenmocks"
 	"github.com/hyperledger/firefly/mocks/wsmocks"
+	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/hyperledger/firefly/pkg/tokens"
 	"github.com/hyperledger/firefly/pkg/wsclient"
@@ -60,7 +61,11 @@ func newTestFFTokens(t *testing.T) (h *FFTokens, toServer, fromServer chan strin
 	utConfPrefix.AddKnownKey(restclient.HTTPCustomClient, mockedClient)
 	config.Set("tokens", []fftypes.JSONObject{{}})
 
-	err := h.Init(context.Background(), "testtokens", utConfPrefix.ArrayEntry(0), &tokenmocks.Callbacks{})
+	mcb := &tokenmocks.Callbacks{}
+	dispatched := make(chan struct{})
+	close(dispatched)
+	mcb.On("TokenEventsDispatched", "testtokens").Return((<-chan struct{})(dispatched)).Maybe()
+	err := h.Init(context.Background(), "testtokens", utConfPrefix.ArrayEntry(0), mcb)
 	assert.NoError(t, err)
 	assert.Equal(t, "fftokens", h.Name())
 	assert.Equal(t, "testtokens", h.configuredName)
@@ -983,6 +988,23 @@ func TestEvents(t *testing.T) {
 	mcb.AssertExpectations(t)
 }
 
+func TestHealthCheck(t *testing.T) {
+	wsm := &wsmocks.WSClient{}
+	h := &FFTokens{
+		ctx:            context.Background(),
+		configuredName: "erc1155",
+		wsconn:         wsm,
+	}
+	wsm.On("Connected").Return(true).Once()
+	err := h.HealthCheck(context.Background())
+	assert.NoError(t, err)
+
+	wsm.On("Connected").Return(false).Once()
+	err = h.HealthCheck(context.Background())
+	assert.Regexp(t, "FF10420.*erc1155", err)
+	wsm.AssertExpectations(t)
+}
+
 func TestEventLoopReceiveClosed(t *testing.T) {
 	dxc := &tokenmocks.Callbacks{}
 	wsm := &wsmocks.WSClient{}
@@ -1008,12 +1030,104 @@ func TestEventLoopSendClosed(t *testing.T) {
 	}
 	r := make(chan []byte, 1)
 	r <- []byte(`{"id":"1"}`) // ignored but acked
+	dispatched := make(chan struct{})
+	close(dispatched)
+	dxc.On("TokenEventsDispatched", "").Return((<-chan struct{})(dispatched))
 	wsm.On("Close").Return()
 	wsm.On("Receive").Return((<-chan []byte)(r))
 	wsm.On("Send", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
 	h.eventLoop() // we're simply looking for it exiting
 }
 
+func TestEventLoopAckAfterDispatch(t *testing.T) {
+	dxc := &tokenmocks.Callbacks{}
+	wsm := &wsmocks.WSClient{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	h := &FFTokens{
+		ctx:            ctx,
+		configuredName: "erc1155",
+		callbacks:      dxc,
+		wsconn:         wsm,
+	}
+	r := make(chan []byte)
+	dispatched1 := make(chan struct{})
+	dispatched2 := make(chan struct{})
+	dxc.On("TokenEventsDispatched", "erc1155").Return((<-chan struct{})(dispatched1)).Once()
+	dxc.On("TokenEventsDispatched", "erc1155").Return((<-chan struct{})(dispatched2)).Once()
+	acks := make(chan string, 2)
+	wsm.On("Close").Return()
+	wsm.On("Receive").Return((<-chan []byte)(r))
+	wsm.On("Send", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		acks <- string(args[1].([]byte))
+	})
+	go h.eventLoop()
+
+	r <- []byte(`{"id":"1"}`)
+	r <- []byte(`{"id":"2"}`)
+	// Acks are sent in order, once the events have been dispatched
+	close(dispatched2)
+	close(dispatched1)
+	assert.Equal(t, `{"data":{"id":"1"},"event":"ack"}`, <-acks)
+	assert.Equal(t, `{"data":{"id":"2"},"event":"ack"}`, <-acks)
+}
+
+func TestEventLoopTokenEventRemovedFail(t *testing.T) {
+	dxc := &tokenmocks.Callbacks{}
+	wsm := &wsmocks.WSClient{}
+	h := &FFTokens{
+		ctx:       context.Background(),
+		callbacks: dxc,
+		wsconn:    wsm,
+	}
+	r := make(chan []byte, 1)
+	r <- []byte(`{"id":"1","event":"token-transfer","data":{"id":"000000000010/000020/000030","removed":true}}`)
+	dxc.On("TokenEventRemoved", h, mock.Anything).Return(fmt.Errorf("pop"))
+	wsm.On("Close").Return()
+	wsm.On("Receive").Return((<-chan []byte)(r))
+	h.eventLoop() // we're simply looking for it exiting
+}
+
+func TestEventLoopTokenEventRemoved(t *testing.T) {
+	h, toServer, fromServer, _, done := newTestFFTokens(t)
+	defer done()
+
+	err := h.Start()
+	assert.NoError(t, err)
+
+	mcb := h.callbacks.(*tokenmocks.Callbacks)
+	mcb.On("TokenEventRemoved", h, mock.MatchedBy(func(e *blockchain.Event) bool {
+		return e.Source == "fftokens:testtokens" && e.ProtocolID == "000000000010/000020/000030" && e.BlockchainTXID == "0xffffeeee"
+	})).Return(nil)
+
+	fromServer <- fftypes.JSONObject{
+		"id":    "1",
+		"event": "token-transfer",
+		"data": fftypes.JSONObject{
+			"id":      "000000000010/000020/000030",
+			"removed": true,
+			"transaction": fftypes.JSONObject{
+				"transactionHash": "0xffffeeee",
+			},
+		},
+	}.String()
+	msg := <-toServer
+	assert.Equal(t, `{"data":{"id":"1"},"event":"ack"}`, string(msg))
+
+	// missing data
+	fromServer <- fftypes.JSONObject{
+		"id":    "2",
+		"event": "token-transfer",
+		"data": fftypes.JSONObject{
+			"removed": true,
+		},
+	}.String()
+	msg = <-toServer
+	assert.Equal(t, `{"data":{"id":"2"},"event":"ack"}`, string(msg))
+
+	mcb.AssertExpectations(t)
+}
+
 func TestEventLoopClosedContext(t *testing.T) {
 	dxc := &tokenmocks.Callbacks{}
 	wsm := &wsmocks.WSClient{}
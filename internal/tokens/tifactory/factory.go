@@ -33,6 +33,7 @@ func InitPrefix(prefix config.PrefixArray) {
 	prefix.AddKnownKey(tokens.TokensConfigConnector)
 	prefix.AddKnownKey(tokens.TokensConfigPlugin)
 	prefix.AddKnownKey(tokens.TokensConfigName)
+	prefix.AddKnownKey(tokens.TokensConfigBlockchain)
 	for _, plugin := range pluginsByName {
 		// Accept a superset of configs allowed by all plugins
 		plugin().InitPrefix(prefix)
@@ -0,0 +1,257 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/karlseguin/ccache"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// ExporterOTLP exports spans over OTLP/HTTP to a collector
+	ExporterOTLP = "otlp"
+	// ExporterFile appends spans as JSON to a local file
+	ExporterFile = "file"
+
+	tracerName = "github.com/hyperledger/firefly"
+)
+
+var (
+	enabled   bool
+	provider  *sdktrace.TracerProvider
+	traceFile *os.File
+	links     *ccache.Cache
+	linkTTL   time.Duration
+)
+
+// Init configures the global OpenTelemetry tracer provider, and W3C trace context propagation, from the static configuration.
+// When tracing is disabled the default no-op tracer is left in place, so creating spans costs almost nothing.
+func Init(ctx context.Context) error {
+	if !config.GetBool(config.TracingEnabled) {
+		return nil
+	}
+
+	exporter, err := newExporter(ctx)
+	if err != nil {
+		return err
+	}
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String(config.GetString(config.TracingServiceName)),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	links = ccache.New(ccache.Configure().MaxSize(config.GetInt64(config.TracingLinkCacheLimit)))
+	linkTTL = config.GetDuration(config.TracingLinkCacheTTL)
+	enabled = true
+	log.L(ctx).Infof("Tracing enabled exporter=%s", config.GetString(config.TracingExporter))
+	return nil
+}
+
+func newExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	exporterType := config.GetString(config.TracingExporter)
+	switch exporterType {
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(config.GetString(config.TracingOTLPEndpoint)),
+		}
+		if config.GetBool(config.TracingOTLPInsecure) {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(ctx, opts...)
+		if err != nil {
+			return nil, i18n.WrapError(ctx, err, i18n.MsgTracingExporterFailed, exporterType)
+		}
+		return exporter, nil
+	case ExporterFile:
+		path := config.GetString(config.TracingFilePath)
+		if path == "" {
+			return nil, i18n.NewError(ctx, i18n.MsgTracingFilePathRequired)
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, i18n.WrapError(ctx, err, i18n.MsgTracingFileOpenFailed, path)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			_ = f.Close()
+			return nil, i18n.WrapError(ctx, err, i18n.MsgTracingExporterFailed, exporterType)
+		}
+		traceFile = f
+		return exporter, nil
+	default:
+		return nil, i18n.NewError(ctx, i18n.MsgTracingInvalidExporter, exporterType)
+	}
+}
+
+// Shutdown flushes any buffered spans to the exporter, and restores the no-op tracer
+func Shutdown(ctx context.Context) {
+	if provider != nil {
+		if err := provider.Shutdown(ctx); err != nil {
+			log.L(ctx).Warnf("Failed to flush spans on shutdown: %s", err)
+		}
+	}
+	if traceFile != nil {
+		_ = traceFile.Close()
+	}
+	otel.SetTracerProvider(trace.NewNoopTracerProvider())
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	enabled = false
+	provider = nil
+	traceFile = nil
+	links = nil
+}
+
+// IsEnabled returns true if spans are being exported
+func IsEnabled() bool {
+	return enabled
+}
+
+// StartSpan starts a span as a child of any span in the context. The spans previously recorded against each of
+// the supplied IDs are added as links, which is how the asynchronous stages of processing a message are tied together.
+func StartSpan(ctx context.Context, name string, linkIDs []*fftypes.UUID, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if !enabled {
+		// The context is passed through untouched, and the span is a no-op
+		return ctx, trace.SpanFromContext(context.Background())
+	}
+	opts := []trace.SpanStartOption{trace.WithAttributes(attrs...)}
+	for _, id := range linkIDs {
+		if id == nil {
+			continue
+		}
+		if item := links.Get(id.String()); item != nil && !item.Expired() {
+			opts = append(opts, trace.WithLinks(trace.Link{SpanContext: item.Value().(trace.SpanContext)}))
+		}
+	}
+	return otel.Tracer(tracerName).Start(ctx, name, opts...)
+}
+
+// EndSpan records the error (if any) on the span, and ends it
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Record remembers the span in the context against each of the supplied message, batch or operation IDs,
+// so that spans started later for the same object can link back to it
+func Record(ctx context.Context, ids ...*fftypes.UUID) {
+	if !enabled {
+		return
+	}
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	for _, id := range ids {
+		if id != nil {
+			links.Set(id.String(), sc, linkTTL)
+		}
+	}
+}
+
+// StartHTTPServerSpan starts the span for an inbound API request, as a child of any W3C trace context in the request headers
+func StartHTTPServerSpan(ctx context.Context, req *http.Request, route string) (context.Context, trace.Span) {
+	if !enabled {
+		return ctx, trace.SpanFromContext(context.Background())
+	}
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(req.Header))
+	return otel.Tracer(tracerName).Start(ctx, req.Method+" "+route,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.HTTPMethodKey.String(req.Method),
+			semconv.HTTPRouteKey.String(route),
+			semconv.HTTPTargetKey.String(req.URL.Path),
+		),
+	)
+}
+
+// EndHTTPServerSpan records the response status on an inbound API request span, and ends it
+func EndHTTPServerSpan(span trace.Span, status int, err error) {
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(status))
+	EndSpan(span, err)
+}
+
+// InjectHTTP adds the W3C trace context of the span in the context to the headers of an outbound request
+func InjectHTTP(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+func idAttribute(key string, id *fftypes.UUID) attribute.KeyValue {
+	if id == nil {
+		return attribute.String(key, "")
+	}
+	return attribute.String(key, id.String())
+}
+
+// MessageID is the span attribute for a message ID
+func MessageID(id *fftypes.UUID) attribute.KeyValue {
+	return idAttribute("firefly.message.id", id)
+}
+
+// BatchID is the span attribute for a batch ID
+func BatchID(id *fftypes.UUID) attribute.KeyValue {
+	return idAttribute("firefly.batch.id", id)
+}
+
+// OperationID is the span attribute for an operation ID
+func OperationID(id *fftypes.UUID) attribute.KeyValue {
+	return idAttribute("firefly.operation.id", id)
+}
+
+// OperationStatus is the span attribute for the status of an operation
+func OperationStatus(status fftypes.OpStatus) attribute.KeyValue {
+	return attribute.String("firefly.operation.status", string(status))
+}
+
+// EventID is the span attribute for an event ID
+func EventID(id *fftypes.UUID) attribute.KeyValue {
+	return idAttribute("firefly.event.id", id)
+}
+
+// Subscription is the span attribute for the name of a subscription
+func Subscription(name string) attribute.KeyValue {
+	return attribute.String("firefly.subscription", name)
+}
+
+// Namespace is the span attribute for a namespace
+func Namespace(ns string) attribute.KeyValue {
+	return attribute.String("firefly.namespace", ns)
+}
@@ -0,0 +1,171 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestFileTracing(t *testing.T) (string, func()) {
+	config.Reset()
+	dir, err := ioutil.TempDir("", "tracing")
+	assert.NoError(t, err)
+	path := filepath.Join(dir, "spans.json")
+	config.Set(config.TracingEnabled, true)
+	config.Set(config.TracingExporter, ExporterFile)
+	config.Set(config.TracingFilePath, path)
+	err = Init(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, IsEnabled())
+	return path, func() {
+		Shutdown(context.Background())
+		os.RemoveAll(dir)
+	}
+}
+
+func TestInitDisabled(t *testing.T) {
+	config.Reset()
+	err := Init(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, IsEnabled())
+
+	// Spans are no-ops, and records are ignored
+	ctx, span := StartSpan(context.Background(), "test", []*fftypes.UUID{fftypes.NewUUID()})
+	Record(ctx, fftypes.NewUUID())
+	EndSpan(span, nil)
+	assert.False(t, span.SpanContext().IsValid())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/status", nil)
+	_, span = StartHTTPServerSpan(ctx, req, "status")
+	EndHTTPServerSpan(span, 200, nil)
+	assert.False(t, span.SpanContext().IsValid())
+}
+
+func TestInitOTLP(t *testing.T) {
+	config.Reset()
+	config.Set(config.TracingEnabled, true)
+	config.Set(config.TracingOTLPInsecure, true)
+	err := Init(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, IsEnabled())
+	Shutdown(context.Background())
+	assert.False(t, IsEnabled())
+}
+
+func TestInitBadExporter(t *testing.T) {
+	config.Reset()
+	config.Set(config.TracingEnabled, true)
+	config.Set(config.TracingExporter, "wrong")
+	err := Init(context.Background())
+	assert.Regexp(t, "FF10423", err)
+}
+
+func TestInitFileMissingPath(t *testing.T) {
+	config.Reset()
+	config.Set(config.TracingEnabled, true)
+	config.Set(config.TracingExporter, ExporterFile)
+	err := Init(context.Background())
+	assert.Regexp(t, "FF10424", err)
+}
+
+func TestInitFileBadPath(t *testing.T) {
+	config.Reset()
+	config.Set(config.TracingEnabled, true)
+	config.Set(config.TracingExporter, ExporterFile)
+	config.Set(config.TracingFilePath, "/does/not/exist/spans.json")
+	err := Init(context.Background())
+	assert.Regexp(t, "FF10425", err)
+}
+
+func TestLinkedSpansWrittenToFile(t *testing.T) {
+	path, done := newTestFileTracing(t)
+
+	msgID := fftypes.NewUUID()
+	ctx, msgSpan := StartSpan(context.Background(), "send", nil, MessageID(msgID), Namespace("ns1"))
+	Record(ctx, msgID, nil)
+	EndSpan(msgSpan, nil)
+
+	// An unrelated span later in the pipeline links back to the message span
+	_, batchSpan := StartSpan(context.Background(), "batch", []*fftypes.UUID{msgID, fftypes.NewUUID(), nil}, BatchID(nil))
+	EndSpan(batchSpan, fmt.Errorf("pop"))
+
+	roBatchSpan := batchSpan.(sdktrace.ReadOnlySpan)
+	assert.Len(t, roBatchSpan.Links(), 1)
+	assert.Equal(t, msgSpan.SpanContext(), roBatchSpan.Links()[0].SpanContext)
+	assert.NotEqual(t, msgSpan.SpanContext().TraceID(), batchSpan.SpanContext().TraceID())
+
+	defer done()
+	Shutdown(context.Background())
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), msgID.String())
+	assert.Contains(t, string(b), "pop")
+}
+
+func TestRecordNoSpan(t *testing.T) {
+	_, done := newTestFileTracing(t)
+	defer done()
+
+	msgID := fftypes.NewUUID()
+	Record(context.Background(), msgID)
+	_, span := StartSpan(context.Background(), "batch", []*fftypes.UUID{msgID})
+	defer span.End()
+	assert.Empty(t, span.(sdktrace.ReadOnlySpan).Links())
+}
+
+func TestHTTPPropagation(t *testing.T) {
+	_, done := newTestFileTracing(t)
+	defer done()
+
+	// The caller's trace context is the parent of the server span
+	callerCtx, callerSpan := StartSpan(context.Background(), "caller", nil)
+	defer callerSpan.End()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/namespaces/ns1/messages/broadcast", nil)
+	InjectHTTP(callerCtx, req.Header)
+	assert.NotEmpty(t, req.Header.Get("traceparent"))
+
+	ctx, serverSpan := StartHTTPServerSpan(context.Background(), req, "namespaces/{ns}/messages/broadcast")
+	assert.Equal(t, callerSpan.SpanContext().TraceID(), serverSpan.SpanContext().TraceID())
+	assert.Equal(t, callerSpan.SpanContext().SpanID(), serverSpan.(sdktrace.ReadOnlySpan).Parent().SpanID())
+	assert.Equal(t, trace.SpanKindServer, serverSpan.(sdktrace.ReadOnlySpan).SpanKind())
+
+	// Outbound calls made within the request carry the server span's trace context
+	outbound := http.Header{}
+	InjectHTTP(ctx, outbound)
+	assert.Contains(t, outbound.Get("traceparent"), serverSpan.SpanContext().SpanID().String())
+	EndHTTPServerSpan(serverSpan, 202, nil)
+}
+
+func TestOperationAttributes(t *testing.T) {
+	assert.Equal(t, "firefly.operation.status", string(OperationStatus(fftypes.OpStatusSucceeded).Key))
+	assert.Equal(t, "firefly.operation.id", string(OperationID(fftypes.NewUUID()).Key))
+	assert.Equal(t, "firefly.event.id", string(EventID(fftypes.NewUUID()).Key))
+	assert.Equal(t, "firefly.subscription", string(Subscription("sub1").Key))
+}
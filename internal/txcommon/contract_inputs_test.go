@@ -0,0 +1,75 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txcommon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddContractDeployInputs(t *testing.T) {
+	op := &fftypes.Operation{}
+	req := &fftypes.ContractDeployRequest{
+		Key:        "0x123",
+		Definition: fftypes.JSONAnyPtr(`[]`),
+		Contract:   fftypes.JSONAnyPtr(`"0x6080"`),
+		Input:      []interface{}{"a"},
+		API:        "api1",
+	}
+
+	err := AddContractDeployInputs(op, req)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x123", op.Input.GetString("key"))
+	assert.Equal(t, "0x6080", op.Input.GetString("contract"))
+	assert.Equal(t, "api1", op.Input.GetString("api"))
+}
+
+func TestRetrieveContractDeployInputs(t *testing.T) {
+	id := fftypes.NewUUID()
+	op := &fftypes.Operation{
+		Input: fftypes.JSONObject{
+			"key":        "0x123",
+			"definition": []interface{}{},
+			"contract":   "0x6080",
+			"input":      []interface{}{"a"},
+			"interface":  fftypes.JSONObject{"id": id.String()},
+			"api":        "api1",
+		},
+	}
+
+	req, err := RetrieveContractDeployInputs(context.Background(), op)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x123", req.Key)
+	assert.Equal(t, `"0x6080"`, req.Contract.String())
+	assert.Equal(t, []interface{}{"a"}, req.Input)
+	assert.Equal(t, id, req.Interface.ID)
+	assert.Equal(t, "api1", req.API)
+}
+
+func TestRetrieveContractDeployInputsBadInput(t *testing.T) {
+	op := &fftypes.Operation{
+		Input: fftypes.JSONObject{
+			"input": "bad",
+		},
+	}
+
+	_, err := RetrieveContractDeployInputs(context.Background(), op)
+	assert.Regexp(t, "FF10151", err)
+}
@@ -40,7 +40,7 @@ func (t *transactionHelper) EnrichEvent(ctx context.Context, event *fftypes.Even
 			return nil, err
 		}
 		e.Message = msg
-	case fftypes.EventTypeBlockchainEventReceived:
+	case fftypes.EventTypeBlockchainEventReceived, fftypes.EventTypeBlockchainEventRemoved:
 		be, err := t.database.GetBlockchainEventByID(ctx, event.Reference)
 		if err != nil {
 			return nil, err
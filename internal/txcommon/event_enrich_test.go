@@ -178,6 +178,34 @@ func TestEnrichBlockchainEventSubmitted(t *testing.T) {
 	assert.Equal(t, ref1, enriched.BlockchainEvent.ID)
 }
 
+func TestEnrichBlockchainEventRemoved(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	// Setup the IDs
+	ref1 := fftypes.NewUUID()
+	ev1 := fftypes.NewUUID()
+
+	// Setup enrichment
+	mdi.On("GetBlockchainEventByID", mock.Anything, ref1).Return(&fftypes.BlockchainEvent{
+		ID:      ref1,
+		Removed: fftypes.Now(),
+	}, nil)
+
+	event := &fftypes.Event{
+		ID:        ev1,
+		Type:      fftypes.EventTypeBlockchainEventRemoved,
+		Reference: ref1,
+	}
+
+	enriched, err := txHelper.EnrichEvent(ctx, event)
+	assert.NoError(t, err)
+	assert.Equal(t, ref1, enriched.BlockchainEvent.ID)
+	assert.NotNil(t, enriched.BlockchainEvent.Removed)
+}
+
 func TestEnrichBlockchainEventFail(t *testing.T) {
 	mdi := &databasemocks.Plugin{}
 	mdm := &datamocks.Manager{}
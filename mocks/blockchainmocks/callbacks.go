@@ -42,6 +42,20 @@ func (_m *Callbacks) BlockchainEvent(event *blockchain.EventWithSubscription) er
 	return r0
 }
 
+// BlockchainEventRemoved provides a mock function with given fields: event
+func (_m *Callbacks) BlockchainEventRemoved(event *blockchain.Event) error {
+	ret := _m.Called(event)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*blockchain.Event) error); ok {
+		r0 = rf(event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // BlockchainOpUpdate provides a mock function with given fields: operationID, txState, blockchainTXID, errorMessage, opOutput
 func (_m *Callbacks) BlockchainOpUpdate(operationID *fftypes.UUID, txState fftypes.OpStatus, blockchainTXID string, errorMessage string, opOutput fftypes.JSONObject) error {
 	ret := _m.Called(operationID, txState, blockchainTXID, errorMessage, opOutput)
@@ -55,3 +69,19 @@ func (_m *Callbacks) BlockchainOpUpdate(operationID *fftypes.UUID, txState fftyp
 
 	return r0
 }
+
+// EventsDispatched provides a mock function with given fields:
+func (_m *Callbacks) EventsDispatched() <-chan struct{} {
+	ret := _m.Called()
+
+	var r0 <-chan struct{}
+	if rf, ok := ret.Get(0).(func() <-chan struct{}); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan struct{})
+		}
+	}
+
+	return r0
+}
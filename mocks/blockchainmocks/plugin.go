@@ -62,6 +62,20 @@ func (_m *Plugin) DeleteContractListener(ctx context.Context, subscription *ffty
 	return r0
 }
 
+// DeployContract provides a mock function with given fields: ctx, operationID, signingKey, definition, contract, input
+func (_m *Plugin) DeployContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, definition *fftypes.JSONAny, contract *fftypes.JSONAny, input []interface{}) error {
+	ret := _m.Called(ctx, operationID, signingKey, definition, contract, input)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, string, *fftypes.JSONAny, *fftypes.JSONAny, []interface{}) error); ok {
+		r0 = rf(ctx, operationID, signingKey, definition, contract, input)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GenerateFFI provides a mock function with given fields: ctx, generationRequest
 func (_m *Plugin) GenerateFFI(ctx context.Context, generationRequest *fftypes.FFIGenerationRequest) (*fftypes.FFI, error) {
 	ret := _m.Called(ctx, generationRequest)
@@ -108,6 +122,20 @@ func (_m *Plugin) GetFFIParamValidator(ctx context.Context) (fftypes.FFIParamVal
 	return r0, r1
 }
 
+// HealthCheck provides a mock function with given fields: ctx
+func (_m *Plugin) HealthCheck(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Init provides a mock function with given fields: ctx, prefix, callbacks
 func (_m *Plugin) Init(ctx context.Context, prefix config.Prefix, callbacks blockchain.Callbacks) error {
 	ret := _m.Called(ctx, prefix, callbacks)
@@ -176,13 +204,13 @@ func (_m *Plugin) NormalizeSigningKey(ctx context.Context, keyRef string) (strin
 	return r0, r1
 }
 
-// QueryContract provides a mock function with given fields: ctx, location, method, input
-func (_m *Plugin) QueryContract(ctx context.Context, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}) (interface{}, error) {
-	ret := _m.Called(ctx, location, method, input)
+// QueryContract provides a mock function with given fields: ctx, location, method, input, block
+func (_m *Plugin) QueryContract(ctx context.Context, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}, block string) (interface{}, error) {
+	ret := _m.Called(ctx, location, method, input, block)
 
 	var r0 interface{}
-	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.JSONAny, *fftypes.FFIMethod, map[string]interface{}) interface{}); ok {
-		r0 = rf(ctx, location, method, input)
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.JSONAny, *fftypes.FFIMethod, map[string]interface{}, string) interface{}); ok {
+		r0 = rf(ctx, location, method, input, block)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(interface{})
@@ -190,8 +218,8 @@ func (_m *Plugin) QueryContract(ctx context.Context, location *fftypes.JSONAny,
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.JSONAny, *fftypes.FFIMethod, map[string]interface{}) error); ok {
-		r1 = rf(ctx, location, method, input)
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.JSONAny, *fftypes.FFIMethod, map[string]interface{}, string) error); ok {
+		r1 = rf(ctx, location, method, input, block)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -227,6 +255,20 @@ func (_m *Plugin) SubmitBatchPin(ctx context.Context, operationID *fftypes.UUID,
 	return r0
 }
 
+// ValidateContractDeploy provides a mock function with given fields: ctx, definition, contract
+func (_m *Plugin) ValidateContractDeploy(ctx context.Context, definition *fftypes.JSONAny, contract *fftypes.JSONAny) error {
+	ret := _m.Called(ctx, definition, contract)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.JSONAny, *fftypes.JSONAny) error); ok {
+		r0 = rf(ctx, definition, contract)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // VerifierType provides a mock function with given fields:
 func (_m *Plugin) VerifierType() fftypes.FFEnum {
 	ret := _m.Called()
@@ -100,6 +100,29 @@ func (_m *Manager) DeleteContractListenerByNameOrID(ctx context.Context, ns stri
 	return r0
 }
 
+// DeployContract provides a mock function with given fields: ctx, ns, req
+func (_m *Manager) DeployContract(ctx context.Context, ns string, req *fftypes.ContractDeployRequest) (*fftypes.ContractDeployResponse, error) {
+	ret := _m.Called(ctx, ns, req)
+
+	var r0 *fftypes.ContractDeployResponse
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.ContractDeployRequest) *fftypes.ContractDeployResponse); ok {
+		r0 = rf(ctx, ns, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.ContractDeployResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.ContractDeployRequest) error); ok {
+		r1 = rf(ctx, ns, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GenerateFFI provides a mock function with given fields: ctx, ns, generationRequest
 func (_m *Manager) GenerateFFI(ctx context.Context, ns string, generationRequest *fftypes.FFIGenerationRequest) (*fftypes.FFI, error) {
 	ret := _m.Called(ctx, ns, generationRequest)
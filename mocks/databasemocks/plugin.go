@@ -35,6 +35,20 @@ func (_m *Plugin) Capabilities() *database.Capabilities {
 	return r0
 }
 
+// DeleteBatch provides a mock function with given fields: ctx, batchID
+func (_m *Plugin) DeleteBatch(ctx context.Context, batchID *fftypes.UUID) error {
+	ret := _m.Called(ctx, batchID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) error); ok {
+		r0 = rf(ctx, batchID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteBlob provides a mock function with given fields: ctx, sequence
 func (_m *Plugin) DeleteBlob(ctx context.Context, sequence int64) error {
 	ret := _m.Called(ctx, sequence)
@@ -49,6 +63,20 @@ func (_m *Plugin) DeleteBlob(ctx context.Context, sequence int64) error {
 	return r0
 }
 
+// DeleteBlockchainEvent provides a mock function with given fields: ctx, id
+func (_m *Plugin) DeleteBlockchainEvent(ctx context.Context, id *fftypes.UUID) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteConfigRecord provides a mock function with given fields: ctx, key
 func (_m *Plugin) DeleteConfigRecord(ctx context.Context, key string) error {
 	ret := _m.Called(ctx, key)
@@ -77,6 +105,90 @@ func (_m *Plugin) DeleteContractListenerByID(ctx context.Context, id *fftypes.UU
 	return r0
 }
 
+// DeleteData provides a mock function with given fields: ctx, dataID
+func (_m *Plugin) DeleteData(ctx context.Context, dataID *fftypes.UUID) error {
+	ret := _m.Called(ctx, dataID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) error); ok {
+		r0 = rf(ctx, dataID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteDeadLetter provides a mock function with given fields: ctx, id
+func (_m *Plugin) DeleteDeadLetter(ctx context.Context, id *fftypes.UUID) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteDeadLetters provides a mock function with given fields: ctx, subscriptionID
+func (_m *Plugin) DeleteDeadLetters(ctx context.Context, subscriptionID *fftypes.UUID) error {
+	ret := _m.Called(ctx, subscriptionID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) error); ok {
+		r0 = rf(ctx, subscriptionID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteEvent provides a mock function with given fields: ctx, eventID
+func (_m *Plugin) DeleteEvent(ctx context.Context, eventID *fftypes.UUID) error {
+	ret := _m.Called(ctx, eventID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) error); ok {
+		r0 = rf(ctx, eventID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteInflightRequest provides a mock function with given fields: ctx, id
+func (_m *Plugin) DeleteInflightRequest(ctx context.Context, id *fftypes.UUID) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteMessage provides a mock function with given fields: ctx, msgID
+func (_m *Plugin) DeleteMessage(ctx context.Context, msgID *fftypes.UUID) error {
+	ret := _m.Called(ctx, msgID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) error); ok {
+		r0 = rf(ctx, msgID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteNamespace provides a mock function with given fields: ctx, id
 func (_m *Plugin) DeleteNamespace(ctx context.Context, id *fftypes.UUID) error {
 	ret := _m.Called(ctx, id)
@@ -133,6 +245,20 @@ func (_m *Plugin) DeleteOffset(ctx context.Context, t fftypes.FFEnum, name strin
 	return r0
 }
 
+// DeleteOperation provides a mock function with given fields: ctx, opID
+func (_m *Plugin) DeleteOperation(ctx context.Context, opID *fftypes.UUID) error {
+	ret := _m.Called(ctx, opID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) error); ok {
+		r0 = rf(ctx, opID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeletePin provides a mock function with given fields: ctx, sequence
 func (_m *Plugin) DeletePin(ctx context.Context, sequence int64) error {
 	ret := _m.Called(ctx, sequence)
@@ -748,6 +874,61 @@ func (_m *Plugin) GetDatatypes(ctx context.Context, filter database.Filter) ([]*
 	return r0, r1, r2
 }
 
+// GetDeadLetterByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetDeadLetterByID(ctx context.Context, id *fftypes.UUID) (*fftypes.DeadLetter, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *fftypes.DeadLetter
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.DeadLetter); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.DeadLetter)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDeadLetters provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetDeadLetters(ctx context.Context, filter database.Filter) ([]*fftypes.DeadLetter, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*fftypes.DeadLetter
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.DeadLetter); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.DeadLetter)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetEventByID provides a mock function with given fields: ctx, id
 func (_m *Plugin) GetEventByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Event, error) {
 	ret := _m.Called(ctx, id)
@@ -1170,6 +1351,61 @@ func (_m *Plugin) GetIdentityByName(ctx context.Context, iType fftypes.FFEnum, n
 	return r0, r1
 }
 
+// GetInflightRequestByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetInflightRequestByID(ctx context.Context, id *fftypes.UUID) (*fftypes.InflightRequest, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *fftypes.InflightRequest
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.InflightRequest); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.InflightRequest)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetInflightRequests provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetInflightRequests(ctx context.Context, filter database.Filter) ([]*fftypes.InflightRequest, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*fftypes.InflightRequest
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.InflightRequest); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.InflightRequest)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetMessageByID provides a mock function with given fields: ctx, id
 func (_m *Plugin) GetMessageByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Message, error) {
 	ret := _m.Called(ctx, id)
@@ -2197,6 +2433,20 @@ func (_m *Plugin) GetVerifiers(ctx context.Context, filter database.Filter) ([]*
 	return r0, r1, r2
 }
 
+// HealthCheck provides a mock function with given fields: ctx
+func (_m *Plugin) HealthCheck(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Init provides a mock function with given fields: ctx, prefix, callbacks
 func (_m *Plugin) Init(ctx context.Context, prefix config.Prefix, callbacks database.Callbacks) error {
 	ret := _m.Called(ctx, prefix, callbacks)
@@ -2258,6 +2508,20 @@ func (_m *Plugin) InsertDataArray(ctx context.Context, data fftypes.DataArray) e
 	return r0
 }
 
+// InsertDeadLetter provides a mock function with given fields: ctx, deadLetter
+func (_m *Plugin) InsertDeadLetter(ctx context.Context, deadLetter *fftypes.DeadLetter) error {
+	ret := _m.Called(ctx, deadLetter)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.DeadLetter) error); ok {
+		r0 = rf(ctx, deadLetter)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InsertEvent provides a mock function with given fields: ctx, data
 func (_m *Plugin) InsertEvent(ctx context.Context, data *fftypes.Event) error {
 	ret := _m.Called(ctx, data)
@@ -2272,6 +2536,20 @@ func (_m *Plugin) InsertEvent(ctx context.Context, data *fftypes.Event) error {
 	return r0
 }
 
+// InsertInflightRequest provides a mock function with given fields: ctx, req
+func (_m *Plugin) InsertInflightRequest(ctx context.Context, req *fftypes.InflightRequest) error {
+	ret := _m.Called(ctx, req)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.InflightRequest) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InsertMessages provides a mock function with given fields: ctx, messages
 func (_m *Plugin) InsertMessages(ctx context.Context, messages []*fftypes.Message) error {
 	ret := _m.Called(ctx, messages)
@@ -2370,6 +2648,20 @@ func (_m *Plugin) ReplaceMessage(ctx context.Context, message *fftypes.Message)
 	return r0
 }
 
+// ResolveInflightRequest provides a mock function with given fields: ctx, ns, id, reply, failed
+func (_m *Plugin) ResolveInflightRequest(ctx context.Context, ns string, id *fftypes.UUID, reply *fftypes.UUID, failed bool) error {
+	ret := _m.Called(ctx, ns, id, reply, failed)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID, *fftypes.UUID, bool) error); ok {
+		r0 = rf(ctx, ns, id, reply, failed)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ResolveOperation provides a mock function with given fields: ctx, id, status, errorMsg, output
 func (_m *Plugin) ResolveOperation(ctx context.Context, id *fftypes.UUID, status fftypes.OpStatus, errorMsg string, output fftypes.JSONObject) error {
 	ret := _m.Called(ctx, id, status, errorMsg, output)
@@ -2412,6 +2704,20 @@ func (_m *Plugin) UpdateBatch(ctx context.Context, id *fftypes.UUID, update data
 	return r0
 }
 
+// UpdateBlockchainEvent provides a mock function with given fields: ctx, id, update
+func (_m *Plugin) UpdateBlockchainEvent(ctx context.Context, id *fftypes.UUID, update database.Update) error {
+	ret := _m.Called(ctx, id, update)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, database.Update) error); ok {
+		r0 = rf(ctx, id, update)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateData provides a mock function with given fields: ctx, id, update
 func (_m *Plugin) UpdateData(ctx context.Context, id *fftypes.UUID, update database.Update) error {
 	ret := _m.Called(ctx, id, update)
@@ -2440,6 +2746,20 @@ func (_m *Plugin) UpdateDatatype(ctx context.Context, id *fftypes.UUID, update d
 	return r0
 }
 
+// UpdateDeadLetter provides a mock function with given fields: ctx, id, update
+func (_m *Plugin) UpdateDeadLetter(ctx context.Context, id *fftypes.UUID, update database.Update) error {
+	ret := _m.Called(ctx, id, update)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, database.Update) error); ok {
+		r0 = rf(ctx, id, update)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateEvent provides a mock function with given fields: ctx, id, update
 func (_m *Plugin) UpdateEvent(ctx context.Context, id *fftypes.UUID, update database.Update) error {
 	ret := _m.Called(ctx, id, update)
@@ -81,6 +81,20 @@ func (_m *Plugin) CheckBLOBReceived(ctx context.Context, peerID string, ns strin
 	return r0, r1, r2
 }
 
+// DeleteBLOB provides a mock function with given fields: ctx, payloadRef
+func (_m *Plugin) DeleteBLOB(ctx context.Context, payloadRef string) error {
+	ret := _m.Called(ctx, payloadRef)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, payloadRef)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DownloadBLOB provides a mock function with given fields: ctx, payloadRef
 func (_m *Plugin) DownloadBLOB(ctx context.Context, payloadRef string) (io.ReadCloser, error) {
 	ret := _m.Called(ctx, payloadRef)
@@ -127,6 +141,20 @@ func (_m *Plugin) GetEndpointInfo(ctx context.Context) (fftypes.JSONObject, erro
 	return r0, r1
 }
 
+// HealthCheck provides a mock function with given fields: ctx
+func (_m *Plugin) HealthCheck(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Init provides a mock function with given fields: ctx, prefix, nodes, callbacks
 func (_m *Plugin) Init(ctx context.Context, prefix config.Prefix, nodes []fftypes.JSONObject, callbacks dataexchange.Callbacks) error {
 	ret := _m.Called(ctx, prefix, nodes, callbacks)
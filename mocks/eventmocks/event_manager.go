@@ -79,6 +79,20 @@ func (_m *EventManager) BlockchainEvent(event *blockchain.EventWithSubscription)
 	return r0
 }
 
+// BlockchainEventRemoved provides a mock function with given fields: event
+func (_m *EventManager) BlockchainEventRemoved(event *blockchain.Event) error {
+	ret := _m.Called(event)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*blockchain.Event) error); ok {
+		r0 = rf(event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ChangeEvents provides a mock function with given fields:
 func (_m *EventManager) ChangeEvents() chan<- *fftypes.ChangeEvent {
 	ret := _m.Called()
@@ -139,6 +153,29 @@ func (_m *EventManager) DeletedSubscriptions() chan<- *fftypes.UUID {
 	return r0
 }
 
+// GetSubscriptionStatus provides a mock function with given fields: ctx, subDef
+func (_m *EventManager) GetSubscriptionStatus(ctx context.Context, subDef *fftypes.Subscription) (*fftypes.SubscriptionStatus, error) {
+	ret := _m.Called(ctx, subDef)
+
+	var r0 *fftypes.SubscriptionStatus
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Subscription) *fftypes.SubscriptionStatus); ok {
+		r0 = rf(ctx, subDef)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.SubscriptionStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.Subscription) error); ok {
+		r1 = rf(ctx, subDef)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // MessageReceived provides a mock function with given fields: dx, peerID, data
 func (_m *EventManager) MessageReceived(dx dataexchange.Plugin, peerID string, data []byte) (string, error) {
 	ret := _m.Called(dx, peerID, data)
@@ -222,6 +259,43 @@ func (_m *EventManager) OperationUpdate(plugin fftypes.Named, operationID *fftyp
 	return r0
 }
 
+// ReplayDeadLetter provides a mock function with given fields: ctx, deadLetter
+func (_m *EventManager) ReplayDeadLetter(ctx context.Context, deadLetter *fftypes.DeadLetter) error {
+	ret := _m.Called(ctx, deadLetter)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.DeadLetter) error); ok {
+		r0 = rf(ctx, deadLetter)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ResetSubscriptionOffset provides a mock function with given fields: ctx, subDef, reset
+func (_m *EventManager) ResetSubscriptionOffset(ctx context.Context, subDef *fftypes.Subscription, reset *fftypes.SubscriptionOffsetReset) (*fftypes.Offset, error) {
+	ret := _m.Called(ctx, subDef, reset)
+
+	var r0 *fftypes.Offset
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Subscription, *fftypes.SubscriptionOffsetReset) *fftypes.Offset); ok {
+		r0 = rf(ctx, subDef, reset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Offset)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.Subscription, *fftypes.SubscriptionOffsetReset) error); ok {
+		r1 = rf(ctx, subDef, reset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Start provides a mock function with given fields:
 func (_m *EventManager) Start() error {
 	ret := _m.Called()
@@ -19,11 +19,21 @@ func (_m *Callbacks) ConnnectionClosed(connID string) {
 	_m.Called(connID)
 }
 
+// CumulativeAck provides a mock function with given fields: connID, subID, sequence
+func (_m *Callbacks) CumulativeAck(connID string, subID *fftypes.UUID, sequence int64) {
+	_m.Called(connID, subID, sequence)
+}
+
 // DeliveryResponse provides a mock function with given fields: connID, inflight
 func (_m *Callbacks) DeliveryResponse(connID string, inflight *fftypes.EventDeliveryResponse) {
 	_m.Called(connID, inflight)
 }
 
+// DeliveryRetry provides a mock function with given fields: connID, sub
+func (_m *Callbacks) DeliveryRetry(connID string, sub *fftypes.SubscriptionRef) {
+	_m.Called(connID, sub)
+}
+
 // EphemeralSubscription provides a mock function with given fields: connID, namespace, filter, options
 func (_m *Callbacks) EphemeralSubscription(connID string, namespace string, filter *fftypes.SubscriptionFilter, options *fftypes.SubscriptionOptions) error {
 	ret := _m.Called(connID, namespace, filter, options)
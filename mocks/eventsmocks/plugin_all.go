@@ -40,13 +40,13 @@ func (_m *PluginAll) ChangeEvent(connID string, ce *fftypes.ChangeEvent) {
 	_m.Called(connID, ce)
 }
 
-// DeliveryRequest provides a mock function with given fields: connID, sub, event, data
-func (_m *PluginAll) DeliveryRequest(connID string, sub *fftypes.Subscription, event *fftypes.EventDelivery, data fftypes.DataArray) error {
-	ret := _m.Called(connID, sub, event, data)
+// DeliveryRequest provides a mock function with given fields: ctx, connID, sub, event, data
+func (_m *PluginAll) DeliveryRequest(ctx context.Context, connID string, sub *fftypes.Subscription, event *fftypes.EventDelivery, data fftypes.DataArray) error {
+	ret := _m.Called(ctx, connID, sub, event, data)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, *fftypes.Subscription, *fftypes.EventDelivery, fftypes.DataArray) error); ok {
-		r0 = rf(connID, sub, event, data)
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.Subscription, *fftypes.EventDelivery, fftypes.DataArray) error); ok {
+		r0 = rf(ctx, connID, sub, event, data)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -25,6 +25,21 @@ func (_m *Manager) CountBatchPin() {
 	_m.Called()
 }
 
+// CountDeliveryRetry provides a mock function with given fields: ns, name, transport
+func (_m *Manager) CountDeliveryRetry(ns string, name string, transport string) {
+	_m.Called(ns, name, transport)
+}
+
+// CountPruned provides a mock function with given fields: ns, collection, count
+func (_m *Manager) CountPruned(ns string, collection string, count int) {
+	_m.Called(ns, collection, count)
+}
+
+// DeleteSubscriptionStatus provides a mock function with given fields: ns, name
+func (_m *Manager) DeleteSubscriptionStatus(ns string, name string) {
+	_m.Called(ns, name)
+}
+
 // DeleteTime provides a mock function with given fields: id
 func (_m *Manager) DeleteTime(id string) {
 	_m.Called(id)
@@ -82,6 +97,11 @@ func (_m *Manager) Start() error {
 	return r0
 }
 
+// SubscriptionStatus provides a mock function with given fields: ns, name, status
+func (_m *Manager) SubscriptionStatus(ns string, name string, status *fftypes.SubscriptionStatus) {
+	_m.Called(ns, name, status)
+}
+
 // TransferConfirmed provides a mock function with given fields: transfer
 func (_m *Manager) TransferConfirmed(transfer *fftypes.TokenTransfer) {
 	_m.Called(transfer)
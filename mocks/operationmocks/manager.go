@@ -30,6 +30,11 @@ func (_m *Manager) AddOrReuseOperation(ctx context.Context, op *fftypes.Operatio
 	return r0
 }
 
+// OperationFailed provides a mock function with given fields: ctx, opID, errorMessage
+func (_m *Manager) OperationFailed(ctx context.Context, opID *fftypes.UUID, errorMessage string) {
+	_m.Called(ctx, opID, errorMessage)
+}
+
 // PrepareOperation provides a mock function with given fields: ctx, op
 func (_m *Manager) PrepareOperation(ctx context.Context, op *fftypes.Operation) (*fftypes.PreparedOperation, error) {
 	ret := _m.Called(ctx, op)
@@ -94,3 +99,22 @@ func (_m *Manager) RunOperation(ctx context.Context, op *fftypes.PreparedOperati
 
 	return r0
 }
+
+// Start provides a mock function with given fields:
+func (_m *Manager) Start() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// WaitStop provides a mock function with given fields:
+func (_m *Manager) WaitStop() {
+	_m.Called()
+}
@@ -3,6 +3,7 @@
 package orchestratormocks
 
 import (
+	archive "github.com/hyperledger/firefly/internal/archive"
 	assets "github.com/hyperledger/firefly/internal/assets"
 	batch "github.com/hyperledger/firefly/internal/batch"
 
@@ -36,6 +37,20 @@ type Orchestrator struct {
 	mock.Mock
 }
 
+// Archive provides a mock function with given fields:
+func (_m *Orchestrator) Archive() archive.Manager {
+	ret := _m.Called()
+
+	var r0 archive.Manager
+	if rf, ok := ret.Get(0).(func() archive.Manager); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(archive.Manager)
+	}
+
+	return r0
+}
+
 // Assets provides a mock function with given fields:
 func (_m *Orchestrator) Assets() assets.Manager {
 	ret := _m.Called()
@@ -543,6 +558,61 @@ func (_m *Orchestrator) GetDatatypes(ctx context.Context, ns string, filter data
 	return r0, r1, r2
 }
 
+// GetDeadLetterByID provides a mock function with given fields: ctx, ns, subID, id
+func (_m *Orchestrator) GetDeadLetterByID(ctx context.Context, ns string, subID string, id string) (*fftypes.DeadLetter, error) {
+	ret := _m.Called(ctx, ns, subID, id)
+
+	var r0 *fftypes.DeadLetter
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *fftypes.DeadLetter); ok {
+		r0 = rf(ctx, ns, subID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.DeadLetter)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, ns, subID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDeadLetters provides a mock function with given fields: ctx, ns, subID, filter
+func (_m *Orchestrator) GetDeadLetters(ctx context.Context, ns string, subID string, filter database.AndFilter) ([]*fftypes.DeadLetter, *database.FilterResult, error) {
+	ret := _m.Called(ctx, ns, subID, filter)
+
+	var r0 []*fftypes.DeadLetter
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, database.AndFilter) []*fftypes.DeadLetter); ok {
+		r0 = rf(ctx, ns, subID, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.DeadLetter)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, database.AndFilter) *database.FilterResult); ok {
+		r1 = rf(ctx, ns, subID, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, database.AndFilter) error); ok {
+		r2 = rf(ctx, ns, subID, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetEventByID provides a mock function with given fields: ctx, ns, id
 func (_m *Orchestrator) GetEventByID(ctx context.Context, ns string, id string) (*fftypes.Event, error) {
 	ret := _m.Called(ctx, ns, id)
@@ -1024,6 +1094,29 @@ func (_m *Orchestrator) GetPins(ctx context.Context, filter database.AndFilter)
 	return r0, r1, r2
 }
 
+// GetReadiness provides a mock function with given fields: ctx
+func (_m *Orchestrator) GetReadiness(ctx context.Context) (*fftypes.NodeHealth, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *fftypes.NodeHealth
+	if rf, ok := ret.Get(0).(func(context.Context) *fftypes.NodeHealth); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.NodeHealth)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetStatus provides a mock function with given fields: ctx
 func (_m *Orchestrator) GetStatus(ctx context.Context) (*fftypes.NodeStatus, error) {
 	ret := _m.Called(ctx)
@@ -1070,6 +1163,29 @@ func (_m *Orchestrator) GetSubscriptionByID(ctx context.Context, ns string, id s
 	return r0, r1
 }
 
+// GetSubscriptionStatus provides a mock function with given fields: ctx, ns, subID
+func (_m *Orchestrator) GetSubscriptionStatus(ctx context.Context, ns string, subID string) (*fftypes.SubscriptionStatus, error) {
+	ret := _m.Called(ctx, ns, subID)
+
+	var r0 *fftypes.SubscriptionStatus
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.SubscriptionStatus); ok {
+		r0 = rf(ctx, ns, subID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.SubscriptionStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, subID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetSubscriptions provides a mock function with given fields: ctx, ns, filter
 func (_m *Orchestrator) GetSubscriptions(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Subscription, *database.FilterResult, error) {
 	ret := _m.Called(ctx, ns, filter)
@@ -1336,6 +1452,20 @@ func (_m *Orchestrator) PrivateMessaging() privatemessaging.Manager {
 	return r0
 }
 
+// PurgeDeadLetters provides a mock function with given fields: ctx, ns, subID
+func (_m *Orchestrator) PurgeDeadLetters(ctx context.Context, ns string, subID string) error {
+	ret := _m.Called(ctx, ns, subID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, ns, subID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // PutConfigRecord provides a mock function with given fields: ctx, key, configRecord
 func (_m *Orchestrator) PutConfigRecord(ctx context.Context, key string, configRecord *fftypes.JSONAny) (*fftypes.JSONAny, error) {
 	ret := _m.Called(ctx, key, configRecord)
@@ -1359,6 +1489,29 @@ func (_m *Orchestrator) PutConfigRecord(ctx context.Context, key string, configR
 	return r0, r1
 }
 
+// ReplayDeadLetter provides a mock function with given fields: ctx, ns, subID, id
+func (_m *Orchestrator) ReplayDeadLetter(ctx context.Context, ns string, subID string, id string) (*fftypes.DeadLetter, error) {
+	ret := _m.Called(ctx, ns, subID, id)
+
+	var r0 *fftypes.DeadLetter
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *fftypes.DeadLetter); ok {
+		r0 = rf(ctx, ns, subID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.DeadLetter)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, ns, subID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RequestReply provides a mock function with given fields: ctx, ns, msg
 func (_m *Orchestrator) RequestReply(ctx context.Context, ns string, msg *fftypes.MessageInOut) (*fftypes.MessageInOut, error) {
 	ret := _m.Called(ctx, ns, msg)
@@ -1387,6 +1540,29 @@ func (_m *Orchestrator) ResetConfig(ctx context.Context) {
 	_m.Called(ctx)
 }
 
+// ResetSubscriptionOffset provides a mock function with given fields: ctx, ns, subID, reset
+func (_m *Orchestrator) ResetSubscriptionOffset(ctx context.Context, ns string, subID string, reset *fftypes.SubscriptionOffsetReset) (*fftypes.Offset, error) {
+	ret := _m.Called(ctx, ns, subID, reset)
+
+	var r0 *fftypes.Offset
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *fftypes.SubscriptionOffsetReset) *fftypes.Offset); ok {
+		r0 = rf(ctx, ns, subID, reset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Offset)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, *fftypes.SubscriptionOffsetReset) error); ok {
+		r1 = rf(ctx, ns, subID, reset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Start provides a mock function with given fields:
 func (_m *Orchestrator) Start() error {
 	ret := _m.Called()
@@ -35,6 +35,20 @@ func (_m *Plugin) Capabilities() *sharedstorage.Capabilities {
 	return r0
 }
 
+// HealthCheck provides a mock function with given fields: ctx
+func (_m *Plugin) HealthCheck(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Init provides a mock function with given fields: ctx, prefix, callbacks
 func (_m *Plugin) Init(ctx context.Context, prefix config.Prefix, callbacks sharedstorage.Callbacks) error {
 	ret := _m.Called(ctx, prefix, callbacks)
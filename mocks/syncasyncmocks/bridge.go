@@ -18,11 +18,30 @@ type Bridge struct {
 	mock.Mock
 }
 
+// InflightRequestUpdated provides a mock function with given fields: ns, id
+func (_m *Bridge) InflightRequestUpdated(ns string, id *fftypes.UUID) {
+	_m.Called(ns, id)
+}
+
 // Init provides a mock function with given fields: sysevents
 func (_m *Bridge) Init(sysevents sysmessaging.SystemEvents) {
 	_m.Called(sysevents)
 }
 
+// Start provides a mock function with given fields:
+func (_m *Bridge) Start() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // WaitForIdentity provides a mock function with given fields: ctx, ns, id, send
 func (_m *Bridge) WaitForIdentity(ctx context.Context, ns string, id *fftypes.UUID, send syncasync.RequestSender) (*fftypes.Identity, error) {
 	ret := _m.Called(ctx, ns, id, send)
@@ -160,3 +179,8 @@ func (_m *Bridge) WaitForTokenTransfer(ctx context.Context, ns string, id *fftyp
 
 	return r0, r1
 }
+
+// WaitStop provides a mock function with given fields:
+func (_m *Bridge) WaitStop() {
+	_m.Called()
+}
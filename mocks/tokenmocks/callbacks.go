@@ -3,6 +3,8 @@
 package tokenmocks
 
 import (
+	blockchain "github.com/hyperledger/firefly/pkg/blockchain"
+
 	fftypes "github.com/hyperledger/firefly/pkg/fftypes"
 	mock "github.com/stretchr/testify/mock"
 
@@ -14,6 +16,36 @@ type Callbacks struct {
 	mock.Mock
 }
 
+// TokenEventRemoved provides a mock function with given fields: plugin, event
+func (_m *Callbacks) TokenEventRemoved(plugin tokens.Plugin, event *blockchain.Event) error {
+	ret := _m.Called(plugin, event)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(tokens.Plugin, *blockchain.Event) error); ok {
+		r0 = rf(plugin, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TokenEventsDispatched provides a mock function with given fields: connector
+func (_m *Callbacks) TokenEventsDispatched(connector string) <-chan struct{} {
+	ret := _m.Called(connector)
+
+	var r0 <-chan struct{}
+	if rf, ok := ret.Get(0).(func(string) <-chan struct{}); ok {
+		r0 = rf(connector)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan struct{})
+		}
+	}
+
+	return r0
+}
+
 // TokenOpUpdate provides a mock function with given fields: plugin, operationID, txState, blockchainTXID, errorMessage, opOutput
 func (_m *Callbacks) TokenOpUpdate(plugin tokens.Plugin, operationID *fftypes.UUID, txState fftypes.OpStatus, blockchainTXID string, errorMessage string, opOutput fftypes.JSONObject) error {
 	ret := _m.Called(plugin, operationID, txState, blockchainTXID, errorMessage, opOutput)
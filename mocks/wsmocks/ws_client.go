@@ -32,6 +32,20 @@ func (_m *WSClient) Connect() error {
 	return r0
 }
 
+// Connected provides a mock function with given fields:
+func (_m *WSClient) Connected() bool {
+	ret := _m.Called()
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
 // Receive provides a mock function with given fields:
 func (_m *WSClient) Receive() <-chan []byte {
 	ret := _m.Called()
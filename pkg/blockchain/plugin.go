@@ -40,6 +40,9 @@ type Plugin interface {
 	// Capabilities returns capabilities - not called until after Init
 	Capabilities() *Capabilities
 
+	// HealthCheck verifies the plugin is connected to its backing service, returning an error describing the problem if not
+	HealthCheck(ctx context.Context) error
+
 	// VerifierType returns the verifier (key) type that is used by this blockchain
 	VerifierType() fftypes.VerifierType
 
@@ -54,8 +57,17 @@ type Plugin interface {
 	// InvokeContract submits a new transaction to be executed by custom on-chain logic
 	InvokeContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}) error
 
-	// QueryContract executes a method via custom on-chain logic and returns the result
-	QueryContract(ctx context.Context, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}) (interface{}, error)
+	// DeployContract submits a new transaction to deploy a compiled smart contract. The location of the new
+	// contract is reported as "location" in the output of the operation update, once the deployment succeeds
+	DeployContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, definition, contract *fftypes.JSONAny, input []interface{}) error
+
+	// ValidateContractDeploy checks the plugin supports deployment, and the definition and contract are valid,
+	// before any transaction is submitted for the deployment
+	ValidateContractDeploy(ctx context.Context, definition, contract *fftypes.JSONAny) error
+
+	// QueryContract executes a method via custom on-chain logic and returns the result.
+	// If block is set, the query is made against the state at that block - see Capabilities.HistoricalQuery
+	QueryContract(ctx context.Context, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}, block string) (interface{}, error)
 
 	// AddContractListener adds a new subscription to a user-specified contract and event
 	AddContractListener(ctx context.Context, subscription *fftypes.ContractListenerInput) error
@@ -70,6 +82,25 @@ type Plugin interface {
 	GenerateFFI(ctx context.Context, generationRequest *fftypes.FFIGenerationRequest) (*fftypes.FFI, error)
 }
 
+// OperationStatusQuerier is an optional interface for blockchain plugins whose connector can report the status
+// of a previously submitted operation. It allows operations to be reconciled when a receipt has been lost.
+type OperationStatusQuerier interface {
+	// GetOperationStatus returns the current status of the operation, or nil if the connector has no record of it.
+	// No record does not mean the operation failed, as it might still be in flight.
+	GetOperationStatus(ctx context.Context, operationID *fftypes.UUID) (*fftypes.OperationUpdate, error)
+}
+
+// BlockConfirmer is an optional interface for blockchain plugins that can be configured with a confirmation depth.
+// Events are held back from processing until the block containing them has the required number of blocks on top of it.
+type BlockConfirmer interface {
+	// RequiresConfirmations returns true if the plugin is configured with a confirmation depth
+	RequiresConfirmations() bool
+
+	// WaitForConfirmations blocks until the event has the required number of confirmations. It returns false if
+	// the event is no longer on the canonical chain, and only returns an error if the context is cancelled.
+	WaitForConfirmations(ctx context.Context, event *Event) (confirmed bool, err error)
+}
+
 // Callbacks is the interface provided to the blockchain plugin, to allow it to pass events back to firefly.
 //
 // Events must be delivered sequentially, such that event 2 is not delivered until the callback invoked for event 1
@@ -93,6 +124,17 @@ type Callbacks interface {
 
 	// BlockchainEvent notifies on the arrival of any event from a user-created subscription.
 	BlockchainEvent(event *EventWithSubscription) error
+
+	// BlockchainEventRemoved notifies that a previously delivered event has been removed from the chain by a reorganization.
+	// The event is identified by its Source and ProtocolID.
+	//
+	// Error should will only be returned in shutdown scenarios
+	BlockchainEventRemoved(event *Event) error
+
+	// EventsDispatched returns a channel that is closed once every event passed to the callbacks so far has been
+	// processed, including events held back waiting for confirmations. Events must not be acknowledged to the
+	// connector before then, as events that are still held back are lost if the node restarts.
+	EventsDispatched() <-chan struct{}
 }
 
 // Capabilities the supported featureset of the blockchain
@@ -101,6 +143,9 @@ type Capabilities struct {
 	// GlobalSequencer means submitting an ordered piece of data visible to all
 	// participants of the network (requires an all-participant chain)
 	GlobalSequencer bool
+
+	// HistoricalQuery means contract queries can be made against the state at a past block
+	HistoricalQuery bool
 }
 
 // TransactionStatus is the only architecturally significant thing that Firefly tracks on blockchain transactions.
@@ -0,0 +1,77 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+)
+
+// CursorInfo is set on the FilterInfo when cursor based pagination has been requested.
+// The plugin must only return items in the sequence order, and must set First and Last
+// to the sequences of the items at each end of the page it returns.
+type CursorInfo struct {
+	After  *int64
+	Before *int64
+	First  *int64
+	Last   *int64
+}
+
+// FilterCursors are the opaque cursors returned for the pages either side of the one returned
+type FilterCursors struct {
+	Next     string `json:"next,omitempty"`
+	Previous string `json:"previous,omitempty"`
+}
+
+// EncodeCursor generates an opaque cursor for a sequence
+func EncodeCursor(sequence int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(sequence, 10)))
+}
+
+func decodeCursor(ctx context.Context, cursor string) (*int64, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, i18n.NewError(ctx, i18n.MsgInvalidCursor, cursor)
+	}
+	sequence, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil || sequence < 0 {
+		return nil, i18n.NewError(ctx, i18n.MsgInvalidCursor, cursor)
+	}
+	return &sequence, nil
+}
+
+// Result builds the cursors to return for the page. If the page was empty, the
+// cursor that was supplied is returned so the caller can try again later.
+func (ci *CursorInfo) Result() *FilterCursors {
+	fc := &FilterCursors{}
+	switch {
+	case ci.First != nil && ci.Last != nil:
+		fc.Previous = EncodeCursor(*ci.First)
+		fc.Next = EncodeCursor(*ci.Last)
+	case ci.After != nil:
+		fc.Next = EncodeCursor(*ci.After)
+	case ci.Before != nil:
+		fc.Previous = EncodeCursor(*ci.Before)
+	}
+	return fc
+}
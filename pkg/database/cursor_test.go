@@ -0,0 +1,58 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	seq, err := decodeCursor(context.Background(), EncodeCursor(12345))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12345), *seq)
+
+	seq, err = decodeCursor(context.Background(), "")
+	assert.NoError(t, err)
+	assert.Nil(t, seq)
+}
+
+func TestCursorNegative(t *testing.T) {
+	_, err := decodeCursor(context.Background(), EncodeCursor(-1))
+	assert.Regexp(t, "FF10390", err)
+}
+
+func TestCursorResult(t *testing.T) {
+	var one, two int64 = 1, 2
+	fc := (&CursorInfo{First: &two, Last: &one}).Result()
+	assert.Equal(t, EncodeCursor(2), fc.Previous)
+	assert.Equal(t, EncodeCursor(1), fc.Next)
+
+	fc = (&CursorInfo{After: &one}).Result()
+	assert.Equal(t, EncodeCursor(1), fc.Next)
+	assert.Empty(t, fc.Previous)
+
+	fc = (&CursorInfo{Before: &two}).Result()
+	assert.Equal(t, EncodeCursor(2), fc.Previous)
+	assert.Empty(t, fc.Next)
+
+	fc = (&CursorInfo{}).Result()
+	assert.Empty(t, fc.Next)
+	assert.Empty(t, fc.Previous)
+}
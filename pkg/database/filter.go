@@ -47,6 +47,12 @@ type Filter interface {
 	// Request a count to be returned on the total number that match the query
 	Count(c bool) Filter
 
+	// After for cursor pagination - returns the page after the supplied cursor (empty for the first page)
+	After(cursor string) Filter
+
+	// Before for cursor pagination - returns the page before the supplied cursor
+	Before(cursor string) Filter
+
 	// Finalize completes the filter, and for the plugin to validated output structure to convert
 	Finalize() (*FilterInfo, error)
 
@@ -226,11 +232,13 @@ type FilterInfo struct {
 	Values    []FieldSerialization
 	Value     FieldSerialization
 	Children  []*FilterInfo
+	Cursor    *CursorInfo
 }
 
-// FilterResult is has additional info if requested on the query - currently only the total count
+// FilterResult is has additional info if requested on the query - the total count, and the cursors for pagination
 type FilterResult struct {
 	TotalCount *int64
+	Cursors    *FilterCursors
 }
 
 func valueString(f FieldSerialization) string {
@@ -293,6 +301,14 @@ func (f *FilterInfo) String() string {
 	if f.Count {
 		val.WriteString(" count=true")
 	}
+	if f.Cursor != nil {
+		if f.Cursor.After != nil {
+			val.WriteString(fmt.Sprintf(" after=%d", *f.Cursor.After))
+		}
+		if f.Cursor.Before != nil {
+			val.WriteString(fmt.Sprintf(" before=%d", *f.Cursor.Before))
+		}
+	}
 
 	return val.String()
 }
@@ -316,6 +332,9 @@ type filterBuilder struct {
 	count           bool
 	forceAscending  bool
 	forceDescending bool
+	cursor          bool
+	after           string
+	before          string
 }
 
 type baseFilter struct {
@@ -400,7 +419,7 @@ func (f *baseFilter) Finalize() (fi *FilterInfo, err error) {
 		}
 	}
 
-	return &FilterInfo{
+	fi = &FilterInfo{
 		Children: children,
 		Op:       f.op,
 		Field:    f.field,
@@ -410,7 +429,39 @@ func (f *baseFilter) Finalize() (fi *FilterInfo, err error) {
 		Skip:     f.fb.skip,
 		Limit:    f.fb.limit,
 		Count:    f.fb.count,
-	}, nil
+	}
+	if f.fb.cursor {
+		if err = f.finalizeCursor(fi); err != nil {
+			return nil, err
+		}
+	}
+	return fi, nil
+}
+
+func (f *baseFilter) finalizeCursor(fi *FilterInfo) (err error) {
+	if f.fb.after != "" && f.fb.before != "" {
+		return i18n.NewError(f.fb.ctx, i18n.MsgCursorAfterAndBefore)
+	}
+	if fi.Skip > 0 {
+		return i18n.NewError(f.fb.ctx, i18n.MsgCursorSkipOrSort)
+	}
+	// Cursors are only stable on the sequence, so that is the only sort we allow
+	descending := !f.fb.forceAscending
+	for i, sf := range fi.Sort {
+		if sf.Field != "sequence" {
+			return i18n.NewError(f.fb.ctx, i18n.MsgCursorSkipOrSort)
+		}
+		if i == 0 && !f.fb.forceAscending && !f.fb.forceDescending {
+			descending = sf.Descending
+		}
+	}
+	fi.Sort = []*SortField{{Field: "sequence", Descending: descending}}
+	fi.Cursor = &CursorInfo{}
+	if fi.Cursor.After, err = decodeCursor(f.fb.ctx, f.fb.after); err != nil {
+		return err
+	}
+	fi.Cursor.Before, err = decodeCursor(f.fb.ctx, f.fb.before)
+	return err
 }
 
 func (f *baseFilter) Sort(fields ...string) Filter {
@@ -445,6 +496,18 @@ func (f *baseFilter) Count(c bool) Filter {
 	return f
 }
 
+func (f *baseFilter) After(cursor string) Filter {
+	f.fb.cursor = true
+	f.fb.after = cursor
+	return f
+}
+
+func (f *baseFilter) Before(cursor string) Filter {
+	f.fb.cursor = true
+	f.fb.before = cursor
+	return f
+}
+
 func (f *baseFilter) Ascending() Filter {
 	f.fb.forceAscending = true
 	return f
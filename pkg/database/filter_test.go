@@ -342,3 +342,68 @@ func TestStringsForTypes(t *testing.T) {
 	assert.Equal(t, "t1,t2", (&ffNameArrayField{na: fftypes.FFStringArray{"t1", "t2"}}).String())
 	assert.Equal(t, "true", (&boolField{b: true}).String())
 }
+
+func TestBuildMessageFilterCursor(t *testing.T) {
+	fb := MessageQueryFactory.NewFilter(context.Background())
+	f, err := fb.Eq("namespace", "ns1").
+		Sort("sequence").
+		Limit(25).
+		After(EncodeCursor(12345)).
+		Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, "namespace == 'ns1' sort=sequence limit=25 after=12345", f.String())
+	assert.Equal(t, int64(12345), *f.Cursor.After)
+	assert.Nil(t, f.Cursor.Before)
+}
+
+func TestBuildMessageFilterCursorDefaultDescending(t *testing.T) {
+	fb := MessageQueryFactory.NewFilter(context.Background())
+	f, err := fb.And().
+		Before(EncodeCursor(12345)).
+		Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, " sort=-sequence before=12345", f.String())
+}
+
+func TestBuildMessageFilterCursorForceDirection(t *testing.T) {
+	fb := MessageQueryFactory.NewFilter(context.Background())
+	f, err := fb.And().Sort("-sequence").Ascending().After("").Finalize()
+	assert.NoError(t, err)
+	assert.False(t, f.Sort[0].Descending)
+	assert.Nil(t, f.Cursor.After)
+
+	fb = MessageQueryFactory.NewFilter(context.Background())
+	f, err = fb.And().Descending().After("").Finalize()
+	assert.NoError(t, err)
+	assert.True(t, f.Sort[0].Descending)
+}
+
+func TestBuildMessageFilterCursorAfterAndBefore(t *testing.T) {
+	fb := MessageQueryFactory.NewFilter(context.Background())
+	_, err := fb.And().After(EncodeCursor(1)).Before(EncodeCursor(2)).Finalize()
+	assert.Regexp(t, "FF10391", err)
+}
+
+func TestBuildMessageFilterCursorSkip(t *testing.T) {
+	fb := MessageQueryFactory.NewFilter(context.Background())
+	_, err := fb.And().Skip(10).After("").Finalize()
+	assert.Regexp(t, "FF10392", err)
+}
+
+func TestBuildMessageFilterCursorSort(t *testing.T) {
+	fb := MessageQueryFactory.NewFilter(context.Background())
+	_, err := fb.And().Sort("created").After("").Finalize()
+	assert.Regexp(t, "FF10392", err)
+}
+
+func TestBuildMessageFilterCursorBadAfter(t *testing.T) {
+	fb := MessageQueryFactory.NewFilter(context.Background())
+	_, err := fb.And().After("!!!").Finalize()
+	assert.Regexp(t, "FF10390", err)
+}
+
+func TestBuildMessageFilterCursorBadBefore(t *testing.T) {
+	fb := MessageQueryFactory.NewFilter(context.Background())
+	_, err := fb.And().Before("YWJj").Finalize()
+	assert.Regexp(t, "FF10390", err)
+}
@@ -54,6 +54,9 @@ type Plugin interface {
 
 	// Capabilities returns capabilities - not called until after Init
 	Capabilities() *Capabilities
+
+	// HealthCheck verifies the plugin is connected to its backing service, returning an error describing the problem if not
+	HealthCheck(ctx context.Context) error
 }
 
 type iNamespaceCollection interface {
@@ -101,6 +104,9 @@ type iMessageCollection interface {
 
 	// GetMessagesForData - List messages where there is a data reference to the specified ID
 	GetMessagesForData(ctx context.Context, dataID *fftypes.UUID, filter Filter) (message []*fftypes.Message, res *FilterResult, err error)
+
+	// DeleteMessage - Delete a message and its data references, when it is pruned (no change event)
+	DeleteMessage(ctx context.Context, msgID *fftypes.UUID) (err error)
 }
 
 type iDataCollection interface {
@@ -123,6 +129,9 @@ type iDataCollection interface {
 
 	// GetDataRefs - Get data references only (no data)
 	GetDataRefs(ctx context.Context, filter Filter) (message fftypes.DataRefs, res *FilterResult, err error)
+
+	// DeleteData - Delete a data record, when it is pruned (no change event)
+	DeleteData(ctx context.Context, dataID *fftypes.UUID) (err error)
 }
 
 type iBatchCollection interface {
@@ -137,6 +146,9 @@ type iBatchCollection interface {
 
 	// GetBatches - Get batches
 	GetBatches(ctx context.Context, filter Filter) (message []*fftypes.BatchPersisted, res *FilterResult, err error)
+
+	// DeleteBatch - Delete a batch, when it is pruned (no change event)
+	DeleteBatch(ctx context.Context, batchID *fftypes.UUID) (err error)
 }
 
 type iTransactionCollection interface {
@@ -219,6 +231,9 @@ type iOperationCollection interface {
 
 	// GetOperations - Get operation
 	GetOperations(ctx context.Context, filter Filter) (operation []*fftypes.Operation, res *FilterResult, err error)
+
+	// DeleteOperation - Delete an operation, when it is pruned (no change event)
+	DeleteOperation(ctx context.Context, opID *fftypes.UUID) (err error)
 }
 
 type iSubscriptionCollection interface {
@@ -242,6 +257,43 @@ type iSubscriptionCollection interface {
 	DeleteSubscriptionByID(ctx context.Context, id *fftypes.UUID) (err error)
 }
 
+type iDeadLetterCollection interface {
+	// InsertDeadLetter - Insert a dead letter, for an event that exceeded the redelivery limit of a subscription
+	InsertDeadLetter(ctx context.Context, deadLetter *fftypes.DeadLetter) (err error)
+
+	// UpdateDeadLetter - Update a dead letter
+	UpdateDeadLetter(ctx context.Context, id *fftypes.UUID, update Update) (err error)
+
+	// GetDeadLetterByID - Get a dead letter by ID
+	GetDeadLetterByID(ctx context.Context, id *fftypes.UUID) (deadLetter *fftypes.DeadLetter, err error)
+
+	// GetDeadLetters - Get dead letters
+	GetDeadLetters(ctx context.Context, filter Filter) (deadLetters []*fftypes.DeadLetter, res *FilterResult, err error)
+
+	// DeleteDeadLetter - Delete a dead letter
+	DeleteDeadLetter(ctx context.Context, id *fftypes.UUID) (err error)
+
+	// DeleteDeadLetters - Delete all the dead letters for a subscription
+	DeleteDeadLetters(ctx context.Context, subscriptionID *fftypes.UUID) (err error)
+}
+
+type iInflightRequestCollection interface {
+	// InsertInflightRequest - Insert a record of a synchronous API request that is waiting for an event
+	InsertInflightRequest(ctx context.Context, req *fftypes.InflightRequest) (err error)
+
+	// ResolveInflightRequest - Resolve an inflight request that has not already been resolved
+	ResolveInflightRequest(ctx context.Context, ns string, id, reply *fftypes.UUID, failed bool) (err error)
+
+	// GetInflightRequestByID - Get an inflight request by ID
+	GetInflightRequestByID(ctx context.Context, id *fftypes.UUID) (req *fftypes.InflightRequest, err error)
+
+	// GetInflightRequests - Get inflight requests
+	GetInflightRequests(ctx context.Context, filter Filter) (reqs []*fftypes.InflightRequest, res *FilterResult, err error)
+
+	// DeleteInflightRequest - Delete an inflight request
+	DeleteInflightRequest(ctx context.Context, id *fftypes.UUID) (err error)
+}
+
 type iEventCollection interface {
 	// InsertEvent - Insert an event. The order of the sequences added to the database, must match the order that
 	//               the rows/objects appear available to the event dispatcher. For a concurrency enabled database
@@ -257,6 +309,9 @@ type iEventCollection interface {
 
 	// GetEvents - Get events
 	GetEvents(ctx context.Context, filter Filter) (message []*fftypes.Event, res *FilterResult, err error)
+
+	// DeleteEvent - Delete an event, when it is pruned (no change event)
+	DeleteEvent(ctx context.Context, eventID *fftypes.UUID) (err error)
 }
 
 type iIdentitiesCollection interface {
@@ -491,6 +546,12 @@ type iBlockchainEventCollection interface {
 
 	// GetBlockchainEvents - get smart contract events
 	GetBlockchainEvents(ctx context.Context, filter Filter) ([]*fftypes.BlockchainEvent, *FilterResult, error)
+
+	// UpdateBlockchainEvent - update a smart contract event, such as marking it removed after a chain reorganization
+	UpdateBlockchainEvent(ctx context.Context, id *fftypes.UUID, update Update) (err error)
+
+	// DeleteBlockchainEvent - delete a blockchain event, when it is pruned
+	DeleteBlockchainEvent(ctx context.Context, id *fftypes.UUID) (err error)
 }
 
 // PersistenceInterface are the operations that must be implemented by a database interface plugin.
@@ -544,6 +605,8 @@ type PersistenceInterface interface {
 	iPinCollection
 	iOperationCollection
 	iSubscriptionCollection
+	iDeadLetterCollection
+	iInflightRequestCollection
 	iEventCollection
 	iIdentitiesCollection
 	iVerifiersCollection
@@ -606,6 +669,8 @@ const (
 	CollectionContractAPIs      UUIDCollectionNS = "contractapis"
 	CollectionContractListeners UUIDCollectionNS = "contractsubscriptions"
 	CollectionIdentities        UUIDCollectionNS = "identities"
+	CollectionDeadLetters       UUIDCollectionNS = "deadletters"
+	CollectionInflightRequests  UUIDCollectionNS = "inflightrequests"
 )
 
 // HashCollectionNS is a collection where the primary key is a hash, such that it can
@@ -768,18 +833,19 @@ var OffsetQueryFactory = &queryFields{
 
 // OperationQueryFactory filter fields for data operations
 var OperationQueryFactory = &queryFields{
-	"id":        &UUIDField{},
-	"tx":        &UUIDField{},
-	"type":      &StringField{},
-	"namespace": &StringField{},
-	"status":    &StringField{},
-	"error":     &StringField{},
-	"plugin":    &StringField{},
-	"input":     &JSONField{},
-	"output":    &JSONField{},
-	"created":   &TimeField{},
-	"updated":   &TimeField{},
-	"retry":     &UUIDField{},
+	"id":         &UUIDField{},
+	"tx":         &UUIDField{},
+	"type":       &StringField{},
+	"namespace":  &StringField{},
+	"status":     &StringField{},
+	"error":      &StringField{},
+	"plugin":     &StringField{},
+	"input":      &JSONField{},
+	"output":     &JSONField{},
+	"created":    &TimeField{},
+	"updated":    &TimeField{},
+	"retry":      &UUIDField{},
+	"retryafter": &TimeField{},
 }
 
 // SubscriptionQueryFactory filter fields for data subscriptions
@@ -794,6 +860,32 @@ var SubscriptionQueryFactory = &queryFields{
 	"created":   &TimeField{},
 }
 
+// DeadLetterQueryFactory filter fields for dead letters
+var DeadLetterQueryFactory = &queryFields{
+	"id":           &UUIDField{},
+	"namespace":    &StringField{},
+	"subscription": &UUIDField{},
+	"event":        &UUIDField{},
+	"eventtype":    &StringField{},
+	"reference":    &UUIDField{},
+	"sequence":     &Int64Field{},
+	"attempts":     &Int64Field{},
+	"info":         &StringField{},
+	"created":      &TimeField{},
+	"updated":      &TimeField{},
+}
+
+// InflightRequestQueryFactory filter fields for inflight requests
+var InflightRequestQueryFactory = &queryFields{
+	"id":        &UUIDField{},
+	"namespace": &StringField{},
+	"type":      &StringField{},
+	"created":   &TimeField{},
+	"resolved":  &TimeField{},
+	"reply":     &UUIDField{},
+	"failed":    &BoolField{},
+}
+
 // EventQueryFactory filter fields for data events
 var EventQueryFactory = &queryFields{
 	"id":         &UUIDField{},
@@ -1013,6 +1105,7 @@ var BlockchainEventQueryFactory = &queryFields{
 	"tx.type":    &StringField{},
 	"tx.id":      &UUIDField{},
 	"timestamp":  &TimeField{},
+	"removed":    &TimeField{},
 }
 
 // ContractAPIQueryFactory filter fields for Contract APIs
@@ -52,6 +52,7 @@ import (
 //   - When data is recevied from other members in the network, be able to return the hash when provided with the remote peerID string, namespace and ID
 //     - Could be done by having a data store to resolve the transfers, or simply a deterministic path to metadata like "receive/peerID/namespace/ID"
 //   - Events triggered for arrival of blobs must contain the payloadRef, and the hash
+// - Can be deleted using the payloadRef, when the data that refers to them is pruned
 //
 type Plugin interface {
 	fftypes.Named
@@ -68,6 +69,9 @@ type Plugin interface {
 	// Capabilities returns capabilities - not called until after Init
 	Capabilities() *Capabilities
 
+	// HealthCheck verifies the plugin is connected to its backing service, returning an error describing the problem if not
+	HealthCheck(ctx context.Context) error
+
 	// GetEndpointInfo returns the information about the local endpoint
 	GetEndpointInfo(ctx context.Context) (peer fftypes.JSONObject, err error)
 
@@ -80,6 +84,9 @@ type Plugin interface {
 	// DownloadBLOB streams a received blob out of storage
 	DownloadBLOB(ctx context.Context, payloadRef string) (content io.ReadCloser, err error)
 
+	// DeleteBLOB removes a blob from storage, once FireFly no longer holds any reference to it. A blob that does not exist is not an error
+	DeleteBLOB(ctx context.Context, payloadRef string) (err error)
+
 	// CheckBLOBReceived confirms that a blob with the specified hash has been received from the specified peer
 	CheckBLOBReceived(ctx context.Context, peerID, ns string, id fftypes.UUID) (hash *fftypes.Bytes32, size int64, err error)
 
@@ -18,6 +18,7 @@ package events
 
 import (
 	"context"
+	"time"
 
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -47,8 +48,9 @@ type Plugin interface {
 	ValidateOptions(options *fftypes.SubscriptionOptions) error
 
 	// DeliveryRequest requests delivery of work on a connection, which must later be responded to
-	// Data will only be supplied as non-nil if the subscription is set to include data
-	DeliveryRequest(connID string, sub *fftypes.Subscription, event *fftypes.EventDelivery, data fftypes.DataArray) error
+	// Data will only be supplied as non-nil if the subscription is set to include data.
+	// The context is cancelled when the dispatcher for the subscription closes, ending any retries in the plugin.
+	DeliveryRequest(ctx context.Context, connID string, sub *fftypes.Subscription, event *fftypes.EventDelivery, data fftypes.DataArray) error
 }
 
 // ChangeEventListener is an optional interface for delivering database change events, only supported for ephemeral connections
@@ -57,12 +59,34 @@ type ChangeEventListener interface {
 	ChangeEvent(connID string, ce *fftypes.ChangeEvent)
 }
 
+// BatchDeliverer is an optional interface for delivering multiple events in a single request, when enabled on the subscription
+type BatchDeliverer interface {
+	// BatchOptions returns the batching options for a subscription, or nil if events are delivered individually
+	BatchOptions(sub *fftypes.Subscription) *BatchOptions
+
+	// BatchDeliveryRequest requests delivery of a batch of events on a connection, each of which must later be responded to
+	BatchDeliveryRequest(ctx context.Context, connID string, sub *fftypes.Subscription, events []*EventDeliveryWithData) error
+}
+
 // PluginAll is a combined interface for easy mocking, with all optional features
 type PluginAll interface {
 	Plugin
 	ChangeEventListener
 }
 
+type BatchOptions struct {
+	// Size is the maximum number of events in a batch - the read ahead of the subscription is raised if required, so that a batch can fill
+	Size int
+	// Timeout is the maximum time to wait for a batch to fill, after the first event arrives
+	Timeout time.Duration
+}
+
+// EventDeliveryWithData is an event in a batch, with its data if the subscription is set to include data
+type EventDeliveryWithData struct {
+	Event *fftypes.EventDelivery
+	Data  fftypes.DataArray
+}
+
 type SubscriptionMatcher func(fftypes.SubscriptionRef) bool
 
 type Callbacks interface {
@@ -88,6 +112,14 @@ type Callbacks interface {
 	// - Reject it: This resets the associated subscription back to the last committed offset
 	//   * Note all message since the last committed offet will be redelivered, so additional messages to be redelivered if streaming ahead
 	DeliveryResponse(connID string, inflight *fftypes.EventDeliveryResponse)
+
+	// CumulativeAck acknowledges every event in-flight on the connection for the subscription, with a sequence
+	// up to and including the one supplied. The offset for the subscription is moved forwards once, for the whole set.
+	CumulativeAck(connID string, subID *fftypes.UUID, sequence int64)
+
+	// DeliveryRetry is a notification that the plugin is retrying a failed delivery to the subscription internally,
+	// before it responds. It is used only for metrics, and has no effect on the state of the delivery.
+	DeliveryRetry(connID string, sub *fftypes.SubscriptionRef)
 }
 
 type Capabilities struct {
@@ -0,0 +1,31 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// ArchiveManifest is the first entry in a namespace archive, describing how the remaining entries should be read
+type ArchiveManifest struct {
+	Version   int     `json:"version"`
+	Namespace string  `json:"namespace"`
+	Created   *FFTime `json:"created"`
+}
+
+// ArchiveImportResult summarizes an import, with the number of records of each type that were imported, or skipped because they already existed
+type ArchiveImportResult struct {
+	Namespace string         `json:"namespace"`
+	Imported  map[string]int `json:"imported"`
+	Skipped   map[string]int `json:"skipped"`
+}
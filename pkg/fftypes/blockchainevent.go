@@ -28,4 +28,5 @@ type BlockchainEvent struct {
 	Info       JSONObject     `json:"info,omitempty"`
 	Timestamp  *FFTime        `json:"timestamp,omitempty"`
 	TX         TransactionRef `json:"tx"`
+	Removed    *FFTime        `json:"removed,omitempty"`
 }
@@ -39,6 +39,47 @@ type ContractListener struct {
 
 type ContractListenerOptions struct {
 	FirstEvent string `json:"firstEvent,omitempty"`
+	// Filter restricts the events delivered to those with matching parameter values. Each key is the name of an
+	// event parameter, and each value is a condition - see ListenerFilterValues.
+	// Where the blockchain connector supports it (indexed parameters on Ethereum) the filter is applied by the
+	// connector. Otherwise, including all filters on Fabric, the connector delivers every event and FireFly
+	// discards the events that do not match.
+	Filter JSONObject `json:"filter,omitempty"`
+}
+
+const (
+	// ListenerFilterAnyOf is the key of a listener filter condition that matches any one of an array of values
+	ListenerFilterAnyOf = "anyOf"
+	// ListenerFilterEquals is the key of a listener filter condition that matches a value exactly, including array and object values
+	ListenerFilterEquals = "equals"
+)
+
+// ListenerFilterValues returns the alternative values that satisfy a listener filter condition. A condition is one of:
+// - a string, number or boolean, that the parameter must equal
+// - {"anyOf": [v1, v2, ...]}, where the parameter must equal one of the values
+// - {"equals": v}, where the parameter must equal v, which can be an array or object
+// A bare array or object is ambiguous, and returns false.
+func ListenerFilterValues(condition interface{}) ([]interface{}, bool) {
+	switch ct := condition.(type) {
+	case []interface{}:
+		return nil, false
+	case JSONObject:
+		return ListenerFilterValues(map[string]interface{}(ct))
+	case map[string]interface{}:
+		if len(ct) != 1 {
+			return nil, false
+		}
+		if anyOf, ok := ct[ListenerFilterAnyOf]; ok {
+			values, isArray := anyOf.([]interface{})
+			return values, isArray
+		}
+		if equals, ok := ct[ListenerFilterEquals]; ok {
+			return []interface{}{equals}, true
+		}
+		return nil, false
+	default:
+		return []interface{}{condition}, true
+	}
 }
 
 type ContractListenerInput struct {
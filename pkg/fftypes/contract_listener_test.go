@@ -95,3 +95,29 @@ func TestContractListenerOptionsValue(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, `{"firstEvent":"newest"}`, string(val.([]byte)))
 }
+
+func TestListenerFilterValues(t *testing.T) {
+	values, ok := ListenerFilterValues("0x12345")
+	assert.True(t, ok)
+	assert.Equal(t, []interface{}{"0x12345"}, values)
+
+	values, ok = ListenerFilterValues(map[string]interface{}{"anyOf": []interface{}{float64(1), float64(2)}})
+	assert.True(t, ok)
+	assert.Equal(t, []interface{}{float64(1), float64(2)}, values)
+
+	values, ok = ListenerFilterValues(JSONObject{"equals": []interface{}{"a", "b"}})
+	assert.True(t, ok)
+	assert.Equal(t, []interface{}{[]interface{}{"a", "b"}}, values)
+
+	_, ok = ListenerFilterValues([]interface{}{"a", "b"})
+	assert.False(t, ok)
+
+	_, ok = ListenerFilterValues(map[string]interface{}{"anyOf": "a"})
+	assert.False(t, ok)
+
+	_, ok = ListenerFilterValues(map[string]interface{}{"other": "a"})
+	assert.False(t, ok)
+
+	_, ok = ListenerFilterValues(map[string]interface{}{"anyOf": []interface{}{"a"}, "equals": "a"})
+	assert.False(t, ok)
+}
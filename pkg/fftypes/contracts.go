@@ -35,12 +35,32 @@ type ContractCallRequest struct {
 	Key       string                 `json:"key,omitempty"`
 	Method    *FFIMethod             `json:"method,omitempty"`
 	Input     map[string]interface{} `json:"input"`
+	// Block is a block number or ledger position to query the state at, instead of the latest state (query only)
+	Block string `json:"block,omitempty"`
+	// BlockchainEvent queries the state at the block where a previously received blockchain event occurred (query only)
+	BlockchainEvent *UUID `json:"blockchainEvent,omitempty"`
 }
 
 type ContractCallResponse struct {
 	ID *UUID `json:"id"`
 }
 
+// ContractDeployRequest deploys a new smart contract, optionally registering an FFI for it and, once the
+// deployment succeeds, a contract API bound to its location
+type ContractDeployRequest struct {
+	Key        string        `json:"key,omitempty"`
+	Definition *JSONAny      `json:"definition"`
+	Contract   *JSONAny      `json:"contract"`
+	Input      []interface{} `json:"input"`
+	Interface  *FFIReference `json:"interface,omitempty"`
+	API        string        `json:"api,omitempty"`
+}
+
+type ContractDeployResponse struct {
+	ID        *UUID         `json:"id"`
+	Interface *FFIReference `json:"interface,omitempty"`
+}
+
 type ContractSubscribeRequest struct {
 	Interface *UUID     `json:"interface,omitempty"`
 	Location  *JSONAny  `json:"location,omitempty"`
@@ -0,0 +1,35 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// DeadLetter records an event that was removed from delivery on a subscription, because it was
+// rejected more times than the redelivery limit of the subscription allows.
+// The offset of the subscription moves past the event, so that the rest of the stream is not blocked,
+// and the dead letter can later be inspected, replayed or purged.
+type DeadLetter struct {
+	ID           *UUID           `json:"id"`
+	Namespace    string          `json:"namespace"`
+	Subscription SubscriptionRef `json:"subscription"`
+	Event        *UUID           `json:"event"`
+	EventType    EventType       `json:"eventType" ffenum:"eventtype"`
+	Reference    *UUID           `json:"reference,omitempty"`
+	Sequence     int64           `json:"sequence"`
+	Attempts     int             `json:"attempts"`
+	Info         string          `json:"info,omitempty"`
+	Created      *FFTime         `json:"created"`
+	Updated      *FFTime         `json:"updated,omitempty"`
+}
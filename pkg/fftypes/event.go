@@ -51,6 +51,8 @@ var (
 	EventTypeContractAPIConfirmed = ffEnum("eventtype", "contract_api_confirmed")
 	// EventTypeBlockchainEventReceived occurs when a new event has been received from the blockchain
 	EventTypeBlockchainEventReceived = ffEnum("eventtype", "blockchain_event_received")
+	// EventTypeBlockchainEventRemoved occurs when a previously received blockchain event has been removed from the chain by a reorganization
+	EventTypeBlockchainEventRemoved = ffEnum("eventtype", "blockchain_event_removed")
 )
 
 // Event is an activity in the system, delivered reliably to applications, that indicates something has happened in the network
@@ -0,0 +1,49 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// InflightRequestType is the type of event a synchronous API request is waiting for
+type InflightRequestType = FFEnum
+
+var (
+	// InflightRequestTypeMessageConfirm waits for a message to be confirmed or rejected
+	InflightRequestTypeMessageConfirm = ffEnum("inflightrequesttype", "message_confirm")
+	// InflightRequestTypeMessageReply waits for a message that replies to the request message
+	InflightRequestTypeMessageReply = ffEnum("inflightrequesttype", "message_reply")
+	// InflightRequestTypeIdentityConfirm waits for an identity to be confirmed
+	InflightRequestTypeIdentityConfirm = ffEnum("inflightrequesttype", "identity_confirm")
+	// InflightRequestTypeTokenPoolConfirm waits for a token pool to be confirmed or rejected
+	InflightRequestTypeTokenPoolConfirm = ffEnum("inflightrequesttype", "token_pool_confirm")
+	// InflightRequestTypeTokenTransferConfirm waits for a token transfer to be confirmed or fail
+	InflightRequestTypeTokenTransferConfirm = ffEnum("inflightrequesttype", "token_transfer_confirm")
+	// InflightRequestTypeTokenApprovalConfirm waits for a token approval to be confirmed or fail
+	InflightRequestTypeTokenApprovalConfirm = ffEnum("inflightrequesttype", "token_approval_confirm")
+)
+
+// InflightRequest records a synchronous API request that is blocked waiting for an event, so that
+// whichever FireFly node processes the event can resolve the request on behalf of the node that
+// is waiting. The reply is the ID of the object that resolved the request, and failed is set if
+// the event was a rejection or failure.
+type InflightRequest struct {
+	ID        *UUID               `json:"id"`
+	Namespace string              `json:"namespace"`
+	Type      InflightRequestType `json:"type" ffenum:"inflightrequesttype"`
+	Created   *FFTime             `json:"created"`
+	Resolved  *FFTime             `json:"resolved,omitempty"`
+	Reply     *UUID               `json:"reply,omitempty"`
+	Failed    bool                `json:"failed,omitempty"`
+}
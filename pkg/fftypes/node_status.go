@@ -18,9 +18,10 @@ package fftypes
 
 // NodeStatus is a set of information that represents the health, and identity of a node
 type NodeStatus struct {
-	Node     NodeStatusNode     `json:"node"`
-	Org      NodeStatusOrg      `json:"org"`
-	Defaults NodeStatusDefaults `json:"defaults"`
+	Node     NodeStatusNode      `json:"node"`
+	Org      NodeStatusOrg       `json:"org"`
+	Defaults NodeStatusDefaults  `json:"defaults"`
+	Plugins  []*NodeStatusPlugin `json:"plugins"`
 }
 
 // NodeStatusNode is the information about the local node, returned in the node status
@@ -42,3 +43,17 @@ type NodeStatusOrg struct {
 type NodeStatusDefaults struct {
 	Namespace string `json:"namespace"`
 }
+
+// NodeStatusPlugin is the connection health of an individual plugin, returned in the node status
+type NodeStatusPlugin struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// NodeHealth is returned by the liveness and readiness checks on the admin API
+type NodeHealth struct {
+	Healthy bool                `json:"healthy"`
+	Plugins []*NodeStatusPlugin `json:"plugins,omitempty"`
+}
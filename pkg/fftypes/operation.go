@@ -26,6 +26,8 @@ var (
 	OpTypeBlockchainBatchPin = ffEnum("optype", "blockchain_batch_pin")
 	// OpTypeBlockchainInvoke is a smart contract invoke
 	OpTypeBlockchainInvoke = ffEnum("optype", "blockchain_invoke")
+	// OpTypeBlockchainContractDeploy is a smart contract deployment
+	OpTypeBlockchainContractDeploy = ffEnum("optype", "blockchain_deploy")
 	// OpTypeSharedStorageBatchBroadcast is a shared storage operation to store broadcast data
 	OpTypeSharedStorageBatchBroadcast = ffEnum("optype", "sharedstorage_batch_broadcast")
 	// OpTypeDataExchangeBatchSend is a private send
@@ -87,6 +89,7 @@ type Operation struct {
 	Created     *FFTime    `json:"created,omitempty"`
 	Updated     *FFTime    `json:"updated,omitempty"`
 	Retry       *UUID      `json:"retry,omitempty"`
+	RetryAfter  *FFTime    `json:"retryAfter,omitempty"`
 }
 
 // PreparedOperation is an operation that has gathered all the raw data ready to send to a plugin
@@ -98,3 +101,11 @@ type PreparedOperation struct {
 	Type OpType      `json:"type" ffenum:"optype"`
 	Data interface{} `json:"data"`
 }
+
+// OperationUpdate is the status of a submitted operation, as reported by the plugin that owns it
+type OperationUpdate struct {
+	Status         OpStatus   `json:"status"`
+	BlockchainTXID string     `json:"blockchainTxId,omitempty"`
+	ErrorMessage   string     `json:"errorMessage,omitempty"`
+	Output         JSONObject `json:"output,omitempty"`
+}
@@ -21,6 +21,7 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"net/url"
+	"strings"
 
 	"github.com/hyperledger/firefly/internal/i18n"
 )
@@ -32,6 +33,7 @@ type SubscriptionFilter struct {
 	Transaction      TransactionFilter     `json:"transaction,omitempty"`
 	BlockchainEvent  BlockchainEventFilter `json:"blockchainevent,omitempty"`
 	Topic            string                `json:"topic,omitempty"`
+	Data             DataFilter            `json:"data,omitempty"`
 	DeprecatedTopics string                `json:"topics,omitempty"`
 	DeprecatedTag    string                `json:"tag,omitempty"`
 	DeprecatedGroup  string                `json:"group,omitempty"`
@@ -39,6 +41,15 @@ type SubscriptionFilter struct {
 }
 
 func NewSubscriptionFilterFromQuery(query url.Values) SubscriptionFilter {
+	var dataFilter DataFilter
+	for key := range query {
+		if strings.HasPrefix(key, "filter.data.") {
+			if dataFilter == nil {
+				dataFilter = DataFilter{}
+			}
+			dataFilter[strings.TrimPrefix(key, "filter.data.")] = query.Get(key)
+		}
+	}
 	return SubscriptionFilter{
 		Events: query.Get("filter.events"),
 		Message: MessageFilter{
@@ -54,6 +65,7 @@ func NewSubscriptionFilterFromQuery(query url.Values) SubscriptionFilter {
 			Type: query.Get("filter.transaction.type"),
 		},
 		Topic:            query.Get("filter.topic"),
+		Data:             dataFilter,
 		DeprecatedTag:    query.Get("filter.tag"),
 		DeprecatedTopics: query.Get("filter.topics"),
 		DeprecatedGroup:  query.Get("filter.group"),
@@ -67,6 +79,11 @@ type MessageFilter struct {
 	Author string `json:"author,omitempty"`
 }
 
+// DataFilter contains regular expressions keyed by a dot separated path, such as "customer.id" or "items.0.sku",
+// into the value of message data or the output of a blockchain event. All must match for an event to be dispatched.
+// For a message, it is sufficient for one data item to match every entry.
+type DataFilter map[string]string
+
 type TransactionFilter struct {
 	Type string `json:"type,omitempty"`
 }
@@ -91,6 +108,10 @@ type SubscriptionCoreOptions struct {
 	FirstEvent *SubOptsFirstEvent `json:"firstEvent,omitempty"`
 	ReadAhead  *uint16            `json:"readAhead,omitempty"`
 	WithData   *bool              `json:"withData,omitempty"`
+	// MaxRedeliveries is the number of times an event rejected by the application is redelivered before it is
+	// moved to the dead letter queue. Attempts are counted in memory on the subscription, so they are kept when the
+	// application reconnects, but restart when the node restarts or the subscription is updated.
+	MaxRedeliveries *uint16 `json:"maxRedeliveries,omitempty"`
 }
 
 // SubscriptionOptions cutomize the behavior of subscriptions
@@ -111,6 +132,28 @@ type SubscriptionRef struct {
 	Name      string `json:"name"`
 }
 
+// SubscriptionOffsetReset moves a durable subscription to a new position in the event stream. Exactly one of
+// FirstEvent (which is interpreted in the same way as the option when the subscription is created) or Timestamp must be set.
+// The reset only restarts delivery on the node that handles it - other replicas sharing the database are not notified.
+type SubscriptionOffsetReset struct {
+	FirstEvent *SubOptsFirstEvent `json:"firstEvent,omitempty"`
+	Timestamp  *FFTime            `json:"timestamp,omitempty"`
+}
+
+// SubscriptionStatus is a point-in-time view of the progress of a durable subscription through the event stream,
+// combining the stored offset with the live state of any dispatchers on this node
+type SubscriptionStatus struct {
+	Subscription    *UUID    `json:"subscription"`
+	CurrentOffset   int64    `json:"currentOffset"`
+	HighestSequence int64    `json:"highestSequence"`
+	Lag             int64    `json:"lag"`
+	Inflight        int      `json:"inflight"`
+	Connections     []string `json:"connections"`
+	Nacks           int64    `json:"nacks"`
+	LastDelivery    *FFTime  `json:"lastDelivery,omitempty"`
+	LastAck         *FFTime  `json:"lastAck,omitempty"`
+}
+
 // Subscription is a binding between the stream of events within a namespace, and an event interface - such as an application listening on websockets
 type Subscription struct {
 	SubscriptionRef
@@ -123,6 +166,39 @@ type Subscription struct {
 	Updated   *FFTime             `json:"updated"`
 }
 
+// RedactedSecret replaces the value of each secret held in the options of a subscription, when it is returned from the API
+const RedactedSecret = "*****"
+
+// Redacted returns a copy of the subscription that is safe to return from the API, with the webhook
+// signing secrets replaced. The number of secrets is kept, so a rotation in progress is still visible.
+func (s *Subscription) Redacted() *Subscription {
+	signing := s.Options.additionalOptions.GetObject("signing")
+	secrets, ok := signing["secrets"]
+	if !ok {
+		return s
+	}
+	var redactedSecrets interface{} = RedactedSecret
+	if secretArray, ok := ToStringArray(secrets); ok {
+		redactedArray := make([]interface{}, len(secretArray))
+		for i := range secretArray {
+			redactedArray[i] = RedactedSecret
+		}
+		redactedSecrets = redactedArray
+	}
+	redactedSigning := JSONObject{}
+	for k, v := range signing {
+		redactedSigning[k] = v
+	}
+	redactedSigning["secrets"] = redactedSecrets
+	redacted := *s
+	redacted.Options.additionalOptions = JSONObject{}
+	for k, v := range s.Options.additionalOptions {
+		redacted.Options.additionalOptions[k] = v
+	}
+	redacted.Options.additionalOptions["signing"] = redactedSigning
+	return &redacted
+}
+
 func (so *SubscriptionOptions) UnmarshalJSON(b []byte) error {
 	so.additionalOptions = JSONObject{}
 	err := json.Unmarshal(b, &so.additionalOptions)
@@ -135,6 +211,7 @@ func (so *SubscriptionOptions) UnmarshalJSON(b []byte) error {
 	delete(so.additionalOptions, "firstEvent")
 	delete(so.additionalOptions, "readAhead")
 	delete(so.additionalOptions, "withData")
+	delete(so.additionalOptions, "maxRedeliveries")
 	return nil
 }
 
@@ -151,6 +228,9 @@ func (so SubscriptionOptions) MarshalJSON() ([]byte, error) {
 	if so.ReadAhead != nil {
 		so.additionalOptions["readAhead"] = float64(*so.ReadAhead)
 	}
+	if so.MaxRedeliveries != nil {
+		so.additionalOptions["maxRedeliveries"] = float64(*so.MaxRedeliveries)
+	}
 	return json.Marshal(&so.additionalOptions)
 }
 
@@ -27,13 +27,15 @@ import (
 func TestSubscriptionOptionsDatabaseSerialization(t *testing.T) {
 	firstEvent := SubOptsFirstEventNewest
 	readAhead := uint16(50)
+	maxRedeliveries := uint16(5)
 	yes := true
 	sub1 := &Subscription{
 		Options: SubscriptionOptions{
 			SubscriptionCoreOptions: SubscriptionCoreOptions{
-				FirstEvent: &firstEvent,
-				ReadAhead:  &readAhead,
-				WithData:   &yes,
+				FirstEvent:      &firstEvent,
+				ReadAhead:       &readAhead,
+				WithData:        &yes,
+				MaxRedeliveries: &maxRedeliveries,
 			},
 		},
 		Filter: SubscriptionFilter{},
@@ -46,7 +48,7 @@ func TestSubscriptionOptionsDatabaseSerialization(t *testing.T) {
 	// Verify it serializes as bytes to the database
 	b1, err := sub1.Options.Value()
 	assert.NoError(t, err)
-	assert.Equal(t, `{"firstEvent":"newest","my-nested-opts":{"myopt1":12345,"myopt2":"test"},"readAhead":50,"withData":true}`, string(b1.([]byte)))
+	assert.Equal(t, `{"firstEvent":"newest","maxRedeliveries":5,"my-nested-opts":{"myopt1":12345,"myopt2":"test"},"readAhead":50,"withData":true}`, string(b1.([]byte)))
 
 	f1, err := sub1.Filter.Value()
 	assert.NoError(t, err)
@@ -61,12 +63,14 @@ func TestSubscriptionOptionsDatabaseSerialization(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, SubOptsFirstEventNewest, *sub2.Options.FirstEvent)
 	assert.Equal(t, uint16(50), *sub2.Options.ReadAhead)
+	assert.Equal(t, uint16(5), *sub2.Options.MaxRedeliveries)
 	assert.Equal(t, string(b1.([]byte)), string(b2.([]byte)))
 
 	// Confirm we don't pass core options, to transports
 	assert.Nil(t, sub2.Options.TransportOptions()["withData"])
 	assert.Nil(t, sub2.Options.TransportOptions()["firstEvent"])
 	assert.Nil(t, sub2.Options.TransportOptions()["readAhead"])
+	assert.Nil(t, sub2.Options.TransportOptions()["maxRedeliveries"])
 
 	// Confirm we get back the transport options
 	assert.Equal(t, float64(12345), sub2.Options.TransportOptions().GetObject("my-nested-opts")["myopt1"])
@@ -94,6 +98,34 @@ func TestSubscriptionOptionsDatabaseSerialization(t *testing.T) {
 	assert.Regexp(t, "FF10125", err)
 }
 
+func TestSubscriptionRedacted(t *testing.T) {
+	sub := &Subscription{Transport: "webhooks"}
+	err := json.Unmarshal([]byte(`{
+		"url": "http://example.com",
+		"signing": {"secrets": ["secret1", "secret2"]}
+	}`), &sub.Options)
+	assert.NoError(t, err)
+
+	redacted := sub.Redacted()
+	b, err := json.Marshal(redacted)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "secret1")
+	assert.Equal(t, []interface{}{RedactedSecret, RedactedSecret}, redacted.Options.TransportOptions().GetObject("signing")["secrets"])
+	assert.Equal(t, "http://example.com", redacted.Options.TransportOptions().GetString("url"))
+
+	// The original is unchanged, so it can still be used to sign deliveries
+	secrets, _ := sub.Options.TransportOptions().GetObject("signing").GetStringArrayOk("secrets")
+	assert.Equal(t, []string{"secret1", "secret2"}, secrets)
+
+	// Values that are not valid secret arrays are still hidden
+	sub.Options.TransportOptions()["signing"] = JSONObject{"secrets": "secret1"}
+	assert.Equal(t, RedactedSecret, sub.Redacted().Options.TransportOptions().GetObject("signing")["secrets"])
+
+	// Subscriptions without secrets are returned as they are
+	noSecrets := &Subscription{}
+	assert.Equal(t, noSecrets, noSecrets.Redacted())
+}
+
 func TestSubscriptionUnMarshalFail(t *testing.T) {
 
 	b, err := json.Marshal(&SubscriptionOptions{})
@@ -127,3 +159,12 @@ func TestNewSubscriptionFilterFromQuery(t *testing.T) {
 	assert.Equal(t, expectedFilter, filter)
 
 }
+
+func TestNewSubscriptionFilterFromQueryData(t *testing.T) {
+	query, _ := url.ParseQuery("filter.data.customer.id=^abc$&filter.data.items.0.sku=sku1")
+	filter := NewSubscriptionFilterFromQuery(query)
+	assert.Equal(t, DataFilter{
+		"customer.id": "^abc$",
+		"items.0.sku": "sku1",
+	}, filter.Data)
+}
@@ -33,6 +33,8 @@ var (
 	TransactionTypeTokenTransfer = ffEnum("txtype", "token_transfer")
 	// TransactionTypeContractInvoke is a smart contract invoke
 	TransactionTypeContractInvoke = ffEnum("txtype", "contract_invoke")
+	// TransactionTypeContractDeploy is a smart contract deployment
+	TransactionTypeContractDeploy = ffEnum("txtype", "contract_deploy")
 	// TransactionTypeTokenTransfer represents a token approval
 	TransactionTypeTokenApproval = ffEnum("txtype", "token_approval")
 )
@@ -50,12 +50,15 @@ type WSClientActionStartPayload struct {
 	ChangeEvents string              `json:"changeEvents,omitempty"`
 }
 
-// WSClientActionAckPayload acknowldges a received event (not applicable in AutoAck mode)
+// WSClientActionAckPayload acknowldges a received event (not applicable in AutoAck mode).
+// If UpToSequence is set, the ack is cumulative - acknowledging every event delivered for the subscription
+// with a sequence up to and including the one supplied, and the ID must not be set.
 type WSClientActionAckPayload struct {
 	WSClientActionBase
 
 	ID           *UUID            `json:"id,omitempty"`
 	Subscription *SubscriptionRef `json:"subscription,omitempty"`
+	UpToSequence *int64           `json:"upToSequence,omitempty"`
 }
 
 // WSProtocolErrorPayload is sent to the client by the server in the case of a protocol error
@@ -38,6 +38,9 @@ type Plugin interface {
 	// Capabilities returns capabilities - not called until after Init
 	Capabilities() *Capabilities
 
+	// HealthCheck verifies the plugin is connected to its backing service, returning an error describing the problem if not
+	HealthCheck(ctx context.Context) error
+
 	// PublishData publishes data to the Shared Storage, and returns a payload reference ID
 	PublishData(ctx context.Context, data io.Reader) (payloadRef string, err error)
 
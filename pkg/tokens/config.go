@@ -23,4 +23,6 @@ const (
 	TokensConfigConnector = "connector" // TODO: remove
 	// TokensConfigPlugin is the connector plugin used for this token type
 	TokensConfigPlugin = "plugin"
+	// TokensConfigBlockchain is the name of the blockchain plugin for the chain the connector indexes (defaults to the first blockchain plugin)
+	TokensConfigBlockchain = "blockchain"
 )
@@ -41,6 +41,9 @@ type Plugin interface {
 	// Capabilities returns capabilities - not called until after Init
 	Capabilities() *Capabilities
 
+	// HealthCheck verifies the plugin is connected to its backing service, returning an error describing the problem if not
+	HealthCheck(ctx context.Context) error
+
 	// CreateTokenPool creates a new (fungible or non-fungible) pool of tokens
 	CreateTokenPool(ctx context.Context, opID *fftypes.UUID, pool *fftypes.TokenPool) (complete bool, err error)
 
@@ -90,6 +93,17 @@ type Callbacks interface {
 	//
 	// Error should will only be returned in shutdown scenarios
 	TokensApproved(plugin Plugin, approval *TokenApproval) error
+
+	// TokenEventRemoved notifies that a previously delivered event has been removed from the chain by a reorganization.
+	// The event is identified by its Source and ProtocolID.
+	//
+	// Error should will only be returned in shutdown scenarios
+	TokenEventRemoved(plugin Plugin, event *blockchain.Event) error
+
+	// TokenEventsDispatched returns a channel that is closed once every event passed to the callbacks so far by the
+	// named connector has been processed, including events held back waiting for confirmations. Events must not be
+	// acknowledged to the connector before then, as events that are still held back are lost if the node restarts.
+	TokenEventsDispatched(connector string) <-chan struct{}
 }
 
 // Capabilities is the supported featureset of the tokens interface implemented by the plugin, with the specified config
@@ -0,0 +1,112 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+)
+
+// SignatureHeader is the HTTP header FireFly sets on webhook deliveries, when a signing secret
+// is configured on the subscription. It has the format:
+//
+//	t=<unix timestamp seconds>,v1=<hex HMAC-SHA256>[,v1=<hex HMAC-SHA256>]
+//
+// Each v1 signature is calculated over "<timestamp>.<request body>" with one of the active secrets.
+// There are two signatures while a secret is being rotated.
+const SignatureHeader = "X-FireFly-Signature"
+
+// DefaultTolerance is the maximum age of a signature timestamp accepted by VerifySignature, if zero is passed
+const DefaultTolerance = 5 * time.Minute
+
+const signatureVersion = "v1"
+
+// ComputeSignature returns the hex encoded HMAC-SHA256 for the timestamp and body, using the supplied secret
+func ComputeSignature(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// GenerateSignatureHeader builds the value of the SignatureHeader, with one signature for each secret
+func GenerateSignatureHeader(timestamp int64, body []byte, secrets ...string) string {
+	parts := make([]string, 0, len(secrets)+1)
+	parts = append(parts, fmt.Sprintf("t=%d", timestamp))
+	for _, secret := range secrets {
+		parts = append(parts, fmt.Sprintf("%s=%s", signatureVersion, ComputeSignature(secret, timestamp, body)))
+	}
+	return strings.Join(parts, ",")
+}
+
+// VerifySignature checks the value of the SignatureHeader received on a webhook, against the raw request body.
+// Any of the supplied secrets can match any of the signatures in the header, so receivers can accept both
+// the old and new secret during a rotation. The timestamp must be within the tolerance of the current time.
+func VerifySignature(ctx context.Context, header string, body []byte, tolerance time.Duration, secrets ...string) error {
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+	var timestamp int64 = -1
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			return i18n.NewError(ctx, i18n.MsgWebhookSignatureInvalid)
+		}
+		switch kv[0] {
+		case "t":
+			t, err := strconv.ParseInt(kv[1], 10, 64)
+			if err != nil {
+				return i18n.NewError(ctx, i18n.MsgWebhookSignatureInvalid)
+			}
+			timestamp = t
+		case signatureVersion:
+			sig, err := hex.DecodeString(kv[1])
+			if err != nil {
+				return i18n.NewError(ctx, i18n.MsgWebhookSignatureInvalid)
+			}
+			signatures = append(signatures, sig)
+		}
+	}
+	if timestamp < 0 || len(signatures) == 0 {
+		return i18n.NewError(ctx, i18n.MsgWebhookSignatureInvalid)
+	}
+
+	age := time.Since(time.Unix(timestamp, 0))
+	if age > tolerance || age < -tolerance {
+		return i18n.NewError(ctx, i18n.MsgWebhookSignatureExpired, timestamp)
+	}
+
+	for _, secret := range secrets {
+		expected, _ := hex.DecodeString(ComputeSignature(secret, timestamp, body))
+		for _, sig := range signatures {
+			if hmac.Equal(expected, sig) {
+				return nil
+			}
+		}
+	}
+	return i18n.NewError(ctx, i18n.MsgWebhookSignatureMismatch)
+}
@@ -0,0 +1,63 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"some":"payload"}`)
+	now := time.Now().Unix()
+	header := GenerateSignatureHeader(now, body, "secret1", "secret2")
+	assert.Equal(t, fmt.Sprintf("t=%d,v1=%s,v1=%s", now, ComputeSignature("secret1", now, body), ComputeSignature("secret2", now, body)), header)
+
+	ctx := context.Background()
+	assert.NoError(t, VerifySignature(ctx, header, body, 0, "secret1"))
+	assert.NoError(t, VerifySignature(ctx, header, body, time.Minute, "other", "secret2"))
+	assert.Regexp(t, "FF10382", VerifySignature(ctx, header, body, 0, "other"))
+	assert.Regexp(t, "FF10382", VerifySignature(ctx, header, []byte(`{"some":"tampered"}`), 0, "secret1"))
+}
+
+func TestVerifyExpired(t *testing.T) {
+	body := []byte(`{}`)
+	old := time.Now().Add(-10 * time.Minute).Unix()
+	header := GenerateSignatureHeader(old, body, "secret")
+	assert.Regexp(t, "FF10381", VerifySignature(context.Background(), header, body, 0, "secret"))
+	assert.NoError(t, VerifySignature(context.Background(), header, body, time.Hour, "secret"))
+
+	future := time.Now().Add(10 * time.Minute).Unix()
+	header = GenerateSignatureHeader(future, body, "secret")
+	assert.Regexp(t, "FF10381", VerifySignature(context.Background(), header, body, 0, "secret"))
+}
+
+func TestVerifyMalformed(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().Unix()
+	assert.Regexp(t, "FF10380", VerifySignature(ctx, "", nil, 0, "secret"))
+	assert.Regexp(t, "FF10380", VerifySignature(ctx, "t=abc,v1=00", nil, 0, "secret"))
+	assert.Regexp(t, "FF10380", VerifySignature(ctx, fmt.Sprintf("t=%d,v1=zz", now), nil, 0, "secret"))
+	assert.Regexp(t, "FF10380", VerifySignature(ctx, fmt.Sprintf("t=%d", now), nil, 0, "secret"))
+	assert.Regexp(t, "FF10380", VerifySignature(ctx, "v1=00", nil, 0, "secret"))
+	// Unknown versions are ignored
+	assert.Regexp(t, "FF10380", VerifySignature(ctx, fmt.Sprintf("t=%d,v0=00", now), nil, 0, "secret"))
+}
@@ -53,6 +53,7 @@ type WSClient interface {
 	URL() string
 	SetURL(url string)
 	Send(ctx context.Context, message []byte) error
+	Connected() bool
 	Close()
 }
 
@@ -75,6 +76,8 @@ type wsClient struct {
 	heartbeatMux         sync.Mutex
 	activePingSent       *time.Time
 	lastPingCompleted    time.Time
+	connectedMux         sync.Mutex
+	connected            bool
 }
 
 // WSPreConnectHandler will be called before every connect/reconnect. Any error returned will prevent the websocket from connecting.
@@ -138,6 +141,7 @@ func (w *wsClient) Connect() error {
 func (w *wsClient) Close() {
 	if !w.closed {
 		w.closed = true
+		w.setConnected(false)
 		close(w.closing)
 		c := w.wsconn
 		if c != nil {
@@ -159,6 +163,19 @@ func (w *wsClient) SetURL(url string) {
 	w.url = url
 }
 
+// Connected returns true if the websocket is connected, and the post-connect handler has completed successfully
+func (w *wsClient) Connected() bool {
+	w.connectedMux.Lock()
+	defer w.connectedMux.Unlock()
+	return w.connected
+}
+
+func (w *wsClient) setConnected(connected bool) {
+	w.connectedMux.Lock()
+	defer w.connectedMux.Unlock()
+	w.connected = connected
+}
+
 func (w *wsClient) Send(ctx context.Context, message []byte) error {
 	// Send
 	select {
@@ -340,7 +357,9 @@ func (w *wsClient) receiveReconnectLoop() {
 
 		if err == nil {
 			// Synchronously invoke the reader, as it's important we react immediately to any error there.
+			w.setConnected(true)
 			w.readLoop()
+			w.setConnected(false)
 			close(receiverDone)
 			<-w.sendDone
 
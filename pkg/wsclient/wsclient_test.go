@@ -75,6 +75,7 @@ func TestWSClientE2E(t *testing.T) {
 	fromServer <- `some data from server`
 	reply := <-wsc.Receive()
 	assert.Equal(t, `some data from server`, string(reply))
+	assert.True(t, wsc.Connected())
 
 	// Send some data back
 	err = wsc.Send(context.Background(), []byte(`some data to server`))
@@ -92,6 +93,7 @@ func TestWSClientE2E(t *testing.T) {
 
 	// Close the client
 	wsc.Close()
+	assert.False(t, wsc.Connected())
 
 }
 